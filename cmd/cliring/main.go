@@ -3,21 +3,19 @@ package main
 import (
 	"cliring/internal/app"
 	"github.com/sirupsen/logrus"
+	"os"
 )
 
-// @title           MERMAID-DIAGRAM API
-// @version         1.0
-// @description     Api server for conducting the power of attorney approval process
-
-// @host localhost:3000
-// @BasePath /
-
-// @securityDefinitions.apikey ApiKeyAuth
-// @in header
-// @name Authorization
+// OpenAPI-документ генерируется по маршрутам при старте и отдается на /v1/openapi.json.
+// Команда "cliring migrate up|down|status|version N" управляет миграциями без запуска сервера.
 func main() {
 	logrus.SetFormatter(new(logrus.JSONFormatter))
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		app.Migrate(os.Args[2:])
+		return
+	}
+
 	app.Run()
 }
 
@@ -2,57 +2,307 @@ package app
 
 import (
 	"cliring/config"
+	"cliring/internal/domain"
 	"cliring/internal/repository"
+	"cliring/internal/repository/memory"
 	"cliring/internal/service"
 	"cliring/internal/transport"
+	"cliring/pkg/cbr"
+	"cliring/pkg/logging"
+	"cliring/pkg/mtls"
+	"cliring/pkg/oidc"
+	"cliring/pkg/pdf"
 	"cliring/pkg/postgres"
+	"cliring/pkg/ratelimit"
+	"cliring/pkg/s3"
+	"cliring/pkg/secrets"
+	"cliring/pkg/webhook"
 	"context"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 // Run - Building dependencies and logic
 func Run() {
-	// Download variables env
-	if err := godotenv.Load(); err != nil {
-		logrus.Fatalf("error initalization db password(file env) %s", err.Error())
-	}
-	cfg, err := config.New()
-	if err != nil {
-		logrus.Fatalf("error load env %s", err.Error())
+	ctx := context.Background()
+	cfg, secretStore := loadConfig(ctx)
+
+	// В режиме memory данные хранятся в памяти процесса, Postgres не нужен
+	var db *postgres.Postgres
+	var repos service.Repository
+	var rateStore ratelimit.Store
+	switch cfg.Storage {
+	case "postgres":
+		// Недоступная при старте БД не останавливает сервис: он отвечает 503 и подключается в фоне
+		// Запросы арендатора (claim tenant_id) выполняются в его схеме
+		db = postgres.New(cfg).WithTenantResolver(func(ctx context.Context) string {
+			tenant, _ := ctx.Value(domain.TenantKey{}).(string)
+			return tenant
+		})
+		if err := db.Open(ctx); err != nil {
+			logrus.Errorf("error open db %s, running degraded until the database is available", err.Error())
+		}
+		postgresRepo := repository.NewRepository(db)
+		repos, rateStore = postgresRepo, postgresRepo
+	case "memory":
+		logrus.Warn("running with in-memory storage, data is lost on restart")
+		memoryRepo := memory.New()
+		repos, rateStore = memoryRepo, memoryRepo
+	default:
+		logrus.Fatalf("unknown storage %s", cfg.Storage)
 	}
 
-	ctx := context.Background()
+	storage := s3.New(cfg)
+	if err := storage.Open(ctx); err != nil {
+		logrus.Fatalf("error open s3 storage %s", err.Error())
+	}
 
-	db := postgres.New(cfg)
-	if err = db.Open(ctx); err != nil {
-		logrus.Fatalf("error open db %s", err.Error())
+	// Клиентский сертификат для банков перечитывается при ротации
+	webhookSender := webhook.New(cfg)
+	tlsCtx, stopTLS := context.WithCancel(ctx)
+	if cfg.BankTLS.CertFile != "" || cfg.BankTLS.CAFile != "" {
+		bankCerts, err := mtls.NewReloader(mtls.Files{
+			CertFile: cfg.BankTLS.CertFile,
+			KeyFile:  cfg.BankTLS.KeyFile,
+			CAFile:   cfg.BankTLS.CAFile,
+		})
+		if err != nil {
+			logrus.Fatalf("error load bank certificates %s", err.Error())
+		}
+		go bankCerts.Run(tlsCtx, cfg.BankTLS.ReloadInterval)
+		webhookSender.WithTLS(bankCerts.ClientConfig())
 	}
 
 	// Dependency injection for architecture application
-	repos := repository.NewRepository(db)
-	services := service.NewService(repos)
-	handlers := transport.NewHandler(services)
-	srv := new(transport.Server)
+	services := service.NewService(repos, cfg).
+		WithDocumentStorage(storage).
+		WithWebhookSender(webhookSender).
+		WithDocumentRenderer(pdf.New(cfg)).
+		WithRateSource(cbr.New(cfg))
+	redactor, err := logging.NewRedactor(cfg.Logging.RedactRules)
+	if err != nil {
+		logrus.Fatalf("error load log redaction rules %s", err.Error())
+	}
+	handlers := transport.NewHandler(services).
+		WithPayloadRedactor(redactor).
+		WithClaimNames(transport.ClaimNames{
+			Roles:        cfg.Auth.RolesClaim,
+			ClientID:     cfg.Auth.ClientIDClaim,
+			DealershipID: cfg.Auth.DealershipIDClaim,
+			Scope:        cfg.Auth.ScopeClaim,
+			Tenant:       cfg.Auth.TenantClaim,
+		}).
+		WithPaymentAPIKeys(cfg.Auth.PaymentAPIKeys)
+	adminNetworks, err := transport.ParseNetworks(cfg.Network.AdminAllowedCIDRs)
+	if err != nil {
+		logrus.Fatalf("error parse admin allowed cidrs %s", err.Error())
+	}
+	trustedProxies, err := transport.ParseNetworks(cfg.Network.TrustedProxies)
+	if err != nil {
+		logrus.Fatalf("error parse trusted proxies %s", err.Error())
+	}
+	handlers.WithAdminNetworks(adminNetworks).WithTrustedProxies(trustedProxies)
+	// Токены выпускает провайдер OpenID Connect; без него - проверка общим секретом
+	switch {
+	case cfg.Auth.OIDCIssuer != "":
+		provider := oidc.New(cfg)
+		if err := provider.Discover(ctx); err != nil {
+			logrus.Errorf("error load oidc provider keys %s", err.Error())
+		}
+		handlers.WithTokenParser(provider)
+	case cfg.Auth.JWTSecret != "":
+		handlers.WithTokenParser(transport.NewSecretTokenParser(cfg.Auth.JWTSecret, cfg.Auth.JWTLeeway))
+	default:
+		logrus.Fatal("either OIDC_ISSUER or JWT_SECRET must be set")
+	}
+	if cfg.RateLimit.Requests > 0 {
+		switch cfg.RateLimit.Store {
+		case "local":
+			handlers.WithRateLimiter(ratelimit.NewLocal(cfg.RateLimit.Requests, cfg.RateLimit.Window))
+		case "postgres":
+			handlers.WithRateLimiter(ratelimit.NewShared(rateStore, cfg.RateLimit.Requests, cfg.RateLimit.Window))
+		default:
+			logrus.Fatalf("unknown rate limit store %s", cfg.RateLimit.Store)
+		}
+	}
+
+	purgerCtx, stopPurger := context.WithCancel(ctx)
+	if db != nil {
+		// Пул соединений открывается, если БД была недоступна при старте
+		go db.Maintain(purgerCtx, cfg.Postgres.ReconnectInterval)
+		// Строка подключения перечитывается из хранилища секретов: после ротации пароля пул пересоздается
+		if secretStore != nil && cfg.Secrets.DSN != "" {
+			go db.WatchDSN(purgerCtx, cfg.Secrets.RefreshInterval, func(ctx context.Context) (string, error) {
+				return secrets.Resolve(ctx, secretStore, cfg.Secrets.DSN)
+			})
+		}
+	}
+
+	// Фоновые задачи запускаются после открытия БД
 	go func() {
-		if err := srv.Run(cfg.HTTPPort, handlers.InitRoutes()); err != nil {
-			logrus.Fatalf("error occured while running http server %s", err.Error())
+		if db != nil {
+			select {
+			case <-db.Opened():
+			case <-purgerCtx.Done():
+				return
+			}
+
+			// Фоновые миграции больших таблиц выполняются на отдельном соединении, не блокируя старт
+			go runOnlineMigrations(ctx, db, cfg)
 		}
+
+		startWorkers(purgerCtx, services)
+		// У каждого арендатора свои фоновые задачи и фоновые миграции в его схеме, в том числе у созданных после старта
+		go superviseTenants(purgerCtx, repos.ListTenants, cfg.Postgres.TenantRefreshInterval, func(ctx context.Context) {
+			if db != nil {
+				go runOnlineMigrations(ctx, db, cfg)
+			}
+			startWorkers(ctx, services)
+		})
 	}()
 
+	srv := new(transport.Server)
+	// С сертификатом сервер работает по HTTPS, с корневыми сертификатами клиентов - с проверкой клиентов (mTLS)
+	if cfg.TLS.CertFile != "" {
+		if cfg.TLS.ClientAuth != "require" && cfg.TLS.ClientAuth != "verify_if_given" {
+			logrus.Fatalf("unknown tls client auth %s", cfg.TLS.ClientAuth)
+		}
+		serverCerts, err := mtls.NewReloader(mtls.Files{
+			CertFile: cfg.TLS.CertFile,
+			KeyFile:  cfg.TLS.KeyFile,
+			CAFile:   cfg.TLS.ClientCAFile,
+		})
+		if err != nil {
+			logrus.Fatalf("error load server certificates %s", err.Error())
+		}
+		go serverCerts.Run(tlsCtx, cfg.TLS.ReloadInterval)
+		tlsConfig := serverCerts.ServerConfig(cfg.TLS.ClientAuth == "require")
+		go func() {
+			if err := srv.RunTLS(cfg.HTTPPort, handlers.InitRoutes(), tlsConfig); err != nil {
+				logrus.Fatalf("error occured while running https server %s", err.Error())
+			}
+		}()
+	} else {
+		go func() {
+			if err := srv.Run(cfg.HTTPPort, handlers.InitRoutes()); err != nil {
+				logrus.Fatalf("error occured while running http server %s", err.Error())
+			}
+		}()
+	}
+
 	logrus.Print("todo server started")
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 	<-quit
 
 	logrus.Println("shutting down server...")
+	stopPurger()
+	stopTLS()
 	if err := srv.Shutdown(context.Background()); err != nil {
 		logrus.Fatalf("error occured while shutting down server %s", err.Error())
 	}
-	if err := db.Close(ctx); err != nil {
-		logrus.Fatalf("error occured while closing db %s", err.Error())
+	if db != nil {
+		if err := db.Close(ctx); err != nil {
+			logrus.Fatalf("error occured while closing db %s", err.Error())
+		}
+	}
+}
+
+// startWorkers starts the background jobs of the schema of the ctx tenant.
+func startWorkers(ctx context.Context, services *service.Service) {
+	// Фоновая очистка мягко удалённых записей по сроку хранения
+	go services.RunRetentionPurger(ctx)
+	// Окончательное удаление сделок по истечении окна отмены
+	go services.RunDealDeletionWorker(ctx)
+	// Асинхронная доставка событий денежных расчетов подписчикам
+	go services.RunWebhookDispatcher(ctx)
+	// Ежедневная загрузка курсов валют ЦБ РФ для неттинга сделок в разных валютах
+	go services.RunFXRateRefresher(ctx)
+	// Ежедневное начисление неустойки по просроченным расчетам
+	go services.RunPenaltyAccrual(ctx)
+	// Заказы комиссий и договорной неустойки по настроенным триггерам
+	go services.RunObligationTriggers(ctx)
+}
+
+// runOnlineMigrations runs the online migrations of the schema of the ctx tenant.
+func runOnlineMigrations(ctx context.Context, db *postgres.Postgres, cfg *config.Config) {
+	opts := postgres.OnlineOptions{BatchSize: cfg.Postgres.OnlineBatchSize, Pause: cfg.Postgres.OnlinePause}
+	if err := db.RunOnlineMigrations(ctx, repository.OnlineMigrations, opts); err != nil {
+		logrus.Errorf("error run online migrations %s", err.Error())
+	}
+}
+
+// superviseTenants calls start with the context of every provisioned tenant once. The tenants are
+// re-read every interval, so tenants provisioned through another instance get their jobs here too.
+func superviseTenants(ctx context.Context, listTenants func(ctx context.Context) ([]*domain.Tenant, error),
+	interval time.Duration, start func(ctx context.Context)) {
+	if interval <= 0 {
+		return
+	}
+	started := map[string]bool{}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		tenants, err := listTenants(ctx)
+		if err != nil {
+			logrus.Errorf("error list tenants %s", err.Error())
+		}
+		for _, tenant := range tenants {
+			if started[tenant.TenantID] || tenant.ProvisionedAt == nil {
+				continue
+			}
+			started[tenant.TenantID] = true
+			start(context.WithValue(ctx, domain.TenantKey{}, tenant.TenantID))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadConfig reads the environment; credentials from the secret store replace the environment values.
+func loadConfig(ctx context.Context) (*config.Config, secrets.Provider) {
+	// Download variables env
+	if err := godotenv.Load(); err != nil {
+		logrus.Fatalf("error initalization db password(file env) %s", err.Error())
+	}
+	cfg, err := config.New()
+	if err != nil {
+		logrus.Fatalf("error load env %s", err.Error())
+	}
+
+	// Учетные данные из хранилища секретов замещают переменные окружения
+	secretStore, err := secrets.New(cfg)
+	if err != nil {
+		logrus.Fatalf("error init secrets provider %s", err.Error())
+	}
+	if secretStore != nil {
+		for _, secret := range []struct {
+			ref    string
+			target *string
+		}{
+			{cfg.Secrets.DSN, &cfg.Postgres.DSN},
+			{cfg.Secrets.JWTSecret, &cfg.Auth.JWTSecret},
+			{cfg.Secrets.S3AccessKey, &cfg.S3.AccessKey},
+			{cfg.Secrets.S3SecretKey, &cfg.S3.SecretKey},
+		} {
+			if secret.ref == "" {
+				continue
+			}
+			value, err := secrets.Resolve(ctx, secretStore, secret.ref)
+			if err != nil {
+				logrus.Fatalf("error resolve secret %s", err.Error())
+			}
+			*secret.target = value
+		}
 	}
+	return cfg, secretStore
 }
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"cliring/pkg/postgres"
+
+	"github.com/sirupsen/logrus"
+)
+
+// migrateUsage describes the migrate subcommand.
+const migrateUsage = `usage: cliring migrate up|down|status|version N
+  up         apply migrations up to MIGRATION_VERSION (the latest when 0)
+  down       roll back the last applied migration
+  status     print the applied and target schema versions and the migrations
+  version N  migrate up or down to schema version N
+Tenant schemas are migrated together with the public schema.`
+
+// Migrate runs the migrate subcommand with args, e.g. ["up"] or ["version", "42"], and exits
+// with a non-zero code on failure. Migrations run under the same advisory lock as on server start.
+func Migrate(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	cfg, _ := loadConfig(ctx)
+	db := postgres.New(cfg)
+	if err := db.Connect(ctx); err != nil {
+		logrus.Fatalf("error open db %s", err.Error())
+	}
+	defer db.Close(ctx)
+
+	var err error
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		err = db.MigrateUp(ctx)
+	case args[0] == "down" && len(args) == 1:
+		err = db.MigrateDown(ctx)
+	case args[0] == "status" && len(args) == 1:
+		err = printMigrationStatus(ctx, db)
+	case args[0] == "version" && len(args) == 2:
+		version, parseErr := strconv.ParseInt(args[1], 10, 32)
+		if parseErr != nil {
+			fmt.Fprintf(os.Stderr, "invalid schema version %q\n%s\n", args[1], migrateUsage)
+			os.Exit(2)
+		}
+		err = db.MigrateTo(ctx, int32(version))
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		_ = db.Close(ctx)
+		logrus.Fatalf("error migrate %s", err.Error())
+	}
+	if args[0] != "status" {
+		logrus.Infof("Schema version is %d", db.SchemaVersion())
+	}
+}
+
+// printMigrationStatus prints the schema versions and the migrations, marking the applied ones.
+func printMigrationStatus(ctx context.Context, db *postgres.Postgres) error {
+	status, err := db.MigrationStatus(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("current version: %d\ntarget version:  %d\n", status.Current, status.Target)
+	for _, migration := range status.Migrations {
+		state := "pending"
+		if migration.Applied {
+			state = "applied"
+		}
+		fmt.Printf("%4d  %-8s %s\n", migration.Version, state, migration.Name)
+	}
+	for _, tenant := range status.Tenants {
+		fmt.Printf("tenant %s: version %d\n", tenant.TenantID, tenant.Current)
+	}
+	return nil
+}
@@ -1,12 +1,59 @@
 package domain
 
 import (
+	"strconv"
 	"time"
 )
 
 // ClientIDKey is the context key for client_id.
 type ClientIDKey struct{}
 
+// ActorKey is the context key for the user performing the request (JWT subject).
+type ActorKey struct{}
+
+// DealershipKey is the context key for the dealership the user performing the request is limited to
+// (JWT dealership_id claim). Users without the claim work with all dealerships.
+type DealershipKey struct{}
+
+// RolesKey is the context key for the roles of the user performing the request (JWT roles claim).
+type RolesKey struct{}
+
+// ClientClaimKey is the context key for the client a user with the client role is (JWT client_id claim).
+type ClientClaimKey struct{}
+
+// TokenKey is the context key for the *TokenInfo of the bearer token of the request.
+type TokenKey struct{}
+
+// ImpersonationKey is the context key for the client an administrator impersonates (X-Impersonate-Client header).
+type ImpersonationKey struct{}
+
+// TenantKey is the context key for the tenant (dealership group, JWT tenant_id claim) whose schema
+// the request works with. Requests without a tenant work with the public schema.
+type TenantKey struct{}
+
+// ScopesKey is the context key for the scopes of the token (JWT scope claim) limiting the routes it reaches.
+type ScopesKey struct{}
+
+// AuditKey is the context key for the *AuditEntry of a state-changing request. Services fill in
+// the changed entity and its states before and after the change.
+type AuditKey struct{}
+
+// Roles of users (JWT roles claim). An administrator has every role.
+const (
+	// RoleClient is the role of clients: they read their own orders only.
+	RoleClient = "client"
+	// RoleManager is the role of dealership managers who open deals.
+	RoleManager = "manager"
+	// RoleFinance is the role of the finance department that executes settlements.
+	RoleFinance = "finance"
+	RoleAdmin   = "admin"
+	// RoleApprover is the role of users who approve large settlements.
+	RoleApprover = "approver"
+)
+
+// ActorSystem is the actor recorded for changes made outside of an authenticated request.
+const ActorSystem = "system"
+
 // Error codes used in API responses.
 const (
 	ErrCodeInvalidInput    = "ERR_INVALID_INPUT"
@@ -14,6 +61,9 @@ const (
 	ErrCodeNotFound        = "ERR_NOT_FOUND"
 	ErrCodeInternal        = "ERR_INTERNAL"
 	ErrCodeInvalidClientID = "ERR_INVALID_CLIENT_ID"
+	ErrCodeConflict        = "ERR_CONFLICT"
+	// ErrCodeInvalidReference is returned when the request references a row that does not exist.
+	ErrCodeInvalidReference = "ERR_INVALID_REFERENCE"
 )
 
 // Status constants for entities.
@@ -23,6 +73,31 @@ const (
 	StatusCancelled = "cancelled"
 )
 
+// Deal lifecycle statuses.
+const (
+	DealStatusDraft     = "draft"
+	DealStatusActive    = "active"
+	DealStatusClearing  = "clearing"
+	DealStatusCompleted = "completed"
+	DealStatusCancelled = "cancelled"
+)
+
+// DealTransitions lists the statuses a deal may move to from each status.
+var DealTransitions = map[string][]string{
+	DealStatusDraft:     {DealStatusActive, DealStatusCancelled},
+	DealStatusActive:    {DealStatusClearing, DealStatusCancelled},
+	DealStatusClearing:  {DealStatusCompleted, DealStatusActive},
+	DealStatusCompleted: {},
+	DealStatusCancelled: {},
+}
+
+// OrderTransitions lists the statuses an order may move to from each status.
+var OrderTransitions = map[string][]string{
+	StatusPending:   {StatusExecuted, StatusCancelled},
+	StatusExecuted:  {},
+	StatusCancelled: {},
+}
+
 // ErrorResponse represents an API error response.
 type ErrorResponse struct {
 	Error ErrorDetail `json:"error"`
@@ -38,12 +113,29 @@ type ErrorDetail struct {
 // Deal represents a deal entity.
 type Deal struct {
 	DealID       int       `json:"deal_id"`
-	IsCompleted  bool      `json:"is_completed"`
+	Status       string    `json:"status"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	DealershipID int       `json:"dealership_id"`
 	ManagerID    int       `json:"manager_id"`
 	ClientID     int       `json:"client_id"`
+	// DeletionScheduledAt is set while the deal is pending deletion; until then the deletion can be cancelled.
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	// SettlementsRecomputeRequired is set when orders were deleted or a settlement was cancelled
+	// after the settlements were last executed.
+	SettlementsRecomputeRequired bool `json:"settlements_recompute_required"`
+}
+
+// DealUpdate represents a request to partially update a deal.
+// Only non-nil fields are applied.
+type DealUpdate struct {
+	DealershipID *int `json:"dealership_id,omitempty"`
+	ManagerID    *int `json:"manager_id,omitempty"`
+}
+
+// DealTransition represents a request to move a deal to another status.
+type DealTransition struct {
+	Status string `json:"status"`
 }
 
 // Order represents an order entity.
@@ -51,37 +143,1483 @@ type Order struct {
 	OrderID         int       `json:"order_id"`
 	DealID          int       `json:"deal_id"`
 	OrderTypeID     int       `json:"order_type_id"`
-	Amount          float64   `json:"amount"`
+	Amount          Money     `json:"amount"`
 	Status          string    `json:"status"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 	NeedAndOrdersID *int      `json:"need_and_orders_id,omitempty"`
 	BankID          *int      `json:"bank_id,omitempty"`
+	CurrencyCode    string    `json:"currency_code"`
+	// Discount is subtracted from Amount, which is the price before discount and without VAT.
+	Discount Money `json:"discount"`
+	// VATRate is the VAT rate in percent.
+	VATRate float64 `json:"vat_rate"`
+	// NetAmount is Amount minus Discount, without VAT.
+	NetAmount Money `json:"net_amount"`
+	// VATAmount is the VAT charged on NetAmount.
+	VATAmount Money `json:"vat_amount"`
+	// GrossAmount is NetAmount plus VATAmount; netting and allocations use this amount.
+	GrossAmount Money `json:"gross_amount"`
+	// DealershipID is the dealership that placed the order; defaults to the dealership of the deal.
+	DealershipID *int `json:"dealership_id,omitempty"`
+	// VehicleID references the vehicle of a purchase or trade-in order.
+	VehicleID *int `json:"vehicle_id,omitempty"`
+	// InsurerID is the insurance company of an insurance order.
+	InsurerID *int `json:"insurer_id,omitempty"`
+	// CurrencyExponent is the number of minor-unit digits of the currency.
+	CurrencyExponent int `json:"currency_exponent"`
+	// AmountDisplay is Amount formatted for display, e.g. "1 234,50 ₽".
+	AmountDisplay string `json:"amount_display"`
+	// PaidAmount is the part of GrossAmount covered by executed settlements.
+	PaidAmount Money `json:"paid_amount"`
+	// OutstandingAmount is the part of GrossAmount not yet covered by executed settlements.
+	OutstandingAmount Money `json:"outstanding_amount"`
+	// OrderNumber is the sequence number of the order within its deal.
+	OrderNumber int `json:"order_number"`
+	// PriceWarnings lists catalog price violations accepted in warn mode.
+	PriceWarnings []*PriceViolation `json:"price_warnings,omitempty"`
+	// ReviewStatus is the anti-fraud review status; empty if the order was never flagged.
+	ReviewStatus string `json:"review_status,omitempty"`
+	// ExecuteAt is the planned execution date, e.g. of a credit disbursement or a trade-in buyout.
+	ExecuteAt *time.Time `json:"execute_at,omitempty"`
+	// Invoice is the invoice or receipt file attached to the order; nil if none was uploaded.
+	Invoice *OrderInvoice `json:"invoice,omitempty"`
+	// ClearingSessionID is the clearing session the order was accepted in.
+	ClearingSessionID *int `json:"clearing_session_id,omitempty"`
+	// TriggerID is the obligation trigger that generated the order; nil for orders placed manually.
+	TriggerID *int `json:"trigger_id,omitempty"`
+	// CreditContractID is the credit contract of a credit order.
+	CreditContractID *int `json:"credit_contract_id,omitempty"`
+	// AppraisalID is the approved appraisal a trade-in order was placed from.
+	AppraisalID *int `json:"appraisal_id,omitempty"`
+}
+
+// OrderInvoice describes the invoice file of an order. The content is kept in the object storage.
+type OrderInvoice struct {
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	StorageKey  string `json:"-"`
+	// DownloadURL is the API path the file is downloaded from.
+	DownloadURL string `json:"download_url"`
+}
+
+// OrderInvoiceURL returns the API path of the invoice file of an order.
+func OrderInvoiceURL(orderID int) string {
+	return "/v1/orders/" + strconv.Itoa(orderID) + "/invoice"
+}
+
+// Anti-fraud review statuses.
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// HeldForReview reports whether the order is kept out of netting by an anti-fraud review.
+func (o *Order) HeldForReview() bool {
+	return o.ReviewStatus == ReviewPending || o.ReviewStatus == ReviewRejected
+}
+
+// AwaitingCreditContract reports whether the order is a pending credit order without a credit contract.
+// Such an order is kept out of netting until the contract is attached; executed credit orders
+// placed before contracts were recorded keep their part in netting.
+func (o *Order) AwaitingCreditContract() bool {
+	return o.OrderTypeID == OrderTypeCredit && o.CreditContractID == nil && o.Status == StatusPending
+}
+
+// OrderReview is an entry of the review queue for an order flagged by anti-fraud rules.
+type OrderReview struct {
+	ReviewID  int        `json:"review_id"`
+	OrderID   int        `json:"order_id"`
+	ClientID  int        `json:"client_id"`
+	Reasons   []string   `json:"reasons"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	DecidedBy *string    `json:"decided_by,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
+// Order revision actions.
+const (
+	OrderRevisionUpdated       = "updated"
+	OrderRevisionStatusChanged = "status_changed"
+	OrderRevisionDeleted       = "deleted"
+)
+
+// OrderRevision holds the values an order had before a change, for dispute investigations.
+type OrderRevision struct {
+	RevisionID  int       `json:"revision_id"`
+	OrderID     int       `json:"order_id"`
+	Action      string    `json:"action"`
+	DealID      int       `json:"deal_id"`
+	OrderTypeID int       `json:"order_type_id"`
+	Amount      Money     `json:"amount"`
+	Status      string    `json:"status"`
+	ChangedBy   string    `json:"changed_by"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+// OrderFilter narrows down an order list. Only non-nil fields are applied.
+type OrderFilter struct {
+	Status      *string    `form:"status"`
+	OrderTypeID *int       `form:"order_type_id"`
+	DealID      *int       `form:"deal_id"`
+	BankID      *int       `form:"bank_id"`
+	AmountMin   *Money     `form:"amount_min"`
+	AmountMax   *Money     `form:"amount_max"`
+	CreatedFrom *time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   *time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// Sortable order fields.
+const (
+	OrderSortAmount    = "amount"
+	OrderSortCreatedAt = "created_at"
+	OrderSortStatus    = "status"
+)
+
+// Sort directions.
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// OrderSort defines the order of an order list. Empty fields select the newest orders first.
+type OrderSort struct {
+	Field     string `form:"sort"`
+	Direction string `form:"order"`
+}
+
+// OrderList is a page of orders.
+type OrderList struct {
+	Orders  []*Order `json:"orders"`
+	Page    int      `json:"page"`
+	Limit   int      `json:"limit"`
+	Total   int      `json:"total"`
+	HasNext bool     `json:"has_next"`
+}
+
+// OrderTransition represents a request to move an order to another status.
+type OrderTransition struct {
+	Status string `json:"status"`
 }
 
 // OrderCreate represents a request to create an order.
 type OrderCreate struct {
-	DealID          int     `json:"deal_id"`
-	OrderTypeID     int     `json:"order_type_id"`
-	Amount          float64 `json:"amount"`
-	NeedAndOrdersID *int    `json:"need_and_orders_id,omitempty"`
-	BankID          *int    `json:"bank_id,omitempty"`
+	DealID          int    `json:"deal_id"`
+	OrderTypeID     int    `json:"order_type_id"`
+	Amount          Money  `json:"amount"`
+	NeedAndOrdersID *int   `json:"need_and_orders_id,omitempty"`
+	BankID          *int   `json:"bank_id,omitempty"`
+	CurrencyCode    string `json:"currency_code,omitempty"`
+	DealershipID    *int   `json:"dealership_id,omitempty"`
+	VehicleID       *int   `json:"vehicle_id,omitempty"`
+	InsurerID       *int   `json:"insurer_id,omitempty"`
+	// CreditContractID references the credit contract of a credit order.
+	CreditContractID *int `json:"credit_contract_id,omitempty"`
+	// AppraisalID references the approved appraisal of a trade-in order.
+	AppraisalID *int `json:"appraisal_id,omitempty"`
+	// Discount and VATRate default to zero: the gross amount equals Amount.
+	Discount Money   `json:"discount,omitempty"`
+	VATRate  float64 `json:"vat_rate,omitempty"`
+	// ExecuteAt plans the execution of the order.
+	ExecuteAt *time.Time `json:"execute_at,omitempty"`
+}
+
+// OrderUpdate represents a request to partially update an order. Only non-nil fields are changed.
+type OrderUpdate struct {
+	DealID           *int       `json:"deal_id,omitempty"`
+	OrderTypeID      *int       `json:"order_type_id,omitempty"`
+	Amount           *Money     `json:"amount,omitempty"`
+	NeedAndOrdersID  *int       `json:"need_and_orders_id,omitempty"`
+	BankID           *int       `json:"bank_id,omitempty"`
+	DealershipID     *int       `json:"dealership_id,omitempty"`
+	VehicleID        *int       `json:"vehicle_id,omitempty"`
+	InsurerID        *int       `json:"insurer_id,omitempty"`
+	CreditContractID *int       `json:"credit_contract_id,omitempty"`
+	AppraisalID      *int       `json:"appraisal_id,omitempty"`
+	Discount         *Money     `json:"discount,omitempty"`
+	VATRate          *float64   `json:"vat_rate,omitempty"`
+	ExecuteAt        *time.Time `json:"execute_at,omitempty"`
 }
 
 // MonetarySettlement represents a monetary settlement entity.
 type MonetarySettlement struct {
 	MonetarySettlementID int       `json:"monetary_settlement_id"`
 	DealID               *int      `json:"deal_id"`
-	Amount               float64   `json:"amount"`
+	Amount               Money     `json:"amount"`
 	Status               string    `json:"status"`
 	CreatedAt            time.Time `json:"created_at"`
 	UpdatedAt            time.Time `json:"updated_at"`
 	BankID               *int      `json:"bank_id,omitempty"`
+	CurrencyCode         string    `json:"currency_code"`
+	// Participant identifies the netting participant: client, bank, dealership or insurer.
+	Participant string `json:"participant"`
+	// ClientID is set for client participants of a cross-deal clearing cycle.
+	ClientID *int `json:"client_id,omitempty"`
+	// DealershipID is set for dealership participants.
+	DealershipID *int `json:"dealership_id,omitempty"`
+	// InsurerID is set for insurer participants.
+	InsurerID *int `json:"insurer_id,omitempty"`
+	// CurrencyExponent is the number of minor-unit digits of the currency.
+	CurrencyExponent int `json:"currency_exponent"`
+	// AmountDisplay is Amount formatted for display, e.g. "1 234,50 ₽".
+	AmountDisplay string `json:"amount_display"`
+	// DueDate is the payment deadline counted in business days.
+	DueDate string `json:"due_date,omitempty"`
+	// Overdue is set when a pending settlement is not paid by DueDate plus the grace period.
+	Overdue bool `json:"overdue"`
+	// RunNumber is the sequence number within the deal of the run that executed the settlement.
+	RunNumber int `json:"run_number,omitempty"`
+	// RoundingResidual is the part of Amount assigned to the participant to balance the rounded net positions.
+	RoundingResidual Money `json:"rounding_residual,omitempty"`
+	// ClearingSessionID is the clearing session whose computation fixed the settlement.
+	ClearingSessionID *int `json:"clearing_session_id,omitempty"`
+	// Allocations shows how an executed settlement was distributed across orders.
+	Allocations []*OrderAllocation `json:"allocations,omitempty"`
+	// VATBreakdown splits Amount into the net amount and VAT of every VAT rate for accounting.
+	VATBreakdown []*VATBreakdown `json:"vat_breakdown,omitempty"`
+	// PaymentReference is the number of the payment document that executed the settlement.
+	PaymentReference string `json:"payment_reference,omitempty"`
+	// ExecutedAt is the moment the settlement was executed.
+	ExecutedAt *time.Time `json:"executed_at,omitempty"`
+	// LedgerEntries are the offsetting entries posted when the settlement was executed.
+	LedgerEntries []*LedgerEntry `json:"ledger_entries,omitempty"`
+	// CancellationReason explains why a cancelled settlement was cancelled.
+	CancellationReason string `json:"cancellation_reason,omitempty"`
+	// CancelledAt is the moment the settlement was cancelled.
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	// PaidAmount is the sum of the partial payments recorded against a stored settlement.
+	PaidAmount Money `json:"paid_amount,omitempty"`
+	// RemainingAmount is the part of a pending settlement not yet covered by partial payments.
+	RemainingAmount Money `json:"remaining_amount,omitempty"`
+	// Payments are the partial payments recorded against the settlement.
+	Payments []*SettlementPayment `json:"payments,omitempty"`
+	// FXRates are the exchange rates the orders of a multi-currency deal were converted with before netting.
+	FXRates []*FXRate `json:"fx_rates,omitempty"`
+	// ApprovalStatus is set for settlements above the approval threshold, which are executed
+	// only after a second user approves them.
+	ApprovalStatus string `json:"approval_status,omitempty"`
+	// CreatedBy is the user whose request fixed the settlement.
+	CreatedBy string `json:"created_by,omitempty"`
+	// ReviewedBy is the user who approved or rejected the settlement.
+	ReviewedBy string     `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	// ReviewComment explains the decision; it is mandatory for a rejection.
+	ReviewComment string `json:"review_comment,omitempty"`
+	// Counterparty is the participant the settlement is paid to; it is set in gross settlement mode only.
+	Counterparty *Participant `json:"counterparty,omitempty"`
+}
+
+// Settlement modes. In net mode every participant settles its net position; in gross mode every
+// obligation between two participants is settled separately, for banks that refuse netted payments.
+const (
+	SettlementModeNet   = "net"
+	SettlementModeGross = "gross"
+)
+
+// MonetarySettlementList is a page of stored monetary settlements.
+type MonetarySettlementList struct {
+	Settlements []*MonetarySettlement `json:"settlements"`
+	Page        int                   `json:"page"`
+	Limit       int                   `json:"limit"`
+	Total       int                   `json:"total"`
+	HasNext     bool                  `json:"has_next"`
+	// Transfers say who pays whom; they are returned for computed settlements only.
+	Transfers []*SettlementLeg `json:"transfers,omitempty"`
+}
+
+// MonetarySettlementExecution represents a request to execute a pending monetary settlement.
+type MonetarySettlementExecution struct {
+	PaymentReference string `json:"payment_reference"`
+}
+
+// SettlementPayment is a partial payment recorded against a pending monetary settlement.
+type SettlementPayment struct {
+	PaymentID            int       `json:"payment_id"`
+	MonetarySettlementID int       `json:"monetary_settlement_id"`
+	Amount               Money     `json:"amount"`
+	PaymentReference     string    `json:"payment_reference"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+// SettlementPaymentCreate represents a request to record a partial payment against a monetary settlement.
+type SettlementPaymentCreate struct {
+	Amount           Money  `json:"amount"`
+	PaymentReference string `json:"payment_reference"`
+}
+
+// Approval statuses of settlements above the approval threshold.
+const (
+	ApprovalRequired = "required"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
+// AwaitsApproval reports whether the settlement cannot be executed until it is approved.
+func (s *MonetarySettlement) AwaitsApproval() bool {
+	return s.ApprovalStatus == ApprovalRequired || s.ApprovalStatus == ApprovalRejected
+}
+
+// MonetarySettlementReview represents a request to approve or reject a settlement above the approval threshold.
+type MonetarySettlementReview struct {
+	Comment string `json:"comment"`
+}
+
+// MonetarySettlementCancellation represents a request to cancel a pending monetary settlement.
+type MonetarySettlementCancellation struct {
+	Reason string `json:"reason"`
+}
+
+// Ledger entry directions.
+const (
+	LedgerDebit  = "debit"
+	LedgerCredit = "credit"
+)
+
+// LedgerAccountClearing is the clearing account that receives the payments of the participants.
+const LedgerAccountClearing = "clearing"
+
+// LedgerEntry is one side of the double entry posted for an executed settlement.
+type LedgerEntry struct {
+	LedgerEntryID        int `json:"ledger_entry_id"`
+	MonetarySettlementID int `json:"monetary_settlement_id"`
+	DealID               int `json:"deal_id"`
+	// Account is LedgerAccountClearing or the participant account, e.g. "dealership:12".
+	Account          string    `json:"account"`
+	Direction        string    `json:"direction"`
+	Amount           Money     `json:"amount"`
+	CurrencyCode     string    `json:"currency_code"`
+	PaymentReference string    `json:"payment_reference,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Bank is an entry of the banks reference. Orders and settlements reference it by BankID;
+// only an active bank may be set on an order.
+type Bank struct {
+	BankID               int       `json:"bank_id"`
+	Name                 string    `json:"name"`
+	BIC                  string    `json:"bic,omitempty"`
+	CorrespondentAccount string    `json:"correspondent_account,omitempty"`
+	Active               bool      `json:"active"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// PaymentDetails are the bank details of a participant account or of the clearing account.
+type PaymentDetails struct {
+	// Account is LedgerAccountClearing or the participant account, e.g. "dealership:12".
+	Account              string `json:"account"`
+	Name                 string `json:"name,omitempty"`
+	INN                  string `json:"inn,omitempty"`
+	KPP                  string `json:"kpp,omitempty"`
+	BankName             string `json:"bank_name,omitempty"`
+	BIC                  string `json:"bic,omitempty"`
+	BankAccount          string `json:"bank_account,omitempty"`
+	CorrespondentAccount string `json:"correspondent_account,omitempty"`
+}
+
+// PaymentInstruction is a payment order of an executed settlement to be sent to internet banking.
+type PaymentInstruction struct {
+	MonetarySettlementID int             `json:"monetary_settlement_id"`
+	DealID               int             `json:"deal_id"`
+	Payer                *PaymentDetails `json:"payer"`
+	Payee                *PaymentDetails `json:"payee"`
+	Amount               Money           `json:"amount"`
+	CurrencyCode         string          `json:"currency_code"`
+	// VATAmount is the VAT of the orders the settlement was allocated to.
+	VATAmount Money  `json:"vat_amount"`
+	Purpose   string `json:"purpose"`
+	// Complete is false if the bank details of the payer or the payee are not known.
+	Complete bool `json:"complete"`
+}
+
+// Settlement events delivered to webhook subscribers.
+const (
+	EventSettlementCreated   = "settlement.created"
+	EventSettlementExecuted  = "settlement.executed"
+	EventSettlementCancelled = "settlement.cancelled"
+)
+
+// Webhook delivery statuses.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription subscribes an external accounting system to settlement events.
+type WebhookSubscription struct {
+	SubscriptionID int      `json:"subscription_id"`
+	URL            string   `json:"url"`
+	Events         []string `json:"events"`
+	// Secret signs the request bodies; it is returned only when the subscription is created.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookEvent is the body of a webhook request.
+type WebhookEvent struct {
+	Event      string              `json:"event"`
+	OccurredAt time.Time           `json:"occurred_at"`
+	Settlement *MonetarySettlement `json:"settlement"`
+}
+
+// WebhookDelivery is a queued delivery of an event to a subscriber.
+type WebhookDelivery struct {
+	DeliveryID     int64
+	SubscriptionID int
+	URL            string
+	Secret         string
+	Event          string
+	Payload        []byte
+	Attempts       int
+}
+
+// Bank statement line reconciliation statuses.
+const (
+	StatementLineMatched   = "matched"
+	StatementLineUnmatched = "unmatched"
+)
+
+// BankStatementLine is an incoming payment of a bank statement.
+type BankStatementLine struct {
+	LineID           int    `json:"line_id"`
+	PaymentReference string `json:"payment_reference"`
+	Amount           Money  `json:"amount"`
+	CurrencyCode     string `json:"currency_code"`
+	// ValueDate is the value date in DateLayout.
+	ValueDate string `json:"value_date"`
+	PayerName string `json:"payer_name,omitempty"`
+	Purpose   string `json:"purpose,omitempty"`
+	Status    string `json:"status"`
+	// MonetarySettlementID is the pending settlement the payment executed.
+	MonetarySettlementID *int       `json:"monetary_settlement_id,omitempty"`
+	ImportedAt           time.Time  `json:"imported_at"`
+	MatchedAt            *time.Time `json:"matched_at,omitempty"`
+}
+
+// BankStatementImport represents a request to import bank statement lines.
+type BankStatementImport struct {
+	Lines []*BankStatementLine `json:"lines"`
+}
+
+// BankStatementReconciliation is the result of a bank statement import.
+type BankStatementReconciliation struct {
+	Matched   []*BankStatementLine `json:"matched"`
+	Unmatched []*BankStatementLine `json:"unmatched"`
+	// Duplicates is the number of lines skipped because they were imported before.
+	Duplicates int `json:"duplicates"`
+}
+
+// Payment statuses reported by banks in payment confirmations.
+const (
+	PaymentExecuted = "executed"
+	PaymentRejected = "rejected"
+)
+
+// Processing outcomes of payment confirmations.
+const (
+	ConfirmationReceived  = "received"
+	ConfirmationApplied   = "applied"
+	ConfirmationUnmatched = "unmatched"
+	ConfirmationIgnored   = "ignored"
+)
+
+// PaymentConfirmation is a payment confirmation sent by a bank. A confirmation of an executed payment
+// is recorded as a payment against the settlement its reference number points to.
+type PaymentConfirmation struct {
+	ConfirmationID int `json:"confirmation_id"`
+	// Source is the bank the API key of the request belongs to.
+	Source string `json:"source"`
+	// ExternalID identifies the confirmation at the bank; a repeated callback with the same ID is not processed again.
+	ExternalID string `json:"external_id"`
+	// ReferenceNumber is the settlement number from the payment purpose, e.g. "7" or "расчет №7".
+	ReferenceNumber  string `json:"reference_number"`
+	PaymentReference string `json:"payment_reference"`
+	Amount           Money  `json:"amount"`
+	CurrencyCode     string `json:"currency_code"`
+	Status           string `json:"status"`
+	Outcome          string `json:"outcome"`
+	// Reason explains why the confirmation was not applied to a settlement.
+	Reason               string     `json:"reason,omitempty"`
+	MonetarySettlementID *int       `json:"monetary_settlement_id,omitempty"`
+	ReceivedAt           time.Time  `json:"received_at"`
+	ProcessedAt          *time.Time `json:"processed_at,omitempty"`
+}
+
+// PaymentConfirmationCreate represents a payment confirmation callback of a bank.
+type PaymentConfirmationCreate struct {
+	ExternalID       string `json:"external_id"`
+	ReferenceNumber  string `json:"reference_number"`
+	PaymentReference string `json:"payment_reference"`
+	Amount           Money  `json:"amount"`
+	CurrencyCode     string `json:"currency_code"`
+	Status           string `json:"status"`
+}
+
+// VATBreakdown is the part of a net position charged at one VAT rate.
+// NetAmount plus VATAmount over all rates of a settlement equals its amount.
+type VATBreakdown struct {
+	VATRate   float64 `json:"vat_rate"`
+	NetAmount Money   `json:"net_amount"`
+	VATAmount Money   `json:"vat_amount"`
+}
+
+// DefaultCurrencyCode is the currency used when none is specified.
+const DefaultCurrencyCode = "RUB"
+
+// FXRate is an exchange rate of a currency to the pivot currency of netting. Rates are never updated:
+// a new rate is a new version, so the rate a settlement was computed with stays available.
+type FXRate struct {
+	// RateID identifies the rate version.
+	RateID       int    `json:"rate_id"`
+	CurrencyCode string `json:"currency_code"`
+	// PivotCurrencyCode is the currency obligations are converted to.
+	PivotCurrencyCode string `json:"pivot_currency_code"`
+	// Rate is the amount of the pivot currency per unit of the currency.
+	Rate float64 `json:"rate"`
+	// RateDate is the date in DateLayout the rate is effective from.
+	RateDate string `json:"rate_date"`
+	// Source tells whether an operator entered the rate or it was fetched from the Central Bank.
+	Source    string    `json:"source,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Sources of exchange rates.
+const (
+	FXRateSourceManual = "manual"
+	FXRateSourceCBR    = "cbr"
+)
+
+// NettingExplanation shows how the net positions of a deal are derived from its orders.
+type NettingExplanation struct {
+	DealID       int    `json:"deal_id"`
+	CurrencyCode string `json:"currency_code"`
+	// Obligations are the non-zero cells of the obligation matrix: what a debtor owes a creditor over all orders.
+	Obligations []*NettingObligation `json:"obligations"`
+	Positions   []*NettingPosition   `json:"positions"`
+	// ExcludedOrderIDs are the orders that do not take part in netting, e.g. orders held for review.
+	ExcludedOrderIDs []int `json:"excluded_order_ids"`
+	// FXRates are the exchange rates orders in other currencies were converted with.
+	FXRates []*FXRate `json:"fx_rates,omitempty"`
+	// Rounding is the rounding policy the net positions were rounded with.
+	Rounding RoundingPolicy `json:"rounding"`
+}
+
+// NettingObligation is the total a debtor owes a creditor over the orders of a deal.
+type NettingObligation struct {
+	Debtor   Participant `json:"debtor"`
+	Creditor Participant `json:"creditor"`
+	Amount   Money       `json:"amount"`
+	OrderIDs []int       `json:"order_ids"`
+	// PenaltyIDs are the overdue penalties of the debtor included in the obligation.
+	PenaltyIDs []int `json:"penalty_ids,omitempty"`
+}
+
+// SettlementPenalty is the penalty accrued for one day of delay of an overdue pending settlement.
+// Penalties are obligations of the debtor in the next netting of the deal.
+type SettlementPenalty struct {
+	PenaltyID            int `json:"penalty_id"`
+	MonetarySettlementID int `json:"monetary_settlement_id"`
+	DealID               int `json:"deal_id"`
+	// Debtor is the participant of the overdue settlement, who pays the penalty.
+	Debtor Participant `json:"debtor"`
+	// AccrualDate is the day of delay in DateLayout the penalty is accrued for.
+	AccrualDate string `json:"accrual_date"`
+	// BaseAmount is the unpaid part of the settlement the penalty is charged on.
+	BaseAmount Money `json:"base_amount"`
+	// Rate is the penalty per day of delay in percent of BaseAmount.
+	Rate         float64   `json:"rate"`
+	Amount       Money     `json:"amount"`
+	CurrencyCode string    `json:"currency_code"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// NettingPosition is the net position of a participant with its gross components:
+// Net = GrossOut - GrossIn is positive if the participant owes and negative if it is owed.
+type NettingPosition struct {
+	Participant Participant `json:"participant"`
+	// GrossOut is the total the participant owes the others.
+	GrossOut Money `json:"gross_out"`
+	// GrossIn is the total the others owe the participant.
+	GrossIn Money `json:"gross_in"`
+	Net     Money `json:"net"`
+	// Rounding is the adjustment of the net position by rounding, including the rounding residual.
+	Rounding Money `json:"rounding,omitempty"`
+	// Derivation writes the net position out as the sum of the obligations of the participant,
+	// e.g. "1 500 000,00 ₽ (dealership:12) − 1 200 000,00 ₽ (bank:1) = 300 000,00 ₽".
+	Derivation string `json:"derivation"`
+}
+
+// ParticipantBalance is the position of a netting participant across all its deals, by currency.
+type ParticipantBalance struct {
+	Participant Participant                   `json:"participant"`
+	Balances    []*ParticipantCurrencyBalance `json:"balances"`
+}
+
+// ParticipantCurrencyBalance is the position of a participant in one currency, computed from its stored settlements.
+type ParticipantCurrencyBalance struct {
+	CurrencyCode string `json:"currency_code"`
+	// Outstanding is what the participant still owes under pending settlements, net of partial payments.
+	Outstanding Money `json:"outstanding"`
+	// Settled is what the participant has paid: executed settlements and partial payments.
+	Settled Money `json:"settled"`
+	// PendingSettlements is the number of pending settlements of the participant.
+	PendingSettlements int `json:"pending_settlements"`
+	// Deals is the number of deals the participant has settlements in.
+	Deals int `json:"deals"`
+}
+
+// Currency represents an entry of the currency table.
+type Currency struct {
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	MinorUnit int    `json:"minor_unit"`
+	Symbol    string `json:"symbol"`
+	// Rounding is the rounding policy of net positions in the currency.
+	Rounding RoundingPolicy `json:"rounding"`
+}
+
+// Rounding modes of amounts.
+const (
+	// RoundingHalfUp rounds a half away from zero (арифметическое округление).
+	RoundingHalfUp = "half_up"
+	// RoundingHalfEven rounds a half to the even neighbour (банковское округление).
+	RoundingHalfEven = "half_even"
+)
+
+// RoundingPolicy is how net positions in a currency are rounded. Positions are rounded to Precision
+// decimal places with Mode, and the residual that keeps the positions balanced is assigned to the
+// participant of the Residual role.
+type RoundingPolicy struct {
+	Mode      string `json:"mode"`
+	Precision int    `json:"precision"`
+	Residual  string `json:"residual"`
+}
+
+// DefaultRoundingPolicy rounds to kopecks half away from zero, so the positions need no adjustment.
+var DefaultRoundingPolicy = RoundingPolicy{Mode: RoundingHalfUp, Precision: 2, Residual: ParticipantClient}
+
+// Allocation strategies for distributing an executed settlement across orders.
+const (
+	AllocationOldestFirst = "oldest_first"
+	AllocationProRata     = "pro_rata"
+)
+
+// OrderAllocation represents the part of an executed settlement allocated to an order.
+type OrderAllocation struct {
+	AllocationID         int       `json:"allocation_id"`
+	MonetarySettlementID int       `json:"monetary_settlement_id"`
+	OrderID              int       `json:"order_id"`
+	Amount               Money     `json:"amount"`
+	CreatedAt            time.Time `json:"created_at"`
 }
 
 // MonetarySettlementCreate represents a request to create a monetary settlement.
 type MonetarySettlementCreate struct {
-	DealID *int    `json:"deal_id"`
-	Amount float64 `json:"amount"`
-	BankID *int    `json:"bank_id,omitempty"`
+	DealID *int  `json:"deal_id"`
+	Amount Money `json:"amount"`
+	BankID *int  `json:"bank_id,omitempty"`
+}
+
+// Entities covered by the retention policy.
+const (
+	EntityDeals               = "deals"
+	EntityOrders              = "orders"
+	EntityMonetarySettlements = "monetary_settlements"
+)
+
+// Retention purge modes.
+const (
+	RetentionModeDelete    = "delete"
+	RetentionModeAnonymize = "anonymize"
+)
+
+// UpcomingPurge represents a soft-deleted row scheduled for purging by the retention policy.
+type UpcomingPurge struct {
+	Entity    string    `json:"entity"`
+	ID        int       `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+	Action    string    `json:"action"`
+}
+
+// PurgeResult contains the number of rows purged per entity in a retention run.
+type PurgeResult struct {
+	Mode   string           `json:"mode"`
+	Purged map[string]int64 `json:"purged"`
+}
+
+// AnonymizationRequest represents a request to anonymize a client.
+// An empty ConfirmationToken requests a new token.
+type AnonymizationRequest struct {
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// AnonymizationConfirmation contains a token that must be sent back to confirm anonymization.
+type AnonymizationConfirmation struct {
+	ClientID          int       `json:"client_id"`
+	ConfirmationToken string    `json:"confirmation_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// AnonymizationResult represents the audit entry of a performed client anonymization.
+type AnonymizationResult struct {
+	AuditID      int       `json:"audit_id"`
+	ClientID     int       `json:"client_id"`
+	AnonymizedAt time.Time `json:"anonymized_at"`
+	Fields       []string  `json:"fields"`
+}
+
+// Deal history actions.
+const (
+	DealActionCreated       = "created"
+	DealActionUpdated       = "updated"
+	DealActionStatusChanged = "status_changed"
+	DealActionDeleted       = "deleted"
+	// DealActionDeletionScheduled and DealActionRestored record the grace window of a two-phase deletion.
+	DealActionDeletionScheduled = "deletion_scheduled"
+	DealActionRestored          = "restored"
+)
+
+// DealHistoryEntry represents a recorded mutation of a deal.
+type DealHistoryEntry struct {
+	HistoryID int            `json:"history_id"`
+	DealID    int            `json:"deal_id"`
+	Action    string         `json:"action"`
+	ChangedBy string         `json:"changed_by"`
+	ChangedAt time.Time      `json:"changed_at"`
+	OldValues map[string]any `json:"old_values,omitempty"`
+	NewValues map[string]any `json:"new_values,omitempty"`
+}
+
+// AuditEntry is a record of the audit log: a state-changing request and the change it made.
+type AuditEntry struct {
+	AuditID    int64     `json:"audit_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Actor      string    `json:"actor"`
+	Method     string    `json:"method"`
+	Route      string    `json:"route"`
+	Path       string    `json:"path"`
+	Entity     string    `json:"entity,omitempty"`
+	EntityID   string    `json:"entity_id,omitempty"`
+	StatusCode int       `json:"status_code"`
+	IP         string    `json:"ip,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"`
+	// ImpersonatedClientID is the client an administrator made the request on behalf of.
+	ImpersonatedClientID *int `json:"impersonated_client_id,omitempty"`
+	// Before and After are the states of the entity around the change; nil if it did not exist.
+	Before any `json:"before,omitempty"`
+	After  any `json:"after,omitempty"`
+}
+
+// AuditFilter narrows the audit log. Only non-empty fields are applied.
+type AuditFilter struct {
+	Actor    string `form:"actor"`
+	Entity   string `form:"entity"`
+	EntityID string `form:"entity_id"`
+	Method   string `form:"method"`
+	// Impersonated selects only the requests made on behalf of an impersonated client.
+	Impersonated bool       `form:"impersonated"`
+	From         *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// AuditList represents a page of the audit log, newest entries first.
+type AuditList struct {
+	Entries []*AuditEntry `json:"entries"`
+	Page    int           `json:"page"`
+	Limit   int           `json:"limit"`
+	Total   int           `json:"total"`
+	HasNext bool          `json:"has_next"`
+}
+
+// ChangeRecord represents an entry of the change feed.
+type ChangeRecord struct {
+	ChangeID  int64          `json:"change_id"`
+	Entity    string         `json:"entity"`
+	EntityID  int            `json:"entity_id"`
+	Action    string         `json:"action"`
+	Data      map[string]any `json:"data"`
+	ChangedAt time.Time      `json:"changed_at"`
+}
+
+// ChangeFeed is a page of the change feed. NextCursor is passed as since to get the next page.
+type ChangeFeed struct {
+	Changes    []*ChangeRecord `json:"changes"`
+	NextCursor int64           `json:"next_cursor"`
+	HasMore    bool            `json:"has_more"`
+}
+
+// Client is a client of the deals. Personal data is scrubbed once the client is anonymized.
+type Client struct {
+	ClientID int    `json:"client_id"`
+	FullName string `json:"full_name"`
+	INN      string `json:"inn,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+	Email    string `json:"email,omitempty"`
+	// PassportRef references the passport scan in the document storage; the passport number is not stored.
+	PassportRef  string     `json:"passport_ref,omitempty"`
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ClientList is a page of clients.
+type ClientList struct {
+	Clients []*Client `json:"clients"`
+	Page    int       `json:"page"`
+	Limit   int       `json:"limit"`
+	Total   int       `json:"total"`
+	HasNext bool      `json:"has_next"`
+}
+
+// Dealership is a dealership of the group. Deals, orders and managers reference it by dealership_id.
+type Dealership struct {
+	DealershipID int    `json:"dealership_id"`
+	Name         string `json:"name"`
+	// LegalEntity is the legal entity the dealership concludes deals on behalf of.
+	LegalEntity string    `json:"legal_entity"`
+	Address     string    `json:"address,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreditContract is the credit contract of a deal with a bank. Credit orders of the deal reference it.
+type CreditContract struct {
+	CreditContractID int    `json:"credit_contract_id"`
+	DealID           int    `json:"deal_id"`
+	BankID           int    `json:"bank_id"`
+	ContractNumber   string `json:"contract_number"`
+	Principal        Money  `json:"principal"`
+	CurrencyCode     string `json:"currency_code"`
+	TermMonths       int    `json:"term_months"`
+	// Rate is the annual interest rate, %.
+	Rate      float64   `json:"rate"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Trade-in appraisal statuses.
+const (
+	AppraisalPending  = "pending"
+	AppraisalApproved = "approved"
+	AppraisalRejected = "rejected"
+)
+
+// TradeInAppraisal is the appraisal of a used vehicle taken in trade-in. A trade-in order is placed
+// from an approved appraisal for the appraised amount.
+type TradeInAppraisal struct {
+	AppraisalID int    `json:"appraisal_id"`
+	DealID      int    `json:"deal_id"`
+	VehicleID   int    `json:"vehicle_id"`
+	Amount      Money  `json:"amount"`
+	Appraiser   string `json:"appraiser"`
+	Status      string `json:"status"`
+	// Comment explains the approval or rejection of the appraisal.
+	Comment    string     `json:"comment,omitempty"`
+	ReviewedBy string     `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// AppraisalReview is the request body of an appraisal approval or rejection.
+type AppraisalReview struct {
+	Comment string `json:"comment"`
+}
+
+// Vehicle conditions.
+const (
+	VehicleConditionNew  = "new"
+	VehicleConditionUsed = "used"
+)
+
+// Vehicle is a vehicle sold in a deal or taken in trade-in. Purchase and trade-in orders reference it.
+type Vehicle struct {
+	VehicleID int `json:"vehicle_id"`
+	// VIN is empty for vehicles moved from the former catalog.
+	VIN   string `json:"vin,omitempty"`
+	Model string `json:"model"`
+	// Price is the list price of a sold vehicle or the appraisal of a trade-in vehicle.
+	Price     Money     `json:"price"`
+	Condition string    `json:"condition"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DealVehicle summarizes a vehicle of a deal together with the orders that reference it.
+type DealVehicle struct {
+	VehicleID int    `json:"vehicle_id"`
+	VIN       string `json:"vin,omitempty"`
+	Model     string `json:"model"`
+	Price     Money  `json:"price"`
+	Condition string `json:"condition"`
+	OrderIDs  []int  `json:"order_ids"`
+}
+
+// Manager is a manager of a dealership who handles deals.
+type Manager struct {
+	ManagerID    int       `json:"manager_id"`
+	DealershipID int       `json:"dealership_id"`
+	FullName     string    `json:"full_name"`
+	Phone        string    `json:"phone,omitempty"`
+	Email        string    `json:"email,omitempty"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ManagerFilter narrows the managers list.
+type ManagerFilter struct {
+	// DealershipID selects the managers of the dealership if set.
+	DealershipID *int `form:"dealership_id"`
+	// Active selects only the managers that accept new deals.
+	Active bool `form:"active"`
+}
+
+// DealView represents a deal together with its orders and computed settlements.
+type DealView struct {
+	Deal *Deal `json:"deal"`
+	// Client is the client of the deal; nil if the deal references no client record.
+	Client *Client `json:"client,omitempty"`
+	// Vehicles are the vehicles the purchase and trade-in orders of the deal reference.
+	Vehicles    []*DealVehicle        `json:"vehicles"`
+	Orders      []*Order              `json:"orders"`
+	Settlements []*MonetarySettlement `json:"settlements"`
+	Legs        []*SettlementLeg      `json:"legs"`
+}
+
+// DateLayout is the layout of calendar dates in API payloads.
+const DateLayout = "2006-01-02"
+
+// CalendarDay represents an exception of the business calendar: a public holiday
+// or a transferred working day on a weekend.
+type CalendarDay struct {
+	Date         string `json:"date"`
+	Name         string `json:"name"`
+	IsWorkingDay bool   `json:"is_working_day"`
+}
+
+// Netting participants.
+const (
+	ParticipantClient     = "client"
+	ParticipantBank       = "bank"
+	ParticipantDealership = "dealership"
+	// ParticipantInsurer is the insurance company of CASCO/OSAGO policies paid through the dealership.
+	ParticipantInsurer = "insurer"
+)
+
+// Participant is a party of the netting calculation: its role and, for a bank, dealership or insurer,
+// the reference to the party in that role. Several banks or dealerships of a deal are separate participants.
+type Participant struct {
+	Role string `json:"role"`
+	// ExternalID references the bank, dealership or insurer. It is set for the client only when
+	// deals of several clients are netted together.
+	ExternalID *int `json:"external_id,omitempty"`
+}
+
+// ID returns the unique participant id, e.g. "dealership:12".
+func (p Participant) ID() string {
+	if p.ExternalID == nil {
+		return p.Role
+	}
+	return p.Role + ":" + strconv.Itoa(*p.ExternalID)
+}
+
+// RefFor returns the external reference of the participant if it has the role, otherwise nil.
+func (p Participant) RefFor(role string) *int {
+	if p.Role != role {
+		return nil
+	}
+	return p.ExternalID
+}
+
+// NettingParticipant returns the netting participant the settlement belongs to.
+func (s *MonetarySettlement) NettingParticipant() Participant {
+	p := Participant{Role: s.Participant}
+	switch s.Participant {
+	case ParticipantClient:
+		p.ExternalID = s.ClientID
+	case ParticipantBank:
+		p.ExternalID = s.BankID
+	case ParticipantDealership:
+		p.ExternalID = s.DealershipID
+	case ParticipantInsurer:
+		p.ExternalID = s.InsurerID
+	}
+	return p
+}
+
+// SettlementLeg is a payment between two netting participants that settles their net positions.
+type SettlementLeg struct {
+	From             string `json:"from"`
+	FromClientID     *int   `json:"from_client_id,omitempty"`
+	FromBankID       *int   `json:"from_bank_id,omitempty"`
+	FromDealershipID *int   `json:"from_dealership_id,omitempty"`
+	FromInsurerID    *int   `json:"from_insurer_id,omitempty"`
+	To               string `json:"to"`
+	ToClientID       *int   `json:"to_client_id,omitempty"`
+	ToBankID         *int   `json:"to_bank_id,omitempty"`
+	ToDealershipID   *int   `json:"to_dealership_id,omitempty"`
+	ToInsurerID      *int   `json:"to_insurer_id,omitempty"`
+	Amount           Money  `json:"amount"`
+	CurrencyCode     string `json:"currency_code"`
+}
+
+// DealForecast projects the final net positions of a deal. Booked positions only include
+// executed orders; projected positions also include pending orders and pending credit
+// applications, which may still change or be cancelled.
+type DealForecast struct {
+	DealID       int    `json:"deal_id"`
+	CurrencyCode string `json:"currency_code"`
+	// Estimate is true when the projection depends on orders that are not executed yet.
+	Estimate  bool                `json:"estimate"`
+	Positions []*ForecastPosition `json:"positions"`
+	// Legs are the payments expected once all pending orders are executed.
+	Legs []*SettlementLeg `json:"legs"`
+}
+
+// NettingSnapshot is a stored result of the netting of a deal. A new version is stored whenever
+// the netting input (the orders of the deal or the netting rules) changes.
+type NettingSnapshot struct {
+	SnapshotID int `json:"snapshot_id"`
+	DealID     int `json:"deal_id"`
+	Version    int `json:"version"`
+	// InputHash is the SHA-256 of the netting input the snapshot was computed from.
+	InputHash string                `json:"input_hash"`
+	OrderIDs  []int                 `json:"order_ids"`
+	Positions []*MonetarySettlement `json:"positions"`
+	Legs      []*SettlementLeg      `json:"legs"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+// NettingSnapshotList is a page of netting snapshots of a deal, newest first.
+type NettingSnapshotList struct {
+	Snapshots []*NettingSnapshot `json:"snapshots"`
+	Page      int                `json:"page"`
+	Limit     int                `json:"limit"`
+	Total     int                `json:"total"`
+	HasNext   bool               `json:"has_next"`
+}
+
+// Clearing session statuses: open → computing → settled → closed.
+const (
+	ClearingSessionOpen      = "open"
+	ClearingSessionComputing = "computing"
+	ClearingSessionSettled   = "settled"
+	ClearingSessionClosed    = "closed"
+)
+
+// ClearingSession groups the orders accepted during its window. While a session is computing,
+// new orders are accepted into the next open session.
+type ClearingSession struct {
+	SessionID   int        `json:"session_id"`
+	Status      string     `json:"status"`
+	OpenedAt    time.Time  `json:"opened_at"`
+	ComputingAt *time.Time `json:"computing_at,omitempty"`
+	SettledAt   *time.Time `json:"settled_at,omitempty"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	// OrderCount is the number of orders accepted in the session.
+	OrderCount int `json:"order_count"`
+	// PendingSettlements is the number of settlements of the session not yet executed or cancelled.
+	PendingSettlements int `json:"pending_settlements"`
+	// Settlements are the settlements fixed by the session computation; set when a single session is requested.
+	Settlements []*MonetarySettlement `json:"settlements,omitempty"`
+}
+
+// ClearingSessionList is a page of clearing sessions, newest first.
+type ClearingSessionList struct {
+	Sessions []*ClearingSession `json:"sessions"`
+	Page     int                `json:"page"`
+	Limit    int                `json:"limit"`
+	Total    int                `json:"total"`
+	HasNext  bool               `json:"has_next"`
+}
+
+// ClearingCycle is the consolidated netting of all open deals of a client or of a dealership.
+// A trade-in in one deal and a purchase in another offset each other, so fewer payments are made
+// than when every deal is settled on its own.
+type ClearingCycle struct {
+	ClientID     *int `json:"client_id,omitempty"`
+	DealershipID *int `json:"dealership_id,omitempty"`
+	// DealIDs are the open (active or clearing) deals included in the cycle.
+	DealIDs []int `json:"deal_ids"`
+	// Settlements are the consolidated net positions; they are not tied to a single deal.
+	Settlements []*MonetarySettlement `json:"settlements"`
+	Legs        []*SettlementLeg      `json:"legs"`
+}
+
+// ForecastPosition is the booked and projected net position of a netting participant.
+// Positive amounts are owed by the participant, negative amounts are owed to it.
+type ForecastPosition struct {
+	Participant     string `json:"participant"`
+	DealershipID    *int   `json:"dealership_id,omitempty"`
+	BankID          *int   `json:"bank_id,omitempty"`
+	InsurerID       *int   `json:"insurer_id,omitempty"`
+	BookedAmount    Money  `json:"booked_amount"`
+	ProjectedAmount Money  `json:"projected_amount"`
+	// PendingAmount is the part of ProjectedAmount coming from orders that are not executed yet.
+	PendingAmount Money `json:"pending_amount"`
+	Estimate      bool  `json:"estimate"`
+}
+
+// NettingRule describes the obligation an order type creates: the debtor owes the order amount
+// to the creditor. Debtor and creditor are netting participants (client, bank, dealership or insurer).
+type NettingRule struct {
+	OrderTypeID int    `json:"order_type_id"`
+	Debtor      string `json:"debtor"`
+	Creditor    string `json:"creditor"`
+	// Excluded keeps the orders of the type out of netting.
+	Excluded bool `json:"excluded,omitempty"`
+	// MinAmount and MaxAmount bound the gross amount of the orders that take part in netting; nil - no bound.
+	MinAmount *Money `json:"min_amount,omitempty"`
+	MaxAmount *Money `json:"max_amount,omitempty"`
+}
+
+// Applies reports whether an order of the type with the gross amount takes part in netting.
+func (r NettingRule) Applies(amount Money) bool {
+	if r.Excluded {
+		return false
+	}
+	if r.MinAmount != nil && amount < *r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != nil && amount > *r.MaxAmount {
+		return false
+	}
+	return true
+}
+
+// NettingRuleConfig is a netting rule of an order type defined by an operator for a validity period.
+// While it is valid, it replaces the rule of the order types catalog, so business changes take effect
+// without a deploy.
+type NettingRuleConfig struct {
+	RuleID      int `json:"rule_id"`
+	OrderTypeID int `json:"order_type_id"`
+	// Debtor and Creditor override the participants of the catalog rule; both empty keep them.
+	Debtor    string `json:"debtor,omitempty"`
+	Creditor  string `json:"creditor,omitempty"`
+	Excluded  bool   `json:"excluded"`
+	MinAmount *Money `json:"min_amount,omitempty"`
+	MaxAmount *Money `json:"max_amount,omitempty"`
+	// ValidFrom and ValidTo are the first and the last day of validity in DateLayout; an empty ValidTo never expires.
+	ValidFrom string    `json:"valid_from"`
+	ValidTo   string    `json:"valid_to,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Apply returns the catalog rule with the overrides of the configuration.
+func (c *NettingRuleConfig) Apply(rule NettingRule) NettingRule {
+	if c.Debtor != "" {
+		rule.Debtor, rule.Creditor = c.Debtor, c.Creditor
+	}
+	rule.Excluded = c.Excluded
+	rule.MinAmount, rule.MaxAmount = c.MinAmount, c.MaxAmount
+	return rule
+}
+
+// Kinds of order types: deal orders, dealership fees and contractual penalties.
+const (
+	OrderKindOrder   = "order"
+	OrderKindFee     = "fee"
+	OrderKindPenalty = "penalty"
+)
+
+// Order types of the catalog with type-specific references: purchase and trade-in orders reference
+// a vehicle of the deal, credit orders a credit contract.
+const (
+	OrderTypePurchase = 1
+	OrderTypeCredit   = 2
+	OrderTypeTradeIn  = 3
+)
+
+// OrderType is an entry of the order types catalog together with the netting rule of the type.
+type OrderType struct {
+	OrderTypeID int    `json:"order_type_id"`
+	Name        string `json:"name"`
+	Debtor      string `json:"debtor"`
+	Creditor    string `json:"creditor"`
+	// Kind is one of the OrderKind constants; empty means OrderKindOrder.
+	Kind string `json:"kind"`
+}
+
+// NettingRule returns the obligation an order of the type creates.
+func (t *OrderType) NettingRule() NettingRule {
+	return NettingRule{OrderTypeID: t.OrderTypeID, Debtor: t.Debtor, Creditor: t.Creditor}
+}
+
+// Events of obligation triggers.
+const (
+	// TriggerDealCancelled fires when a deal is cancelled at least DelayDays after its creation.
+	TriggerDealCancelled = "deal_cancelled"
+	// TriggerDealOpen fires when a deal is still draft or active DelayDays after its creation.
+	TriggerDealOpen = "deal_open"
+)
+
+// ObligationTrigger generates a fee or penalty order in a deal when the event occurs.
+// A trigger fires at most once per deal; the generated order is netted like any other order.
+type ObligationTrigger struct {
+	TriggerID   int    `json:"trigger_id"`
+	OrderTypeID int    `json:"order_type_id"`
+	Event       string `json:"event"`
+	// DelayDays is the number of calendar days since the creation of the deal.
+	DelayDays int `json:"delay_days"`
+	// Amount is the amount of the generated order without VAT.
+	Amount       Money     `json:"amount"`
+	CurrencyCode string    `json:"currency_code"`
+	VATRate      float64   `json:"vat_rate"`
+	Active       bool      `json:"active"`
+	Comment      string    `json:"comment,omitempty"`
+	CreatedBy    string    `json:"created_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Due reports whether the trigger fires for the deal at the moment.
+func (t *ObligationTrigger) Due(deal *Deal, at time.Time) bool {
+	if !t.Active {
+		return false
+	}
+	switch t.Event {
+	case TriggerDealCancelled:
+		return deal.Status == DealStatusCancelled && !deal.UpdatedAt.Before(deal.CreatedAt.AddDate(0, 0, t.DelayDays))
+	case TriggerDealOpen:
+		return (deal.Status == DealStatusDraft || deal.Status == DealStatusActive) &&
+			!at.Before(deal.CreatedAt.AddDate(0, 0, t.DelayDays))
+	}
+	return false
+}
+
+// SimulationOrder is a hypothetical order of a netting simulation.
+type SimulationOrder struct {
+	OrderTypeID  int     `json:"order_type_id"`
+	Amount       Money   `json:"amount"`
+	BankID       *int    `json:"bank_id,omitempty"`
+	DealershipID *int    `json:"dealership_id,omitempty"`
+	InsurerID    *int    `json:"insurer_id,omitempty"`
+	CurrencyCode string  `json:"currency_code,omitempty"`
+	Discount     Money   `json:"discount,omitempty"`
+	VATRate      float64 `json:"vat_rate,omitempty"`
+}
+
+// SimulationRequest is a netting scenario: hypothetical orders and optional overrides of the
+// netting rules, e.g. for an order type of a financing product that does not exist yet.
+type SimulationRequest struct {
+	Orders []SimulationOrder `json:"orders"`
+	Rules  []NettingRule     `json:"rules,omitempty"`
+}
+
+// SimulationResult is the outcome of a netting simulation. Nothing is persisted.
+type SimulationResult struct {
+	// Rules are the effective netting rules, defaults merged with the overrides.
+	Rules       []NettingRule         `json:"rules"`
+	Settlements []*MonetarySettlement `json:"settlements"`
+	// Legs is the transfer plan settling the net positions.
+	Legs []*SettlementLeg `json:"legs"`
+}
+
+// Integration names.
+const (
+	IntegrationObjectStorage = "object_storage"
+	IntegrationWebhooks      = "webhooks"
+	IntegrationCBRRates      = "cbr_rates"
+)
+
+// Integration statuses.
+const (
+	IntegrationUp       = "up"
+	IntegrationDegraded = "degraded"
+	IntegrationDown     = "down"
+)
+
+// IntegrationStatus reports the health of an external system the service calls.
+type IntegrationStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Reachable is the result of an active probe; absent if the integration cannot be probed.
+	Reachable  *bool  `json:"reachable,omitempty"`
+	ProbeError string `json:"probe_error,omitempty"`
+	// Calls and Failures are counted since the service started.
+	Calls    int64 `json:"calls"`
+	Failures int64 `json:"failures"`
+	// ErrorRate is the share of failed calls among the recent ones.
+	ErrorRate     float64    `json:"error_rate"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	CheckedAt     time.Time  `json:"checked_at"`
+}
+
+// InterBranchLeg is the aggregated amount one dealership owes another within the dealership group.
+type InterBranchLeg struct {
+	FromDealershipID int    `json:"from_dealership_id"`
+	ToDealershipID   int    `json:"to_dealership_id"`
+	Amount           Money  `json:"amount"`
+	CurrencyCode     string `json:"currency_code"`
+	DealIDs          []int  `json:"deal_ids"`
+}
+
+// InterBranchReport lists inter-branch settlement legs of deals created in the period.
+type InterBranchReport struct {
+	From string            `json:"from"`
+	To   string            `json:"to"`
+	Legs []*InterBranchLeg `json:"legs"`
+}
+
+// SettlementTotal is the number and amount of the settlements of one group of a settlement report.
+// Only the field the group is keyed by is set among Status, BankID and OrderTypeID.
+type SettlementTotal struct {
+	Status       string `json:"status,omitempty"`
+	BankID       *int   `json:"bank_id,omitempty"`
+	OrderTypeID  *int   `json:"order_type_id,omitempty"`
+	CurrencyCode string `json:"currency_code"`
+	Count        int    `json:"count"`
+	Amount       Money  `json:"amount"`
+}
+
+// SettlementReport aggregates the stored settlements created in the period for the deals of a dealership.
+type SettlementReport struct {
+	DealershipID int                `json:"dealership_id"`
+	From         string             `json:"from"`
+	To           string             `json:"to"`
+	ByStatus     []*SettlementTotal `json:"by_status"`
+	ByBank       []*SettlementTotal `json:"by_bank"`
+	// ByOrderType splits the executed settlements by the order types they were allocated to.
+	ByOrderType []*SettlementTotal `json:"by_order_type"`
+}
+
+// Price enforcement modes for catalog validation of purchase orders.
+const (
+	PriceEnforcementOff   = "off"
+	PriceEnforcementWarn  = "warn"
+	PriceEnforcementError = "error"
+)
+
+// PriceViolation describes a purchase order amount outside the allowed range around the list price.
+type PriceViolation struct {
+	VehicleID int    `json:"vehicle_id"`
+	ListPrice Money  `json:"list_price"`
+	MinAmount Money  `json:"min_amount"`
+	MaxAmount Money  `json:"max_amount"`
+	Amount    Money  `json:"amount"`
+	Message   string `json:"message"`
+}
+
+// DealDocument represents the metadata of a file attached to a deal.
+type DealDocument struct {
+	DocumentID  int       `json:"document_id"`
+	DealID      int       `json:"deal_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	StorageKey  string    `json:"-"`
+	UploadedBy  string    `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Kinds of database constraint violations.
+const (
+	ConstraintUnique     = "unique"
+	ConstraintForeignKey = "foreign_key"
+	ConstraintCheck      = "check"
+	ConstraintNotNull    = "not_null"
+)
+
+// ConstraintError describes a database constraint violation caused by the request data.
+type ConstraintError struct {
+	Kind       string `json:"kind"`
+	Table      string `json:"table,omitempty"`
+	Column     string `json:"column,omitempty"`
+	Constraint string `json:"constraint,omitempty"`
+	Err        error  `json:"-"`
+}
+
+// BatchItemError is returned when an item of a batch request fails; the whole batch is rolled back.
+type BatchItemError struct {
+	Index int   `json:"index"`
+	Err   error `json:"-"`
+}
+
+// Error implements the error interface.
+func (e *BatchItemError) Error() string {
+	return "item " + strconv.Itoa(e.Index) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error of the failed item.
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+// Error implements the error interface.
+func (e *ConstraintError) Error() string {
+	return e.Kind + " constraint " + e.Constraint + " violated on " + e.Table + "." + e.Column
+}
+
+// Unwrap returns the underlying driver error.
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// Readiness statuses.
+const (
+	ReadinessReady    = "ready"
+	ReadinessNotReady = "not_ready"
+)
+
+// Readiness is the response of the readiness probe.
+type Readiness struct {
+	Status string `json:"status"`
+	// SchemaVersion is the database schema version applied at startup.
+	SchemaVersion int32 `json:"schema_version"`
+}
+
+// LivenessAlive is the status of the liveness probe.
+const LivenessAlive = "alive"
+
+// Liveness is the response of the liveness probe.
+type Liveness struct {
+	Status string `json:"status"`
+}
+
+// TokenInfo identifies the bearer token of a request for revocation.
+type TokenInfo struct {
+	// ID is the jti claim; tokens without it cannot be revoked.
+	ID        string
+	ExpiresAt *time.Time
+}
+
+// TokenRevocation is a revoked token. Requests with the token are rejected until it expires.
+type TokenRevocation struct {
+	// JTI is the token to revoke; empty revokes the token of the request itself.
+	JTI string `json:"jti,omitempty"`
+	// ExpiresAt is the expiry of the token; the revocation is kept until then.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	RevokedBy string     `json:"revoked_by,omitempty"`
+	RevokedAt time.Time  `json:"revoked_at"`
+}
+
+// Tenant is a dealership group whose data is isolated in its own database schema.
+type Tenant struct {
+	// TenantID is the value of the tenant_id claim of the group's tokens.
+	TenantID  string    `json:"tenant_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	// ProvisionedAt is nil until the schema is created and migrated.
+	ProvisionedAt *time.Time `json:"provisioned_at,omitempty"`
+}
+
+// IdempotencyRecord is the stored outcome of a request made with an Idempotency-Key header.
+type IdempotencyRecord struct {
+	RequestHash string
+	// StatusCode is nil while the first request with the key is still in progress.
+	StatusCode *int
+	Body       []byte
 }
@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in kopecks (hundredths of the currency unit). Amount columns are numeric(15,2),
+// so every stored amount is exact, and sums of amounts do not drift the way float64 sums do.
+// In JSON an amount is a number with up to two decimal places, e.g. 1234.5.
+type Money int64
+
+// moneyScale is the number of kopecks in a currency unit.
+const moneyScale = 100
+
+// ParseMoney parses a decimal amount such as "1234.56". More than two decimal places are rejected.
+func ParseMoney(s string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	r.Mul(r, big.NewRat(moneyScale, 1))
+	if !r.IsInt() {
+		return 0, fmt.Errorf("amount %q has more than two decimal places", s)
+	}
+	if !r.Num().IsInt64() {
+		return 0, fmt.Errorf("amount %q is out of range", s)
+	}
+	return Money(r.Num().Int64()), nil
+}
+
+// MoneyFromFloat converts a float amount, e.g. from configuration, rounding it to kopecks.
+func MoneyFromFloat(amount float64) Money {
+	return Money(math.Round(amount * moneyScale))
+}
+
+// Kopecks returns the amount in minor units.
+func (m Money) Kopecks() int64 {
+	return int64(m)
+}
+
+// Abs returns the absolute amount.
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// MulRatio returns m*num/den rounded half away from zero to kopecks. The product is computed
+// without overflow, so it is safe for proportional splits of large amounts.
+func (m Money) MulRatio(num, den Money) Money {
+	r := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(int64(num))), big.NewInt(int64(den)))
+	return ratToMoney(r)
+}
+
+// Percent returns percent percents of m rounded to kopecks.
+func (m Money) Percent(percent float64) Money {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(percent, 'f', -1, 64))
+	if !ok {
+		return 0
+	}
+	return ratToMoney(r.Mul(r, big.NewRat(int64(m), 100)))
+}
+
+// Convert returns m converted to another currency at the exchange rate, rounded to kopecks.
+func (m Money) Convert(rate float64) Money {
+	return m.ConvertRounded(rate, RoundingHalfUp)
+}
+
+// ConvertRounded returns m converted to another currency at the exchange rate, rounded to kopecks
+// with the rounding mode.
+func (m Money) ConvertRounded(rate float64, mode string) Money {
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	if !ok {
+		return 0
+	}
+	r.Mul(r, big.NewRat(int64(m), 1))
+	return Money(roundQuo(r.Num(), r.Denom(), mode).Int64())
+}
+
+// Round returns m rounded to precision decimal places (0 to 2) with the rounding mode.
+func (m Money) Round(precision int, mode string) Money {
+	if precision >= 2 {
+		return m
+	}
+	unit := big.NewInt(int64(math.Pow10(2 - precision)))
+	q := roundQuo(big.NewInt(int64(m)), unit, mode)
+	return Money(q.Mul(q, unit).Int64())
+}
+
+// ratToMoney rounds a kopeck amount half away from zero.
+func ratToMoney(r *big.Rat) Money {
+	return Money(roundQuo(r.Num(), r.Denom(), RoundingHalfUp).Int64())
+}
+
+// roundQuo returns num/den (den > 0) rounded to an integer with the rounding mode:
+// a half goes away from zero for RoundingHalfUp and to the even neighbour for RoundingHalfEven.
+func roundQuo(num, den *big.Int, mode string) *big.Int {
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	// Сравнение удвоенного остатка с делителем: меньше - вниз по модулю, больше - от нуля, равно - половина
+	cmp := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(den)
+	if cmp > 0 || (cmp == 0 && (mode != RoundingHalfEven || q.Bit(0) == 1)) {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// String formats the amount with two decimal places, e.g. "-1234.50".
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign = "-"
+	}
+	abs := uint64(m.Abs())
+	return fmt.Sprintf("%s%d.%02d", sign, abs/moneyScale, abs%moneyScale)
+}
+
+// MarshalJSON encodes the amount as a JSON number.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON decodes the amount from a JSON number or a string holding a number.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	amount, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = amount
+	return nil
+}
+
+// UnmarshalText decodes the amount from configuration values.
+func (m *Money) UnmarshalText(text []byte) error {
+	amount, err := ParseMoney(string(text))
+	if err != nil {
+		return err
+	}
+	*m = amount
+	return nil
+}
+
+// UnmarshalParam decodes the amount from query parameters bound by gin.
+func (m *Money) UnmarshalParam(param string) error {
+	return m.UnmarshalText([]byte(param))
+}
+
+// Scan reads the amount from a numeric column.
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return m.UnmarshalText([]byte(v))
+	case []byte:
+		return m.UnmarshalText(v)
+	case int64:
+		*m = Money(v * moneyScale)
+		return nil
+	case nil:
+		return fmt.Errorf("cannot scan NULL into Money")
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+}
+
+// Value writes the amount to a numeric column.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
@@ -0,0 +1,54 @@
+package netting
+
+import (
+	"errors"
+	"fmt"
+
+	"cliring/internal/domain"
+)
+
+// ErrMissingRate is returned when an order currency has no exchange rate to the pivot currency.
+var ErrMissingRate = errors.New("missing exchange rate")
+
+// MixedCurrencies reports whether the orders are in more than one currency.
+func MixedCurrencies(orders []*domain.Order) bool {
+	for _, order := range orders {
+		if order.CurrencyCode != orders[0].CurrencyCode {
+			return true
+		}
+	}
+	return false
+}
+
+// ConvertOrders converts the amounts of the orders to the pivot currency with the rates indexed
+// by currency code, so that obligations in different currencies can be netted together. Converted amounts
+// are rounded to kopecks with the rounding mode of the pivot currency. It returns converted copies of
+// the orders and the rates used, in the order their currencies first appear.
+func ConvertOrders(orders []*domain.Order, pivot string, rates map[string]*domain.FXRate, rounding domain.RoundingPolicy) ([]*domain.Order, []*domain.FXRate, error) {
+	converted := make([]*domain.Order, 0, len(orders))
+	used := map[string]bool{}
+	var usedRates []*domain.FXRate
+	for _, order := range orders {
+		if order.CurrencyCode == pivot {
+			converted = append(converted, order)
+			continue
+		}
+		rate, ok := rates[order.CurrencyCode]
+		if !ok {
+			return nil, nil, fmt.Errorf("%s to %s: %w", order.CurrencyCode, pivot, ErrMissingRate)
+		}
+		if !used[order.CurrencyCode] {
+			used[order.CurrencyCode] = true
+			usedRates = append(usedRates, rate)
+		}
+
+		// НДС пересчитывается отдельно, сумма с НДС складывается заново, чтобы разбивка по ставкам сходилась
+		copied := *order
+		copied.CurrencyCode = pivot
+		copied.NetAmount = order.NetAmount.ConvertRounded(rate.Rate, rounding.Mode)
+		copied.VATAmount = order.VATAmount.ConvertRounded(rate.Rate, rounding.Mode)
+		copied.GrossAmount = copied.NetAmount + copied.VATAmount
+		converted = append(converted, &copied)
+	}
+	return converted, usedRates, nil
+}
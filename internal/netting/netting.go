@@ -0,0 +1,338 @@
+// Package netting implements the multilateral netting of deal orders. It is pure: the engine
+// works on the orders and the netting rules it is given and does not touch the database.
+package netting
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"cliring/internal/domain"
+)
+
+// ErrUnknownOrderType is returned when an order references an order type without a netting rule.
+var ErrUnknownOrderType = errors.New("unknown order type")
+
+// Engine nets orders according to the netting rules of their order types. The rules decide
+// who owes whom, so a new order type only needs a rule.
+type Engine struct {
+	rules map[int]domain.NettingRule
+	// dealClients maps deal_id to client_id when deals of several clients are netted together.
+	dealClients map[int]int
+	// rounding is the rounding policy of the net positions; nil keeps them in kopecks.
+	rounding *domain.RoundingPolicy
+	// penalties are the overdue penalties netted together with the orders.
+	penalties []*domain.SettlementPenalty
+}
+
+// NewEngine creates an engine with the netting rules indexed by order_type_id.
+func NewEngine(rules map[int]domain.NettingRule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// WithDealClients makes the client of every deal a separate participant, identified by client_id.
+// It is used when orders of several deals are netted together.
+func (e *Engine) WithDealClients(dealClients map[int]int) *Engine {
+	e.dealClients = dealClients
+	return e
+}
+
+// WithRounding makes the engine round the net positions with the rounding policy of their currency.
+func (e *Engine) WithRounding(policy domain.RoundingPolicy) *Engine {
+	e.rounding = &policy
+	return e
+}
+
+// WithPenalties nets the overdue penalties together with the orders. A penalty is owed to the
+// participants the orders leave owed, in proportion to their positions; it is left out if no one else is owed.
+func (e *Engine) WithPenalties(penalties []*domain.SettlementPenalty) *Engine {
+	e.penalties = penalties
+	return e
+}
+
+// Result holds the net positions of the participants: Net[i] = sum(a_ij) - sum(a_ji) is positive
+// if Participants[i] owes and negative if it is owed. The client of a single deal is always the first
+// participant, the others follow in the order they first appear in the orders.
+type Result struct {
+	Participants []domain.Participant
+	Net          []domain.Money
+	// VATBreakdowns[i] splits Net[i] by VAT rate.
+	VATBreakdowns [][]*domain.VATBreakdown
+	// Edges are the gross obligations between the participants before netting,
+	// in the order they first appear in the orders.
+	Edges []*Edge
+	// Rounding[i] is the adjustment of Net[i] by rounding, including the residual.
+	Rounding []domain.Money
+	// Residual is the amount assigned to Participants[ResidualIndex] so that the rounded positions
+	// sum up to zero; ResidualIndex is -1 if the positions needed no residual.
+	Residual      domain.Money
+	ResidualIndex int
+}
+
+// Edge is the total a debtor owes a creditor over all orders, i.e. an edge of the obligation matrix.
+type Edge struct {
+	Debtor   domain.Participant
+	Creditor domain.Participant
+	Amount   domain.Money
+	// VATBreakdown splits Amount by VAT rate.
+	VATBreakdown []*domain.VATBreakdown
+	// OrderIDs are the orders that make up the obligation.
+	OrderIDs []int
+	// PenaltyIDs are the overdue penalties that make up the obligation.
+	PenaltyIDs []int
+}
+
+// edgeID returns the key of the obligation edge in the VAT positions.
+func edgeID(pair [2]string) string {
+	return pair[0] + ">" + pair[1]
+}
+
+// Net builds the obligation matrix of the orders and returns the net position of every participant.
+func (e *Engine) Net(orders []*domain.Order) (*Result, error) {
+	// Участники: Клиент, каждый банк, страховая компания и дилерский центр, упомянутые в заказах
+	result := &Result{}
+	index := map[string]int{}
+	if e.dealClients == nil {
+		client := domain.Participant{Role: domain.ParticipantClient}
+		result.Participants = append(result.Participants, client)
+		index[client.ID()] = 0
+	}
+	addParticipant := func(p domain.Participant) {
+		if _, ok := index[p.ID()]; !ok {
+			index[p.ID()] = len(result.Participants)
+			result.Participants = append(result.Participants, p)
+		}
+	}
+
+	// edges[{from, to}] - сумма, которую участник from должен участнику to
+	edges := map[[2]string]*Edge{}
+	vat := vatPositions{}
+	addEdge := func(debtor, creditor domain.Participant, amount domain.Money, vatRate float64, net, vatAmount domain.Money) *Edge {
+		addParticipant(debtor)
+		addParticipant(creditor)
+		pair := [2]string{debtor.ID(), creditor.ID()}
+		if _, ok := edges[pair]; !ok {
+			edges[pair] = &Edge{Debtor: debtor, Creditor: creditor}
+			result.Edges = append(result.Edges, edges[pair])
+		}
+		edges[pair].Amount += amount
+		vat.add(debtor.ID(), vatRate, net, vatAmount)
+		vat.add(creditor.ID(), vatRate, -net, -vatAmount)
+		vat.add(edgeID(pair), vatRate, net, vatAmount)
+		return edges[pair]
+	}
+	for _, order := range orders {
+		if _, ok := e.rules[order.OrderTypeID]; !ok {
+			return nil, fmt.Errorf("order_type_id %d: %w", order.OrderTypeID, ErrUnknownOrderType)
+		}
+		debtor, creditor, ok := e.Obligation(order)
+		if !ok {
+			continue
+		}
+		// В неттинге участвует сумма заказа с НДС
+		edge := addEdge(debtor, creditor, order.GrossAmount, order.VATRate, order.NetAmount, order.VATAmount)
+		edge.OrderIDs = append(edge.OrderIDs, order.OrderID)
+	}
+	if len(e.penalties) > 0 {
+		owed := map[string]domain.Money{}
+		for _, edge := range result.Edges {
+			owed[edge.Debtor.ID()] += edge.Amount
+			owed[edge.Creditor.ID()] -= edge.Amount
+		}
+		for _, penalty := range e.penalties {
+			e.addPenalty(penalty, result.Participants, owed, addEdge)
+		}
+	}
+	for pair, edge := range edges {
+		edge.VATBreakdown = vat.breakdown(edgeID(pair))
+	}
+
+	result.Net = make([]domain.Money, len(result.Participants))
+	for _, edge := range result.Edges {
+		result.Net[index[edge.Debtor.ID()]] += edge.Amount
+		result.Net[index[edge.Creditor.ID()]] -= edge.Amount
+	}
+	result.round(e.rounding)
+	result.VATBreakdowns = make([][]*domain.VATBreakdown, len(result.Participants))
+	for i, p := range result.Participants {
+		result.VATBreakdowns[i] = vat.breakdown(p.ID())
+	}
+
+	return result, nil
+}
+
+// addPenalty splits a penalty between the participants owed by the orders in proportion to their positions;
+// the rounding remainder goes to the last of them. Penalties are not subject to VAT.
+func (e *Engine) addPenalty(penalty *domain.SettlementPenalty, participants []domain.Participant, owed map[string]domain.Money,
+	addEdge func(debtor, creditor domain.Participant, amount domain.Money, vatRate float64, net, vat domain.Money) *Edge) {
+	debtor := penalty.Debtor
+	if debtor.Role == domain.ParticipantClient && e.dealClients != nil {
+		clientID, ok := e.dealClients[penalty.DealID]
+		if !ok {
+			return
+		}
+		debtor.ExternalID = &clientID
+	}
+
+	var creditors []domain.Participant
+	var total domain.Money
+	for _, p := range participants {
+		if owed[p.ID()] < 0 && p.ID() != debtor.ID() {
+			creditors = append(creditors, p)
+			total -= owed[p.ID()]
+		}
+	}
+
+	remaining := penalty.Amount
+	for i, creditor := range creditors {
+		part := penalty.Amount.MulRatio(-owed[creditor.ID()], total)
+		if i == len(creditors)-1 {
+			part = remaining
+		}
+		remaining -= part
+		if part == 0 {
+			continue
+		}
+		edge := addEdge(debtor, creditor, part, 0, part, 0)
+		edge.PenaltyIDs = append(edge.PenaltyIDs, penalty.PenaltyID)
+	}
+}
+
+// round rounds the net positions with the policy and assigns the residual to the first participant
+// of the residual role, or to the first participant if no participant has the role.
+func (r *Result) round(policy *domain.RoundingPolicy) {
+	r.Rounding = make([]domain.Money, len(r.Net))
+	r.ResidualIndex = -1
+	if policy == nil {
+		return
+	}
+
+	var sum domain.Money
+	for i, amount := range r.Net {
+		rounded := amount.Round(policy.Precision, policy.Mode)
+		r.Rounding[i] = rounded - amount
+		r.Net[i] = rounded
+		sum += rounded
+	}
+	if sum == 0 {
+		return
+	}
+
+	// Сумма чистых позиций должна оставаться нулевой: остаток относится на назначенного участника
+	r.ResidualIndex = 0
+	for i, p := range r.Participants {
+		if p.Role == policy.Residual {
+			r.ResidualIndex = i
+			break
+		}
+	}
+	r.Residual = -sum
+	r.Net[r.ResidualIndex] += r.Residual
+	r.Rounding[r.ResidualIndex] += r.Residual
+}
+
+// Obligation resolves the debtor and creditor of an order by its netting rule.
+// It reports false if the order does not take part in netting.
+func (e *Engine) Obligation(order *domain.Order) (domain.Participant, domain.Participant, bool) {
+	// Заказ на антифрод-проверке и заказ на кредит без кредитного договора не участвуют в неттинге
+	if order.HeldForReview() || order.AwaitingCreditContract() {
+		return domain.Participant{}, domain.Participant{}, false
+	}
+	rule, ok := e.rules[order.OrderTypeID]
+	if !ok || !rule.Applies(order.GrossAmount) {
+		return domain.Participant{}, domain.Participant{}, false
+	}
+	debtor, ok := e.orderParticipant(rule.Debtor, order)
+	if !ok {
+		return domain.Participant{}, domain.Participant{}, false
+	}
+	creditor, ok := e.orderParticipant(rule.Creditor, order)
+	if !ok {
+		return domain.Participant{}, domain.Participant{}, false
+	}
+	return debtor, creditor, true
+}
+
+// orderParticipant resolves a participant role of a netting rule for an order.
+// A bank or insurer participant is only known if the order references a bank or an insurer.
+func (e *Engine) orderParticipant(role string, order *domain.Order) (domain.Participant, bool) {
+	switch role {
+	case domain.ParticipantClient:
+		if e.dealClients != nil {
+			clientID, ok := e.dealClients[order.DealID]
+			return domain.Participant{Role: role, ExternalID: &clientID}, ok
+		}
+		return domain.Participant{Role: role}, true
+	case domain.ParticipantBank:
+		return domain.Participant{Role: role, ExternalID: order.BankID}, order.BankID != nil
+	case domain.ParticipantDealership:
+		return domain.Participant{Role: role, ExternalID: order.DealershipID}, true
+	case domain.ParticipantInsurer:
+		return domain.Participant{Role: role, ExternalID: order.InsurerID}, order.InsurerID != nil
+	default:
+		return domain.Participant{}, false
+	}
+}
+
+// Legs turns the net positions into a minimal set of pairwise transfers (greedy min-cash-flow):
+// the largest remaining debtor pays the largest remaining creditor until all positions are settled.
+// Every transfer settles at least one participant, so n participants need at most n-1 transfers.
+// Ties go to the participant that comes first, so the client pays before the others.
+func (r *Result) Legs(currencyCode string) []*domain.SettlementLeg {
+	remaining := append([]domain.Money(nil), r.Net...)
+	var legs []*domain.SettlementLeg
+	for {
+		d, c := -1, -1
+		for i, amount := range remaining {
+			if amount > 0 && (d < 0 || amount > remaining[d]) {
+				d = i
+			}
+			if amount < 0 && (c < 0 || amount < remaining[c]) {
+				c = i
+			}
+		}
+		if d < 0 || c < 0 {
+			return legs
+		}
+
+		amount := min(remaining[d], -remaining[c])
+		legs = append(legs, newLeg(r.Participants[d], r.Participants[c], amount, currencyCode))
+		remaining[d] -= amount
+		remaining[c] += amount
+	}
+}
+
+// Leg returns the payment that settles the obligation without netting.
+func (e *Edge) Leg(currencyCode string) *domain.SettlementLeg {
+	return newLeg(e.Debtor, e.Creditor, e.Amount, currencyCode)
+}
+
+// newLeg returns a payment of the amount from one participant to another.
+func newLeg(from, to domain.Participant, amount domain.Money, currencyCode string) *domain.SettlementLeg {
+	return &domain.SettlementLeg{
+		From:             from.Role,
+		FromClientID:     from.RefFor(domain.ParticipantClient),
+		FromBankID:       from.RefFor(domain.ParticipantBank),
+		FromDealershipID: from.RefFor(domain.ParticipantDealership),
+		FromInsurerID:    from.RefFor(domain.ParticipantInsurer),
+		To:               to.Role,
+		ToClientID:       to.RefFor(domain.ParticipantClient),
+		ToBankID:         to.RefFor(domain.ParticipantBank),
+		ToDealershipID:   to.RefFor(domain.ParticipantDealership),
+		ToInsurerID:      to.RefFor(domain.ParticipantInsurer),
+		Amount:           amount,
+		CurrencyCode:     currencyCode,
+	}
+}
+
+// ExecutableOrders returns the orders that are not planned for execution after at.
+func ExecutableOrders(orders []*domain.Order, at time.Time) []*domain.Order {
+	executable := make([]*domain.Order, 0, len(orders))
+	for _, order := range orders {
+		if order.ExecuteAt != nil && order.ExecuteAt.After(at) {
+			continue
+		}
+		executable = append(executable, order)
+	}
+	return executable
+}
@@ -0,0 +1,50 @@
+package netting
+
+import (
+	"sort"
+
+	"cliring/internal/domain"
+)
+
+// vatPosition accumulates the net amount and VAT of a participant's net position at one VAT rate.
+type vatPosition struct {
+	net, vat domain.Money
+}
+
+// vatPositions holds the VAT positions of the participants by participant id and VAT rate.
+// Positions are signed the same way as the net positions, so the parts of a participant
+// add up to its net position.
+type vatPositions map[string]map[float64]*vatPosition
+
+// add adds the net amount and VAT of an order at the rate to the position of the participant.
+func (v vatPositions) add(participantID string, rate float64, net, vat domain.Money) {
+	if v[participantID] == nil {
+		v[participantID] = map[float64]*vatPosition{}
+	}
+	position, ok := v[participantID][rate]
+	if !ok {
+		position = &vatPosition{}
+		v[participantID][rate] = position
+	}
+	position.net += net
+	position.vat += vat
+}
+
+// breakdown returns the non-zero VAT positions of the participant ordered by VAT rate.
+func (v vatPositions) breakdown(participantID string) []*domain.VATBreakdown {
+	var breakdown []*domain.VATBreakdown
+	for rate, position := range v[participantID] {
+		if position.net == 0 && position.vat == 0 {
+			continue
+		}
+		breakdown = append(breakdown, &domain.VATBreakdown{
+			VATRate:   rate,
+			NetAmount: position.net,
+			VATAmount: position.vat,
+		})
+	}
+	sort.Slice(breakdown, func(a, b int) bool {
+		return breakdown[a].VATRate < breakdown[b].VATRate
+	})
+	return breakdown
+}
@@ -0,0 +1,186 @@
+// Package pain001 generates ISO 20022 pain.001.001.03 (CustomerCreditTransferInitiation) payment files
+// from the payment instructions of executed settlements, to be loaded into corporate banking.
+// A file holds the payments of one debtor bank, with a payment information block per debtor account.
+package pain001
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+)
+
+// Namespace is the XML namespace of pain.001.001.03.
+const Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+
+// Limits of the Max35Text, Max140Text and Max34Text types of the schema.
+const (
+	maxIDLength      = 35
+	maxTextLength    = 140
+	maxAccountLength = 34
+)
+
+// russianClearingSystem identifies Russian BIC (БИК) codes, which are not SWIFT BICs and are given
+// as clearing system member IDs.
+const russianClearingSystem = "RUCBC"
+
+var (
+	// ErrIncomplete is returned for an instruction without the bank details a transfer requires.
+	ErrIncomplete = errors.New("incomplete payment instruction")
+	// ErrMixedBanks is returned when the instructions of one file are paid from accounts in different banks.
+	ErrMixedBanks = errors.New("payments of one file must be made from one bank")
+)
+
+// swiftBICPattern matches a SWIFT BIC (BICIdentifier of the schema).
+var swiftBICPattern = regexp.MustCompile(`^[A-Z]{6}[A-Z2-9][A-NP-Z0-9]([A-Z0-9]{3})?$`)
+
+// File describes a payment file.
+type File struct {
+	// MessageID identifies the file at the bank and must be unique per initiating party.
+	MessageID string
+	CreatedAt time.Time
+	// InitiatingParty is the name of the organization sending the file.
+	InitiatingParty string
+	// ExecutionDate is the requested execution date in domain.DateLayout.
+	ExecutionDate string
+	Instructions  []*domain.PaymentInstruction
+}
+
+// Generate renders the file as pain.001.001.03 XML. All instructions must be complete and paid from one bank.
+func Generate(file File) ([]byte, error) {
+	if file.MessageID == "" || utf8.RuneCountInString(file.MessageID) > maxIDLength {
+		return nil, fmt.Errorf("message id must have 1 to %d characters", maxIDLength)
+	}
+	if len(file.Instructions) == 0 {
+		return nil, errors.New("a payment file requires at least one instruction")
+	}
+	if _, err := time.Parse(domain.DateLayout, file.ExecutionDate); err != nil {
+		return nil, fmt.Errorf("invalid execution date %q", file.ExecutionDate)
+	}
+
+	document := document{
+		Namespace: Namespace,
+		Initiation: initiation{
+			Header: groupHeader{
+				MessageID:       file.MessageID,
+				CreatedAt:       file.CreatedAt.Format("2006-01-02T15:04:05"),
+				InitiatingParty: party{Name: text(file.InitiatingParty, maxTextLength)},
+			},
+		},
+	}
+
+	// Платежи группируются по счету плательщика в порядке первого появления
+	blocks := map[string]*paymentInformation{}
+	var total domain.Money
+	for _, instruction := range file.Instructions {
+		if err := validate(instruction); err != nil {
+			return nil, err
+		}
+		if instruction.Payer.BIC != file.Instructions[0].Payer.BIC {
+			return nil, ErrMixedBanks
+		}
+
+		block, ok := blocks[instruction.Payer.BankAccount]
+		if !ok {
+			block = &paymentInformation{
+				ID:            text(fmt.Sprintf("%s-%d", file.MessageID, len(blocks)+1), maxIDLength),
+				Method:        "TRF",
+				ExecutionDate: file.ExecutionDate,
+				Debtor:        partyOf(instruction.Payer),
+				DebtorAccount: account{ID: accountID{Other: genericID{ID: instruction.Payer.BankAccount}}, Currency: currencyOf(instruction)},
+				DebtorAgent:   agentOf(instruction.Payer),
+			}
+			blocks[instruction.Payer.BankAccount] = block
+			document.Initiation.Payments = append(document.Initiation.Payments, block)
+		}
+
+		reference := fmt.Sprintf("S%d", instruction.MonetarySettlementID)
+		block.Transactions = append(block.Transactions, creditTransfer{
+			PaymentID:       paymentID{InstructionID: reference, EndToEndID: reference},
+			Amount:          amount{Currency: currencyOf(instruction), Value: instruction.Amount.String()},
+			CreditorAgent:   agentOf(instruction.Payee),
+			Creditor:        partyOf(instruction.Payee),
+			CreditorAccount: account{ID: accountID{Other: genericID{ID: instruction.Payee.BankAccount}}},
+			Remittance:      &remittance{Unstructured: text(instruction.Purpose, maxTextLength)},
+		})
+		block.count++
+		block.sum += instruction.Amount
+		total += instruction.Amount
+	}
+
+	for _, block := range document.Initiation.Payments {
+		block.NumberOfTransactions = fmt.Sprint(block.count)
+		block.ControlSum = block.sum.String()
+	}
+	document.Initiation.Header.NumberOfTransactions = fmt.Sprint(len(file.Instructions))
+	document.Initiation.Header.ControlSum = total.String()
+
+	data, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render payment file: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// validate checks that the instruction has the data the schema requires for a transfer.
+func validate(instruction *domain.PaymentInstruction) error {
+	if !instruction.Complete {
+		return fmt.Errorf("settlement %d: %w", instruction.MonetarySettlementID, ErrIncomplete)
+	}
+	if instruction.Amount <= 0 {
+		return fmt.Errorf("settlement %d: amount must be positive", instruction.MonetarySettlementID)
+	}
+	for _, details := range []*domain.PaymentDetails{instruction.Payer, instruction.Payee} {
+		if details.BIC == "" {
+			return fmt.Errorf("settlement %d: %s has no BIC: %w", instruction.MonetarySettlementID, details.Account, ErrIncomplete)
+		}
+		if len(details.BankAccount) > maxAccountLength {
+			return fmt.Errorf("settlement %d: bank account of %s exceeds %d characters", instruction.MonetarySettlementID,
+				details.Account, maxAccountLength)
+		}
+	}
+	return nil
+}
+
+// partyOf returns the party with its INN as the tax identifier.
+func partyOf(details *domain.PaymentDetails) party {
+	p := party{Name: text(details.Name, maxTextLength)}
+	if details.INN != "" {
+		p.ID = &partyID{Organisation: organisationID{Other: genericID{ID: details.INN, Scheme: &scheme{Code: "TXID"}}}}
+	}
+	return p
+}
+
+// agentOf returns the bank of the account: a SWIFT BIC as is, a Russian BIC as a clearing system member.
+func agentOf(details *domain.PaymentDetails) agent {
+	bic := strings.ToUpper(strings.TrimSpace(details.BIC))
+	if swiftBICPattern.MatchString(bic) {
+		return agent{Institution: institution{BIC: bic}}
+	}
+	return agent{Institution: institution{ClearingMember: &clearingMember{
+		System:   clearingSystem{Code: russianClearingSystem},
+		MemberID: bic,
+	}}}
+}
+
+// currencyOf returns the currency of the instruction, the default currency if it is not set.
+func currencyOf(instruction *domain.PaymentInstruction) string {
+	if instruction.CurrencyCode == "" {
+		return domain.DefaultCurrencyCode
+	}
+	return instruction.CurrencyCode
+}
+
+// text shortens s to at most n characters, as the schema rejects longer texts.
+func text(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,115 @@
+package pain001
+
+import (
+	"encoding/xml"
+
+	"cliring/internal/domain"
+)
+
+// Элементы pain.001.001.03; порядок полей структур соответствует порядку элементов в XSD.
+
+type document struct {
+	XMLName    xml.Name   `xml:"Document"`
+	Namespace  string     `xml:"xmlns,attr"`
+	Initiation initiation `xml:"CstmrCdtTrfInitn"`
+}
+
+type initiation struct {
+	Header   groupHeader           `xml:"GrpHdr"`
+	Payments []*paymentInformation `xml:"PmtInf"`
+}
+
+type groupHeader struct {
+	MessageID            string `xml:"MsgId"`
+	CreatedAt            string `xml:"CreDtTm"`
+	NumberOfTransactions string `xml:"NbOfTxs"`
+	ControlSum           string `xml:"CtrlSum"`
+	InitiatingParty      party  `xml:"InitgPty"`
+}
+
+type paymentInformation struct {
+	ID                   string           `xml:"PmtInfId"`
+	Method               string           `xml:"PmtMtd"`
+	NumberOfTransactions string           `xml:"NbOfTxs"`
+	ControlSum           string           `xml:"CtrlSum"`
+	ExecutionDate        string           `xml:"ReqdExctnDt"`
+	Debtor               party            `xml:"Dbtr"`
+	DebtorAccount        account          `xml:"DbtrAcct"`
+	DebtorAgent          agent            `xml:"DbtrAgt"`
+	Transactions         []creditTransfer `xml:"CdtTrfTxInf"`
+
+	count int
+	sum   domain.Money
+}
+
+type party struct {
+	Name string   `xml:"Nm,omitempty"`
+	ID   *partyID `xml:"Id,omitempty"`
+}
+
+type partyID struct {
+	Organisation organisationID `xml:"OrgId"`
+}
+
+type organisationID struct {
+	Other genericID `xml:"Othr"`
+}
+
+type genericID struct {
+	ID     string  `xml:"Id"`
+	Scheme *scheme `xml:"SchmeNm,omitempty"`
+}
+
+type scheme struct {
+	Code string `xml:"Cd"`
+}
+
+type account struct {
+	ID       accountID `xml:"Id"`
+	Currency string    `xml:"Ccy,omitempty"`
+}
+
+type accountID struct {
+	Other genericID `xml:"Othr"`
+}
+
+type agent struct {
+	Institution institution `xml:"FinInstnId"`
+}
+
+type institution struct {
+	BIC            string          `xml:"BIC,omitempty"`
+	ClearingMember *clearingMember `xml:"ClrSysMmbId,omitempty"`
+}
+
+type clearingMember struct {
+	System   clearingSystem `xml:"ClrSysId"`
+	MemberID string         `xml:"MmbId"`
+}
+
+type clearingSystem struct {
+	Code string `xml:"Cd"`
+}
+
+type creditTransfer struct {
+	PaymentID       paymentID   `xml:"PmtId"`
+	Amount          amount      `xml:"Amt>InstdAmt"`
+	CreditorAgent   agent       `xml:"CdtrAgt"`
+	Creditor        party       `xml:"Cdtr"`
+	CreditorAccount account     `xml:"CdtrAcct"`
+	Remittance      *remittance `xml:"RmtInf,omitempty"`
+}
+
+type paymentID struct {
+	InstructionID string `xml:"InstrId"`
+	EndToEndID    string `xml:"EndToEndId"`
+}
+
+type amount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type remittance struct {
+	Unstructured string `xml:"Ustrd"`
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"cliring/internal/domain"
+)
+
+// monetarySettlementColumns is the column list selected for a stored monetary settlement.
+const monetarySettlementColumns = `monetary_settlement_id, deal_id, amount, status, created_at, updated_at, bank_id, currency_code,
+		COALESCE(to_char(due_date, 'YYYY-MM-DD'), ''), dealership_id, COALESCE(run_number, 0), insurer_id,
+		COALESCE(participant, ''), COALESCE(payment_reference, ''), executed_at,
+		COALESCE(cancellation_reason, ''), cancelled_at,
+		COALESCE((SELECT SUM(p.amount) FROM settlement_payments p
+			WHERE p.monetary_settlement_id = monetary_settlements.monetary_settlement_id), 0),
+		fx_rates, COALESCE(approval_status, ''), COALESCE(created_by, ''), COALESCE(reviewed_by, ''), reviewed_at,
+		COALESCE(review_comment, ''), clearing_session_id, rounding_residual`
+
+// scanMonetarySettlement scans a row selected with monetarySettlementColumns into a monetary settlement.
+func scanMonetarySettlement(row pgx.Row) (*domain.MonetarySettlement, error) {
+	var settlement domain.MonetarySettlement
+	var bankID, dealershipID, insurerID, sessionID pgtype.Int4
+	var executedAt, cancelledAt, reviewedAt pgtype.Timestamptz
+	err := row.Scan(
+		&settlement.MonetarySettlementID, &settlement.DealID, &settlement.Amount,
+		&settlement.Status, &settlement.CreatedAt, &settlement.UpdatedAt, &bankID, &settlement.CurrencyCode,
+		&settlement.DueDate, &dealershipID, &settlement.RunNumber, &insurerID,
+		&settlement.Participant, &settlement.PaymentReference, &executedAt,
+		&settlement.CancellationReason, &cancelledAt, &settlement.PaidAmount,
+		&settlement.FXRates, &settlement.ApprovalStatus, &settlement.CreatedBy, &settlement.ReviewedBy, &reviewedAt,
+		&settlement.ReviewComment, &sessionID, &settlement.RoundingResidual,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if bankID.Valid {
+		bankIDInt := int(bankID.Int32)
+		settlement.BankID = &bankIDInt
+	}
+	if dealershipID.Valid {
+		dealershipIDInt := int(dealershipID.Int32)
+		settlement.DealershipID = &dealershipIDInt
+	}
+	if insurerID.Valid {
+		insurerIDInt := int(insurerID.Int32)
+		settlement.InsurerID = &insurerIDInt
+	}
+	if executedAt.Valid {
+		settlement.ExecutedAt = &executedAt.Time
+	}
+	if cancelledAt.Valid {
+		settlement.CancelledAt = &cancelledAt.Time
+	}
+	if reviewedAt.Valid {
+		settlement.ReviewedAt = &reviewedAt.Time
+	}
+	if sessionID.Valid {
+		sessionIDInt := int(sessionID.Int32)
+		settlement.ClearingSessionID = &sessionIDInt
+	}
+	if settlement.Status == domain.StatusPending {
+		settlement.RemainingAmount = settlement.Amount - settlement.PaidAmount
+	}
+	return &settlement, nil
+}
+
+// ExecuteMonetarySettlement stores an executed settlement together with its allocations to orders
+// and its ledger entries. Orders that become fully paid are switched to the executed status.
+func (r *Repository) ExecuteMonetarySettlement(ctx context.Context, settlement *domain.MonetarySettlement,
+	allocations []*domain.OrderAllocation, entries []*domain.LedgerEntry) (*domain.MonetarySettlement, error) {
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	// Create settlement
+	query := `
+		INSERT INTO monetary_settlements (deal_id, amount, status, created_at, updated_at, bank_id, currency_code,
+			due_date, dealership_id, run_number, insurer_id, participant, fx_rates, rounding_residual, executed_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $4, $5, NULLIF($6, '')::date, $7, $8, $9, $10,
+			$11, $12, CURRENT_TIMESTAMP)
+		RETURNING ` + monetarySettlementColumns
+
+	executed, err := scanMonetarySettlement(tx.QueryRow(ctx, query,
+		settlement.DealID, settlement.Amount, domain.StatusExecuted, settlement.BankID, settlement.CurrencyCode,
+		settlement.DueDate, settlement.DealershipID, settlement.RunNumber, settlement.InsurerID, settlement.Participant,
+		settlement.FXRates, settlement.RoundingResidual,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create monetary settlement: %w", wrapConstraintError(err))
+	}
+
+	executed.Allocations, err = createAllocationsTx(ctx, tx, executed.MonetarySettlementID, allocations)
+	if err != nil {
+		return nil, err
+	}
+	executed.LedgerEntries, err = createLedgerEntriesTx(ctx, tx, executed, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return executed, nil
+}
+
+// createAllocationsTx stores the allocations of an executed settlement to orders and switches
+// orders that become fully paid to the executed status.
+func createAllocationsTx(ctx context.Context, tx pgx.Tx, settlementID int, allocations []*domain.OrderAllocation) ([]*domain.OrderAllocation, error) {
+	query := `
+		INSERT INTO order_allocations (monetary_settlement_id, order_id, amount, created_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		RETURNING allocation_id, monetary_settlement_id, order_id, amount, created_at`
+
+	var created []*domain.OrderAllocation
+	for _, allocation := range allocations {
+		var allocationRow domain.OrderAllocation
+		err := tx.QueryRow(ctx, query, settlementID, allocation.OrderID, allocation.Amount).Scan(
+			&allocationRow.AllocationID, &allocationRow.MonetarySettlementID, &allocationRow.OrderID,
+			&allocationRow.Amount, &allocationRow.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create order allocation: %w", wrapConstraintError(err))
+		}
+		created = append(created, &allocationRow)
+	}
+
+	// Mark fully paid orders as executed
+	query = `
+		UPDATE orders o
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE o.order_id IN (SELECT a.order_id FROM order_allocations a WHERE a.monetary_settlement_id = $2)
+			AND COALESCE(o.gross_amount, o.amount) <= (SELECT COALESCE(SUM(a.amount), 0) FROM order_allocations a WHERE a.order_id = o.order_id)`
+	if _, err := tx.Exec(ctx, query, domain.StatusExecuted, settlementID); err != nil {
+		return nil, fmt.Errorf("failed to update paid orders: %w", err)
+	}
+
+	return created, nil
+}
+
+// ClearSettlementsRecompute resets the settlement recomputation flag of a deal after its settlements were executed.
+func (r *Repository) ClearSettlementsRecompute(ctx context.Context, dealID int) error {
+	query := `
+		UPDATE deals
+		SET settlements_recompute_required = false
+		WHERE deal_id = $1 AND settlements_recompute_required`
+	if _, err := r.db.Exec(ctx, query, dealID); err != nil {
+		return fmt.Errorf("failed to clear settlements recompute flag: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// anonymizedClientFields lists the personal data columns scrubbed from a client record.
+var anonymizedClientFields = []string{"full_name", "inn", "phone", "email", "passport_ref"}
+
+// GetClientAnonymizedAt returns when the client was anonymized, or nil if it was not.
+func (r *Repository) GetClientAnonymizedAt(ctx context.Context, clientID int) (*time.Time, error) {
+	query := `SELECT anonymized_at FROM clients WHERE client_id = $1`
+
+	var anonymizedAt *time.Time
+	err := r.db.QueryRow(ctx, query, clientID).Scan(&anonymizedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	return anonymizedAt, nil
+}
+
+// CreateAnonymizationToken stores the hash of a confirmation token for client anonymization.
+func (r *Repository) CreateAnonymizationToken(ctx context.Context, clientID int, tokenHash string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO client_anonymization_tokens (token_hash, client_id, expires_at)
+		VALUES ($1, $2, $3)`
+
+	if _, err := r.db.Exec(ctx, query, tokenHash, clientID, expiresAt); err != nil {
+		return fmt.Errorf("failed to create anonymization token: %w", err)
+	}
+
+	return nil
+}
+
+// AnonymizeClient consumes a confirmation token and irreversibly scrubs the personal data of the client.
+// Orders and monetary settlements are left untouched. ErrUnauthorized is returned for an unknown,
+// used or expired token, ErrConflict if the client is already anonymized.
+func (r *Repository) AnonymizeClient(ctx context.Context, clientID int, tokenHash string) (*domain.AnonymizationResult, error) {
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	// Consume confirmation token
+	query := `
+		UPDATE client_anonymization_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1 AND client_id = $2 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP`
+	result, err := tx.Exec(ctx, query, tokenHash, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume anonymization token: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		err = ErrUnauthorized
+		return nil, err
+	}
+
+	// Scrub personal data
+	query = `
+		UPDATE clients
+		SET full_name = 'ANONYMIZED-' || client_id, inn = NULL, phone = NULL, email = NULL, passport_ref = NULL,
+			anonymized_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE client_id = $1 AND anonymized_at IS NULL`
+	result, err = tx.Exec(ctx, query, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize client: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		err = ErrConflict
+		return nil, err
+	}
+
+	// Write audit entry
+	query = `
+		INSERT INTO client_anonymization_audit (client_id, fields)
+		VALUES ($1, $2)
+		RETURNING audit_id, client_id, anonymized_at, fields`
+	var anonymization domain.AnonymizationResult
+	err = tx.QueryRow(ctx, query, clientID, anonymizedClientFields).Scan(
+		&anonymization.AuditID, &anonymization.ClientID, &anonymization.AnonymizedAt, &anonymization.Fields,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anonymization audit entry: %w", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &anonymization, nil
+}
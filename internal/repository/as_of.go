@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// entitySnapshotsQuery selects the state of every row of an entity at $1 from the change_events feed.
+// Rows created before the feed existed (no insert event) and unchanged up to $1 fall back to their
+// current state. Deleted rows are excluded. The %[1]s verb is the entity table, %[2]s its key column.
+const entitySnapshotsQuery = `
+	WITH snapshots AS (
+		SELECT DISTINCT ON (ce.entity_id) ce.action, ce.data
+		FROM change_events ce
+		WHERE ce.entity = '%[1]s' AND ce.changed_at <= $1
+		ORDER BY ce.entity_id, ce.change_id DESC
+	)
+	SELECT s.data FROM snapshots s WHERE s.action <> 'delete' AND s.data ->> 'deleted_at' IS NULL
+	UNION ALL
+	SELECT to_jsonb(t) FROM %[1]s t
+	WHERE t.created_at <= $1 AND NOT EXISTS (
+		SELECT 1 FROM change_events ce
+		WHERE ce.entity = '%[1]s' AND ce.entity_id = t.%[2]s AND (ce.changed_at <= $1 OR ce.action = 'insert')
+	)`
+
+// dealSnapshot is the JSON representation of a deals row stored in change_events.
+type dealSnapshot struct {
+	DealID              int        `json:"deal_id"`
+	Status              string     `json:"status"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	DealershipID        *int       `json:"dealership_id"`
+	ManagerID           *int       `json:"manager_id"`
+	ClientID            *int       `json:"client_id"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	// SettlementsRecomputeRequired is absent from snapshots recorded before the column existed.
+	SettlementsRecomputeRequired bool `json:"settlements_recompute_required"`
+}
+
+// orderSnapshot is the JSON representation of an orders row stored in change_events.
+type orderSnapshot struct {
+	OrderID         int          `json:"order_id"`
+	DealID          int          `json:"deal_id"`
+	OrderTypeID     int          `json:"order_type_id"`
+	Amount          domain.Money `json:"amount"`
+	Status          string       `json:"status"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+	NeedAndOrdersID *int         `json:"need_and_orders_id"`
+	BankID          *int         `json:"bank_id"`
+	CurrencyCode    string       `json:"currency_code"`
+	DealershipID    *int         `json:"dealership_id"`
+	VehicleID       *int         `json:"vehicle_id"`
+	OrderNumber     *int         `json:"order_number"`
+	ReviewStatus    *string      `json:"review_status"`
+	// Discount, VAT and the computed amounts are absent from snapshots recorded before the columns existed.
+	Discount    domain.Money  `json:"discount"`
+	VATRate     float64       `json:"vat_rate"`
+	NetAmount   *domain.Money `json:"net_amount"`
+	VATAmount   *domain.Money `json:"vat_amount"`
+	GrossAmount *domain.Money `json:"gross_amount"`
+	ExecuteAt   *time.Time    `json:"execute_at"`
+	InsurerID   *int          `json:"insurer_id"`
+	// CreditContractID is absent from snapshots recorded before credit contracts were introduced.
+	CreditContractID *int `json:"credit_contract_id"`
+	AppraisalID      *int `json:"appraisal_id"`
+}
+
+// GetDealWithOrdersAsOf reconstructs a deal and its orders in the state they had at asOf.
+// Paid amounts only include allocations made up to asOf.
+func (r *Repository) GetDealWithOrdersAsOf(ctx context.Context, dealID int, asOf time.Time, sort domain.OrderSort) (*domain.Deal, []*domain.Order, error) {
+	orderBy, err := orderByClause(sort, orderSnapshotSortColumns, "(o.data ->> 'order_id')::integer")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Begin read-only transaction so that the deal and its orders are read from the same snapshot
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `
+		SELECT d.data
+		FROM (` + fmt.Sprintf(entitySnapshotsQuery, domain.EntityDeals, "deal_id") + `) d
+		WHERE (d.data ->> 'deal_id')::integer = $2`
+
+	var data []byte
+	if err := tx.QueryRow(ctx, query, asOf, dealID).Scan(&data); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get deal snapshot: %w", err)
+	}
+	var dealRow dealSnapshot
+	if err := json.Unmarshal(data, &dealRow); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode deal snapshot: %w", err)
+	}
+	deal := &domain.Deal{
+		DealID:                       dealRow.DealID,
+		Status:                       dealRow.Status,
+		CreatedAt:                    dealRow.CreatedAt,
+		UpdatedAt:                    dealRow.UpdatedAt,
+		DeletionScheduledAt:          dealRow.DeletionScheduledAt,
+		SettlementsRecomputeRequired: dealRow.SettlementsRecomputeRequired,
+	}
+	if dealRow.DealershipID != nil {
+		deal.DealershipID = *dealRow.DealershipID
+	}
+	if dealRow.ManagerID != nil {
+		deal.ManagerID = *dealRow.ManagerID
+	}
+	if dealRow.ClientID != nil {
+		deal.ClientID = *dealRow.ClientID
+	}
+
+	query = `
+		SELECT o.data, (
+			SELECT COALESCE(SUM(a.amount), 0) FROM order_allocations a
+			WHERE a.order_id = (o.data ->> 'order_id')::integer AND a.created_at <= $1
+		)
+		FROM (` + fmt.Sprintf(entitySnapshotsQuery, domain.EntityOrders, "order_id") + `) o
+		WHERE (o.data ->> 'deal_id')::integer = $2
+		ORDER BY ` + orderBy
+
+	rows, err := tx.Query(ctx, query, asOf, dealID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query order snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*domain.Order
+	for rows.Next() {
+		var paidAmount domain.Money
+		if err := rows.Scan(&data, &paidAmount); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan order snapshot: %w", err)
+		}
+		var orderRow orderSnapshot
+		if err := json.Unmarshal(data, &orderRow); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode order snapshot: %w", err)
+		}
+		order := &domain.Order{
+			OrderID:          orderRow.OrderID,
+			DealID:           orderRow.DealID,
+			OrderTypeID:      orderRow.OrderTypeID,
+			Amount:           orderRow.Amount,
+			Status:           orderRow.Status,
+			CreatedAt:        orderRow.CreatedAt,
+			UpdatedAt:        orderRow.UpdatedAt,
+			NeedAndOrdersID:  orderRow.NeedAndOrdersID,
+			BankID:           orderRow.BankID,
+			CurrencyCode:     orderRow.CurrencyCode,
+			DealershipID:     orderRow.DealershipID,
+			VehicleID:        orderRow.VehicleID,
+			ExecuteAt:        orderRow.ExecuteAt,
+			InsurerID:        orderRow.InsurerID,
+			CreditContractID: orderRow.CreditContractID,
+			AppraisalID:      orderRow.AppraisalID,
+			Discount:         orderRow.Discount,
+			VATRate:          orderRow.VATRate,
+			NetAmount:        orderRow.Amount,
+			GrossAmount:      orderRow.Amount,
+			PaidAmount:       paidAmount,
+		}
+		if orderRow.NetAmount != nil {
+			order.NetAmount = *orderRow.NetAmount
+		}
+		if orderRow.VATAmount != nil {
+			order.VATAmount = *orderRow.VATAmount
+		}
+		if orderRow.GrossAmount != nil {
+			order.GrossAmount = *orderRow.GrossAmount
+		}
+		order.OutstandingAmount = order.GrossAmount - paidAmount
+		if orderRow.OrderNumber != nil {
+			order.OrderNumber = *orderRow.OrderNumber
+		}
+		if orderRow.ReviewStatus != nil {
+			order.ReviewStatus = *orderRow.ReviewStatus
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating order snapshots: %w", err)
+	}
+
+	return deal, orders, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+const auditColumns = `audit_id, occurred_at, actor, method, route, path, COALESCE(entity, ''), COALESCE(entity_id, ''),
+	status_code, COALESCE(ip, ''), COALESCE(request_id, ''), impersonated_client_id, before, after`
+
+// scanAuditEntry scans a row selected with auditColumns.
+func scanAuditEntry(row pgx.Row) (*domain.AuditEntry, error) {
+	var entry domain.AuditEntry
+	if err := row.Scan(&entry.AuditID, &entry.OccurredAt, &entry.Actor, &entry.Method, &entry.Route, &entry.Path,
+		&entry.Entity, &entry.EntityID, &entry.StatusCode, &entry.IP, &entry.RequestID, &entry.ImpersonatedClientID,
+		&entry.Before, &entry.After); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// InsertAuditEntry appends an entry to the audit log. The table rejects updates and deletes.
+func (r *Repository) InsertAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (actor, method, route, path, entity, entity_id, status_code, ip, request_id,
+			impersonated_client_id, before, after)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, NULLIF($8, ''), NULLIF($9, ''), $10, $11, $12)`
+
+	if _, err := r.db.Exec(ctx, query, entry.Actor, entry.Method, entry.Route, entry.Path, entry.Entity,
+		entry.EntityID, entry.StatusCode, entry.IP, entry.RequestID, entry.ImpersonatedClientID,
+		entry.Before, entry.After); err != nil {
+		return fmt.Errorf("failed to insert audit entry: %w", err)
+	}
+	return nil
+}
+
+// auditFilterClauses builds the WHERE conditions of the audit log for the filter.
+func auditFilterClauses(filter domain.AuditFilter) ([]string, []any) {
+	var clauses []string
+	var args []any
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		clauses = append(clauses, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Actor != "" {
+		add("actor = $%d", filter.Actor)
+	}
+	if filter.Entity != "" {
+		add("entity = $%d", filter.Entity)
+	}
+	if filter.EntityID != "" {
+		add("entity_id = $%d", filter.EntityID)
+	}
+	if filter.Method != "" {
+		add("method = $%d", filter.Method)
+	}
+	if filter.Impersonated {
+		clauses = append(clauses, "impersonated_client_id IS NOT NULL")
+	}
+	if filter.From != nil {
+		add("occurred_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("occurred_at < $%d", *filter.To)
+	}
+
+	return clauses, args
+}
+
+// ListAuditEntries retrieves a page of the audit log matching the filter, newest first, together with
+// the total number of matching entries.
+func (r *Repository) ListAuditEntries(ctx context.Context, filter domain.AuditFilter, page, limit int) ([]*domain.AuditEntry, int, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, fmt.Errorf("invalid pagination parameters: %w", ErrInvalidInput)
+	}
+
+	clauses, args := auditFilterClauses(filter)
+	where := "TRUE"
+	if len(clauses) > 0 {
+		where = strings.Join(clauses, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM audit_log WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT `+auditColumns+`
+		FROM audit_log
+		WHERE %s
+		ORDER BY audit_id DESC
+		LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(ctx, query, append(args, limit, (page-1)*limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating audit entries: %w", err)
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"cliring/internal/domain"
+)
+
+// bankColumns is the column list selected for a bank.
+const bankColumns = `bank_id, name, COALESCE(bic, ''), COALESCE(correspondent_account, ''), active, created_at, updated_at`
+
+// scanBank scans a row selected with bankColumns into a bank.
+func scanBank(row pgx.Row) (*domain.Bank, error) {
+	var bank domain.Bank
+	err := row.Scan(&bank.BankID, &bank.Name, &bank.BIC, &bank.CorrespondentAccount, &bank.Active, &bank.CreatedAt, &bank.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &bank, nil
+}
+
+// ListBanks retrieves the banks reference ordered by bank_id, optionally only the active banks.
+func (r *Repository) ListBanks(ctx context.Context, activeOnly bool) ([]*domain.Bank, error) {
+	query := `
+		SELECT ` + bankColumns + `
+		FROM banks
+		WHERE NOT $1 OR active
+		ORDER BY bank_id`
+
+	rows, err := r.db.Query(ctx, query, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query banks: %w", err)
+	}
+	defer rows.Close()
+
+	var banks []*domain.Bank
+	for rows.Next() {
+		bank, err := scanBank(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bank: %w", err)
+		}
+		banks = append(banks, bank)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating banks: %w", err)
+	}
+
+	return banks, nil
+}
+
+// GetBank retrieves a bank by its ID.
+func (r *Repository) GetBank(ctx context.Context, bankID int) (*domain.Bank, error) {
+	query := `SELECT ` + bankColumns + ` FROM banks WHERE bank_id = $1`
+
+	bank, err := scanBank(r.db.QueryRow(ctx, query, bankID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get bank: %w", err)
+	}
+	return bank, nil
+}
+
+// CreateBank adds a bank. ErrConflict is returned if the bank_id already exists.
+func (r *Repository) CreateBank(ctx context.Context, bank domain.Bank) (*domain.Bank, error) {
+	query := `
+		INSERT INTO banks (bank_id, name, bic, correspondent_account, active, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (bank_id) DO NOTHING
+		RETURNING ` + bankColumns
+
+	created, err := scanBank(r.db.QueryRow(ctx, query,
+		bank.BankID, bank.Name, bank.BIC, bank.CorrespondentAccount, bank.Active))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to create bank: %w", wrapConstraintError(err))
+	}
+
+	return created, nil
+}
+
+// UpdateBank updates the details and the active flag of a bank.
+func (r *Repository) UpdateBank(ctx context.Context, bank domain.Bank) (*domain.Bank, error) {
+	query := `
+		UPDATE banks
+		SET name = $2, bic = NULLIF($3, ''), correspondent_account = NULLIF($4, ''), active = $5,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE bank_id = $1
+		RETURNING ` + bankColumns
+
+	updated, err := scanBank(r.db.QueryRow(ctx, query,
+		bank.BankID, bank.Name, bank.BIC, bank.CorrespondentAccount, bank.Active))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update bank: %w", wrapConstraintError(err))
+	}
+
+	return updated, nil
+}
+
+// DeleteBank removes a bank. ErrConflict is returned if orders or settlements still reference the bank.
+func (r *Repository) DeleteBank(ctx context.Context, bankID int) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM banks WHERE bank_id = $1`, bankID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to delete bank: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"cliring/internal/domain"
+)
+
+const bankStatementLineColumns = `line_id, payment_reference, amount, currency_code, to_char(value_date, 'YYYY-MM-DD'),
+		COALESCE(payer_name, ''), COALESCE(purpose, ''), status, monetary_settlement_id, imported_at, matched_at`
+
+// scanBankStatementLine scans a row selected with bankStatementLineColumns into a bank statement line.
+func scanBankStatementLine(row pgx.Row) (*domain.BankStatementLine, error) {
+	var line domain.BankStatementLine
+	var settlementID pgtype.Int4
+	var matchedAt pgtype.Timestamptz
+	err := row.Scan(
+		&line.LineID, &line.PaymentReference, &line.Amount, &line.CurrencyCode, &line.ValueDate,
+		&line.PayerName, &line.Purpose, &line.Status, &settlementID, &line.ImportedAt, &matchedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if settlementID.Valid {
+		settlementIDInt := int(settlementID.Int32)
+		line.MonetarySettlementID = &settlementIDInt
+	}
+	if matchedAt.Valid {
+		line.MatchedAt = &matchedAt.Time
+	}
+	return &line, nil
+}
+
+// CreateBankStatementLine stores an unmatched bank statement line. It reports false without storing
+// the line if a line with the same payment reference, value date and amount was imported before.
+func (r *Repository) CreateBankStatementLine(ctx context.Context, line *domain.BankStatementLine) (*domain.BankStatementLine, bool, error) {
+	query := `
+		INSERT INTO bank_statement_lines (payment_reference, amount, currency_code, value_date, payer_name, purpose,
+			status, imported_at)
+		VALUES ($1, $2, $3, $4::date, NULLIF($5, ''), NULLIF($6, ''), $7, CURRENT_TIMESTAMP)
+		ON CONFLICT (payment_reference, value_date, amount) DO NOTHING
+		RETURNING ` + bankStatementLineColumns
+
+	created, err := scanBankStatementLine(r.db.QueryRow(ctx, query,
+		line.PaymentReference, line.Amount, line.CurrencyCode, line.ValueDate, line.PayerName, line.Purpose,
+		domain.StatementLineUnmatched,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to create bank statement line: %w", wrapConstraintError(err))
+	}
+	return created, true, nil
+}
+
+// MatchBankStatementLine links a bank statement line to the settlement the payment executed.
+func (r *Repository) MatchBankStatementLine(ctx context.Context, lineID, settlementID int) (*domain.BankStatementLine, error) {
+	query := `
+		UPDATE bank_statement_lines
+		SET status = $1, monetary_settlement_id = $2, matched_at = CURRENT_TIMESTAMP
+		WHERE line_id = $3
+		RETURNING ` + bankStatementLineColumns
+
+	line, err := scanBankStatementLine(r.db.QueryRow(ctx, query, domain.StatementLineMatched, settlementID, lineID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to match bank statement line: %w", wrapConstraintError(err))
+	}
+	return line, nil
+}
+
+// ListPendingSettlementsByAmount retrieves the pending settlements of the amount in the currency,
+// the candidates an incoming payment can execute.
+func (r *Repository) ListPendingSettlementsByAmount(ctx context.Context, amount domain.Money, currencyCode string) ([]*domain.MonetarySettlement, error) {
+	query := `
+		SELECT ` + monetarySettlementColumns + `
+		FROM monetary_settlements
+		WHERE status = $1 AND amount = $2 AND currency_code = $3 AND deleted_at IS NULL
+		ORDER BY monetary_settlement_id`
+
+	rows, err := r.db.Query(ctx, query, domain.StatusPending, amount, currencyCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending settlements: %w", err)
+	}
+	defer rows.Close()
+
+	var settlements []*domain.MonetarySettlement
+	for rows.Next() {
+		settlement, err := scanMonetarySettlement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan monetary settlement: %w", err)
+		}
+		settlements = append(settlements, settlement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate monetary settlements: %w", err)
+	}
+
+	return settlements, nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// calendarDayColumns is the column list selected for a business calendar day.
+const calendarDayColumns = `calendar_date, name, is_working_day`
+
+// scanCalendarDay scans a row selected with calendarDayColumns into a calendar day.
+func scanCalendarDay(row pgx.Row) (*domain.CalendarDay, error) {
+	var day domain.CalendarDay
+	var date time.Time
+	if err := row.Scan(&date, &day.Name, &day.IsWorkingDay); err != nil {
+		return nil, err
+	}
+	day.Date = date.Format(domain.DateLayout)
+	return &day, nil
+}
+
+// ListCalendarDays retrieves business calendar exceptions between from and to inclusive.
+func (r *Repository) ListCalendarDays(ctx context.Context, from, to time.Time) ([]*domain.CalendarDay, error) {
+	query := `
+		SELECT ` + calendarDayColumns + `
+		FROM business_calendar
+		WHERE calendar_date BETWEEN $1 AND $2
+		ORDER BY calendar_date`
+
+	rows, err := r.db.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar days: %w", err)
+	}
+	defer rows.Close()
+
+	var days []*domain.CalendarDay
+	for rows.Next() {
+		day, err := scanCalendarDay(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar day: %w", err)
+		}
+		days = append(days, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating calendar days: %w", err)
+	}
+
+	return days, nil
+}
+
+// CreateCalendarDay adds a business calendar exception. ErrConflict is returned if the date already exists.
+func (r *Repository) CreateCalendarDay(ctx context.Context, day domain.CalendarDay) (*domain.CalendarDay, error) {
+	query := `
+		INSERT INTO business_calendar (calendar_date, name, is_working_day)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (calendar_date) DO NOTHING
+		RETURNING ` + calendarDayColumns
+
+	created, err := scanCalendarDay(r.db.QueryRow(ctx, query, day.Date, day.Name, day.IsWorkingDay))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to create calendar day: %w", wrapConstraintError(err))
+	}
+
+	return created, nil
+}
+
+// UpdateCalendarDay updates a business calendar exception.
+func (r *Repository) UpdateCalendarDay(ctx context.Context, day domain.CalendarDay) (*domain.CalendarDay, error) {
+	query := `
+		UPDATE business_calendar
+		SET name = $2, is_working_day = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE calendar_date = $1
+		RETURNING ` + calendarDayColumns
+
+	updated, err := scanCalendarDay(r.db.QueryRow(ctx, query, day.Date, day.Name, day.IsWorkingDay))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update calendar day: %w", wrapConstraintError(err))
+	}
+
+	return updated, nil
+}
+
+// DeleteCalendarDay removes a business calendar exception.
+func (r *Repository) DeleteCalendarDay(ctx context.Context, date string) error {
+	query := `DELETE FROM business_calendar WHERE calendar_date = $1`
+
+	result, err := r.db.Exec(ctx, query, date)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar day: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
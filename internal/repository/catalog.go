@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// GetListPrice retrieves the list price of a vehicle from the vehicles reference.
+func (r *Repository) GetListPrice(ctx context.Context, vehicleID int) (domain.Money, error) {
+	query := `SELECT price FROM vehicles WHERE vehicle_id = $1`
+
+	var listPrice domain.Money
+	err := r.db.QueryRow(ctx, query, vehicleID).Scan(&listPrice)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, fmt.Errorf("failed to get list price: %w", err)
+	}
+
+	return listPrice, nil
+}
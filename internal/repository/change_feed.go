@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cliring/internal/domain"
+)
+
+// ListChanges retrieves change records of an entity with a cursor greater than since, in cursor order.
+func (r *Repository) ListChanges(ctx context.Context, entity string, since int64, limit int) ([]*domain.ChangeRecord, error) {
+	query := `
+		SELECT change_id, entity, entity_id, action, data, changed_at
+		FROM change_events
+		WHERE entity = $1 AND change_id > $2
+		ORDER BY change_id
+		LIMIT $3`
+
+	rows, err := r.db.Query(ctx, query, entity, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []*domain.ChangeRecord
+	for rows.Next() {
+		var change domain.ChangeRecord
+		err := rows.Scan(
+			&change.ChangeID, &change.Entity, &change.EntityID, &change.Action, &change.Data, &change.ChangedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan change: %w", err)
+		}
+		changes = append(changes, &change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating changes: %w", err)
+	}
+
+	return changes, nil
+}
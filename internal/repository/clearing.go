@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cliring/internal/domain"
+)
+
+// ListOpenDeals retrieves the open (active or clearing) deals of a client or of a dealership.
+// A deal belongs to a dealership if it was opened there or if one of its orders was placed there.
+func (r *Repository) ListOpenDeals(ctx context.Context, clientID, dealershipID *int) ([]*domain.Deal, error) {
+	query := `
+		SELECT ` + dealColumns + `
+		FROM deals d
+		WHERE d.deleted_at IS NULL AND d.status IN ($1, $2)
+			AND ($3::integer IS NULL OR d.client_id = $3)
+			AND ($4::integer IS NULL OR d.dealership_id = $4 OR EXISTS (
+				SELECT 1 FROM orders o WHERE o.deal_id = d.deal_id AND o.dealership_id = $4 AND o.deleted_at IS NULL))
+		ORDER BY d.deal_id`
+
+	rows, err := r.db.Query(ctx, query, domain.DealStatusActive, domain.DealStatusClearing, clientID, dealershipID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open deals: %w", err)
+	}
+	defer rows.Close()
+
+	var deals []*domain.Deal
+	for rows.Next() {
+		deal, err := scanDeal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deal: %w", err)
+		}
+		deals = append(deals, deal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating open deals: %w", err)
+	}
+
+	return deals, nil
+}
+
+// ListOrdersOfDeals retrieves the orders of several deals in creation order.
+func (r *Repository) ListOrdersOfDeals(ctx context.Context, dealIDs []int) ([]*domain.Order, error) {
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders o
+		WHERE o.deal_id = ANY($1) AND o.deleted_at IS NULL
+		ORDER BY o.created_at, o.order_id`
+
+	rows, err := r.db.Query(ctx, query, dealIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*domain.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orders: %w", err)
+	}
+
+	return orders, nil
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"cliring/internal/domain"
+)
+
+const clearingSessionColumns = `s.session_id, s.status, s.opened_at, s.computing_at, s.settled_at, s.closed_at,
+		(SELECT COUNT(*) FROM orders o WHERE o.clearing_session_id = s.session_id AND o.deleted_at IS NULL),
+		(SELECT COUNT(*) FROM monetary_settlements m
+			WHERE m.clearing_session_id = s.session_id AND m.status = 'pending' AND m.deleted_at IS NULL)`
+
+// clearingSessionTimestamps maps a session status to the column recording when the session entered it.
+var clearingSessionTimestamps = map[string]string{
+	domain.ClearingSessionComputing: "computing_at",
+	domain.ClearingSessionSettled:   "settled_at",
+	domain.ClearingSessionClosed:    "closed_at",
+}
+
+// scanClearingSession scans a row selected with clearingSessionColumns into a clearing session.
+func scanClearingSession(row pgx.Row) (*domain.ClearingSession, error) {
+	var session domain.ClearingSession
+	var computingAt, settledAt, closedAt pgtype.Timestamptz
+	err := row.Scan(
+		&session.SessionID, &session.Status, &session.OpenedAt, &computingAt, &settledAt, &closedAt,
+		&session.OrderCount, &session.PendingSettlements,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if computingAt.Valid {
+		session.ComputingAt = &computingAt.Time
+	}
+	if settledAt.Valid {
+		session.SettledAt = &settledAt.Time
+	}
+	if closedAt.Valid {
+		session.ClosedAt = &closedAt.Time
+	}
+	return &session, nil
+}
+
+// openClearingSessionTx returns the open clearing session, opening one if there is none. The session row
+// is locked for share, so a session cannot start computing while an order is being accepted into it.
+func openClearingSessionTx(ctx context.Context, tx pgx.Tx) (int, error) {
+	// Если сессия параллельно перешла в расчет, повторный поиск находит следующую открытую сессию
+	for attempt := 0; attempt < 3; attempt++ {
+		var sessionID int
+		err := tx.QueryRow(ctx, `SELECT session_id FROM clearing_sessions WHERE status = $1 FOR SHARE`,
+			domain.ClearingSessionOpen).Scan(&sessionID)
+		if err == nil {
+			return sessionID, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("failed to get open clearing session: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO clearing_sessions (status, opened_at) VALUES ($1, CURRENT_TIMESTAMP)
+			ON CONFLICT (status) WHERE status = 'open' DO NOTHING`, domain.ClearingSessionOpen)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open clearing session: %w", wrapConstraintError(err))
+		}
+	}
+	return 0, fmt.Errorf("failed to open clearing session: %w", ErrConflict)
+}
+
+// GetClearingSession retrieves a clearing session by its ID.
+func (r *Repository) GetClearingSession(ctx context.Context, sessionID int) (*domain.ClearingSession, error) {
+	query := `SELECT ` + clearingSessionColumns + ` FROM clearing_sessions s WHERE s.session_id = $1`
+
+	session, err := scanClearingSession(r.db.QueryRow(ctx, query, sessionID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get clearing session: %w", err)
+	}
+	return session, nil
+}
+
+// ListClearingSessions retrieves a page of clearing sessions, newest first, optionally in one status,
+// and the total number of such sessions.
+func (r *Repository) ListClearingSessions(ctx context.Context, status string, page, limit int) ([]*domain.ClearingSession, int, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, fmt.Errorf("invalid pagination parameters: %w", ErrInvalidInput)
+	}
+
+	var total int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM clearing_sessions WHERE $1 = '' OR status = $1`, status).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count clearing sessions: %w", err)
+	}
+
+	query := `
+		SELECT ` + clearingSessionColumns + `
+		FROM clearing_sessions s
+		WHERE $1 = '' OR s.status = $1
+		ORDER BY s.session_id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, query, status, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query clearing sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*domain.ClearingSession
+	for rows.Next() {
+		session, err := scanClearingSession(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan clearing session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating clearing sessions: %w", err)
+	}
+
+	return sessions, total, nil
+}
+
+// StartComputingClearingSession moves an open session to computing and opens the next session in one
+// transaction, so orders accepted from now on go to the next session. ErrConflict is returned if the
+// session is not open.
+func (r *Repository) StartComputingClearingSession(ctx context.Context, sessionID int) (*domain.ClearingSession, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE clearing_sessions SET status = $1, computing_at = CURRENT_TIMESTAMP
+		WHERE session_id = $2 AND status = $3`,
+		domain.ClearingSessionComputing, sessionID, domain.ClearingSessionOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update clearing session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		err = ErrConflict
+		return nil, err
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO clearing_sessions (status, opened_at) VALUES ($1, CURRENT_TIMESTAMP)`,
+		domain.ClearingSessionOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open next clearing session: %w", wrapConstraintError(err))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return r.GetClearingSession(ctx, sessionID)
+}
+
+// TransitionClearingSession moves a session from one status to the next. A session is closed only when
+// none of its settlements is pending. ErrConflict is returned if the session is not in the from status.
+func (r *Repository) TransitionClearingSession(ctx context.Context, sessionID int, from, to string) (*domain.ClearingSession, error) {
+	column, ok := clearingSessionTimestamps[to]
+	if !ok {
+		return nil, fmt.Errorf("unknown clearing session status %q: %w", to, ErrInvalidInput)
+	}
+	query := `
+		UPDATE clearing_sessions s SET status = $1, ` + column + ` = CURRENT_TIMESTAMP
+		WHERE s.session_id = $2 AND s.status = $3
+			AND ($1 <> $5 OR NOT EXISTS (
+				SELECT 1 FROM monetary_settlements m
+				WHERE m.clearing_session_id = s.session_id AND m.status = $4 AND m.deleted_at IS NULL))
+		RETURNING ` + clearingSessionColumns
+
+	session, err := scanClearingSession(r.db.QueryRow(ctx, query, to, sessionID, from, domain.StatusPending, domain.ClearingSessionClosed))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to update clearing session: %w", err)
+	}
+	return session, nil
+}
+
+// ClearingSessionDeals returns the IDs of the open deals with orders accepted in the session.
+func (r *Repository) ClearingSessionDeals(ctx context.Context, sessionID int) ([]int, error) {
+	query := `
+		SELECT DISTINCT o.deal_id
+		FROM orders o
+		JOIN deals d ON d.deal_id = o.deal_id
+		WHERE o.clearing_session_id = $1 AND o.deleted_at IS NULL
+			AND d.deleted_at IS NULL AND d.status IN ($2, $3)
+		ORDER BY o.deal_id`
+
+	rows, err := r.db.Query(ctx, query, sessionID, domain.DealStatusActive, domain.DealStatusClearing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query clearing session deals: %w", err)
+	}
+	defer rows.Close()
+
+	var dealIDs []int
+	for rows.Next() {
+		var dealID int
+		if err := rows.Scan(&dealID); err != nil {
+			return nil, fmt.Errorf("failed to scan deal id: %w", err)
+		}
+		dealIDs = append(dealIDs, dealID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating clearing session deals: %w", err)
+	}
+	return dealIDs, nil
+}
+
+// ListClearingSessionSettlements retrieves the settlements fixed by the computation of a session.
+func (r *Repository) ListClearingSessionSettlements(ctx context.Context, sessionID int) ([]*domain.MonetarySettlement, error) {
+	query := `
+		SELECT ` + monetarySettlementColumns + `
+		FROM monetary_settlements
+		WHERE clearing_session_id = $1 AND deleted_at IS NULL
+		ORDER BY monetary_settlement_id`
+
+	rows, err := r.db.Query(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clearing session settlements: %w", err)
+	}
+	defer rows.Close()
+
+	var settlements []*domain.MonetarySettlement
+	for rows.Next() {
+		settlement, err := scanMonetarySettlement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan monetary settlement: %w", err)
+		}
+		settlements = append(settlements, settlement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate monetary settlements: %w", err)
+	}
+	return settlements, nil
+}
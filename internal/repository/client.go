@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"cliring/internal/domain"
+)
+
+// clientColumns is the column list selected for a client.
+const clientColumns = `client_id, full_name, COALESCE(inn, ''), COALESCE(phone, ''), COALESCE(email, ''),
+		COALESCE(passport_ref, ''), anonymized_at, created_at, updated_at`
+
+// scanClient scans a row selected with clientColumns into a client.
+func scanClient(row pgx.Row) (*domain.Client, error) {
+	var client domain.Client
+	err := row.Scan(
+		&client.ClientID, &client.FullName, &client.INN, &client.Phone, &client.Email, &client.PassportRef,
+		&client.AnonymizedAt, &client.CreatedAt, &client.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+// ListClients retrieves a page of clients ordered by client_id and the total number of clients.
+func (r *Repository) ListClients(ctx context.Context, page, limit int) ([]*domain.Client, int, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, fmt.Errorf("invalid pagination parameters: %w", ErrInvalidInput)
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM clients`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count clients: %w", err)
+	}
+
+	query := `
+		SELECT ` + clientColumns + `
+		FROM clients
+		ORDER BY client_id
+		LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.Query(ctx, query, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query clients: %w", err)
+	}
+	defer rows.Close()
+
+	var clients []*domain.Client
+	for rows.Next() {
+		client, err := scanClient(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan client: %w", err)
+		}
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating clients: %w", err)
+	}
+
+	return clients, total, nil
+}
+
+// GetClient retrieves a client by its ID.
+func (r *Repository) GetClient(ctx context.Context, clientID int) (*domain.Client, error) {
+	query := `SELECT ` + clientColumns + ` FROM clients WHERE client_id = $1`
+
+	client, err := scanClient(r.db.QueryRow(ctx, query, clientID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	return client, nil
+}
+
+// CreateClient adds a client. ErrConflict is returned if the client_id already exists.
+func (r *Repository) CreateClient(ctx context.Context, client domain.Client) (*domain.Client, error) {
+	query := `
+		INSERT INTO clients (client_id, full_name, inn, phone, email, passport_ref, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (client_id) DO NOTHING
+		RETURNING ` + clientColumns
+
+	created, err := scanClient(r.db.QueryRow(ctx, query,
+		client.ClientID, client.FullName, client.INN, client.Phone, client.Email, client.PassportRef))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to create client: %w", wrapConstraintError(err))
+	}
+
+	return created, nil
+}
+
+// UpdateClient updates the personal data of a client. ErrNotFound is returned for an unknown client,
+// ErrConflict for an anonymized one: its personal data must not be restored.
+func (r *Repository) UpdateClient(ctx context.Context, client domain.Client) (*domain.Client, error) {
+	query := `
+		UPDATE clients
+		SET full_name = $2, inn = NULLIF($3, ''), phone = NULLIF($4, ''), email = NULLIF($5, ''),
+			passport_ref = NULLIF($6, ''), updated_at = CURRENT_TIMESTAMP
+		WHERE client_id = $1 AND anonymized_at IS NULL
+		RETURNING ` + clientColumns
+
+	updated, err := scanClient(r.db.QueryRow(ctx, query,
+		client.ClientID, client.FullName, client.INN, client.Phone, client.Email, client.PassportRef))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, getErr := r.GetClient(ctx, client.ClientID); getErr != nil {
+				return nil, getErr
+			}
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to update client: %w", wrapConstraintError(err))
+	}
+
+	return updated, nil
+}
+
+// DeleteClient removes a client. ErrConflict is returned if deals or the anonymization audit reference the client.
+func (r *Repository) DeleteClient(ctx context.Context, clientID int) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM clients WHERE client_id = $1`, clientID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to delete client: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
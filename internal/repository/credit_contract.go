@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"cliring/internal/domain"
+)
+
+// creditContractColumns is the column list selected for a credit contract.
+const creditContractColumns = `credit_contract_id, deal_id, bank_id, contract_number, principal, currency_code,
+		term_months, rate, created_at, updated_at`
+
+// scanCreditContract scans a row selected with creditContractColumns into a credit contract.
+func scanCreditContract(row pgx.Row) (*domain.CreditContract, error) {
+	var contract domain.CreditContract
+	err := row.Scan(
+		&contract.CreditContractID, &contract.DealID, &contract.BankID, &contract.ContractNumber, &contract.Principal,
+		&contract.CurrencyCode, &contract.TermMonths, &contract.Rate, &contract.CreatedAt, &contract.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &contract, nil
+}
+
+// ListCreditContracts retrieves the credit contracts of a deal ordered by ID.
+func (r *Repository) ListCreditContracts(ctx context.Context, dealID int) ([]*domain.CreditContract, error) {
+	query := `
+		SELECT ` + creditContractColumns + `
+		FROM credit_contracts
+		WHERE deal_id = $1
+		ORDER BY credit_contract_id`
+
+	rows, err := r.db.Query(ctx, query, dealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credit contracts: %w", err)
+	}
+	defer rows.Close()
+
+	var contracts []*domain.CreditContract
+	for rows.Next() {
+		contract, err := scanCreditContract(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan credit contract: %w", err)
+		}
+		contracts = append(contracts, contract)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating credit contracts: %w", err)
+	}
+
+	return contracts, nil
+}
+
+// GetCreditContract retrieves a credit contract by its ID.
+func (r *Repository) GetCreditContract(ctx context.Context, contractID int) (*domain.CreditContract, error) {
+	query := `SELECT ` + creditContractColumns + ` FROM credit_contracts WHERE credit_contract_id = $1`
+
+	contract, err := scanCreditContract(r.db.QueryRow(ctx, query, contractID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get credit contract: %w", err)
+	}
+	return contract, nil
+}
+
+// CreateCreditContract stores a credit contract. A contract number already used at the bank is reported
+// as a unique constraint violation.
+func (r *Repository) CreateCreditContract(ctx context.Context, contract domain.CreditContract) (*domain.CreditContract, error) {
+	query := `
+		INSERT INTO credit_contracts (deal_id, bank_id, contract_number, principal, currency_code, term_months, rate,
+			created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING ` + creditContractColumns
+
+	created, err := scanCreditContract(r.db.QueryRow(ctx, query,
+		contract.DealID, contract.BankID, contract.ContractNumber, contract.Principal, contract.CurrencyCode,
+		contract.TermMonths, contract.Rate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credit contract: %w", wrapConstraintError(err))
+	}
+	return created, nil
+}
+
+// UpdateCreditContract replaces the terms of a credit contract. The deal of the contract is not changed.
+func (r *Repository) UpdateCreditContract(ctx context.Context, contract domain.CreditContract) (*domain.CreditContract, error) {
+	query := `
+		UPDATE credit_contracts
+		SET bank_id = $2, contract_number = $3, principal = $4, currency_code = $5, term_months = $6, rate = $7,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE credit_contract_id = $1
+		RETURNING ` + creditContractColumns
+
+	updated, err := scanCreditContract(r.db.QueryRow(ctx, query,
+		contract.CreditContractID, contract.BankID, contract.ContractNumber, contract.Principal, contract.CurrencyCode,
+		contract.TermMonths, contract.Rate))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update credit contract: %w", wrapConstraintError(err))
+	}
+	return updated, nil
+}
+
+// DeleteCreditContract removes a credit contract. ErrConflict is returned if orders reference the contract.
+func (r *Repository) DeleteCreditContract(ctx context.Context, contractID int) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM credit_contracts WHERE credit_contract_id = $1`, contractID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to delete credit contract: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// CountContractOrders returns the number of orders that reference the credit contract.
+func (r *Repository) CountContractOrders(ctx context.Context, contractID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM orders WHERE credit_contract_id = $1 AND deleted_at IS NULL`
+	if err := r.db.QueryRow(ctx, query, contractID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count contract orders: %w", err)
+	}
+	return count, nil
+}
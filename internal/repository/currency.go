@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cliring/internal/domain"
+)
+
+// ListCurrencies retrieves all currencies from the currency table.
+func (r *Repository) ListCurrencies(ctx context.Context) ([]*domain.Currency, error) {
+	query := `
+		SELECT currency_code, name, minor_unit, symbol, rounding_mode, rounding_precision, rounding_residual
+		FROM currencies
+		ORDER BY currency_code`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query currencies: %w", err)
+	}
+	defer rows.Close()
+
+	var currencies []*domain.Currency
+	for rows.Next() {
+		var currency domain.Currency
+		err := rows.Scan(
+			&currency.Code, &currency.Name, &currency.MinorUnit, &currency.Symbol,
+			&currency.Rounding.Mode, &currency.Rounding.Precision, &currency.Rounding.Residual,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan currency: %w", err)
+		}
+		currencies = append(currencies, &currency)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating currencies: %w", err)
+	}
+
+	return currencies, nil
+}
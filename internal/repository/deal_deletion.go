@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cliring/internal/domain"
+)
+
+// ScheduleDealDeletion marks a deal as pending deletion at the given time.
+// ErrConflict is returned if the deletion is already scheduled.
+func (r *Repository) ScheduleDealDeletion(ctx context.Context, dealID int, at time.Time) (*domain.Deal, error) {
+	return r.setDealDeletionSchedule(ctx, dealID, &at, domain.DealActionDeletionScheduled)
+}
+
+// RestoreDeal cancels a scheduled deletion of a deal.
+// ErrConflict is returned if the deal is not pending deletion.
+func (r *Repository) RestoreDeal(ctx context.Context, dealID int) (*domain.Deal, error) {
+	return r.setDealDeletionSchedule(ctx, dealID, nil, domain.DealActionRestored)
+}
+
+// setDealDeletionSchedule sets or clears deletion_scheduled_at and records the change in the deal history.
+func (r *Repository) setDealDeletionSchedule(ctx context.Context, dealID int, at *time.Time, action string) (*domain.Deal, error) {
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	oldDeal, err := r.getDealForUpdate(ctx, tx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	// Планировать можно только не запланированное удаление, отменять - только запланированное
+	if (at != nil) == (oldDeal.DeletionScheduledAt != nil) {
+		err = ErrConflict
+		return nil, err
+	}
+
+	query := `
+		UPDATE deals
+		SET deletion_scheduled_at = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE deal_id = $2
+		RETURNING ` + dealColumns
+
+	deal, err := scanDeal(tx.QueryRow(ctx, query, at, dealID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update deal deletion schedule: %w", err)
+	}
+
+	if err = r.insertDealHistory(ctx, tx, action, oldDeal, deal); err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deal, nil
+}
+
+// ListDealsDueForDeletion retrieves IDs of deals whose deletion grace window has expired by now.
+func (r *Repository) ListDealsDueForDeletion(ctx context.Context, now time.Time) ([]int, error) {
+	query := `
+		SELECT deal_id
+		FROM deals
+		WHERE deletion_scheduled_at <= $1 AND deleted_at IS NULL
+		ORDER BY deletion_scheduled_at`
+
+	rows, err := r.db.Query(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deals due for deletion: %w", err)
+	}
+	defer rows.Close()
+
+	var dealIDs []int
+	for rows.Next() {
+		var dealID int
+		if err := rows.Scan(&dealID); err != nil {
+			return nil, fmt.Errorf("failed to scan deal id: %w", err)
+		}
+		dealIDs = append(dealIDs, dealID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deals due for deletion: %w", err)
+	}
+
+	return dealIDs, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// dealDocumentColumns is the column list selected for a deal document.
+const dealDocumentColumns = `document_id, deal_id, file_name, content_type, size_bytes, storage_key, uploaded_by, created_at`
+
+// scanDealDocument scans a row selected with dealDocumentColumns into a deal document.
+func scanDealDocument(row pgx.Row) (*domain.DealDocument, error) {
+	var document domain.DealDocument
+	err := row.Scan(
+		&document.DocumentID, &document.DealID, &document.FileName, &document.ContentType, &document.SizeBytes,
+		&document.StorageKey, &document.UploadedBy, &document.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// CreateDealDocument stores the metadata of an uploaded deal document.
+func (r *Repository) CreateDealDocument(ctx context.Context, document *domain.DealDocument) (*domain.DealDocument, error) {
+	query := `
+		INSERT INTO deal_documents (deal_id, file_name, content_type, size_bytes, storage_key, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + dealDocumentColumns
+
+	created, err := scanDealDocument(r.db.QueryRow(ctx, query,
+		document.DealID, document.FileName, document.ContentType, document.SizeBytes, document.StorageKey,
+		actorFromContext(ctx),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create deal document: %w", wrapConstraintError(err))
+	}
+
+	return created, nil
+}
+
+// ListDealDocuments retrieves the documents attached to a deal, newest first.
+func (r *Repository) ListDealDocuments(ctx context.Context, dealID int) ([]*domain.DealDocument, error) {
+	query := `
+		SELECT ` + dealDocumentColumns + `
+		FROM deal_documents
+		WHERE deal_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at DESC, document_id DESC`
+
+	rows, err := r.db.Query(ctx, query, dealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deal documents: %w", err)
+	}
+	defer rows.Close()
+
+	var documents []*domain.DealDocument
+	for rows.Next() {
+		document, err := scanDealDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deal document: %w", err)
+		}
+		documents = append(documents, document)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deal documents: %w", err)
+	}
+
+	return documents, nil
+}
+
+// GetDealDocument retrieves a document of a deal by its ID.
+func (r *Repository) GetDealDocument(ctx context.Context, dealID, documentID int) (*domain.DealDocument, error) {
+	query := `
+		SELECT ` + dealDocumentColumns + `
+		FROM deal_documents
+		WHERE deal_id = $1 AND document_id = $2 AND deleted_at IS NULL`
+
+	document, err := scanDealDocument(r.db.QueryRow(ctx, query, dealID, documentID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get deal document: %w", err)
+	}
+
+	return document, nil
+}
+
+// DeleteDealDocument soft-deletes a document of a deal.
+func (r *Repository) DeleteDealDocument(ctx context.Context, dealID, documentID int) error {
+	query := `
+		UPDATE deal_documents
+		SET deleted_at = CURRENT_TIMESTAMP
+		WHERE deal_id = $1 AND document_id = $2 AND deleted_at IS NULL`
+
+	tag, err := r.db.Exec(ctx, query, dealID, documentID)
+	if err != nil {
+		return fmt.Errorf("failed to delete deal document: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
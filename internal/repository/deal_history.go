@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// actorFromContext returns the user performing the request, as put into the context by the auth middleware.
+func actorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(domain.ActorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return domain.ActorSystem
+}
+
+// dealValues converts a deal into a map of its JSON fields.
+func dealValues(deal *domain.Deal) (map[string]any, error) {
+	if deal == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(deal)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// insertDealHistory records a deal mutation. Only fields that differ between oldDeal and newDeal
+// are stored; a nil deal means the deal did not exist before (or after) the mutation.
+func (r *Repository) insertDealHistory(ctx context.Context, tx pgx.Tx, action string, oldDeal, newDeal *domain.Deal) error {
+	oldValues, err := dealValues(oldDeal)
+	if err != nil {
+		return fmt.Errorf("failed to encode old deal values: %w", err)
+	}
+	newValues, err := dealValues(newDeal)
+	if err != nil {
+		return fmt.Errorf("failed to encode new deal values: %w", err)
+	}
+
+	// Оставляем только изменившиеся поля
+	if oldValues != nil && newValues != nil {
+		for field := range oldValues {
+			if field == "updated_at" || reflect.DeepEqual(oldValues[field], newValues[field]) {
+				delete(oldValues, field)
+				delete(newValues, field)
+			}
+		}
+	}
+
+	dealID := 0
+	if oldDeal != nil {
+		dealID = oldDeal.DealID
+	} else if newDeal != nil {
+		dealID = newDeal.DealID
+	}
+
+	query := `
+		INSERT INTO deal_history (deal_id, action, changed_by, old_values, new_values)
+		VALUES ($1, $2, $3, $4, $5)`
+	if _, err := tx.Exec(ctx, query, dealID, action, actorFromContext(ctx), oldValues, newValues); err != nil {
+		return fmt.Errorf("failed to record deal history: %w", err)
+	}
+
+	return nil
+}
+
+// ListDealHistory retrieves the change history of a deal, oldest first.
+func (r *Repository) ListDealHistory(ctx context.Context, dealID int) ([]*domain.DealHistoryEntry, error) {
+	query := `
+		SELECT history_id, deal_id, action, changed_by, changed_at, old_values, new_values
+		FROM deal_history
+		WHERE deal_id = $1
+		ORDER BY changed_at, history_id`
+
+	rows, err := r.db.Query(ctx, query, dealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deal history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domain.DealHistoryEntry
+	for rows.Next() {
+		var entry domain.DealHistoryEntry
+		err := rows.Scan(
+			&entry.HistoryID, &entry.DealID, &entry.Action, &entry.ChangedBy, &entry.ChangedAt,
+			&entry.OldValues, &entry.NewValues,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deal history: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deal history: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"cliring/pkg/postgres"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// dealLockNamespace is the first key of the two-key advisory locks taken on deals,
+// so they do not collide with advisory locks of other subsystems.
+const dealLockNamespace int32 = 3764
+
+// heldDealLocksKey is the context key of the set of deals locked with LockDeal by the caller.
+type heldDealLocksKey struct{}
+
+// LockDeal takes a session-level advisory lock on a deal, blocking until it is available. The lock is held
+// on a dedicated pool connection until it is released with the returned func, so it serializes netting of the
+// deal with order writes, which take transaction-level locks on the same key from other connections.
+// The returned context marks the deal as locked: LockDeal called with it again for the same deal returns at once,
+// and queries run with it go to the lock connection, so work under the lock does not take a second connection.
+func (r *Repository) LockDeal(ctx context.Context, dealID int) (context.Context, func(), error) {
+	held, _ := ctx.Value(heldDealLocksKey{}).(map[int]bool)
+	if held[dealID] {
+		return ctx, func() {}, nil
+	}
+
+	conn, err := r.db.Acquire(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock deal %d: %w", dealID, err)
+	}
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1, $2)`, dealLockNamespace, dealID); err != nil {
+		conn.Release()
+		return nil, nil, fmt.Errorf("failed to lock deal %d: %w", dealID, err)
+	}
+
+	ctx, unpin := postgres.WithConn(ctx, conn)
+	unlock := func() {
+		unpin()
+		defer conn.Release()
+		// Снимаем блокировку даже если контекст запроса уже отменён
+		if _, err := conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1, $2)`,
+			dealLockNamespace, dealID); err != nil {
+			logrus.Errorf("failed to unlock deal %d: %s", dealID, err)
+			// Соединение с неснятой блокировкой не возвращается в пул: закрытие сессии снимает ее
+			_ = conn.Conn().Close(context.WithoutCancel(ctx))
+		}
+	}
+
+	locked := make(map[int]bool, len(held)+1)
+	for id := range held {
+		locked[id] = true
+	}
+	locked[dealID] = true
+	return context.WithValue(ctx, heldDealLocksKey{}, locked), unlock, nil
+}
+
+// lockDealsTx takes transaction-level advisory locks on deals in ascending order to avoid deadlocks.
+// The locks are released when the transaction ends. Deals locked by the caller with LockDeal are skipped.
+func lockDealsTx(ctx context.Context, tx pgx.Tx, dealIDs ...int) error {
+	held, _ := ctx.Value(heldDealLocksKey{}).(map[int]bool)
+	dealIDs = slices.DeleteFunc(slices.Clone(dealIDs), func(dealID int) bool { return held[dealID] })
+	if len(dealIDs) == 0 {
+		return nil
+	}
+
+	query := `SELECT pg_advisory_xact_lock($1, d) FROM unnest($2::integer[]) AS d ORDER BY d`
+	if _, err := tx.Exec(ctx, query, dealLockNamespace, dealIDs); err != nil {
+		return fmt.Errorf("failed to lock deals: %w", err)
+	}
+	return nil
+}
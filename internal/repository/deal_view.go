@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// GetDealWithOrders retrieves a deal and all its orders, sorted as requested, from one consistent snapshot.
+func (r *Repository) GetDealWithOrders(ctx context.Context, dealID int, sort domain.OrderSort) (*domain.Deal, []*domain.Order, error) {
+	orderBy, err := orderByClause(sort, orderSortColumns, "o.order_id")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Begin read-only transaction so that the deal and its orders are read from the same snapshot
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query := `
+		SELECT ` + dealColumns + `
+		FROM deals
+		WHERE deal_id = $1 AND deleted_at IS NULL`
+
+	deal, err := scanDeal(tx.QueryRow(ctx, query, dealID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+
+	query = `
+		SELECT ` + orderColumns + `
+		FROM orders o
+		WHERE o.deal_id = $1 AND o.deleted_at IS NULL
+		ORDER BY ` + orderBy
+
+	rows, err := tx.Query(ctx, query, dealID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []*domain.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating orders: %w", err)
+	}
+
+	return deal, orders, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"cliring/internal/domain"
+)
+
+// dealershipColumns is the column list selected for a dealership.
+const dealershipColumns = `dealership_id, name, legal_entity, COALESCE(address, ''), active, created_at, updated_at`
+
+// scanDealership scans a row selected with dealershipColumns into a dealership.
+func scanDealership(row pgx.Row) (*domain.Dealership, error) {
+	var dealership domain.Dealership
+	err := row.Scan(
+		&dealership.DealershipID, &dealership.Name, &dealership.LegalEntity, &dealership.Address,
+		&dealership.Active, &dealership.CreatedAt, &dealership.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &dealership, nil
+}
+
+// ListDealerships retrieves the dealerships ordered by dealership_id, optionally only the active ones.
+func (r *Repository) ListDealerships(ctx context.Context, activeOnly bool) ([]*domain.Dealership, error) {
+	query := `
+		SELECT ` + dealershipColumns + `
+		FROM dealerships
+		WHERE NOT $1 OR active
+		ORDER BY dealership_id`
+
+	rows, err := r.db.Query(ctx, query, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dealerships: %w", err)
+	}
+	defer rows.Close()
+
+	var dealerships []*domain.Dealership
+	for rows.Next() {
+		dealership, err := scanDealership(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dealership: %w", err)
+		}
+		dealerships = append(dealerships, dealership)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dealerships: %w", err)
+	}
+
+	return dealerships, nil
+}
+
+// GetDealership retrieves a dealership by its ID.
+func (r *Repository) GetDealership(ctx context.Context, dealershipID int) (*domain.Dealership, error) {
+	query := `SELECT ` + dealershipColumns + ` FROM dealerships WHERE dealership_id = $1`
+
+	dealership, err := scanDealership(r.db.QueryRow(ctx, query, dealershipID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get dealership: %w", err)
+	}
+	return dealership, nil
+}
+
+// CreateDealership adds a dealership. ErrConflict is returned if the dealership_id already exists.
+func (r *Repository) CreateDealership(ctx context.Context, dealership domain.Dealership) (*domain.Dealership, error) {
+	query := `
+		INSERT INTO dealerships (dealership_id, name, legal_entity, address, active, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (dealership_id) DO NOTHING
+		RETURNING ` + dealershipColumns
+
+	created, err := scanDealership(r.db.QueryRow(ctx, query,
+		dealership.DealershipID, dealership.Name, dealership.LegalEntity, dealership.Address, dealership.Active))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to create dealership: %w", wrapConstraintError(err))
+	}
+
+	return created, nil
+}
+
+// UpdateDealership updates the details and the active flag of a dealership.
+func (r *Repository) UpdateDealership(ctx context.Context, dealership domain.Dealership) (*domain.Dealership, error) {
+	query := `
+		UPDATE dealerships
+		SET name = $2, legal_entity = $3, address = NULLIF($4, ''), active = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE dealership_id = $1
+		RETURNING ` + dealershipColumns
+
+	updated, err := scanDealership(r.db.QueryRow(ctx, query,
+		dealership.DealershipID, dealership.Name, dealership.LegalEntity, dealership.Address, dealership.Active))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update dealership: %w", wrapConstraintError(err))
+	}
+
+	return updated, nil
+}
+
+// DeleteDealership removes a dealership. ErrConflict is returned if deals, orders or managers reference it.
+func (r *Repository) DeleteDealership(ctx context.Context, dealershipID int) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM dealerships WHERE dealership_id = $1`, dealershipID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to delete dealership: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
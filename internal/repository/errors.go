@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"cliring/internal/domain"
+)
+
+// Postgres error codes of integrity constraint violations.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgCheckViolation      = "23514"
+	pgNotNullViolation    = "23502"
+)
+
+// constraintKeyPattern extracts the column list from a violation detail like
+// `Key (client_id)=(5) is not present in table "clients".`
+var constraintKeyPattern = regexp.MustCompile(`Key \(([^)]+)\)=`)
+
+// wrapConstraintError converts a Postgres constraint violation into a domain.ConstraintError
+// that keeps the offending column. Other errors are returned unchanged.
+func wrapConstraintError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var kind string
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		kind = domain.ConstraintUnique
+	case pgForeignKeyViolation:
+		kind = domain.ConstraintForeignKey
+	case pgCheckViolation:
+		kind = domain.ConstraintCheck
+	case pgNotNullViolation:
+		kind = domain.ConstraintNotNull
+	default:
+		return err
+	}
+
+	return &domain.ConstraintError{
+		Kind:       kind,
+		Table:      pgErr.TableName,
+		Column:     constraintColumn(pgErr),
+		Constraint: pgErr.ConstraintName,
+		Err:        err,
+	}
+}
+
+// constraintColumn determines the column that violated the constraint.
+func constraintColumn(pgErr *pgconn.PgError) string {
+	if pgErr.ColumnName != "" {
+		return pgErr.ColumnName
+	}
+	if m := constraintKeyPattern.FindStringSubmatch(pgErr.Detail); m != nil {
+		return m[1]
+	}
+	// Имена check-ограничений по умолчанию имеют вид <таблица>_<колонка>_check
+	if pgErr.Code == pgCheckViolation && pgErr.TableName != "" {
+		column := strings.TrimPrefix(pgErr.ConstraintName, pgErr.TableName+"_")
+		return strings.TrimSuffix(column, "_check")
+	}
+	return ""
+}
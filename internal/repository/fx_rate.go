@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// fxRateColumns is the column list selected for an exchange rate.
+const fxRateColumns = `rate_id, currency_code, pivot_currency_code, rate::float8, to_char(rate_date, 'YYYY-MM-DD'), source,
+		created_at`
+
+// scanFXRate scans a row selected with fxRateColumns into an exchange rate.
+func scanFXRate(row pgx.Row) (*domain.FXRate, error) {
+	var rate domain.FXRate
+	err := row.Scan(
+		&rate.RateID, &rate.CurrencyCode, &rate.PivotCurrencyCode, &rate.Rate, &rate.RateDate, &rate.Source, &rate.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// CreateFXRate stores a new version of an exchange rate.
+func (r *Repository) CreateFXRate(ctx context.Context, rate domain.FXRate) (*domain.FXRate, error) {
+	query := `
+		INSERT INTO fx_rates (currency_code, pivot_currency_code, rate, rate_date, source, created_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		RETURNING ` + fxRateColumns
+
+	created, err := scanFXRate(r.db.QueryRow(ctx, query,
+		rate.CurrencyCode, rate.PivotCurrencyCode, rate.Rate, rate.RateDate, domain.FXRateSourceManual))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exchange rate: %w", wrapConstraintError(err))
+	}
+	return created, nil
+}
+
+// SaveCBRRates stores the Central Bank rates of a date as new versions of the exchange rates.
+// Rates already fetched for the date are kept; it returns the number of rates stored.
+func (r *Repository) SaveCBRRates(ctx context.Context, rates []domain.FXRate) (int, error) {
+	query := `
+		INSERT INTO fx_rates (currency_code, pivot_currency_code, rate, rate_date, source, created_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		ON CONFLICT (currency_code, pivot_currency_code, rate_date) WHERE source = 'cbr' DO NOTHING`
+
+	saved := 0
+	for _, rate := range rates {
+		tag, err := r.db.Exec(ctx, query,
+			rate.CurrencyCode, rate.PivotCurrencyCode, rate.Rate, rate.RateDate, domain.FXRateSourceCBR)
+		if err != nil {
+			return saved, fmt.Errorf("failed to save exchange rate: %w", wrapConstraintError(err))
+		}
+		saved += int(tag.RowsAffected())
+	}
+	return saved, nil
+}
+
+// ListFXRates retrieves the exchange rates to the pivot currency, newest first, optionally
+// only the rates of one currency.
+func (r *Repository) ListFXRates(ctx context.Context, pivotCurrencyCode, currencyCode string) ([]*domain.FXRate, error) {
+	query := `
+		SELECT ` + fxRateColumns + `
+		FROM fx_rates
+		WHERE pivot_currency_code = $1 AND ($2 = '' OR currency_code = $2)
+		ORDER BY rate_date DESC, rate_id DESC`
+
+	return r.queryFXRates(ctx, query, pivotCurrencyCode, currencyCode)
+}
+
+// ListEffectiveFXRates retrieves for every currency the latest version of its exchange rate
+// to the pivot currency effective on the date.
+func (r *Repository) ListEffectiveFXRates(ctx context.Context, pivotCurrencyCode string, date time.Time) ([]*domain.FXRate, error) {
+	query := `
+		SELECT DISTINCT ON (currency_code) ` + fxRateColumns + `
+		FROM fx_rates
+		WHERE pivot_currency_code = $1 AND rate_date <= $2
+		ORDER BY currency_code, rate_date DESC, rate_id DESC`
+
+	return r.queryFXRates(ctx, query, pivotCurrencyCode, date.Format(domain.DateLayout))
+}
+
+// queryFXRates runs a query selecting fxRateColumns.
+func (r *Repository) queryFXRates(ctx context.Context, query string, args ...any) ([]*domain.FXRate, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []*domain.FXRate
+	for rows.Next() {
+		rate, err := scanFXRate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating exchange rates: %w", err)
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// Ping checks that the database connection is alive.
+func (r *Repository) Ping(ctx context.Context) error {
+	if err := r.db.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// Available reports whether the database connection has been opened and migrated.
+func (r *Repository) Available() bool {
+	return r.db.IsOpen()
+}
+
+// SchemaVersion returns the schema version applied by the startup migration.
+func (r *Repository) SchemaVersion() int32 {
+	return r.db.SchemaVersion()
+}
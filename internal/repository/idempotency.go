@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// ReserveIdempotencyKey reserves the key for a new request on behalf of owner. A key created before expiredBefore, or left
+// in progress without a heartbeat since abandonedBefore by a failed instance, is taken over. If the key is held by another
+// request, the stored record is returned with reserved = false.
+func (r *Repository) ReserveIdempotencyKey(ctx context.Context, scope, key, owner, requestHash string, expiredBefore, abandonedBefore time.Time) (*domain.IdempotencyRecord, bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (scope, key, request_hash, owner, heartbeat_at)
+		VALUES ($1, $2, $3, $6, CURRENT_TIMESTAMP)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			request_hash = EXCLUDED.request_hash,
+			owner = EXCLUDED.owner,
+			status_code = NULL,
+			response_body = NULL,
+			created_at = CURRENT_TIMESTAMP,
+			heartbeat_at = CURRENT_TIMESTAMP
+		WHERE idempotency_keys.created_at < $4
+			OR (idempotency_keys.status_code IS NULL AND idempotency_keys.heartbeat_at < $5)
+		RETURNING request_hash`
+
+	var reservedHash string
+	err := r.db.QueryRow(ctx, query, scope, key, requestHash, expiredBefore, abandonedBefore, owner).Scan(&reservedHash)
+	if err == nil {
+		return nil, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	query = `
+		SELECT request_hash, status_code, response_body
+		FROM idempotency_keys
+		WHERE scope = $1 AND key = $2`
+
+	var record domain.IdempotencyRecord
+	if err := r.db.QueryRow(ctx, query, scope, key).Scan(&record.RequestHash, &record.StatusCode, &record.Body); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Ключ удалили между запросами - повтор вызывающей стороной безопасен
+			return nil, false, ErrConflict
+		}
+		return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return &record, false, nil
+}
+
+// TouchIdempotencyKey records a heartbeat of the request that reserved the key and is still running.
+func (r *Repository) TouchIdempotencyKey(ctx context.Context, scope, key, owner string) error {
+	query := `
+		UPDATE idempotency_keys
+		SET heartbeat_at = CURRENT_TIMESTAMP
+		WHERE scope = $1 AND key = $2 AND owner = $3 AND status_code IS NULL`
+
+	if _, err := r.db.Exec(ctx, query, scope, key, owner); err != nil {
+		return fmt.Errorf("failed to touch idempotency key: %w", err)
+	}
+	return nil
+}
+
+// CompleteIdempotencyKey stores the response of the request that reserved the key. Nothing is stored if the key
+// has been taken over by another request in the meantime.
+func (r *Repository) CompleteIdempotencyKey(ctx context.Context, scope, key, owner string, statusCode int, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $4, response_body = $5
+		WHERE scope = $1 AND key = $2 AND owner = $3`
+
+	if _, err := r.db.Exec(ctx, query, scope, key, owner, statusCode, body); err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey removes the reservation of a request that failed, so that it can be retried. A key taken
+// over by another request is kept.
+func (r *Repository) ReleaseIdempotencyKey(ctx context.Context, scope, key, owner string) error {
+	query := `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND owner = $3 AND status_code IS NULL`
+
+	if _, err := r.db.Exec(ctx, query, scope, key, owner); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// PurgeIdempotencyKeys deletes keys created before the given time and returns their number.
+func (r *Repository) PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ListMultiBranchDealIDs retrieves IDs of deals created in [from, to) whose orders
+// were placed by more than one dealership.
+func (r *Repository) ListMultiBranchDealIDs(ctx context.Context, from, to time.Time) ([]int, error) {
+	query := `
+		SELECT d.deal_id
+		FROM deals d
+		JOIN orders o ON o.deal_id = d.deal_id AND o.deleted_at IS NULL
+		WHERE d.created_at >= $1 AND d.created_at < $2 AND d.deleted_at IS NULL
+		GROUP BY d.deal_id
+		HAVING COUNT(DISTINCT o.dealership_id) > 1
+		ORDER BY d.deal_id`
+
+	rows, err := r.db.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query multi-branch deals: %w", err)
+	}
+	defer rows.Close()
+
+	var dealIDs []int
+	for rows.Next() {
+		var dealID int
+		if err := rows.Scan(&dealID); err != nil {
+			return nil, fmt.Errorf("failed to scan deal id: %w", err)
+		}
+		dealIDs = append(dealIDs, dealID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating multi-branch deals: %w", err)
+	}
+
+	return dealIDs, nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// managerColumns is the column list selected for a manager.
+const managerColumns = `manager_id, dealership_id, full_name, COALESCE(phone, ''), COALESCE(email, ''), active,
+		created_at, updated_at`
+
+// scanManager scans a row selected with managerColumns into a manager.
+func scanManager(row pgx.Row) (*domain.Manager, error) {
+	var manager domain.Manager
+	err := row.Scan(
+		&manager.ManagerID, &manager.DealershipID, &manager.FullName, &manager.Phone, &manager.Email,
+		&manager.Active, &manager.CreatedAt, &manager.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &manager, nil
+}
+
+// ListManagers retrieves the managers matching the filter ordered by manager_id.
+func (r *Repository) ListManagers(ctx context.Context, filter domain.ManagerFilter) ([]*domain.Manager, error) {
+	query := `
+		SELECT ` + managerColumns + `
+		FROM managers
+		WHERE ($1::integer IS NULL OR dealership_id = $1) AND (NOT $2 OR active)
+		ORDER BY manager_id`
+
+	rows, err := r.db.Query(ctx, query, filter.DealershipID, filter.Active)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query managers: %w", err)
+	}
+	defer rows.Close()
+
+	var managers []*domain.Manager
+	for rows.Next() {
+		manager, err := scanManager(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan manager: %w", err)
+		}
+		managers = append(managers, manager)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating managers: %w", err)
+	}
+
+	return managers, nil
+}
+
+// GetManager retrieves a manager by its ID.
+func (r *Repository) GetManager(ctx context.Context, managerID int) (*domain.Manager, error) {
+	query := `SELECT ` + managerColumns + ` FROM managers WHERE manager_id = $1`
+
+	manager, err := scanManager(r.db.QueryRow(ctx, query, managerID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get manager: %w", err)
+	}
+	return manager, nil
+}
+
+// CreateManager adds a manager. ErrConflict is returned if the manager_id already exists.
+func (r *Repository) CreateManager(ctx context.Context, manager domain.Manager) (*domain.Manager, error) {
+	query := `
+		INSERT INTO managers (manager_id, dealership_id, full_name, phone, email, active, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (manager_id) DO NOTHING
+		RETURNING ` + managerColumns
+
+	created, err := scanManager(r.db.QueryRow(ctx, query,
+		manager.ManagerID, manager.DealershipID, manager.FullName, manager.Phone, manager.Email, manager.Active))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to create manager: %w", wrapConstraintError(err))
+	}
+
+	return created, nil
+}
+
+// UpdateManager updates the details, the dealership and the active flag of a manager.
+func (r *Repository) UpdateManager(ctx context.Context, manager domain.Manager) (*domain.Manager, error) {
+	query := `
+		UPDATE managers
+		SET dealership_id = $2, full_name = $3, phone = NULLIF($4, ''), email = NULLIF($5, ''), active = $6,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE manager_id = $1
+		RETURNING ` + managerColumns
+
+	updated, err := scanManager(r.db.QueryRow(ctx, query,
+		manager.ManagerID, manager.DealershipID, manager.FullName, manager.Phone, manager.Email, manager.Active))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update manager: %w", wrapConstraintError(err))
+	}
+
+	return updated, nil
+}
+
+// DeleteManager removes a manager. ErrConflict is returned if deals reference the manager;
+// deals.manager_id has no foreign key, so the check is part of the statement.
+func (r *Repository) DeleteManager(ctx context.Context, managerID int) error {
+	query := `
+		DELETE FROM managers m
+		WHERE m.manager_id = $1
+			AND NOT EXISTS (SELECT 1 FROM deals d WHERE d.manager_id = m.manager_id)`
+
+	result, err := r.db.Exec(ctx, query, managerID)
+	if err != nil {
+		return fmt.Errorf("failed to delete manager: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		if _, err := r.GetManager(ctx, managerID); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+
+	return nil
+}
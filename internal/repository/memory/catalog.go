@@ -0,0 +1,406 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// GetListPrice returns the list price of a vehicle from the catalog.
+func (r *Repository) GetListPrice(ctx context.Context, vehicleID int) (domain.Money, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	vehicle, ok := s.vehicles[vehicleID]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+	return vehicle.Price, nil
+}
+
+// ListVehicles retrieves the vehicles, optionally only the vehicles in the given condition.
+func (r *Repository) ListVehicles(ctx context.Context, condition string) ([]*domain.Vehicle, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var vehicles []*domain.Vehicle
+	for _, vehicleID := range sortedKeys(s.vehicles) {
+		if vehicle := s.vehicles[vehicleID]; condition == "" || vehicle.Condition == condition {
+			copied := *vehicle
+			vehicles = append(vehicles, &copied)
+		}
+	}
+	return vehicles, nil
+}
+
+// ListVehiclesByIDs retrieves the vehicles with the given IDs; unknown IDs are skipped.
+func (r *Repository) ListVehiclesByIDs(ctx context.Context, vehicleIDs []int) ([]*domain.Vehicle, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var vehicles []*domain.Vehicle
+	for _, vehicleID := range sortedKeys(s.vehicles) {
+		if slices.Contains(vehicleIDs, vehicleID) {
+			copied := *s.vehicles[vehicleID]
+			vehicles = append(vehicles, &copied)
+		}
+	}
+	return vehicles, nil
+}
+
+// GetVehicle retrieves a vehicle by its ID.
+func (r *Repository) GetVehicle(ctx context.Context, vehicleID int) (*domain.Vehicle, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	vehicle, ok := s.vehicles[vehicleID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *vehicle
+	return &copied, nil
+}
+
+// checkVIN reports a VIN already assigned to another vehicle.
+func (s *store) checkVIN(vehicle domain.Vehicle) error {
+	if vehicle.VIN == "" {
+		return nil
+	}
+	for _, other := range s.vehicles {
+		if other.VehicleID != vehicle.VehicleID && other.VIN == vehicle.VIN {
+			return uniqueError("vehicles", "vin")
+		}
+	}
+	return nil
+}
+
+// CreateVehicle adds a vehicle. ErrConflict is returned if the vehicle_id already exists;
+// a duplicate VIN is reported as a unique constraint violation.
+func (r *Repository) CreateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.vehicles[vehicle.VehicleID]; ok {
+		return nil, repository.ErrConflict
+	}
+	if err := s.checkVIN(vehicle); err != nil {
+		return nil, fmt.Errorf("failed to create vehicle: %w", err)
+	}
+	vehicle.CreatedAt = time.Now()
+	vehicle.UpdatedAt = vehicle.CreatedAt
+	s.vehicles[vehicle.VehicleID] = &vehicle
+
+	created := vehicle
+	return &created, nil
+}
+
+// UpdateVehicle updates a vehicle. ErrNotFound is returned if the vehicle does not exist.
+func (r *Repository) UpdateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	stored, ok := s.vehicles[vehicle.VehicleID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if err := s.checkVIN(vehicle); err != nil {
+		return nil, fmt.Errorf("failed to update vehicle: %w", err)
+	}
+	stored.VIN, stored.Model, stored.Price, stored.Condition = vehicle.VIN, vehicle.Model, vehicle.Price, vehicle.Condition
+	stored.UpdatedAt = time.Now()
+
+	updated := *stored
+	return &updated, nil
+}
+
+// DeleteVehicle removes a vehicle. ErrConflict is returned if orders reference the vehicle.
+func (r *Repository) DeleteVehicle(ctx context.Context, vehicleID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.vehicles[vehicleID]; !ok {
+		return repository.ErrNotFound
+	}
+	for _, order := range s.orders {
+		if sameInt(order.VehicleID, &vehicleID) {
+			return repository.ErrConflict
+		}
+	}
+	for _, appraisal := range s.appraisals {
+		if appraisal.VehicleID == vehicleID {
+			return repository.ErrConflict
+		}
+	}
+	delete(s.vehicles, vehicleID)
+	return nil
+}
+
+// ListOrderTypes retrieves the order types catalog.
+func (r *Repository) ListOrderTypes(ctx context.Context) ([]*domain.OrderType, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var orderTypes []*domain.OrderType
+	for _, orderTypeID := range sortedKeys(s.orderTypes) {
+		copied := *s.orderTypes[orderTypeID]
+		orderTypes = append(orderTypes, &copied)
+	}
+	return orderTypes, nil
+}
+
+// CreateOrderType adds an order type. ErrConflict is returned if the order_type_id already exists.
+func (r *Repository) CreateOrderType(ctx context.Context, orderType domain.OrderType) (*domain.OrderType, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.orderTypes[orderType.OrderTypeID]; ok {
+		return nil, repository.ErrConflict
+	}
+	s.orderTypes[orderType.OrderTypeID] = &orderType
+
+	created := orderType
+	return &created, nil
+}
+
+// UpdateOrderType updates an order type. ErrNotFound is returned if the order type does not exist.
+func (r *Repository) UpdateOrderType(ctx context.Context, orderType domain.OrderType) (*domain.OrderType, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	stored, ok := s.orderTypes[orderType.OrderTypeID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	*stored = orderType
+
+	updated := *stored
+	return &updated, nil
+}
+
+// DeleteOrderType removes an order type. ErrConflict is returned if orders still reference the type.
+// The netting rules of the type are removed with it.
+func (r *Repository) DeleteOrderType(ctx context.Context, orderTypeID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.orderTypes[orderTypeID]; !ok {
+		return repository.ErrNotFound
+	}
+	for _, order := range s.orders {
+		if order.OrderTypeID == orderTypeID {
+			return repository.ErrConflict
+		}
+	}
+	for _, trigger := range s.triggers {
+		if trigger.OrderTypeID == orderTypeID {
+			return repository.ErrConflict
+		}
+	}
+	for ruleID, rule := range s.nettingRules {
+		if rule.OrderTypeID == orderTypeID {
+			delete(s.nettingRules, ruleID)
+		}
+	}
+	delete(s.orderTypes, orderTypeID)
+	return nil
+}
+
+// ListCurrencies retrieves the currencies reference.
+func (r *Repository) ListCurrencies(ctx context.Context) ([]*domain.Currency, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var currencies []*domain.Currency
+	for _, code := range sortedKeys(s.currencies) {
+		copied := *s.currencies[code]
+		currencies = append(currencies, &copied)
+	}
+	return currencies, nil
+}
+
+// checkFXRate checks the constraints and the foreign keys of an exchange rate.
+func (s *store) checkFXRate(rate domain.FXRate) error {
+	switch {
+	case rate.Rate <= 0:
+		return checkError("fx_rates", "rate")
+	case rate.CurrencyCode == rate.PivotCurrencyCode:
+		return checkError("fx_rates", "currency_code")
+	}
+	if _, ok := s.currencies[rate.CurrencyCode]; !ok {
+		return foreignKeyError("fx_rates", "currency_code")
+	}
+	if _, ok := s.currencies[rate.PivotCurrencyCode]; !ok {
+		return foreignKeyError("fx_rates", "pivot_currency_code")
+	}
+	return nil
+}
+
+// insertFXRate stores a new version of an exchange rate.
+func (s *store) insertFXRate(rate domain.FXRate, source string) *domain.FXRate {
+	stored := &domain.FXRate{
+		RateID:            s.nextID("fx_rates"),
+		CurrencyCode:      rate.CurrencyCode,
+		PivotCurrencyCode: rate.PivotCurrencyCode,
+		Rate:              rate.Rate,
+		RateDate:          rate.RateDate,
+		Source:            source,
+		CreatedAt:         time.Now(),
+	}
+	s.fxRates = append(s.fxRates, stored)
+	return stored
+}
+
+// CreateFXRate stores a new version of an exchange rate.
+func (r *Repository) CreateFXRate(ctx context.Context, rate domain.FXRate) (*domain.FXRate, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if err := s.checkFXRate(rate); err != nil {
+		return nil, fmt.Errorf("failed to create exchange rate: %w", err)
+	}
+	created := *s.insertFXRate(rate, domain.FXRateSourceManual)
+	return &created, nil
+}
+
+// SaveCBRRates stores the Central Bank rates of a date as new versions of the exchange rates.
+// Rates already fetched for the date are kept; it returns the number of rates stored.
+func (r *Repository) SaveCBRRates(ctx context.Context, rates []domain.FXRate) (int, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	saved := 0
+	for _, rate := range rates {
+		if err := s.checkFXRate(rate); err != nil {
+			return saved, fmt.Errorf("failed to save exchange rate: %w", err)
+		}
+		fetched := slices.ContainsFunc(s.fxRates, func(other *domain.FXRate) bool {
+			return other.Source == domain.FXRateSourceCBR && other.CurrencyCode == rate.CurrencyCode &&
+				other.PivotCurrencyCode == rate.PivotCurrencyCode && other.RateDate == rate.RateDate
+		})
+		if fetched {
+			continue
+		}
+		s.insertFXRate(rate, domain.FXRateSourceCBR)
+		saved++
+	}
+	return saved, nil
+}
+
+// sortFXRatesNewestFirst sorts exchange rates by date and version, newest first.
+func sortFXRatesNewestFirst(rates []*domain.FXRate) {
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].RateDate != rates[j].RateDate {
+			return rates[i].RateDate > rates[j].RateDate
+		}
+		return rates[i].RateID > rates[j].RateID
+	})
+}
+
+// ListFXRates retrieves the exchange rates to the pivot currency, newest first, optionally
+// only the rates of one currency.
+func (r *Repository) ListFXRates(ctx context.Context, pivotCurrencyCode, currencyCode string) ([]*domain.FXRate, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var rates []*domain.FXRate
+	for _, rate := range s.fxRates {
+		if rate.PivotCurrencyCode == pivotCurrencyCode && (currencyCode == "" || rate.CurrencyCode == currencyCode) {
+			copied := *rate
+			rates = append(rates, &copied)
+		}
+	}
+	sortFXRatesNewestFirst(rates)
+	return rates, nil
+}
+
+// ListEffectiveFXRates retrieves the latest version of the rate of every currency to the pivot currency
+// effective on the date.
+func (r *Repository) ListEffectiveFXRates(ctx context.Context, pivotCurrencyCode string, date time.Time) ([]*domain.FXRate, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	day := date.Format(domain.DateLayout)
+	var candidates []*domain.FXRate
+	for _, rate := range s.fxRates {
+		if rate.PivotCurrencyCode == pivotCurrencyCode && rate.RateDate <= day {
+			candidates = append(candidates, rate)
+		}
+	}
+	sortFXRatesNewestFirst(candidates)
+
+	effective := map[string]*domain.FXRate{}
+	for _, rate := range candidates {
+		if _, ok := effective[rate.CurrencyCode]; !ok {
+			copied := *rate
+			effective[rate.CurrencyCode] = &copied
+		}
+	}
+
+	var rates []*domain.FXRate
+	for _, code := range sortedKeys(effective) {
+		rates = append(rates, effective[code])
+	}
+	return rates, nil
+}
+
+// ListCalendarDays retrieves business calendar exceptions between from and to inclusive.
+func (r *Repository) ListCalendarDays(ctx context.Context, from, to time.Time) ([]*domain.CalendarDay, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	first, last := from.Format(domain.DateLayout), to.Format(domain.DateLayout)
+	var days []*domain.CalendarDay
+	for _, date := range sortedKeys(s.calendar) {
+		if date >= first && date <= last {
+			copied := *s.calendar[date]
+			days = append(days, &copied)
+		}
+	}
+	return days, nil
+}
+
+// CreateCalendarDay adds a business calendar exception. ErrConflict is returned if the date already exists.
+func (r *Repository) CreateCalendarDay(ctx context.Context, day domain.CalendarDay) (*domain.CalendarDay, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.calendar[day.Date]; ok {
+		return nil, repository.ErrConflict
+	}
+	s.calendar[day.Date] = &day
+
+	created := day
+	return &created, nil
+}
+
+// UpdateCalendarDay updates a business calendar exception. ErrNotFound is returned if the date does not exist.
+func (r *Repository) UpdateCalendarDay(ctx context.Context, day domain.CalendarDay) (*domain.CalendarDay, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	stored, ok := s.calendar[day.Date]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	stored.Name, stored.IsWorkingDay = day.Name, day.IsWorkingDay
+
+	updated := *stored
+	return &updated, nil
+}
+
+// DeleteCalendarDay removes a business calendar exception. ErrNotFound is returned if the date does not exist.
+func (r *Repository) DeleteCalendarDay(ctx context.Context, date string) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.calendar[date]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(s.calendar, date)
+	return nil
+}
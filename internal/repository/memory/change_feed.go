@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"cliring/internal/domain"
+)
+
+// change is an entry of the change feed. Deal and order entries keep a copy of the row,
+// which GetDealWithOrdersAsOf reconstructs past states from.
+type change struct {
+	record domain.ChangeRecord
+	deal   *dealRow
+	order  *orderRow
+}
+
+// recordChange appends an entry to the change feed, as the triggers on the tables do in Postgres.
+func (s *store) recordChange(entity string, entityID int, action string, data map[string]any) *change {
+	c := &change{record: domain.ChangeRecord{
+		ChangeID:  int64(s.nextID("change_events")),
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    action,
+		Data:      data,
+		ChangedAt: time.Now(),
+	}}
+	s.changes = append(s.changes, c)
+	return c
+}
+
+// recordDeal records an insert, update or delete of a deal.
+func (s *store) recordDeal(action string, row *dealRow) {
+	snapshot := *row
+	s.recordChange(domain.EntityDeals, row.DealID, action, row.values()).deal = &snapshot
+}
+
+// recordOrder records an insert, update or delete of an order.
+func (s *store) recordOrder(action string, row *orderRow) {
+	snapshot := *row
+	s.recordChange(domain.EntityOrders, row.OrderID, action, row.values()).order = &snapshot
+}
+
+// recordSettlement records an insert, update or delete of a monetary settlement.
+func (s *store) recordSettlement(action string, row *settlementRow) {
+	s.recordChange(domain.EntityMonetarySettlements, row.MonetarySettlementID, action, row.values())
+}
+
+// ListChanges retrieves change records of an entity with a cursor greater than since, in cursor order.
+func (r *Repository) ListChanges(ctx context.Context, entity string, since int64, limit int) ([]*domain.ChangeRecord, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var changes []*domain.ChangeRecord
+	for _, c := range s.changes {
+		if len(changes) >= limit {
+			break
+		}
+		if c.record.Entity == entity && c.record.ChangeID > since {
+			record := c.record
+			changes = append(changes, &record)
+		}
+	}
+	return changes, nil
+}
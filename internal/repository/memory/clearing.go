@@ -0,0 +1,237 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// openClearingSession returns the open clearing session, opening one if there is none.
+func (s *store) openClearingSession() int {
+	for _, sessionID := range sortedKeys(s.sessions) {
+		if s.sessions[sessionID].Status == domain.ClearingSessionOpen {
+			return sessionID
+		}
+	}
+	return s.insertClearingSession()
+}
+
+// insertClearingSession opens a new clearing session.
+func (s *store) insertClearingSession() int {
+	session := &domain.ClearingSession{
+		SessionID: s.nextID("clearing_sessions"),
+		Status:    domain.ClearingSessionOpen,
+		OpenedAt:  time.Now(),
+	}
+	s.sessions[session.SessionID] = session
+	return session.SessionID
+}
+
+// sessionOut returns a copy of a clearing session with the counts of its orders and pending settlements.
+func (s *store) sessionOut(session *domain.ClearingSession) *domain.ClearingSession {
+	out := *session
+	out.OrderCount, out.PendingSettlements = 0, 0
+	for _, order := range s.orders {
+		if order.deletedAt == nil && sameInt(order.ClearingSessionID, &session.SessionID) {
+			out.OrderCount++
+		}
+	}
+	for _, settlement := range s.settlements {
+		if settlement.deletedAt == nil && settlement.Status == domain.StatusPending &&
+			sameInt(settlement.ClearingSessionID, &session.SessionID) {
+			out.PendingSettlements++
+		}
+	}
+	return &out
+}
+
+// GetClearingSession retrieves a clearing session by its ID.
+func (r *Repository) GetClearingSession(ctx context.Context, sessionID int) (*domain.ClearingSession, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return s.sessionOut(session), nil
+}
+
+// ListClearingSessions retrieves a page of clearing sessions, newest first, optionally in one status,
+// and the total number of such sessions.
+func (r *Repository) ListClearingSessions(ctx context.Context, status string, page, limit int) ([]*domain.ClearingSession, int, error) {
+	if err := validPage(page, limit); err != nil {
+		return nil, 0, err
+	}
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var matched []*domain.ClearingSession
+	sessionIDs := sortedKeys(s.sessions)
+	for i := len(sessionIDs) - 1; i >= 0; i-- {
+		session := s.sessions[sessionIDs[i]]
+		if status == "" || session.Status == status {
+			matched = append(matched, session)
+		}
+	}
+
+	var sessions []*domain.ClearingSession
+	for _, session := range pageOf(matched, page, limit) {
+		sessions = append(sessions, s.sessionOut(session))
+	}
+	return sessions, len(matched), nil
+}
+
+// StartComputingClearingSession moves an open session to computing and opens the next session,
+// so orders accepted from now on go to the next session. ErrConflict is returned if the session is not open.
+func (r *Repository) StartComputingClearingSession(ctx context.Context, sessionID int) (*domain.ClearingSession, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok || session.Status != domain.ClearingSessionOpen {
+		return nil, repository.ErrConflict
+	}
+	session.Status = domain.ClearingSessionComputing
+	session.ComputingAt = timePtr(time.Now())
+	s.insertClearingSession()
+
+	return s.sessionOut(session), nil
+}
+
+// TransitionClearingSession moves a session from one status to the next. A session is closed only when
+// none of its settlements is pending. ErrConflict is returned if the session is not in the from status.
+func (r *Repository) TransitionClearingSession(ctx context.Context, sessionID int, from, to string) (*domain.ClearingSession, error) {
+	switch to {
+	case domain.ClearingSessionComputing, domain.ClearingSessionSettled, domain.ClearingSessionClosed:
+	default:
+		return nil, fmt.Errorf("unknown clearing session status %q: %w", to, repository.ErrInvalidInput)
+	}
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok || session.Status != from {
+		return nil, repository.ErrConflict
+	}
+	if to == domain.ClearingSessionClosed && s.sessionOut(session).PendingSettlements > 0 {
+		return nil, repository.ErrConflict
+	}
+
+	now := time.Now()
+	session.Status = to
+	switch to {
+	case domain.ClearingSessionComputing:
+		session.ComputingAt = timePtr(now)
+	case domain.ClearingSessionSettled:
+		session.SettledAt = timePtr(now)
+	case domain.ClearingSessionClosed:
+		session.ClosedAt = timePtr(now)
+	}
+
+	return s.sessionOut(session), nil
+}
+
+// ClearingSessionDeals returns the IDs of the open deals with orders accepted in the session.
+func (r *Repository) ClearingSessionDeals(ctx context.Context, sessionID int) ([]int, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	deals := map[int]bool{}
+	for _, order := range s.orders {
+		if order.deletedAt != nil || !sameInt(order.ClearingSessionID, &sessionID) {
+			continue
+		}
+		deal, err := s.liveDeal(order.DealID)
+		if err == nil && (deal.Status == domain.DealStatusActive || deal.Status == domain.DealStatusClearing) {
+			deals[order.DealID] = true
+		}
+	}
+
+	var dealIDs []int
+	for _, dealID := range sortedKeys(deals) {
+		dealIDs = append(dealIDs, dealID)
+	}
+	return dealIDs, nil
+}
+
+// ListClearingSessionSettlements retrieves the settlements fixed by the computation of a session.
+func (r *Repository) ListClearingSessionSettlements(ctx context.Context, sessionID int) ([]*domain.MonetarySettlement, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var settlements []*domain.MonetarySettlement
+	for _, settlementID := range sortedKeys(s.settlements) {
+		row := s.settlements[settlementID]
+		if row.deletedAt == nil && sameInt(row.ClearingSessionID, &sessionID) {
+			settlements = append(settlements, s.settlementOut(row))
+		}
+	}
+	return settlements, nil
+}
+
+// ListOpenDeals retrieves the active and clearing deals, optionally of one client and of one dealership.
+// A deal belongs to a dealership if the dealership runs the deal or takes part in any of its orders.
+func (r *Repository) ListOpenDeals(ctx context.Context, clientID, dealershipID *int) ([]*domain.Deal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var deals []*domain.Deal
+	for _, dealID := range sortedKeys(s.deals) {
+		row := s.deals[dealID]
+		if row.deletedAt != nil || (row.Status != domain.DealStatusActive && row.Status != domain.DealStatusClearing) {
+			continue
+		}
+		if clientID != nil && row.ClientID != *clientID {
+			continue
+		}
+		if dealershipID != nil && row.DealershipID != *dealershipID && !s.dealershipTakesPart(dealID, *dealershipID) {
+			continue
+		}
+		deal := row.Deal
+		deals = append(deals, &deal)
+	}
+	return deals, nil
+}
+
+// dealershipTakesPart reports whether the dealership takes part in an order of the deal.
+func (s *store) dealershipTakesPart(dealID, dealershipID int) bool {
+	for _, order := range s.orders {
+		if order.DealID == dealID && order.deletedAt == nil && sameInt(order.DealershipID, &dealershipID) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListMultiBranchDealIDs retrieves the IDs of the deals created in [from, to) whose orders involve
+// more than one dealership.
+func (r *Repository) ListMultiBranchDealIDs(ctx context.Context, from, to time.Time) ([]int, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	dealerships := map[int]map[int]bool{}
+	for _, order := range s.orders {
+		if order.deletedAt != nil || order.DealershipID == nil {
+			continue
+		}
+		if dealerships[order.DealID] == nil {
+			dealerships[order.DealID] = map[int]bool{}
+		}
+		dealerships[order.DealID][*order.DealershipID] = true
+	}
+
+	var dealIDs []int
+	for _, dealID := range sortedKeys(s.deals) {
+		row := s.deals[dealID]
+		if row.deletedAt == nil && !row.CreatedAt.Before(from) && row.CreatedAt.Before(to) && len(dealerships[dealID]) > 1 {
+			dealIDs = append(dealIDs, dealID)
+		}
+	}
+	return dealIDs, nil
+}
@@ -0,0 +1,240 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// ListCreditContracts retrieves the credit contracts of a deal.
+func (r *Repository) ListCreditContracts(ctx context.Context, dealID int) ([]*domain.CreditContract, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var contracts []*domain.CreditContract
+	for _, contractID := range sortedKeys(s.contracts) {
+		if contract := s.contracts[contractID]; contract.DealID == dealID {
+			copied := *contract
+			contracts = append(contracts, &copied)
+		}
+	}
+	return contracts, nil
+}
+
+// GetCreditContract retrieves a credit contract by its ID.
+func (r *Repository) GetCreditContract(ctx context.Context, contractID int) (*domain.CreditContract, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	contract, ok := s.contracts[contractID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *contract
+	return &copied, nil
+}
+
+// checkCreditContract checks the foreign keys of a credit contract and the uniqueness of its number at the bank.
+func (s *store) checkCreditContract(contract domain.CreditContract) error {
+	if _, ok := s.deals[contract.DealID]; !ok {
+		return foreignKeyError("credit_contracts", "deal_id")
+	}
+	if _, ok := s.banks[contract.BankID]; !ok {
+		return foreignKeyError("credit_contracts", "bank_id")
+	}
+	if _, ok := s.currencies[contract.CurrencyCode]; !ok {
+		return foreignKeyError("credit_contracts", "currency_code")
+	}
+	for _, other := range s.contracts {
+		if other.CreditContractID != contract.CreditContractID && other.BankID == contract.BankID &&
+			other.ContractNumber == contract.ContractNumber {
+			return uniqueError("credit_contracts", "contract_number")
+		}
+	}
+	return nil
+}
+
+// CreateCreditContract attaches a credit contract to a deal.
+func (r *Repository) CreateCreditContract(ctx context.Context, contract domain.CreditContract) (*domain.CreditContract, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	contract.CreditContractID = 0
+	if err := s.checkCreditContract(contract); err != nil {
+		return nil, fmt.Errorf("failed to create credit contract: %w", err)
+	}
+	contract.CreditContractID = s.nextID("credit_contracts")
+	contract.CreatedAt = time.Now()
+	contract.UpdatedAt = contract.CreatedAt
+	s.contracts[contract.CreditContractID] = &contract
+
+	created := contract
+	return &created, nil
+}
+
+// UpdateCreditContract replaces the terms of a credit contract; the deal of the contract is kept.
+func (r *Repository) UpdateCreditContract(ctx context.Context, contract domain.CreditContract) (*domain.CreditContract, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	stored, ok := s.contracts[contract.CreditContractID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	contract.DealID = stored.DealID
+	if err := s.checkCreditContract(contract); err != nil {
+		return nil, fmt.Errorf("failed to update credit contract: %w", err)
+	}
+	stored.BankID, stored.ContractNumber, stored.Principal = contract.BankID, contract.ContractNumber, contract.Principal
+	stored.CurrencyCode, stored.TermMonths, stored.Rate = contract.CurrencyCode, contract.TermMonths, contract.Rate
+	stored.UpdatedAt = time.Now()
+
+	updated := *stored
+	return &updated, nil
+}
+
+// DeleteCreditContract removes a credit contract. ErrConflict is returned if orders reference the contract.
+func (r *Repository) DeleteCreditContract(ctx context.Context, contractID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.contracts[contractID]; !ok {
+		return repository.ErrNotFound
+	}
+	for _, order := range s.orders {
+		if sameInt(order.CreditContractID, &contractID) {
+			return repository.ErrConflict
+		}
+	}
+	delete(s.contracts, contractID)
+	return nil
+}
+
+// CountContractOrders returns the number of orders placed under a credit contract.
+func (r *Repository) CountContractOrders(ctx context.Context, contractID int) (int, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	count := 0
+	for _, order := range s.orders {
+		if order.deletedAt == nil && sameInt(order.CreditContractID, &contractID) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ListAppraisals retrieves the trade-in appraisals of a deal.
+func (r *Repository) ListAppraisals(ctx context.Context, dealID int) ([]*domain.TradeInAppraisal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var appraisals []*domain.TradeInAppraisal
+	for _, appraisalID := range sortedKeys(s.appraisals) {
+		if appraisal := s.appraisals[appraisalID]; appraisal.DealID == dealID {
+			copied := *appraisal
+			appraisals = append(appraisals, &copied)
+		}
+	}
+	return appraisals, nil
+}
+
+// GetAppraisal retrieves a trade-in appraisal by its ID.
+func (r *Repository) GetAppraisal(ctx context.Context, appraisalID int) (*domain.TradeInAppraisal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	appraisal, ok := s.appraisals[appraisalID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *appraisal
+	return &copied, nil
+}
+
+// CreateAppraisal stores a pending trade-in appraisal of a deal.
+func (r *Repository) CreateAppraisal(ctx context.Context, appraisal domain.TradeInAppraisal) (*domain.TradeInAppraisal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.deals[appraisal.DealID]; !ok {
+		return nil, fmt.Errorf("failed to create appraisal: %w", foreignKeyError("trade_in_appraisals", "deal_id"))
+	}
+	if _, ok := s.vehicles[appraisal.VehicleID]; !ok {
+		return nil, fmt.Errorf("failed to create appraisal: %w", foreignKeyError("trade_in_appraisals", "vehicle_id"))
+	}
+	now := time.Now()
+	stored := &domain.TradeInAppraisal{
+		AppraisalID: s.nextID("trade_in_appraisals"),
+		DealID:      appraisal.DealID,
+		VehicleID:   appraisal.VehicleID,
+		Amount:      appraisal.Amount,
+		Appraiser:   appraisal.Appraiser,
+		Status:      domain.AppraisalPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.appraisals[stored.AppraisalID] = stored
+
+	created := *stored
+	return &created, nil
+}
+
+// UpdateAppraisal replaces the vehicle, amount and appraiser of a pending appraisal. ErrConflict is returned
+// if the appraisal is not pending.
+func (r *Repository) UpdateAppraisal(ctx context.Context, appraisal domain.TradeInAppraisal) (*domain.TradeInAppraisal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	stored, ok := s.appraisals[appraisal.AppraisalID]
+	if !ok || stored.Status != domain.AppraisalPending {
+		return nil, repository.ErrConflict
+	}
+	if _, ok := s.vehicles[appraisal.VehicleID]; !ok {
+		return nil, fmt.Errorf("failed to update appraisal: %w", foreignKeyError("trade_in_appraisals", "vehicle_id"))
+	}
+	stored.VehicleID, stored.Amount, stored.Appraiser = appraisal.VehicleID, appraisal.Amount, appraisal.Appraiser
+	stored.UpdatedAt = time.Now()
+
+	updated := *stored
+	return &updated, nil
+}
+
+// ReviewAppraisal records the decision on a pending appraisal. ErrConflict is returned if the appraisal
+// is not pending.
+func (r *Repository) ReviewAppraisal(ctx context.Context, appraisalID int, status, reviewer, comment string) (*domain.TradeInAppraisal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	stored, ok := s.appraisals[appraisalID]
+	if !ok || stored.Status != domain.AppraisalPending {
+		return nil, repository.ErrConflict
+	}
+	now := time.Now()
+	stored.Status, stored.ReviewedBy, stored.Comment = status, reviewer, comment
+	stored.ReviewedAt = timePtr(now)
+	stored.UpdatedAt = now
+
+	reviewed := *stored
+	return &reviewed, nil
+}
+
+// DeleteAppraisal removes a trade-in appraisal. ErrConflict is returned if orders reference the appraisal.
+func (r *Repository) DeleteAppraisal(ctx context.Context, appraisalID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.appraisals[appraisalID]; !ok {
+		return repository.ErrNotFound
+	}
+	for _, order := range s.orders {
+		if sameInt(order.AppraisalID, &appraisalID) {
+			return repository.ErrConflict
+		}
+	}
+	delete(s.appraisals, appraisalID)
+	return nil
+}
@@ -0,0 +1,551 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// dealRow is a stored deal with the columns the domain type does not carry.
+type dealRow struct {
+	domain.Deal
+	deletedAt    *time.Time
+	anonymizedAt *time.Time
+}
+
+// values converts the row into a map of its JSON fields.
+func (d *dealRow) values() map[string]any {
+	values := jsonValues(d.Deal)
+	values["deleted_at"] = d.deletedAt
+	return values
+}
+
+// documentRow is a stored deal document.
+type documentRow struct {
+	domain.DealDocument
+	deletedAt *time.Time
+}
+
+// dealCounter holds the per-deal sequences of order numbers and settlement runs.
+type dealCounter struct {
+	lastOrderNumber   int
+	lastSettlementRun int
+}
+
+// counter returns the sequences of a deal, creating them on first use.
+func (s *store) counter(dealID int) *dealCounter {
+	c, ok := s.dealCounters[dealID]
+	if !ok {
+		c = &dealCounter{}
+		s.dealCounters[dealID] = c
+	}
+	return c
+}
+
+// liveDeal returns a deal that is not deleted.
+func (s *store) liveDeal(dealID int) (*dealRow, error) {
+	row, ok := s.deals[dealID]
+	if !ok || row.deletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	return row, nil
+}
+
+// checkDealReferences checks the foreign keys and the status of a deal.
+func (s *store) checkDealReferences(deal domain.Deal) error {
+	if _, ok := domain.DealTransitions[deal.Status]; !ok {
+		return checkError("deals", "status")
+	}
+	if _, ok := s.clients[deal.ClientID]; !ok {
+		return foreignKeyError("deals", "client_id")
+	}
+	if _, ok := s.dealerships[deal.DealershipID]; !ok {
+		return foreignKeyError("deals", "dealership_id")
+	}
+	return nil
+}
+
+// CreateDeal creates a new deal.
+func (r *Repository) CreateDeal(ctx context.Context, req domain.Deal) (*domain.Deal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.deals[req.DealID]; ok {
+		return nil, fmt.Errorf("failed to create deal: %w", uniqueError("deals", "deal_id"))
+	}
+	if err := s.checkDealReferences(req); err != nil {
+		return nil, fmt.Errorf("failed to create deal: %w", err)
+	}
+
+	now := time.Now()
+	row := &dealRow{Deal: domain.Deal{
+		DealID:       req.DealID,
+		Status:       req.Status,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		DealershipID: req.DealershipID,
+		ManagerID:    req.ManagerID,
+		ClientID:     req.ClientID,
+	}}
+	s.deals[row.DealID] = row
+	s.recordDeal("insert", row)
+	s.insertDealHistory(ctx, domain.DealActionCreated, nil, &row.Deal)
+
+	deal := row.Deal
+	return &deal, nil
+}
+
+// GetDeal retrieves a deal by its ID.
+func (r *Repository) GetDeal(ctx context.Context, dealID int) (*domain.Deal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveDeal(dealID)
+	if err != nil {
+		return nil, err
+	}
+	deal := row.Deal
+	return &deal, nil
+}
+
+// UpdateDeal applies a partial update to a deal. Only the fields set in req are changed.
+func (r *Repository) UpdateDeal(ctx context.Context, dealID int, req domain.DealUpdate) (*domain.Deal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveDeal(dealID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := row.Deal
+	if req.DealershipID != nil {
+		updated.DealershipID = *req.DealershipID
+	}
+	if req.ManagerID != nil {
+		updated.ManagerID = *req.ManagerID
+	}
+	if _, ok := s.dealerships[updated.DealershipID]; !ok {
+		return nil, fmt.Errorf("failed to update deal: %w", foreignKeyError("deals", "dealership_id"))
+	}
+	updated.UpdatedAt = time.Now()
+
+	oldDeal := row.Deal
+	row.Deal = updated
+	s.recordDeal("update", row)
+	s.insertDealHistory(ctx, domain.DealActionUpdated, &oldDeal, &row.Deal)
+
+	deal := row.Deal
+	return &deal, nil
+}
+
+// UpdateDealStatus moves a deal from one status to another. The update only succeeds if the deal
+// is still in the from status, otherwise ErrConflict is returned.
+func (r *Repository) UpdateDealStatus(ctx context.Context, dealID int, from, to string) (*domain.Deal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveDeal(dealID)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != from {
+		return nil, repository.ErrConflict
+	}
+	if _, ok := domain.DealTransitions[to]; !ok {
+		return nil, fmt.Errorf("failed to update deal status: %w", checkError("deals", "status"))
+	}
+
+	oldDeal := row.Deal
+	row.Status = to
+	row.UpdatedAt = time.Now()
+	s.recordDeal("update", row)
+	s.insertDealHistory(ctx, domain.DealActionStatusChanged, &oldDeal, &row.Deal)
+
+	deal := row.Deal
+	return &deal, nil
+}
+
+// DeleteDeal soft-deletes a deal by its ID along with related orders and monetary settlements.
+// The deal must be scheduled for deletion with an expired grace period, otherwise ErrConflict is returned.
+func (r *Repository) DeleteDeal(ctx context.Context, dealID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveDeal(dealID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if row.DeletionScheduledAt == nil || row.DeletionScheduledAt.After(now) {
+		return repository.ErrConflict
+	}
+
+	row.deletedAt = timePtr(now)
+	s.recordDeal("update", row)
+	for _, orderID := range sortedKeys(s.orders) {
+		order := s.orders[orderID]
+		if order.DealID == dealID && order.deletedAt == nil {
+			order.deletedAt = timePtr(now)
+			s.recordOrder("update", order)
+		}
+	}
+	for _, settlementID := range sortedKeys(s.settlements) {
+		settlement := s.settlements[settlementID]
+		if settlement.DealID != nil && *settlement.DealID == dealID && settlement.deletedAt == nil {
+			settlement.deletedAt = timePtr(now)
+			s.recordSettlement("update", settlement)
+		}
+	}
+	s.insertDealHistory(ctx, domain.DealActionDeleted, &row.Deal, nil)
+	s.recordDeal("delete", row)
+
+	return nil
+}
+
+// ScheduleDealDeletion marks a deal as pending deletion at the given time.
+// ErrConflict is returned if the deletion is already scheduled.
+func (r *Repository) ScheduleDealDeletion(ctx context.Context, dealID int, at time.Time) (*domain.Deal, error) {
+	return r.setDealDeletionSchedule(ctx, dealID, &at, domain.DealActionDeletionScheduled)
+}
+
+// RestoreDeal cancels a scheduled deletion of a deal.
+// ErrConflict is returned if the deal is not pending deletion.
+func (r *Repository) RestoreDeal(ctx context.Context, dealID int) (*domain.Deal, error) {
+	return r.setDealDeletionSchedule(ctx, dealID, nil, domain.DealActionRestored)
+}
+
+// setDealDeletionSchedule sets or clears the deletion schedule and records the change in the deal history.
+func (r *Repository) setDealDeletionSchedule(ctx context.Context, dealID int, at *time.Time, action string) (*domain.Deal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveDeal(dealID)
+	if err != nil {
+		return nil, err
+	}
+	if (at != nil) == (row.DeletionScheduledAt != nil) {
+		return nil, repository.ErrConflict
+	}
+
+	oldDeal := row.Deal
+	row.DeletionScheduledAt = at
+	row.UpdatedAt = time.Now()
+	s.recordDeal("update", row)
+	s.insertDealHistory(ctx, action, &oldDeal, &row.Deal)
+
+	deal := row.Deal
+	return &deal, nil
+}
+
+// ListDealsDueForDeletion retrieves IDs of deals whose deletion grace window has expired by now.
+func (r *Repository) ListDealsDueForDeletion(ctx context.Context, now time.Time) ([]int, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var due []*dealRow
+	for _, row := range s.deals {
+		if row.deletedAt == nil && row.DeletionScheduledAt != nil && !row.DeletionScheduledAt.After(now) {
+			due = append(due, row)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].DeletionScheduledAt.Before(*due[j].DeletionScheduledAt)
+	})
+
+	var dealIDs []int
+	for _, row := range due {
+		dealIDs = append(dealIDs, row.DealID)
+	}
+	return dealIDs, nil
+}
+
+// insertDealHistory records a deal mutation. Only fields that differ between oldDeal and newDeal
+// are stored; a nil deal means the deal did not exist before (or after) the mutation.
+func (s *store) insertDealHistory(ctx context.Context, action string, oldDeal, newDeal *domain.Deal) {
+	var oldValues, newValues map[string]any
+	if oldDeal != nil {
+		oldValues = jsonValues(oldDeal)
+	}
+	if newDeal != nil {
+		newValues = jsonValues(newDeal)
+	}
+	if oldValues != nil && newValues != nil {
+		for field := range oldValues {
+			if field == "updated_at" || reflect.DeepEqual(oldValues[field], newValues[field]) {
+				delete(oldValues, field)
+				delete(newValues, field)
+			}
+		}
+	}
+
+	dealID := 0
+	if oldDeal != nil {
+		dealID = oldDeal.DealID
+	} else if newDeal != nil {
+		dealID = newDeal.DealID
+	}
+	s.dealHistory = append(s.dealHistory, &domain.DealHistoryEntry{
+		HistoryID: s.nextID("deal_history"),
+		DealID:    dealID,
+		Action:    action,
+		ChangedBy: actorFromContext(ctx),
+		ChangedAt: time.Now(),
+		OldValues: oldValues,
+		NewValues: newValues,
+	})
+}
+
+// ListDealHistory retrieves the change history of a deal, oldest first.
+func (r *Repository) ListDealHistory(ctx context.Context, dealID int) ([]*domain.DealHistoryEntry, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var entries []*domain.DealHistoryEntry
+	for _, entry := range s.dealHistory {
+		if entry.DealID == dealID {
+			copied := *entry
+			entries = append(entries, &copied)
+		}
+	}
+	return entries, nil
+}
+
+// GetDealWithOrders retrieves a deal and all its orders, sorted as requested.
+func (r *Repository) GetDealWithOrders(ctx context.Context, dealID int, sort domain.OrderSort) (*domain.Deal, []*domain.Order, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveDeal(dealID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var orders []*domain.Order
+	for _, order := range s.orders {
+		if order.DealID == dealID && order.deletedAt == nil {
+			orders = append(orders, s.orderOut(order))
+		}
+	}
+	if err := sortOrders(orders, sort); err != nil {
+		return nil, nil, err
+	}
+
+	deal := row.Deal
+	return &deal, orders, nil
+}
+
+// GetDealWithOrdersAsOf reconstructs a deal and its orders in the state they had at asOf from the change feed.
+// Paid amounts only include allocations made up to asOf.
+func (r *Repository) GetDealWithOrdersAsOf(ctx context.Context, dealID int, asOf time.Time, sort domain.OrderSort) (*domain.Deal, []*domain.Order, error) {
+	if err := sortOrders(nil, sort); err != nil {
+		return nil, nil, err
+	}
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	// Последнее изменение каждой записи до asOf - ее состояние на этот момент
+	deals := map[int]*change{}
+	orders := map[int]*change{}
+	for _, c := range s.changes {
+		if c.record.ChangedAt.After(asOf) {
+			break
+		}
+		switch c.record.Entity {
+		case domain.EntityDeals:
+			deals[c.record.EntityID] = c
+		case domain.EntityOrders:
+			orders[c.record.EntityID] = c
+		}
+	}
+
+	dealChange, ok := deals[dealID]
+	if !ok || dealChange.record.Action == "delete" || dealChange.deal.deletedAt != nil {
+		return nil, nil, repository.ErrNotFound
+	}
+	deal := dealChange.deal.Deal
+
+	var result []*domain.Order
+	for _, c := range orders {
+		if c.record.Action == "delete" || c.order.deletedAt != nil || c.order.DealID != dealID {
+			continue
+		}
+		order := c.order.Order
+		order.Invoice, order.ClearingSessionID, order.TriggerID = nil, nil, nil
+		order.PaidAmount = 0
+		for _, allocation := range s.allocations {
+			if allocation.OrderID == order.OrderID && !allocation.CreatedAt.After(asOf) {
+				order.PaidAmount += allocation.Amount
+			}
+		}
+		order.OutstandingAmount = order.GrossAmount - order.PaidAmount
+		result = append(result, &order)
+	}
+	if err := sortOrders(result, sort); err != nil {
+		return nil, nil, err
+	}
+
+	return &deal, result, nil
+}
+
+// heldDealLocksKey is the context key of the set of deals locked with LockDeal by the caller.
+type heldDealLocksKey struct{}
+
+// dealLock returns the lock of a deal of the ctx tenant, creating it on first use.
+func (r *Repository) dealLock(ctx context.Context, dealID int) chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tenant := tenantOf(ctx)
+	locks, ok := r.dealLocks[tenant]
+	if !ok {
+		locks = map[int]chan struct{}{}
+		r.dealLocks[tenant] = locks
+	}
+	lock, ok := locks[dealID]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		locks[dealID] = lock
+	}
+	return lock
+}
+
+// LockDeal locks a deal, blocking until it is available or ctx is done, and serializes netting of the deal
+// with order writes. The returned context marks the deal as locked: LockDeal called with it again for the
+// same deal returns at once.
+func (r *Repository) LockDeal(ctx context.Context, dealID int) (context.Context, func(), error) {
+	held, _ := ctx.Value(heldDealLocksKey{}).(map[int]bool)
+	if held[dealID] {
+		return ctx, func() {}, nil
+	}
+
+	unlock, err := r.lockDeals(ctx, dealID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	locked := make(map[int]bool, len(held)+1)
+	for id := range held {
+		locked[id] = true
+	}
+	locked[dealID] = true
+	return context.WithValue(ctx, heldDealLocksKey{}, locked), unlock, nil
+}
+
+// lockDeals locks the deals not yet locked by the caller in ascending order to avoid deadlocks.
+func (r *Repository) lockDeals(ctx context.Context, dealIDs ...int) (func(), error) {
+	held, _ := ctx.Value(heldDealLocksKey{}).(map[int]bool)
+	ids := slices.Clone(dealIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	var taken []chan struct{}
+	release := func() {
+		for _, lock := range taken {
+			<-lock
+		}
+	}
+	for _, dealID := range ids {
+		if held[dealID] {
+			continue
+		}
+		lock := r.dealLock(ctx, dealID)
+		select {
+		case lock <- struct{}{}:
+			taken = append(taken, lock)
+		case <-ctx.Done():
+			release()
+			return nil, fmt.Errorf("failed to lock deal %d: %w", dealID, ctx.Err())
+		}
+	}
+
+	var once sync.Once
+	return func() { once.Do(release) }, nil
+}
+
+// CreateDealDocument stores the metadata of an uploaded deal document.
+func (r *Repository) CreateDealDocument(ctx context.Context, document *domain.DealDocument) (*domain.DealDocument, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.deals[document.DealID]; !ok {
+		return nil, fmt.Errorf("failed to create deal document: %w", foreignKeyError("deal_documents", "deal_id"))
+	}
+	if document.SizeBytes < 0 {
+		return nil, fmt.Errorf("failed to create deal document: %w", checkError("deal_documents", "size_bytes"))
+	}
+	for _, existing := range s.dealDocuments {
+		if existing.StorageKey == document.StorageKey {
+			return nil, fmt.Errorf("failed to create deal document: %w", uniqueError("deal_documents", "storage_key"))
+		}
+	}
+
+	row := &documentRow{DealDocument: domain.DealDocument{
+		DocumentID:  s.nextID("deal_documents"),
+		DealID:      document.DealID,
+		FileName:    document.FileName,
+		ContentType: document.ContentType,
+		SizeBytes:   document.SizeBytes,
+		StorageKey:  document.StorageKey,
+		UploadedBy:  actorFromContext(ctx),
+		CreatedAt:   time.Now(),
+	}}
+	s.dealDocuments[row.DocumentID] = row
+
+	created := row.DealDocument
+	return &created, nil
+}
+
+// ListDealDocuments retrieves the documents attached to a deal, newest first.
+func (r *Repository) ListDealDocuments(ctx context.Context, dealID int) ([]*domain.DealDocument, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var documents []*domain.DealDocument
+	for _, row := range s.dealDocuments {
+		if row.DealID == dealID && row.deletedAt == nil {
+			document := row.DealDocument
+			documents = append(documents, &document)
+		}
+	}
+	sort.Slice(documents, func(i, j int) bool {
+		if !documents[i].CreatedAt.Equal(documents[j].CreatedAt) {
+			return documents[i].CreatedAt.After(documents[j].CreatedAt)
+		}
+		return documents[i].DocumentID > documents[j].DocumentID
+	})
+	return documents, nil
+}
+
+// GetDealDocument retrieves a document of a deal by its ID.
+func (r *Repository) GetDealDocument(ctx context.Context, dealID, documentID int) (*domain.DealDocument, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, ok := s.dealDocuments[documentID]
+	if !ok || row.DealID != dealID || row.deletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	document := row.DealDocument
+	return &document, nil
+}
+
+// DeleteDealDocument soft-deletes a document of a deal.
+func (r *Repository) DeleteDealDocument(ctx context.Context, dealID, documentID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, ok := s.dealDocuments[documentID]
+	if !ok || row.DealID != dealID || row.deletedAt != nil {
+		return repository.ErrNotFound
+	}
+	row.deletedAt = timePtr(time.Now())
+	return nil
+}
@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+	"cliring/pkg/postgres"
+)
+
+// schemaVersion is the version of the last migration; the in-memory store always matches it.
+const schemaVersion int32 = 57
+
+// Ping always succeeds: the in-memory store has no connection to lose.
+func (r *Repository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Available always reports true.
+func (r *Repository) Available() bool {
+	return true
+}
+
+// SchemaVersion returns the version of the schema the in-memory store mirrors.
+func (r *Repository) SchemaVersion() int32 {
+	return schemaVersion
+}
+
+// ProvisionTenant registers a tenant; its store is created on first use. ErrConflict is returned if the tenant
+// is already provisioned, ErrInvalidInput for an invalid id.
+func (r *Repository) ProvisionTenant(ctx context.Context, tenantID, name string) (*domain.Tenant, error) {
+	if !postgres.ValidTenantID(tenantID) {
+		return nil, repository.ErrInvalidInput
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tenants[tenantID]; ok {
+		return nil, repository.ErrConflict
+	}
+	now := time.Now()
+	tenant := &domain.Tenant{TenantID: tenantID, Name: name, CreatedAt: now, ProvisionedAt: timePtr(now)}
+	r.tenants[tenantID] = tenant
+
+	provisioned := *tenant
+	return &provisioned, nil
+}
+
+// ListTenants retrieves the registered tenants ordered by id.
+func (r *Repository) ListTenants(ctx context.Context) ([]*domain.Tenant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tenants := make([]*domain.Tenant, 0, len(r.tenants))
+	for _, tenantID := range sortedKeys(r.tenants) {
+		tenant := *r.tenants[tenantID]
+		tenants = append(tenants, &tenant)
+	}
+	return tenants, nil
+}
+
+// HasTenant reports whether the tenant is registered.
+func (r *Repository) HasTenant(ctx context.Context, tenantID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.tenants[tenantID]
+	return ok, nil
+}
+
+// PurgeDeals hard-deletes deals soft-deleted before cutoff. Deals that still have orders or
+// monetary settlements are kept until those are purged. With anonymize set the personal data
+// of the deals is scrubbed instead and the rows are kept.
+func (r *Repository) PurgeDeals(ctx context.Context, cutoff time.Time, anonymize bool) (int64, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	referenced := map[int]bool{}
+	for _, order := range s.orders {
+		referenced[order.DealID] = true
+	}
+	for _, settlement := range s.settlements {
+		if settlement.DealID != nil {
+			referenced[*settlement.DealID] = true
+		}
+	}
+
+	now := time.Now()
+	var purged int64
+	for dealID, deal := range s.deals {
+		if deal.deletedAt == nil || !deal.deletedAt.Before(cutoff) {
+			continue
+		}
+		switch {
+		case anonymize && deal.anonymizedAt == nil:
+			deal.ClientID, deal.ManagerID = 0, 0
+			deal.anonymizedAt = timePtr(now)
+		case anonymize || referenced[dealID]:
+			continue
+		default:
+			delete(s.deals, dealID)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// PurgeOrders hard-deletes orders soft-deleted before cutoff together with their allocations.
+// With anonymize set the external references of the orders are scrubbed instead.
+func (r *Repository) PurgeOrders(ctx context.Context, cutoff time.Time, anonymize bool) (int64, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	now := time.Now()
+	var purged int64
+	for orderID, order := range s.orders {
+		if order.deletedAt == nil || !order.deletedAt.Before(cutoff) {
+			continue
+		}
+		switch {
+		case anonymize && order.anonymizedAt == nil:
+			order.NeedAndOrdersID = nil
+			order.anonymizedAt = timePtr(now)
+		case anonymize:
+			continue
+		default:
+			s.allocations = slices.DeleteFunc(s.allocations, func(allocation *domain.OrderAllocation) bool {
+				return allocation.OrderID == orderID
+			})
+			delete(s.orders, orderID)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// PurgeMonetarySettlements hard-deletes monetary settlements soft-deleted before cutoff together
+// with their allocations.
+func (r *Repository) PurgeMonetarySettlements(ctx context.Context, cutoff time.Time) (int64, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var purged int64
+	for settlementID, settlement := range s.settlements {
+		if settlement.deletedAt == nil || !settlement.deletedAt.Before(cutoff) {
+			continue
+		}
+		s.allocations = slices.DeleteFunc(s.allocations, func(allocation *domain.OrderAllocation) bool {
+			return allocation.MonetarySettlementID == settlementID
+		})
+		delete(s.settlements, settlementID)
+		purged++
+	}
+	return purged, nil
+}
+
+// ListSoftDeleted retrieves soft-deleted rows of an entity deleted before the given time
+// that have not been anonymized yet.
+func (r *Repository) ListSoftDeleted(ctx context.Context, entity string, deletedBefore time.Time) ([]*domain.UpcomingPurge, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var purges []*domain.UpcomingPurge
+	add := func(id int, deletedAt, anonymizedAt *time.Time) {
+		if deletedAt != nil && deletedAt.Before(deletedBefore) && anonymizedAt == nil {
+			purges = append(purges, &domain.UpcomingPurge{Entity: entity, ID: id, DeletedAt: *deletedAt})
+		}
+	}
+
+	switch entity {
+	case domain.EntityDeals:
+		for dealID, deal := range s.deals {
+			add(dealID, deal.deletedAt, deal.anonymizedAt)
+		}
+	case domain.EntityOrders:
+		for orderID, order := range s.orders {
+			add(orderID, order.deletedAt, order.anonymizedAt)
+		}
+	case domain.EntityMonetarySettlements:
+		for settlementID, settlement := range s.settlements {
+			add(settlementID, settlement.deletedAt, nil)
+		}
+	default:
+		return nil, fmt.Errorf("unknown entity %q: %w", entity, repository.ErrInvalidInput)
+	}
+
+	sort.Slice(purges, func(i, j int) bool {
+		if !purges[i].DeletedAt.Equal(purges[j].DeletedAt) {
+			return purges[i].DeletedAt.Before(purges[j].DeletedAt)
+		}
+		return purges[i].ID < purges[j].ID
+	})
+	return purges, nil
+}
@@ -0,0 +1,234 @@
+// Package memory implements the storage of the service on maps kept in process memory. It reports
+// failures with the errors of the repository package, so service and handler tests, as well as the
+// demo mode, run without Postgres. The data is lost when the process exits.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// Repository keeps the data of every tenant in its own store, as Postgres keeps it in a schema per tenant.
+// A single mutex guards all stores: every method is one short critical section, like a transaction.
+type Repository struct {
+	mu      sync.Mutex
+	stores  map[string]*store
+	tenants map[string]*domain.Tenant
+	// dealLocks are the locks taken with LockDeal, by tenant and deal.
+	dealLocks map[string]map[int]chan struct{}
+}
+
+// New creates an empty Repository. Every tenant store starts with the reference data the migrations seed.
+func New() *Repository {
+	return &Repository{
+		stores:    map[string]*store{},
+		tenants:   map[string]*domain.Tenant{},
+		dealLocks: map[string]map[int]chan struct{}{},
+	}
+}
+
+// store holds the tables of one tenant.
+type store struct {
+	ids map[string]int
+
+	deals          map[int]*dealRow
+	dealHistory    []*domain.DealHistoryEntry
+	dealDocuments  map[int]*documentRow
+	dealCounters   map[int]*dealCounter
+	orders         map[int]*orderRow
+	revisions      []*domain.OrderRevision
+	statusHistory  []*orderStatusChange
+	reviews        map[int]*domain.OrderReview
+	settlements    map[int]*settlementRow
+	allocations    []*domain.OrderAllocation
+	ledger         []*domain.LedgerEntry
+	payments       []*domain.SettlementPayment
+	penalties      []*domain.SettlementPenalty
+	paymentDetails map[string]*domain.PaymentDetails
+	sessions       map[int]*domain.ClearingSession
+
+	banks               map[int]*domain.Bank
+	clients             map[int]*domain.Client
+	anonymizationTokens map[string]*anonymizationToken
+	dealerships         map[int]*domain.Dealership
+	managers            map[int]*domain.Manager
+
+	vehicles     map[int]*domain.Vehicle
+	orderTypes   map[int]*domain.OrderType
+	currencies   map[string]*domain.Currency
+	fxRates      []*domain.FXRate
+	calendar     map[string]*domain.CalendarDay
+	nettingRules map[int]*domain.NettingRuleConfig
+	triggers     map[int]*domain.ObligationTrigger
+	snapshots    []*domain.NettingSnapshot
+	contracts    map[int]*domain.CreditContract
+	appraisals   map[int]*domain.TradeInAppraisal
+
+	statementLines []*domain.BankStatementLine
+	confirmations  []*domain.PaymentConfirmation
+	webhooks       map[int]*domain.WebhookSubscription
+	deliveries     []*webhookDelivery
+
+	audit           []*domain.AuditEntry
+	revokedTokens   map[string]*domain.TokenRevocation
+	idempotencyKeys map[idempotencyKey]*idempotencyEntry
+	rateLimits      map[string]*rateLimit
+	changes         []*change
+}
+
+// newStore creates the tables of a tenant with the reference data the migrations seed.
+func newStore() *store {
+	s := &store{
+		ids:                 map[string]int{},
+		deals:               map[int]*dealRow{},
+		dealDocuments:       map[int]*documentRow{},
+		dealCounters:        map[int]*dealCounter{},
+		orders:              map[int]*orderRow{},
+		reviews:             map[int]*domain.OrderReview{},
+		settlements:         map[int]*settlementRow{},
+		paymentDetails:      map[string]*domain.PaymentDetails{},
+		sessions:            map[int]*domain.ClearingSession{},
+		banks:               map[int]*domain.Bank{},
+		clients:             map[int]*domain.Client{},
+		anonymizationTokens: map[string]*anonymizationToken{},
+		dealerships:         map[int]*domain.Dealership{},
+		managers:            map[int]*domain.Manager{},
+		vehicles:            map[int]*domain.Vehicle{},
+		orderTypes:          map[int]*domain.OrderType{},
+		currencies:          map[string]*domain.Currency{},
+		calendar:            map[string]*domain.CalendarDay{},
+		nettingRules:        map[int]*domain.NettingRuleConfig{},
+		triggers:            map[int]*domain.ObligationTrigger{},
+		contracts:           map[int]*domain.CreditContract{},
+		appraisals:          map[int]*domain.TradeInAppraisal{},
+		webhooks:            map[int]*domain.WebhookSubscription{},
+		revokedTokens:       map[string]*domain.TokenRevocation{},
+		idempotencyKeys:     map[idempotencyKey]*idempotencyEntry{},
+		rateLimits:          map[string]*rateLimit{},
+	}
+	s.seed()
+	return s
+}
+
+// nextID issues the next value of the identity column of a table.
+func (s *store) nextID(table string) int {
+	s.ids[table]++
+	return s.ids[table]
+}
+
+// tenantOf returns the tenant of the request; requests without a tenant work with the public store.
+func tenantOf(ctx context.Context) string {
+	tenant, _ := ctx.Value(domain.TenantKey{}).(string)
+	return tenant
+}
+
+// lock takes the repository mutex and returns the store of the ctx tenant, creating it on first use.
+func (r *Repository) lock(ctx context.Context) *store {
+	r.mu.Lock()
+	tenant := tenantOf(ctx)
+	s, ok := r.stores[tenant]
+	if !ok {
+		s = newStore()
+		r.stores[tenant] = s
+	}
+	return s
+}
+
+// unlock releases the repository mutex taken with lock.
+func (r *Repository) unlock() {
+	r.mu.Unlock()
+}
+
+// actorFromContext returns the user performing the request, as put into the context by the auth middleware.
+func actorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(domain.ActorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return domain.ActorSystem
+}
+
+// validPage reports an invalid page or limit the way the Postgres repository does.
+func validPage(page, limit int) error {
+	if page < 1 || limit < 1 {
+		return fmt.Errorf("invalid pagination parameters: %w", repository.ErrInvalidInput)
+	}
+	return nil
+}
+
+// pageOf returns the items of a page of a sorted list.
+func pageOf[T any](items []T, page, limit int) []T {
+	start := (page - 1) * limit
+	if start >= len(items) {
+		return nil
+	}
+	end := min(start+limit, len(items))
+	return items[start:end]
+}
+
+// sortedKeys returns the keys of a map in ascending order.
+func sortedKeys[K int | string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// constraintError describes a violation of a constraint the Postgres schema enforces.
+func constraintError(kind, table, column string) error {
+	return &domain.ConstraintError{Kind: kind, Table: table, Column: column, Constraint: table + "_" + column + "_" + kind}
+}
+
+// foreignKeyError reports a reference to a row that does not exist.
+func foreignKeyError(table, column string) error {
+	return constraintError(domain.ConstraintForeignKey, table, column)
+}
+
+// checkError reports a value rejected by a check constraint.
+func checkError(table, column string) error {
+	return constraintError(domain.ConstraintCheck, table, column)
+}
+
+// uniqueError reports a duplicate of a unique value.
+func uniqueError(table, column string) error {
+	return constraintError(domain.ConstraintUnique, table, column)
+}
+
+// jsonValues converts a row into a map of its JSON fields, as to_jsonb does in Postgres.
+func jsonValues(row any) map[string]any {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return nil
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+// timePtr returns a pointer to a copy of t.
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+// intPtr returns a pointer to a copy of v.
+func intPtr(v int) *int {
+	return &v
+}
+
+// sameInt reports whether two nullable integers are equal.
+func sameInt(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,309 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// ListNettingRules retrieves the configured netting rules, optionally of one order type.
+func (r *Repository) ListNettingRules(ctx context.Context, orderTypeID int) ([]*domain.NettingRuleConfig, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var rules []*domain.NettingRuleConfig
+	for _, rule := range s.nettingRules {
+		if orderTypeID == 0 || rule.OrderTypeID == orderTypeID {
+			copied := *rule
+			rules = append(rules, &copied)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].OrderTypeID != rules[j].OrderTypeID {
+			return rules[i].OrderTypeID < rules[j].OrderTypeID
+		}
+		return rules[i].ValidFrom < rules[j].ValidFrom
+	})
+	return rules, nil
+}
+
+// ListEffectiveNettingRules retrieves the netting rules in effect on the date.
+func (r *Repository) ListEffectiveNettingRules(ctx context.Context, date time.Time) ([]*domain.NettingRuleConfig, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	day := date.Format(domain.DateLayout)
+	var rules []*domain.NettingRuleConfig
+	for _, rule := range s.nettingRules {
+		if rule.ValidFrom <= day && (rule.ValidTo == "" || rule.ValidTo >= day) {
+			copied := *rule
+			rules = append(rules, &copied)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].OrderTypeID < rules[j].OrderTypeID })
+	return rules, nil
+}
+
+// overlaps reports whether two inclusive validity periods overlap; an empty end never expires.
+func overlaps(fromA, toA, fromB, toB string) bool {
+	return (toB == "" || fromA <= toB) && (toA == "" || fromB <= toA)
+}
+
+// checkNettingRule reports an unknown order type or a validity period overlapping another rule of the type.
+func (s *store) checkNettingRule(rule domain.NettingRuleConfig) error {
+	if _, ok := s.orderTypes[rule.OrderTypeID]; !ok {
+		return repository.ErrNotFound
+	}
+	for _, other := range s.nettingRules {
+		if other.RuleID != rule.RuleID && other.OrderTypeID == rule.OrderTypeID &&
+			overlaps(other.ValidFrom, other.ValidTo, rule.ValidFrom, rule.ValidTo) {
+			return repository.ErrConflict
+		}
+	}
+	return nil
+}
+
+// CreateNettingRule stores a configured netting rule. ErrNotFound is returned for an unknown order type,
+// ErrConflict if the validity period overlaps another rule of the order type.
+func (r *Repository) CreateNettingRule(ctx context.Context, rule domain.NettingRuleConfig) (*domain.NettingRuleConfig, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	rule.RuleID = 0
+	if err := s.checkNettingRule(rule); err != nil {
+		return nil, err
+	}
+	rule.RuleID = s.nextID("netting_rules")
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = rule.CreatedAt
+	s.nettingRules[rule.RuleID] = &rule
+
+	created := rule
+	return &created, nil
+}
+
+// UpdateNettingRule replaces a configured netting rule. ErrNotFound is returned for an unknown rule,
+// ErrConflict if the new validity period overlaps another rule of the order type.
+func (r *Repository) UpdateNettingRule(ctx context.Context, rule domain.NettingRuleConfig) (*domain.NettingRuleConfig, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if err := s.checkNettingRule(rule); err != nil {
+		return nil, err
+	}
+	stored, ok := s.nettingRules[rule.RuleID]
+	if !ok || stored.OrderTypeID != rule.OrderTypeID {
+		return nil, repository.ErrNotFound
+	}
+	stored.Debtor, stored.Creditor, stored.Excluded = rule.Debtor, rule.Creditor, rule.Excluded
+	stored.MinAmount, stored.MaxAmount = rule.MinAmount, rule.MaxAmount
+	stored.ValidFrom, stored.ValidTo, stored.Comment = rule.ValidFrom, rule.ValidTo, rule.Comment
+	stored.UpdatedAt = time.Now()
+
+	updated := *stored
+	return &updated, nil
+}
+
+// GetNettingRule retrieves a configured netting rule by its ID.
+func (r *Repository) GetNettingRule(ctx context.Context, ruleID int) (*domain.NettingRuleConfig, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	rule, ok := s.nettingRules[ruleID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *rule
+	return &copied, nil
+}
+
+// DeleteNettingRule removes a configured netting rule; the catalog rule applies again.
+func (r *Repository) DeleteNettingRule(ctx context.Context, ruleID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.nettingRules[ruleID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(s.nettingRules, ruleID)
+	return nil
+}
+
+// ListObligationTriggers retrieves the obligation triggers, optionally only the active ones.
+func (r *Repository) ListObligationTriggers(ctx context.Context, activeOnly bool) ([]*domain.ObligationTrigger, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var triggers []*domain.ObligationTrigger
+	for _, triggerID := range sortedKeys(s.triggers) {
+		if trigger := s.triggers[triggerID]; !activeOnly || trigger.Active {
+			copied := *trigger
+			triggers = append(triggers, &copied)
+		}
+	}
+	return triggers, nil
+}
+
+// checkObligationTrigger checks the foreign keys of an obligation trigger.
+func (s *store) checkObligationTrigger(trigger domain.ObligationTrigger) error {
+	if _, ok := s.orderTypes[trigger.OrderTypeID]; !ok {
+		return foreignKeyError("obligation_triggers", "order_type_id")
+	}
+	if _, ok := s.currencies[trigger.CurrencyCode]; !ok {
+		return foreignKeyError("obligation_triggers", "currency_code")
+	}
+	return nil
+}
+
+// CreateObligationTrigger stores an obligation trigger.
+func (r *Repository) CreateObligationTrigger(ctx context.Context, trigger domain.ObligationTrigger) (*domain.ObligationTrigger, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if err := s.checkObligationTrigger(trigger); err != nil {
+		return nil, fmt.Errorf("failed to create obligation trigger: %w", err)
+	}
+	trigger.TriggerID = s.nextID("obligation_triggers")
+	trigger.CreatedAt = time.Now()
+	trigger.UpdatedAt = trigger.CreatedAt
+	s.triggers[trigger.TriggerID] = &trigger
+
+	created := trigger
+	return &created, nil
+}
+
+// UpdateObligationTrigger replaces an obligation trigger. Orders it already generated are not changed.
+func (r *Repository) UpdateObligationTrigger(ctx context.Context, trigger domain.ObligationTrigger) (*domain.ObligationTrigger, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	stored, ok := s.triggers[trigger.TriggerID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if err := s.checkObligationTrigger(trigger); err != nil {
+		return nil, fmt.Errorf("failed to update obligation trigger: %w", err)
+	}
+	stored.OrderTypeID, stored.Event, stored.DelayDays = trigger.OrderTypeID, trigger.Event, trigger.DelayDays
+	stored.Amount, stored.CurrencyCode, stored.VATRate = trigger.Amount, trigger.CurrencyCode, trigger.VATRate
+	stored.Active, stored.Comment = trigger.Active, trigger.Comment
+	stored.UpdatedAt = time.Now()
+
+	updated := *stored
+	return &updated, nil
+}
+
+// DeleteObligationTrigger removes an obligation trigger.
+func (r *Repository) DeleteObligationTrigger(ctx context.Context, triggerID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.triggers[triggerID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(s.triggers, triggerID)
+	return nil
+}
+
+// ListTriggerDeals retrieves the deals the trigger fires for at the moment that have no order
+// generated by the trigger yet.
+func (r *Repository) ListTriggerDeals(ctx context.Context, trigger *domain.ObligationTrigger, at time.Time) ([]*domain.Deal, error) {
+	var due func(deal *dealRow) bool
+	switch trigger.Event {
+	case domain.TriggerDealCancelled:
+		due = func(deal *dealRow) bool {
+			return deal.Status == domain.DealStatusCancelled && !deal.UpdatedAt.Before(deal.CreatedAt.AddDate(0, 0, trigger.DelayDays))
+		}
+	case domain.TriggerDealOpen:
+		due = func(deal *dealRow) bool {
+			return (deal.Status == domain.DealStatusDraft || deal.Status == domain.DealStatusActive) &&
+				!at.Before(deal.CreatedAt.AddDate(0, 0, trigger.DelayDays))
+		}
+	default:
+		return nil, fmt.Errorf("unknown trigger event %q: %w", trigger.Event, repository.ErrInvalidInput)
+	}
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	generated := map[int]bool{}
+	for _, order := range s.orders {
+		if sameInt(order.TriggerID, &trigger.TriggerID) {
+			generated[order.DealID] = true
+		}
+	}
+
+	var deals []*domain.Deal
+	for _, dealID := range sortedKeys(s.deals) {
+		row := s.deals[dealID]
+		if row.deletedAt == nil && !generated[dealID] && due(row) {
+			deal := row.Deal
+			deals = append(deals, &deal)
+		}
+	}
+	return deals, nil
+}
+
+// SaveNettingSnapshot stores the netting result of a deal as its next snapshot version unless the latest
+// snapshot was computed from the same input. It reports whether a new version was stored.
+func (r *Repository) SaveNettingSnapshot(ctx context.Context, snapshot *domain.NettingSnapshot) (bool, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var latest *domain.NettingSnapshot
+	for _, stored := range s.snapshots {
+		if stored.DealID == snapshot.DealID && (latest == nil || stored.Version > latest.Version) {
+			latest = stored
+		}
+	}
+	if latest != nil && latest.InputHash == snapshot.InputHash {
+		return false, nil
+	}
+
+	version := 1
+	if latest != nil {
+		version = latest.Version + 1
+	}
+	s.snapshots = append(s.snapshots, &domain.NettingSnapshot{
+		SnapshotID: s.nextID("netting_snapshots"),
+		DealID:     snapshot.DealID,
+		Version:    version,
+		InputHash:  snapshot.InputHash,
+		OrderIDs:   slices.Clone(snapshot.OrderIDs),
+		Positions:  slices.Clone(snapshot.Positions),
+		Legs:       slices.Clone(snapshot.Legs),
+		CreatedAt:  time.Now(),
+	})
+	return true, nil
+}
+
+// ListNettingSnapshots retrieves a page of the netting snapshots of a deal, newest version first,
+// and the total number of its snapshots.
+func (r *Repository) ListNettingSnapshots(ctx context.Context, dealID, page, limit int) ([]*domain.NettingSnapshot, int, error) {
+	if err := validPage(page, limit); err != nil {
+		return nil, 0, err
+	}
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var matched []*domain.NettingSnapshot
+	for _, snapshot := range s.snapshots {
+		if snapshot.DealID == dealID {
+			matched = append(matched, snapshot)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Version > matched[j].Version })
+
+	var snapshots []*domain.NettingSnapshot
+	for _, snapshot := range pageOf(matched, page, limit) {
+		copied := *snapshot
+		snapshots = append(snapshots, &copied)
+	}
+	return snapshots, len(matched), nil
+}
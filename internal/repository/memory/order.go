@@ -0,0 +1,673 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// orderRow is a stored order with the columns the domain type does not carry.
+// PaidAmount and OutstandingAmount are not stored: orderOut computes them from the allocations.
+type orderRow struct {
+	domain.Order
+	deletedAt    *time.Time
+	anonymizedAt *time.Time
+}
+
+// values converts the row into a map of its JSON fields.
+func (o *orderRow) values() map[string]any {
+	values := jsonValues(o.Order)
+	values["deleted_at"] = o.deletedAt
+	return values
+}
+
+// grossAmount returns the amount with VAT; orders created before VAT was introduced have only the amount.
+func (o *orderRow) grossAmount() domain.Money {
+	if o.GrossAmount == 0 {
+		return o.Amount
+	}
+	return o.GrossAmount
+}
+
+// orderStatusChange is an entry of the order status history.
+type orderStatusChange struct {
+	orderID   int
+	from, to  string
+	changedBy string
+	changedAt time.Time
+}
+
+// orderOut returns a copy of a stored order with the amount covered by executed settlements.
+func (s *store) orderOut(row *orderRow) *domain.Order {
+	order := row.Order
+	order.PaidAmount = 0
+	for _, allocation := range s.allocations {
+		if allocation.OrderID == order.OrderID {
+			order.PaidAmount += allocation.Amount
+		}
+	}
+	order.OutstandingAmount = row.grossAmount() - order.PaidAmount
+	if row.Invoice != nil {
+		invoice := *row.Invoice
+		invoice.DownloadURL = domain.OrderInvoiceURL(order.OrderID)
+		order.Invoice = &invoice
+	}
+	return &order
+}
+
+// liveOrder returns an order that is not deleted.
+func (s *store) liveOrder(orderID int) (*orderRow, error) {
+	row, ok := s.orders[orderID]
+	if !ok || row.deletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	return row, nil
+}
+
+// sortOrders sorts orders by the whitelisted sort field. An empty sort selects the newest orders first;
+// the order ID breaks ties.
+func sortOrders(orders []*domain.Order, orderSort domain.OrderSort) error {
+	field, direction := orderSort.Field, orderSort.Direction
+	if field == "" {
+		field = domain.OrderSortCreatedAt
+	}
+	if direction == "" {
+		direction = domain.SortDesc
+	}
+
+	var compare func(a, b *domain.Order) int
+	switch field {
+	case domain.OrderSortAmount:
+		compare = func(a, b *domain.Order) int { return int(a.Amount - b.Amount) }
+	case domain.OrderSortCreatedAt:
+		compare = func(a, b *domain.Order) int { return a.CreatedAt.Compare(b.CreatedAt) }
+	case domain.OrderSortStatus:
+		compare = func(a, b *domain.Order) int {
+			if a.Status == b.Status {
+				return 0
+			}
+			if a.Status < b.Status {
+				return -1
+			}
+			return 1
+		}
+	default:
+		return fmt.Errorf("unknown sort field %q: %w", field, repository.ErrInvalidInput)
+	}
+	if direction != domain.SortAsc && direction != domain.SortDesc {
+		return fmt.Errorf("unknown sort direction %q: %w", direction, repository.ErrInvalidInput)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		c := compare(orders[i], orders[j])
+		if c == 0 {
+			c = orders[i].OrderID - orders[j].OrderID
+		}
+		if direction == domain.SortDesc {
+			return c > 0
+		}
+		return c < 0
+	})
+	return nil
+}
+
+// checkOrder checks the constraints and the foreign keys of an order.
+func (s *store) checkOrder(order *domain.Order) error {
+	switch {
+	case order.Amount <= 0:
+		return checkError("orders", "amount")
+	case order.Discount < 0:
+		return checkError("orders", "discount")
+	case order.VATRate < 0 || order.VATRate > 100:
+		return checkError("orders", "vat_rate")
+	}
+	if _, ok := domain.OrderTransitions[order.Status]; !ok {
+		return checkError("orders", "status")
+	}
+	if _, ok := s.deals[order.DealID]; !ok {
+		return foreignKeyError("orders", "deal_id")
+	}
+	if _, ok := s.orderTypes[order.OrderTypeID]; !ok {
+		return foreignKeyError("orders", "order_type_id")
+	}
+	if _, ok := s.currencies[order.CurrencyCode]; !ok {
+		return foreignKeyError("orders", "currency_code")
+	}
+	if order.BankID != nil && s.banks[*order.BankID] == nil {
+		return foreignKeyError("orders", "bank_id")
+	}
+	if order.DealershipID != nil && s.dealerships[*order.DealershipID] == nil {
+		return foreignKeyError("orders", "dealership_id")
+	}
+	if order.VehicleID != nil && s.vehicles[*order.VehicleID] == nil {
+		return foreignKeyError("orders", "vehicle_id")
+	}
+	if order.CreditContractID != nil && s.contracts[*order.CreditContractID] == nil {
+		return foreignKeyError("orders", "credit_contract_id")
+	}
+	if order.AppraisalID != nil && s.appraisals[*order.AppraisalID] == nil {
+		return foreignKeyError("orders", "appraisal_id")
+	}
+	if order.TriggerID != nil && s.triggers[*order.TriggerID] == nil {
+		return foreignKeyError("orders", "trigger_id")
+	}
+	return nil
+}
+
+// ListOrders retrieves a page of orders for a client matching the filter together with the total
+// number of matching orders, sorted by the whitelisted sort field.
+func (r *Repository) ListOrders(ctx context.Context, clientID int, filter domain.OrderFilter, sort domain.OrderSort, page, limit int) ([]*domain.Order, int, error) {
+	if err := validPage(page, limit); err != nil {
+		return nil, 0, err
+	}
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var orders []*domain.Order
+	for _, row := range s.orders {
+		deal, ok := s.deals[row.DealID]
+		if !ok || deal.ClientID != clientID || row.deletedAt != nil || deal.deletedAt != nil || !matchesOrderFilter(row, filter) {
+			continue
+		}
+		orders = append(orders, s.orderOut(row))
+	}
+	if err := sortOrders(orders, sort); err != nil {
+		return nil, 0, err
+	}
+
+	return pageOf(orders, page, limit), len(orders), nil
+}
+
+// matchesOrderFilter reports whether an order matches every set field of the filter.
+func matchesOrderFilter(order *orderRow, filter domain.OrderFilter) bool {
+	switch {
+	case filter.Status != nil && order.Status != *filter.Status:
+		return false
+	case filter.OrderTypeID != nil && order.OrderTypeID != *filter.OrderTypeID:
+		return false
+	case filter.DealID != nil && order.DealID != *filter.DealID:
+		return false
+	case filter.BankID != nil && !sameInt(order.BankID, filter.BankID):
+		return false
+	case filter.AmountMin != nil && order.Amount < *filter.AmountMin:
+		return false
+	case filter.AmountMax != nil && order.Amount > *filter.AmountMax:
+		return false
+	case filter.CreatedFrom != nil && order.CreatedAt.Before(*filter.CreatedFrom):
+		return false
+	case filter.CreatedTo != nil && !order.CreatedAt.Before(*filter.CreatedTo):
+		return false
+	}
+	return true
+}
+
+// ListOrdersByDeals retrieves all orders for a specific deal, newest first.
+func (r *Repository) ListOrdersByDeals(ctx context.Context, dealID int) ([]*domain.Order, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var orders []*domain.Order
+	for _, row := range s.orders {
+		if row.DealID == dealID && row.deletedAt == nil {
+			orders = append(orders, s.orderOut(row))
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].CreatedAt.After(orders[j].CreatedAt) })
+	return orders, nil
+}
+
+// ListOrdersOfDeals retrieves the orders of the deals, oldest first.
+func (r *Repository) ListOrdersOfDeals(ctx context.Context, dealIDs []int) ([]*domain.Order, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var orders []*domain.Order
+	for _, row := range s.orders {
+		if row.deletedAt == nil && slices.Contains(dealIDs, row.DealID) {
+			orders = append(orders, s.orderOut(row))
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].CreatedAt.Equal(orders[j].CreatedAt) {
+			return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+		}
+		return orders[i].OrderID < orders[j].OrderID
+	})
+	return orders, nil
+}
+
+// CreateOrders inserts a batch of orders: either all orders are created or none. The error of a failed
+// insert is a *domain.BatchItemError carrying the index of the order. The deals are locked for the
+// duration of the inserts so that they do not interleave with netting.
+func (r *Repository) CreateOrders(ctx context.Context, orders []*domain.Order) ([]*domain.Order, error) {
+	dealIDs := make([]int, 0, len(orders))
+	for _, order := range orders {
+		dealIDs = append(dealIDs, order.DealID)
+	}
+	unlockDeals, err := r.lockDeals(ctx, dealIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockDeals()
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	for i, order := range orders {
+		if err := s.checkOrder(order); err != nil {
+			return nil, &domain.BatchItemError{Index: i, Err: fmt.Errorf("failed to create order: %w", err)}
+		}
+	}
+
+	sessionID := s.openClearingSession()
+	now := time.Now()
+	createdOrders := make([]*domain.Order, 0, len(orders))
+	for _, order := range orders {
+		counter := s.counter(order.DealID)
+		counter.lastOrderNumber++
+
+		row := &orderRow{Order: *order}
+		row.OrderID = s.nextID("orders")
+		row.CreatedAt, row.UpdatedAt = now, now
+		row.OrderNumber = counter.lastOrderNumber
+		row.ClearingSessionID = intPtr(sessionID)
+		row.ReviewStatus, row.Invoice, row.PriceWarnings = "", nil, nil
+		row.PaidAmount, row.OutstandingAmount = 0, 0
+		row.CurrencyExponent, row.AmountDisplay = 0, ""
+		s.orders[row.OrderID] = row
+		s.recordOrder("insert", row)
+		createdOrders = append(createdOrders, s.orderOut(row))
+	}
+
+	return createdOrders, nil
+}
+
+// GetOrder retrieves an order by its ID.
+func (r *Repository) GetOrder(ctx context.Context, orderID int) (*domain.Order, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.orderOut(row), nil
+}
+
+// GetClientOrder retrieves an order by its ID and checks that its deal belongs to the client.
+// ErrUnauthorized is returned if the order belongs to another client.
+func (r *Repository) GetClientOrder(ctx context.Context, clientID, orderID int) (*domain.Order, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if deal, ok := s.deals[row.DealID]; !ok || deal.ClientID != clientID {
+		return nil, repository.ErrUnauthorized
+	}
+	return s.orderOut(row), nil
+}
+
+// orderDeal returns the deal of an order that is not deleted.
+func (r *Repository) orderDeal(ctx context.Context, orderID int) (int, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveOrder(orderID)
+	if err != nil {
+		return 0, err
+	}
+	return row.DealID, nil
+}
+
+// UpdateOrder updates an existing order. Both the current and the target deal are locked so that
+// the update does not interleave with netting.
+func (r *Repository) UpdateOrder(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	currentDealID, err := r.orderDeal(ctx, order.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	unlockDeals, err := r.lockDeals(ctx, currentDealID, order.DealID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockDeals()
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveOrder(order.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	updated := row.Order
+	updated.DealID, updated.OrderTypeID, updated.Amount, updated.Status = order.DealID, order.OrderTypeID, order.Amount, order.Status
+	updated.NeedAndOrdersID, updated.BankID, updated.DealershipID = order.NeedAndOrdersID, order.BankID, order.DealershipID
+	updated.VehicleID, updated.Discount, updated.VATRate = order.VehicleID, order.Discount, order.VATRate
+	updated.NetAmount, updated.VATAmount, updated.GrossAmount = order.NetAmount, order.VATAmount, order.GrossAmount
+	updated.ExecuteAt, updated.InsurerID = order.ExecuteAt, order.InsurerID
+	updated.CreditContractID, updated.AppraisalID = order.CreditContractID, order.AppraisalID
+	if err := s.checkOrder(&updated); err != nil {
+		return nil, fmt.Errorf("failed to update order: %w", err)
+	}
+
+	// При переносе в другую сделку заказ получает номер в ней
+	if updated.DealID != row.DealID {
+		counter := s.counter(updated.DealID)
+		counter.lastOrderNumber++
+		updated.OrderNumber = counter.lastOrderNumber
+	}
+	s.insertOrderRevision(ctx, row, domain.OrderRevisionUpdated)
+	updated.UpdatedAt = time.Now()
+	row.Order = updated
+	s.recordOrder("update", row)
+
+	return s.orderOut(row), nil
+}
+
+// UpdateOrderStatus moves an order from one status to another and records the change in the
+// order status history. The update only succeeds if the order is still in the from status,
+// otherwise ErrConflict is returned.
+func (r *Repository) UpdateOrderStatus(ctx context.Context, orderID int, from, to string) (*domain.Order, error) {
+	dealID, err := r.orderDeal(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	unlockDeals, err := r.lockDeals(ctx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockDeals()
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, ok := s.orders[orderID]
+	if !ok || row.deletedAt != nil || row.Status != from {
+		return nil, repository.ErrConflict
+	}
+	if _, ok := domain.OrderTransitions[to]; !ok {
+		return nil, fmt.Errorf("failed to update order status: %w", checkError("orders", "status"))
+	}
+
+	s.insertOrderRevision(ctx, row, domain.OrderRevisionStatusChanged)
+	row.Status = to
+	row.UpdatedAt = time.Now()
+	s.recordOrder("update", row)
+	s.statusHistory = append(s.statusHistory, &orderStatusChange{
+		orderID: orderID, from: from, to: to, changedBy: actorFromContext(ctx), changedAt: row.UpdatedAt,
+	})
+
+	return s.orderOut(row), nil
+}
+
+// DeleteOrder soft-deletes an order that has not been executed and flags the settlements of its deal
+// for recomputation. The deal is locked so that the deletion does not interleave with netting.
+func (r *Repository) DeleteOrder(ctx context.Context, orderID int) error {
+	dealID, err := r.orderDeal(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	unlockDeals, err := r.lockDeals(ctx, dealID)
+	if err != nil {
+		return err
+	}
+	defer unlockDeals()
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, ok := s.orders[orderID]
+	if !ok || row.deletedAt != nil || row.Status == domain.StatusExecuted {
+		return repository.ErrConflict
+	}
+
+	s.insertOrderRevision(ctx, row, domain.OrderRevisionDeleted)
+	now := time.Now()
+	row.deletedAt = timePtr(now)
+	row.UpdatedAt = now
+	s.recordOrder("update", row)
+	s.flagDealForRecompute(row.DealID)
+
+	return nil
+}
+
+// flagDealForRecompute marks the settlements of a deal as outdated.
+func (s *store) flagDealForRecompute(dealID int) {
+	deal, ok := s.deals[dealID]
+	if !ok {
+		return
+	}
+	deal.SettlementsRecomputeRequired = true
+	deal.UpdatedAt = time.Now()
+	s.recordDeal("update", deal)
+}
+
+// insertOrderRevision stores the current values of an order before it is changed by action.
+func (s *store) insertOrderRevision(ctx context.Context, row *orderRow, action string) {
+	s.revisions = append(s.revisions, &domain.OrderRevision{
+		RevisionID:  s.nextID("order_revisions"),
+		OrderID:     row.OrderID,
+		Action:      action,
+		DealID:      row.DealID,
+		OrderTypeID: row.OrderTypeID,
+		Amount:      row.Amount,
+		Status:      row.Status,
+		ChangedBy:   actorFromContext(ctx),
+		ChangedAt:   time.Now(),
+	})
+}
+
+// ListClientOrderRevisions retrieves the revisions of a client's order, oldest first.
+// Deleted orders keep their history, so ownership is checked on deleted orders too.
+func (r *Repository) ListClientOrderRevisions(ctx context.Context, clientID, orderID int) ([]*domain.OrderRevision, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, ok := s.orders[orderID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if deal, ok := s.deals[row.DealID]; !ok || deal.ClientID != clientID {
+		return nil, repository.ErrUnauthorized
+	}
+
+	var revisions []*domain.OrderRevision
+	for _, revision := range s.revisions {
+		if revision.OrderID == orderID {
+			copied := *revision
+			revisions = append(revisions, &copied)
+		}
+	}
+	return revisions, nil
+}
+
+// SetOrderInvoice attaches an invoice file to an order, replacing the previous one.
+// It returns the storage key of the replaced file, or an empty string if the order had no invoice.
+func (r *Repository) SetOrderInvoice(ctx context.Context, orderID int, invoice *domain.OrderInvoice) (string, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveOrder(orderID)
+	if err != nil {
+		return "", err
+	}
+	for _, other := range s.orders {
+		if other.OrderID != orderID && other.Invoice != nil && other.Invoice.StorageKey == invoice.StorageKey {
+			return "", fmt.Errorf("failed to set order invoice: %w", uniqueError("orders", "invoice_key"))
+		}
+	}
+
+	var previousKey string
+	if row.Invoice != nil {
+		previousKey = row.Invoice.StorageKey
+	}
+	row.Invoice = &domain.OrderInvoice{
+		FileName:    invoice.FileName,
+		ContentType: invoice.ContentType,
+		SizeBytes:   invoice.SizeBytes,
+		StorageKey:  invoice.StorageKey,
+	}
+	s.recordOrder("update", row)
+
+	return previousKey, nil
+}
+
+// CountClientOrdersSince counts the client's orders of at least minAmount created since the given time.
+func (r *Repository) CountClientOrdersSince(ctx context.Context, clientID int, minAmount domain.Money, since time.Time) (int, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	count := 0
+	for _, row := range s.orders {
+		deal, ok := s.deals[row.DealID]
+		if ok && deal.ClientID == clientID && row.Amount >= minAmount && !row.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CountClientCancellationsSince counts the client's orders cancelled or deleted since the given time.
+func (r *Repository) CountClientCancellationsSince(ctx context.Context, clientID int, since time.Time) (int, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	cancelled := map[int]bool{}
+	for _, change := range s.statusHistory {
+		if change.to == domain.StatusCancelled && !change.changedAt.Before(since) {
+			cancelled[change.orderID] = true
+		}
+	}
+
+	count := 0
+	for _, row := range s.orders {
+		deal, ok := s.deals[row.DealID]
+		if !ok || deal.ClientID != clientID {
+			continue
+		}
+		if cancelled[row.OrderID] || (row.deletedAt != nil && !row.deletedAt.Before(since)) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// FlagOrderForReview holds an order out of netting and puts it into the review queue.
+func (r *Repository) FlagOrderForReview(ctx context.Context, orderID, clientID int, reasons []string) (*domain.OrderReview, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	row.ReviewStatus = domain.ReviewPending
+	s.recordOrder("update", row)
+
+	review := &domain.OrderReview{
+		ReviewID:  s.nextID("order_reviews"),
+		OrderID:   orderID,
+		ClientID:  clientID,
+		Reasons:   slices.Clone(reasons),
+		Status:    domain.ReviewPending,
+		CreatedAt: time.Now(),
+	}
+	s.reviews[review.ReviewID] = review
+
+	created := *review
+	return &created, nil
+}
+
+// ListOrderReviews retrieves the order reviews with the given status, oldest first.
+// An empty status lists all reviews.
+func (r *Repository) ListOrderReviews(ctx context.Context, status string) ([]*domain.OrderReview, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var reviews []*domain.OrderReview
+	for _, reviewID := range sortedKeys(s.reviews) {
+		review := s.reviews[reviewID]
+		if status == "" || review.Status == status {
+			copied := *review
+			reviews = append(reviews, &copied)
+		}
+	}
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].CreatedAt.Before(reviews[j].CreatedAt) })
+	return reviews, nil
+}
+
+// DecideOrderReview approves or rejects a pending review. An approved order enters netting and the
+// settlements of its deal are flagged for recomputation; a rejected pending order is cancelled.
+// ErrNotFound is returned for an unknown review and ErrConflict if it was already decided.
+func (r *Repository) DecideOrderReview(ctx context.Context, reviewID int, status string) (*domain.OrderReview, error) {
+	dealID, err := r.reviewDeal(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	unlockDeals, err := r.lockDeals(ctx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlockDeals()
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	review := s.reviews[reviewID]
+	if review.Status != domain.ReviewPending {
+		return nil, repository.ErrConflict
+	}
+	actor := actorFromContext(ctx)
+	now := time.Now()
+	review.Status = status
+	review.DecidedBy = &actor
+	review.DecidedAt = timePtr(now)
+
+	order := s.orders[review.OrderID]
+	order.ReviewStatus = status
+	order.UpdatedAt = now
+	s.recordOrder("update", order)
+
+	switch status {
+	case domain.ReviewApproved:
+		s.flagDealForRecompute(dealID)
+	case domain.ReviewRejected:
+		if order.Status == domain.StatusPending && order.deletedAt == nil {
+			s.insertOrderRevision(ctx, order, domain.OrderRevisionStatusChanged)
+			order.Status = domain.StatusCancelled
+			s.recordOrder("update", order)
+			s.statusHistory = append(s.statusHistory, &orderStatusChange{
+				orderID: order.OrderID, from: domain.StatusPending, to: domain.StatusCancelled,
+				changedBy: actor, changedAt: now,
+			})
+		}
+	}
+
+	decided := *review
+	return &decided, nil
+}
+
+// reviewDeal returns the deal of the order under review.
+func (r *Repository) reviewDeal(ctx context.Context, reviewID int) (int, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	review, ok := s.reviews[reviewID]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+	order, ok := s.orders[review.OrderID]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+	return order.DealID, nil
+}
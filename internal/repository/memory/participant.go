@@ -0,0 +1,436 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// anonymizedClientFields are the personal data fields scrubbed by AnonymizeClient.
+var anonymizedClientFields = []string{"full_name", "inn", "phone", "email", "passport_ref"}
+
+// anonymizationToken is a one-time token confirming the anonymization of a client.
+type anonymizationToken struct {
+	clientID  int
+	expiresAt time.Time
+	usedAt    *time.Time
+}
+
+// ListBanks retrieves the banks reference, optionally only the active banks.
+func (r *Repository) ListBanks(ctx context.Context, activeOnly bool) ([]*domain.Bank, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var banks []*domain.Bank
+	for _, bankID := range sortedKeys(s.banks) {
+		if bank := s.banks[bankID]; !activeOnly || bank.Active {
+			copied := *bank
+			banks = append(banks, &copied)
+		}
+	}
+	return banks, nil
+}
+
+// GetBank retrieves a bank by its ID.
+func (r *Repository) GetBank(ctx context.Context, bankID int) (*domain.Bank, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	bank, ok := s.banks[bankID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *bank
+	return &copied, nil
+}
+
+// CreateBank adds a bank. ErrConflict is returned if the bank_id already exists.
+func (r *Repository) CreateBank(ctx context.Context, bank domain.Bank) (*domain.Bank, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.banks[bank.BankID]; ok {
+		return nil, repository.ErrConflict
+	}
+	bank.CreatedAt = time.Now()
+	bank.UpdatedAt = bank.CreatedAt
+	s.banks[bank.BankID] = &bank
+
+	created := bank
+	return &created, nil
+}
+
+// UpdateBank updates a bank. ErrNotFound is returned if the bank does not exist.
+func (r *Repository) UpdateBank(ctx context.Context, bank domain.Bank) (*domain.Bank, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	stored, ok := s.banks[bank.BankID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	stored.Name, stored.BIC, stored.CorrespondentAccount, stored.Active = bank.Name, bank.BIC, bank.CorrespondentAccount, bank.Active
+	stored.UpdatedAt = time.Now()
+
+	updated := *stored
+	return &updated, nil
+}
+
+// DeleteBank removes a bank. ErrConflict is returned if orders or settlements still reference the bank.
+func (r *Repository) DeleteBank(ctx context.Context, bankID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.banks[bankID]; !ok {
+		return repository.ErrNotFound
+	}
+	for _, order := range s.orders {
+		if sameInt(order.BankID, &bankID) {
+			return repository.ErrConflict
+		}
+	}
+	for _, settlement := range s.settlements {
+		if sameInt(settlement.BankID, &bankID) {
+			return repository.ErrConflict
+		}
+	}
+	for _, contract := range s.contracts {
+		if contract.BankID == bankID {
+			return repository.ErrConflict
+		}
+	}
+	delete(s.banks, bankID)
+	return nil
+}
+
+// ListClients retrieves a page of clients and the total number of clients.
+func (r *Repository) ListClients(ctx context.Context, page, limit int) ([]*domain.Client, int, error) {
+	if err := validPage(page, limit); err != nil {
+		return nil, 0, err
+	}
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	clientIDs := sortedKeys(s.clients)
+	var clients []*domain.Client
+	for _, clientID := range pageOf(clientIDs, page, limit) {
+		copied := *s.clients[clientID]
+		clients = append(clients, &copied)
+	}
+	return clients, len(clientIDs), nil
+}
+
+// GetClient retrieves a client by its ID.
+func (r *Repository) GetClient(ctx context.Context, clientID int) (*domain.Client, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	client, ok := s.clients[clientID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *client
+	return &copied, nil
+}
+
+// CreateClient adds a client. ErrConflict is returned if the client_id already exists.
+func (r *Repository) CreateClient(ctx context.Context, client domain.Client) (*domain.Client, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.clients[client.ClientID]; ok {
+		return nil, repository.ErrConflict
+	}
+	client.AnonymizedAt = nil
+	client.CreatedAt = time.Now()
+	client.UpdatedAt = client.CreatedAt
+	s.clients[client.ClientID] = &client
+
+	created := client
+	return &created, nil
+}
+
+// UpdateClient updates the personal data of a client. ErrNotFound is returned for an unknown client,
+// ErrConflict for an anonymized one: its personal data must not be restored.
+func (r *Repository) UpdateClient(ctx context.Context, client domain.Client) (*domain.Client, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	stored, ok := s.clients[client.ClientID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if stored.AnonymizedAt != nil {
+		return nil, repository.ErrConflict
+	}
+	stored.FullName, stored.INN, stored.Phone = client.FullName, client.INN, client.Phone
+	stored.Email, stored.PassportRef = client.Email, client.PassportRef
+	stored.UpdatedAt = time.Now()
+
+	updated := *stored
+	return &updated, nil
+}
+
+// DeleteClient removes a client. ErrConflict is returned if deals or the anonymization audit reference the client.
+func (r *Repository) DeleteClient(ctx context.Context, clientID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	client, ok := s.clients[clientID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	// Анонимизированный клиент упомянут в журнале анонимизации
+	if client.AnonymizedAt != nil {
+		return repository.ErrConflict
+	}
+	for _, deal := range s.deals {
+		if deal.ClientID == clientID {
+			return repository.ErrConflict
+		}
+	}
+	delete(s.clients, clientID)
+	return nil
+}
+
+// GetClientAnonymizedAt returns when the client was anonymized, or nil if the client was not anonymized.
+func (r *Repository) GetClientAnonymizedAt(ctx context.Context, clientID int) (*time.Time, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	client, ok := s.clients[clientID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if client.AnonymizedAt == nil {
+		return nil, nil
+	}
+	return timePtr(*client.AnonymizedAt), nil
+}
+
+// CreateAnonymizationToken stores the hash of a one-time anonymization token of a client.
+func (r *Repository) CreateAnonymizationToken(ctx context.Context, clientID int, tokenHash string, expiresAt time.Time) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.clients[clientID]; !ok {
+		return fmt.Errorf("failed to create anonymization token: %w", foreignKeyError("client_anonymization_tokens", "client_id"))
+	}
+	if _, ok := s.anonymizationTokens[tokenHash]; ok {
+		return fmt.Errorf("failed to create anonymization token: %w", uniqueError("client_anonymization_tokens", "token_hash"))
+	}
+	s.anonymizationTokens[tokenHash] = &anonymizationToken{clientID: clientID, expiresAt: expiresAt}
+	return nil
+}
+
+// AnonymizeClient consumes the token and scrubs the personal data of the client.
+// Orders and monetary settlements are left untouched. ErrUnauthorized is returned for an unknown,
+// used or expired token, ErrConflict if the client is already anonymized.
+func (r *Repository) AnonymizeClient(ctx context.Context, clientID int, tokenHash string) (*domain.AnonymizationResult, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	now := time.Now()
+	token, ok := s.anonymizationTokens[tokenHash]
+	if !ok || token.clientID != clientID || token.usedAt != nil || !token.expiresAt.After(now) {
+		return nil, repository.ErrUnauthorized
+	}
+	client, ok := s.clients[clientID]
+	if !ok || client.AnonymizedAt != nil {
+		return nil, repository.ErrConflict
+	}
+
+	token.usedAt = timePtr(now)
+	client.FullName = "ANONYMIZED-" + strconv.Itoa(clientID)
+	client.INN, client.Phone, client.Email, client.PassportRef = "", "", "", ""
+	client.AnonymizedAt = timePtr(now)
+	client.UpdatedAt = now
+
+	return &domain.AnonymizationResult{
+		AuditID:      s.nextID("client_anonymization_audit"),
+		ClientID:     clientID,
+		AnonymizedAt: now,
+		Fields:       append([]string(nil), anonymizedClientFields...),
+	}, nil
+}
+
+// ListDealerships retrieves the dealerships reference, optionally only the active dealerships.
+func (r *Repository) ListDealerships(ctx context.Context, activeOnly bool) ([]*domain.Dealership, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var dealerships []*domain.Dealership
+	for _, dealershipID := range sortedKeys(s.dealerships) {
+		if dealership := s.dealerships[dealershipID]; !activeOnly || dealership.Active {
+			copied := *dealership
+			dealerships = append(dealerships, &copied)
+		}
+	}
+	return dealerships, nil
+}
+
+// GetDealership retrieves a dealership by its ID.
+func (r *Repository) GetDealership(ctx context.Context, dealershipID int) (*domain.Dealership, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	dealership, ok := s.dealerships[dealershipID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *dealership
+	return &copied, nil
+}
+
+// CreateDealership adds a dealership. ErrConflict is returned if the dealership_id already exists.
+func (r *Repository) CreateDealership(ctx context.Context, dealership domain.Dealership) (*domain.Dealership, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.dealerships[dealership.DealershipID]; ok {
+		return nil, repository.ErrConflict
+	}
+	dealership.CreatedAt = time.Now()
+	dealership.UpdatedAt = dealership.CreatedAt
+	s.dealerships[dealership.DealershipID] = &dealership
+
+	created := dealership
+	return &created, nil
+}
+
+// UpdateDealership updates a dealership. ErrNotFound is returned if the dealership does not exist.
+func (r *Repository) UpdateDealership(ctx context.Context, dealership domain.Dealership) (*domain.Dealership, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	stored, ok := s.dealerships[dealership.DealershipID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	stored.Name, stored.LegalEntity, stored.Address, stored.Active = dealership.Name, dealership.LegalEntity, dealership.Address, dealership.Active
+	stored.UpdatedAt = time.Now()
+
+	updated := *stored
+	return &updated, nil
+}
+
+// DeleteDealership removes a dealership. ErrConflict is returned if deals, orders or managers reference it.
+func (r *Repository) DeleteDealership(ctx context.Context, dealershipID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.dealerships[dealershipID]; !ok {
+		return repository.ErrNotFound
+	}
+	for _, deal := range s.deals {
+		if deal.DealershipID == dealershipID {
+			return repository.ErrConflict
+		}
+	}
+	for _, order := range s.orders {
+		if sameInt(order.DealershipID, &dealershipID) {
+			return repository.ErrConflict
+		}
+	}
+	for _, manager := range s.managers {
+		if manager.DealershipID == dealershipID {
+			return repository.ErrConflict
+		}
+	}
+	delete(s.dealerships, dealershipID)
+	return nil
+}
+
+// ListManagers retrieves the managers matching the filter.
+func (r *Repository) ListManagers(ctx context.Context, filter domain.ManagerFilter) ([]*domain.Manager, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var managers []*domain.Manager
+	for _, managerID := range sortedKeys(s.managers) {
+		manager := s.managers[managerID]
+		if filter.DealershipID != nil && manager.DealershipID != *filter.DealershipID {
+			continue
+		}
+		if filter.Active && !manager.Active {
+			continue
+		}
+		copied := *manager
+		managers = append(managers, &copied)
+	}
+	return managers, nil
+}
+
+// GetManager retrieves a manager by its ID.
+func (r *Repository) GetManager(ctx context.Context, managerID int) (*domain.Manager, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	manager, ok := s.managers[managerID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *manager
+	return &copied, nil
+}
+
+// CreateManager adds a manager. ErrConflict is returned if the manager_id already exists.
+func (r *Repository) CreateManager(ctx context.Context, manager domain.Manager) (*domain.Manager, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.managers[manager.ManagerID]; ok {
+		return nil, repository.ErrConflict
+	}
+	if _, ok := s.dealerships[manager.DealershipID]; !ok {
+		return nil, fmt.Errorf("failed to create manager: %w", foreignKeyError("managers", "dealership_id"))
+	}
+	manager.CreatedAt = time.Now()
+	manager.UpdatedAt = manager.CreatedAt
+	s.managers[manager.ManagerID] = &manager
+
+	created := manager
+	return &created, nil
+}
+
+// UpdateManager updates a manager. ErrNotFound is returned if the manager does not exist.
+func (r *Repository) UpdateManager(ctx context.Context, manager domain.Manager) (*domain.Manager, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	stored, ok := s.managers[manager.ManagerID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if _, ok := s.dealerships[manager.DealershipID]; !ok {
+		return nil, fmt.Errorf("failed to update manager: %w", foreignKeyError("managers", "dealership_id"))
+	}
+	stored.DealershipID, stored.FullName, stored.Phone = manager.DealershipID, manager.FullName, manager.Phone
+	stored.Email, stored.Active = manager.Email, manager.Active
+	stored.UpdatedAt = time.Now()
+
+	updated := *stored
+	return &updated, nil
+}
+
+// DeleteManager removes a manager. ErrConflict is returned if deals reference the manager.
+func (r *Repository) DeleteManager(ctx context.Context, managerID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.managers[managerID]; !ok {
+		return repository.ErrNotFound
+	}
+	for _, deal := range s.deals {
+		if deal.ManagerID == managerID {
+			return repository.ErrConflict
+		}
+	}
+	delete(s.managers, managerID)
+	return nil
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// CreateBankStatementLine stores an unmatched bank statement line. It reports false without storing
+// the line if a line with the same payment reference, value date and amount was imported before.
+func (r *Repository) CreateBankStatementLine(ctx context.Context, line *domain.BankStatementLine) (*domain.BankStatementLine, bool, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	for _, other := range s.statementLines {
+		if other.PaymentReference == line.PaymentReference && other.ValueDate == line.ValueDate && other.Amount == line.Amount {
+			return nil, false, nil
+		}
+	}
+	if _, ok := s.currencies[line.CurrencyCode]; !ok {
+		return nil, false, fmt.Errorf("failed to create bank statement line: %w", foreignKeyError("bank_statement_lines", "currency_code"))
+	}
+
+	stored := &domain.BankStatementLine{
+		LineID:           s.nextID("bank_statement_lines"),
+		PaymentReference: line.PaymentReference,
+		Amount:           line.Amount,
+		CurrencyCode:     line.CurrencyCode,
+		ValueDate:        line.ValueDate,
+		PayerName:        line.PayerName,
+		Purpose:          line.Purpose,
+		Status:           domain.StatementLineUnmatched,
+		ImportedAt:       time.Now(),
+	}
+	s.statementLines = append(s.statementLines, stored)
+
+	created := *stored
+	return &created, true, nil
+}
+
+// MatchBankStatementLine links a bank statement line to the settlement the payment executed.
+func (r *Repository) MatchBankStatementLine(ctx context.Context, lineID, settlementID int) (*domain.BankStatementLine, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	for _, line := range s.statementLines {
+		if line.LineID != lineID {
+			continue
+		}
+		if _, ok := s.settlements[settlementID]; !ok {
+			return nil, fmt.Errorf("failed to match bank statement line: %w",
+				foreignKeyError("bank_statement_lines", "monetary_settlement_id"))
+		}
+		line.Status = domain.StatementLineMatched
+		line.MonetarySettlementID = intPtr(settlementID)
+		line.MatchedAt = timePtr(time.Now())
+
+		matched := *line
+		return &matched, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+// ListPendingSettlementsByAmount retrieves the pending settlements of the amount in the currency,
+// the candidates an incoming payment can execute.
+func (r *Repository) ListPendingSettlementsByAmount(ctx context.Context, amount domain.Money, currencyCode string) ([]*domain.MonetarySettlement, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var settlements []*domain.MonetarySettlement
+	for _, settlementID := range sortedKeys(s.settlements) {
+		row := s.settlements[settlementID]
+		if row.Status == domain.StatusPending && row.Amount == amount && row.CurrencyCode == currencyCode && row.deletedAt == nil {
+			settlements = append(settlements, s.settlementOut(row))
+		}
+	}
+	return settlements, nil
+}
+
+// CreatePaymentConfirmation stores a received payment confirmation. It reports false without storing
+// the confirmation if the source already sent a confirmation with the same external ID.
+func (r *Repository) CreatePaymentConfirmation(ctx context.Context, confirmation *domain.PaymentConfirmation) (*domain.PaymentConfirmation, bool, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	for _, other := range s.confirmations {
+		if other.Source == confirmation.Source && other.ExternalID == confirmation.ExternalID {
+			return nil, false, nil
+		}
+	}
+
+	stored := &domain.PaymentConfirmation{
+		ConfirmationID:   s.nextID("payment_confirmations"),
+		Source:           confirmation.Source,
+		ExternalID:       confirmation.ExternalID,
+		ReferenceNumber:  confirmation.ReferenceNumber,
+		PaymentReference: confirmation.PaymentReference,
+		Amount:           confirmation.Amount,
+		CurrencyCode:     confirmation.CurrencyCode,
+		Status:           confirmation.Status,
+		Outcome:          domain.ConfirmationReceived,
+		ReceivedAt:       time.Now(),
+	}
+	s.confirmations = append(s.confirmations, stored)
+
+	created := *stored
+	return &created, true, nil
+}
+
+// GetPaymentConfirmation retrieves the confirmation a source sent with the external ID.
+func (r *Repository) GetPaymentConfirmation(ctx context.Context, source, externalID string) (*domain.PaymentConfirmation, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	for _, confirmation := range s.confirmations {
+		if confirmation.Source == source && confirmation.ExternalID == externalID {
+			copied := *confirmation
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+// CompletePaymentConfirmation records the processing outcome of a confirmation and the settlement it was linked to.
+func (r *Repository) CompletePaymentConfirmation(ctx context.Context, confirmationID int, outcome string, settlementID *int,
+	reason string) (*domain.PaymentConfirmation, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	for _, confirmation := range s.confirmations {
+		if confirmation.ConfirmationID != confirmationID {
+			continue
+		}
+		confirmation.Outcome = outcome
+		confirmation.MonetarySettlementID = nil
+		if settlementID != nil {
+			confirmation.MonetarySettlementID = intPtr(*settlementID)
+		}
+		confirmation.Reason = reason
+		confirmation.ProcessedAt = timePtr(time.Now())
+
+		completed := *confirmation
+		return &completed, nil
+	}
+	return nil, repository.ErrNotFound
+}
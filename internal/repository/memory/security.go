@@ -0,0 +1,236 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"cliring/internal/domain"
+)
+
+// idempotencyKey identifies an idempotency key within its scope.
+type idempotencyKey struct {
+	scope string
+	key   string
+}
+
+// idempotencyEntry is the reservation of an idempotency key and, once completed, the stored response.
+type idempotencyEntry struct {
+	owner       string
+	requestHash string
+	statusCode  *int
+	body        []byte
+	createdAt   time.Time
+	heartbeatAt time.Time
+}
+
+// rateLimit counts the requests of a key in its current window.
+type rateLimit struct {
+	windowStart time.Time
+	hits        int
+}
+
+// InsertAuditEntry appends an entry to the audit log.
+func (r *Repository) InsertAuditEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	stored := *entry
+	stored.AuditID = int64(s.nextID("audit_log"))
+	stored.OccurredAt = time.Now()
+	stored.ImpersonatedClientID = nil
+	if entry.ImpersonatedClientID != nil {
+		stored.ImpersonatedClientID = intPtr(*entry.ImpersonatedClientID)
+	}
+	s.audit = append(s.audit, &stored)
+	return nil
+}
+
+// matchesAuditFilter reports whether an audit entry matches the filter.
+func matchesAuditFilter(entry *domain.AuditEntry, filter domain.AuditFilter) bool {
+	switch {
+	case filter.Actor != "" && entry.Actor != filter.Actor,
+		filter.Entity != "" && entry.Entity != filter.Entity,
+		filter.EntityID != "" && entry.EntityID != filter.EntityID,
+		filter.Method != "" && entry.Method != filter.Method,
+		filter.Impersonated && entry.ImpersonatedClientID == nil,
+		filter.From != nil && entry.OccurredAt.Before(*filter.From),
+		filter.To != nil && !entry.OccurredAt.Before(*filter.To):
+		return false
+	}
+	return true
+}
+
+// ListAuditEntries retrieves a page of the audit log matching the filter, newest first, together with
+// the total number of matching entries.
+func (r *Repository) ListAuditEntries(ctx context.Context, filter domain.AuditFilter, page, limit int) ([]*domain.AuditEntry, int, error) {
+	if err := validPage(page, limit); err != nil {
+		return nil, 0, err
+	}
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var matched []*domain.AuditEntry
+	for _, entry := range s.audit {
+		if matchesAuditFilter(entry, filter) {
+			matched = append(matched, entry)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].AuditID > matched[j].AuditID })
+
+	var entries []*domain.AuditEntry
+	for _, entry := range pageOf(matched, page, limit) {
+		copied := *entry
+		entries = append(entries, &copied)
+	}
+	return entries, len(matched), nil
+}
+
+// RevokeToken stores a revocation of the token until its expiry. A repeated revocation keeps the first one
+// and extends its expiry.
+func (r *Repository) RevokeToken(ctx context.Context, revocation domain.TokenRevocation) (*domain.TokenRevocation, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var expiresAt time.Time
+	if revocation.ExpiresAt != nil {
+		expiresAt = *revocation.ExpiresAt
+	}
+
+	stored, ok := s.revokedTokens[revocation.JTI]
+	if !ok {
+		stored = &domain.TokenRevocation{
+			JTI:       revocation.JTI,
+			ExpiresAt: timePtr(expiresAt),
+			RevokedBy: actorFromContext(ctx),
+			Reason:    revocation.Reason,
+			RevokedAt: time.Now(),
+		}
+		s.revokedTokens[revocation.JTI] = stored
+	} else if expiresAt.After(*stored.ExpiresAt) {
+		stored.ExpiresAt = timePtr(expiresAt)
+	}
+
+	revoked := *stored
+	revoked.ExpiresAt = timePtr(*stored.ExpiresAt)
+	return &revoked, nil
+}
+
+// IsTokenRevoked reports whether an unexpired revocation of the token exists.
+func (r *Repository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	revocation, ok := s.revokedTokens[jti]
+	return ok && revocation.ExpiresAt.After(time.Now()), nil
+}
+
+// PurgeRevokedTokens deletes revocations of tokens expired before the given time and returns their number.
+func (r *Repository) PurgeRevokedTokens(ctx context.Context, before time.Time) (int64, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var purged int64
+	for jti, revocation := range s.revokedTokens {
+		if revocation.ExpiresAt.Before(before) {
+			delete(s.revokedTokens, jti)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// ReserveIdempotencyKey reserves the key for a request of owner. It reports true if the caller holds the reservation;
+// otherwise the stored record of the earlier request is returned. A key is taken over once it expires, or
+// when the request holding it is still in progress but its heartbeat is older than abandonedBefore.
+func (r *Repository) ReserveIdempotencyKey(ctx context.Context, scope, key, owner, requestHash string, expiredBefore, abandonedBefore time.Time) (*domain.IdempotencyRecord, bool, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	id := idempotencyKey{scope: scope, key: key}
+	entry, ok := s.idempotencyKeys[id]
+	if ok && !entry.createdAt.Before(expiredBefore) && (entry.statusCode != nil || !entry.heartbeatAt.Before(abandonedBefore)) {
+		record := &domain.IdempotencyRecord{RequestHash: entry.requestHash, Body: slices.Clone(entry.body)}
+		if entry.statusCode != nil {
+			record.StatusCode = intPtr(*entry.statusCode)
+		}
+		return record, false, nil
+	}
+
+	now := time.Now()
+	s.idempotencyKeys[id] = &idempotencyEntry{owner: owner, requestHash: requestHash, createdAt: now, heartbeatAt: now}
+	return nil, true, nil
+}
+
+// TouchIdempotencyKey records a heartbeat of the request that reserved the key and is still running.
+func (r *Repository) TouchIdempotencyKey(ctx context.Context, scope, key, owner string) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if entry, ok := s.idempotencyKeys[idempotencyKey{scope: scope, key: key}]; ok && entry.owner == owner && entry.statusCode == nil {
+		entry.heartbeatAt = time.Now()
+	}
+	return nil
+}
+
+// CompleteIdempotencyKey stores the response of the request that reserved the key. Nothing is stored if the key
+// has been taken over by another request in the meantime.
+func (r *Repository) CompleteIdempotencyKey(ctx context.Context, scope, key, owner string, statusCode int, body []byte) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if entry, ok := s.idempotencyKeys[idempotencyKey{scope: scope, key: key}]; ok && entry.owner == owner {
+		entry.statusCode = intPtr(statusCode)
+		entry.body = slices.Clone(body)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey removes the reservation of a request that failed, so that it can be retried. A key taken
+// over by another request is kept.
+func (r *Repository) ReleaseIdempotencyKey(ctx context.Context, scope, key, owner string) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	id := idempotencyKey{scope: scope, key: key}
+	if entry, ok := s.idempotencyKeys[id]; ok && entry.owner == owner && entry.statusCode == nil {
+		delete(s.idempotencyKeys, id)
+	}
+	return nil
+}
+
+// PurgeIdempotencyKeys deletes keys created before the given time and returns their number.
+func (r *Repository) PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var purged int64
+	for id, entry := range s.idempotencyKeys {
+		if entry.createdAt.Before(before) {
+			delete(s.idempotencyKeys, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// HitRateLimit counts a request with the key in the window starting at windowStart and returns the number
+// of requests in that window. A key keeps a single counter that is reset when a new window starts.
+func (r *Repository) HitRateLimit(ctx context.Context, key string, windowStart time.Time) (int, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	limit, ok := s.rateLimits[key]
+	if !ok {
+		limit = &rateLimit{}
+		s.rateLimits[key] = limit
+	}
+	if ok && limit.windowStart.Equal(windowStart) {
+		limit.hits++
+	} else {
+		limit.windowStart, limit.hits = windowStart, 1
+	}
+	return limit.hits, nil
+}
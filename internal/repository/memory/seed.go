@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"cliring/internal/domain"
+)
+
+// seedCurrencies are the currencies of the currencies migration.
+var seedCurrencies = []domain.Currency{
+	{Code: "RUB", Name: "Российский рубль", MinorUnit: 2, Symbol: "₽"},
+	{Code: "USD", Name: "Доллар США", MinorUnit: 2, Symbol: "$"},
+	{Code: "EUR", Name: "Евро", MinorUnit: 2, Symbol: "€"},
+	{Code: "CNY", Name: "Китайский юань", MinorUnit: 2, Symbol: "¥"},
+}
+
+// seedOrderTypes are the order types of the order type catalog migrations.
+var seedOrderTypes = []domain.OrderType{
+	{OrderTypeID: 1, Name: "ПОКУПКА", Debtor: domain.ParticipantClient, Creditor: domain.ParticipantDealership, Kind: domain.OrderKindOrder},
+	{OrderTypeID: 2, Name: "КРЕДИТ", Debtor: domain.ParticipantBank, Creditor: domain.ParticipantClient, Kind: domain.OrderKindOrder},
+	{OrderTypeID: 3, Name: "ТРЕЙД-ИН", Debtor: domain.ParticipantDealership, Creditor: domain.ParticipantClient, Kind: domain.OrderKindOrder},
+	{OrderTypeID: 4, Name: "ВОЗВРАТ", Debtor: domain.ParticipantDealership, Creditor: domain.ParticipantClient, Kind: domain.OrderKindOrder},
+	{OrderTypeID: 5, Name: "СТРАХОВАНИЕ", Debtor: domain.ParticipantClient, Creditor: domain.ParticipantInsurer, Kind: domain.OrderKindOrder},
+	{OrderTypeID: 6, Name: "КОМИССИЯ ОФОРМЛЕНИЯ", Debtor: domain.ParticipantClient, Creditor: domain.ParticipantDealership, Kind: domain.OrderKindFee},
+	{OrderTypeID: 7, Name: "ХРАНЕНИЕ", Debtor: domain.ParticipantClient, Creditor: domain.ParticipantDealership, Kind: domain.OrderKindFee},
+	{OrderTypeID: 8, Name: "НЕУСТОЙКА", Debtor: domain.ParticipantClient, Creditor: domain.ParticipantDealership, Kind: domain.OrderKindPenalty},
+}
+
+// seedCalendar are the business calendar exceptions of the business calendar migration.
+var seedCalendar = []domain.CalendarDay{
+	{Date: "2025-01-01", Name: "Новогодние каникулы"}, {Date: "2025-01-02", Name: "Новогодние каникулы"},
+	{Date: "2025-01-03", Name: "Новогодние каникулы"}, {Date: "2025-01-06", Name: "Новогодние каникулы"},
+	{Date: "2025-01-07", Name: "Рождество Христово"}, {Date: "2025-01-08", Name: "Новогодние каникулы"},
+	{Date: "2025-05-01", Name: "Праздник Весны и Труда"}, {Date: "2025-05-02", Name: "Перенос выходного дня"},
+	{Date: "2025-05-08", Name: "Перенос выходного дня"}, {Date: "2025-05-09", Name: "День Победы"},
+	{Date: "2025-06-12", Name: "День России"}, {Date: "2025-06-13", Name: "Перенос выходного дня"},
+	{Date: "2025-11-03", Name: "Перенос выходного дня"}, {Date: "2025-11-04", Name: "День народного единства"},
+	{Date: "2025-12-31", Name: "Перенос выходного дня"},
+	{Date: "2026-01-01", Name: "Новогодние каникулы"}, {Date: "2026-01-02", Name: "Новогодние каникулы"},
+	{Date: "2026-01-05", Name: "Новогодние каникулы"}, {Date: "2026-01-06", Name: "Новогодние каникулы"},
+	{Date: "2026-01-07", Name: "Рождество Христово"}, {Date: "2026-01-08", Name: "Новогодние каникулы"},
+	{Date: "2026-01-09", Name: "Перенос выходного дня"}, {Date: "2026-02-23", Name: "День защитника Отечества"},
+	{Date: "2026-03-09", Name: "Перенос выходного дня"}, {Date: "2026-05-01", Name: "Праздник Весны и Труда"},
+	{Date: "2026-05-11", Name: "Перенос выходного дня"}, {Date: "2026-06-12", Name: "День России"},
+	{Date: "2026-11-04", Name: "День народного единства"}, {Date: "2026-12-31", Name: "Перенос выходного дня"},
+	{Date: "2025-11-01", Name: "Рабочая суббота", IsWorkingDay: true},
+}
+
+// seed fills the reference tables the migrations fill in Postgres.
+func (s *store) seed() {
+	for _, currency := range seedCurrencies {
+		currency.Rounding = domain.DefaultRoundingPolicy
+		s.currencies[currency.Code] = &currency
+	}
+	for _, orderType := range seedOrderTypes {
+		s.orderTypes[orderType.OrderTypeID] = &orderType
+	}
+	for _, day := range seedCalendar {
+		s.calendar[day.Date] = &day
+	}
+}
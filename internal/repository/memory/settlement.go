@@ -0,0 +1,775 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// settlementRow is a stored monetary settlement with the columns the domain type does not carry.
+// PaidAmount and RemainingAmount are not stored: settlementOut computes them from the payments.
+type settlementRow struct {
+	domain.MonetarySettlement
+	deletedAt *time.Time
+}
+
+// values converts the row into a map of its JSON fields.
+func (m *settlementRow) values() map[string]any {
+	values := jsonValues(m.MonetarySettlement)
+	values["deleted_at"] = m.deletedAt
+	return values
+}
+
+// paid returns the sum of the partial payments recorded against a settlement.
+func (s *store) paid(settlementID int) domain.Money {
+	var paid domain.Money
+	for _, payment := range s.payments {
+		if payment.MonetarySettlementID == settlementID {
+			paid += payment.Amount
+		}
+	}
+	return paid
+}
+
+// settlementOut returns a copy of a stored settlement with the amount covered by partial payments.
+func (s *store) settlementOut(row *settlementRow) *domain.MonetarySettlement {
+	settlement := row.MonetarySettlement
+	settlement.FXRates = slices.Clone(row.FXRates)
+	settlement.PaidAmount = s.paid(settlement.MonetarySettlementID)
+	settlement.RemainingAmount = 0
+	if settlement.Status == domain.StatusPending {
+		settlement.RemainingAmount = settlement.Amount - settlement.PaidAmount
+	}
+	return &settlement
+}
+
+// liveSettlement returns a settlement that is not deleted.
+func (s *store) liveSettlement(settlementID int) (*settlementRow, error) {
+	row, ok := s.settlements[settlementID]
+	if !ok || row.deletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	return row, nil
+}
+
+// checkSettlement checks the constraints and the foreign keys of a settlement.
+func (s *store) checkSettlement(settlement *domain.MonetarySettlement) error {
+	if settlement.DealID != nil && s.deals[*settlement.DealID] == nil {
+		return foreignKeyError("monetary_settlements", "deal_id")
+	}
+	if settlement.BankID != nil && s.banks[*settlement.BankID] == nil {
+		return foreignKeyError("monetary_settlements", "bank_id")
+	}
+	if _, ok := s.currencies[settlement.CurrencyCode]; !ok {
+		return foreignKeyError("monetary_settlements", "currency_code")
+	}
+	if settlement.ClearingSessionID != nil && s.sessions[*settlement.ClearingSessionID] == nil {
+		return foreignKeyError("monetary_settlements", "clearing_session_id")
+	}
+	return nil
+}
+
+// insertSettlement stores a new settlement in the given status.
+func (s *store) insertSettlement(settlement domain.MonetarySettlement, status string) *settlementRow {
+	now := time.Now()
+	row := &settlementRow{MonetarySettlement: settlement}
+	row.MonetarySettlementID = s.nextID("monetary_settlements")
+	row.Status = status
+	row.CreatedAt, row.UpdatedAt = now, now
+	s.settlements[row.MonetarySettlementID] = row
+	s.recordSettlement("insert", row)
+	return row
+}
+
+// NextSettlementRun issues the next settlement run number of a deal.
+func (r *Repository) NextSettlementRun(ctx context.Context, dealID int) (int, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.deals[dealID]; !ok {
+		return 0, fmt.Errorf("failed to issue settlement run number: %w", foreignKeyError("deal_counters", "deal_id"))
+	}
+	counter := s.counter(dealID)
+	counter.lastSettlementRun++
+	return counter.lastSettlementRun, nil
+}
+
+// CreateMonetarySettlement creates a new monetary settlement.
+func (r *Repository) CreateMonetarySettlement(ctx context.Context, settlement *domain.MonetarySettlement) (*domain.MonetarySettlement, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if settlement.Amount <= 0 {
+		return nil, fmt.Errorf("failed to create monetary settlement: %w", checkError("monetary_settlements", "amount"))
+	}
+	if err := s.checkSettlement(settlement); err != nil {
+		return nil, fmt.Errorf("failed to create monetary settlement: %w", err)
+	}
+
+	row := s.insertSettlement(domain.MonetarySettlement{
+		DealID:       settlement.DealID,
+		Amount:       settlement.Amount,
+		BankID:       settlement.BankID,
+		CurrencyCode: settlement.CurrencyCode,
+	}, settlement.Status)
+
+	return &domain.MonetarySettlement{
+		MonetarySettlementID: row.MonetarySettlementID,
+		DealID:               row.DealID,
+		Amount:               row.Amount,
+		Status:               row.Status,
+		CreatedAt:            row.CreatedAt,
+		UpdatedAt:            row.UpdatedAt,
+		BankID:               row.BankID,
+		CurrencyCode:         row.CurrencyCode,
+	}, nil
+}
+
+// ExecuteMonetarySettlement stores an executed settlement together with its allocations to orders
+// and its ledger entries. Orders that become fully paid are switched to the executed status.
+func (r *Repository) ExecuteMonetarySettlement(ctx context.Context, settlement *domain.MonetarySettlement,
+	allocations []*domain.OrderAllocation, entries []*domain.LedgerEntry) (*domain.MonetarySettlement, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if err := s.checkSettlement(settlement); err != nil {
+		return nil, fmt.Errorf("failed to create monetary settlement: %w", err)
+	}
+	if err := s.checkAllocations(allocations); err != nil {
+		return nil, err
+	}
+
+	row := s.insertSettlement(domain.MonetarySettlement{
+		DealID:           settlement.DealID,
+		Amount:           settlement.Amount,
+		BankID:           settlement.BankID,
+		CurrencyCode:     settlement.CurrencyCode,
+		DueDate:          settlement.DueDate,
+		DealershipID:     settlement.DealershipID,
+		RunNumber:        settlement.RunNumber,
+		InsurerID:        settlement.InsurerID,
+		Participant:      settlement.Participant,
+		FXRates:          slices.Clone(settlement.FXRates),
+		RoundingResidual: settlement.RoundingResidual,
+	}, domain.StatusExecuted)
+	row.ExecutedAt = timePtr(row.CreatedAt)
+
+	executed := s.settlementOut(row)
+	executed.Allocations = s.insertAllocations(row.MonetarySettlementID, allocations)
+	executed.LedgerEntries = s.insertLedgerEntries(row, entries)
+	return executed, nil
+}
+
+// checkAllocations checks that the allocated orders exist.
+func (s *store) checkAllocations(allocations []*domain.OrderAllocation) error {
+	for _, allocation := range allocations {
+		if _, ok := s.orders[allocation.OrderID]; !ok {
+			return fmt.Errorf("failed to create order allocation: %w", foreignKeyError("order_allocations", "order_id"))
+		}
+	}
+	return nil
+}
+
+// insertAllocations stores the allocations of an executed settlement to orders and switches
+// orders that become fully paid to the executed status.
+func (s *store) insertAllocations(settlementID int, allocations []*domain.OrderAllocation) []*domain.OrderAllocation {
+	now := time.Now()
+	var created []*domain.OrderAllocation
+	for _, allocation := range allocations {
+		stored := &domain.OrderAllocation{
+			AllocationID:         s.nextID("order_allocations"),
+			MonetarySettlementID: settlementID,
+			OrderID:              allocation.OrderID,
+			Amount:               allocation.Amount,
+			CreatedAt:            now,
+		}
+		s.allocations = append(s.allocations, stored)
+		copied := *stored
+		created = append(created, &copied)
+	}
+
+	// Заказы, полностью покрытые распределениями, считаются исполненными
+	for _, allocation := range created {
+		order := s.orders[allocation.OrderID]
+		if order.Status == domain.StatusExecuted {
+			continue
+		}
+		var paid domain.Money
+		for _, other := range s.allocations {
+			if other.OrderID == order.OrderID {
+				paid += other.Amount
+			}
+		}
+		if order.grossAmount() <= paid {
+			order.Status = domain.StatusExecuted
+			order.UpdatedAt = now
+			s.recordOrder("update", order)
+		}
+	}
+	return created
+}
+
+// insertLedgerEntries posts the ledger entries of an executed settlement.
+func (s *store) insertLedgerEntries(settlement *settlementRow, entries []*domain.LedgerEntry) []*domain.LedgerEntry {
+	var dealID int
+	if settlement.DealID != nil {
+		dealID = *settlement.DealID
+	}
+	now := time.Now()
+	var created []*domain.LedgerEntry
+	for _, entry := range entries {
+		stored := &domain.LedgerEntry{
+			LedgerEntryID:        s.nextID("ledger_entries"),
+			MonetarySettlementID: settlement.MonetarySettlementID,
+			DealID:               dealID,
+			Account:              entry.Account,
+			Direction:            entry.Direction,
+			Amount:               entry.Amount,
+			CurrencyCode:         settlement.CurrencyCode,
+			PaymentReference:     settlement.PaymentReference,
+			CreatedAt:            now,
+		}
+		s.ledger = append(s.ledger, stored)
+		copied := *stored
+		created = append(created, &copied)
+	}
+	return created
+}
+
+// ClearSettlementsRecompute resets the settlement recomputation flag of a deal after its settlements were executed.
+func (r *Repository) ClearSettlementsRecompute(ctx context.Context, dealID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if deal, ok := s.deals[dealID]; ok && deal.SettlementsRecomputeRequired {
+		deal.SettlementsRecomputeRequired = false
+		s.recordDeal("update", deal)
+	}
+	return nil
+}
+
+// FixPendingSettlements stores the netting result of a deal as pending settlements to be executed
+// one by one. Pending settlements fixed earlier for the deal are cancelled.
+func (r *Repository) FixPendingSettlements(ctx context.Context, dealID int, settlements []*domain.MonetarySettlement) ([]*domain.MonetarySettlement, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	for _, settlement := range settlements {
+		fixed := *settlement
+		fixed.DealID = intPtr(dealID)
+		if err := s.checkSettlement(&fixed); err != nil {
+			return nil, fmt.Errorf("failed to create monetary settlement: %w", err)
+		}
+	}
+
+	s.cancelPendingSettlements(dealID)
+
+	var fixed []*domain.MonetarySettlement
+	for _, settlement := range settlements {
+		row := s.insertSettlement(domain.MonetarySettlement{
+			DealID:            intPtr(dealID),
+			Amount:            settlement.Amount,
+			BankID:            settlement.BankID,
+			CurrencyCode:      settlement.CurrencyCode,
+			DueDate:           settlement.DueDate,
+			DealershipID:      settlement.DealershipID,
+			RunNumber:         settlement.RunNumber,
+			InsurerID:         settlement.InsurerID,
+			Participant:       settlement.Participant,
+			FXRates:           slices.Clone(settlement.FXRates),
+			ApprovalStatus:    settlement.ApprovalStatus,
+			CreatedBy:         settlement.CreatedBy,
+			ClearingSessionID: settlement.ClearingSessionID,
+			RoundingResidual:  settlement.RoundingResidual,
+		}, domain.StatusPending)
+		fixed = append(fixed, s.settlementOut(row))
+	}
+
+	return fixed, nil
+}
+
+// CancelPendingSettlements cancels the pending settlements of a deal that were not executed
+// and returns the cancelled settlements.
+func (r *Repository) CancelPendingSettlements(ctx context.Context, dealID int) ([]*domain.MonetarySettlement, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	return s.cancelPendingSettlements(dealID), nil
+}
+
+// cancelPendingSettlements cancels the pending settlements of a deal.
+func (s *store) cancelPendingSettlements(dealID int) []*domain.MonetarySettlement {
+	now := time.Now()
+	var cancelled []*domain.MonetarySettlement
+	for _, settlementID := range sortedKeys(s.settlements) {
+		row := s.settlements[settlementID]
+		if row.DealID == nil || *row.DealID != dealID || row.Status != domain.StatusPending || row.deletedAt != nil {
+			continue
+		}
+		row.Status = domain.StatusCancelled
+		row.CancelledAt = timePtr(now)
+		row.UpdatedAt = now
+		s.recordSettlement("update", row)
+		cancelled = append(cancelled, s.settlementOut(row))
+	}
+	return cancelled
+}
+
+// storedSettlements returns the settlements of a deal in the given status, in ID order.
+func (s *store) storedSettlements(dealID int, status string) []*settlementRow {
+	var rows []*settlementRow
+	for _, settlementID := range sortedKeys(s.settlements) {
+		row := s.settlements[settlementID]
+		if row.DealID != nil && *row.DealID == dealID && row.Status == status && row.deletedAt == nil {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// ListStoredMonetarySettlements retrieves a page of the stored settlements of a deal in the given status
+// and the total number of such settlements.
+func (r *Repository) ListStoredMonetarySettlements(ctx context.Context, dealID int, status string, page, limit int) ([]*domain.MonetarySettlement, int, error) {
+	if err := validPage(page, limit); err != nil {
+		return nil, 0, err
+	}
+
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	rows := s.storedSettlements(dealID, status)
+	var settlements []*domain.MonetarySettlement
+	for _, row := range pageOf(rows, page, limit) {
+		settlements = append(settlements, s.settlementOut(row))
+	}
+	return settlements, len(rows), nil
+}
+
+// CountStoredMonetarySettlements returns the number of stored settlements of a deal in the given status.
+func (r *Repository) CountStoredMonetarySettlements(ctx context.Context, dealID int, status string) (int, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	return len(s.storedSettlements(dealID, status)), nil
+}
+
+// GetMonetarySettlement retrieves a stored monetary settlement by its ID.
+func (r *Repository) GetMonetarySettlement(ctx context.Context, settlementID int) (*domain.MonetarySettlement, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveSettlement(settlementID)
+	if err != nil {
+		return nil, err
+	}
+	return s.settlementOut(row), nil
+}
+
+// ExecutePendingSettlement moves a pending settlement to the executed status with the payment reference,
+// allocates it to orders and posts its ledger entries.
+// ErrConflict is returned if the settlement is no longer pending.
+func (r *Repository) ExecutePendingSettlement(ctx context.Context, settlementID int, paymentReference string,
+	allocations []*domain.OrderAllocation, entries []*domain.LedgerEntry) (*domain.MonetarySettlement, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, ok := s.settlements[settlementID]
+	if !ok || row.Status != domain.StatusPending || row.deletedAt != nil ||
+		(row.ApprovalStatus != "" && row.ApprovalStatus != domain.ApprovalApproved) {
+		return nil, repository.ErrConflict
+	}
+	if err := s.checkAllocations(allocations); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	row.Status = domain.StatusExecuted
+	row.PaymentReference = paymentReference
+	row.ExecutedAt = timePtr(now)
+	row.UpdatedAt = now
+	s.recordSettlement("update", row)
+
+	executed := s.settlementOut(row)
+	executed.Allocations = s.insertAllocations(settlementID, allocations)
+	executed.LedgerEntries = s.insertLedgerEntries(row, entries)
+	return executed, nil
+}
+
+// CancelMonetarySettlement cancels a pending settlement with the reason and flags its deal for re-netting.
+// ErrConflict is returned if the settlement is no longer pending.
+func (r *Repository) CancelMonetarySettlement(ctx context.Context, settlementID int, reason string) (*domain.MonetarySettlement, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, ok := s.settlements[settlementID]
+	if !ok || row.Status != domain.StatusPending || row.deletedAt != nil {
+		return nil, repository.ErrConflict
+	}
+
+	now := time.Now()
+	row.Status = domain.StatusCancelled
+	row.CancellationReason = reason
+	row.CancelledAt = timePtr(now)
+	row.UpdatedAt = now
+	s.recordSettlement("update", row)
+	if row.DealID != nil {
+		s.flagDealForRecompute(*row.DealID)
+	}
+
+	return s.settlementOut(row), nil
+}
+
+// ReviewMonetarySettlement records the decision of an approver on a pending settlement awaiting approval.
+// ErrConflict is returned if the settlement is no longer pending or does not await approval.
+func (r *Repository) ReviewMonetarySettlement(ctx context.Context, settlementID int, approvalStatus, reviewer, comment string) (*domain.MonetarySettlement, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, ok := s.settlements[settlementID]
+	if !ok || row.Status != domain.StatusPending || row.ApprovalStatus != domain.ApprovalRequired || row.deletedAt != nil {
+		return nil, repository.ErrConflict
+	}
+
+	now := time.Now()
+	row.ApprovalStatus = approvalStatus
+	row.ReviewedBy = reviewer
+	row.ReviewedAt = timePtr(now)
+	row.ReviewComment = comment
+	row.UpdatedAt = now
+	s.recordSettlement("update", row)
+
+	return s.settlementOut(row), nil
+}
+
+// CreateSettlementPayment records a partial payment against a pending monetary settlement.
+// It returns ErrConflict if the settlement is not pending and ErrInvalidInput if the payment
+// exceeds the remaining balance of the settlement.
+func (r *Repository) CreateSettlementPayment(ctx context.Context, payment *domain.SettlementPayment) (*domain.SettlementPayment, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	row, err := s.liveSettlement(payment.MonetarySettlementID)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != domain.StatusPending {
+		return nil, repository.ErrConflict
+	}
+	if s.paid(row.MonetarySettlementID)+payment.Amount > row.Amount {
+		return nil, repository.ErrInvalidInput
+	}
+	if payment.Amount <= 0 {
+		return nil, fmt.Errorf("failed to create settlement payment: %w", checkError("settlement_payments", "amount"))
+	}
+
+	stored := &domain.SettlementPayment{
+		PaymentID:            s.nextID("settlement_payments"),
+		MonetarySettlementID: payment.MonetarySettlementID,
+		Amount:               payment.Amount,
+		PaymentReference:     payment.PaymentReference,
+		CreatedAt:            time.Now(),
+	}
+	s.payments = append(s.payments, stored)
+
+	created := *stored
+	return &created, nil
+}
+
+// ListSettlementPayments retrieves the partial payments of a monetary settlement in the order they were recorded.
+func (r *Repository) ListSettlementPayments(ctx context.Context, settlementID int) ([]*domain.SettlementPayment, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var payments []*domain.SettlementPayment
+	for _, payment := range s.payments {
+		if payment.MonetarySettlementID == settlementID {
+			copied := *payment
+			payments = append(payments, &copied)
+		}
+	}
+	return payments, nil
+}
+
+// ListPayablePendingSettlements retrieves the pending settlements of all deals that a participant has to pay.
+func (r *Repository) ListPayablePendingSettlements(ctx context.Context) ([]*domain.MonetarySettlement, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var settlements []*domain.MonetarySettlement
+	for _, settlementID := range sortedKeys(s.settlements) {
+		row := s.settlements[settlementID]
+		if row.Status == domain.StatusPending && row.Amount > 0 && row.DealID != nil && row.deletedAt == nil {
+			settlements = append(settlements, s.settlementOut(row))
+		}
+	}
+	return settlements, nil
+}
+
+// LastPenaltyDates retrieves the latest accrual date of the penalties of every settlement that has penalties.
+func (r *Repository) LastPenaltyDates(ctx context.Context, settlementIDs []int) (map[int]string, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	dates := map[int]string{}
+	for _, penalty := range s.penalties {
+		if slices.Contains(settlementIDs, penalty.MonetarySettlementID) && penalty.AccrualDate > dates[penalty.MonetarySettlementID] {
+			dates[penalty.MonetarySettlementID] = penalty.AccrualDate
+		}
+	}
+	return dates, nil
+}
+
+// CreateSettlementPenalties stores accrued penalties. A penalty already accrued for the settlement and day
+// is kept; it returns the number of penalties stored.
+func (r *Repository) CreateSettlementPenalties(ctx context.Context, penalties []*domain.SettlementPenalty) (int, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	created := 0
+	for _, penalty := range penalties {
+		if _, ok := s.settlements[penalty.MonetarySettlementID]; !ok {
+			return created, fmt.Errorf("failed to create settlement penalty: %w", foreignKeyError("settlement_penalties", "monetary_settlement_id"))
+		}
+		accrued := slices.ContainsFunc(s.penalties, func(p *domain.SettlementPenalty) bool {
+			return p.MonetarySettlementID == penalty.MonetarySettlementID && p.AccrualDate == penalty.AccrualDate
+		})
+		if accrued {
+			continue
+		}
+
+		stored := *penalty
+		stored.PenaltyID = s.nextID("settlement_penalties")
+		stored.CreatedAt = time.Now()
+		s.penalties = append(s.penalties, &stored)
+		created++
+	}
+	return created, nil
+}
+
+// ListSettlementPenalties retrieves the penalties of the deals accrued up to the date in DateLayout,
+// all of them if the date is empty, in the order of accrual.
+func (r *Repository) ListSettlementPenalties(ctx context.Context, dealIDs []int, upTo string) ([]*domain.SettlementPenalty, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var penalties []*domain.SettlementPenalty
+	for _, penalty := range s.penalties {
+		if slices.Contains(dealIDs, penalty.DealID) && (upTo == "" || penalty.AccrualDate <= upTo) {
+			copied := *penalty
+			penalties = append(penalties, &copied)
+		}
+	}
+	sort.SliceStable(penalties, func(i, j int) bool { return penalties[i].AccrualDate < penalties[j].AccrualDate })
+	return penalties, nil
+}
+
+// GetPaymentDetails retrieves the bank details of a participant account or of the clearing account.
+func (r *Repository) GetPaymentDetails(ctx context.Context, account string) (*domain.PaymentDetails, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	details, ok := s.paymentDetails[account]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *details
+	return &copied, nil
+}
+
+// AllocatedVAT returns the VAT part of a settlement: every allocation carries the VAT share of its order.
+func (r *Repository) AllocatedVAT(ctx context.Context, settlementID int) (domain.Money, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var vat domain.Money
+	for _, allocation := range s.allocations {
+		if allocation.MonetarySettlementID != settlementID {
+			continue
+		}
+		if order, ok := s.orders[allocation.OrderID]; ok {
+			vat += allocation.Amount.MulRatio(order.VATAmount, order.grossAmount())
+		}
+	}
+	return vat, nil
+}
+
+// dealershipSettlements returns the stored settlements of the deals of a dealership created in [from, to).
+func (s *store) dealershipSettlements(dealershipID int, from, to time.Time) []*settlementRow {
+	var rows []*settlementRow
+	for _, settlementID := range sortedKeys(s.settlements) {
+		row := s.settlements[settlementID]
+		if row.DealID == nil || row.deletedAt != nil || row.CreatedAt.Before(from) || !row.CreatedAt.Before(to) {
+			continue
+		}
+		if deal, ok := s.deals[*row.DealID]; ok && deal.DealershipID == dealershipID {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// totalKey groups settlement totals.
+type totalKey struct {
+	status       string
+	id           int
+	currencyCode string
+}
+
+// settlementTotals sums the amounts of the groups and returns the totals ordered by the group key.
+func settlementTotals(keys []totalKey, amounts map[totalKey]domain.Money, counts map[totalKey]int,
+	total func(key totalKey) *domain.SettlementTotal) []*domain.SettlementTotal {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].status != keys[j].status {
+			return keys[i].status < keys[j].status
+		}
+		if keys[i].id != keys[j].id {
+			return keys[i].id < keys[j].id
+		}
+		return keys[i].currencyCode < keys[j].currencyCode
+	})
+
+	totals := []*domain.SettlementTotal{}
+	for _, key := range keys {
+		t := total(key)
+		t.CurrencyCode, t.Count, t.Amount = key.currencyCode, counts[key], amounts[key]
+		totals = append(totals, t)
+	}
+	return totals
+}
+
+// SettlementTotalsByStatus aggregates the settlements of a dealership created in [from, to) by status and currency.
+func (r *Repository) SettlementTotalsByStatus(ctx context.Context, dealershipID int, from, to time.Time) ([]*domain.SettlementTotal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var keys []totalKey
+	amounts, counts := map[totalKey]domain.Money{}, map[totalKey]int{}
+	for _, row := range s.dealershipSettlements(dealershipID, from, to) {
+		key := totalKey{status: row.Status, currencyCode: row.CurrencyCode}
+		if _, ok := counts[key]; !ok {
+			keys = append(keys, key)
+		}
+		counts[key]++
+		amounts[key] += row.Amount
+	}
+	return settlementTotals(keys, amounts, counts, func(key totalKey) *domain.SettlementTotal {
+		return &domain.SettlementTotal{Status: key.status}
+	}), nil
+}
+
+// SettlementTotalsByBank aggregates the bank settlements of a dealership created in [from, to) by bank and currency.
+func (r *Repository) SettlementTotalsByBank(ctx context.Context, dealershipID int, from, to time.Time) ([]*domain.SettlementTotal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var keys []totalKey
+	amounts, counts := map[totalKey]domain.Money{}, map[totalKey]int{}
+	for _, row := range s.dealershipSettlements(dealershipID, from, to) {
+		if row.BankID == nil {
+			continue
+		}
+		key := totalKey{id: *row.BankID, currencyCode: row.CurrencyCode}
+		if _, ok := counts[key]; !ok {
+			keys = append(keys, key)
+		}
+		counts[key]++
+		amounts[key] += row.Amount
+	}
+	return settlementTotals(keys, amounts, counts, func(key totalKey) *domain.SettlementTotal {
+		return &domain.SettlementTotal{BankID: intPtr(key.id)}
+	}), nil
+}
+
+// SettlementTotalsByOrderType aggregates the allocations of the executed settlements of a dealership
+// created in [from, to) by the order type of the allocated orders and currency.
+func (r *Repository) SettlementTotalsByOrderType(ctx context.Context, dealershipID int, from, to time.Time) ([]*domain.SettlementTotal, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var keys []totalKey
+	amounts, counts := map[totalKey]domain.Money{}, map[totalKey]int{}
+	for _, row := range s.dealershipSettlements(dealershipID, from, to) {
+		counted := map[totalKey]bool{}
+		for _, allocation := range s.allocations {
+			order, ok := s.orders[allocation.OrderID]
+			if allocation.MonetarySettlementID != row.MonetarySettlementID || !ok {
+				continue
+			}
+			key := totalKey{id: order.OrderTypeID, currencyCode: row.CurrencyCode}
+			if _, ok := amounts[key]; !ok {
+				keys = append(keys, key)
+			}
+			amounts[key] += allocation.Amount
+			if !counted[key] {
+				counted[key] = true
+				counts[key]++
+			}
+		}
+	}
+	return settlementTotals(keys, amounts, counts, func(key totalKey) *domain.SettlementTotal {
+		return &domain.SettlementTotal{OrderTypeID: intPtr(key.id)}
+	}), nil
+}
+
+// ParticipantBalances aggregates the stored settlements of a participant across all deals by currency.
+// The client of a deal settlement is resolved through the deal.
+func (r *Repository) ParticipantBalances(ctx context.Context, participant domain.Participant) ([]*domain.ParticipantCurrencyBalance, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	balances := map[string]*domain.ParticipantCurrencyBalance{}
+	deals := map[string]map[int]bool{}
+	for _, row := range s.settlements {
+		if row.deletedAt != nil || row.Status == domain.StatusCancelled || row.Participant != participant.Role || row.DealID == nil {
+			continue
+		}
+		deal, ok := s.deals[*row.DealID]
+		if !ok {
+			continue
+		}
+		var participantID *int
+		switch row.Participant {
+		case domain.ParticipantClient:
+			participantID = intPtr(deal.ClientID)
+		case domain.ParticipantBank:
+			participantID = row.BankID
+		case domain.ParticipantDealership:
+			participantID = row.DealershipID
+		case domain.ParticipantInsurer:
+			participantID = row.InsurerID
+		}
+		if participantID == nil || participant.ExternalID == nil || *participantID != *participant.ExternalID {
+			continue
+		}
+
+		balance, ok := balances[row.CurrencyCode]
+		if !ok {
+			balance = &domain.ParticipantCurrencyBalance{CurrencyCode: row.CurrencyCode}
+			balances[row.CurrencyCode] = balance
+			deals[row.CurrencyCode] = map[int]bool{}
+		}
+		paid := s.paid(row.MonetarySettlementID)
+		switch row.Status {
+		case domain.StatusPending:
+			balance.Outstanding += row.Amount - paid
+			balance.Settled += paid
+			balance.PendingSettlements++
+		case domain.StatusExecuted:
+			balance.Settled += row.Amount
+		}
+		deals[row.CurrencyCode][*row.DealID] = true
+	}
+
+	var result []*domain.ParticipantCurrencyBalance
+	for _, currencyCode := range sortedKeys(balances) {
+		balance := balances[currencyCode]
+		balance.Deals = len(deals[currencyCode])
+		result = append(result, balance)
+	}
+	return result, nil
+}
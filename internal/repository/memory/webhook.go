@@ -0,0 +1,169 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// webhookDelivery is a queued delivery of an event to a webhook subscription.
+type webhookDelivery struct {
+	id             int64
+	subscriptionID int
+	event          string
+	payload        []byte
+	status         string
+	attempts       int
+	lastError      string
+	nextAttemptAt  time.Time
+	deliveredAt    *time.Time
+}
+
+// CreateWebhookSubscription stores a webhook subscription.
+func (r *Repository) CreateWebhookSubscription(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	stored := &domain.WebhookSubscription{
+		SubscriptionID: s.nextID("webhook_subscriptions"),
+		URL:            subscription.URL,
+		Events:         slices.Clone(subscription.Events),
+		Secret:         subscription.Secret,
+		CreatedAt:      time.Now(),
+	}
+	s.webhooks[stored.SubscriptionID] = stored
+
+	created := *stored
+	created.Events = slices.Clone(stored.Events)
+	return &created, nil
+}
+
+// ListWebhookSubscriptions retrieves all webhook subscriptions without their secrets.
+func (r *Repository) ListWebhookSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var subscriptions []*domain.WebhookSubscription
+	for _, subscriptionID := range sortedKeys(s.webhooks) {
+		subscription := *s.webhooks[subscriptionID]
+		subscription.Events = slices.Clone(subscription.Events)
+		subscription.Secret = ""
+		subscriptions = append(subscriptions, &subscription)
+	}
+	return subscriptions, nil
+}
+
+// DeleteWebhookSubscription removes a webhook subscription together with its queued deliveries.
+func (r *Repository) DeleteWebhookSubscription(ctx context.Context, subscriptionID int) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if _, ok := s.webhooks[subscriptionID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(s.webhooks, subscriptionID)
+	s.deliveries = slices.DeleteFunc(s.deliveries, func(delivery *webhookDelivery) bool {
+		return delivery.subscriptionID == subscriptionID
+	})
+	return nil
+}
+
+// EnqueueWebhookEvent queues a delivery of the event to every subscription listening to it.
+func (r *Repository) EnqueueWebhookEvent(ctx context.Context, event string, payload []byte) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	now := time.Now()
+	for _, subscriptionID := range sortedKeys(s.webhooks) {
+		if !slices.Contains(s.webhooks[subscriptionID].Events, event) {
+			continue
+		}
+		s.deliveries = append(s.deliveries, &webhookDelivery{
+			id:             int64(s.nextID("webhook_deliveries")),
+			subscriptionID: subscriptionID,
+			event:          event,
+			payload:        slices.Clone(payload),
+			status:         domain.WebhookDeliveryPending,
+			nextAttemptAt:  now,
+		})
+	}
+	return nil
+}
+
+// ListDueWebhookDeliveries retrieves the pending deliveries whose next attempt is due, oldest first.
+func (r *Repository) ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	var deliveries []*domain.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		if len(deliveries) >= limit {
+			break
+		}
+		if delivery.status != domain.WebhookDeliveryPending || delivery.nextAttemptAt.After(now) {
+			continue
+		}
+		subscription := s.webhooks[delivery.subscriptionID]
+		deliveries = append(deliveries, &domain.WebhookDelivery{
+			DeliveryID:     delivery.id,
+			SubscriptionID: delivery.subscriptionID,
+			URL:            subscription.URL,
+			Secret:         subscription.Secret,
+			Event:          delivery.event,
+			Payload:        slices.Clone(delivery.payload),
+			Attempts:       delivery.attempts,
+		})
+	}
+	return deliveries, nil
+}
+
+// delivery returns a queued delivery by its ID, or nil if there is none.
+func (s *store) delivery(deliveryID int64) *webhookDelivery {
+	for _, delivery := range s.deliveries {
+		if delivery.id == deliveryID {
+			return delivery
+		}
+	}
+	return nil
+}
+
+// MarkWebhookDelivered records a successful delivery.
+func (r *Repository) MarkWebhookDelivered(ctx context.Context, deliveryID int64) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	if delivery := s.delivery(deliveryID); delivery != nil {
+		delivery.status = domain.WebhookDeliveryDelivered
+		delivery.attempts++
+		delivery.lastError = ""
+		delivery.deliveredAt = timePtr(time.Now())
+	}
+	return nil
+}
+
+// MarkWebhookAttemptFailed records a failed delivery attempt. The delivery is retried at nextAttemptAt;
+// a nil nextAttemptAt gives the delivery up.
+func (r *Repository) MarkWebhookAttemptFailed(ctx context.Context, deliveryID int64, lastError string, nextAttemptAt *time.Time) error {
+	s := r.lock(ctx)
+	defer r.unlock()
+
+	delivery := s.delivery(deliveryID)
+	if delivery == nil {
+		return nil
+	}
+	delivery.attempts++
+	if runes := []rune(lastError); len(runes) > 500 {
+		lastError = string(runes[:500])
+	}
+	delivery.lastError = lastError
+	if nextAttemptAt == nil {
+		delivery.status = domain.WebhookDeliveryFailed
+	} else {
+		delivery.status = domain.WebhookDeliveryPending
+		delivery.nextAttemptAt = *nextAttemptAt
+	}
+	return nil
+}
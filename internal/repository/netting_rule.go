@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// nettingRuleColumns is the column list selected for a configured netting rule.
+const nettingRuleColumns = `rule_id, order_type_id, COALESCE(debtor, ''), COALESCE(creditor, ''), excluded, min_amount, max_amount,
+		to_char(valid_from, 'YYYY-MM-DD'), COALESCE(to_char(valid_to, 'YYYY-MM-DD'), ''), COALESCE(comment, ''),
+		COALESCE(created_by, ''), created_at, updated_at`
+
+// scanNettingRule scans a row selected with nettingRuleColumns into a configured netting rule.
+func scanNettingRule(row pgx.Row) (*domain.NettingRuleConfig, error) {
+	var rule domain.NettingRuleConfig
+	err := row.Scan(
+		&rule.RuleID, &rule.OrderTypeID, &rule.Debtor, &rule.Creditor, &rule.Excluded, &rule.MinAmount, &rule.MaxAmount,
+		&rule.ValidFrom, &rule.ValidTo, &rule.Comment, &rule.CreatedBy, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// ListNettingRules retrieves the configured netting rules ordered by order type and start of validity,
+// optionally only the rules of one order type.
+func (r *Repository) ListNettingRules(ctx context.Context, orderTypeID int) ([]*domain.NettingRuleConfig, error) {
+	query := `
+		SELECT ` + nettingRuleColumns + `
+		FROM netting_rules
+		WHERE $1 = 0 OR order_type_id = $1
+		ORDER BY order_type_id, valid_from`
+
+	return r.queryNettingRules(ctx, query, orderTypeID)
+}
+
+// ListEffectiveNettingRules retrieves the configured netting rules valid on the date.
+func (r *Repository) ListEffectiveNettingRules(ctx context.Context, date time.Time) ([]*domain.NettingRuleConfig, error) {
+	query := `
+		SELECT ` + nettingRuleColumns + `
+		FROM netting_rules
+		WHERE valid_from <= $1::date AND (valid_to IS NULL OR valid_to >= $1::date)
+		ORDER BY order_type_id`
+
+	return r.queryNettingRules(ctx, query, date.Format(domain.DateLayout))
+}
+
+// queryNettingRules runs a query selecting nettingRuleColumns.
+func (r *Repository) queryNettingRules(ctx context.Context, query string, args ...any) ([]*domain.NettingRuleConfig, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query netting rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*domain.NettingRuleConfig
+	for rows.Next() {
+		rule, err := scanNettingRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan netting rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating netting rules: %w", err)
+	}
+	return rules, nil
+}
+
+// CreateNettingRule stores a configured netting rule. ErrNotFound is returned for an unknown order type,
+// ErrConflict if the validity period overlaps another rule of the order type.
+func (r *Repository) CreateNettingRule(ctx context.Context, rule domain.NettingRuleConfig) (*domain.NettingRuleConfig, error) {
+	query := `
+		INSERT INTO netting_rules (order_type_id, debtor, creditor, excluded, min_amount, max_amount, valid_from, valid_to,
+			comment, created_by, created_at, updated_at)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, $7::date, NULLIF($8, '')::date, NULLIF($9, ''),
+			NULLIF($10, ''), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING ` + nettingRuleColumns
+
+	return r.saveNettingRule(ctx, rule, query,
+		rule.OrderTypeID, rule.Debtor, rule.Creditor, rule.Excluded, rule.MinAmount, rule.MaxAmount, rule.ValidFrom,
+		rule.ValidTo, rule.Comment, rule.CreatedBy)
+}
+
+// UpdateNettingRule replaces a configured netting rule. ErrNotFound is returned for an unknown rule,
+// ErrConflict if the new validity period overlaps another rule of the order type.
+func (r *Repository) UpdateNettingRule(ctx context.Context, rule domain.NettingRuleConfig) (*domain.NettingRuleConfig, error) {
+	query := `
+		UPDATE netting_rules
+		SET debtor = NULLIF($2, ''), creditor = NULLIF($3, ''), excluded = $4, min_amount = $5, max_amount = $6,
+			valid_from = $7::date, valid_to = NULLIF($8, '')::date, comment = NULLIF($9, ''), updated_at = CURRENT_TIMESTAMP
+		WHERE rule_id = $10 AND order_type_id = $1
+		RETURNING ` + nettingRuleColumns
+
+	return r.saveNettingRule(ctx, rule, query,
+		rule.OrderTypeID, rule.Debtor, rule.Creditor, rule.Excluded, rule.MinAmount, rule.MaxAmount, rule.ValidFrom,
+		rule.ValidTo, rule.Comment, rule.RuleID)
+}
+
+// saveNettingRule runs the insert or update of a rule after checking its validity period against the other
+// rules of the order type. The order type row is locked, so two overlapping rules cannot be saved concurrently.
+func (r *Repository) saveNettingRule(ctx context.Context, rule domain.NettingRuleConfig, query string, args ...any) (*domain.NettingRuleConfig, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var orderTypeID int
+	err = tx.QueryRow(ctx, `SELECT order_type_id FROM order_types WHERE order_type_id = $1 FOR UPDATE`, rule.OrderTypeID).Scan(&orderTypeID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrNotFound
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to lock order type: %w", err)
+	}
+
+	// Периоды действия правил одного типа заказа не пересекаются: на любую дату действует не более одного правила
+	var overlaps bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM netting_rules
+			WHERE order_type_id = $1 AND rule_id <> $2
+				AND daterange(valid_from, valid_to, '[]') && daterange($3::date, NULLIF($4, '')::date, '[]')
+		)`, rule.OrderTypeID, rule.RuleID, rule.ValidFrom, rule.ValidTo).Scan(&overlaps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check netting rule validity: %w", err)
+	}
+	if overlaps {
+		err = ErrConflict
+		return nil, err
+	}
+
+	saved, err := scanNettingRule(tx.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrNotFound
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save netting rule: %w", wrapConstraintError(err))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return saved, nil
+}
+
+// GetNettingRule retrieves a configured netting rule by its ID.
+func (r *Repository) GetNettingRule(ctx context.Context, ruleID int) (*domain.NettingRuleConfig, error) {
+	query := `SELECT ` + nettingRuleColumns + ` FROM netting_rules WHERE rule_id = $1`
+
+	rule, err := scanNettingRule(r.db.QueryRow(ctx, query, ruleID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get netting rule: %w", err)
+	}
+	return rule, nil
+}
+
+// DeleteNettingRule removes a configured netting rule.
+func (r *Repository) DeleteNettingRule(ctx context.Context, ruleID int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM netting_rules WHERE rule_id = $1`, ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to delete netting rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cliring/internal/domain"
+)
+
+// SaveNettingSnapshot stores the netting result of a deal as its next snapshot version unless the latest
+// snapshot was computed from the same input. It reports whether a new version was stored.
+func (r *Repository) SaveNettingSnapshot(ctx context.Context, snapshot *domain.NettingSnapshot) (bool, error) {
+	// Конфликт версий означает, что тот же результат параллельно сохранил другой запрос
+	query := `
+		WITH latest AS (
+			SELECT version, input_hash FROM netting_snapshots WHERE deal_id = $1 ORDER BY version DESC LIMIT 1
+		)
+		INSERT INTO netting_snapshots (deal_id, version, input_hash, order_ids, positions, legs, created_at)
+		SELECT $1, COALESCE((SELECT version FROM latest), 0) + 1, $2, $3, $4, $5, CURRENT_TIMESTAMP
+		WHERE NOT EXISTS (SELECT 1 FROM latest WHERE input_hash = $2)
+		ON CONFLICT (deal_id, version) DO NOTHING`
+
+	tag, err := r.db.Exec(ctx, query,
+		snapshot.DealID, snapshot.InputHash, snapshot.OrderIDs, snapshot.Positions, snapshot.Legs,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to save netting snapshot: %w", wrapConstraintError(err))
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListNettingSnapshots retrieves a page of the netting snapshots of a deal, newest first,
+// and the total number of its snapshots.
+func (r *Repository) ListNettingSnapshots(ctx context.Context, dealID, page, limit int) ([]*domain.NettingSnapshot, int, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, fmt.Errorf("invalid pagination parameters: %w", ErrInvalidInput)
+	}
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM netting_snapshots WHERE deal_id = $1`, dealID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count netting snapshots: %w", err)
+	}
+
+	query := `
+		SELECT snapshot_id, deal_id, version, input_hash, order_ids, positions, legs, created_at
+		FROM netting_snapshots
+		WHERE deal_id = $1
+		ORDER BY version DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, query, dealID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query netting snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*domain.NettingSnapshot
+	for rows.Next() {
+		var snapshot domain.NettingSnapshot
+		err := rows.Scan(
+			&snapshot.SnapshotID, &snapshot.DealID, &snapshot.Version, &snapshot.InputHash, &snapshot.OrderIDs,
+			&snapshot.Positions, &snapshot.Legs, &snapshot.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan netting snapshot: %w", err)
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating netting snapshots: %w", err)
+	}
+
+	return snapshots, total, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// obligationTriggerColumns is the column list selected for an obligation trigger.
+const obligationTriggerColumns = `trigger_id, order_type_id, event, delay_days, amount, currency_code, vat_rate, active,
+		COALESCE(comment, ''), COALESCE(created_by, ''), created_at, updated_at`
+
+// scanObligationTrigger scans a row selected with obligationTriggerColumns into an obligation trigger.
+func scanObligationTrigger(row pgx.Row) (*domain.ObligationTrigger, error) {
+	var trigger domain.ObligationTrigger
+	err := row.Scan(
+		&trigger.TriggerID, &trigger.OrderTypeID, &trigger.Event, &trigger.DelayDays, &trigger.Amount,
+		&trigger.CurrencyCode, &trigger.VATRate, &trigger.Active, &trigger.Comment, &trigger.CreatedBy,
+		&trigger.CreatedAt, &trigger.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &trigger, nil
+}
+
+// ListObligationTriggers retrieves the obligation triggers ordered by ID, optionally only the active ones.
+func (r *Repository) ListObligationTriggers(ctx context.Context, activeOnly bool) ([]*domain.ObligationTrigger, error) {
+	query := `
+		SELECT ` + obligationTriggerColumns + `
+		FROM obligation_triggers
+		WHERE NOT $1 OR active
+		ORDER BY trigger_id`
+
+	rows, err := r.db.Query(ctx, query, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query obligation triggers: %w", err)
+	}
+	defer rows.Close()
+
+	var triggers []*domain.ObligationTrigger
+	for rows.Next() {
+		trigger, err := scanObligationTrigger(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan obligation trigger: %w", err)
+		}
+		triggers = append(triggers, trigger)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating obligation triggers: %w", err)
+	}
+	return triggers, nil
+}
+
+// CreateObligationTrigger stores an obligation trigger.
+func (r *Repository) CreateObligationTrigger(ctx context.Context, trigger domain.ObligationTrigger) (*domain.ObligationTrigger, error) {
+	query := `
+		INSERT INTO obligation_triggers (order_type_id, event, delay_days, amount, currency_code, vat_rate, active,
+			comment, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING ` + obligationTriggerColumns
+
+	created, err := scanObligationTrigger(r.db.QueryRow(ctx, query,
+		trigger.OrderTypeID, trigger.Event, trigger.DelayDays, trigger.Amount, trigger.CurrencyCode, trigger.VATRate,
+		trigger.Active, trigger.Comment, trigger.CreatedBy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create obligation trigger: %w", wrapConstraintError(err))
+	}
+	return created, nil
+}
+
+// UpdateObligationTrigger replaces an obligation trigger. Orders it already generated are not changed.
+func (r *Repository) UpdateObligationTrigger(ctx context.Context, trigger domain.ObligationTrigger) (*domain.ObligationTrigger, error) {
+	query := `
+		UPDATE obligation_triggers
+		SET order_type_id = $2, event = $3, delay_days = $4, amount = $5, currency_code = $6, vat_rate = $7, active = $8,
+			comment = NULLIF($9, ''), updated_at = CURRENT_TIMESTAMP
+		WHERE trigger_id = $1
+		RETURNING ` + obligationTriggerColumns
+
+	updated, err := scanObligationTrigger(r.db.QueryRow(ctx, query,
+		trigger.TriggerID, trigger.OrderTypeID, trigger.Event, trigger.DelayDays, trigger.Amount, trigger.CurrencyCode,
+		trigger.VATRate, trigger.Active, trigger.Comment))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update obligation trigger: %w", wrapConstraintError(err))
+	}
+	return updated, nil
+}
+
+// DeleteObligationTrigger removes an obligation trigger; the orders it generated stay in their deals.
+func (r *Repository) DeleteObligationTrigger(ctx context.Context, triggerID int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM obligation_triggers WHERE trigger_id = $1`, triggerID)
+	if err != nil {
+		return fmt.Errorf("failed to delete obligation trigger: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListTriggerDeals retrieves the deals the trigger fires for at the moment and has not fired for yet.
+// The cancellation time of a deal is taken from its updated_at.
+func (r *Repository) ListTriggerDeals(ctx context.Context, trigger *domain.ObligationTrigger, at time.Time) ([]*domain.Deal, error) {
+	var condition string
+	args := []any{trigger.TriggerID, trigger.DelayDays}
+	switch trigger.Event {
+	case domain.TriggerDealCancelled:
+		condition = `d.status = $3 AND d.updated_at >= d.created_at + make_interval(days => $2)`
+		args = append(args, domain.DealStatusCancelled)
+	case domain.TriggerDealOpen:
+		condition = `d.status IN ($3, $4) AND $5 >= d.created_at + make_interval(days => $2)`
+		args = append(args, domain.DealStatusDraft, domain.DealStatusActive, at)
+	default:
+		return nil, fmt.Errorf("unknown trigger event %q: %w", trigger.Event, ErrInvalidInput)
+	}
+
+	query := `
+		SELECT ` + dealColumns + `
+		FROM deals d
+		WHERE d.deleted_at IS NULL AND ` + condition + `
+			AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.deal_id = d.deal_id AND o.trigger_id = $1)
+		ORDER BY d.deal_id`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trigger deals: %w", err)
+	}
+	defer rows.Close()
+
+	var deals []*domain.Deal
+	for rows.Next() {
+		deal, err := scanDeal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deal: %w", err)
+		}
+		deals = append(deals, deal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trigger deals: %w", err)
+	}
+	return deals, nil
+}
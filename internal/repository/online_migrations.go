@@ -0,0 +1,12 @@
+package repository
+
+import "cliring/pkg/postgres"
+
+// OnlineMigrations are the background migrations of large tables, run after the tern migrations
+// on startup. See postgres.OnlineMigration for the steps of a zero-downtime column change.
+//
+// The float→NUMERIC amount conversion needs no entry and ran no blocking migration either: the float
+// amounts were float64 fields of the domain types, replaced by domain.Money, while orders.amount,
+// monetary_settlements.amount and order_allocations.amount are numeric(15,2) since they were created,
+// so no stored column changed its type. The first column conversion of a large table is added here.
+var OnlineMigrations = []postgres.OnlineMigration{}
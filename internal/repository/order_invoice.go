@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"cliring/internal/domain"
+)
+
+// SetOrderInvoice attaches an invoice file to an order, replacing the previous one.
+// It returns the storage key of the replaced file, or an empty string if the order had no invoice.
+func (r *Repository) SetOrderInvoice(ctx context.Context, orderID int, invoice *domain.OrderInvoice) (string, error) {
+	// Прежний ключ читается под блокировкой строки, чтобы параллельная загрузка не потеряла файл
+	query := `
+		UPDATE orders o
+		SET invoice_key = $2, invoice_file_name = $3, invoice_content_type = $4, invoice_size_bytes = $5
+		FROM (SELECT order_id, invoice_key FROM orders WHERE order_id = $1 AND deleted_at IS NULL FOR UPDATE) prev
+		WHERE o.order_id = prev.order_id
+		RETURNING prev.invoice_key`
+
+	var previousKey pgtype.Text
+	err := r.db.QueryRow(ctx, query,
+		orderID, invoice.StorageKey, invoice.FileName, invoice.ContentType, invoice.SizeBytes,
+	).Scan(&previousKey)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to set order invoice: %w", wrapConstraintError(err))
+	}
+
+	return previousKey.String, nil
+}
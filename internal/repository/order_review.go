@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"cliring/internal/domain"
+)
+
+// orderReviewColumns is the column list selected for an order review.
+const orderReviewColumns = `review_id, order_id, client_id, reasons, status, created_at, decided_by, decided_at`
+
+// scanOrderReview scans a row selected with orderReviewColumns into an order review.
+func scanOrderReview(row pgx.Row) (*domain.OrderReview, error) {
+	var review domain.OrderReview
+	err := row.Scan(
+		&review.ReviewID, &review.OrderID, &review.ClientID, &review.Reasons, &review.Status, &review.CreatedAt,
+		&review.DecidedBy, &review.DecidedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// CountClientOrdersSince counts the client's orders of at least minAmount created since the given time.
+func (r *Repository) CountClientOrdersSince(ctx context.Context, clientID int, minAmount domain.Money, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM orders o
+		JOIN deals d ON o.deal_id = d.deal_id
+		WHERE d.client_id = $1 AND o.amount >= $2 AND o.created_at >= $3`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, clientID, minAmount, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count client orders: %w", err)
+	}
+	return count, nil
+}
+
+// CountClientCancellationsSince counts the client's orders cancelled or deleted since the given time.
+func (r *Repository) CountClientCancellationsSince(ctx context.Context, clientID int, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(DISTINCT o.order_id)
+		FROM orders o
+		JOIN deals d ON o.deal_id = d.deal_id
+		LEFT JOIN order_status_history h
+			ON h.order_id = o.order_id AND h.to_status = $2 AND h.changed_at >= $3
+		WHERE d.client_id = $1 AND (h.history_id IS NOT NULL OR o.deleted_at >= $3)`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, clientID, domain.StatusCancelled, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count client cancellations: %w", err)
+	}
+	return count, nil
+}
+
+// FlagOrderForReview holds an order out of netting and puts it into the review queue.
+func (r *Repository) FlagOrderForReview(ctx context.Context, orderID, clientID int, reasons []string) (*domain.OrderReview, error) {
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	query := `UPDATE orders SET review_status = $1 WHERE order_id = $2 AND deleted_at IS NULL`
+	tag, err := tx.Exec(ctx, query, domain.ReviewPending, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to flag order: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		err = ErrNotFound
+		return nil, err
+	}
+
+	query = `
+		INSERT INTO order_reviews (order_id, client_id, reasons)
+		VALUES ($1, $2, $3)
+		RETURNING ` + orderReviewColumns
+	review, err := scanOrderReview(tx.QueryRow(ctx, query, orderID, clientID, reasons))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order review: %w", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return review, nil
+}
+
+// ListOrderReviews retrieves the order reviews with the given status, oldest first.
+// An empty status lists all reviews.
+func (r *Repository) ListOrderReviews(ctx context.Context, status string) ([]*domain.OrderReview, error) {
+	query := `
+		SELECT ` + orderReviewColumns + `
+		FROM order_reviews
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at, review_id`
+
+	rows, err := r.db.Query(ctx, query, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []*domain.OrderReview
+	for rows.Next() {
+		review, err := scanOrderReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating order reviews: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// DecideOrderReview approves or rejects a pending review. An approved order enters netting and the
+// settlements of its deal are flagged for recomputation; a rejected pending order is cancelled.
+// ErrNotFound is returned for an unknown review and ErrConflict if it was already decided.
+func (r *Repository) DecideOrderReview(ctx context.Context, reviewID int, status string) (*domain.OrderReview, error) {
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var dealID int
+	query := `
+		SELECT o.deal_id
+		FROM order_reviews v
+		JOIN orders o ON v.order_id = o.order_id
+		WHERE v.review_id = $1`
+	if err = tx.QueryRow(ctx, query, reviewID).Scan(&dealID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrNotFound
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get order review: %w", err)
+	}
+	if err = lockDealsTx(ctx, tx, dealID); err != nil {
+		return nil, err
+	}
+
+	query = `
+		UPDATE order_reviews
+		SET status = $1, decided_by = $2, decided_at = CURRENT_TIMESTAMP
+		WHERE review_id = $3 AND status = $4
+		RETURNING ` + orderReviewColumns
+	review, err := scanOrderReview(tx.QueryRow(ctx, query, status, actorFromContext(ctx), reviewID, domain.ReviewPending))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrConflict
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update order review: %w", err)
+	}
+
+	query = `UPDATE orders SET review_status = $1, updated_at = CURRENT_TIMESTAMP WHERE order_id = $2`
+	if _, err = tx.Exec(ctx, query, status, review.OrderID); err != nil {
+		return nil, fmt.Errorf("failed to update order review status: %w", err)
+	}
+
+	switch status {
+	case domain.ReviewApproved:
+		query = `UPDATE deals SET settlements_recompute_required = true, updated_at = CURRENT_TIMESTAMP WHERE deal_id = $1`
+		if _, err = tx.Exec(ctx, query, dealID); err != nil {
+			return nil, fmt.Errorf("failed to flag deal settlements: %w", err)
+		}
+	case domain.ReviewRejected:
+		query = `
+			UPDATE orders SET status = $1, updated_at = CURRENT_TIMESTAMP
+			WHERE order_id = $2 AND status = $3 AND deleted_at IS NULL`
+		var tag pgconn.CommandTag
+		tag, err = tx.Exec(ctx, query, domain.StatusCancelled, review.OrderID, domain.StatusPending)
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel rejected order: %w", err)
+		}
+		if tag.RowsAffected() > 0 {
+			// Ревизия пишется после отмены, поэтому фиксирует статус до неё явно
+			query = `
+				INSERT INTO order_revisions (order_id, action, deal_id, order_type_id, amount, status, changed_by)
+				SELECT order_id, $2, deal_id, order_type_id, amount, $3, $4
+				FROM orders
+				WHERE order_id = $1`
+			_, err = tx.Exec(ctx, query, review.OrderID, domain.OrderRevisionStatusChanged, domain.StatusPending,
+				actorFromContext(ctx))
+			if err != nil {
+				return nil, fmt.Errorf("failed to record order revision: %w", err)
+			}
+
+			query = `
+				INSERT INTO order_status_history (order_id, from_status, to_status, changed_by)
+				VALUES ($1, $2, $3, $4)`
+			_, err = tx.Exec(ctx, query, review.OrderID, domain.StatusPending, domain.StatusCancelled, actorFromContext(ctx))
+			if err != nil {
+				return nil, fmt.Errorf("failed to record order status history: %w", err)
+			}
+		}
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return review, nil
+}
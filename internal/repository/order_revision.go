@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"cliring/internal/domain"
+)
+
+// insertOrderRevisionTx stores the current values of an order before it is changed by action.
+// It must run in the transaction of the change, before the change itself.
+func insertOrderRevisionTx(ctx context.Context, tx pgx.Tx, orderID int, action string) error {
+	query := `
+		INSERT INTO order_revisions (order_id, action, deal_id, order_type_id, amount, status, changed_by)
+		SELECT order_id, $2, deal_id, order_type_id, amount, status, $3
+		FROM orders
+		WHERE order_id = $1`
+	if _, err := tx.Exec(ctx, query, orderID, action, actorFromContext(ctx)); err != nil {
+		return fmt.Errorf("failed to record order revision: %w", err)
+	}
+	return nil
+}
+
+// ListClientOrderRevisions retrieves the revisions of a client's order, oldest first.
+// Deleted orders keep their history, so ownership is checked without the deleted_at filter.
+func (r *Repository) ListClientOrderRevisions(ctx context.Context, clientID, orderID int) ([]*domain.OrderRevision, error) {
+	query := `
+		SELECT d.client_id
+		FROM orders o
+		JOIN deals d ON o.deal_id = d.deal_id
+		WHERE o.order_id = $1`
+
+	var ownerID pgtype.Int4
+	if err := r.db.QueryRow(ctx, query, orderID).Scan(&ownerID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get order owner: %w", err)
+	}
+	if !ownerID.Valid || int(ownerID.Int32) != clientID {
+		return nil, ErrUnauthorized
+	}
+
+	query = `
+		SELECT revision_id, order_id, action, deal_id, order_type_id, amount, status, changed_by, changed_at
+		FROM order_revisions
+		WHERE order_id = $1
+		ORDER BY changed_at, revision_id`
+
+	rows, err := r.db.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*domain.OrderRevision
+	for rows.Next() {
+		var revision domain.OrderRevision
+		err := rows.Scan(
+			&revision.RevisionID, &revision.OrderID, &revision.Action, &revision.DealID, &revision.OrderTypeID,
+			&revision.Amount, &revision.Status, &revision.ChangedBy, &revision.ChangedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order revision: %w", err)
+		}
+		revisions = append(revisions, &revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating order revisions: %w", err)
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"fmt"
+
+	"cliring/internal/domain"
+)
+
+// orderSortColumns maps the sortable order fields to columns of the orders table (alias "o").
+var orderSortColumns = map[string]string{
+	domain.OrderSortAmount:    "o.amount",
+	domain.OrderSortCreatedAt: "o.created_at",
+	domain.OrderSortStatus:    "o.status",
+}
+
+// orderSnapshotSortColumns maps the sortable order fields to fields of order snapshots (alias "o").
+var orderSnapshotSortColumns = map[string]string{
+	domain.OrderSortAmount:    "(o.data ->> 'amount')::numeric",
+	domain.OrderSortCreatedAt: "(o.data ->> 'created_at')::timestamptz",
+	domain.OrderSortStatus:    "o.data ->> 'status'",
+}
+
+// orderByClause translates an order sort into an ORDER BY expression using the given column map.
+// Only whitelisted fields and directions are accepted, so the result is safe to put into SQL.
+// An empty sort selects the newest orders first; idColumn breaks ties.
+func orderByClause(sort domain.OrderSort, columns map[string]string, idColumn string) (string, error) {
+	field, direction := sort.Field, sort.Direction
+	if field == "" {
+		field = domain.OrderSortCreatedAt
+	}
+	if direction == "" {
+		direction = domain.SortDesc
+	}
+
+	column, ok := columns[field]
+	if !ok {
+		return "", fmt.Errorf("unknown sort field %q: %w", field, ErrInvalidInput)
+	}
+	var keyword string
+	switch direction {
+	case domain.SortAsc:
+		keyword = "ASC"
+	case domain.SortDesc:
+		keyword = "DESC"
+	default:
+		return "", fmt.Errorf("unknown sort direction %q: %w", direction, ErrInvalidInput)
+	}
+
+	return column + " " + keyword + ", " + idColumn + " " + keyword, nil
+}
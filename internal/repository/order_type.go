@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"cliring/internal/domain"
+)
+
+// orderTypeColumns is the column list selected for an order type.
+const orderTypeColumns = `order_type_id, name, debtor, creditor, kind`
+
+// scanOrderType scans a row selected with orderTypeColumns into an order type.
+func scanOrderType(row pgx.Row) (*domain.OrderType, error) {
+	var orderType domain.OrderType
+	if err := row.Scan(&orderType.OrderTypeID, &orderType.Name, &orderType.Debtor, &orderType.Creditor, &orderType.Kind); err != nil {
+		return nil, err
+	}
+	return &orderType, nil
+}
+
+// ListOrderTypes retrieves the order types catalog.
+func (r *Repository) ListOrderTypes(ctx context.Context) ([]*domain.OrderType, error) {
+	query := `
+		SELECT ` + orderTypeColumns + `
+		FROM order_types
+		ORDER BY order_type_id`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order types: %w", err)
+	}
+	defer rows.Close()
+
+	var orderTypes []*domain.OrderType
+	for rows.Next() {
+		orderType, err := scanOrderType(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order type: %w", err)
+		}
+		orderTypes = append(orderTypes, orderType)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating order types: %w", err)
+	}
+
+	return orderTypes, nil
+}
+
+// CreateOrderType adds an order type. ErrConflict is returned if the order_type_id already exists.
+func (r *Repository) CreateOrderType(ctx context.Context, orderType domain.OrderType) (*domain.OrderType, error) {
+	query := `
+		INSERT INTO order_types (order_type_id, name, debtor, creditor, kind)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (order_type_id) DO NOTHING
+		RETURNING ` + orderTypeColumns
+
+	created, err := scanOrderType(r.db.QueryRow(ctx, query,
+		orderType.OrderTypeID, orderType.Name, orderType.Debtor, orderType.Creditor, orderType.Kind))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to create order type: %w", wrapConstraintError(err))
+	}
+
+	return created, nil
+}
+
+// UpdateOrderType updates the name, the netting rule and the kind of an order type.
+func (r *Repository) UpdateOrderType(ctx context.Context, orderType domain.OrderType) (*domain.OrderType, error) {
+	query := `
+		UPDATE order_types
+		SET name = $2, debtor = $3, creditor = $4, kind = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE order_type_id = $1
+		RETURNING ` + orderTypeColumns
+
+	updated, err := scanOrderType(r.db.QueryRow(ctx, query,
+		orderType.OrderTypeID, orderType.Name, orderType.Debtor, orderType.Creditor, orderType.Kind))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update order type: %w", wrapConstraintError(err))
+	}
+
+	return updated, nil
+}
+
+// DeleteOrderType removes an order type. ErrConflict is returned if orders still reference the type.
+func (r *Repository) DeleteOrderType(ctx context.Context, orderTypeID int) error {
+	query := `DELETE FROM order_types WHERE order_type_id = $1`
+
+	result, err := r.db.Exec(ctx, query, orderTypeID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to delete order type: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cliring/internal/domain"
+)
+
+// ParticipantBalances aggregates the stored settlements of a participant across all deals by currency.
+// The client of a deal settlement is resolved through the deal.
+func (r *Repository) ParticipantBalances(ctx context.Context, participant domain.Participant) ([]*domain.ParticipantCurrencyBalance, error) {
+	query := `
+		WITH settlements AS (
+			SELECT ms.deal_id, ms.status, ms.currency_code, ms.amount,
+				COALESCE((SELECT SUM(p.amount) FROM settlement_payments p
+					WHERE p.monetary_settlement_id = ms.monetary_settlement_id), 0) AS paid
+			FROM monetary_settlements ms
+			JOIN deals d ON d.deal_id = ms.deal_id
+			WHERE ms.deleted_at IS NULL AND ms.status <> $1 AND ms.participant = $2
+				AND CASE ms.participant
+					WHEN 'client' THEN d.client_id
+					WHEN 'bank' THEN ms.bank_id
+					WHEN 'dealership' THEN ms.dealership_id
+					WHEN 'insurer' THEN ms.insurer_id
+				END = $3
+		)
+		SELECT currency_code,
+			COALESCE(SUM(amount - paid) FILTER (WHERE status = $4), 0),
+			COALESCE(SUM(amount) FILTER (WHERE status = $5), 0) + COALESCE(SUM(paid) FILTER (WHERE status = $4), 0),
+			COUNT(*) FILTER (WHERE status = $4),
+			COUNT(DISTINCT deal_id)
+		FROM settlements
+		GROUP BY currency_code
+		ORDER BY currency_code`
+
+	rows, err := r.db.Query(ctx, query,
+		domain.StatusCancelled, participant.Role, participant.ExternalID, domain.StatusPending, domain.StatusExecuted,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query participant balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []*domain.ParticipantCurrencyBalance
+	for rows.Next() {
+		var balance domain.ParticipantCurrencyBalance
+		err := rows.Scan(&balance.CurrencyCode, &balance.Outstanding, &balance.Settled,
+			&balance.PendingSettlements, &balance.Deals)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan participant balance: %w", err)
+		}
+		balances = append(balances, &balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating participant balances: %w", err)
+	}
+
+	return balances, nil
+}
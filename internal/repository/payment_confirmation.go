@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"cliring/internal/domain"
+)
+
+const paymentConfirmationColumns = `confirmation_id, source, external_id, reference_number, payment_reference, amount,
+		currency_code, status, outcome, COALESCE(reason, ''), monetary_settlement_id, received_at, processed_at`
+
+// scanPaymentConfirmation scans a row selected with paymentConfirmationColumns into a payment confirmation.
+func scanPaymentConfirmation(row pgx.Row) (*domain.PaymentConfirmation, error) {
+	var confirmation domain.PaymentConfirmation
+	var settlementID pgtype.Int4
+	var processedAt pgtype.Timestamptz
+	err := row.Scan(
+		&confirmation.ConfirmationID, &confirmation.Source, &confirmation.ExternalID, &confirmation.ReferenceNumber,
+		&confirmation.PaymentReference, &confirmation.Amount, &confirmation.CurrencyCode, &confirmation.Status,
+		&confirmation.Outcome, &confirmation.Reason, &settlementID, &confirmation.ReceivedAt, &processedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if settlementID.Valid {
+		settlementIDInt := int(settlementID.Int32)
+		confirmation.MonetarySettlementID = &settlementIDInt
+	}
+	if processedAt.Valid {
+		confirmation.ProcessedAt = &processedAt.Time
+	}
+	return &confirmation, nil
+}
+
+// CreatePaymentConfirmation stores a received payment confirmation. It reports false without storing
+// the confirmation if the source sent a confirmation with the same external ID before.
+func (r *Repository) CreatePaymentConfirmation(ctx context.Context, confirmation *domain.PaymentConfirmation) (*domain.PaymentConfirmation, bool, error) {
+	query := `
+		INSERT INTO payment_confirmations (source, external_id, reference_number, payment_reference, amount,
+			currency_code, status, outcome, received_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP)
+		ON CONFLICT (source, external_id) DO NOTHING
+		RETURNING ` + paymentConfirmationColumns
+
+	created, err := scanPaymentConfirmation(r.db.QueryRow(ctx, query,
+		confirmation.Source, confirmation.ExternalID, confirmation.ReferenceNumber, confirmation.PaymentReference,
+		confirmation.Amount, confirmation.CurrencyCode, confirmation.Status, domain.ConfirmationReceived,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to create payment confirmation: %w", wrapConstraintError(err))
+	}
+	return created, true, nil
+}
+
+// GetPaymentConfirmation retrieves the confirmation a source sent with the external ID.
+func (r *Repository) GetPaymentConfirmation(ctx context.Context, source, externalID string) (*domain.PaymentConfirmation, error) {
+	query := `SELECT ` + paymentConfirmationColumns + ` FROM payment_confirmations WHERE source = $1 AND external_id = $2`
+
+	confirmation, err := scanPaymentConfirmation(r.db.QueryRow(ctx, query, source, externalID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get payment confirmation: %w", err)
+	}
+	return confirmation, nil
+}
+
+// CompletePaymentConfirmation records the processing outcome of a confirmation and the settlement it was linked to.
+func (r *Repository) CompletePaymentConfirmation(ctx context.Context, confirmationID int, outcome string, settlementID *int,
+	reason string) (*domain.PaymentConfirmation, error) {
+	query := `
+		UPDATE payment_confirmations
+		SET outcome = $1, monetary_settlement_id = $2, reason = NULLIF($3, ''), processed_at = CURRENT_TIMESTAMP
+		WHERE confirmation_id = $4
+		RETURNING ` + paymentConfirmationColumns
+
+	confirmation, err := scanPaymentConfirmation(r.db.QueryRow(ctx, query, outcome, settlementID, reason, confirmationID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to complete payment confirmation: %w", wrapConstraintError(err))
+	}
+	return confirmation, nil
+}
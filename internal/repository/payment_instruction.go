@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// GetPaymentDetails retrieves the bank details of a participant account or of the clearing account.
+func (r *Repository) GetPaymentDetails(ctx context.Context, account string) (*domain.PaymentDetails, error) {
+	query := `
+		SELECT account, name, COALESCE(inn, ''), COALESCE(kpp, ''), COALESCE(bank_name, ''), COALESCE(bic, ''),
+			COALESCE(bank_account, ''), COALESCE(correspondent_account, '')
+		FROM payment_details
+		WHERE account = $1`
+
+	var details domain.PaymentDetails
+	err := r.db.QueryRow(ctx, query, account).Scan(
+		&details.Account, &details.Name, &details.INN, &details.KPP, &details.BankName, &details.BIC,
+		&details.BankAccount, &details.CorrespondentAccount,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get payment details: %w", err)
+	}
+	return &details, nil
+}
+
+// AllocatedVAT returns the VAT part of a settlement: every allocation carries the VAT share of its order.
+func (r *Repository) AllocatedVAT(ctx context.Context, settlementID int) (domain.Money, error) {
+	// У заказов, созданных до введения НДС, vat_amount и gross_amount не заполнены
+	query := `
+		SELECT COALESCE(SUM(ROUND(a.amount * COALESCE(o.vat_amount, 0) / COALESCE(o.gross_amount, o.amount), 2)), 0)::numeric(15, 2)
+		FROM order_allocations a
+		JOIN orders o ON o.order_id = a.order_id
+		WHERE a.monetary_settlement_id = $1`
+
+	var vat domain.Money
+	if err := r.db.QueryRow(ctx, query, settlementID).Scan(&vat); err != nil {
+		return 0, fmt.Errorf("failed to sum allocated VAT: %w", err)
+	}
+	return vat, nil
+}
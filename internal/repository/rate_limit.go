@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// HitRateLimit counts a request with the key in the window starting at windowStart and returns the number
+// of requests in that window. A key keeps a single row that is reset when a new window starts.
+func (r *Repository) HitRateLimit(ctx context.Context, key string, windowStart time.Time) (int, error) {
+	query := `
+		INSERT INTO rate_limits (key, window_start, hits)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (key) DO UPDATE SET
+			hits = CASE WHEN rate_limits.window_start = EXCLUDED.window_start THEN rate_limits.hits + 1 ELSE 1 END,
+			window_start = EXCLUDED.window_start
+		RETURNING hits`
+
+	var hits int
+	if err := r.db.QueryRow(ctx, query, key, windowStart).Scan(&hits); err != nil {
+		return 0, fmt.Errorf("failed to count rate limit hit: %w", err)
+	}
+	return hits, nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -18,6 +19,7 @@ var (
 	ErrInvalidInput = errors.New("invalid input")
 	ErrNotFound     = errors.New("resource not found")
 	ErrUnauthorized = errors.New("unauthorized access")
+	ErrConflict     = errors.New("conflict")
 )
 
 // Repository handles database operations for the Cliring API.
@@ -30,39 +32,73 @@ func NewRepository(db *postgres.Postgres) *Repository {
 	return &Repository{db: db}
 }
 
+// dealColumns is the column list selected for a deal.
+const dealColumns = `deal_id, status, created_at, updated_at, dealership_id, manager_id, client_id,
+		deletion_scheduled_at, settlements_recompute_required`
+
+// scanDeal scans a row selected with dealColumns into a deal.
+func scanDeal(row pgx.Row) (*domain.Deal, error) {
+	var deal domain.Deal
+	var deletionScheduledAt pgtype.Timestamptz
+	err := row.Scan(
+		&deal.DealID, &deal.Status, &deal.CreatedAt, &deal.UpdatedAt,
+		&deal.DealershipID, &deal.ManagerID, &deal.ClientID, &deletionScheduledAt,
+		&deal.SettlementsRecomputeRequired,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if deletionScheduledAt.Valid {
+		deal.DeletionScheduledAt = &deletionScheduledAt.Time
+	}
+	return &deal, nil
+}
+
 // CreateDeal creates a new deal in the database.
 func (r *Repository) CreateDeal(ctx context.Context, req domain.Deal) (*domain.Deal, error) {
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
 	query := `
-		INSERT INTO deals (deal_id, dealership_id, manager_id, client_id)
-		VALUES ($1, $2, $3, $4)
-		RETURNING deal_id, is_completed, created_at, updated_at, dealership_id, manager_id, client_id`
+		INSERT INTO deals (deal_id, dealership_id, manager_id, client_id, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + dealColumns
 
-	var deal domain.Deal
-	err := r.db.Conn.QueryRow(ctx, query,
-		req.DealID, req.DealershipID, req.ManagerID, req.ClientID,
-	).Scan(
-		&deal.DealID, &deal.IsCompleted, &deal.CreatedAt, &deal.UpdatedAt,
-		&deal.DealershipID, &deal.ManagerID, &deal.ClientID,
-	)
+	deal, err := scanDeal(tx.QueryRow(ctx, query,
+		req.DealID, req.DealershipID, req.ManagerID, req.ClientID, req.Status,
+	))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create deal: %w", err)
+		return nil, fmt.Errorf("failed to create deal: %w", wrapConstraintError(err))
 	}
 
-	return &deal, nil
+	if err = r.insertDealHistory(ctx, tx, domain.DealActionCreated, nil, deal); err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deal, nil
 }
 
 // GetDeal retrieves a deal by its ID.
 func (r *Repository) GetDeal(ctx context.Context, dealID int) (*domain.Deal, error) {
 	query := `
-		SELECT deal_id, is_completed, created_at, updated_at, dealership_id, manager_id, client_id
+		SELECT ` + dealColumns + `
 		FROM deals
-		WHERE deal_id = $1`
+		WHERE deal_id = $1 AND deleted_at IS NULL`
 
-	var deal domain.Deal
-	err := r.db.Conn.QueryRow(ctx, query, dealID).Scan(
-		&deal.DealID, &deal.IsCompleted, &deal.CreatedAt, &deal.UpdatedAt,
-		&deal.DealershipID, &deal.ManagerID, &deal.ClientID,
-	)
+	deal, err := scanDeal(r.db.QueryRow(ctx, query, dealID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -70,13 +106,135 @@ func (r *Repository) GetDeal(ctx context.Context, dealID int) (*domain.Deal, err
 		return nil, fmt.Errorf("failed to get deal: %w", err)
 	}
 
-	return &deal, nil
+	return deal, nil
+}
+
+// getDealForUpdate retrieves and locks a deal inside a transaction.
+func (r *Repository) getDealForUpdate(ctx context.Context, tx pgx.Tx, dealID int) (*domain.Deal, error) {
+	query := `
+		SELECT ` + dealColumns + `
+		FROM deals
+		WHERE deal_id = $1 AND deleted_at IS NULL
+		FOR UPDATE`
+
+	deal, err := scanDeal(tx.QueryRow(ctx, query, dealID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+
+	return deal, nil
+}
+
+// UpdateDeal applies a partial update to a deal. Only the fields set in req are changed.
+func (r *Repository) UpdateDeal(ctx context.Context, dealID int, req domain.DealUpdate) (*domain.Deal, error) {
+	setClauses := []string{"updated_at = CURRENT_TIMESTAMP"}
+	args := []any{}
+
+	if req.DealershipID != nil {
+		args = append(args, *req.DealershipID)
+		setClauses = append(setClauses, fmt.Sprintf("dealership_id = $%d", len(args)))
+	}
+	if req.ManagerID != nil {
+		args = append(args, *req.ManagerID)
+		setClauses = append(setClauses, fmt.Sprintf("manager_id = $%d", len(args)))
+	}
+
+	args = append(args, dealID)
+	query := fmt.Sprintf(`
+		UPDATE deals
+		SET %s
+		WHERE deal_id = $%d AND deleted_at IS NULL
+		RETURNING `+dealColumns,
+		strings.Join(setClauses, ", "), len(args))
+
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	oldDeal, err := r.getDealForUpdate(ctx, tx, dealID)
+	if err != nil {
+		return nil, err
+	}
+
+	deal, err := scanDeal(tx.QueryRow(ctx, query, args...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update deal: %w", wrapConstraintError(err))
+	}
+
+	if err = r.insertDealHistory(ctx, tx, domain.DealActionUpdated, oldDeal, deal); err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deal, nil
+}
+
+// UpdateDealStatus moves a deal from one status to another. The update only succeeds if the deal
+// is still in the from status, otherwise ErrConflict is returned.
+func (r *Repository) UpdateDealStatus(ctx context.Context, dealID int, from, to string) (*domain.Deal, error) {
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	oldDeal, err := r.getDealForUpdate(ctx, tx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	if oldDeal.Status != from {
+		err = ErrConflict
+		return nil, err
+	}
+
+	query := `
+		UPDATE deals
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE deal_id = $2
+		RETURNING ` + dealColumns
+
+	deal, err := scanDeal(tx.QueryRow(ctx, query, to, dealID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update deal status: %w", wrapConstraintError(err))
+	}
+
+	if err = r.insertDealHistory(ctx, tx, domain.DealActionStatusChanged, oldDeal, deal); err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deal, nil
 }
 
-// DeleteDeal deletes a deal by its ID along with related orders and monetary settlements.
+// DeleteDeal soft-deletes a deal by its ID along with related orders and monetary settlements.
+// The deal must be scheduled for deletion with an expired grace period, otherwise ErrConflict is returned.
+// Soft-deleted rows are hard-deleted or anonymized later by the retention purger.
 func (r *Repository) DeleteDeal(ctx context.Context, dealID int) error {
 	// Begin transaction
-	tx, err := r.db.Conn.Begin(ctx)
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -86,44 +244,48 @@ func (r *Repository) DeleteDeal(ctx context.Context, dealID int) error {
 		}
 	}()
 
-	// Verify deal exists
-	query := `SELECT deal_id FROM deals WHERE deal_id = $1`
-	var id int
-	err = tx.QueryRow(ctx, query, dealID).Scan(&id)
+	oldDeal, err := r.getDealForUpdate(ctx, tx, dealID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return ErrNotFound
-		}
-		return fmt.Errorf("failed to verify deal: %w", err)
+		return err
+	}
+	// Удаление могли отменить после выбора сделки фоновым заданием
+	if oldDeal.DeletionScheduledAt == nil || oldDeal.DeletionScheduledAt.After(time.Now()) {
+		err = ErrConflict
+		return err
+	}
+
+	// Delete deal
+	query := `UPDATE deals SET deleted_at = CURRENT_TIMESTAMP WHERE deal_id = $1`
+	_, err = tx.Exec(ctx, query, dealID)
+	if err != nil {
+		return fmt.Errorf("failed to delete deal: %w", err)
 	}
 
 	// Delete related orders
-	query = `DELETE FROM orders WHERE deal_id = $1`
+	query = `UPDATE orders SET deleted_at = CURRENT_TIMESTAMP WHERE deal_id = $1 AND deleted_at IS NULL`
 	_, err = tx.Exec(ctx, query, dealID)
 	if err != nil {
 		return fmt.Errorf("failed to delete orders: %w", err)
 	}
 
 	// Delete related monetary settlements
-	query = `DELETE FROM monetary_settlements WHERE deal_id = $1`
+	query = `UPDATE monetary_settlements SET deleted_at = CURRENT_TIMESTAMP WHERE deal_id = $1 AND deleted_at IS NULL`
 	_, err = tx.Exec(ctx, query, dealID)
 	if err != nil {
 		return fmt.Errorf("failed to delete monetary settlements: %w", err)
 	}
 
-	// Delete deal
-	query = `DELETE FROM deals WHERE deal_id = $1`
-	result, err := tx.Exec(ctx, query, dealID)
-	if err != nil {
-		return fmt.Errorf("failed to delete deal: %w", err)
+	if err = r.insertDealHistory(ctx, tx, domain.DealActionDeleted, oldDeal, nil); err != nil {
+		return err
 	}
 
-	rowsAffected := result.RowsAffected()
+	// Событие удаления в ленте изменений для внешних потребителей
+	query = `
+		INSERT INTO change_events (entity, entity_id, action, data)
+		SELECT 'deals', deal_id, 'delete', to_jsonb(d) FROM deals d WHERE deal_id = $1`
+	_, err = tx.Exec(ctx, query, dealID)
 	if err != nil {
-		return fmt.Errorf("failed to check rows affected: %w", err)
-	}
-	if rowsAffected == 0 {
-		return ErrNotFound
+		return fmt.Errorf("failed to record deal deletion event: %w", err)
 	}
 
 	// Commit transaction
@@ -134,31 +296,163 @@ func (r *Repository) DeleteDeal(ctx context.Context, dealID int) error {
 	return nil
 }
 
-// ListOrders retrieves a paginated list of orders for a client.
-func (r *Repository) ListOrders(ctx context.Context, clientID int) ([]*domain.Order, int, error) {
+// orderColumns is the column list selected for an order (table alias "o"),
+// including the amount already covered by executed settlements.
+const orderColumns = `o.order_id, o.deal_id, o.order_type_id, o.amount, o.status, o.created_at, o.updated_at,
+		o.need_and_orders_id, o.bank_id, o.currency_code, o.dealership_id, o.vehicle_id, COALESCE(o.order_number, 0),
+		o.discount, o.vat_rate, COALESCE(o.net_amount, o.amount), COALESCE(o.vat_amount, 0), COALESCE(o.gross_amount, o.amount),
+		o.execute_at, o.insurer_id,
+		COALESCE(o.review_status, ''), o.invoice_key, o.invoice_file_name, o.invoice_content_type, o.invoice_size_bytes,
+		(SELECT COALESCE(SUM(a.amount), 0) FROM order_allocations a WHERE a.order_id = o.order_id), o.clearing_session_id,
+		o.trigger_id, o.credit_contract_id, o.appraisal_id`
+
+// scanOrder scans a row selected with orderColumns into an order.
+func scanOrder(row pgx.Row) (*domain.Order, error) {
+	var order domain.Order
+	var needAndOrdersID, bankID, dealershipID, vehicleID, insurerID, sessionID, triggerID, creditContractID, appraisalID pgtype.Int4
+	var invoiceKey, invoiceFileName, invoiceContentType pgtype.Text
+	var invoiceSize pgtype.Int8
+	var executeAt pgtype.Timestamptz
+	err := row.Scan(
+		&order.OrderID, &order.DealID, &order.OrderTypeID, &order.Amount, &order.Status,
+		&order.CreatedAt, &order.UpdatedAt, &needAndOrdersID, &bankID, &order.CurrencyCode, &dealershipID,
+		&vehicleID, &order.OrderNumber, &order.Discount, &order.VATRate, &order.NetAmount, &order.VATAmount,
+		&order.GrossAmount, &executeAt, &insurerID, &order.ReviewStatus, &invoiceKey, &invoiceFileName, &invoiceContentType,
+		&invoiceSize, &order.PaidAmount, &sessionID, &triggerID, &creditContractID, &appraisalID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if needAndOrdersID.Valid {
+		needAndOrdersIDInt := int(needAndOrdersID.Int32)
+		order.NeedAndOrdersID = &needAndOrdersIDInt
+	}
+	if bankID.Valid {
+		bankIDInt := int(bankID.Int32)
+		order.BankID = &bankIDInt
+	}
+	if dealershipID.Valid {
+		dealershipIDInt := int(dealershipID.Int32)
+		order.DealershipID = &dealershipIDInt
+	}
+	if vehicleID.Valid {
+		vehicleIDInt := int(vehicleID.Int32)
+		order.VehicleID = &vehicleIDInt
+	}
+	if executeAt.Valid {
+		order.ExecuteAt = &executeAt.Time
+	}
+	if insurerID.Valid {
+		insurerIDInt := int(insurerID.Int32)
+		order.InsurerID = &insurerIDInt
+	}
+	if invoiceKey.Valid {
+		order.Invoice = &domain.OrderInvoice{
+			FileName:    invoiceFileName.String,
+			ContentType: invoiceContentType.String,
+			SizeBytes:   invoiceSize.Int64,
+			StorageKey:  invoiceKey.String,
+			DownloadURL: domain.OrderInvoiceURL(order.OrderID),
+		}
+	}
+	if sessionID.Valid {
+		sessionIDInt := int(sessionID.Int32)
+		order.ClearingSessionID = &sessionIDInt
+	}
+	if triggerID.Valid {
+		triggerIDInt := int(triggerID.Int32)
+		order.TriggerID = &triggerIDInt
+	}
+	if creditContractID.Valid {
+		creditContractIDInt := int(creditContractID.Int32)
+		order.CreditContractID = &creditContractIDInt
+	}
+	if appraisalID.Valid {
+		appraisalIDInt := int(appraisalID.Int32)
+		order.AppraisalID = &appraisalIDInt
+	}
+	order.OutstandingAmount = order.GrossAmount - order.PaidAmount
+
+	return &order, nil
+}
+
+// orderFilterClauses builds the WHERE conditions of an order list (table alias "o") for the filter.
+// Arguments are appended to args, whose placeholders the conditions reference.
+func orderFilterClauses(filter domain.OrderFilter, args []any) ([]string, []any) {
+	var clauses []string
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		clauses = append(clauses, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Status != nil {
+		add("o.status = $%d", *filter.Status)
+	}
+	if filter.OrderTypeID != nil {
+		add("o.order_type_id = $%d", *filter.OrderTypeID)
+	}
+	if filter.DealID != nil {
+		add("o.deal_id = $%d", *filter.DealID)
+	}
+	if filter.BankID != nil {
+		add("o.bank_id = $%d", *filter.BankID)
+	}
+	if filter.AmountMin != nil {
+		add("o.amount >= $%d", *filter.AmountMin)
+	}
+	if filter.AmountMax != nil {
+		add("o.amount <= $%d", *filter.AmountMax)
+	}
+	if filter.CreatedFrom != nil {
+		add("o.created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		add("o.created_at < $%d", *filter.CreatedTo)
+	}
+
+	return clauses, args
+}
+
+// ListOrders retrieves a page of orders for a client matching the filter together with the total
+// number of matching orders, sorted by the whitelisted sort field.
+func (r *Repository) ListOrders(ctx context.Context, clientID int, filter domain.OrderFilter, sort domain.OrderSort, page, limit int) ([]*domain.Order, int, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, fmt.Errorf("invalid pagination parameters: %w", ErrInvalidInput)
+	}
+	orderBy, err := orderByClause(sort, orderSortColumns, "o.order_id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	clauses, args := orderFilterClauses(filter, []any{clientID})
+	where := strings.Join(append([]string{
+		"d.client_id = $1", "o.deleted_at IS NULL", "d.deleted_at IS NULL",
+	}, clauses...), " AND ")
+
 	// Count total orders
 	countQuery := `
 		SELECT COUNT(o.order_id)
 		FROM orders o
 		JOIN deals d ON o.deal_id = d.deal_id
-		WHERE d.client_id = $1`
+		WHERE ` + where
 
 	var total int
-	err := r.db.Conn.QueryRow(ctx, countQuery, clientID).Scan(&total)
+	err = r.db.QueryRow(ctx, countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count orders: %w", err)
 	}
 
 	// Retrieve orders
-	query := `
-		SELECT o.order_id, o.deal_id, o.order_type_id, o.amount, o.status, o.created_at, o.updated_at, 
-			o.need_and_orders_id, o.bank_id
+	query := fmt.Sprintf(`
+		SELECT `+orderColumns+`
 		FROM orders o
 		JOIN deals d ON o.deal_id = d.deal_id
-		WHERE d.client_id = $1
-		ORDER BY o.created_at DESC`
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d`, where, orderBy, len(args)+1, len(args)+2)
 
-	rows, err := r.db.Conn.Query(ctx, query, clientID)
+	rows, err := r.db.Query(ctx, query, append(args, limit, (page-1)*limit)...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query orders: %w", err)
 	}
@@ -166,24 +460,11 @@ func (r *Repository) ListOrders(ctx context.Context, clientID int) ([]*domain.Or
 
 	var orders []*domain.Order
 	for rows.Next() {
-		var order domain.Order
-		var needAndOrdersID, bankID pgtype.Int4
-		err := rows.Scan(
-			&order.OrderID, &order.DealID, &order.OrderTypeID, &order.Amount, &order.Status,
-			&order.CreatedAt, &order.UpdatedAt, &needAndOrdersID, &bankID,
-		)
+		order, err := scanOrder(rows)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan order: %w", err)
 		}
-		if needAndOrdersID.Valid {
-			needAndOrdersIDInt := int(needAndOrdersID.Int32)
-			order.NeedAndOrdersID = &needAndOrdersIDInt
-		}
-		if bankID.Valid {
-			bankIDInt := int(bankID.Int32)
-			order.BankID = &bankIDInt
-		}
-		orders = append(orders, &order)
+		orders = append(orders, order)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -196,12 +477,12 @@ func (r *Repository) ListOrders(ctx context.Context, clientID int) ([]*domain.Or
 // ListOrdersByDeals retrieves all orders for a specific deal.
 func (r *Repository) ListOrdersByDeals(ctx context.Context, dealID int) ([]*domain.Order, error) {
 	query := `
-		SELECT order_id, deal_id, order_type_id, amount, status, created_at, updated_at, need_and_orders_id, bank_id
-		FROM orders
-		WHERE deal_id = $1
-		ORDER BY created_at DESC`
+		SELECT ` + orderColumns + `
+		FROM orders o
+		WHERE o.deal_id = $1 AND o.deleted_at IS NULL
+		ORDER BY o.created_at DESC`
 
-	rows, err := r.db.Conn.Query(ctx, query, dealID)
+	rows, err := r.db.Query(ctx, query, dealID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query orders: %w", err)
 	}
@@ -209,24 +490,11 @@ func (r *Repository) ListOrdersByDeals(ctx context.Context, dealID int) ([]*doma
 
 	var orders []*domain.Order
 	for rows.Next() {
-		var order domain.Order
-		var needAndOrdersID, bankID pgtype.Int4
-		err := rows.Scan(
-			&order.OrderID, &order.DealID, &order.OrderTypeID, &order.Amount, &order.Status,
-			&order.CreatedAt, &order.UpdatedAt, &needAndOrdersID, &bankID,
-		)
+		order, err := scanOrder(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan order: %w", err)
 		}
-		if needAndOrdersID.Valid {
-			needAndOrdersIDInt := int(needAndOrdersID.Int32)
-			order.NeedAndOrdersID = &needAndOrdersIDInt
-		}
-		if bankID.Valid {
-			bankIDInt := int(bankID.Int32)
-			order.BankID = &bankIDInt
-		}
-		orders = append(orders, &order)
+		orders = append(orders, order)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -236,50 +504,81 @@ func (r *Repository) ListOrdersByDeals(ctx context.Context, dealID int) ([]*doma
 	return orders, nil
 }
 
-// CreateOrder creates a new order in the database.
-func (r *Repository) CreateOrder(ctx context.Context, order *domain.Order) (*domain.Order, error) {
-	query := `
-		INSERT INTO orders (deal_id, order_type_id, amount, status, created_at, updated_at, need_and_orders_id, bank_id)
-		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $5, $6)
-		RETURNING order_id, deal_id, order_type_id, amount, status, created_at, updated_at, need_and_orders_id, bank_id`
-
-	var createdOrder domain.Order
-	var needAndOrdersID, bankID pgtype.Int4
-	err := r.db.Conn.QueryRow(ctx, query,
-		order.DealID, order.OrderTypeID, order.Amount, order.Status, order.NeedAndOrdersID, order.BankID,
-	).Scan(
-		&createdOrder.OrderID, &createdOrder.DealID, &createdOrder.OrderTypeID, &createdOrder.Amount,
-		&createdOrder.Status, &createdOrder.CreatedAt, &createdOrder.UpdatedAt, &needAndOrdersID, &bankID,
-	)
+// CreateOrders inserts a batch of orders in a single transaction: either all orders are created or
+// none. The error of a failed insert is a *domain.BatchItemError carrying the index of the order.
+// The deals are locked for the duration of the inserts so that they do not interleave with netting.
+func (r *Repository) CreateOrders(ctx context.Context, orders []*domain.Order) ([]*domain.Order, error) {
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create order: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
 
-	if needAndOrdersID.Valid {
-		needAndOrdersIDInt := int(needAndOrdersID.Int32)
-		createdOrder.NeedAndOrdersID = &needAndOrdersIDInt
+	dealIDs := make([]int, 0, len(orders))
+	for _, order := range orders {
+		dealIDs = append(dealIDs, order.DealID)
 	}
-	if bankID.Valid {
-		bankIDInt := int(bankID.Int32)
-		createdOrder.BankID = &bankIDInt
+	if err = lockDealsTx(ctx, tx, dealIDs...); err != nil {
+		return nil, err
+	}
+	var sessionID int
+	if sessionID, err = openClearingSessionTx(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO orders AS o (deal_id, order_type_id, amount, status, created_at, updated_at, need_and_orders_id,
+			bank_id, currency_code, dealership_id, vehicle_id, order_number, discount, vat_rate, net_amount, vat_amount,
+			gross_amount, execute_at, insurer_id, clearing_session_id, trigger_id, credit_contract_id,
+			appraisal_id)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15,
+			$16, $17, $18, $19, $20, $21)
+		RETURNING ` + orderColumns
+
+	createdOrders := make([]*domain.Order, 0, len(orders))
+	for i, order := range orders {
+		var orderNumber int
+		orderNumber, err = nextOrderNumberTx(ctx, tx, order.DealID)
+		if err != nil {
+			err = &domain.BatchItemError{Index: i, Err: err}
+			return nil, err
+		}
+
+		var createdOrder *domain.Order
+		createdOrder, err = scanOrder(tx.QueryRow(ctx, query,
+			order.DealID, order.OrderTypeID, order.Amount, order.Status, order.NeedAndOrdersID, order.BankID,
+			order.CurrencyCode, order.DealershipID, order.VehicleID, orderNumber, order.Discount, order.VATRate,
+			order.NetAmount, order.VATAmount, order.GrossAmount, order.ExecuteAt, order.InsurerID, sessionID, order.TriggerID,
+			order.CreditContractID, order.AppraisalID,
+		))
+		if err != nil {
+			err = &domain.BatchItemError{Index: i, Err: fmt.Errorf("failed to create order: %w", wrapConstraintError(err))}
+			return nil, err
+		}
+		createdOrders = append(createdOrders, createdOrder)
 	}
 
-	return &createdOrder, nil
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return createdOrders, nil
 }
 
 // GetOrder retrieves an order by its ID.
 func (r *Repository) GetOrder(ctx context.Context, orderID int) (*domain.Order, error) {
 	query := `
-		SELECT order_id, deal_id, order_type_id, amount, status, created_at, updated_at, need_and_orders_id, bank_id
-		FROM orders
-		WHERE order_id = $1`
+		SELECT ` + orderColumns + `
+		FROM orders o
+		WHERE o.order_id = $1 AND o.deleted_at IS NULL`
 
-	var order domain.Order
-	var needAndOrdersID, bankID pgtype.Int4
-	err := r.db.Conn.QueryRow(ctx, query, orderID).Scan(
-		&order.OrderID, &order.DealID, &order.OrderTypeID, &order.Amount, &order.Status,
-		&order.CreatedAt, &order.UpdatedAt, &needAndOrdersID, &bankID,
-	)
+	order, err := scanOrder(r.db.QueryRow(ctx, query, orderID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
@@ -287,181 +586,241 @@ func (r *Repository) GetOrder(ctx context.Context, orderID int) (*domain.Order,
 		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
 
-	if needAndOrdersID.Valid {
-		needAndOrdersIDInt := int(needAndOrdersID.Int32)
-		order.NeedAndOrdersID = &needAndOrdersIDInt
+	return order, nil
+}
+
+// GetClientOrder retrieves an order by its ID and checks that its deal belongs to the client.
+// ErrUnauthorized is returned if the order belongs to another client.
+func (r *Repository) GetClientOrder(ctx context.Context, clientID, orderID int) (*domain.Order, error) {
+	query := `
+		SELECT d.client_id
+		FROM orders o
+		JOIN deals d ON o.deal_id = d.deal_id
+		WHERE o.order_id = $1 AND o.deleted_at IS NULL`
+
+	var ownerID pgtype.Int4
+	if err := r.db.QueryRow(ctx, query, orderID).Scan(&ownerID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get order owner: %w", err)
 	}
-	if bankID.Valid {
-		bankIDInt := int(bankID.Int32)
-		order.BankID = &bankIDInt
+	if !ownerID.Valid || int(ownerID.Int32) != clientID {
+		return nil, ErrUnauthorized
 	}
 
-	return &order, nil
+	return r.GetOrder(ctx, orderID)
 }
 
 // UpdateOrder updates an existing order in the database.
+// Both the current and the target deal are locked so that the update does not interleave with netting.
 func (r *Repository) UpdateOrder(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var currentDealID, orderNumber int
+	err = tx.QueryRow(ctx, `SELECT deal_id, COALESCE(order_number, 0) FROM orders WHERE order_id = $1 AND deleted_at IS NULL`,
+		order.OrderID).Scan(&currentDealID, &orderNumber)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrNotFound
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if err = lockDealsTx(ctx, tx, currentDealID, order.DealID); err != nil {
+		return nil, err
+	}
+	// При переносе в другую сделку заказ получает номер в ней
+	if order.DealID != currentDealID {
+		orderNumber, err = nextOrderNumberTx(ctx, tx, order.DealID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = insertOrderRevisionTx(ctx, tx, order.OrderID, domain.OrderRevisionUpdated); err != nil {
+		return nil, err
+	}
+
 	query := `
-		UPDATE orders
+		UPDATE orders o
 		SET deal_id = $1, order_type_id = $2, amount = $3, status = $4, updated_at = CURRENT_TIMESTAMP,
-			need_and_orders_id = $5, bank_id = $6
-		WHERE order_id = $7
-		RETURNING order_id, deal_id, order_type_id, amount, status, created_at, updated_at, need_and_orders_id, bank_id`
-
-	var updatedOrder domain.Order
-	var needAndOrdersID, bankID pgtype.Int4
-	err := r.db.Conn.QueryRow(ctx, query,
-		order.DealID, order.OrderTypeID, order.Amount, order.Status, order.NeedAndOrdersID, order.BankID, order.OrderID,
-	).Scan(
-		&updatedOrder.OrderID, &updatedOrder.DealID, &updatedOrder.OrderTypeID, &updatedOrder.Amount,
-		&updatedOrder.Status, &updatedOrder.CreatedAt, &updatedOrder.UpdatedAt, &needAndOrdersID, &bankID,
-	)
+			need_and_orders_id = $5, bank_id = $6, dealership_id = $7,
+			vehicle_id = $8, order_number = NULLIF($10, 0), discount = $11, vat_rate = $12, net_amount = $13,
+			vat_amount = $14, gross_amount = $15, execute_at = $16, insurer_id = $17, credit_contract_id = $18,
+			appraisal_id = $19
+		WHERE o.order_id = $9 AND o.deleted_at IS NULL
+		RETURNING ` + orderColumns
+
+	updatedOrder, err := scanOrder(tx.QueryRow(ctx, query,
+		order.DealID, order.OrderTypeID, order.Amount, order.Status, order.NeedAndOrdersID, order.BankID,
+		order.DealershipID, order.VehicleID, order.OrderID, orderNumber, order.Discount, order.VATRate,
+		order.NetAmount, order.VATAmount, order.GrossAmount, order.ExecuteAt, order.InsurerID, order.CreditContractID,
+		order.AppraisalID,
+	))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrNotFound
+			err = ErrNotFound
+			return nil, err
 		}
-		return nil, fmt.Errorf("failed to update order: %w", err)
+		return nil, fmt.Errorf("failed to update order: %w", wrapConstraintError(err))
 	}
 
-	if needAndOrdersID.Valid {
-		needAndOrdersIDInt := int(needAndOrdersID.Int32)
-		updatedOrder.NeedAndOrdersID = &needAndOrdersIDInt
-	}
-	if bankID.Valid {
-		bankIDInt := int(bankID.Int32)
-		updatedOrder.BankID = &bankIDInt
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return &updatedOrder, nil
+	return updatedOrder, nil
 }
 
-// ListMonetarySettlements performs a netting calculation (bilateral or multilateral) based on orders for a deal.
-func (r *Repository) ListMonetarySettlements(ctx context.Context, dealID, page, limit int) ([]*domain.MonetarySettlement, int, error) {
-	// Validate inputs
-	if dealID <= 0 {
-		return nil, 0, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
-	}
-	if page < 1 || limit < 1 {
-		return nil, 0, fmt.Errorf("invalid pagination parameters: %w", ErrInvalidInput)
+// UpdateOrderStatus moves an order from one status to another and records the change in the
+// order status history. The update only succeeds if the order is still in the from status,
+// otherwise ErrConflict is returned.
+func (r *Repository) UpdateOrderStatus(ctx context.Context, orderID int, from, to string) (*domain.Order, error) {
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
 
-	// Get orders for the deal
-	orders, err := r.ListOrdersByDeals(ctx, dealID)
+	var dealID int
+	err = tx.QueryRow(ctx, `SELECT deal_id FROM orders WHERE order_id = $1 AND deleted_at IS NULL`, orderID).
+		Scan(&dealID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list orders: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrNotFound
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if err = lockDealsTx(ctx, tx, dealID); err != nil {
+		return nil, err
+	}
+	if err = insertOrderRevisionTx(ctx, tx, orderID, domain.OrderRevisionStatusChanged); err != nil {
+		return nil, err
 	}
 
-	// Check if any order has a bank_id
-	hasBank := false
-	for _, order := range orders {
-		if order.BankID != nil {
-			hasBank = true
-			break
+	query := `
+		UPDATE orders o
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE o.order_id = $2 AND o.status = $3 AND o.deleted_at IS NULL
+		RETURNING ` + orderColumns
+
+	order, err := scanOrder(tx.QueryRow(ctx, query, to, orderID, from))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrConflict
+			return nil, err
 		}
+		return nil, fmt.Errorf("failed to update order status: %w", wrapConstraintError(err))
 	}
 
-	// Participants: Client (C), Rolf (R), Bank (B) if applicable
-	participants := []string{"Client", "Rolf"}
-	if hasBank {
-		participants = append(participants, "Bank")
+	query = `
+		INSERT INTO order_status_history (order_id, from_status, to_status, changed_by)
+		VALUES ($1, $2, $3, $4)`
+	if _, err = tx.Exec(ctx, query, orderID, from, to, actorFromContext(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to record order status history: %w", err)
 	}
-	n := len(participants)
 
-	// Initialize obligation matrix: obligations[i][j] is amount participant i owes to participant j
-	obligations := make([][]float64, n)
-	for i := range obligations {
-		obligations[i] = make([]float64, n)
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// Build obligation matrix based on order_type_id
-	for _, order := range orders {
-		amount := order.Amount
-		switch order.OrderTypeID {
-		case 1: // Purchase: Client owes Rolf
-			obligations[0][1] += amount // Client -> Rolf
-		case 2: // Credit: Client owes Bank, Bank owes Rolf
-			if order.BankID != nil {
-				obligations[0][2] += amount // Client -> Bank
-				obligations[2][1] += amount // Bank -> Rolf
-			} else {
-				// Fallback to Client -> Rolf if no bank
-				obligations[0][1] += amount
-			}
-		case 3: // Trade-in: Rolf owes Client
-			obligations[1][0] += amount // Rolf -> Client
-		default:
-			return nil, 0, fmt.Errorf("unknown order_type_id %d: %w", order.OrderTypeID, ErrInvalidInput)
-		}
+	return order, nil
+}
+
+// DeleteOrder soft-deletes an order that has not been executed and flags the settlements of its deal
+// for recomputation. The deal is locked so that the deletion does not interleave with netting.
+func (r *Repository) DeleteOrder(ctx context.Context, orderID int) error {
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
 
-	// Calculate net positions: net[i] = sum(a_ij) - sum(a_ji)
-	netPositions := make([]float64, n)
-	for i := 0; i < n; i++ {
-		for j := 0; j < n; j++ {
-			if i != j {
-				netPositions[i] += obligations[i][j] // Outgoing
-				netPositions[i] -= obligations[j][i] // Incoming
-			}
+	var dealID int
+	var status string
+	err = tx.QueryRow(ctx, `SELECT deal_id, status FROM orders WHERE order_id = $1 AND deleted_at IS NULL`, orderID).
+		Scan(&dealID, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrNotFound
+			return err
 		}
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+	if err = lockDealsTx(ctx, tx, dealID); err != nil {
+		return err
+	}
+	if err = insertOrderRevisionTx(ctx, tx, orderID, domain.OrderRevisionDeleted); err != nil {
+		return err
 	}
 
-	// Create MonetarySettlements for non-zero net positions
-	var settlements []*domain.MonetarySettlement
-	now := time.Now()
-	for i, net := range netPositions {
-		if net != 0 {
-			settlement := &domain.MonetarySettlement{
-				MonetarySettlementID: 0, // Not saved in DB yet
-				DealID:               &dealID,
-				Amount:               net, // Positive: owes, Negative: owed
-				Status:               domain.StatusPending,
-				CreatedAt:            now,
-				UpdatedAt:            now,
-			}
-			if hasBank && participants[i] == "Bank" {
-				// Set BankID for bank participant (assume bank_id from first order with bank)
-				for _, order := range orders {
-					if order.BankID != nil {
-						settlement.BankID = order.BankID
-						break
-					}
-				}
-			}
-			settlements = append(settlements, settlement)
-		}
+	// Статус мог смениться до получения блокировки, поэтому проверяем его в самом удалении
+	query := `
+		UPDATE orders
+		SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE order_id = $1 AND deleted_at IS NULL AND status <> $2`
+	tag, err := tx.Exec(ctx, query, orderID, domain.StatusExecuted)
+	if err != nil {
+		return fmt.Errorf("failed to delete order: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		err = ErrConflict
+		return err
 	}
 
-	// Apply pagination
-	total := len(settlements)
-	start := (page - 1) * limit
-	end := start + limit
-	if start > total {
-		return nil, total, nil
+	query = `UPDATE deals SET settlements_recompute_required = true, updated_at = CURRENT_TIMESTAMP WHERE deal_id = $1`
+	if _, err = tx.Exec(ctx, query, dealID); err != nil {
+		return fmt.Errorf("failed to flag deal settlements: %w", err)
 	}
-	if end > total {
-		end = total
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return settlements[start:end], total, nil
+	return nil
 }
 
 // CreateMonetarySettlement creates a new monetary settlement in the database.
 func (r *Repository) CreateMonetarySettlement(ctx context.Context, settlement *domain.MonetarySettlement) (*domain.MonetarySettlement, error) {
 	query := `
-		INSERT INTO monetary_settlements (deal_id, amount, status, created_at, updated_at, bank_id)
-		VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $4)
-		RETURNING monetary_settlement_id, deal_id, amount, status, created_at, updated_at, bank_id`
+		INSERT INTO monetary_settlements (deal_id, amount, status, created_at, updated_at, bank_id, currency_code)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $4, $5)
+		RETURNING monetary_settlement_id, deal_id, amount, status, created_at, updated_at, bank_id, currency_code`
 
 	var createdSettlement domain.MonetarySettlement
 	var bankID pgtype.Int4
-	err := r.db.Conn.QueryRow(ctx, query,
-		settlement.DealID, settlement.Amount, settlement.Status, settlement.BankID,
+	err := r.db.QueryRow(ctx, query,
+		settlement.DealID, settlement.Amount, settlement.Status, settlement.BankID, settlement.CurrencyCode,
 	).Scan(
 		&createdSettlement.MonetarySettlementID, &createdSettlement.DealID, &createdSettlement.Amount,
 		&createdSettlement.Status, &createdSettlement.CreatedAt, &createdSettlement.UpdatedAt, &bankID,
+		&createdSettlement.CurrencyCode,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create monetary settlement: %w", err)
+		return nil, fmt.Errorf("failed to create monetary settlement: %w", wrapConstraintError(err))
 	}
 
 	if bankID.Valid {
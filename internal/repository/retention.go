@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cliring/internal/domain"
+)
+
+// PurgeDeals hard-deletes deals soft-deleted before cutoff. Deals that still have orders or
+// monetary settlements are kept until those are purged. With anonymize set the personal data
+// of the deals is scrubbed instead and the rows are kept.
+func (r *Repository) PurgeDeals(ctx context.Context, cutoff time.Time, anonymize bool) (int64, error) {
+	query := `
+		DELETE FROM deals d
+		WHERE d.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.deal_id = d.deal_id)
+			AND NOT EXISTS (SELECT 1 FROM monetary_settlements ms WHERE ms.deal_id = d.deal_id)`
+	if anonymize {
+		query = `
+			UPDATE deals
+			SET client_id = NULL, manager_id = NULL, anonymized_at = CURRENT_TIMESTAMP
+			WHERE deleted_at < $1 AND anonymized_at IS NULL`
+	}
+
+	result, err := r.db.Exec(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deals: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// PurgeOrders hard-deletes orders soft-deleted before cutoff together with their allocations.
+// With anonymize set the external references of the orders are scrubbed instead.
+func (r *Repository) PurgeOrders(ctx context.Context, cutoff time.Time, anonymize bool) (int64, error) {
+	if anonymize {
+		query := `
+			UPDATE orders
+			SET need_and_orders_id = NULL, anonymized_at = CURRENT_TIMESTAMP
+			WHERE deleted_at < $1 AND anonymized_at IS NULL`
+
+		result, err := r.db.Exec(ctx, query, cutoff)
+		if err != nil {
+			return 0, fmt.Errorf("failed to anonymize orders: %w", err)
+		}
+		return result.RowsAffected(), nil
+	}
+
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	query := `DELETE FROM order_allocations WHERE order_id IN (SELECT order_id FROM orders WHERE deleted_at < $1)`
+	_, err = tx.Exec(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge order allocations: %w", err)
+	}
+
+	query = `DELETE FROM orders WHERE deleted_at < $1`
+	result, err := tx.Exec(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge orders: %w", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// PurgeMonetarySettlements hard-deletes monetary settlements soft-deleted before cutoff together
+// with their allocations.
+func (r *Repository) PurgeMonetarySettlements(ctx context.Context, cutoff time.Time) (int64, error) {
+	// Begin transaction
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	query := `
+		DELETE FROM order_allocations
+		WHERE monetary_settlement_id IN (SELECT monetary_settlement_id FROM monetary_settlements WHERE deleted_at < $1)`
+	_, err = tx.Exec(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge order allocations: %w", err)
+	}
+
+	query = `DELETE FROM monetary_settlements WHERE deleted_at < $1`
+	result, err := tx.Exec(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge monetary settlements: %w", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// ListSoftDeleted retrieves soft-deleted rows of an entity deleted before the given time
+// that have not been anonymized yet.
+func (r *Repository) ListSoftDeleted(ctx context.Context, entity string, deletedBefore time.Time) ([]*domain.UpcomingPurge, error) {
+	var query string
+	switch entity {
+	case domain.EntityDeals:
+		query = `SELECT deal_id, deleted_at FROM deals WHERE deleted_at < $1 AND anonymized_at IS NULL`
+	case domain.EntityOrders:
+		query = `SELECT order_id, deleted_at FROM orders WHERE deleted_at < $1 AND anonymized_at IS NULL`
+	case domain.EntityMonetarySettlements:
+		query = `SELECT monetary_settlement_id, deleted_at FROM monetary_settlements WHERE deleted_at < $1`
+	default:
+		return nil, fmt.Errorf("unknown entity %q: %w", entity, ErrInvalidInput)
+	}
+	query += ` ORDER BY deleted_at`
+
+	rows, err := r.db.Query(ctx, query, deletedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query soft-deleted %s: %w", entity, err)
+	}
+	defer rows.Close()
+
+	var purges []*domain.UpcomingPurge
+	for rows.Next() {
+		purge := domain.UpcomingPurge{Entity: entity}
+		if err := rows.Scan(&purge.ID, &purge.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan soft-deleted %s: %w", entity, err)
+		}
+		purges = append(purges, &purge)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating soft-deleted %s: %w", entity, err)
+	}
+
+	return purges, nil
+}
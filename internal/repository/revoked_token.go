@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cliring/internal/domain"
+)
+
+// RevokeToken adds a token to the revocation list. Revoking a revoked token again keeps the first
+// revocation and extends its expiry if needed.
+func (r *Repository) RevokeToken(ctx context.Context, revocation domain.TokenRevocation) (*domain.TokenRevocation, error) {
+	query := `
+		INSERT INTO revoked_tokens (jti, expires_at, revoked_by, reason, revoked_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), CURRENT_TIMESTAMP)
+		ON CONFLICT (jti) DO UPDATE SET expires_at = GREATEST(revoked_tokens.expires_at, EXCLUDED.expires_at)
+		RETURNING jti, expires_at, revoked_by, COALESCE(reason, ''), revoked_at`
+
+	var revoked domain.TokenRevocation
+	var expiresAt time.Time
+	if err := r.db.QueryRow(ctx, query, revocation.JTI, revocation.ExpiresAt, actorFromContext(ctx), revocation.Reason).
+		Scan(&revoked.JTI, &expiresAt, &revoked.RevokedBy, &revoked.Reason, &revoked.RevokedAt); err != nil {
+		return nil, fmt.Errorf("failed to revoke token: %w", err)
+	}
+	revoked.ExpiresAt = &expiresAt
+	return &revoked, nil
+}
+
+// IsTokenRevoked reports whether an unexpired revocation of the token exists.
+func (r *Repository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	query := `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1 AND expires_at > CURRENT_TIMESTAMP)`
+	if err := r.db.QueryRow(ctx, query, jti).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+// PurgeRevokedTokens deletes revocations of tokens expired before the given time and returns their number.
+func (r *Repository) PurgeRevokedTokens(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge revoked tokens: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// nextOrderNumberTx issues the next per-deal order number inside the transaction of the order write.
+// The counter row stays locked until the transaction ends, so numbers are neither skipped nor reused.
+func nextOrderNumberTx(ctx context.Context, tx pgx.Tx, dealID int) (int, error) {
+	query := `
+		INSERT INTO deal_counters (deal_id, last_order_number)
+		VALUES ($1, 1)
+		ON CONFLICT (deal_id) DO UPDATE SET last_order_number = deal_counters.last_order_number + 1
+		RETURNING last_order_number`
+
+	var number int
+	if err := tx.QueryRow(ctx, query, dealID).Scan(&number); err != nil {
+		return 0, fmt.Errorf("failed to issue order number: %w", wrapConstraintError(err))
+	}
+	return number, nil
+}
+
+// NextSettlementRun issues the next per-deal settlement run number. All settlements executed
+// in one run share the number.
+func (r *Repository) NextSettlementRun(ctx context.Context, dealID int) (int, error) {
+	query := `
+		INSERT INTO deal_counters (deal_id, last_settlement_run)
+		VALUES ($1, 1)
+		ON CONFLICT (deal_id) DO UPDATE SET last_settlement_run = deal_counters.last_settlement_run + 1
+		RETURNING last_settlement_run`
+
+	var run int
+	if err := r.db.QueryRow(ctx, query, dealID).Scan(&run); err != nil {
+		return 0, fmt.Errorf("failed to issue settlement run number: %w", wrapConstraintError(err))
+	}
+	return run, nil
+}
@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// createLedgerEntriesTx posts the ledger entries of an executed settlement.
+func createLedgerEntriesTx(ctx context.Context, tx pgx.Tx, settlement *domain.MonetarySettlement, entries []*domain.LedgerEntry) ([]*domain.LedgerEntry, error) {
+	query := `
+		INSERT INTO ledger_entries (monetary_settlement_id, deal_id, account, direction, amount, currency_code,
+			payment_reference, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), CURRENT_TIMESTAMP)
+		RETURNING ledger_entry_id, monetary_settlement_id, deal_id, account, direction, amount, currency_code,
+			COALESCE(payment_reference, ''), created_at`
+
+	var created []*domain.LedgerEntry
+	for _, entry := range entries {
+		var entryRow domain.LedgerEntry
+		err := tx.QueryRow(ctx, query,
+			settlement.MonetarySettlementID, settlement.DealID, entry.Account, entry.Direction, entry.Amount,
+			settlement.CurrencyCode, settlement.PaymentReference,
+		).Scan(
+			&entryRow.LedgerEntryID, &entryRow.MonetarySettlementID, &entryRow.DealID, &entryRow.Account,
+			&entryRow.Direction, &entryRow.Amount, &entryRow.CurrencyCode, &entryRow.PaymentReference, &entryRow.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ledger entry: %w", wrapConstraintError(err))
+		}
+		created = append(created, &entryRow)
+	}
+	return created, nil
+}
+
+// FixPendingSettlements stores the netting result of a deal as pending settlements to be executed
+// one by one. Pending settlements fixed earlier for the deal are cancelled.
+func (r *Repository) FixPendingSettlements(ctx context.Context, dealID int, settlements []*domain.MonetarySettlement) ([]*domain.MonetarySettlement, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if _, err = cancelPendingSettlementsTx(ctx, tx, dealID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO monetary_settlements (deal_id, amount, status, created_at, updated_at, bank_id, currency_code,
+			due_date, dealership_id, run_number, insurer_id, participant, fx_rates, approval_status, created_by,
+			clearing_session_id, rounding_residual)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, $4, $5, NULLIF($6, '')::date, $7, $8, $9, $10, $11,
+			NULLIF($12, ''), NULLIF($13, ''), $14, $15)
+		RETURNING ` + monetarySettlementColumns
+
+	var fixed []*domain.MonetarySettlement
+	for _, settlement := range settlements {
+		var created *domain.MonetarySettlement
+		created, err = scanMonetarySettlement(tx.QueryRow(ctx, query,
+			dealID, settlement.Amount, domain.StatusPending, settlement.BankID, settlement.CurrencyCode,
+			settlement.DueDate, settlement.DealershipID, settlement.RunNumber, settlement.InsurerID, settlement.Participant,
+			settlement.FXRates, settlement.ApprovalStatus, settlement.CreatedBy, settlement.ClearingSessionID,
+			settlement.RoundingResidual,
+		))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create monetary settlement: %w", wrapConstraintError(err))
+		}
+		fixed = append(fixed, created)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return fixed, nil
+}
+
+// CancelPendingSettlements cancels the pending settlements of a deal that were not executed
+// and returns the cancelled settlements.
+func (r *Repository) CancelPendingSettlements(ctx context.Context, dealID int) ([]*domain.MonetarySettlement, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	cancelled, err := cancelPendingSettlementsTx(ctx, tx, dealID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return cancelled, nil
+}
+
+// cancelPendingSettlementsTx cancels the pending settlements of a deal inside a transaction.
+func cancelPendingSettlementsTx(ctx context.Context, tx pgx.Tx, dealID int) ([]*domain.MonetarySettlement, error) {
+	query := `
+		UPDATE monetary_settlements
+		SET status = $1, cancelled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE deal_id = $2 AND status = $3 AND deleted_at IS NULL
+		RETURNING ` + monetarySettlementColumns
+	rows, err := tx.Query(ctx, query, domain.StatusCancelled, dealID, domain.StatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel pending settlements: %w", err)
+	}
+	defer rows.Close()
+
+	var cancelled []*domain.MonetarySettlement
+	for rows.Next() {
+		settlement, err := scanMonetarySettlement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan monetary settlement: %w", err)
+		}
+		cancelled = append(cancelled, settlement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to cancel pending settlements: %w", err)
+	}
+	return cancelled, nil
+}
+
+// ListStoredMonetarySettlements retrieves a page of the stored settlements of a deal in the given status
+// and the total number of such settlements.
+func (r *Repository) ListStoredMonetarySettlements(ctx context.Context, dealID int, status string, page, limit int) ([]*domain.MonetarySettlement, int, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, fmt.Errorf("invalid pagination parameters: %w", ErrInvalidInput)
+	}
+
+	// Count total settlements
+	countQuery := `
+		SELECT COUNT(*)
+		FROM monetary_settlements
+		WHERE deal_id = $1 AND status = $2 AND deleted_at IS NULL`
+
+	var total int
+	if err := r.db.QueryRow(ctx, countQuery, dealID, status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count monetary settlements: %w", err)
+	}
+
+	// Retrieve settlements
+	query := `
+		SELECT ` + monetarySettlementColumns + `
+		FROM monetary_settlements
+		WHERE deal_id = $1 AND status = $2 AND deleted_at IS NULL
+		ORDER BY monetary_settlement_id
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(ctx, query, dealID, status, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list monetary settlements: %w", err)
+	}
+	defer rows.Close()
+
+	var settlements []*domain.MonetarySettlement
+	for rows.Next() {
+		settlement, err := scanMonetarySettlement(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan monetary settlement: %w", err)
+		}
+		settlements = append(settlements, settlement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate monetary settlements: %w", err)
+	}
+
+	return settlements, total, nil
+}
+
+// CountStoredMonetarySettlements returns the number of stored settlements of a deal in the given status.
+func (r *Repository) CountStoredMonetarySettlements(ctx context.Context, dealID int, status string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM monetary_settlements
+		WHERE deal_id = $1 AND status = $2 AND deleted_at IS NULL`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, dealID, status).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count monetary settlements: %w", err)
+	}
+	return count, nil
+}
+
+// GetMonetarySettlement retrieves a stored monetary settlement by its ID.
+func (r *Repository) GetMonetarySettlement(ctx context.Context, settlementID int) (*domain.MonetarySettlement, error) {
+	query := `
+		SELECT ` + monetarySettlementColumns + `
+		FROM monetary_settlements
+		WHERE monetary_settlement_id = $1 AND deleted_at IS NULL`
+
+	settlement, err := scanMonetarySettlement(r.db.QueryRow(ctx, query, settlementID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get monetary settlement: %w", err)
+	}
+	return settlement, nil
+}
+
+// ExecutePendingSettlement moves a pending settlement to the executed status with the payment reference,
+// allocates it to orders and posts its ledger entries in one transaction.
+// ErrConflict is returned if the settlement is no longer pending.
+func (r *Repository) ExecutePendingSettlement(ctx context.Context, settlementID int, paymentReference string,
+	allocations []*domain.OrderAllocation, entries []*domain.LedgerEntry) (*domain.MonetarySettlement, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	// Условие на статус защищает от повторного исполнения параллельным запросом,
+	// условие на одобрение - от исполнения расчета, одобрение которого параллельно отозвано
+	query := `
+		UPDATE monetary_settlements
+		SET status = $1, payment_reference = $2, executed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE monetary_settlement_id = $3 AND status = $4 AND deleted_at IS NULL
+			AND (approval_status IS NULL OR approval_status = $5)
+		RETURNING ` + monetarySettlementColumns
+
+	executed, err := scanMonetarySettlement(tx.QueryRow(ctx, query,
+		domain.StatusExecuted, paymentReference, settlementID, domain.StatusPending, domain.ApprovalApproved,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrConflict
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to execute monetary settlement: %w", wrapConstraintError(err))
+	}
+
+	executed.Allocations, err = createAllocationsTx(ctx, tx, executed.MonetarySettlementID, allocations)
+	if err != nil {
+		return nil, err
+	}
+	executed.LedgerEntries, err = createLedgerEntriesTx(ctx, tx, executed, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return executed, nil
+}
+
+// CancelMonetarySettlement cancels a pending settlement with the reason and flags its deal for re-netting.
+// ErrConflict is returned if the settlement is no longer pending.
+func (r *Repository) CancelMonetarySettlement(ctx context.Context, settlementID int, reason string) (*domain.MonetarySettlement, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	query := `
+		UPDATE monetary_settlements
+		SET status = $1, cancellation_reason = $2, cancelled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE monetary_settlement_id = $3 AND status = $4 AND deleted_at IS NULL
+		RETURNING ` + monetarySettlementColumns
+
+	cancelled, err := scanMonetarySettlement(tx.QueryRow(ctx, query,
+		domain.StatusCancelled, reason, settlementID, domain.StatusPending,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrConflict
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to cancel monetary settlement: %w", wrapConstraintError(err))
+	}
+
+	query = `UPDATE deals SET settlements_recompute_required = true, updated_at = CURRENT_TIMESTAMP WHERE deal_id = $1`
+	if _, err = tx.Exec(ctx, query, cancelled.DealID); err != nil {
+		return nil, fmt.Errorf("failed to flag deal settlements: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return cancelled, nil
+}
+
+// ReviewMonetarySettlement records the decision of an approver on a pending settlement awaiting approval.
+// ErrConflict is returned if the settlement is no longer pending or does not await approval.
+func (r *Repository) ReviewMonetarySettlement(ctx context.Context, settlementID int, approvalStatus, reviewer, comment string) (*domain.MonetarySettlement, error) {
+	query := `
+		UPDATE monetary_settlements
+		SET approval_status = $1, reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP, review_comment = NULLIF($3, ''),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE monetary_settlement_id = $4 AND status = $5 AND approval_status = $6 AND deleted_at IS NULL
+		RETURNING ` + monetarySettlementColumns
+
+	reviewed, err := scanMonetarySettlement(r.db.QueryRow(ctx, query,
+		approvalStatus, reviewer, comment, settlementID, domain.StatusPending, domain.ApprovalRequired,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to review monetary settlement: %w", wrapConstraintError(err))
+	}
+	return reviewed, nil
+}
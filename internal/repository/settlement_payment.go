@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"cliring/internal/domain"
+)
+
+// CreateSettlementPayment records a partial payment against a pending monetary settlement.
+// It returns ErrConflict if the settlement is not pending and ErrInvalidInput if the payment
+// exceeds the remaining balance of the settlement.
+func (r *Repository) CreateSettlementPayment(ctx context.Context, payment *domain.SettlementPayment) (*domain.SettlementPayment, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	// Блокировка расчета, чтобы параллельные оплаты не превысили его сумму
+	var amount domain.Money
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT amount, status FROM monetary_settlements
+		WHERE monetary_settlement_id = $1 AND deleted_at IS NULL
+		FOR UPDATE`, payment.MonetarySettlementID).Scan(&amount, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = ErrNotFound
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to lock monetary settlement: %w", err)
+	}
+	if status != domain.StatusPending {
+		err = ErrConflict
+		return nil, err
+	}
+
+	var paid domain.Money
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount), 0) FROM settlement_payments WHERE monetary_settlement_id = $1`,
+		payment.MonetarySettlementID).Scan(&paid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum settlement payments: %w", err)
+	}
+	if paid+payment.Amount > amount {
+		err = ErrInvalidInput
+		return nil, err
+	}
+
+	var created domain.SettlementPayment
+	err = tx.QueryRow(ctx, `
+		INSERT INTO settlement_payments (monetary_settlement_id, amount, payment_reference, created_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		RETURNING payment_id, monetary_settlement_id, amount, payment_reference, created_at`,
+		payment.MonetarySettlementID, payment.Amount, payment.PaymentReference,
+	).Scan(&created.PaymentID, &created.MonetarySettlementID, &created.Amount, &created.PaymentReference, &created.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create settlement payment: %w", wrapConstraintError(err))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &created, nil
+}
+
+// ListSettlementPayments retrieves the partial payments of a monetary settlement in the order they were recorded.
+func (r *Repository) ListSettlementPayments(ctx context.Context, settlementID int) ([]*domain.SettlementPayment, error) {
+	query := `
+		SELECT payment_id, monetary_settlement_id, amount, payment_reference, created_at
+		FROM settlement_payments
+		WHERE monetary_settlement_id = $1
+		ORDER BY payment_id`
+
+	rows, err := r.db.Query(ctx, query, settlementID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query settlement payments: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []*domain.SettlementPayment
+	for rows.Next() {
+		var payment domain.SettlementPayment
+		err := rows.Scan(&payment.PaymentID, &payment.MonetarySettlementID, &payment.Amount,
+			&payment.PaymentReference, &payment.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan settlement payment: %w", err)
+		}
+		payments = append(payments, &payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating settlement payments: %w", err)
+	}
+
+	return payments, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"cliring/internal/domain"
+)
+
+// ListPayablePendingSettlements retrieves the pending settlements of all deals that a participant has to pay.
+func (r *Repository) ListPayablePendingSettlements(ctx context.Context) ([]*domain.MonetarySettlement, error) {
+	query := `
+		SELECT ` + monetarySettlementColumns + `
+		FROM monetary_settlements
+		WHERE status = $1 AND amount > 0 AND deal_id IS NOT NULL AND deleted_at IS NULL
+		ORDER BY monetary_settlement_id`
+
+	rows, err := r.db.Query(ctx, query, domain.StatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending settlements: %w", err)
+	}
+	defer rows.Close()
+
+	var settlements []*domain.MonetarySettlement
+	for rows.Next() {
+		settlement, err := scanMonetarySettlement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan monetary settlement: %w", err)
+		}
+		settlements = append(settlements, settlement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating monetary settlements: %w", err)
+	}
+	return settlements, nil
+}
+
+// LastPenaltyDates retrieves the latest accrual date of the penalties of every settlement that has penalties.
+func (r *Repository) LastPenaltyDates(ctx context.Context, settlementIDs []int) (map[int]string, error) {
+	query := `
+		SELECT monetary_settlement_id, to_char(MAX(accrual_date), 'YYYY-MM-DD')
+		FROM settlement_penalties
+		WHERE monetary_settlement_id = ANY($1)
+		GROUP BY monetary_settlement_id`
+
+	rows, err := r.db.Query(ctx, query, settlementIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query penalty dates: %w", err)
+	}
+	defer rows.Close()
+
+	dates := map[int]string{}
+	for rows.Next() {
+		var settlementID int
+		var date string
+		if err := rows.Scan(&settlementID, &date); err != nil {
+			return nil, fmt.Errorf("failed to scan penalty date: %w", err)
+		}
+		dates[settlementID] = date
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating penalty dates: %w", err)
+	}
+	return dates, nil
+}
+
+// CreateSettlementPenalties stores accrued penalties. A penalty already accrued for the settlement and day
+// is kept; it returns the number of penalties stored.
+func (r *Repository) CreateSettlementPenalties(ctx context.Context, penalties []*domain.SettlementPenalty) (int, error) {
+	query := `
+		INSERT INTO settlement_penalties (monetary_settlement_id, deal_id, participant, participant_id, accrual_date,
+			base_amount, rate, amount, currency_code, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP)
+		ON CONFLICT (monetary_settlement_id, accrual_date) DO NOTHING`
+
+	created := 0
+	for _, penalty := range penalties {
+		tag, err := r.db.Exec(ctx, query,
+			penalty.MonetarySettlementID, penalty.DealID, penalty.Debtor.Role, penalty.Debtor.ExternalID,
+			penalty.AccrualDate, penalty.BaseAmount, penalty.Rate, penalty.Amount, penalty.CurrencyCode,
+		)
+		if err != nil {
+			return created, fmt.Errorf("failed to create settlement penalty: %w", wrapConstraintError(err))
+		}
+		created += int(tag.RowsAffected())
+	}
+	return created, nil
+}
+
+// ListSettlementPenalties retrieves the penalties of the deals accrued up to the date in DateLayout,
+// all of them if the date is empty, in the order of accrual.
+func (r *Repository) ListSettlementPenalties(ctx context.Context, dealIDs []int, upTo string) ([]*domain.SettlementPenalty, error) {
+	query := `
+		SELECT penalty_id, monetary_settlement_id, deal_id, participant, participant_id,
+			to_char(accrual_date, 'YYYY-MM-DD'), base_amount, rate::float8, amount, currency_code, created_at
+		FROM settlement_penalties
+		WHERE deal_id = ANY($1) AND ($2 = '' OR accrual_date <= $2::date)
+		ORDER BY accrual_date, penalty_id`
+
+	rows, err := r.db.Query(ctx, query, dealIDs, upTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query settlement penalties: %w", err)
+	}
+	defer rows.Close()
+
+	var penalties []*domain.SettlementPenalty
+	for rows.Next() {
+		var penalty domain.SettlementPenalty
+		var participantID pgtype.Int4
+		err := rows.Scan(
+			&penalty.PenaltyID, &penalty.MonetarySettlementID, &penalty.DealID, &penalty.Debtor.Role, &participantID,
+			&penalty.AccrualDate, &penalty.BaseAmount, &penalty.Rate, &penalty.Amount, &penalty.CurrencyCode,
+			&penalty.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan settlement penalty: %w", err)
+		}
+		if participantID.Valid {
+			id := int(participantID.Int32)
+			penalty.Debtor.ExternalID = &id
+		}
+		penalties = append(penalties, &penalty)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating settlement penalties: %w", err)
+	}
+	return penalties, nil
+}
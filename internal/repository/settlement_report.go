@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"cliring/internal/domain"
+)
+
+// dealershipSettlements selects the stored settlements of the deals of dealership $1 created in [$2, $3).
+const dealershipSettlements = `
+		SELECT s.*
+		FROM monetary_settlements s
+		JOIN deals d ON d.deal_id = s.deal_id
+		WHERE d.dealership_id = $1 AND s.created_at >= $2 AND s.created_at < $3 AND s.deleted_at IS NULL`
+
+// SettlementTotalsByStatus aggregates the settlements of a dealership created in [from, to) by status and currency.
+func (r *Repository) SettlementTotalsByStatus(ctx context.Context, dealershipID int, from, to time.Time) ([]*domain.SettlementTotal, error) {
+	query := `
+		WITH s AS (` + dealershipSettlements + `)
+		SELECT status, NULL::integer, NULL::integer, currency_code, COUNT(*), SUM(amount)
+		FROM s
+		GROUP BY status, currency_code
+		ORDER BY status, currency_code`
+	return r.settlementTotals(ctx, query, dealershipID, from, to)
+}
+
+// SettlementTotalsByBank aggregates the bank settlements of a dealership created in [from, to) by bank and currency.
+func (r *Repository) SettlementTotalsByBank(ctx context.Context, dealershipID int, from, to time.Time) ([]*domain.SettlementTotal, error) {
+	query := `
+		WITH s AS (` + dealershipSettlements + `)
+		SELECT '', bank_id, NULL::integer, currency_code, COUNT(*), SUM(amount)
+		FROM s
+		WHERE bank_id IS NOT NULL
+		GROUP BY bank_id, currency_code
+		ORDER BY bank_id, currency_code`
+	return r.settlementTotals(ctx, query, dealershipID, from, to)
+}
+
+// SettlementTotalsByOrderType aggregates the allocations of the executed settlements of a dealership
+// created in [from, to) by the order type of the allocated orders and currency.
+func (r *Repository) SettlementTotalsByOrderType(ctx context.Context, dealershipID int, from, to time.Time) ([]*domain.SettlementTotal, error) {
+	query := `
+		WITH s AS (` + dealershipSettlements + `)
+		SELECT '', NULL::integer, o.order_type_id, s.currency_code, COUNT(DISTINCT s.monetary_settlement_id), SUM(a.amount)
+		FROM s
+		JOIN order_allocations a ON a.monetary_settlement_id = s.monetary_settlement_id
+		JOIN orders o ON o.order_id = a.order_id
+		GROUP BY o.order_type_id, s.currency_code
+		ORDER BY o.order_type_id, s.currency_code`
+	return r.settlementTotals(ctx, query, dealershipID, from, to)
+}
+
+// settlementTotals runs an aggregation query selecting status, bank_id, order_type_id, currency_code,
+// count and amount.
+func (r *Repository) settlementTotals(ctx context.Context, query string, args ...any) ([]*domain.SettlementTotal, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate settlements: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []*domain.SettlementTotal{}
+	for rows.Next() {
+		var total domain.SettlementTotal
+		var bankID, orderTypeID pgtype.Int4
+		err := rows.Scan(&total.Status, &bankID, &orderTypeID, &total.CurrencyCode, &total.Count, &total.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan settlement total: %w", err)
+		}
+		if bankID.Valid {
+			bankIDInt := int(bankID.Int32)
+			total.BankID = &bankIDInt
+		}
+		if orderTypeID.Valid {
+			orderTypeIDInt := int(orderTypeID.Int32)
+			total.OrderTypeID = &orderTypeIDInt
+		}
+		totals = append(totals, &total)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating settlement totals: %w", err)
+	}
+
+	return totals, nil
+}
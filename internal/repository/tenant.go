@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cliring/internal/domain"
+	"cliring/pkg/postgres"
+)
+
+// ProvisionTenant registers a tenant and creates its schema with all migrations applied.
+// ErrConflict is returned if the tenant is already provisioned, ErrInvalidInput for an invalid id.
+func (r *Repository) ProvisionTenant(ctx context.Context, tenantID, name string) (*domain.Tenant, error) {
+	tenant, err := r.db.ProvisionTenant(ctx, tenantID, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, postgres.ErrTenantExists):
+			return nil, ErrConflict
+		case errors.Is(err, postgres.ErrInvalidTenantID):
+			return nil, ErrInvalidInput
+		}
+		return nil, fmt.Errorf("failed to provision tenant: %w", err)
+	}
+	return toDomainTenant(*tenant), nil
+}
+
+// ListTenants retrieves the registered tenants ordered by id.
+func (r *Repository) ListTenants(ctx context.Context) ([]*domain.Tenant, error) {
+	tenants, err := r.db.Tenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	result := make([]*domain.Tenant, 0, len(tenants))
+	for _, tenant := range tenants {
+		result = append(result, toDomainTenant(tenant))
+	}
+	return result, nil
+}
+
+// HasTenant reports whether the tenant is registered and its schema is provisioned.
+func (r *Repository) HasTenant(ctx context.Context, tenantID string) (bool, error) {
+	exists, err := r.db.HasTenant(ctx, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check tenant: %w", err)
+	}
+	return exists, nil
+}
+
+// toDomainTenant converts a tenant of the registry.
+func toDomainTenant(tenant postgres.Tenant) *domain.Tenant {
+	return &domain.Tenant{
+		TenantID:      tenant.ID,
+		Name:          tenant.Name,
+		CreatedAt:     tenant.CreatedAt,
+		ProvisionedAt: tenant.ProvisionedAt,
+	}
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"cliring/internal/domain"
+)
+
+// appraisalColumns is the column list selected for a trade-in appraisal.
+const appraisalColumns = `appraisal_id, deal_id, vehicle_id, amount, appraiser, status, COALESCE(comment, ''),
+		COALESCE(reviewed_by, ''), reviewed_at, created_at, updated_at`
+
+// scanAppraisal scans a row selected with appraisalColumns into a trade-in appraisal.
+func scanAppraisal(row pgx.Row) (*domain.TradeInAppraisal, error) {
+	var appraisal domain.TradeInAppraisal
+	var reviewedAt pgtype.Timestamptz
+	err := row.Scan(
+		&appraisal.AppraisalID, &appraisal.DealID, &appraisal.VehicleID, &appraisal.Amount, &appraisal.Appraiser,
+		&appraisal.Status, &appraisal.Comment, &appraisal.ReviewedBy, &reviewedAt, &appraisal.CreatedAt, &appraisal.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if reviewedAt.Valid {
+		appraisal.ReviewedAt = &reviewedAt.Time
+	}
+	return &appraisal, nil
+}
+
+// ListAppraisals retrieves the trade-in appraisals of a deal ordered by ID.
+func (r *Repository) ListAppraisals(ctx context.Context, dealID int) ([]*domain.TradeInAppraisal, error) {
+	query := `
+		SELECT ` + appraisalColumns + `
+		FROM trade_in_appraisals
+		WHERE deal_id = $1
+		ORDER BY appraisal_id`
+
+	rows, err := r.db.Query(ctx, query, dealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query appraisals: %w", err)
+	}
+	defer rows.Close()
+
+	var appraisals []*domain.TradeInAppraisal
+	for rows.Next() {
+		appraisal, err := scanAppraisal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan appraisal: %w", err)
+		}
+		appraisals = append(appraisals, appraisal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating appraisals: %w", err)
+	}
+
+	return appraisals, nil
+}
+
+// GetAppraisal retrieves a trade-in appraisal by its ID.
+func (r *Repository) GetAppraisal(ctx context.Context, appraisalID int) (*domain.TradeInAppraisal, error) {
+	query := `SELECT ` + appraisalColumns + ` FROM trade_in_appraisals WHERE appraisal_id = $1`
+
+	appraisal, err := scanAppraisal(r.db.QueryRow(ctx, query, appraisalID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get appraisal: %w", err)
+	}
+	return appraisal, nil
+}
+
+// CreateAppraisal stores a pending trade-in appraisal.
+func (r *Repository) CreateAppraisal(ctx context.Context, appraisal domain.TradeInAppraisal) (*domain.TradeInAppraisal, error) {
+	query := `
+		INSERT INTO trade_in_appraisals (deal_id, vehicle_id, amount, appraiser, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING ` + appraisalColumns
+
+	created, err := scanAppraisal(r.db.QueryRow(ctx, query,
+		appraisal.DealID, appraisal.VehicleID, appraisal.Amount, appraisal.Appraiser, domain.AppraisalPending))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create appraisal: %w", wrapConstraintError(err))
+	}
+	return created, nil
+}
+
+// UpdateAppraisal replaces the vehicle, amount and appraiser of a pending appraisal. ErrConflict is returned
+// if the appraisal has already been reviewed.
+func (r *Repository) UpdateAppraisal(ctx context.Context, appraisal domain.TradeInAppraisal) (*domain.TradeInAppraisal, error) {
+	query := `
+		UPDATE trade_in_appraisals
+		SET vehicle_id = $2, amount = $3, appraiser = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE appraisal_id = $1 AND status = $5
+		RETURNING ` + appraisalColumns
+
+	updated, err := scanAppraisal(r.db.QueryRow(ctx, query,
+		appraisal.AppraisalID, appraisal.VehicleID, appraisal.Amount, appraisal.Appraiser, domain.AppraisalPending))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to update appraisal: %w", wrapConstraintError(err))
+	}
+	return updated, nil
+}
+
+// ReviewAppraisal records the decision on a pending appraisal. ErrConflict is returned if the appraisal
+// is no longer pending.
+func (r *Repository) ReviewAppraisal(ctx context.Context, appraisalID int, status, reviewer, comment string) (*domain.TradeInAppraisal, error) {
+	query := `
+		UPDATE trade_in_appraisals
+		SET status = $1, reviewed_by = NULLIF($2, ''), reviewed_at = CURRENT_TIMESTAMP, comment = NULLIF($3, ''),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE appraisal_id = $4 AND status = $5
+		RETURNING ` + appraisalColumns
+
+	reviewed, err := scanAppraisal(r.db.QueryRow(ctx, query,
+		status, reviewer, comment, appraisalID, domain.AppraisalPending))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to review appraisal: %w", wrapConstraintError(err))
+	}
+	return reviewed, nil
+}
+
+// DeleteAppraisal removes a trade-in appraisal. ErrConflict is returned if orders reference the appraisal.
+func (r *Repository) DeleteAppraisal(ctx context.Context, appraisalID int) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM trade_in_appraisals WHERE appraisal_id = $1`, appraisalID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to delete appraisal: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
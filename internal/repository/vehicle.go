@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"cliring/internal/domain"
+)
+
+// vehicleColumns is the column list selected for a vehicle.
+const vehicleColumns = `vehicle_id, COALESCE(vin, ''), model, price, condition, created_at, updated_at`
+
+// scanVehicle scans a row selected with vehicleColumns into a vehicle.
+func scanVehicle(row pgx.Row) (*domain.Vehicle, error) {
+	var vehicle domain.Vehicle
+	err := row.Scan(
+		&vehicle.VehicleID, &vehicle.VIN, &vehicle.Model, &vehicle.Price, &vehicle.Condition,
+		&vehicle.CreatedAt, &vehicle.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &vehicle, nil
+}
+
+// ListVehicles retrieves the vehicles ordered by vehicle_id, optionally only those in the condition.
+func (r *Repository) ListVehicles(ctx context.Context, condition string) ([]*domain.Vehicle, error) {
+	query := `
+		SELECT ` + vehicleColumns + `
+		FROM vehicles
+		WHERE $1 = '' OR condition = $1
+		ORDER BY vehicle_id`
+
+	return r.queryVehicles(ctx, query, condition)
+}
+
+// ListVehiclesByIDs retrieves the vehicles with the IDs ordered by vehicle_id. Unknown IDs are skipped.
+func (r *Repository) ListVehiclesByIDs(ctx context.Context, vehicleIDs []int) ([]*domain.Vehicle, error) {
+	query := `
+		SELECT ` + vehicleColumns + `
+		FROM vehicles
+		WHERE vehicle_id = ANY($1)
+		ORDER BY vehicle_id`
+
+	return r.queryVehicles(ctx, query, vehicleIDs)
+}
+
+// queryVehicles runs a query selecting vehicleColumns and scans the vehicles.
+func (r *Repository) queryVehicles(ctx context.Context, query string, args ...any) ([]*domain.Vehicle, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vehicles: %w", err)
+	}
+	defer rows.Close()
+
+	var vehicles []*domain.Vehicle
+	for rows.Next() {
+		vehicle, err := scanVehicle(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan vehicle: %w", err)
+		}
+		vehicles = append(vehicles, vehicle)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating vehicles: %w", err)
+	}
+
+	return vehicles, nil
+}
+
+// GetVehicle retrieves a vehicle by its ID.
+func (r *Repository) GetVehicle(ctx context.Context, vehicleID int) (*domain.Vehicle, error) {
+	query := `SELECT ` + vehicleColumns + ` FROM vehicles WHERE vehicle_id = $1`
+
+	vehicle, err := scanVehicle(r.db.QueryRow(ctx, query, vehicleID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get vehicle: %w", err)
+	}
+	return vehicle, nil
+}
+
+// CreateVehicle adds a vehicle. ErrConflict is returned if the vehicle_id already exists;
+// a duplicate VIN is reported as a unique constraint violation.
+func (r *Repository) CreateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error) {
+	query := `
+		INSERT INTO vehicles (vehicle_id, vin, model, price, condition, created_at, updated_at)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (vehicle_id) DO NOTHING
+		RETURNING ` + vehicleColumns
+
+	created, err := scanVehicle(r.db.QueryRow(ctx, query,
+		vehicle.VehicleID, vehicle.VIN, vehicle.Model, vehicle.Price, vehicle.Condition))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to create vehicle: %w", wrapConstraintError(err))
+	}
+
+	return created, nil
+}
+
+// UpdateVehicle updates the details of a vehicle.
+func (r *Repository) UpdateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error) {
+	query := `
+		UPDATE vehicles
+		SET vin = NULLIF($2, ''), model = $3, price = $4, condition = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE vehicle_id = $1
+		RETURNING ` + vehicleColumns
+
+	updated, err := scanVehicle(r.db.QueryRow(ctx, query,
+		vehicle.VehicleID, vehicle.VIN, vehicle.Model, vehicle.Price, vehicle.Condition))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update vehicle: %w", wrapConstraintError(err))
+	}
+
+	return updated, nil
+}
+
+// DeleteVehicle removes a vehicle. ErrConflict is returned if orders reference the vehicle.
+func (r *Repository) DeleteVehicle(ctx context.Context, vehicleID int) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM vehicles WHERE vehicle_id = $1`, vehicleID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to delete vehicle: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cliring/internal/domain"
+)
+
+// CreateWebhookSubscription stores a webhook subscription.
+func (r *Repository) CreateWebhookSubscription(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	query := `
+		INSERT INTO webhook_subscriptions (url, events, secret, created_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		RETURNING subscription_id, url, events, secret, created_at`
+
+	var created domain.WebhookSubscription
+	err := r.db.QueryRow(ctx, query, subscription.URL, subscription.Events, subscription.Secret).Scan(
+		&created.SubscriptionID, &created.URL, &created.Events, &created.Secret, &created.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", wrapConstraintError(err))
+	}
+	return &created, nil
+}
+
+// ListWebhookSubscriptions retrieves all webhook subscriptions without their secrets.
+func (r *Repository) ListWebhookSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	query := `
+		SELECT subscription_id, url, events, created_at
+		FROM webhook_subscriptions
+		ORDER BY subscription_id`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []*domain.WebhookSubscription
+	for rows.Next() {
+		var subscription domain.WebhookSubscription
+		err := rows.Scan(&subscription.SubscriptionID, &subscription.URL, &subscription.Events, &subscription.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, &subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// DeleteWebhookSubscription deletes a webhook subscription together with its undelivered events.
+func (r *Repository) DeleteWebhookSubscription(ctx context.Context, subscriptionID int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE subscription_id = $1`, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// EnqueueWebhookEvent queues the event for delivery to every subscription to it.
+func (r *Repository) EnqueueWebhookEvent(ctx context.Context, event string, payload []byte) error {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event, payload, status, next_attempt_at, created_at)
+		SELECT subscription_id, $1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+		FROM webhook_subscriptions
+		WHERE $1 = ANY(events)`
+
+	if _, err := r.db.Exec(ctx, query, event, string(payload), domain.WebhookDeliveryPending); err != nil {
+		return fmt.Errorf("failed to enqueue webhook event: %w", err)
+	}
+	return nil
+}
+
+// ListDueWebhookDeliveries retrieves up to limit pending deliveries whose next attempt is due, oldest first.
+func (r *Repository) ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT d.delivery_id, d.subscription_id, s.url, s.secret, d.event, d.payload::text, d.attempts
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.subscription_id = d.subscription_id
+		WHERE d.status = $1 AND d.next_attempt_at <= $2
+		ORDER BY d.delivery_id
+		LIMIT $3`
+
+	rows, err := r.db.Query(ctx, query, domain.WebhookDeliveryPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		var delivery domain.WebhookDelivery
+		var payload string
+		err := rows.Scan(&delivery.DeliveryID, &delivery.SubscriptionID, &delivery.URL, &delivery.Secret,
+			&delivery.Event, &payload, &delivery.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		delivery.Payload = []byte(payload)
+		deliveries = append(deliveries, &delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// MarkWebhookDelivered records a successful delivery.
+func (r *Repository) MarkWebhookDelivered(ctx context.Context, deliveryID int64) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = attempts + 1, last_error = NULL, delivered_at = CURRENT_TIMESTAMP
+		WHERE delivery_id = $2`
+
+	if _, err := r.db.Exec(ctx, query, domain.WebhookDeliveryDelivered, deliveryID); err != nil {
+		return fmt.Errorf("failed to mark webhook delivered: %w", err)
+	}
+	return nil
+}
+
+// MarkWebhookAttemptFailed records a failed delivery attempt. The delivery is retried at nextAttemptAt;
+// without nextAttemptAt it is marked failed.
+func (r *Repository) MarkWebhookAttemptFailed(ctx context.Context, deliveryID int64, lastError string, nextAttemptAt *time.Time) error {
+	status := domain.WebhookDeliveryPending
+	if nextAttemptAt == nil {
+		status = domain.WebhookDeliveryFailed
+	}
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = attempts + 1, last_error = left($2, 500), next_attempt_at = COALESCE($3, next_attempt_at)
+		WHERE delivery_id = $4`
+
+	if _, err := r.db.Exec(ctx, query, status, lastError, nextAttemptAt, deliveryID); err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"cliring/internal/domain"
+)
+
+// hasRole reports whether the user performing the request has the role. Administrators have every role.
+func hasRole(ctx context.Context, role string) bool {
+	roles, _ := ctx.Value(domain.RolesKey{}).([]string)
+	return slices.Contains(roles, role) || slices.Contains(roles, domain.RoleAdmin)
+}
+
+// requireRole reports ErrForbidden unless the user performing the request has one of the roles.
+func requireRole(ctx context.Context, roles ...string) error {
+	for _, role := range roles {
+		if hasRole(ctx, role) {
+			return nil
+		}
+	}
+	return fmt.Errorf("role %s is required: %w", strings.Join(roles, " or "), ErrForbidden)
+}
+
+// clientOnly reports whether the user performing the request is a client without staff roles.
+func clientOnly(ctx context.Context) bool {
+	if !hasRole(ctx, domain.RoleClient) {
+		return false
+	}
+	for _, role := range []string{domain.RoleManager, domain.RoleFinance, domain.RoleAdmin} {
+		if hasRole(ctx, role) {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenClient returns the client of the token (JWT client_id claim) of the user performing the request.
+func tokenClient(ctx context.Context) (int, bool) {
+	clientID, ok := ctx.Value(domain.ClientClaimKey{}).(int)
+	return clientID, ok
+}
+
+// inClientScope reports whether the user of the request may work with the client's deals.
+// Staff users without a client in the token work with all clients; a client role without a client
+// in the token works with none.
+func inClientScope(ctx context.Context, clientID int) bool {
+	tokenClientID, ok := tokenClient(ctx)
+	if !ok {
+		return !clientOnly(ctx)
+	}
+	return tokenClientID == clientID
+}
+
+// checkClientAccess limits a client to its own orders: the client_id of the request must be the client
+// of the token. A client role without a client in the token reads no orders; staff users read the orders
+// of any client.
+func checkClientAccess(ctx context.Context, clientID int) error {
+	tokenClientID, ok := tokenClient(ctx)
+	if !ok && !clientOnly(ctx) {
+		return nil
+	}
+	if tokenClientID != clientID {
+		return fmt.Errorf("client %d cannot access orders of client %d: %w", tokenClientID, clientID, ErrForbidden)
+	}
+	return nil
+}
+
+// denyClientWrite reports ErrForbidden for clients: they only read their deals, orders and settlements.
+func denyClientWrite(ctx context.Context) error {
+	if clientOnly(ctx) {
+		return fmt.Errorf("clients have read-only access: %w", ErrForbidden)
+	}
+	return nil
+}
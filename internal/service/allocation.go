@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// ExecuteMonetarySettlements executes the netting result of a deal: every payable (positive) net position
+// is stored as an executed settlement with its ledger entries and its amount is allocated back to the deal's
+// outstanding orders using the configured allocation strategy. The deal is locked from netting until the result is persisted.
+func (s *Service) ExecuteMonetarySettlements(ctx context.Context, dealID int) ([]*domain.MonetarySettlement, error) {
+	if err := requireRole(ctx, domain.RoleFinance); err != nil {
+		return nil, err
+	}
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+
+	// Расчёт и сохранение результата выполняются с контекстом блокировки, на её соединении
+	ctx, unlock, err := s.repo.LockDeal(ctx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Verify deal exists
+	_, err = s.repo.GetDeal(ctx, dealID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+
+	// Зафиксированный при входе в клиринг результат исполняется по одному расчёту
+	pending, err := s.repo.CountStoredMonetarySettlements(ctx, dealID, domain.StatusPending)
+	if err != nil {
+		return nil, err
+	}
+	if pending > 0 {
+		return nil, fmt.Errorf("deal has %d pending settlements fixed for clearing, execute them one by one: %w", pending, ErrConflict)
+	}
+
+	settlements, _, err := s.ListMonetarySettlements(ctx, dealID, nil, domain.SettlementModeNet)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, settlement := range settlements {
+		if settlement.Amount > 0 && s.requiresApproval(settlement.Amount) {
+			return nil, fmt.Errorf("settlement of %s exceeds the approval threshold, move the deal to clearing to have it approved: %w",
+				settlement.Amount, ErrConflict)
+		}
+	}
+
+	orders, err := s.repo.ListOrdersByDeals(ctx, dealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	var executed []*domain.MonetarySettlement
+	var runNumber int
+	for _, settlement := range settlements {
+		// Отрицательная позиция - участнику должны, платёж по ней не исполняется
+		if settlement.Amount <= 0 {
+			continue
+		}
+
+		// Номер запуска выдаётся только если есть что исполнять
+		if runNumber == 0 {
+			runNumber, err = s.repo.NextSettlementRun(ctx, dealID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		settlement.RunNumber = runNumber
+
+		allocations, err := s.allocate(settlement.Amount, orders)
+		if err != nil {
+			return nil, err
+		}
+
+		executedSettlement, err := s.repo.ExecuteMonetarySettlement(ctx, settlement, allocations, ledgerEntries(settlement))
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute monetary settlement: %w", err)
+		}
+		executedSettlement.VATBreakdown = settlement.VATBreakdown
+
+		// Учитываем распределение для следующего расчёта в рамках сделки
+		for _, allocation := range allocations {
+			for _, order := range orders {
+				if order.OrderID == allocation.OrderID {
+					order.PaidAmount += allocation.Amount
+					order.OutstandingAmount -= allocation.Amount
+				}
+			}
+		}
+		executed = append(executed, executedSettlement)
+	}
+	// Расчёты пересчитаны по актуальным заказам
+	if err := s.repo.ClearSettlementsRecompute(ctx, dealID); err != nil {
+		return nil, err
+	}
+	if err := s.applySettlementCurrency(ctx, executed...); err != nil {
+		return nil, err
+	}
+	if err := s.applySettlementDueDates(ctx, time.Now(), executed...); err != nil {
+		return nil, err
+	}
+	// Расчет создается сразу исполненным, подписчики получают оба события
+	s.publishSettlementEvent(ctx, domain.EventSettlementCreated, executed...)
+	s.publishSettlementEvent(ctx, domain.EventSettlementExecuted, executed...)
+
+	return executed, nil
+}
+
+// allocate distributes amount across orders with an outstanding balance.
+func (s *Service) allocate(amount domain.Money, orders []*domain.Order) ([]*domain.OrderAllocation, error) {
+	var outstanding []*domain.Order
+	for _, order := range orders {
+		if order.Status != domain.StatusCancelled && !order.HeldForReview() && !order.AwaitingCreditContract() &&
+			order.OutstandingAmount > 0 {
+			outstanding = append(outstanding, order)
+		}
+	}
+	// Сначала самые старые заказы
+	sort.SliceStable(outstanding, func(i, j int) bool {
+		return outstanding[i].CreatedAt.Before(outstanding[j].CreatedAt)
+	})
+
+	switch s.cfg.Settlement.AllocationStrategy {
+	case domain.AllocationOldestFirst, "":
+		return allocateOldestFirst(amount, outstanding), nil
+	case domain.AllocationProRata:
+		return allocateProRata(amount, outstanding), nil
+	default:
+		return nil, fmt.Errorf("unknown allocation strategy %q", s.cfg.Settlement.AllocationStrategy)
+	}
+}
+
+// allocateOldestFirst covers orders one by one starting from the oldest.
+func allocateOldestFirst(amount domain.Money, orders []*domain.Order) []*domain.OrderAllocation {
+	var allocations []*domain.OrderAllocation
+	remaining := amount
+	for _, order := range orders {
+		if remaining <= 0 {
+			break
+		}
+		part := min(remaining, order.OutstandingAmount)
+		if part <= 0 {
+			continue
+		}
+		allocations = append(allocations, &domain.OrderAllocation{OrderID: order.OrderID, Amount: part})
+		remaining -= part
+	}
+	return allocations
+}
+
+// allocateProRata splits amount proportionally to the outstanding balance of each order.
+// The rounding remainder is assigned to the last order.
+func allocateProRata(amount domain.Money, orders []*domain.Order) []*domain.OrderAllocation {
+	var totalOutstanding domain.Money
+	for _, order := range orders {
+		totalOutstanding += order.OutstandingAmount
+	}
+	if totalOutstanding <= 0 {
+		return nil
+	}
+	amount = min(amount, totalOutstanding)
+
+	var allocations []*domain.OrderAllocation
+	remaining := amount
+	for i, order := range orders {
+		part := amount.MulRatio(order.OutstandingAmount, totalOutstanding)
+		if i == len(orders)-1 {
+			part = min(remaining, order.OutstandingAmount)
+		}
+		if part <= 0 {
+			continue
+		}
+		allocations = append(allocations, &domain.OrderAllocation{OrderID: order.OrderID, Amount: part})
+		remaining -= part
+	}
+	return allocations
+}
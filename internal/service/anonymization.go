@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// anonymizationTokenTTL is how long a confirmation token for client anonymization stays valid.
+const anonymizationTokenTTL = 15 * time.Minute
+
+// RequestClientAnonymization issues a confirmation token that must be sent back to anonymize the client.
+func (s *Service) RequestClientAnonymization(ctx context.Context, clientID int) (*domain.AnonymizationConfirmation, error) {
+	if clientID <= 0 {
+		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+
+	anonymizedAt, err := s.repo.GetClientAnonymizedAt(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("client not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	if anonymizedAt != nil {
+		return nil, fmt.Errorf("client already anonymized at %s: %w", anonymizedAt.Format(time.RFC3339), ErrConflict)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+	expiresAt := time.Now().Add(anonymizationTokenTTL)
+
+	// В базе хранится только хеш токена
+	if err := s.repo.CreateAnonymizationToken(ctx, clientID, hashToken(token), expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to create confirmation token: %w", err)
+	}
+
+	return &domain.AnonymizationConfirmation{
+		ClientID:          clientID,
+		ConfirmationToken: token,
+		ExpiresAt:         expiresAt,
+	}, nil
+}
+
+// ConfirmClientAnonymization irreversibly scrubs the personal data of the client
+// after checking the confirmation token. Financial data is preserved.
+func (s *Service) ConfirmClientAnonymization(ctx context.Context, clientID int, token string) (*domain.AnonymizationResult, error) {
+	if clientID <= 0 {
+		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+
+	result, err := s.repo.AnonymizeClient(ctx, clientID, hashToken(token))
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrUnauthorized):
+			return nil, fmt.Errorf("invalid or expired confirmation token: %w", ErrInvalidInput)
+		case errors.Is(err, repository.ErrConflict):
+			return nil, fmt.Errorf("client already anonymized: %w", ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to anonymize client: %w", err)
+	}
+
+	return result, nil
+}
+
+// hashToken returns the hex-encoded SHA-256 hash of a token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
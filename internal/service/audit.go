@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"cliring/internal/domain"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// auditChange attaches the changed entity and its states before and after the change to the audit entry
+// of the request. Nil states mean the entity did not exist, and a zero entityID stands for a change of several
+// records. Outside of an audited request it does nothing.
+// The states are encoded at once, so later changes of the values do not leak into the log.
+func auditChange(ctx context.Context, entity string, entityID int, before, after any) {
+	entry, ok := ctx.Value(domain.AuditKey{}).(*domain.AuditEntry)
+	if !ok || entry == nil {
+		return
+	}
+	entry.Entity = entity
+	if entityID > 0 {
+		entry.EntityID = strconv.Itoa(entityID)
+	}
+	entry.Before = auditState(before)
+	entry.After = auditState(after)
+}
+
+// auditState encodes an entity state for the audit log.
+func auditState(state any) any {
+	if state == nil {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to encode audit state")
+		return nil
+	}
+	return json.RawMessage(data)
+}
+
+// RecordAudit appends an entry to the audit log.
+func (s *Service) RecordAudit(ctx context.Context, entry *domain.AuditEntry) error {
+	if entry.Actor == "" {
+		entry.Actor = domain.ActorSystem
+	}
+	if err := s.repo.InsertAuditEntry(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListAuditLog returns a page of the audit log matching the filter, newest entries first. Administrators only.
+func (s *Service) ListAuditLog(ctx context.Context, filter domain.AuditFilter, page, limit int) (*domain.AuditList, error) {
+	if err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if page == 0 {
+		page = 1
+	}
+	if page < 0 {
+		return nil, fmt.Errorf("page must be positive: %w", ErrInvalidInput)
+	}
+	if limit == 0 {
+		limit = defaultAuditLimit
+	}
+	if limit < 0 || limit > maxAuditLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d: %w", maxAuditLimit, ErrInvalidInput)
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, fmt.Errorf("from must be before to: %w", ErrInvalidInput)
+	}
+
+	entries, total, err := s.repo.ListAuditEntries(ctx, filter, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	if entries == nil {
+		entries = []*domain.AuditEntry{}
+	}
+
+	return &domain.AuditList{
+		Entries: entries,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+		HasNext: page*limit < total,
+	}, nil
+}
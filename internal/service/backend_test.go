@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"cliring/config"
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+	"cliring/internal/repository/memory"
+	"cliring/internal/service"
+	"cliring/pkg/postgres"
+)
+
+// backend is a storage the service tests run against, with the context of a fresh tenant.
+type backend struct {
+	name string
+	repo service.Repository
+	ctx  context.Context
+}
+
+// backends returns the in-memory repository and, when TEST_POSTGRES_DSN points to a throwaway database,
+// the Postgres repository. Every test gets its own Postgres tenant, so tests do not see each other's data.
+func backends(t *testing.T) []backend {
+	t.Helper()
+	list := []backend{{name: "memory", repo: memory.New(), ctx: context.Background()}}
+
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		return list
+	}
+	cfg := testConfig(t)
+	cfg.Postgres.DSN = dsn
+	cfg.Postgres.MigrationsDir = "../../migrations"
+	db := postgres.New(cfg).WithTenantResolver(func(ctx context.Context) string {
+		tenant, _ := ctx.Value(domain.TenantKey{}).(string)
+		return tenant
+	})
+	if err := db.Open(context.Background()); err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close(context.Background()) })
+
+	repo := repository.NewRepository(db)
+	tenantID := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := repo.ProvisionTenant(context.Background(), tenantID, t.Name()); err != nil {
+		t.Fatalf("provision tenant: %v", err)
+	}
+	ctx := context.WithValue(context.Background(), domain.TenantKey{}, tenantID)
+	return append(list, backend{name: "postgres", repo: repo, ctx: ctx})
+}
+
+// testConfig returns the configuration with the defaults of the environment.
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.New()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	return cfg
+}
+
+// asAdmin returns ctx of an administrator.
+func asAdmin(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, domain.ActorKey{}, "admin")
+	return context.WithValue(ctx, domain.RolesKey{}, []string{domain.RoleAdmin})
+}
+
+// fixture is the reference data of a deal: a dealership with a manager, a client and two banks.
+type fixture struct {
+	dealershipID, managerID, clientID int
+	bankIDs                           []int
+}
+
+// newFixture creates the reference data of a deal.
+func newFixture(t *testing.T, ctx context.Context, svc *service.Service) fixture {
+	t.Helper()
+	f := fixture{dealershipID: 1, managerID: 1, clientID: 1, bankIDs: []int{1, 2}}
+
+	if _, err := svc.CreateDealership(ctx, domain.Dealership{DealershipID: f.dealershipID, Name: "Рольф Юг", LegalEntity: "ООО Рольф", Active: true}); err != nil {
+		t.Fatalf("create dealership: %v", err)
+	}
+	if _, err := svc.CreateManager(ctx, domain.Manager{ManagerID: f.managerID, DealershipID: f.dealershipID, FullName: "Иванов Иван", Active: true}); err != nil {
+		t.Fatalf("create manager: %v", err)
+	}
+	if _, err := svc.CreateClient(ctx, domain.Client{ClientID: f.clientID, FullName: "Петров Петр"}); err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	for _, bankID := range f.bankIDs {
+		if _, err := svc.CreateBank(ctx, domain.Bank{BankID: bankID, Name: fmt.Sprintf("Банк %d", bankID), Active: true}); err != nil {
+			t.Fatalf("create bank %d: %v", bankID, err)
+		}
+	}
+	return f
+}
+
+// newActiveDeal creates a deal of the fixture and activates it.
+func newActiveDeal(t *testing.T, ctx context.Context, svc *service.Service, f fixture, dealID int) int {
+	t.Helper()
+	deal, err := svc.CreateDeal(ctx, domain.Deal{DealID: dealID, DealershipID: f.dealershipID, ManagerID: f.managerID, ClientID: f.clientID})
+	if err != nil {
+		t.Fatalf("create deal: %v", err)
+	}
+	if _, err := svc.TransitionDeal(ctx, deal.DealID, domain.DealTransition{Status: domain.DealStatusActive}); err != nil {
+		t.Fatalf("activate deal: %v", err)
+	}
+	return deal.DealID
+}
+
+// newCreditContract concludes a credit contract of the deal with the bank and returns its id.
+func newCreditContract(t *testing.T, ctx context.Context, svc *service.Service, dealID, bankID int, principal domain.Money) *int {
+	t.Helper()
+	contract, err := svc.CreateCreditContract(ctx, domain.CreditContract{
+		DealID:         dealID,
+		BankID:         bankID,
+		ContractNumber: fmt.Sprintf("КД-%d-%d", dealID, bankID),
+		Principal:      principal,
+		TermMonths:     36,
+		Rate:           12.5,
+	})
+	if err != nil {
+		t.Fatalf("create credit contract: %v", err)
+	}
+	return &contract.CreditContractID
+}
+
+// intPtr returns a pointer to v.
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+var (
+	// bicPattern matches a Russian bank identification code.
+	bicPattern = regexp.MustCompile(`^[0-9]{9}$`)
+	// correspondentAccountPattern matches a correspondent account number.
+	correspondentAccountPattern = regexp.MustCompile(`^[0-9]{20}$`)
+)
+
+// ListBanks returns the banks reference ordered by bank_id, optionally only the active banks.
+func (s *Service) ListBanks(ctx context.Context, activeOnly bool) ([]*domain.Bank, error) {
+	banks, err := s.repo.ListBanks(ctx, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list banks: %w", err)
+	}
+	if banks == nil {
+		banks = []*domain.Bank{}
+	}
+
+	return banks, nil
+}
+
+// GetBank returns a bank of the reference.
+func (s *Service) GetBank(ctx context.Context, bankID int) (*domain.Bank, error) {
+	if bankID <= 0 {
+		return nil, fmt.Errorf("invalid bank_id: %w", ErrInvalidInput)
+	}
+
+	bank, err := s.repo.GetBank(ctx, bankID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("bank not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get bank: %w", err)
+	}
+
+	return bank, nil
+}
+
+// CreateBank adds a bank to the reference. A new bank is active.
+func (s *Service) CreateBank(ctx context.Context, req domain.Bank) (*domain.Bank, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	req.Active = true
+	if err := validateBank(req); err != nil {
+		return nil, err
+	}
+
+	bank, err := s.repo.CreateBank(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("bank %d already exists: %w", req.BankID, ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to create bank: %w", err)
+	}
+
+	return bank, nil
+}
+
+// UpdateBank changes the details of a bank or deactivates it. Orders and settlements that already
+// reference a deactivated bank keep it.
+func (s *Service) UpdateBank(ctx context.Context, req domain.Bank) (*domain.Bank, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateBank(req); err != nil {
+		return nil, err
+	}
+
+	bank, err := s.repo.UpdateBank(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("bank not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to update bank: %w", err)
+	}
+
+	return bank, nil
+}
+
+// DeleteBank removes a bank that no order or settlement references.
+func (s *Service) DeleteBank(ctx context.Context, bankID int) error {
+	if err := denyClientWrite(ctx); err != nil {
+		return err
+	}
+	if bankID <= 0 {
+		return fmt.Errorf("invalid bank_id: %w", ErrInvalidInput)
+	}
+
+	if err := s.repo.DeleteBank(ctx, bankID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return fmt.Errorf("bank not found: %w", ErrNotFound)
+		case errors.Is(err, repository.ErrConflict):
+			return fmt.Errorf("bank %d is used by orders or settlements, deactivate it instead: %w", bankID, ErrConflict)
+		}
+		return fmt.Errorf("failed to delete bank: %w", err)
+	}
+
+	return nil
+}
+
+// validateBank checks a reference entry: a positive ID, a name that fits the column and well-formed details.
+func validateBank(bank domain.Bank) error {
+	if bank.BankID <= 0 {
+		return fmt.Errorf("invalid bank_id: %w", ErrInvalidInput)
+	}
+	name := strings.TrimSpace(bank.Name)
+	if name == "" {
+		return fmt.Errorf("name is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(name) > 255 {
+		return fmt.Errorf("name must not exceed 255 characters: %w", ErrInvalidInput)
+	}
+	if bank.BIC != "" && !bicPattern.MatchString(bank.BIC) {
+		return fmt.Errorf("bic must be 9 digits: %w", ErrInvalidInput)
+	}
+	if bank.CorrespondentAccount != "" && !correspondentAccountPattern.MatchString(bank.CorrespondentAccount) {
+		return fmt.Errorf("correspondent_account must be 20 digits: %w", ErrInvalidInput)
+	}
+	return nil
+}
+
+// validateOrderBank checks that the bank of an order is an active bank of the reference.
+// The bank the order already references is accepted even if it was deactivated since.
+func (s *Service) validateOrderBank(ctx context.Context, bankID, current *int) error {
+	if bankID == nil || (current != nil && *current == *bankID) {
+		return nil
+	}
+
+	bank, err := s.repo.GetBank(ctx, *bankID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("unknown bank_id %d: %w", *bankID, ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to get bank: %w", err)
+	}
+	if !bank.Active {
+		return fmt.Errorf("bank %d is inactive: %w", *bankID, ErrInvalidInput)
+	}
+	return nil
+}
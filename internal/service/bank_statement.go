@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/statement"
+)
+
+// maxStatementLines limits the number of lines of one bank statement import.
+const maxStatementLines = 1000
+
+// Sizes of the payer_name and purpose columns of bank statement lines.
+const (
+	maxPayerNameLength = 255
+	maxPurposeLength   = 500
+)
+
+// settlementReferencePattern finds the settlement number in a payment purpose,
+// e.g. "Взаиморасчеты по сделке №12345, расчет №7." produced by PaymentInstructions.
+var settlementReferencePattern = regexp.MustCompile(`(?i)расч[её]т\s*№\s*(\d+)`)
+
+// ImportBankStatement stores the incoming payments of a bank statement and matches them to pending
+// settlements: a matched payment executes its settlement with the payment reference of the line.
+// Lines imported before are skipped, lines without a matching settlement are reported as unmatched.
+func (s *Service) ImportBankStatement(ctx context.Context, req domain.BankStatementImport) (*domain.BankStatementReconciliation, error) {
+	if err := requireRole(ctx, domain.RoleFinance); err != nil {
+		return nil, err
+	}
+	if len(req.Lines) == 0 {
+		return nil, fmt.Errorf("lines are required: %w", ErrInvalidInput)
+	}
+	if len(req.Lines) > maxStatementLines {
+		return nil, fmt.Errorf("a statement must not exceed %d lines: %w", maxStatementLines, ErrInvalidInput)
+	}
+	currencies, err := s.currencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Все строки проверяются до загрузки, чтобы выписка не загружалась частично из-за ошибки в данных
+	for i, line := range req.Lines {
+		if err := validateStatementLine(line, currencies); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+
+	reconciliation := &domain.BankStatementReconciliation{
+		Matched:   []*domain.BankStatementLine{},
+		Unmatched: []*domain.BankStatementLine{},
+	}
+	for _, line := range req.Lines {
+		created, ok, err := s.repo.CreateBankStatementLine(ctx, line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import bank statement line: %w", err)
+		}
+		if !ok {
+			reconciliation.Duplicates++
+			continue
+		}
+
+		matched, err := s.matchStatementLine(ctx, created)
+		if err != nil {
+			return nil, err
+		}
+		if matched.Status == domain.StatementLineMatched {
+			reconciliation.Matched = append(reconciliation.Matched, matched)
+		} else {
+			reconciliation.Unmatched = append(reconciliation.Unmatched, matched)
+		}
+	}
+
+	return reconciliation, nil
+}
+
+// ImportBankStatementFile parses a bank statement file in a standard format (camt.053 or MT940) and imports
+// its incoming payments like ImportBankStatement. An empty format is detected from the file content.
+func (s *Service) ImportBankStatementFile(ctx context.Context, format, fileName string, size int64, r io.Reader) (*domain.BankStatementReconciliation, error) {
+	if err := s.validateUpload(fileName, size); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bank statement file: %w", err)
+	}
+
+	lines, err := statement.Parse(format, data)
+	if err != nil {
+		if errors.Is(err, statement.ErrUnknownFormat) {
+			return nil, fmt.Errorf("unsupported statement format %q: %w", format, ErrInvalidInput)
+		}
+		if errors.Is(err, statement.ErrMalformed) {
+			return nil, fmt.Errorf("%v: %w", err, ErrInvalidInput)
+		}
+		return nil, fmt.Errorf("failed to parse bank statement: %w", err)
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("statement contains no booked incoming payments: %w", ErrInvalidInput)
+	}
+	// Банки не ограничивают длину назначения платежа, поэтому длинные тексты обрезаются, а не отклоняются
+	for _, line := range lines {
+		line.PayerName = truncateRunes(line.PayerName, maxPayerNameLength)
+		line.Purpose = truncateRunes(line.Purpose, maxPurposeLength)
+	}
+
+	return s.ImportBankStatement(ctx, domain.BankStatementImport{Lines: lines})
+}
+
+// truncateRunes shortens s to at most n characters.
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// validateStatementLine normalizes and validates a bank statement line.
+func validateStatementLine(line *domain.BankStatementLine, currencies map[string]*domain.Currency) error {
+	if line == nil {
+		return fmt.Errorf("line is required: %w", ErrInvalidInput)
+	}
+	line.PaymentReference = strings.TrimSpace(line.PaymentReference)
+	if line.PaymentReference == "" {
+		return fmt.Errorf("payment_reference is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(line.PaymentReference) > maxPaymentReferenceLength {
+		return fmt.Errorf("payment_reference must not exceed %d characters: %w", maxPaymentReferenceLength, ErrInvalidInput)
+	}
+	if line.Amount <= 0 {
+		return fmt.Errorf("amount must be positive: %w", ErrInvalidInput)
+	}
+	if line.CurrencyCode == "" {
+		line.CurrencyCode = domain.DefaultCurrencyCode
+	}
+	if _, ok := currencies[line.CurrencyCode]; !ok {
+		return fmt.Errorf("unknown currency_code %s: %w", line.CurrencyCode, ErrInvalidInput)
+	}
+	if _, err := time.Parse(domain.DateLayout, line.ValueDate); err != nil {
+		return fmt.Errorf("invalid value_date %q: %w", line.ValueDate, ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(line.PayerName) > maxPayerNameLength {
+		return fmt.Errorf("payer_name must not exceed %d characters: %w", maxPayerNameLength, ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(line.Purpose) > maxPurposeLength {
+		return fmt.Errorf("purpose must not exceed %d characters: %w", maxPurposeLength, ErrInvalidInput)
+	}
+	return nil
+}
+
+// matchStatementLine links a stored bank statement line to the pending settlement it pays and executes
+// the settlement. Candidates are the pending settlements of the line amount and currency; the settlement
+// number in the purpose decides between several candidates. The line stays unmatched if no candidate
+// or several candidates without a reference remain.
+func (s *Service) matchStatementLine(ctx context.Context, line *domain.BankStatementLine) (*domain.BankStatementLine, error) {
+	candidates, err := s.repo.ListPendingSettlementsByAmount(ctx, line.Amount, line.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var settlement *domain.MonetarySettlement
+	if match := settlementReferencePattern.FindStringSubmatch(line.Purpose); match != nil {
+		settlementID, _ := strconv.Atoi(match[1])
+		for _, candidate := range candidates {
+			if candidate.MonetarySettlementID == settlementID {
+				settlement = candidate
+				break
+			}
+		}
+	}
+	if settlement == nil && len(candidates) == 1 {
+		settlement = candidates[0]
+	}
+	if settlement == nil {
+		return line, nil
+	}
+
+	_, err = s.executeMonetarySettlement(ctx, settlement.MonetarySettlementID, domain.MonetarySettlementExecution{
+		PaymentReference: line.PaymentReference,
+	})
+	if err != nil {
+		// Расчет исполнен или отменен параллельно, строка остается несопоставленной
+		if errors.Is(err, ErrConflict) {
+			return line, nil
+		}
+		return nil, err
+	}
+
+	return s.repo.MatchBankStatementLine(ctx, line.LineID, settlement.MonetarySettlementID)
+}
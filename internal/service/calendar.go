@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// businessCalendar answers whether a date is a business day: weekends and public holidays are
+// days off, unless the calendar marks the date as a transferred working day.
+type businessCalendar struct {
+	days map[string]*domain.CalendarDay
+}
+
+// loadCalendar loads calendar exceptions for a year starting at from.
+func (s *Service) loadCalendar(ctx context.Context, from time.Time) (*businessCalendar, error) {
+	days, err := s.repo.ListCalendarDays(ctx, from, from.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load business calendar: %w", err)
+	}
+
+	calendar := &businessCalendar{days: make(map[string]*domain.CalendarDay, len(days))}
+	for _, day := range days {
+		calendar.days[day.Date] = day
+	}
+	return calendar, nil
+}
+
+// isBusinessDay reports whether t is a business day.
+func (c *businessCalendar) isBusinessDay(t time.Time) bool {
+	if day, ok := c.days[t.Format(domain.DateLayout)]; ok {
+		return day.IsWorkingDay
+	}
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+// addBusinessDays returns the date n business days after t.
+func (c *businessCalendar) addBusinessDays(t time.Time, n int) time.Time {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for n > 0 {
+		date = date.AddDate(0, 0, 1)
+		if c.isBusinessDay(date) {
+			n--
+		}
+	}
+	return date
+}
+
+// overdueFrom returns the first day a settlement due on dueDate is overdue.
+func (c *businessCalendar) overdueFrom(dueDate time.Time, graceDays int) time.Time {
+	// Просрочка наступает на следующий день после окончания льготного периода
+	return c.addBusinessDays(dueDate, graceDays).AddDate(0, 0, 1)
+}
+
+// applySettlementDueDates sets due dates of settlements without one and flags pending
+// settlements that are past their due date plus the grace period as of now.
+func (s *Service) applySettlementDueDates(ctx context.Context, now time.Time, settlements ...*domain.MonetarySettlement) error {
+	if len(settlements) == 0 {
+		return nil
+	}
+
+	from := settlements[0].CreatedAt
+	for _, settlement := range settlements {
+		if settlement.CreatedAt.Before(from) {
+			from = settlement.CreatedAt
+		}
+	}
+	calendar, err := s.loadCalendar(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	for _, settlement := range settlements {
+		if settlement.DueDate == "" {
+			settlement.DueDate = calendar.addBusinessDays(settlement.CreatedAt, s.cfg.Settlement.PaymentTermDays).
+				Format(domain.DateLayout)
+		}
+
+		dueDate, err := time.ParseInLocation(domain.DateLayout, settlement.DueDate, now.Location())
+		if err != nil {
+			return fmt.Errorf("invalid due_date %q: %w", settlement.DueDate, err)
+		}
+		overdueFrom := calendar.overdueFrom(dueDate, s.cfg.Settlement.GraceDays)
+		settlement.Overdue = settlement.Status == domain.StatusPending && !now.Before(overdueFrom)
+	}
+	return nil
+}
+
+// ListCalendarDays retrieves business calendar exceptions of a year.
+func (s *Service) ListCalendarDays(ctx context.Context, year int) ([]*domain.CalendarDay, error) {
+	if year < 1 {
+		return nil, fmt.Errorf("invalid year: %w", ErrInvalidInput)
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	days, err := s.repo.ListCalendarDays(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar days: %w", err)
+	}
+
+	return days, nil
+}
+
+// CreateCalendarDay adds a holiday or a transferred working day to the business calendar.
+func (s *Service) CreateCalendarDay(ctx context.Context, req domain.CalendarDay) (*domain.CalendarDay, error) {
+	if err := validateCalendarDay(req); err != nil {
+		return nil, err
+	}
+
+	day, err := s.repo.CreateCalendarDay(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("calendar day %s already exists: %w", req.Date, ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to create calendar day: %w", err)
+	}
+
+	return day, nil
+}
+
+// UpdateCalendarDay updates a business calendar exception.
+func (s *Service) UpdateCalendarDay(ctx context.Context, req domain.CalendarDay) (*domain.CalendarDay, error) {
+	if err := validateCalendarDay(req); err != nil {
+		return nil, err
+	}
+
+	day, err := s.repo.UpdateCalendarDay(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("calendar day not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to update calendar day: %w", err)
+	}
+
+	return day, nil
+}
+
+// DeleteCalendarDay removes a business calendar exception.
+func (s *Service) DeleteCalendarDay(ctx context.Context, date string) error {
+	if _, err := time.Parse(domain.DateLayout, date); err != nil {
+		return fmt.Errorf("invalid date, expected YYYY-MM-DD: %w", ErrInvalidInput)
+	}
+
+	if err := s.repo.DeleteCalendarDay(ctx, date); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("calendar day not found: %w", ErrNotFound)
+		}
+		return fmt.Errorf("failed to delete calendar day: %w", err)
+	}
+
+	return nil
+}
+
+// validateCalendarDay checks a business calendar exception.
+func validateCalendarDay(day domain.CalendarDay) error {
+	if _, err := time.Parse(domain.DateLayout, day.Date); err != nil {
+		return fmt.Errorf("invalid date, expected YYYY-MM-DD: %w", ErrInvalidInput)
+	}
+	if strings.TrimSpace(day.Name) == "" {
+		return fmt.Errorf("name is required: %w", ErrInvalidInput)
+	}
+	return nil
+}
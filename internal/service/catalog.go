@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// PriceCatalog looks up vehicle list prices. The repository implementation reads the
+// vehicles table; an external catalog can be plugged in with WithPriceCatalog.
+type PriceCatalog interface {
+	// GetListPrice returns the list price of a vehicle or repository.ErrNotFound.
+	GetListPrice(ctx context.Context, vehicleID int) (domain.Money, error)
+}
+
+// WithPriceCatalog replaces the catalog used to validate purchase order amounts.
+func (s *Service) WithPriceCatalog(catalog PriceCatalog) *Service {
+	s.catalog = catalog
+	return s
+}
+
+// checkOrderPrice validates the amount of a purchase order against the catalog list price.
+// A violation is returned as a warning in warn mode and as ErrInvalidInput in error mode.
+func (s *Service) checkOrderPrice(ctx context.Context, order *domain.Order) (*domain.PriceViolation, error) {
+	enforcement := s.cfg.Catalog.PriceEnforcement
+	if enforcement == domain.PriceEnforcementOff || order.OrderTypeID != domain.OrderTypePurchase || order.VehicleID == nil {
+		return nil, nil
+	}
+
+	listPrice, err := s.catalog.GetListPrice(ctx, *order.VehicleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("vehicle %d not found in catalog: %w", *order.VehicleID, ErrInvalidInput)
+		}
+		return nil, fmt.Errorf("failed to get list price: %w", err)
+	}
+
+	// Допустимый диапазон: прайсовая цена ± допустимая скидка
+	deviation := listPrice.Percent(s.cfg.Catalog.MaxDiscountPercent)
+	minAmount, maxAmount := listPrice-deviation, listPrice+deviation
+	if order.Amount >= minAmount && order.Amount <= maxAmount {
+		return nil, nil
+	}
+
+	violation := &domain.PriceViolation{
+		VehicleID: *order.VehicleID,
+		ListPrice: listPrice,
+		MinAmount: minAmount,
+		MaxAmount: maxAmount,
+		Amount:    order.Amount,
+		Message: fmt.Sprintf("amount %s is outside the allowed range %s-%s for vehicle %d",
+			order.Amount, minAmount, maxAmount, *order.VehicleID),
+	}
+	if enforcement == domain.PriceEnforcementError {
+		return nil, fmt.Errorf("%s: %w", violation.Message, ErrInvalidInput)
+	}
+
+	return violation, nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"cliring/internal/domain"
+)
+
+// Change feed page size limits.
+const (
+	defaultChangeFeedLimit = 100
+	maxChangeFeedLimit     = 1000
+)
+
+// ListChanges returns change records of an entity after the since cursor.
+func (s *Service) ListChanges(ctx context.Context, entity string, since int64, limit int) (*domain.ChangeFeed, error) {
+	switch entity {
+	case domain.EntityDeals, domain.EntityOrders, domain.EntityMonetarySettlements:
+	default:
+		return nil, fmt.Errorf("unknown entity %q: %w", entity, ErrInvalidInput)
+	}
+	if since < 0 {
+		return nil, fmt.Errorf("invalid since cursor: %w", ErrInvalidInput)
+	}
+	if limit == 0 {
+		limit = defaultChangeFeedLimit
+	}
+	if limit < 0 || limit > maxChangeFeedLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d: %w", maxChangeFeedLimit, ErrInvalidInput)
+	}
+
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	changes, err := s.repo.ListChanges(ctx, entity, since, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changes: %w", err)
+	}
+
+	feed := &domain.ChangeFeed{Changes: changes, NextCursor: since}
+	if len(changes) > limit {
+		feed.Changes = changes[:limit]
+		feed.HasMore = true
+	}
+	if len(feed.Changes) > 0 {
+		feed.NextCursor = feed.Changes[len(feed.Changes)-1].ChangeID
+	}
+
+	return feed, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/netting"
+)
+
+// ClearingCycle nets all open deals of a client or of a dealership together. Every client is a separate
+// participant, so a dealership cycle does not offset the obligations of different clients. Orders are
+// netted per currency. The consolidated settlements are computed on the fly and are not stored.
+func (s *Service) ClearingCycle(ctx context.Context, clientID, dealershipID *int) (*domain.ClearingCycle, error) {
+	// Клиент получает клиринг только по своим сделкам
+	if tokenClientID, ok := tokenClient(ctx); ok {
+		if dealershipID != nil || (clientID != nil && *clientID != tokenClientID) {
+			return nil, fmt.Errorf("client %d can only clear its own deals: %w", tokenClientID, ErrForbidden)
+		}
+		clientID = &tokenClientID
+	} else if clientOnly(ctx) {
+		return nil, fmt.Errorf("client role without a client in the token cannot clear deals: %w", ErrForbidden)
+	}
+	if (clientID == nil) == (dealershipID == nil) {
+		return nil, fmt.Errorf("exactly one of client_id and dealership_id is required: %w", ErrInvalidInput)
+	}
+	if clientID != nil && *clientID <= 0 {
+		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+	if dealershipID != nil && *dealershipID <= 0 {
+		return nil, fmt.Errorf("invalid dealership_id: %w", ErrInvalidInput)
+	}
+
+	deals, err := s.repo.ListOpenDeals(ctx, clientID, dealershipID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open deals: %w", err)
+	}
+
+	cycle := &domain.ClearingCycle{
+		ClientID:     clientID,
+		DealershipID: dealershipID,
+		DealIDs:      []int{},
+		Settlements:  []*domain.MonetarySettlement{},
+		Legs:         []*domain.SettlementLeg{},
+	}
+	if len(deals) == 0 {
+		return cycle, nil
+	}
+
+	dealClients := make(map[int]int, len(deals))
+	for _, deal := range deals {
+		cycle.DealIDs = append(cycle.DealIDs, deal.DealID)
+		dealClients[deal.DealID] = deal.ClientID
+	}
+
+	orders, err := s.repo.ListOrdersOfDeals(ctx, cycle.DealIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	if err := s.applyOrderCurrency(ctx, orders...); err != nil {
+		return nil, err
+	}
+	rules, err := s.nettingRules(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Позиции в разных валютах друг друга не погашают
+	byCurrency := map[string][]*domain.Order{}
+	var currencies []string
+	for _, order := range orders {
+		if _, ok := byCurrency[order.CurrencyCode]; !ok {
+			currencies = append(currencies, order.CurrencyCode)
+		}
+		byCurrency[order.CurrencyCode] = append(byCurrency[order.CurrencyCode], order)
+	}
+	sort.Strings(currencies)
+
+	now := time.Now()
+	engine := netting.NewEngine(rules).WithDealClients(dealClients)
+	for _, currencyCode := range currencies {
+		rounding, err := s.roundingPolicy(ctx, currencyCode)
+		if err != nil {
+			return nil, err
+		}
+		penalties, err := s.dealPenalties(ctx, cycle.DealIDs, currencyCode, nil)
+		if err != nil {
+			return nil, err
+		}
+		result, err := runNetting(engine.WithRounding(rounding).WithPenalties(penalties), byCurrency[currencyCode])
+		if err != nil {
+			return nil, err
+		}
+		for i, p := range result.Participants {
+			if result.Net[i] == 0 {
+				continue
+			}
+			var residual domain.Money
+			if i == result.ResidualIndex {
+				residual = result.Residual
+			}
+			cycle.Settlements = append(cycle.Settlements, &domain.MonetarySettlement{
+				Amount:           result.Net[i], // Positive: owes, Negative: owed
+				Status:           domain.StatusPending,
+				CreatedAt:        now,
+				UpdatedAt:        now,
+				CurrencyCode:     currencyCode,
+				Participant:      p.Role,
+				ClientID:         p.RefFor(domain.ParticipantClient),
+				BankID:           p.RefFor(domain.ParticipantBank),
+				DealershipID:     p.RefFor(domain.ParticipantDealership),
+				InsurerID:        p.RefFor(domain.ParticipantInsurer),
+				VATBreakdown:     result.VATBreakdowns[i],
+				RoundingResidual: residual,
+			})
+		}
+		cycle.Legs = append(cycle.Legs, result.Legs(currencyCode)...)
+	}
+	if err := s.applySettlementCurrency(ctx, cycle.Settlements...); err != nil {
+		return nil, err
+	}
+	if err := s.applySettlementDueDates(ctx, now, cycle.Settlements...); err != nil {
+		return nil, err
+	}
+
+	return cycle, nil
+}
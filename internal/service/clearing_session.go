@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// ListClearingSessions retrieves a page of clearing sessions, newest first, optionally in one status.
+func (s *Service) ListClearingSessions(ctx context.Context, status string, page, limit int) (*domain.ClearingSessionList, error) {
+	switch status {
+	case "", domain.ClearingSessionOpen, domain.ClearingSessionComputing, domain.ClearingSessionSettled, domain.ClearingSessionClosed:
+	default:
+		return nil, fmt.Errorf("unknown clearing session status %q: %w", status, ErrInvalidInput)
+	}
+	if page == 0 {
+		page = 1
+	}
+	if page < 0 {
+		return nil, fmt.Errorf("page must be positive: %w", ErrInvalidInput)
+	}
+	if limit == 0 {
+		limit = defaultSettlementsLimit
+	}
+	if limit < 0 || limit > maxSettlementsLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d: %w", maxSettlementsLimit, ErrInvalidInput)
+	}
+
+	sessions, total, err := s.repo.ListClearingSessions(ctx, status, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clearing sessions: %w", err)
+	}
+	if sessions == nil {
+		sessions = []*domain.ClearingSession{}
+	}
+
+	return &domain.ClearingSessionList{
+		Sessions: sessions,
+		Page:     page,
+		Limit:    limit,
+		Total:    total,
+		HasNext:  page*limit < total,
+	}, nil
+}
+
+// GetClearingSession retrieves a clearing session with the settlements its computation fixed.
+func (s *Service) GetClearingSession(ctx context.Context, sessionID int) (*domain.ClearingSession, error) {
+	session, err := s.clearingSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachSessionSettlements(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// ComputeClearingSession computes an open session: the session moves to computing and the next session
+// opens for new orders, the pending settlements of every open deal with orders in the session are fixed
+// and tied to the session, and the session moves to settled. A session left computing by a failed
+// computation is computed again.
+func (s *Service) ComputeClearingSession(ctx context.Context, sessionID int) (*domain.ClearingSession, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	session, err := s.clearingSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	switch session.Status {
+	case domain.ClearingSessionOpen:
+		if _, err := s.repo.StartComputingClearingSession(ctx, sessionID); err != nil {
+			if errors.Is(err, repository.ErrConflict) {
+				return nil, fmt.Errorf("clearing session is already being computed: %w", ErrConflict)
+			}
+			return nil, fmt.Errorf("failed to start computing clearing session: %w", err)
+		}
+	case domain.ClearingSessionComputing:
+	default:
+		return nil, fmt.Errorf("clearing session is %s, only open sessions can be computed: %w", session.Status, ErrConflict)
+	}
+
+	dealIDs, err := s.repo.ClearingSessionDeals(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, dealID := range dealIDs {
+		if _, err := s.fixPendingSettlements(ctx, dealID, &sessionID); err != nil {
+			return nil, fmt.Errorf("deal %d: %w", dealID, err)
+		}
+	}
+
+	session, err = s.repo.TransitionClearingSession(ctx, sessionID, domain.ClearingSessionComputing, domain.ClearingSessionSettled)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("clearing session status changed concurrently: %w", ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to update clearing session: %w", err)
+	}
+	if err := s.attachSessionSettlements(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// CloseClearingSession closes a settled session once all its settlements are executed or cancelled.
+func (s *Service) CloseClearingSession(ctx context.Context, sessionID int) (*domain.ClearingSession, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	session, err := s.clearingSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != domain.ClearingSessionSettled {
+		return nil, fmt.Errorf("clearing session is %s, only settled sessions can be closed: %w", session.Status, ErrConflict)
+	}
+	if session.PendingSettlements > 0 {
+		return nil, fmt.Errorf("clearing session has %d pending settlements: %w", session.PendingSettlements, ErrConflict)
+	}
+
+	session, err = s.repo.TransitionClearingSession(ctx, sessionID, domain.ClearingSessionSettled, domain.ClearingSessionClosed)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("clearing session changed concurrently: %w", ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to update clearing session: %w", err)
+	}
+	if err := s.attachSessionSettlements(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// clearingSession retrieves a clearing session, reporting an unknown session as ErrNotFound.
+func (s *Service) clearingSession(ctx context.Context, sessionID int) (*domain.ClearingSession, error) {
+	if sessionID <= 0 {
+		return nil, fmt.Errorf("invalid session_id: %w", ErrInvalidInput)
+	}
+	session, err := s.repo.GetClearingSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("clearing session not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get clearing session: %w", err)
+	}
+	return session, nil
+}
+
+// attachSessionSettlements sets the settlements of the session with their currency and due dates.
+func (s *Service) attachSessionSettlements(ctx context.Context, session *domain.ClearingSession) error {
+	settlements, err := s.repo.ListClearingSessionSettlements(ctx, session.SessionID)
+	if err != nil {
+		return err
+	}
+	if err := s.applySettlementCurrency(ctx, settlements...); err != nil {
+		return err
+	}
+	if err := s.applySettlementDueDates(ctx, time.Now(), settlements...); err != nil {
+		return err
+	}
+	session.Settlements = settlements
+	return nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// phonePattern matches a phone number in international format.
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{10,15}$`)
+
+// Client list page size limits.
+const (
+	defaultClientsLimit = 20
+	maxClientsLimit     = 100
+)
+
+// ListClients retrieves a page of clients ordered by client_id. Zero page and limit select the defaults.
+func (s *Service) ListClients(ctx context.Context, page, limit int) (*domain.ClientList, error) {
+	if page == 0 {
+		page = 1
+	}
+	if page < 0 {
+		return nil, fmt.Errorf("page must be positive: %w", ErrInvalidInput)
+	}
+	if limit == 0 {
+		limit = defaultClientsLimit
+	}
+	if limit < 0 || limit > maxClientsLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d: %w", maxClientsLimit, ErrInvalidInput)
+	}
+
+	clients, total, err := s.repo.ListClients(ctx, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+	if clients == nil {
+		clients = []*domain.Client{}
+	}
+
+	return &domain.ClientList{
+		Clients: clients,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+		HasNext: page*limit < total,
+	}, nil
+}
+
+// GetClient returns a client.
+func (s *Service) GetClient(ctx context.Context, clientID int) (*domain.Client, error) {
+	if clientID <= 0 {
+		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+
+	client, err := s.repo.GetClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("client not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	return client, nil
+}
+
+// CreateClient adds a client; deals reference it by client_id.
+func (s *Service) CreateClient(ctx context.Context, req domain.Client) (*domain.Client, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateClient(req); err != nil {
+		return nil, err
+	}
+
+	client, err := s.repo.CreateClient(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("client %d already exists: %w", req.ClientID, ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	return client, nil
+}
+
+// UpdateClient replaces the personal data of a client. An anonymized client cannot be updated.
+func (s *Service) UpdateClient(ctx context.Context, req domain.Client) (*domain.Client, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateClient(req); err != nil {
+		return nil, err
+	}
+
+	client, err := s.repo.UpdateClient(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, fmt.Errorf("client not found: %w", ErrNotFound)
+		case errors.Is(err, repository.ErrConflict):
+			return nil, fmt.Errorf("client %d is anonymized: %w", req.ClientID, ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to update client: %w", err)
+	}
+
+	return client, nil
+}
+
+// DeleteClient removes a client without deals. A client with deals is anonymized instead.
+func (s *Service) DeleteClient(ctx context.Context, clientID int) error {
+	if err := denyClientWrite(ctx); err != nil {
+		return err
+	}
+	if clientID <= 0 {
+		return fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+
+	if err := s.repo.DeleteClient(ctx, clientID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return fmt.Errorf("client not found: %w", ErrNotFound)
+		case errors.Is(err, repository.ErrConflict):
+			return fmt.Errorf("client %d has deals, anonymize it instead: %w", clientID, ErrConflict)
+		}
+		return fmt.Errorf("failed to delete client: %w", err)
+	}
+
+	return nil
+}
+
+// validateClient checks a client record: a positive ID, a full name that fits the column and
+// well-formed contacts.
+func validateClient(client domain.Client) error {
+	if client.ClientID <= 0 {
+		return fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+	fullName := strings.TrimSpace(client.FullName)
+	if fullName == "" {
+		return fmt.Errorf("full_name is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(fullName) > 255 {
+		return fmt.Errorf("full_name must not exceed 255 characters: %w", ErrInvalidInput)
+	}
+	if client.Phone != "" && !phonePattern.MatchString(client.Phone) {
+		return fmt.Errorf("phone must be 10 to 15 digits with an optional leading +: %w", ErrInvalidInput)
+	}
+	if client.Email != "" {
+		address, err := mail.ParseAddress(client.Email)
+		if err != nil || address.Address != client.Email || len(client.Email) > 255 {
+			return fmt.Errorf("invalid email: %w", ErrInvalidInput)
+		}
+	}
+	if utf8.RuneCountInString(client.PassportRef) > 100 {
+		return fmt.Errorf("passport_ref must not exceed 100 characters: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(client.INN) > 100 {
+		return fmt.Errorf("inn must not exceed 100 characters: %w", ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// maxCreditTermMonths limits the term of a credit contract.
+const maxCreditTermMonths = 600
+
+// ListCreditContracts returns the credit contracts of a deal ordered by ID.
+func (s *Service) ListCreditContracts(ctx context.Context, dealID int) ([]*domain.CreditContract, error) {
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if err := s.CheckDealAccess(ctx, dealID); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetDeal(ctx, dealID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+
+	contracts, err := s.repo.ListCreditContracts(ctx, dealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list credit contracts: %w", err)
+	}
+	if contracts == nil {
+		contracts = []*domain.CreditContract{}
+	}
+
+	return contracts, nil
+}
+
+// GetCreditContract returns a credit contract. Contracts of deals the user may not see are not found.
+func (s *Service) GetCreditContract(ctx context.Context, contractID int) (*domain.CreditContract, error) {
+	if contractID <= 0 {
+		return nil, fmt.Errorf("invalid credit_contract_id: %w", ErrInvalidInput)
+	}
+
+	contract, err := s.repo.GetCreditContract(ctx, contractID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("credit contract not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get credit contract: %w", err)
+	}
+	if err := s.CheckDealAccess(ctx, contract.DealID); err != nil {
+		return nil, fmt.Errorf("credit contract not found: %w", ErrNotFound)
+	}
+
+	return contract, nil
+}
+
+// CreateCreditContract records the credit contract of a deal. Credit orders of the deal take part
+// in netting once they reference a contract.
+func (s *Service) CreateCreditContract(ctx context.Context, req domain.CreditContract) (*domain.CreditContract, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if req.DealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if err := s.CheckDealAccess(ctx, req.DealID); err != nil {
+		return nil, err
+	}
+	if req.CurrencyCode == "" {
+		req.CurrencyCode = domain.DefaultCurrencyCode
+	}
+	if err := s.validateCreditContract(ctx, req, nil); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetDeal(ctx, req.DealID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+
+	contract, err := s.repo.CreateCreditContract(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credit contract: %w", err)
+	}
+
+	return contract, nil
+}
+
+// UpdateCreditContract replaces the terms of a credit contract. The bank of a contract that orders
+// already reference cannot be changed: the orders are payable to that bank.
+func (s *Service) UpdateCreditContract(ctx context.Context, req domain.CreditContract) (*domain.CreditContract, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	current, err := s.GetCreditContract(ctx, req.CreditContractID)
+	if err != nil {
+		return nil, err
+	}
+	if req.CurrencyCode == "" {
+		req.CurrencyCode = domain.DefaultCurrencyCode
+	}
+	if err := s.validateCreditContract(ctx, req, &current.BankID); err != nil {
+		return nil, err
+	}
+	if req.BankID != current.BankID {
+		count, err := s.repo.CountContractOrders(ctx, req.CreditContractID)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("bank of credit contract %d is referenced by %d orders: %w",
+				req.CreditContractID, count, ErrConflict)
+		}
+	}
+
+	contract, err := s.repo.UpdateCreditContract(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("credit contract not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to update credit contract: %w", err)
+	}
+
+	return contract, nil
+}
+
+// DeleteCreditContract removes a credit contract that no order references.
+func (s *Service) DeleteCreditContract(ctx context.Context, contractID int) error {
+	if err := denyClientWrite(ctx); err != nil {
+		return err
+	}
+	if _, err := s.GetCreditContract(ctx, contractID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteCreditContract(ctx, contractID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return fmt.Errorf("credit contract not found: %w", ErrNotFound)
+		case errors.Is(err, repository.ErrConflict):
+			return fmt.Errorf("credit contract %d is referenced by orders: %w", contractID, ErrConflict)
+		}
+		return fmt.Errorf("failed to delete credit contract: %w", err)
+	}
+
+	return nil
+}
+
+// validateCreditContract checks the terms of a credit contract: an active bank (or the bank the contract
+// already has), a contract number, a positive principal in a known currency, the term and the rate.
+func (s *Service) validateCreditContract(ctx context.Context, contract domain.CreditContract, currentBankID *int) error {
+	if contract.BankID <= 0 {
+		return fmt.Errorf("invalid bank_id: %w", ErrInvalidInput)
+	}
+	if err := s.validateOrderBank(ctx, &contract.BankID, currentBankID); err != nil {
+		return err
+	}
+	number := strings.TrimSpace(contract.ContractNumber)
+	if number == "" {
+		return fmt.Errorf("contract_number is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(number) > 50 {
+		return fmt.Errorf("contract_number must not exceed 50 characters: %w", ErrInvalidInput)
+	}
+	if contract.Principal <= 0 {
+		return fmt.Errorf("principal must be positive: %w", ErrInvalidInput)
+	}
+	currencies, err := s.currencies(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := currencies[contract.CurrencyCode]; !ok {
+		return fmt.Errorf("unknown currency_code %s: %w", contract.CurrencyCode, ErrInvalidInput)
+	}
+	if contract.TermMonths <= 0 || contract.TermMonths > maxCreditTermMonths {
+		return fmt.Errorf("term_months must be between 1 and %d: %w", maxCreditTermMonths, ErrInvalidInput)
+	}
+	if contract.Rate < 0 || contract.Rate > 100 {
+		return fmt.Errorf("rate must be between 0 and 100: %w", ErrInvalidInput)
+	}
+	return nil
+}
+
+// validateOrderCreditContract checks the credit contract of an order: only credit orders reference
+// a contract, the contract belongs to the deal of the order and the order is payable to the bank
+// of the contract. An order without a bank takes the bank of the contract.
+func (s *Service) validateOrderCreditContract(ctx context.Context, order *domain.Order) error {
+	if order.CreditContractID == nil {
+		return nil
+	}
+	if order.OrderTypeID != domain.OrderTypeCredit {
+		return fmt.Errorf("order type %d does not reference a credit contract: %w", order.OrderTypeID, ErrInvalidInput)
+	}
+
+	contract, err := s.repo.GetCreditContract(ctx, *order.CreditContractID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("unknown credit_contract_id %d: %w", *order.CreditContractID, ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to get credit contract: %w", err)
+	}
+	if contract.DealID != order.DealID {
+		return fmt.Errorf("credit contract %d belongs to deal %d: %w", contract.CreditContractID, contract.DealID, ErrInvalidInput)
+	}
+	if order.BankID == nil {
+		order.BankID = &contract.BankID
+	} else if *order.BankID != contract.BankID {
+		return fmt.Errorf("bank_id %d differs from bank %d of credit contract %d: %w",
+			*order.BankID, contract.BankID, contract.CreditContractID, ErrInvalidInput)
+	}
+	return nil
+}
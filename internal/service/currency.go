@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"cliring/internal/domain"
+)
+
+// currencies loads the currency table indexed by currency code.
+func (s *Service) currencies(ctx context.Context) (map[string]*domain.Currency, error) {
+	list, err := s.repo.ListCurrencies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list currencies: %w", err)
+	}
+
+	currencies := make(map[string]*domain.Currency, len(list))
+	for _, currency := range list {
+		currencies[currency.Code] = currency
+	}
+	return currencies, nil
+}
+
+// applyOrderCurrency fills currency exponent and display amount of orders from the currency table.
+func (s *Service) applyOrderCurrency(ctx context.Context, orders ...*domain.Order) error {
+	currencies, err := s.currencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, order := range orders {
+		currency := currencyOrDefault(currencies, order.CurrencyCode)
+		order.CurrencyCode = currency.Code
+		order.CurrencyExponent = currency.MinorUnit
+		order.AmountDisplay = formatAmount(order.Amount, currency)
+	}
+	return nil
+}
+
+// applySettlementCurrency fills currency exponent and display amount of settlements from the currency table.
+func (s *Service) applySettlementCurrency(ctx context.Context, settlements ...*domain.MonetarySettlement) error {
+	currencies, err := s.currencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, settlement := range settlements {
+		currency := currencyOrDefault(currencies, settlement.CurrencyCode)
+		settlement.CurrencyCode = currency.Code
+		settlement.CurrencyExponent = currency.MinorUnit
+		settlement.AmountDisplay = formatAmount(settlement.Amount, currency)
+	}
+	return nil
+}
+
+// currencyOrDefault returns the currency for code. Unknown codes are formatted with two minor digits
+// and the code itself as a symbol.
+func currencyOrDefault(currencies map[string]*domain.Currency, code string) *domain.Currency {
+	if code == "" {
+		code = domain.DefaultCurrencyCode
+	}
+	if currency, ok := currencies[code]; ok {
+		return currency
+	}
+	return &domain.Currency{Code: code, MinorUnit: 2, Symbol: code, Rounding: domain.DefaultRoundingPolicy}
+}
+
+// roundingPolicy returns the rounding policy of net positions in the currency.
+func (s *Service) roundingPolicy(ctx context.Context, code string) (domain.RoundingPolicy, error) {
+	currencies, err := s.currencies(ctx)
+	if err != nil {
+		return domain.RoundingPolicy{}, err
+	}
+	return currencyOrDefault(currencies, code).Rounding, nil
+}
+
+// formatAmount formats an amount in the Russian style: "-1 234 567,89 ₽".
+// Groups are separated with a non-breaking space.
+func formatAmount(amount domain.Money, currency *domain.Currency) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	// Суммы хранятся в копейках; валюта может иметь другое число разрядов дробной части
+	scale := int64(math.Pow10(currency.MinorUnit))
+	minor := amount.MulRatio(domain.Money(scale), 100).Kopecks()
+	integer := strconv.FormatInt(minor/scale, 10)
+
+	// Разбиение целой части на группы по три цифры
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteRune(' ')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	result := sign + grouped.String()
+	if currency.MinorUnit > 0 {
+		fraction := strconv.FormatInt(minor%scale, 10)
+		result += "," + strings.Repeat("0", currency.MinorUnit-len(fraction)) + fraction
+	}
+	return result + " " + currency.Symbol
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// DeleteDeal schedules a deal for deletion. The deal stays available and can be restored
+// until the configured grace period expires; then it is soft-deleted by the deletion worker.
+func (s *Service) DeleteDeal(ctx context.Context, dealID int) (*domain.Deal, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+
+	deal, err := s.repo.ScheduleDealDeletion(ctx, dealID, time.Now().Add(s.cfg.Deletion.GracePeriod))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("deal %d is already pending deletion: %w", dealID, ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to schedule deal deletion: %w", err)
+	}
+
+	return deal, nil
+}
+
+// RestoreDeal cancels a scheduled deletion of a deal within the grace period.
+func (s *Service) RestoreDeal(ctx context.Context, dealID int) (*domain.Deal, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+
+	deal, err := s.repo.RestoreDeal(ctx, dealID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("deal %d is not pending deletion: %w", dealID, ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to restore deal: %w", err)
+	}
+
+	return deal, nil
+}
+
+// DeleteDueDeals soft-deletes deals whose deletion grace period has expired and returns their IDs.
+func (s *Service) DeleteDueDeals(ctx context.Context) ([]int, error) {
+	dealIDs, err := s.repo.ListDealsDueForDeletion(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deals due for deletion: %w", err)
+	}
+
+	var deleted []int
+	for _, dealID := range dealIDs {
+		if err := s.repo.DeleteDeal(ctx, dealID); err != nil {
+			// Сделку могли восстановить или удалить параллельно - переходим к следующей
+			if errors.Is(err, repository.ErrNotFound) || errors.Is(err, repository.ErrConflict) {
+				continue
+			}
+			return deleted, fmt.Errorf("failed to delete deal %d: %w", dealID, err)
+		}
+		deleted = append(deleted, dealID)
+	}
+
+	return deleted, nil
+}
+
+// RunDealDeletionWorker periodically deletes deals with an expired grace period until ctx is cancelled.
+func (s *Service) RunDealDeletionWorker(ctx context.Context) {
+	interval := s.cfg.Deletion.CheckInterval
+	if interval <= 0 {
+		logrus.Info("Deal deletion worker disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.DeleteDueDeals(ctx)
+			if err != nil {
+				logrus.Error("Deal deletion failed: ", err)
+			}
+			if len(deleted) > 0 {
+				logrus.WithField("deal_ids", deleted).Info("Deals deleted after grace period")
+			}
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/sirupsen/logrus"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+	"cliring/pkg/s3"
+)
+
+// defaultContentType is stored for uploaded files sent without a content type.
+const defaultContentType = "application/octet-stream"
+
+// DocumentStorage stores the content of deal documents and order invoices. The metadata is kept in Postgres.
+type DocumentStorage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// WithDocumentStorage sets the storage used for deal document content. Calls to the storage are
+// reported in the integrations status.
+func (s *Service) WithDocumentStorage(storage DocumentStorage) *Service {
+	s.storage = monitoredStorage{DocumentStorage: storage, s: s}
+	s.integrations.Register(domain.IntegrationObjectStorage)
+	if prober, ok := storage.(Prober); ok {
+		s.probes[domain.IntegrationObjectStorage] = prober
+	}
+	return s
+}
+
+// UploadDealDocument stores a file and attaches it to the deal.
+func (s *Service) UploadDealDocument(ctx context.Context, dealID int, fileName, contentType string, size int64, r io.Reader) (*domain.DealDocument, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if err := s.validateUpload(fileName, size); err != nil {
+		return nil, err
+	}
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+
+	// Verify deal exists
+	if _, err := s.repo.GetDeal(ctx, dealID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+
+	key, err := storageKey("deals", dealID, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.Put(ctx, key, r, size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store document: %w", err)
+	}
+
+	document, err := s.repo.CreateDealDocument(ctx, &domain.DealDocument{
+		DealID:      dealID,
+		FileName:    fileName,
+		ContentType: contentType,
+		SizeBytes:   size,
+		StorageKey:  key,
+	})
+	if err != nil {
+		// Файл без метаданных недоступен через API, удаляем его
+		if delErr := s.storage.Delete(ctx, key); delErr != nil {
+			logrus.Errorf("failed to remove orphaned document %s: %s", key, delErr)
+		}
+		return nil, fmt.Errorf("failed to create deal document: %w", err)
+	}
+
+	return document, nil
+}
+
+// ListDealDocuments retrieves the documents attached to a deal.
+func (s *Service) ListDealDocuments(ctx context.Context, dealID int) ([]*domain.DealDocument, error) {
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+
+	// Verify deal exists
+	if _, err := s.repo.GetDeal(ctx, dealID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+
+	documents, err := s.repo.ListDealDocuments(ctx, dealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deal documents: %w", err)
+	}
+
+	return documents, nil
+}
+
+// DownloadDealDocument returns the metadata and content of a deal document.
+// The caller must close the returned reader.
+func (s *Service) DownloadDealDocument(ctx context.Context, dealID, documentID int) (*domain.DealDocument, io.ReadCloser, error) {
+	document, err := s.getDealDocument(ctx, dealID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.storage.Get(ctx, document.StorageKey)
+	if err != nil {
+		if errors.Is(err, s3.ErrObjectNotFound) {
+			return nil, nil, fmt.Errorf("document content not found: %w", ErrNotFound)
+		}
+		return nil, nil, fmt.Errorf("failed to read document: %w", err)
+	}
+
+	return document, content, nil
+}
+
+// DeleteDealDocument detaches a document from the deal and removes its content from the storage.
+func (s *Service) DeleteDealDocument(ctx context.Context, dealID, documentID int) error {
+	if err := denyClientWrite(ctx); err != nil {
+		return err
+	}
+	document, err := s.getDealDocument(ctx, dealID, documentID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteDealDocument(ctx, dealID, documentID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("document not found: %w", ErrNotFound)
+		}
+		return fmt.Errorf("failed to delete deal document: %w", err)
+	}
+	if err := s.storage.Delete(ctx, document.StorageKey); err != nil {
+		return fmt.Errorf("failed to delete document content: %w", err)
+	}
+
+	return nil
+}
+
+// getDealDocument validates the identifiers and retrieves the document metadata.
+func (s *Service) getDealDocument(ctx context.Context, dealID, documentID int) (*domain.DealDocument, error) {
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if documentID <= 0 {
+		return nil, fmt.Errorf("invalid document_id: %w", ErrInvalidInput)
+	}
+
+	document, err := s.repo.GetDealDocument(ctx, dealID, documentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("document not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal document: %w", err)
+	}
+
+	return document, nil
+}
+
+// validateUpload checks the name and size of an uploaded file.
+func (s *Service) validateUpload(fileName string, size int64) error {
+	if fileName == "" {
+		return fmt.Errorf("file name is required: %w", ErrInvalidInput)
+	}
+	if size <= 0 {
+		return fmt.Errorf("file is empty: %w", ErrInvalidInput)
+	}
+	if size > s.cfg.S3.MaxUploadSize {
+		return fmt.Errorf("file exceeds %d bytes: %w", s.cfg.S3.MaxUploadSize, ErrInvalidInput)
+	}
+	return nil
+}
+
+// storageKey builds a unique storage key for a file of an entity (e.g. "deals/42/<random>.pdf"),
+// keeping the file extension.
+func storageKey(entity string, id int, fileName string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate storage key: %w", err)
+	}
+	return fmt.Sprintf("%s/%d/%s%s", entity, id, hex.EncodeToString(b), path.Ext(fileName)), nil
+}
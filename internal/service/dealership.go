@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// ListDealerships returns the dealerships ordered by dealership_id, optionally only the active ones.
+// A user limited to a dealership sees only that dealership.
+func (s *Service) ListDealerships(ctx context.Context, activeOnly bool) ([]*domain.Dealership, error) {
+	dealerships, err := s.repo.ListDealerships(ctx, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dealerships: %w", err)
+	}
+
+	scoped := []*domain.Dealership{}
+	for _, dealership := range dealerships {
+		if inDealershipScope(ctx, dealership.DealershipID) {
+			scoped = append(scoped, dealership)
+		}
+	}
+
+	return scoped, nil
+}
+
+// GetDealership returns a dealership. Other dealerships are not found for a user limited to a dealership.
+func (s *Service) GetDealership(ctx context.Context, dealershipID int) (*domain.Dealership, error) {
+	if dealershipID <= 0 {
+		return nil, fmt.Errorf("invalid dealership_id: %w", ErrInvalidInput)
+	}
+	if !inDealershipScope(ctx, dealershipID) {
+		return nil, fmt.Errorf("dealership not found: %w", ErrNotFound)
+	}
+
+	dealership, err := s.repo.GetDealership(ctx, dealershipID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("dealership not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get dealership: %w", err)
+	}
+
+	return dealership, nil
+}
+
+// CreateDealership adds a dealership to the group. A new dealership is active.
+func (s *Service) CreateDealership(ctx context.Context, req domain.Dealership) (*domain.Dealership, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if err := requireGroupScope(ctx); err != nil {
+		return nil, err
+	}
+	req.Active = true
+	if err := validateDealership(req); err != nil {
+		return nil, err
+	}
+
+	dealership, err := s.repo.CreateDealership(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("dealership %d already exists: %w", req.DealershipID, ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to create dealership: %w", err)
+	}
+
+	return dealership, nil
+}
+
+// UpdateDealership changes the details of a dealership or deactivates it. Existing deals keep their dealership.
+func (s *Service) UpdateDealership(ctx context.Context, req domain.Dealership) (*domain.Dealership, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if err := requireGroupScope(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateDealership(req); err != nil {
+		return nil, err
+	}
+
+	dealership, err := s.repo.UpdateDealership(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("dealership not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to update dealership: %w", err)
+	}
+
+	return dealership, nil
+}
+
+// DeleteDealership removes a dealership without deals, orders and managers.
+func (s *Service) DeleteDealership(ctx context.Context, dealershipID int) error {
+	if err := denyClientWrite(ctx); err != nil {
+		return err
+	}
+	if err := requireGroupScope(ctx); err != nil {
+		return err
+	}
+	if dealershipID <= 0 {
+		return fmt.Errorf("invalid dealership_id: %w", ErrInvalidInput)
+	}
+
+	if err := s.repo.DeleteDealership(ctx, dealershipID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return fmt.Errorf("dealership not found: %w", ErrNotFound)
+		case errors.Is(err, repository.ErrConflict):
+			return fmt.Errorf("dealership %d has deals, orders or managers, deactivate it instead: %w", dealershipID, ErrConflict)
+		}
+		return fmt.Errorf("failed to delete dealership: %w", err)
+	}
+
+	return nil
+}
+
+// validateDealership checks a dealership record: a positive ID and a name, legal entity and address
+// that fit the columns.
+func validateDealership(dealership domain.Dealership) error {
+	if dealership.DealershipID <= 0 {
+		return fmt.Errorf("invalid dealership_id: %w", ErrInvalidInput)
+	}
+	fields := []struct{ name, value string }{
+		{"name", dealership.Name},
+		{"legal_entity", dealership.LegalEntity},
+	}
+	for _, field := range fields {
+		value := strings.TrimSpace(field.value)
+		if value == "" {
+			return fmt.Errorf("%s is required: %w", field.name, ErrInvalidInput)
+		}
+		if utf8.RuneCountInString(value) > 255 {
+			return fmt.Errorf("%s must not exceed 255 characters: %w", field.name, ErrInvalidInput)
+		}
+	}
+	if utf8.RuneCountInString(dealership.Address) > 500 {
+		return fmt.Errorf("address must not exceed 500 characters: %w", ErrInvalidInput)
+	}
+	return nil
+}
+
+// validateDealDealership checks that a deal is placed in an active dealership the user may work with.
+func (s *Service) validateDealDealership(ctx context.Context, dealershipID int) error {
+	if err := checkDealershipScope(ctx, dealershipID); err != nil {
+		return err
+	}
+
+	dealership, err := s.repo.GetDealership(ctx, dealershipID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("unknown dealership_id %d: %w", dealershipID, ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to get dealership: %w", err)
+	}
+	if !dealership.Active {
+		return fmt.Errorf("dealership %d is inactive: %w", dealershipID, ErrInvalidInput)
+	}
+	return nil
+}
+
+// dealershipScope returns the dealership the user of the request is limited to, if any.
+func dealershipScope(ctx context.Context) (int, bool) {
+	dealershipID, ok := ctx.Value(domain.DealershipKey{}).(int)
+	return dealershipID, ok
+}
+
+// inDealershipScope reports whether the user of the request may work with the dealership.
+func inDealershipScope(ctx context.Context, dealershipID int) bool {
+	scope, ok := dealershipScope(ctx)
+	return !ok || scope == dealershipID
+}
+
+// checkDealershipScope rejects access to another dealership by a user limited to a dealership.
+func checkDealershipScope(ctx context.Context, dealershipID int) error {
+	if !inDealershipScope(ctx, dealershipID) {
+		return fmt.Errorf("access to dealership %d is not allowed: %w", dealershipID, ErrForbidden)
+	}
+	return nil
+}
+
+// requireGroupScope rejects changes of group-wide data by a user limited to a dealership.
+func requireGroupScope(ctx context.Context) error {
+	if _, ok := dealershipScope(ctx); ok {
+		return fmt.Errorf("dealership-scoped users cannot change the dealerships reference: %w", ErrForbidden)
+	}
+	return nil
+}
+
+// CheckDealAccess reports ErrNotFound for a deal of another dealership if the user of the request is limited
+// to a dealership, and for a deal of another client if the token belongs to a client, so that such deals
+// are not disclosed. A client role without a client in the token sees no deals. Unknown deals pass:
+// the handler reports them itself.
+func (s *Service) CheckDealAccess(ctx context.Context, dealID int) error {
+	_, dealershipScoped := dealershipScope(ctx)
+	_, clientScoped := tokenClient(ctx)
+	if !clientScoped && clientOnly(ctx) {
+		return fmt.Errorf("deal not found: %w", ErrNotFound)
+	}
+	if (!dealershipScoped && !clientScoped) || dealID <= 0 {
+		return nil
+	}
+
+	deal, err := s.repo.GetDeal(ctx, dealID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get deal: %w", err)
+	}
+	if !inDealershipScope(ctx, deal.DealershipID) || !inClientScope(ctx, deal.ClientID) {
+		return fmt.Errorf("deal not found: %w", ErrNotFound)
+	}
+	return nil
+}
+
+// checkSettlementAccess reports ErrNotFound for a settlement of a deal the user of the request may not see.
+func (s *Service) checkSettlementAccess(ctx context.Context, settlement *domain.MonetarySettlement) error {
+	if settlement.DealID == nil {
+		return nil
+	}
+	if err := s.CheckDealAccess(ctx, *settlement.DealID); err != nil {
+		return fmt.Errorf("monetary settlement not found: %w", ErrNotFound)
+	}
+	return nil
+}
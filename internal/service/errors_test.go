@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cliring/internal/domain"
+	"cliring/internal/service"
+)
+
+// TestRepositoryErrors checks that every backend reports missing rows as ErrNotFound and violated
+// constraints as ErrConflict, so the service maps them to the same responses.
+func TestRepositoryErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(ctx context.Context, svc *service.Service, f fixture) error
+		want error
+	}{
+		{
+			name: "missing bank",
+			call: func(ctx context.Context, svc *service.Service, f fixture) error {
+				_, err := svc.GetBank(ctx, 999)
+				return err
+			},
+			want: service.ErrNotFound,
+		},
+		{
+			name: "missing order",
+			call: func(ctx context.Context, svc *service.Service, f fixture) error {
+				_, err := svc.GetOrder(ctx, f.clientID, 999)
+				return err
+			},
+			want: service.ErrNotFound,
+		},
+		{
+			name: "missing deal",
+			call: func(ctx context.Context, svc *service.Service, f fixture) error {
+				_, err := svc.GetDealView(ctx, 999, nil, domain.OrderSort{})
+				return err
+			},
+			want: service.ErrNotFound,
+		},
+		{
+			name: "missing order type",
+			call: func(ctx context.Context, svc *service.Service, f fixture) error {
+				return svc.DeleteOrderType(ctx, 999)
+			},
+			want: service.ErrNotFound,
+		},
+		{
+			name: "duplicate bank",
+			call: func(ctx context.Context, svc *service.Service, f fixture) error {
+				_, err := svc.CreateBank(ctx, domain.Bank{BankID: f.bankIDs[0], Name: "Дубликат", Active: true})
+				return err
+			},
+			want: service.ErrConflict,
+		},
+		{
+			name: "duplicate order type",
+			call: func(ctx context.Context, svc *service.Service, f fixture) error {
+				_, err := svc.CreateOrderType(ctx, domain.OrderType{
+					OrderTypeID: domain.OrderTypePurchase,
+					Name:        "ПОКУПКА",
+					Debtor:      domain.ParticipantClient,
+					Creditor:    domain.ParticipantDealership,
+				})
+				return err
+			},
+			want: service.ErrConflict,
+		},
+		{
+			name: "bank used by orders",
+			call: func(ctx context.Context, svc *service.Service, f fixture) error {
+				return svc.DeleteBank(ctx, f.bankIDs[0])
+			},
+			want: service.ErrConflict,
+		},
+		{
+			name: "order type used by orders",
+			call: func(ctx context.Context, svc *service.Service, f fixture) error {
+				return svc.DeleteOrderType(ctx, domain.OrderTypePurchase)
+			},
+			want: service.ErrConflict,
+		},
+	}
+
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx := asAdmin(b.ctx)
+			svc := service.NewService(b.repo, testConfig(t))
+			f := newFixture(t, ctx, svc)
+			dealID := newActiveDeal(t, ctx, svc, f, 1)
+			orders := []domain.OrderCreate{
+				{DealID: dealID, OrderTypeID: domain.OrderTypePurchase, Amount: domain.MoneyFromFloat(100_000)},
+				{DealID: dealID, OrderTypeID: domain.OrderTypeCredit, Amount: domain.MoneyFromFloat(80_000), BankID: intPtr(f.bankIDs[0])},
+			}
+			if _, err := svc.CreateOrders(ctx, f.clientID, orders); err != nil {
+				t.Fatalf("create orders: %v", err)
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					if err := tt.call(ctx, svc, f); !errors.Is(err, tt.want) {
+						t.Errorf("got error %v, want %v", err, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// GetDealForecast projects the net positions of a deal once all its pending orders are executed.
+// Pending purchase and trade-in orders are the scheduled part of the deal and pending credit
+// orders are credit applications awaiting the bank's decision; both are counted in the
+// projection and flagged as estimates. Cancelled orders are ignored.
+func (s *Service) GetDealForecast(ctx context.Context, dealID int) (*domain.DealForecast, error) {
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+
+	_, orders, err := s.repo.GetDealWithOrders(ctx, dealID, domain.OrderSort{})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+	if err := s.applyOrderCurrency(ctx, orders...); err != nil {
+		return nil, err
+	}
+	orders, currencyCode, _, err := s.convertOrders(ctx, orders, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var booked, projected []*domain.Order
+	for _, order := range orders {
+		switch order.Status {
+		case domain.StatusExecuted:
+			booked = append(booked, order)
+			projected = append(projected, order)
+		case domain.StatusPending:
+			projected = append(projected, order)
+		}
+	}
+
+	rules, err := s.nettingRules(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	rounding, err := s.roundingPolicy(ctx, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+	penalties, err := s.dealPenalties(ctx, []int{dealID}, currencyCode, nil)
+	if err != nil {
+		return nil, err
+	}
+	bookedResult, err := netOrders(booked, rules, rounding, penalties)
+	if err != nil {
+		return nil, err
+	}
+	bookedByKey := make(map[string]domain.Money, len(bookedResult.Participants))
+	for i, p := range bookedResult.Participants {
+		bookedByKey[p.ID()] = bookedResult.Net[i]
+	}
+
+	// Участники прогноза включают всех участников исполненных заказов, так как исполненные заказы входят в прогноз
+	result, err := netOrders(projected, rules, rounding, penalties)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := &domain.DealForecast{
+		DealID:       dealID,
+		CurrencyCode: currencyCode,
+		Positions:    []*domain.ForecastPosition{},
+		Legs:         result.Legs(currencyCode),
+	}
+	for i, p := range result.Participants {
+		pending := result.Net[i] - bookedByKey[p.ID()]
+		position := &domain.ForecastPosition{
+			Participant:     p.Role,
+			DealershipID:    p.RefFor(domain.ParticipantDealership),
+			BankID:          p.RefFor(domain.ParticipantBank),
+			InsurerID:       p.RefFor(domain.ParticipantInsurer),
+			BookedAmount:    bookedByKey[p.ID()],
+			ProjectedAmount: result.Net[i],
+			PendingAmount:   pending,
+			Estimate:        pending != 0,
+		}
+		forecast.Estimate = forecast.Estimate || position.Estimate
+		forecast.Positions = append(forecast.Positions, position)
+	}
+	if forecast.Legs == nil {
+		forecast.Legs = []*domain.SettlementLeg{}
+	}
+
+	return forecast, nil
+}
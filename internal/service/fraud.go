@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cliring/config"
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// OrderRule inspects a newly created order for suspicious patterns. A non-empty reason flags the
+// order for manual review: it is kept out of netting until an administrator approves it.
+type OrderRule interface {
+	Check(ctx context.Context, clientID int, order *domain.Order) (reason string, err error)
+}
+
+// WithOrderRules replaces the anti-fraud rules applied to new orders.
+func (s *Service) WithOrderRules(rules ...OrderRule) *Service {
+	s.rules = rules
+	return s
+}
+
+// defaultOrderRules builds the velocity rules configured by cfg.Fraud. A zero threshold disables a rule.
+func defaultOrderRules(repo OrderActivity, cfg *config.Config) []OrderRule {
+	var rules []OrderRule
+	if cfg.Fraud.HighValueCount > 0 {
+		rules = append(rules, highValueVelocityRule{
+			repo:      repo,
+			minAmount: domain.MoneyFromFloat(cfg.Fraud.HighValueAmount),
+			count:     cfg.Fraud.HighValueCount,
+			window:    cfg.Fraud.HighValueWindow,
+		})
+	}
+	if cfg.Fraud.CancelCount > 0 {
+		rules = append(rules, cancelCycleRule{
+			repo:   repo,
+			count:  cfg.Fraud.CancelCount,
+			window: cfg.Fraud.CancelWindow,
+		})
+	}
+	return rules
+}
+
+// highValueVelocityRule flags a client placing many high-value orders within a short window.
+type highValueVelocityRule struct {
+	repo      OrderActivity
+	minAmount domain.Money
+	count     int
+	window    time.Duration
+}
+
+// Check implements OrderRule.
+func (r highValueVelocityRule) Check(ctx context.Context, clientID int, order *domain.Order) (string, error) {
+	if order.Amount < r.minAmount {
+		return "", nil
+	}
+	count, err := r.repo.CountClientOrdersSince(ctx, clientID, r.minAmount, time.Now().Add(-r.window))
+	if err != nil {
+		return "", err
+	}
+	if count < r.count {
+		return "", nil
+	}
+	return fmt.Sprintf("high_value_velocity: %d orders of at least %s within %s", count, r.minAmount, r.window), nil
+}
+
+// cancelCycleRule flags a client that repeatedly creates and cancels or deletes orders.
+type cancelCycleRule struct {
+	repo   OrderActivity
+	count  int
+	window time.Duration
+}
+
+// Check implements OrderRule.
+func (r cancelCycleRule) Check(ctx context.Context, clientID int, _ *domain.Order) (string, error) {
+	count, err := r.repo.CountClientCancellationsSince(ctx, clientID, time.Now().Add(-r.window))
+	if err != nil {
+		return "", err
+	}
+	if count < r.count {
+		return "", nil
+	}
+	return fmt.Sprintf("cancel_cycles: %d orders cancelled or deleted within %s", count, r.window), nil
+}
+
+// reviewOrder runs the anti-fraud rules against a new order and puts it into the review queue
+// if any rule fires.
+func (s *Service) reviewOrder(ctx context.Context, clientID int, order *domain.Order) error {
+	var reasons []string
+	for _, rule := range s.rules {
+		reason, err := rule.Check(ctx, clientID, order)
+		if err != nil {
+			return fmt.Errorf("failed to check order rules: %w", err)
+		}
+		if reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	if _, err := s.repo.FlagOrderForReview(ctx, order.OrderID, clientID, reasons); err != nil {
+		return fmt.Errorf("failed to flag order for review: %w", err)
+	}
+	order.ReviewStatus = domain.ReviewPending
+	return nil
+}
+
+// ListOrderReviews retrieves the review queue filtered by status; an empty status lists all reviews.
+func (s *Service) ListOrderReviews(ctx context.Context, status string) ([]*domain.OrderReview, error) {
+	switch status {
+	case "", domain.ReviewPending, domain.ReviewApproved, domain.ReviewRejected:
+	default:
+		return nil, fmt.Errorf("unknown review status %q: %w", status, ErrInvalidInput)
+	}
+
+	reviews, err := s.repo.ListOrderReviews(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order reviews: %w", err)
+	}
+	return reviews, nil
+}
+
+// DecideOrderReview approves or rejects a flagged order. Approved orders enter netting,
+// rejected ones are cancelled.
+func (s *Service) DecideOrderReview(ctx context.Context, reviewID int, status string) (*domain.OrderReview, error) {
+	if reviewID <= 0 {
+		return nil, fmt.Errorf("invalid review_id: %w", ErrInvalidInput)
+	}
+	if status != domain.ReviewApproved && status != domain.ReviewRejected {
+		return nil, fmt.Errorf("unknown review decision %q: %w", status, ErrInvalidInput)
+	}
+
+	review, err := s.repo.DecideOrderReview(ctx, reviewID, status)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, fmt.Errorf("order review not found: %w", ErrNotFound)
+		case errors.Is(err, repository.ErrConflict):
+			return nil, fmt.Errorf("order review %d is already decided: %w", reviewID, ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to decide order review: %w", err)
+	}
+	return review, nil
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"cliring/internal/domain"
+	"cliring/internal/netting"
+)
+
+// cbrBaseCurrency is the currency the Central Bank quotes its rates in.
+const cbrBaseCurrency = "RUB"
+
+// RateSource fetches the official exchange rates set for a date. It returns the date in DateLayout
+// the rates are effective from, which is the latest date with rates if none are set for the date yet,
+// and the rates in roubles per unit of a currency indexed by currency code.
+type RateSource interface {
+	DailyRates(ctx context.Context, date time.Time) (string, map[string]float64, error)
+}
+
+// WithRateSource sets the source of the Central Bank rates the exchange rate table is refreshed from.
+// Fetches are reported in the integrations status.
+func (s *Service) WithRateSource(source RateSource) *Service {
+	s.rateSource = source
+	s.integrations.Register(domain.IntegrationCBRRates)
+	return s
+}
+
+// CreateFXRate stores a new version of the exchange rate of a currency to the pivot currency.
+func (s *Service) CreateFXRate(ctx context.Context, req domain.FXRate) (*domain.FXRate, error) {
+	req.CurrencyCode = strings.ToUpper(strings.TrimSpace(req.CurrencyCode))
+	req.PivotCurrencyCode = s.cfg.Settlement.PivotCurrency
+	if req.CurrencyCode == req.PivotCurrencyCode {
+		return nil, fmt.Errorf("currency_code must differ from the pivot currency %s: %w", req.PivotCurrencyCode, ErrInvalidInput)
+	}
+	currencies, err := s.currencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := currencies[req.CurrencyCode]; !ok {
+		return nil, fmt.Errorf("unknown currency_code %s: %w", req.CurrencyCode, ErrInvalidInput)
+	}
+	if req.Rate <= 0 {
+		return nil, fmt.Errorf("rate must be positive: %w", ErrInvalidInput)
+	}
+	if _, err := time.Parse(domain.DateLayout, req.RateDate); err != nil {
+		return nil, fmt.Errorf("invalid rate_date %q: %w", req.RateDate, ErrInvalidInput)
+	}
+
+	rate, err := s.repo.CreateFXRate(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exchange rate: %w", err)
+	}
+	return rate, nil
+}
+
+// ListFXRates retrieves the versions of the exchange rates to the pivot currency, newest first.
+func (s *Service) ListFXRates(ctx context.Context, currencyCode string) ([]*domain.FXRate, error) {
+	rates, err := s.repo.ListFXRates(ctx, s.cfg.Settlement.PivotCurrency, strings.ToUpper(currencyCode))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+	if rates == nil {
+		rates = []*domain.FXRate{}
+	}
+	return rates, nil
+}
+
+// ListEffectiveFXRates returns for every currency the exchange rate to the pivot currency
+// effective on the date, today if the date is empty, and the date itself.
+func (s *Service) ListEffectiveFXRates(ctx context.Context, date string) ([]*domain.FXRate, string, error) {
+	at := time.Now()
+	if date != "" {
+		var err error
+		at, err = time.ParseInLocation(domain.DateLayout, date, time.Local)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid date %q: %w", date, ErrInvalidInput)
+		}
+	}
+
+	rates, err := s.repo.ListEffectiveFXRates(ctx, s.cfg.Settlement.PivotCurrency, at)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+	if rates == nil {
+		rates = []*domain.FXRate{}
+	}
+	return rates, at.Format(domain.DateLayout), nil
+}
+
+// RefreshFXRates fetches the Central Bank rates for tomorrow, which are published in advance,
+// or the latest rates if they are not published yet, and stores the rates of the currencies of
+// the currency table to the pivot currency. It returns the number of new rates stored.
+func (s *Service) RefreshFXRates(ctx context.Context) (int, error) {
+	if s.rateSource == nil {
+		return 0, fmt.Errorf("rate source is not configured: %w", ErrConflict)
+	}
+
+	rateDate, roubles, err := s.rateSource.DailyRates(ctx, time.Now().AddDate(0, 0, 1))
+	s.integrations.Record(domain.IntegrationCBRRates, err)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch central bank rates: %w", err)
+	}
+	roubles[cbrBaseCurrency] = 1
+
+	// Курсы ЦБ заданы в рублях; для другой валюты неттинга курс считается через рубль
+	pivot := s.cfg.Settlement.PivotCurrency
+	pivotRate, ok := roubles[pivot]
+	if !ok {
+		return 0, fmt.Errorf("central bank has no rate of the pivot currency %s: %w", pivot, ErrConflict)
+	}
+	currencies, err := s.currencies(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var rates []domain.FXRate
+	for code := range currencies {
+		rate, ok := roubles[code]
+		if code == pivot || !ok {
+			continue
+		}
+		rates = append(rates, domain.FXRate{
+			CurrencyCode:      code,
+			PivotCurrencyCode: pivot,
+			Rate:              rate / pivotRate,
+			RateDate:          rateDate,
+		})
+	}
+
+	saved, err := s.repo.SaveCBRRates(ctx, rates)
+	if err != nil {
+		return saved, fmt.Errorf("failed to save central bank rates: %w", err)
+	}
+	return saved, nil
+}
+
+// RunFXRateRefresher refreshes the exchange rate table from the Central Bank on start and then
+// periodically until ctx is cancelled.
+func (s *Service) RunFXRateRefresher(ctx context.Context) {
+	interval := s.cfg.FX.CBRRefreshInterval
+	if interval <= 0 || s.rateSource == nil {
+		logrus.Info("Central bank rates refresher disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		saved, err := s.RefreshFXRates(ctx)
+		if err != nil {
+			logrus.Error("Central bank rates refresh failed: ", err)
+		} else if saved > 0 {
+			logrus.WithField("saved", saved).Info("Central bank rates refreshed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// convertOrders prepares the orders of a deal for netting. Orders in one currency are netted as is;
+// orders in different currencies are converted to the pivot currency with the rates effective at.
+// It returns the orders, the currency of the settlements and the rates used.
+func (s *Service) convertOrders(ctx context.Context, orders []*domain.Order, at time.Time) ([]*domain.Order, string, []*domain.FXRate, error) {
+	if !netting.MixedCurrencies(orders) {
+		currencyCode := domain.DefaultCurrencyCode
+		if len(orders) > 0 {
+			currencyCode = orders[0].CurrencyCode
+		}
+		return orders, currencyCode, nil, nil
+	}
+
+	pivot := s.cfg.Settlement.PivotCurrency
+	list, err := s.repo.ListEffectiveFXRates(ctx, pivot, at)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+	rates := make(map[string]*domain.FXRate, len(list))
+	for _, rate := range list {
+		rates[rate.CurrencyCode] = rate
+	}
+
+	rounding, err := s.roundingPolicy(ctx, pivot)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	converted, used, err := netting.ConvertOrders(orders, pivot, rates, rounding)
+	if err != nil {
+		if errors.Is(err, netting.ErrMissingRate) {
+			return nil, "", nil, fmt.Errorf("%v on %s: %w", err, at.Format(domain.DateLayout), ErrConflict)
+		}
+		return nil, "", nil, err
+	}
+	return converted, pivot, used, nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"cliring/internal/domain"
+)
+
+// errDatabaseNotOpen reports that the service started without the database and has not connected yet.
+var errDatabaseNotOpen = errors.New("database is not open")
+
+// Readiness reports whether the service can handle requests and which schema version it runs on.
+// The database ping is bounded by the readiness timeout so a hung connection reports not ready
+// instead of blocking the probe.
+func (s *Service) Readiness(ctx context.Context) (*domain.Readiness, error) {
+	readiness := &domain.Readiness{
+		Status:        domain.ReadinessReady,
+		SchemaVersion: s.repo.SchemaVersion(),
+	}
+	if !s.repo.Available() {
+		readiness.Status = domain.ReadinessNotReady
+		return readiness, errDatabaseNotOpen
+	}
+	if s.cfg.Postgres.ReadinessTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.Postgres.ReadinessTimeout)
+		defer cancel()
+	}
+	if err := s.repo.Ping(ctx); err != nil {
+		readiness.Status = domain.ReadinessNotReady
+		return readiness, err
+	}
+	return readiness, nil
+}
+
+// DatabaseAvailable reports whether the database has been opened, so requests can be served.
+// Until then the service runs degraded: only the probes respond.
+func (s *Service) DatabaseAvailable() bool {
+	return s.repo.Available()
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// idempotencyMissedHeartbeats is the number of heartbeat intervals after which a key left in progress,
+// e.g. by a crashed instance, may be taken over by a retry. A running request refreshes its key, so a slow
+// request is never taken over however long it runs.
+const idempotencyMissedHeartbeats = 3
+
+// Errors returned when an Idempotency-Key cannot be used for the request.
+var (
+	ErrIdempotencyKeyInProgress = fmt.Errorf("request with the idempotency key is in progress: %w", ErrConflict)
+	ErrIdempotencyKeyReused     = fmt.Errorf("idempotency key was used for a different request: %w", ErrInvalidInput)
+)
+
+// BeginIdempotentRequest reserves an idempotency key of the scope for a request and returns the owner token
+// of the reservation, which the request passes to the other idempotency calls. If a request with the same
+// key and hash has already completed, its stored response is returned and the request must not be executed again.
+func (s *Service) BeginIdempotentRequest(ctx context.Context, scope, key, requestHash string) (*domain.IdempotencyRecord, string, error) {
+	// Токен владельца отличает запрос от повтора, перехватившего ключ: только владелец сохраняет ответ
+	// или освобождает ключ
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", fmt.Errorf("failed to generate idempotency owner: %w", err)
+	}
+	owner := hex.EncodeToString(raw)
+
+	now := time.Now()
+	expiredBefore := now.Add(-s.cfg.Idempotency.KeyTTL)
+	// Без продления ключ в работе перехватывается только вместе с истекшими
+	abandonedBefore := expiredBefore
+	if s.cfg.Idempotency.HeartbeatInterval > 0 {
+		abandonedBefore = now.Add(-idempotencyMissedHeartbeats * s.cfg.Idempotency.HeartbeatInterval)
+	}
+	record, reserved, err := s.repo.ReserveIdempotencyKey(ctx, scope, key, owner, requestHash, expiredBefore, abandonedBefore)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, "", ErrIdempotencyKeyInProgress
+		}
+		return nil, "", err
+	}
+	if reserved {
+		return nil, owner, nil
+	}
+
+	if record.RequestHash != requestHash {
+		return nil, "", ErrIdempotencyKeyReused
+	}
+	if record.StatusCode == nil {
+		return nil, "", ErrIdempotencyKeyInProgress
+	}
+	return record, "", nil
+}
+
+// HeartbeatIdempotentRequest refreshes the key of a running request every heartbeat interval until ctx is done,
+// so that retries keep getting ErrIdempotencyKeyInProgress while the request runs.
+func (s *Service) HeartbeatIdempotentRequest(ctx context.Context, scope, key, owner string) {
+	if s.cfg.Idempotency.HeartbeatInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.cfg.Idempotency.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.repo.TouchIdempotencyKey(ctx, scope, key, owner); err != nil && ctx.Err() == nil {
+				logrus.Warn("Failed to refresh idempotency key: ", err)
+			}
+		}
+	}
+}
+
+// CompleteIdempotentRequest stores the response of a request so that retries with its key replay it.
+// A request whose key was taken over by a retry stores nothing.
+func (s *Service) CompleteIdempotentRequest(ctx context.Context, scope, key, owner string, statusCode int, body []byte) error {
+	return s.repo.CompleteIdempotencyKey(ctx, scope, key, owner, statusCode, body)
+}
+
+// ReleaseIdempotentRequest frees the key of a request that failed, so that a retry executes it again.
+// A key taken over by a retry stays with the retry.
+func (s *Service) ReleaseIdempotentRequest(ctx context.Context, scope, key, owner string) error {
+	return s.repo.ReleaseIdempotencyKey(ctx, scope, key, owner)
+}
+
+// PurgeIdempotencyKeys deletes idempotency keys older than the configured TTL.
+func (s *Service) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	return s.repo.PurgeIdempotencyKeys(ctx, time.Now().Add(-s.cfg.Idempotency.KeyTTL))
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/pkg/s3"
+)
+
+// integrationProbeTimeout bounds a reachability check of one integration.
+const integrationProbeTimeout = 3 * time.Second
+
+// degradedErrorRate is the share of failed recent calls at which an integration is reported degraded.
+const degradedErrorRate = 0.5
+
+// Prober checks that an external system is reachable. Integrations implementing it are probed
+// by the integrations status.
+type Prober interface {
+	Ping(ctx context.Context) error
+}
+
+// monitoredStorage records the outcome of document storage calls in the integration monitor.
+type monitoredStorage struct {
+	DocumentStorage
+	s *Service
+}
+
+// Put implements DocumentStorage.
+func (m monitoredStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	err := m.DocumentStorage.Put(ctx, key, r, size, contentType)
+	m.s.integrations.Record(domain.IntegrationObjectStorage, err)
+	return err
+}
+
+// Get implements DocumentStorage. A missing object is a successful call.
+func (m monitoredStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := m.DocumentStorage.Get(ctx, key)
+	if errors.Is(err, s3.ErrObjectNotFound) {
+		m.s.integrations.Record(domain.IntegrationObjectStorage, nil)
+	} else {
+		m.s.integrations.Record(domain.IntegrationObjectStorage, err)
+	}
+	return object, err
+}
+
+// Delete implements DocumentStorage.
+func (m monitoredStorage) Delete(ctx context.Context, key string) error {
+	err := m.DocumentStorage.Delete(ctx, key)
+	m.s.integrations.Record(domain.IntegrationObjectStorage, err)
+	return err
+}
+
+// IntegrationsStatus reports reachability and call statistics of every configured integration.
+func (s *Service) IntegrationsStatus(ctx context.Context) []*domain.IntegrationStatus {
+	statuses := []*domain.IntegrationStatus{}
+	for _, name := range s.integrations.Names() {
+		stats := s.integrations.Stats(name)
+		status := &domain.IntegrationStatus{
+			Name:          name,
+			Status:        domain.IntegrationUp,
+			Calls:         stats.Calls,
+			Failures:      stats.Failures,
+			ErrorRate:     stats.ErrorRate,
+			LastSuccessAt: stats.LastSuccessAt,
+			LastFailureAt: stats.LastFailureAt,
+			LastError:     stats.LastError,
+			CheckedAt:     time.Now(),
+		}
+		if stats.ErrorRate >= degradedErrorRate {
+			status.Status = domain.IntegrationDegraded
+		}
+
+		if prober, ok := s.probes[name]; ok {
+			probeCtx, cancel := context.WithTimeout(ctx, integrationProbeTimeout)
+			err := prober.Ping(probeCtx)
+			cancel()
+
+			reachable := err == nil
+			status.Reachable = &reachable
+			if err != nil {
+				status.Status = domain.IntegrationDown
+				status.ProbeError = err.Error()
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
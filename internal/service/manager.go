@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// ListManagers returns the managers matching the filter ordered by manager_id.
+// A user limited to a dealership gets only the managers of that dealership.
+func (s *Service) ListManagers(ctx context.Context, filter domain.ManagerFilter) ([]*domain.Manager, error) {
+	if filter.DealershipID != nil && *filter.DealershipID <= 0 {
+		return nil, fmt.Errorf("invalid dealership_id: %w", ErrInvalidInput)
+	}
+	if scope, ok := dealershipScope(ctx); ok {
+		if filter.DealershipID != nil && *filter.DealershipID != scope {
+			return []*domain.Manager{}, nil
+		}
+		filter.DealershipID = &scope
+	}
+
+	managers, err := s.repo.ListManagers(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list managers: %w", err)
+	}
+	if managers == nil {
+		managers = []*domain.Manager{}
+	}
+
+	return managers, nil
+}
+
+// GetManager returns a manager.
+func (s *Service) GetManager(ctx context.Context, managerID int) (*domain.Manager, error) {
+	if managerID <= 0 {
+		return nil, fmt.Errorf("invalid manager_id: %w", ErrInvalidInput)
+	}
+
+	manager, err := s.repo.GetManager(ctx, managerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("manager not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get manager: %w", err)
+	}
+	if !inDealershipScope(ctx, manager.DealershipID) {
+		return nil, fmt.Errorf("manager not found: %w", ErrNotFound)
+	}
+
+	return manager, nil
+}
+
+// CreateManager adds a manager of a dealership. A new manager is active.
+func (s *Service) CreateManager(ctx context.Context, req domain.Manager) (*domain.Manager, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	req.Active = true
+	if err := validateManager(req); err != nil {
+		return nil, err
+	}
+	if err := s.validateManagerDealership(ctx, req.DealershipID); err != nil {
+		return nil, err
+	}
+
+	manager, err := s.repo.CreateManager(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("manager %d already exists: %w", req.ManagerID, ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to create manager: %w", err)
+	}
+
+	return manager, nil
+}
+
+// UpdateManager changes the details of a manager, moves it to another dealership or deactivates it.
+// Existing deals keep their manager and dealership.
+func (s *Service) UpdateManager(ctx context.Context, req domain.Manager) (*domain.Manager, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if err := validateManager(req); err != nil {
+		return nil, err
+	}
+	// Пользователь дилерского центра не может забрать чужого менеджера
+	if _, err := s.GetManager(ctx, req.ManagerID); err != nil {
+		return nil, err
+	}
+	if err := s.validateManagerDealership(ctx, req.DealershipID); err != nil {
+		return nil, err
+	}
+
+	manager, err := s.repo.UpdateManager(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("manager not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to update manager: %w", err)
+	}
+
+	return manager, nil
+}
+
+// DeleteManager removes a manager without deals. A manager with deals is deactivated instead.
+func (s *Service) DeleteManager(ctx context.Context, managerID int) error {
+	if err := denyClientWrite(ctx); err != nil {
+		return err
+	}
+	if _, err := s.GetManager(ctx, managerID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteManager(ctx, managerID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return fmt.Errorf("manager not found: %w", ErrNotFound)
+		case errors.Is(err, repository.ErrConflict):
+			return fmt.Errorf("manager %d has deals, deactivate it instead: %w", managerID, ErrConflict)
+		}
+		return fmt.Errorf("failed to delete manager: %w", err)
+	}
+
+	return nil
+}
+
+// validateManager checks a manager record: positive IDs, a full name that fits the column and
+// well-formed contacts.
+func validateManager(manager domain.Manager) error {
+	if manager.ManagerID <= 0 {
+		return fmt.Errorf("invalid manager_id: %w", ErrInvalidInput)
+	}
+	if manager.DealershipID <= 0 {
+		return fmt.Errorf("invalid dealership_id: %w", ErrInvalidInput)
+	}
+	fullName := strings.TrimSpace(manager.FullName)
+	if fullName == "" {
+		return fmt.Errorf("full_name is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(fullName) > 255 {
+		return fmt.Errorf("full_name must not exceed 255 characters: %w", ErrInvalidInput)
+	}
+	if manager.Phone != "" && !phonePattern.MatchString(manager.Phone) {
+		return fmt.Errorf("phone must be 10 to 15 digits with an optional leading +: %w", ErrInvalidInput)
+	}
+	if manager.Email != "" {
+		address, err := mail.ParseAddress(manager.Email)
+		if err != nil || address.Address != manager.Email || len(manager.Email) > 255 {
+			return fmt.Errorf("invalid email: %w", ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+// validateManagerDealership checks that the dealership of a manager is known and the user may work with it.
+func (s *Service) validateManagerDealership(ctx context.Context, dealershipID int) error {
+	if err := checkDealershipScope(ctx, dealershipID); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetDealership(ctx, dealershipID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("unknown dealership_id %d: %w", dealershipID, ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to get dealership: %w", err)
+	}
+	return nil
+}
+
+// validateDealManager checks that the manager of a deal is an active manager of the deal's dealership.
+func (s *Service) validateDealManager(ctx context.Context, managerID, dealershipID int) error {
+	manager, err := s.repo.GetManager(ctx, managerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("unknown manager_id %d: %w", managerID, ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to get manager: %w", err)
+	}
+	if manager.DealershipID != dealershipID {
+		return fmt.Errorf("manager %d does not belong to dealership %d: %w", managerID, dealershipID, ErrInvalidInput)
+	}
+	if !manager.Active {
+		return fmt.Errorf("manager %d is inactive: %w", managerID, ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/netting"
+)
+
+// netOrders nets the orders together with the overdue penalties with the netting engine and rounds
+// the net positions with the rounding policy. An order type missing from the rules is invalid input.
+func netOrders(orders []*domain.Order, rules map[int]domain.NettingRule, rounding domain.RoundingPolicy,
+	penalties []*domain.SettlementPenalty) (*netting.Result, error) {
+	return runNetting(netting.NewEngine(rules).WithRounding(rounding).WithPenalties(penalties), orders)
+}
+
+// runNetting nets the orders with the engine and maps its errors to service errors.
+func runNetting(engine *netting.Engine, orders []*domain.Order) (*netting.Result, error) {
+	result, err := engine.Net(orders)
+	if err != nil {
+		if errors.Is(err, netting.ErrUnknownOrderType) {
+			return nil, fmt.Errorf("%v: %w", err, ErrInvalidInput)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// netSettlements performs a multilateral netting calculation over the orders of a deal.
+// Every bank and every dealership referenced by the orders is a separate participant with its own
+// settlement, so a deal financed by two banks gets a settlement per bank, and a trade-in at one
+// branch and a purchase at another produce an inter-branch settlement leg.
+// The settlements are dated asOf if set, otherwise now. With asOf set, orders planned
+// for execution after asOf are excluded. Orders in different currencies are netted in the pivot
+// currency, and the settlements record the exchange rates used.
+func (s *Service) netSettlements(ctx context.Context, dealID int, orders []*domain.Order, asOf *time.Time, rules map[int]domain.NettingRule) ([]*domain.MonetarySettlement, []*domain.SettlementLeg, error) {
+	now := time.Now()
+	if asOf != nil {
+		now = *asOf
+		orders = netting.ExecutableOrders(orders, *asOf)
+	}
+	// Валюта расчётов совпадает с валютой заказов сделки, для разных валют - валюта неттинга
+	orders, currencyCode, rates, err := s.convertOrders(ctx, orders, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	rounding, err := s.roundingPolicy(ctx, currencyCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Неустойка по просроченным расчетам учитывается в неттинге сделки; у сценария симуляции ее нет
+	var penalties []*domain.SettlementPenalty
+	if dealID > 0 {
+		penalties, err = s.dealPenalties(ctx, []int{dealID}, currencyCode, asOf)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	result, err := netOrders(orders, rules, rounding, penalties)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Создание денежных расчетов по ненулевым чистым позициям
+	var settlements []*domain.MonetarySettlement
+	for i, p := range result.Participants {
+		if result.Net[i] == 0 {
+			continue
+		}
+		var residual domain.Money
+		if i == result.ResidualIndex {
+			residual = result.Residual
+		}
+		settlements = append(settlements, &domain.MonetarySettlement{
+			MonetarySettlementID: 0, // Not saved in DB yet
+			DealID:               &dealID,
+			Amount:               result.Net[i], // Positive: owes, Negative: owed
+			Status:               domain.StatusPending,
+			CreatedAt:            now,
+			UpdatedAt:            now,
+			BankID:               p.RefFor(domain.ParticipantBank),
+			CurrencyCode:         currencyCode,
+			Participant:          p.Role,
+			DealershipID:         p.RefFor(domain.ParticipantDealership),
+			InsurerID:            p.RefFor(domain.ParticipantInsurer),
+			VATBreakdown:         result.VATBreakdowns[i],
+			FXRates:              rates,
+			RoundingResidual:     residual,
+		})
+	}
+	if err := s.applySettlementCurrency(ctx, settlements...); err != nil {
+		return nil, nil, err
+	}
+	if err := s.applySettlementDueDates(ctx, now, settlements...); err != nil {
+		return nil, nil, err
+	}
+
+	legs := result.Legs(currencyCode)
+	// Снимок сохраняется только для текущего состояния сделки, прогнозы на дату не версионируются
+	if asOf == nil && dealID > 0 {
+		if err := s.recordNettingSnapshot(ctx, dealID, orders, penalties, rules, rounding, settlements, legs); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return settlements, legs, nil
+}
+
+// grossSettlements settles every obligation between two participants separately instead of
+// their net positions: each settlement is paid by the debtor to the counterparty, and every
+// obligation is a transfer of its own. Gross results are not stored as netting snapshots.
+func (s *Service) grossSettlements(ctx context.Context, dealID int, orders []*domain.Order, asOf *time.Time, rules map[int]domain.NettingRule) ([]*domain.MonetarySettlement, []*domain.SettlementLeg, error) {
+	now := time.Now()
+	if asOf != nil {
+		now = *asOf
+		orders = netting.ExecutableOrders(orders, *asOf)
+	}
+	orders, currencyCode, rates, err := s.convertOrders(ctx, orders, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	penalties, err := s.dealPenalties(ctx, []int{dealID}, currencyCode, asOf)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Обязательства не сворачиваются, поэтому округляются только суммы пересчета по курсу
+	result, err := runNetting(netting.NewEngine(rules).WithPenalties(penalties), orders)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var settlements []*domain.MonetarySettlement
+	var legs []*domain.SettlementLeg
+	for _, edge := range result.Edges {
+		if edge.Amount == 0 {
+			continue
+		}
+		legs = append(legs, edge.Leg(currencyCode))
+		creditor := edge.Creditor
+		settlements = append(settlements, &domain.MonetarySettlement{
+			DealID:       &dealID,
+			Amount:       edge.Amount,
+			Status:       domain.StatusPending,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			BankID:       edge.Debtor.RefFor(domain.ParticipantBank),
+			CurrencyCode: currencyCode,
+			Participant:  edge.Debtor.Role,
+			DealershipID: edge.Debtor.RefFor(domain.ParticipantDealership),
+			InsurerID:    edge.Debtor.RefFor(domain.ParticipantInsurer),
+			VATBreakdown: edge.VATBreakdown,
+			Counterparty: &creditor,
+			FXRates:      rates,
+		})
+	}
+	if err := s.applySettlementCurrency(ctx, settlements...); err != nil {
+		return nil, nil, err
+	}
+	if err := s.applySettlementDueDates(ctx, now, settlements...); err != nil {
+		return nil, nil, err
+	}
+
+	return settlements, legs, nil
+}
+
+// isInterBranch reports whether the leg is a payment between two different dealerships.
+func isInterBranch(leg *domain.SettlementLeg) bool {
+	return leg.From == domain.ParticipantDealership && leg.To == domain.ParticipantDealership &&
+		leg.FromDealershipID != nil && leg.ToDealershipID != nil && *leg.FromDealershipID != *leg.ToDealershipID
+}
+
+// InterBranchReport aggregates inter-branch settlement legs of deals created in [from, to].
+// A user limited to a dealership gets only the legs the dealership pays or receives.
+func (s *Service) InterBranchReport(ctx context.Context, from, to string) (*domain.InterBranchReport, error) {
+	fromDate, err := time.Parse(domain.DateLayout, from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from %q: %w", from, ErrInvalidInput)
+	}
+	toDate, err := time.Parse(domain.DateLayout, to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to %q: %w", to, ErrInvalidInput)
+	}
+	if toDate.Before(fromDate) {
+		return nil, fmt.Errorf("to must not be before from: %w", ErrInvalidInput)
+	}
+
+	dealIDs, err := s.repo.ListMultiBranchDealIDs(ctx, fromDate, toDate.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list multi-branch deals: %w", err)
+	}
+
+	type pairKey struct {
+		from, to int
+		currency string
+	}
+	rules, err := s.nettingRules(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	aggregated := map[pairKey]*domain.InterBranchLeg{}
+	report := &domain.InterBranchReport{From: from, To: to, Legs: []*domain.InterBranchLeg{}}
+	for _, dealID := range dealIDs {
+		orders, err := s.repo.ListOrdersByDeals(ctx, dealID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list orders: %w", err)
+		}
+		orders, currencyCode, _, err := s.convertOrders(ctx, orders, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		rounding, err := s.roundingPolicy(ctx, currencyCode)
+		if err != nil {
+			return nil, err
+		}
+		result, err := netOrders(orders, rules, rounding, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, leg := range result.Legs(currencyCode) {
+			if !isInterBranch(leg) {
+				continue
+			}
+			if !inDealershipScope(ctx, *leg.FromDealershipID) && !inDealershipScope(ctx, *leg.ToDealershipID) {
+				continue
+			}
+			key := pairKey{from: *leg.FromDealershipID, to: *leg.ToDealershipID, currency: leg.CurrencyCode}
+			entry, ok := aggregated[key]
+			if !ok {
+				entry = &domain.InterBranchLeg{
+					FromDealershipID: key.from,
+					ToDealershipID:   key.to,
+					CurrencyCode:     key.currency,
+				}
+				aggregated[key] = entry
+				report.Legs = append(report.Legs, entry)
+			}
+			entry.Amount += leg.Amount
+			entry.DealIDs = append(entry.DealIDs, dealID)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+	"cliring/pkg/pdf"
+)
+
+// DocumentRenderer renders documents to PDF.
+type DocumentRenderer interface {
+	Render(doc *pdf.Document) ([]byte, error)
+}
+
+// WithDocumentRenderer sets the renderer of netting acts.
+func (s *Service) WithDocumentRenderer(renderer DocumentRenderer) *Service {
+	s.renderer = renderer
+	return s
+}
+
+// NettingAct renders the netting act of a deal (акт взаимозачета) to PDF: the parties with their details,
+// the obligations offset against each other, the resulting net amounts and signature blocks of the parties.
+func (s *Service) NettingAct(ctx context.Context, dealID int) ([]byte, error) {
+	if s.renderer == nil {
+		return nil, errors.New("document renderer is not configured")
+	}
+
+	explanation, err := s.ExplainNetting(ctx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	if len(explanation.Obligations) == 0 {
+		return nil, fmt.Errorf("deal has no obligations to offset: %w", ErrConflict)
+	}
+	deal, err := s.repo.GetDeal(ctx, dealID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+	currencies, err := s.currencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	currency := currencyOrDefault(currencies, explanation.CurrencyCode)
+
+	// Реквизиты сторон берутся из справочника payment_details, ФИО клиента без реквизитов - из карточки клиента;
+	// клиент сделки определяется по сделке
+	names := map[string]string{}
+	parties := &pdf.Table{
+		Header: []string{"Сторона", "Наименование", "ИНН", "КПП"},
+		Widths: []float64{40, 80, 30, 30},
+	}
+	var signatures []pdf.Signature
+	for _, position := range explanation.Positions {
+		participant := position.Participant
+		if participant.Role == domain.ParticipantClient && participant.ExternalID == nil {
+			participant.ExternalID = &deal.ClientID
+		}
+		details, err := s.paymentDetails(ctx, participant.ID())
+		if err != nil {
+			return nil, err
+		}
+		name := details.Name
+		if name == "" && participant.Role == domain.ParticipantClient && participant.ExternalID != nil {
+			client, err := s.repo.GetClient(ctx, *participant.ExternalID)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return nil, fmt.Errorf("failed to get client: %w", err)
+			}
+			if client != nil {
+				name = client.FullName
+			}
+		}
+		if name == "" {
+			name = participantTitle(participant)
+		}
+		names[position.Participant.ID()] = name
+		parties.Rows = append(parties.Rows, []string{participantTitle(participant), name, details.INN, details.KPP})
+		signatures = append(signatures, pdf.Signature{Party: participantTitle(participant), Name: name})
+	}
+
+	obligations := &pdf.Table{
+		Header: []string{"Должник", "Кредитор", "Сумма", "Заказы"},
+		Widths: []float64{50, 50, 40, 40},
+	}
+	for _, obligation := range explanation.Obligations {
+		orderIDs := make([]string, 0, len(obligation.OrderIDs))
+		for _, orderID := range obligation.OrderIDs {
+			orderIDs = append(orderIDs, "№"+strconv.Itoa(orderID))
+		}
+		obligations.Rows = append(obligations.Rows, []string{
+			names[obligation.Debtor.ID()], names[obligation.Creditor.ID()],
+			formatAmount(obligation.Amount, currency), strings.Join(orderIDs, ", "),
+		})
+	}
+
+	positions := &pdf.Table{
+		Header: []string{"Сторона", "Обязательства стороны", "Требования стороны", "Итог после зачета"},
+		Widths: []float64{54, 42, 42, 42},
+	}
+	for _, position := range explanation.Positions {
+		result := "Обязательства прекращены"
+		switch {
+		case position.Net > 0:
+			result = "К оплате " + formatAmount(position.Net, currency)
+		case position.Net < 0:
+			result = "К получению " + formatAmount(position.Net.Abs(), currency)
+		}
+		positions.Rows = append(positions.Rows, []string{
+			names[position.Participant.ID()], formatAmount(position.GrossOut, currency),
+			formatAmount(position.GrossIn, currency), result,
+		})
+	}
+
+	now := time.Now()
+	doc := &pdf.Document{
+		Title:    fmt.Sprintf("Акт взаимозачета по сделке № %d", dealID),
+		Subtitle: "от " + now.Format("02.01.2006"),
+		Sections: []pdf.Section{
+			{Heading: "1. Стороны", Table: parties},
+			{
+				Heading: "2. Встречные обязательства",
+				Paragraphs: []string{
+					"Стороны подтверждают наличие следующих обязательств, возникших по заказам сделки:",
+				},
+				Table: obligations,
+			},
+			{
+				Heading: "3. Зачет",
+				Paragraphs: []string{
+					"Стороны произвели зачет встречных однородных требований в соответствии со статьей 410 " +
+						"Гражданского кодекса Российской Федерации. После зачета обязательства сторон составляют:",
+				},
+				Table: positions,
+			},
+			{
+				Paragraphs: []string{
+					"Акт составлен в электронном виде и подписывается каждой из сторон. " +
+						"Обязательства в части зачтенных сумм прекращаются с даты подписания акта всеми сторонами.",
+				},
+			},
+		},
+		Signatures: signatures,
+	}
+	if len(explanation.FXRates) > 0 {
+		var rates []string
+		for _, rate := range explanation.FXRates {
+			rates = append(rates, fmt.Sprintf("%s — %g %s (курс от %s, версия %d)",
+				rate.CurrencyCode, rate.Rate, rate.PivotCurrencyCode, rate.RateDate, rate.RateID))
+		}
+		doc.Sections[1].Paragraphs = append(doc.Sections[1].Paragraphs,
+			"Обязательства в иностранной валюте пересчитаны по курсам: "+strings.Join(rates, "; ")+".")
+	}
+
+	act, err := s.renderer.Render(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render netting act: %w", err)
+	}
+	return act, nil
+}
+
+// participantTitle names a participant without known details, e.g. "Дилерский центр №12".
+func participantTitle(p domain.Participant) string {
+	var title string
+	switch p.Role {
+	case domain.ParticipantClient:
+		title = "Клиент"
+	case domain.ParticipantBank:
+		title = "Банк"
+	case domain.ParticipantDealership:
+		title = "Дилерский центр"
+	case domain.ParticipantInsurer:
+		title = "Страховая компания"
+	default:
+		title = p.Role
+	}
+	if p.ExternalID != nil {
+		title += " №" + strconv.Itoa(*p.ExternalID)
+	}
+	return title
+}
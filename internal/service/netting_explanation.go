@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// ExplainNetting returns the obligation matrix of a deal, the gross amounts every participant owes
+// and is owed, and the derivation of every net position from the obligations.
+func (s *Service) ExplainNetting(ctx context.Context, dealID int) (*domain.NettingExplanation, error) {
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+
+	_, orders, err := s.repo.GetDealWithOrders(ctx, dealID, domain.OrderSort{})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+	orders, currencyCode, rates, err := s.convertOrders(ctx, orders, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.nettingRules(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	currencies, err := s.currencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	currency := currencyOrDefault(currencies, currencyCode)
+	penalties, err := s.dealPenalties(ctx, []int{dealID}, currency.Code, nil)
+	if err != nil {
+		return nil, err
+	}
+	result, err := netOrders(orders, rules, currency.Rounding, penalties)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &domain.NettingExplanation{
+		DealID:           dealID,
+		CurrencyCode:     currency.Code,
+		Obligations:      []*domain.NettingObligation{},
+		Positions:        []*domain.NettingPosition{},
+		ExcludedOrderIDs: []int{},
+		FXRates:          rates,
+		Rounding:         currency.Rounding,
+	}
+	netted := map[int]bool{}
+	for _, edge := range result.Edges {
+		explanation.Obligations = append(explanation.Obligations, &domain.NettingObligation{
+			Debtor:     edge.Debtor,
+			Creditor:   edge.Creditor,
+			Amount:     edge.Amount,
+			OrderIDs:   edge.OrderIDs,
+			PenaltyIDs: edge.PenaltyIDs,
+		})
+		for _, orderID := range edge.OrderIDs {
+			netted[orderID] = true
+		}
+	}
+	for _, order := range orders {
+		if !netted[order.OrderID] {
+			explanation.ExcludedOrderIDs = append(explanation.ExcludedOrderIDs, order.OrderID)
+		}
+	}
+
+	for i, p := range result.Participants {
+		position := &domain.NettingPosition{Participant: p, Net: result.Net[i], Rounding: result.Rounding[i]}
+		// Слагаемые: долг участника перед контрагентом со знаком плюс, долг контрагента перед участником - со знаком минус
+		var terms []string
+		for _, edge := range result.Edges {
+			switch p.ID() {
+			case edge.Debtor.ID():
+				position.GrossOut += edge.Amount
+				terms = append(terms, derivationTerm(len(terms) == 0, edge.Amount, currency, edge.Creditor))
+			case edge.Creditor.ID():
+				position.GrossIn += edge.Amount
+				terms = append(terms, derivationTerm(len(terms) == 0, -edge.Amount, currency, edge.Debtor))
+			}
+		}
+		if len(terms) == 0 {
+			terms = append(terms, formatAmount(0, currency))
+		}
+		if position.Rounding != 0 {
+			terms = append(terms, roundingTerm(position.Rounding, currency))
+		}
+		position.Derivation = strings.Join(terms, " ") + " = " + formatAmount(position.Net, currency)
+		explanation.Positions = append(explanation.Positions, position)
+	}
+
+	return explanation, nil
+}
+
+// roundingTerm formats the rounding adjustment of a net position as a term of its sum,
+// e.g. "+ 0,40 ₽ (округление)".
+func roundingTerm(amount domain.Money, currency *domain.Currency) string {
+	sign := "+ "
+	if amount < 0 {
+		sign = "− "
+	}
+	return sign + formatAmount(amount.Abs(), currency) + " (округление)"
+}
+
+// derivationTerm formats an obligation of a participant as a term of its net position sum,
+// naming the counterparty, e.g. "− 1 200 000,00 ₽ (bank:1)".
+func derivationTerm(first bool, amount domain.Money, currency *domain.Currency, counterparty domain.Participant) string {
+	sign := "+ "
+	if amount < 0 {
+		sign = "− "
+	}
+	if first && amount >= 0 {
+		sign = ""
+	}
+	return sign + formatAmount(amount.Abs(), currency) + " (" + counterparty.ID() + ")"
+}
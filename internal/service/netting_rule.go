@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// ListNettingRules returns the configured netting rules, optionally only those of one order type.
+func (s *Service) ListNettingRules(ctx context.Context, orderTypeID int) ([]*domain.NettingRuleConfig, error) {
+	if orderTypeID < 0 {
+		return nil, fmt.Errorf("invalid order_type_id: %w", ErrInvalidInput)
+	}
+
+	rules, err := s.repo.ListNettingRules(ctx, orderTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list netting rules: %w", err)
+	}
+	if rules == nil {
+		rules = []*domain.NettingRuleConfig{}
+	}
+
+	return rules, nil
+}
+
+// CreateNettingRule adds a netting rule of an order type for a validity period.
+// It takes effect on the next netting run that falls into the period.
+func (s *Service) CreateNettingRule(ctx context.Context, req domain.NettingRuleConfig) (*domain.NettingRuleConfig, error) {
+	if err := s.validateNettingRuleConfig(ctx, req); err != nil {
+		return nil, err
+	}
+	req.RuleID = 0
+	req.CreatedBy, _ = ctx.Value(domain.ActorKey{}).(string)
+
+	rule, err := s.repo.CreateNettingRule(ctx, req)
+	if err != nil {
+		return nil, nettingRuleError(err, req)
+	}
+
+	return rule, nil
+}
+
+// UpdateNettingRule replaces a configured netting rule. The order type of a rule cannot be changed.
+func (s *Service) UpdateNettingRule(ctx context.Context, req domain.NettingRuleConfig) (*domain.NettingRuleConfig, error) {
+	if req.RuleID <= 0 {
+		return nil, fmt.Errorf("invalid rule_id: %w", ErrInvalidInput)
+	}
+	current, err := s.repo.GetNettingRule(ctx, req.RuleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("netting rule not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get netting rule: %w", err)
+	}
+	if req.OrderTypeID == 0 {
+		req.OrderTypeID = current.OrderTypeID
+	}
+	if req.OrderTypeID != current.OrderTypeID {
+		return nil, fmt.Errorf("order_type_id of a netting rule cannot be changed: %w", ErrInvalidInput)
+	}
+	if err := s.validateNettingRuleConfig(ctx, req); err != nil {
+		return nil, err
+	}
+
+	rule, err := s.repo.UpdateNettingRule(ctx, req)
+	if err != nil {
+		return nil, nettingRuleError(err, req)
+	}
+
+	return rule, nil
+}
+
+// DeleteNettingRule removes a configured netting rule; the catalog rule applies again for its period.
+func (s *Service) DeleteNettingRule(ctx context.Context, ruleID int) error {
+	if ruleID <= 0 {
+		return fmt.Errorf("invalid rule_id: %w", ErrInvalidInput)
+	}
+
+	if err := s.repo.DeleteNettingRule(ctx, ruleID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("netting rule not found: %w", ErrNotFound)
+		}
+		return fmt.Errorf("failed to delete netting rule: %w", err)
+	}
+
+	return nil
+}
+
+// nettingRuleError maps a repository error of saving a netting rule to a service error.
+func nettingRuleError(err error, req domain.NettingRuleConfig) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return fmt.Errorf("netting rule not found: %w", ErrNotFound)
+	case errors.Is(err, repository.ErrConflict):
+		return fmt.Errorf("validity period overlaps another netting rule of order type %d: %w", req.OrderTypeID, ErrConflict)
+	case errors.Is(err, repository.ErrInvalidInput):
+		return fmt.Errorf("invalid netting rule: %w", ErrInvalidInput)
+	}
+	return fmt.Errorf("failed to save netting rule: %w", err)
+}
+
+// validateNettingRuleConfig checks a configured netting rule: a known order type, a valid pair of
+// participants if they are overridden, consistent amount bounds and validity period.
+func (s *Service) validateNettingRuleConfig(ctx context.Context, rule domain.NettingRuleConfig) error {
+	if err := s.validateOrderTypeID(ctx, rule.OrderTypeID); err != nil {
+		return err
+	}
+	if rule.Debtor != "" || rule.Creditor != "" {
+		err := validateNettingRule(domain.NettingRule{OrderTypeID: rule.OrderTypeID, Debtor: rule.Debtor, Creditor: rule.Creditor})
+		if err != nil {
+			return err
+		}
+	}
+	if rule.MinAmount != nil && *rule.MinAmount < 0 {
+		return fmt.Errorf("min_amount must not be negative: %w", ErrInvalidInput)
+	}
+	if rule.MaxAmount != nil && *rule.MaxAmount < 0 {
+		return fmt.Errorf("max_amount must not be negative: %w", ErrInvalidInput)
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return fmt.Errorf("min_amount must not exceed max_amount: %w", ErrInvalidInput)
+	}
+
+	validFrom, err := time.Parse(domain.DateLayout, rule.ValidFrom)
+	if err != nil {
+		return fmt.Errorf("valid_from must be a date in %s format: %w", domain.DateLayout, ErrInvalidInput)
+	}
+	if rule.ValidTo != "" {
+		validTo, err := time.Parse(domain.DateLayout, rule.ValidTo)
+		if err != nil {
+			return fmt.Errorf("valid_to must be a date in %s format: %w", domain.DateLayout, ErrInvalidInput)
+		}
+		if validTo.Before(validFrom) {
+			return fmt.Errorf("valid_to must not be before valid_from: %w", ErrInvalidInput)
+		}
+	}
+	if utf8.RuneCountInString(strings.TrimSpace(rule.Comment)) > 500 {
+		return fmt.Errorf("comment must not exceed 500 characters: %w", ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"cliring/internal/domain"
+)
+
+// recordNettingSnapshot stores the netting result of a deal as a new snapshot version
+// if the netting input changed since the latest snapshot.
+func (s *Service) recordNettingSnapshot(ctx context.Context, dealID int, orders []*domain.Order, penalties []*domain.SettlementPenalty,
+	rules map[int]domain.NettingRule, rounding domain.RoundingPolicy, settlements []*domain.MonetarySettlement, legs []*domain.SettlementLeg) error {
+	inputHash, orderIDs := nettingInputHash(orders, penalties, rules, rounding)
+	snapshot := &domain.NettingSnapshot{
+		DealID:    dealID,
+		InputHash: inputHash,
+		OrderIDs:  orderIDs,
+		Positions: settlements,
+		Legs:      legs,
+	}
+	if snapshot.Positions == nil {
+		snapshot.Positions = []*domain.MonetarySettlement{}
+	}
+	if snapshot.Legs == nil {
+		snapshot.Legs = []*domain.SettlementLeg{}
+	}
+
+	if _, err := s.repo.SaveNettingSnapshot(ctx, snapshot); err != nil {
+		return err
+	}
+	return nil
+}
+
+// nettingInputHash returns the SHA-256 of the order fields, penalties, netting rules and rounding policy
+// the netting result depends on, together with the sorted IDs of the orders.
+func nettingInputHash(orders []*domain.Order, penalties []*domain.SettlementPenalty, rules map[int]domain.NettingRule,
+	rounding domain.RoundingPolicy) (string, []int) {
+	sorted := append([]*domain.Order(nil), orders...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].OrderID < sorted[j].OrderID
+	})
+
+	hash := sha256.New()
+	orderIDs := make([]int, 0, len(sorted))
+	for _, order := range sorted {
+		orderIDs = append(orderIDs, order.OrderID)
+		fmt.Fprintf(hash, "order|%d|%d|%s|%s|%s|%s|%s|%s|%g|%s|%s|%s\n",
+			order.OrderID, order.OrderTypeID, order.Status, order.ReviewStatus, order.CurrencyCode,
+			order.GrossAmount, order.NetAmount, order.VATAmount, order.VATRate,
+			optionalID(order.BankID), optionalID(order.DealershipID), optionalID(order.InsurerID))
+		// Строка добавляется только для удержанных заказов, чтобы хеши прежних снимков не изменились
+		if order.AwaitingCreditContract() {
+			fmt.Fprintf(hash, "hold|%d|credit_contract\n", order.OrderID)
+		}
+	}
+
+	// Неустойки упорядочены по дате начисления и идентификатору, их порядок стабилен
+	for _, penalty := range penalties {
+		fmt.Fprintf(hash, "penalty|%d|%s|%s\n", penalty.PenaltyID, penalty.Debtor.ID(), penalty.Amount)
+	}
+
+	orderTypeIDs := make([]int, 0, len(rules))
+	for orderTypeID := range rules {
+		orderTypeIDs = append(orderTypeIDs, orderTypeID)
+	}
+	sort.Ints(orderTypeIDs)
+	for _, orderTypeID := range orderTypeIDs {
+		rule := rules[orderTypeID]
+		fmt.Fprintf(hash, "rule|%d|%s|%s|%t|%s|%s\n", orderTypeID, rule.Debtor, rule.Creditor, rule.Excluded,
+			optionalMoney(rule.MinAmount), optionalMoney(rule.MaxAmount))
+	}
+	fmt.Fprintf(hash, "rounding|%s|%d|%s\n", rounding.Mode, rounding.Precision, rounding.Residual)
+
+	return hex.EncodeToString(hash.Sum(nil)), orderIDs
+}
+
+// optionalID formats an optional reference for hashing.
+func optionalID(id *int) string {
+	if id == nil {
+		return "-"
+	}
+	return strconv.Itoa(*id)
+}
+
+// optionalMoney formats an optional amount for hashing.
+func optionalMoney(amount *domain.Money) string {
+	if amount == nil {
+		return "-"
+	}
+	return amount.String()
+}
+
+// ListNettingSnapshots retrieves a page of the netting snapshots of a deal, newest first.
+func (s *Service) ListNettingSnapshots(ctx context.Context, dealID, page, limit int) (*domain.NettingSnapshotList, error) {
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if page == 0 {
+		page = 1
+	}
+	if page < 0 {
+		return nil, fmt.Errorf("page must be positive: %w", ErrInvalidInput)
+	}
+	if limit == 0 {
+		limit = defaultSettlementsLimit
+	}
+	if limit < 0 || limit > maxSettlementsLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d: %w", maxSettlementsLimit, ErrInvalidInput)
+	}
+
+	snapshots, total, err := s.repo.ListNettingSnapshots(ctx, dealID, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list netting snapshots: %w", err)
+	}
+	if snapshots == nil {
+		snapshots = []*domain.NettingSnapshot{}
+	}
+
+	return &domain.NettingSnapshotList{
+		Snapshots: snapshots,
+		Page:      page,
+		Limit:     limit,
+		Total:     total,
+		HasNext:   page*limit < total,
+	}, nil
+}
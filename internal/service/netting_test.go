@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+
+	"cliring/internal/domain"
+	"cliring/internal/service"
+)
+
+// orderTypeRefund is the refund order type of the catalog: the dealership owes the client.
+const orderTypeRefund = 4
+
+// settlementKey identifies the participant of a settlement in test expectations.
+func settlementKey(settlement *domain.MonetarySettlement) string {
+	switch {
+	case settlement.BankID != nil:
+		return fmt.Sprintf("%s %d", settlement.Participant, *settlement.BankID)
+	case settlement.DealershipID != nil:
+		return fmt.Sprintf("%s %d", settlement.Participant, *settlement.DealershipID)
+	}
+	return settlement.Participant
+}
+
+// TestListMonetarySettlementsNet nets a deal financed by two banks on every backend, so the in-memory
+// repository yields the same positions as Postgres: a settlement per bank, the client's down payment
+// and the dealership owed the purchase less the refund.
+func TestListMonetarySettlementsNet(t *testing.T) {
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx := asAdmin(b.ctx)
+			svc := service.NewService(b.repo, testConfig(t))
+			f := newFixture(t, ctx, svc)
+			dealID := newActiveDeal(t, ctx, svc, f, 1)
+
+			firstCredit := newCreditContract(t, ctx, svc, dealID, f.bankIDs[0], domain.MoneyFromFloat(600_000))
+			secondCredit := newCreditContract(t, ctx, svc, dealID, f.bankIDs[1], domain.MoneyFromFloat(200_000))
+
+			orders := []domain.OrderCreate{
+				{DealID: dealID, OrderTypeID: domain.OrderTypePurchase, Amount: domain.MoneyFromFloat(1_000_000)},
+				{DealID: dealID, OrderTypeID: domain.OrderTypeCredit, Amount: domain.MoneyFromFloat(600_000), CreditContractID: firstCredit},
+				{DealID: dealID, OrderTypeID: domain.OrderTypeCredit, Amount: domain.MoneyFromFloat(200_000), CreditContractID: secondCredit},
+				{DealID: dealID, OrderTypeID: orderTypeRefund, Amount: domain.MoneyFromFloat(50_000)},
+			}
+			if _, err := svc.CreateOrders(ctx, f.clientID, orders); err != nil {
+				t.Fatalf("create orders: %v", err)
+			}
+
+			settlements, _, err := svc.ListMonetarySettlements(ctx, dealID, nil, domain.SettlementModeNet)
+			if err != nil {
+				t.Fatalf("list monetary settlements: %v", err)
+			}
+
+			want := map[string]domain.Money{
+				domain.ParticipantClient:                                           domain.MoneyFromFloat(150_000),
+				fmt.Sprintf("%s %d", domain.ParticipantBank, f.bankIDs[0]):         domain.MoneyFromFloat(600_000),
+				fmt.Sprintf("%s %d", domain.ParticipantBank, f.bankIDs[1]):         domain.MoneyFromFloat(200_000),
+				fmt.Sprintf("%s %d", domain.ParticipantDealership, f.dealershipID): domain.MoneyFromFloat(-950_000),
+			}
+			got := map[string]domain.Money{}
+			var total domain.Money
+			for _, settlement := range settlements {
+				got[settlementKey(settlement)] = settlement.Amount
+				total += settlement.Amount
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got settlements %v, want %v", got, want)
+			}
+			for key, amount := range want {
+				if got[key] != amount {
+					t.Errorf("settlement of %s = %s, want %s", key, got[key], amount)
+				}
+			}
+			if total != 0 {
+				t.Errorf("net positions sum to %s, want 0", total)
+			}
+		})
+	}
+}
+
+// TestExecuteMonetarySettlements stores the payable positions and allocates them to the orders of the deal.
+func TestExecuteMonetarySettlements(t *testing.T) {
+	for _, b := range backends(t) {
+		t.Run(b.name, func(t *testing.T) {
+			ctx := asAdmin(b.ctx)
+			svc := service.NewService(b.repo, testConfig(t))
+			f := newFixture(t, ctx, svc)
+			dealID := newActiveDeal(t, ctx, svc, f, 1)
+
+			credit := newCreditContract(t, ctx, svc, dealID, f.bankIDs[0], domain.MoneyFromFloat(300_000))
+
+			orders := []domain.OrderCreate{
+				{DealID: dealID, OrderTypeID: domain.OrderTypePurchase, Amount: domain.MoneyFromFloat(500_000)},
+				{DealID: dealID, OrderTypeID: domain.OrderTypeCredit, Amount: domain.MoneyFromFloat(300_000), CreditContractID: credit},
+			}
+			if _, err := svc.CreateOrders(ctx, f.clientID, orders); err != nil {
+				t.Fatalf("create orders: %v", err)
+			}
+
+			executed, err := svc.ExecuteMonetarySettlements(ctx, dealID)
+			if err != nil {
+				t.Fatalf("execute monetary settlements: %v", err)
+			}
+			got := map[string]domain.Money{}
+			for _, settlement := range executed {
+				if settlement.MonetarySettlementID <= 0 {
+					t.Errorf("settlement of %s is not stored", settlementKey(settlement))
+				}
+				got[settlementKey(settlement)] = settlement.Amount
+			}
+			want := map[string]domain.Money{
+				domain.ParticipantClient:                                   domain.MoneyFromFloat(200_000),
+				fmt.Sprintf("%s %d", domain.ParticipantBank, f.bankIDs[0]): domain.MoneyFromFloat(300_000),
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got executed settlements %v, want %v", got, want)
+			}
+			for key, amount := range want {
+				if got[key] != amount {
+					t.Errorf("executed settlement of %s = %s, want %s", key, got[key], amount)
+				}
+			}
+		})
+	}
+}
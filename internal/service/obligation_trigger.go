@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// ListObligationTriggers returns the obligation triggers ordered by ID.
+func (s *Service) ListObligationTriggers(ctx context.Context) ([]*domain.ObligationTrigger, error) {
+	triggers, err := s.repo.ListObligationTriggers(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list obligation triggers: %w", err)
+	}
+	if triggers == nil {
+		triggers = []*domain.ObligationTrigger{}
+	}
+
+	return triggers, nil
+}
+
+// CreateObligationTrigger adds a trigger that generates a fee or penalty order in the deals
+// it fires for. Deals that already match the trigger get the order on the next run of the job.
+func (s *Service) CreateObligationTrigger(ctx context.Context, req domain.ObligationTrigger) (*domain.ObligationTrigger, error) {
+	if req.CurrencyCode == "" {
+		req.CurrencyCode = domain.DefaultCurrencyCode
+	}
+	if err := s.validateObligationTrigger(ctx, req); err != nil {
+		return nil, err
+	}
+	req.CreatedBy, _ = ctx.Value(domain.ActorKey{}).(string)
+
+	trigger, err := s.repo.CreateObligationTrigger(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create obligation trigger: %w", err)
+	}
+
+	return trigger, nil
+}
+
+// UpdateObligationTrigger replaces an obligation trigger. Orders it already generated are not changed.
+func (s *Service) UpdateObligationTrigger(ctx context.Context, req domain.ObligationTrigger) (*domain.ObligationTrigger, error) {
+	if req.TriggerID <= 0 {
+		return nil, fmt.Errorf("invalid trigger_id: %w", ErrInvalidInput)
+	}
+	if req.CurrencyCode == "" {
+		req.CurrencyCode = domain.DefaultCurrencyCode
+	}
+	if err := s.validateObligationTrigger(ctx, req); err != nil {
+		return nil, err
+	}
+
+	trigger, err := s.repo.UpdateObligationTrigger(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("obligation trigger not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to update obligation trigger: %w", err)
+	}
+
+	return trigger, nil
+}
+
+// DeleteObligationTrigger removes an obligation trigger; the orders it generated stay in their deals.
+func (s *Service) DeleteObligationTrigger(ctx context.Context, triggerID int) error {
+	if triggerID <= 0 {
+		return fmt.Errorf("invalid trigger_id: %w", ErrInvalidInput)
+	}
+
+	if err := s.repo.DeleteObligationTrigger(ctx, triggerID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("obligation trigger not found: %w", ErrNotFound)
+		}
+		return fmt.Errorf("failed to delete obligation trigger: %w", err)
+	}
+
+	return nil
+}
+
+// validateObligationTrigger checks a trigger: a fee or penalty order type whose obligation is between
+// the client and the dealership of the deal, a known event and currency and a valid order amount.
+func (s *Service) validateObligationTrigger(ctx context.Context, trigger domain.ObligationTrigger) error {
+	if err := s.validateOrderTypeID(ctx, trigger.OrderTypeID); err != nil {
+		return err
+	}
+	types, err := s.orderTypes(ctx)
+	if err != nil {
+		return err
+	}
+	orderType := types[trigger.OrderTypeID]
+	if orderType.Kind != domain.OrderKindFee && orderType.Kind != domain.OrderKindPenalty {
+		return fmt.Errorf("order type %d is not a fee or penalty: %w", trigger.OrderTypeID, ErrInvalidInput)
+	}
+	// Сгенерированный заказ не указывает банк и страховую компанию, поэтому обязательство - только между клиентом и дилерским центром
+	for _, participant := range []string{orderType.Debtor, orderType.Creditor} {
+		if participant != domain.ParticipantClient && participant != domain.ParticipantDealership {
+			return fmt.Errorf("order type %d must be an obligation between client and dealership: %w",
+				trigger.OrderTypeID, ErrInvalidInput)
+		}
+	}
+
+	switch trigger.Event {
+	case domain.TriggerDealCancelled, domain.TriggerDealOpen:
+	default:
+		return fmt.Errorf("event must be one of %s, %s: %w", domain.TriggerDealCancelled, domain.TriggerDealOpen, ErrInvalidInput)
+	}
+	if trigger.DelayDays < 0 {
+		return fmt.Errorf("delay_days must not be negative: %w", ErrInvalidInput)
+	}
+	if trigger.Amount <= 0 {
+		return fmt.Errorf("amount must be positive: %w", ErrInvalidInput)
+	}
+	currencies, err := s.currencies(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := currencies[trigger.CurrencyCode]; !ok {
+		return fmt.Errorf("unknown currency_code %s: %w", trigger.CurrencyCode, ErrInvalidInput)
+	}
+	if err := priceOrder(&domain.Order{Amount: trigger.Amount, VATRate: trigger.VATRate}); err != nil {
+		return err
+	}
+	if utf8.RuneCountInString(strings.TrimSpace(trigger.Comment)) > 500 {
+		return fmt.Errorf("comment must not exceed 500 characters: %w", ErrInvalidInput)
+	}
+	return nil
+}
+
+// FireObligationTriggers generates the orders of the active triggers in the deals they fire for
+// at the moment. It returns the number of orders generated.
+func (s *Service) FireObligationTriggers(ctx context.Context, now time.Time) (int, error) {
+	triggers, err := s.repo.ListObligationTriggers(ctx, true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list obligation triggers: %w", err)
+	}
+
+	generated := 0
+	for _, trigger := range triggers {
+		deals, err := s.repo.ListTriggerDeals(ctx, trigger, now)
+		if err != nil {
+			return generated, fmt.Errorf("failed to list deals of trigger %d: %w", trigger.TriggerID, err)
+		}
+		for _, deal := range deals {
+			ok, err := s.fireObligationTrigger(ctx, trigger, deal)
+			if err != nil {
+				return generated, err
+			}
+			if ok {
+				generated++
+			}
+		}
+	}
+	return generated, nil
+}
+
+// fireDealTriggers generates the orders of the active triggers of the event that fire for the deal,
+// e.g. the cancellation penalty right after the deal is cancelled.
+func (s *Service) fireDealTriggers(ctx context.Context, deal *domain.Deal, event string) error {
+	triggers, err := s.repo.ListObligationTriggers(ctx, true)
+	if err != nil {
+		return fmt.Errorf("failed to list obligation triggers: %w", err)
+	}
+
+	now := time.Now()
+	for _, trigger := range triggers {
+		if trigger.Event != event || !trigger.Due(deal, now) {
+			continue
+		}
+		if _, err := s.fireObligationTrigger(ctx, trigger, deal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fireObligationTrigger adds the order of the trigger to the deal. It reports false if the trigger
+// has already fired for the deal.
+func (s *Service) fireObligationTrigger(ctx context.Context, trigger *domain.ObligationTrigger, deal *domain.Deal) (bool, error) {
+	triggerID := trigger.TriggerID
+	order := &domain.Order{
+		DealID:       deal.DealID,
+		OrderTypeID:  trigger.OrderTypeID,
+		Amount:       trigger.Amount,
+		Status:       domain.StatusPending,
+		CurrencyCode: trigger.CurrencyCode,
+		DealershipID: &deal.DealershipID,
+		VATRate:      trigger.VATRate,
+		TriggerID:    &triggerID,
+	}
+	if err := priceOrder(order); err != nil {
+		return false, err
+	}
+
+	if _, err := s.repo.CreateOrders(ctx, []*domain.Order{order}); err != nil {
+		// Заказ триггера в сделке уникален: параллельный запуск уже создал его
+		var constraintErr *domain.ConstraintError
+		if errors.As(err, &constraintErr) && constraintErr.Kind == domain.ConstraintUnique {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create order of trigger %d in deal %d: %w", trigger.TriggerID, deal.DealID, err)
+	}
+	return true, nil
+}
+
+// RunObligationTriggers periodically fires the obligation triggers until ctx is cancelled.
+func (s *Service) RunObligationTriggers(ctx context.Context) {
+	interval := s.cfg.Obligation.TriggerInterval
+	if interval <= 0 {
+		logrus.Info("Obligation triggers disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			generated, err := s.FireObligationTriggers(ctx, time.Now())
+			if err != nil {
+				logrus.Error("Obligation triggers failed: ", err)
+				continue
+			}
+			if generated > 0 {
+				logrus.WithField("generated", generated).Info("Obligation triggers completed")
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+	"cliring/pkg/s3"
+)
+
+// UploadOrderInvoice stores an invoice or receipt file and attaches it to the client's order.
+// A previously attached invoice is replaced and its content removed from the storage.
+func (s *Service) UploadOrderInvoice(ctx context.Context, clientID, orderID int, fileName, contentType string, size int64, r io.Reader) (*domain.Order, error) {
+	if clientID <= 0 {
+		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.validateUpload(fileName, size); err != nil {
+		return nil, err
+	}
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+
+	if _, err := s.getClientOrder(ctx, clientID, orderID); err != nil {
+		return nil, err
+	}
+
+	key, err := storageKey("orders", orderID, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.Put(ctx, key, r, size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store invoice: %w", err)
+	}
+
+	previousKey, err := s.repo.SetOrderInvoice(ctx, orderID, &domain.OrderInvoice{
+		FileName:    fileName,
+		ContentType: contentType,
+		SizeBytes:   size,
+		StorageKey:  key,
+	})
+	if err != nil {
+		// Файл без ссылки из заказа недоступен через API, удаляем его
+		if delErr := s.storage.Delete(ctx, key); delErr != nil {
+			logrus.Errorf("failed to remove orphaned invoice %s: %s", key, delErr)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("order not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to attach invoice: %w", err)
+	}
+	if previousKey != "" {
+		if err := s.storage.Delete(ctx, previousKey); err != nil {
+			logrus.Errorf("failed to remove replaced invoice %s: %s", previousKey, err)
+		}
+	}
+
+	return s.GetOrder(ctx, clientID, orderID)
+}
+
+// DownloadOrderInvoice returns the invoice metadata and content of the client's order.
+// The caller must close the returned reader.
+func (s *Service) DownloadOrderInvoice(ctx context.Context, clientID, orderID int) (*domain.OrderInvoice, io.ReadCloser, error) {
+	if clientID <= 0 {
+		return nil, nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+	if err := checkClientAccess(ctx, clientID); err != nil {
+		return nil, nil, err
+	}
+
+	order, err := s.getClientOrder(ctx, clientID, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if order.Invoice == nil {
+		return nil, nil, fmt.Errorf("order %d has no invoice: %w", orderID, ErrNotFound)
+	}
+
+	content, err := s.storage.Get(ctx, order.Invoice.StorageKey)
+	if err != nil {
+		if errors.Is(err, s3.ErrObjectNotFound) {
+			return nil, nil, fmt.Errorf("invoice content not found: %w", ErrNotFound)
+		}
+		return nil, nil, fmt.Errorf("failed to read invoice: %w", err)
+	}
+
+	return order.Invoice, content, nil
+}
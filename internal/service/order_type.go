@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// orderTypeCacheTTL bounds how long an instance serves a catalog changed through another instance.
+const orderTypeCacheTTL = time.Minute
+
+// orderTypeCache keeps the order types catalog in memory: it is read on every order write and netting run.
+type orderTypeCache struct {
+	mu       sync.RWMutex
+	types    map[int]*domain.OrderType
+	loadedAt time.Time
+	// version is bumped on every invalidation so that a load started before it is not stored.
+	version int
+}
+
+// orderTypeCacheFor returns the catalog cache of the request tenant: each tenant has its own catalog.
+func (s *Service) orderTypeCacheFor(ctx context.Context) *orderTypeCache {
+	cache, _ := s.orderTypeCaches.LoadOrStore(tenantOf(ctx), &orderTypeCache{})
+	return cache.(*orderTypeCache)
+}
+
+// orderTypes returns the order types catalog indexed by order_type_id.
+func (s *Service) orderTypes(ctx context.Context) (map[int]*domain.OrderType, error) {
+	c := s.orderTypeCacheFor(ctx)
+	c.mu.RLock()
+	types, loadedAt, version := c.types, c.loadedAt, c.version
+	c.mu.RUnlock()
+	if types != nil && time.Since(loadedAt) < orderTypeCacheTTL {
+		return types, nil
+	}
+
+	list, err := s.repo.ListOrderTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order types: %w", err)
+	}
+	types = make(map[int]*domain.OrderType, len(list))
+	for _, orderType := range list {
+		types[orderType.OrderTypeID] = orderType
+	}
+
+	c.mu.Lock()
+	if c.version == version {
+		c.types, c.loadedAt = types, time.Now()
+	}
+	c.mu.Unlock()
+	return types, nil
+}
+
+// invalidateOrderTypes drops the cached catalog of the request tenant after a change.
+func (s *Service) invalidateOrderTypes(ctx context.Context) {
+	c := s.orderTypeCacheFor(ctx)
+	c.mu.Lock()
+	c.types = nil
+	c.version++
+	c.mu.Unlock()
+}
+
+// nettingRules returns the netting rules effective at the moment indexed by order_type_id:
+// the rules of the catalog with the configured netting rules valid on that date applied.
+func (s *Service) nettingRules(ctx context.Context, at time.Time) (map[int]domain.NettingRule, error) {
+	types, err := s.orderTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[int]domain.NettingRule, len(types))
+	for orderTypeID, orderType := range types {
+		rules[orderTypeID] = orderType.NettingRule()
+	}
+
+	configs, err := s.repo.ListEffectiveNettingRules(ctx, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list netting rules: %w", err)
+	}
+	for _, config := range configs {
+		if rule, ok := rules[config.OrderTypeID]; ok {
+			rules[config.OrderTypeID] = config.Apply(rule)
+		}
+	}
+	return rules, nil
+}
+
+// nettingTime returns the moment the netting rules are taken at: asOf if set, otherwise now.
+func nettingTime(asOf *time.Time) time.Time {
+	if asOf != nil {
+		return *asOf
+	}
+	return time.Now()
+}
+
+// validateOrderTypeID checks that an order references a type of the catalog.
+func (s *Service) validateOrderTypeID(ctx context.Context, orderTypeID int) error {
+	if orderTypeID <= 0 {
+		return fmt.Errorf("invalid order_type_id: %w", ErrInvalidInput)
+	}
+	types, err := s.orderTypes(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := types[orderTypeID]; !ok {
+		return fmt.Errorf("unknown order_type_id %d: %w", orderTypeID, ErrInvalidInput)
+	}
+	return nil
+}
+
+// validateOrderInsurer checks the insurer of an order against the netting rule of its type.
+// The order type must already be validated.
+func (s *Service) validateOrderInsurer(ctx context.Context, order *domain.Order) error {
+	types, err := s.orderTypes(ctx)
+	if err != nil {
+		return err
+	}
+	orderType, ok := types[order.OrderTypeID]
+	if !ok {
+		return fmt.Errorf("unknown order_type_id %d: %w", order.OrderTypeID, ErrInvalidInput)
+	}
+	return validateInsurer(order.InsurerID, orderType.NettingRule())
+}
+
+// ListOrderTypes returns the order types catalog ordered by order_type_id.
+func (s *Service) ListOrderTypes(ctx context.Context) ([]*domain.OrderType, error) {
+	orderTypes, err := s.repo.ListOrderTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list order types: %w", err)
+	}
+
+	return orderTypes, nil
+}
+
+// GetOrderType returns an order type of the catalog.
+func (s *Service) GetOrderType(ctx context.Context, orderTypeID int) (*domain.OrderType, error) {
+	if orderTypeID <= 0 {
+		return nil, fmt.Errorf("invalid order_type_id: %w", ErrInvalidInput)
+	}
+
+	types, err := s.orderTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orderType, ok := types[orderTypeID]
+	if !ok {
+		return nil, fmt.Errorf("order type not found: %w", ErrNotFound)
+	}
+
+	return orderType, nil
+}
+
+// CreateOrderType adds an order type with its netting rule to the catalog.
+func (s *Service) CreateOrderType(ctx context.Context, req domain.OrderType) (*domain.OrderType, error) {
+	if err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if req.Kind == "" {
+		req.Kind = domain.OrderKindOrder
+	}
+	if err := validateOrderType(req); err != nil {
+		return nil, err
+	}
+
+	orderType, err := s.repo.CreateOrderType(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("order type %d already exists: %w", req.OrderTypeID, ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to create order type: %w", err)
+	}
+	s.invalidateOrderTypes(ctx)
+
+	return orderType, nil
+}
+
+// UpdateOrderType renames an order type or changes its netting rule.
+// Settlements of existing orders follow the new rule on the next netting run.
+func (s *Service) UpdateOrderType(ctx context.Context, req domain.OrderType) (*domain.OrderType, error) {
+	if err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if req.Kind == "" {
+		req.Kind = domain.OrderKindOrder
+	}
+	if err := validateOrderType(req); err != nil {
+		return nil, err
+	}
+
+	orderType, err := s.repo.UpdateOrderType(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("order type not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to update order type: %w", err)
+	}
+	s.invalidateOrderTypes(ctx)
+
+	return orderType, nil
+}
+
+// DeleteOrderType removes an order type that no order references.
+func (s *Service) DeleteOrderType(ctx context.Context, orderTypeID int) error {
+	if err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return err
+	}
+	if orderTypeID <= 0 {
+		return fmt.Errorf("invalid order_type_id: %w", ErrInvalidInput)
+	}
+
+	if err := s.repo.DeleteOrderType(ctx, orderTypeID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return fmt.Errorf("order type not found: %w", ErrNotFound)
+		case errors.Is(err, repository.ErrConflict):
+			return fmt.Errorf("order type %d is used by orders: %w", orderTypeID, ErrConflict)
+		}
+		return fmt.Errorf("failed to delete order type: %w", err)
+	}
+	s.invalidateOrderTypes(ctx)
+
+	return nil
+}
+
+// validateOrderType checks a catalog entry: a name that fits the column, a known kind and a valid netting rule.
+func validateOrderType(orderType domain.OrderType) error {
+	name := strings.TrimSpace(orderType.Name)
+	if name == "" {
+		return fmt.Errorf("name is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(name) > 20 {
+		return fmt.Errorf("name must not exceed 20 characters: %w", ErrInvalidInput)
+	}
+	switch orderType.Kind {
+	case domain.OrderKindOrder, domain.OrderKindFee, domain.OrderKindPenalty:
+	default:
+		return fmt.Errorf("kind must be one of %s, %s, %s: %w",
+			domain.OrderKindOrder, domain.OrderKindFee, domain.OrderKindPenalty, ErrInvalidInput)
+	}
+	return validateNettingRule(orderType.NettingRule())
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cliring/internal/domain"
+)
+
+// ParticipantBalance returns the position of a participant across all its deals: what it still owes under
+// pending settlements and what it has settled, by currency. The participant is identified as in the ledger,
+// e.g. "client:42", "bank:1", "dealership:12" or "insurer:3".
+func (s *Service) ParticipantBalance(ctx context.Context, participantID string) (*domain.ParticipantBalance, error) {
+	participant, err := parseParticipantID(participantID)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := s.repo.ParticipantBalances(ctx, participant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get participant balance: %w", err)
+	}
+	if balances == nil {
+		balances = []*domain.ParticipantCurrencyBalance{}
+	}
+
+	return &domain.ParticipantBalance{Participant: participant, Balances: balances}, nil
+}
+
+// parseParticipantID parses a participant id of the form "role:external_id".
+func parseParticipantID(participantID string) (domain.Participant, error) {
+	role, idStr, ok := strings.Cut(participantID, ":")
+	if !ok {
+		return domain.Participant{}, fmt.Errorf("participant id %q must be role:id: %w", participantID, ErrInvalidInput)
+	}
+	switch role {
+	case domain.ParticipantClient, domain.ParticipantBank, domain.ParticipantDealership, domain.ParticipantInsurer:
+	default:
+		return domain.Participant{}, fmt.Errorf("unknown participant role %q: %w", role, ErrInvalidInput)
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return domain.Participant{}, fmt.Errorf("invalid participant id %q: %w", idStr, ErrInvalidInput)
+	}
+	return domain.Participant{Role: role, ExternalID: &id}, nil
+}
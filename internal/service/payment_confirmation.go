@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// maxExternalIDLength is the size of the external_id and reference_number columns of payment confirmations.
+const maxExternalIDLength = 100
+
+// ConfirmPayment stores a payment confirmation sent by a bank and applies it: an executed payment is recorded
+// against the pending settlement its reference number points to, which executes the settlement once it is
+// paid in full. A confirmation the source sent before is not processed again; the stored confirmation is
+// returned with created set to false.
+func (s *Service) ConfirmPayment(ctx context.Context, source string, req domain.PaymentConfirmationCreate) (
+	confirmation *domain.PaymentConfirmation, created bool, err error) {
+	if source == "" {
+		return nil, false, fmt.Errorf("payment confirmation source is required: %w", ErrUnauthorized)
+	}
+	if err := s.validatePaymentConfirmation(ctx, &req); err != nil {
+		return nil, false, err
+	}
+
+	confirmation, created, err = s.repo.CreatePaymentConfirmation(ctx, &domain.PaymentConfirmation{
+		Source:           source,
+		ExternalID:       req.ExternalID,
+		ReferenceNumber:  req.ReferenceNumber,
+		PaymentReference: req.PaymentReference,
+		Amount:           req.Amount,
+		CurrencyCode:     req.CurrencyCode,
+		Status:           req.Status,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to store payment confirmation: %w", err)
+	}
+	if !created {
+		// Повторная доставка: банк получает результат первой обработки
+		confirmation, err = s.repo.GetPaymentConfirmation(ctx, source, req.ExternalID)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get payment confirmation: %w", err)
+		}
+		return confirmation, false, nil
+	}
+
+	outcome, settlementID, reason, err := s.applyPaymentConfirmation(ctx, confirmation)
+	if err != nil {
+		return nil, false, err
+	}
+	confirmation, err = s.repo.CompletePaymentConfirmation(ctx, confirmation.ConfirmationID, outcome, settlementID, reason)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to complete payment confirmation: %w", err)
+	}
+	return confirmation, true, nil
+}
+
+// validatePaymentConfirmation normalizes and validates a payment confirmation callback.
+func (s *Service) validatePaymentConfirmation(ctx context.Context, req *domain.PaymentConfirmationCreate) error {
+	req.ExternalID = strings.TrimSpace(req.ExternalID)
+	if req.ExternalID == "" {
+		return fmt.Errorf("external_id is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(req.ExternalID) > maxExternalIDLength {
+		return fmt.Errorf("external_id must not exceed %d characters: %w", maxExternalIDLength, ErrInvalidInput)
+	}
+	req.ReferenceNumber = strings.TrimSpace(req.ReferenceNumber)
+	if req.ReferenceNumber == "" {
+		return fmt.Errorf("reference_number is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(req.ReferenceNumber) > maxExternalIDLength {
+		return fmt.Errorf("reference_number must not exceed %d characters: %w", maxExternalIDLength, ErrInvalidInput)
+	}
+	req.PaymentReference = strings.TrimSpace(req.PaymentReference)
+	if req.PaymentReference == "" {
+		return fmt.Errorf("payment_reference is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(req.PaymentReference) > maxPaymentReferenceLength {
+		return fmt.Errorf("payment_reference must not exceed %d characters: %w", maxPaymentReferenceLength, ErrInvalidInput)
+	}
+	if req.Amount <= 0 {
+		return fmt.Errorf("amount must be positive: %w", ErrInvalidInput)
+	}
+	if req.CurrencyCode == "" {
+		req.CurrencyCode = domain.DefaultCurrencyCode
+	}
+	currencies, err := s.currencies(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := currencies[req.CurrencyCode]; !ok {
+		return fmt.Errorf("unknown currency_code %s: %w", req.CurrencyCode, ErrInvalidInput)
+	}
+	if req.Status != domain.PaymentExecuted && req.Status != domain.PaymentRejected {
+		return fmt.Errorf("status must be %s or %s: %w", domain.PaymentExecuted, domain.PaymentRejected, ErrInvalidInput)
+	}
+	return nil
+}
+
+// applyPaymentConfirmation links a stored confirmation to the settlement of its reference number and records
+// an executed payment against it. A confirmation that cannot be applied is reported as unmatched with the reason,
+// so that the bank is not asked to retry a callback that will fail again.
+func (s *Service) applyPaymentConfirmation(ctx context.Context, confirmation *domain.PaymentConfirmation) (
+	outcome string, settlementID *int, reason string, err error) {
+	id, ok := settlementReferenceNumber(confirmation.ReferenceNumber)
+	if !ok {
+		return domain.ConfirmationUnmatched, nil, "reference_number does not contain a settlement number", nil
+	}
+	settlement, err := s.repo.GetMonetarySettlement(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ConfirmationUnmatched, nil, fmt.Sprintf("monetary settlement %d not found", id), nil
+		}
+		return "", nil, "", fmt.Errorf("failed to get monetary settlement: %w", err)
+	}
+	settlementID = &settlement.MonetarySettlementID
+
+	if confirmation.Status == domain.PaymentRejected {
+		return domain.ConfirmationIgnored, settlementID, "payment rejected by the bank", nil
+	}
+	currencyCode := settlement.CurrencyCode
+	if currencyCode == "" {
+		currencyCode = domain.DefaultCurrencyCode
+	}
+	if confirmation.CurrencyCode != currencyCode {
+		return domain.ConfirmationUnmatched, settlementID,
+			fmt.Sprintf("payment currency %s differs from settlement currency %s", confirmation.CurrencyCode, currencyCode), nil
+	}
+
+	_, err = s.recordSettlementPayment(ctx, settlement.MonetarySettlementID, domain.SettlementPaymentCreate{
+		Amount:           confirmation.Amount,
+		PaymentReference: confirmation.PaymentReference,
+	})
+	if err != nil {
+		// Расчет исполнен, отменен или оплачен параллельно: подтверждение остается несопоставленным
+		if errors.Is(err, ErrConflict) || errors.Is(err, ErrInvalidInput) || errors.Is(err, ErrNotFound) {
+			return domain.ConfirmationUnmatched, settlementID, err.Error(), nil
+		}
+		return "", nil, "", err
+	}
+	return domain.ConfirmationApplied, settlementID, "", nil
+}
+
+// settlementReferenceNumber extracts the settlement ID from a reference number: either the number itself
+// or a payment purpose such as "Взаиморасчеты по сделке №12345, расчет №7.".
+func settlementReferenceNumber(reference string) (int, bool) {
+	if match := settlementReferencePattern.FindStringSubmatch(reference); match != nil {
+		reference = match[1]
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(reference, "№"))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"cliring/internal/domain"
+	"cliring/internal/pain001"
+)
+
+// ExportPaymentFiles generates the pain.001.001.03 payment files of a settlement run of a deal, one file
+// per payer bank, and returns them as a ZIP archive. Run number 0 selects the latest run. The message ID
+// of a file is derived from the deal, run and bank, so a bank rejects a file that was already loaded.
+func (s *Service) ExportPaymentFiles(ctx context.Context, dealID, runNumber int) ([]byte, error) {
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if runNumber < 0 {
+		return nil, fmt.Errorf("invalid run_number: %w", ErrInvalidInput)
+	}
+
+	settlements, err := s.executedSettlements(ctx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	if runNumber == 0 {
+		for _, settlement := range settlements {
+			runNumber = max(runNumber, settlement.RunNumber)
+		}
+		if runNumber == 0 {
+			return nil, fmt.Errorf("deal has no executed settlement runs: %w", ErrConflict)
+		}
+	}
+
+	byBank := map[string][]*domain.PaymentInstruction{}
+	for _, settlement := range settlements {
+		if settlement.RunNumber != runNumber || settlement.Amount == 0 {
+			continue
+		}
+		instructions, err := s.PaymentInstructions(ctx, settlement.MonetarySettlementID)
+		if err != nil {
+			return nil, err
+		}
+		for _, instruction := range instructions {
+			if !instruction.Complete || instruction.Payer.BIC == "" || instruction.Payee.BIC == "" {
+				return nil, fmt.Errorf("bank details of settlement %d are incomplete: %w", settlement.MonetarySettlementID, ErrConflict)
+			}
+			byBank[instruction.Payer.BIC] = append(byBank[instruction.Payer.BIC], instruction)
+		}
+	}
+	if len(byBank) == 0 {
+		return nil, fmt.Errorf("run %d of the deal has no payments: %w", runNumber, ErrNotFound)
+	}
+
+	clearing, err := s.paymentDetails(ctx, domain.LedgerAccountClearing)
+	if err != nil {
+		return nil, err
+	}
+	banks := make([]string, 0, len(byBank))
+	for bic := range byBank {
+		banks = append(banks, bic)
+	}
+	sort.Strings(banks)
+
+	now := time.Now()
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for _, bic := range banks {
+		data, err := pain001.Generate(pain001.File{
+			MessageID:       fmt.Sprintf("CLR-%d-%d-%s", dealID, runNumber, bic),
+			CreatedAt:       now,
+			InitiatingParty: clearing.Name,
+			ExecutionDate:   now.Format(domain.DateLayout),
+			Instructions:    byBank[bic],
+		})
+		if err != nil {
+			if errors.Is(err, pain001.ErrIncomplete) {
+				return nil, fmt.Errorf("%v: %w", err, ErrConflict)
+			}
+			return nil, fmt.Errorf("failed to generate payment file for bank %s: %w", bic, err)
+		}
+		file, err := writer.Create(fmt.Sprintf("pain001_deal%d_run%d_%s.xml", dealID, runNumber, bic))
+		if err != nil {
+			return nil, fmt.Errorf("failed to add payment file to archive: %w", err)
+		}
+		if _, err := file.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to add payment file to archive: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write payment file archive: %w", err)
+	}
+	return archive.Bytes(), nil
+}
+
+// executedSettlements returns all executed settlements of a deal, page by page.
+func (s *Service) executedSettlements(ctx context.Context, dealID int) ([]*domain.MonetarySettlement, error) {
+	var settlements []*domain.MonetarySettlement
+	for page := 1; ; page++ {
+		batch, total, err := s.repo.ListStoredMonetarySettlements(ctx, dealID, domain.StatusExecuted, page, maxSettlementsLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list monetary settlements: %w", err)
+		}
+		settlements = append(settlements, batch...)
+		if page*maxSettlementsLimit >= total {
+			return settlements, nil
+		}
+	}
+}
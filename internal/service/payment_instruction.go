@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// PaymentInstructions generates the payment orders of an executed settlement for internet banking.
+// The participant pays a positive amount to the clearing account and receives a negative amount from it,
+// as in the ledger entries of the settlement. Instructions are built on request and are not stored.
+func (s *Service) PaymentInstructions(ctx context.Context, settlementID int) ([]*domain.PaymentInstruction, error) {
+	if settlementID <= 0 {
+		return nil, fmt.Errorf("invalid monetary_settlement_id: %w", ErrInvalidInput)
+	}
+
+	settlement, err := s.repo.GetMonetarySettlement(ctx, settlementID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("monetary settlement not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get monetary settlement: %w", err)
+	}
+	if err := s.checkSettlementAccess(ctx, settlement); err != nil {
+		return nil, err
+	}
+	if settlement.Status != domain.StatusExecuted {
+		return nil, fmt.Errorf("monetary settlement is %s, instructions are generated for executed settlements only: %w", settlement.Status, ErrConflict)
+	}
+	if settlement.Amount == 0 {
+		return []*domain.PaymentInstruction{}, nil
+	}
+
+	// Клиент сделки не хранится в расчете, его счет определяется по сделке
+	participant := settlement.NettingParticipant()
+	if participant.Role == domain.ParticipantClient && participant.ExternalID == nil {
+		deal, err := s.repo.GetDeal(ctx, *settlement.DealID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+			}
+			return nil, fmt.Errorf("failed to get deal: %w", err)
+		}
+		participant.ExternalID = &deal.ClientID
+	}
+
+	participantDetails, err := s.paymentDetails(ctx, participant.ID())
+	if err != nil {
+		return nil, err
+	}
+	clearingDetails, err := s.paymentDetails(ctx, domain.LedgerAccountClearing)
+	if err != nil {
+		return nil, err
+	}
+	vat, err := s.repo.AllocatedVAT(ctx, settlementID)
+	if err != nil {
+		return nil, err
+	}
+
+	instruction := &domain.PaymentInstruction{
+		MonetarySettlementID: settlement.MonetarySettlementID,
+		DealID:               *settlement.DealID,
+		Payer:                participantDetails,
+		Payee:                clearingDetails,
+		Amount:               settlement.Amount,
+		CurrencyCode:         settlement.CurrencyCode,
+		VATAmount:            vat.Abs(),
+	}
+	if settlement.Amount < 0 {
+		instruction.Payer, instruction.Payee = clearingDetails, participantDetails
+		instruction.Amount = settlement.Amount.Abs()
+	}
+	instruction.Complete = instruction.Payer.Name != "" && instruction.Payer.BankAccount != "" &&
+		instruction.Payee.Name != "" && instruction.Payee.BankAccount != ""
+	instruction.Purpose = paymentPurpose(instruction)
+
+	return []*domain.PaymentInstruction{instruction}, nil
+}
+
+// paymentDetails returns the bank details of the account; unknown accounts get details without requisites.
+func (s *Service) paymentDetails(ctx context.Context, account string) (*domain.PaymentDetails, error) {
+	details, err := s.repo.GetPaymentDetails(ctx, account)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return &domain.PaymentDetails{Account: account}, nil
+		}
+		return nil, err
+	}
+	return details, nil
+}
+
+// paymentPurpose returns the purpose text of a payment order, with the VAT clause required in Russian payment orders.
+func paymentPurpose(instruction *domain.PaymentInstruction) string {
+	purpose := fmt.Sprintf("Взаиморасчеты по сделке №%d, расчет №%d.", instruction.DealID, instruction.MonetarySettlementID)
+	if instruction.VATAmount == 0 {
+		return purpose + " НДС не облагается"
+	}
+	return fmt.Sprintf("%s В т.ч. НДС %s %s", purpose, instruction.VATAmount, instruction.CurrencyCode)
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"cliring/internal/domain"
+)
+
+// Repository is the storage the service runs on. It is implemented by repository.Repository on Postgres
+// and by memory.Repository for tests and the demo mode; both report failures with the repository errors.
+type Repository interface {
+	DealRepository
+	OrderRepository
+	SettlementRepository
+	ClearingRepository
+	ParticipantRepository
+	CatalogRepository
+	NettingRepository
+	ContractRepository
+	ReconciliationRepository
+	WebhookRepository
+	SecurityRepository
+	MaintenanceRepository
+	PriceCatalog
+	OrderActivity
+}
+
+// DealRepository stores deals with their history, documents and deletion schedule.
+type DealRepository interface {
+	CreateDeal(ctx context.Context, req domain.Deal) (*domain.Deal, error)
+	GetDeal(ctx context.Context, dealID int) (*domain.Deal, error)
+	UpdateDeal(ctx context.Context, dealID int, req domain.DealUpdate) (*domain.Deal, error)
+	UpdateDealStatus(ctx context.Context, dealID int, from, to string) (*domain.Deal, error)
+	DeleteDeal(ctx context.Context, dealID int) error
+	GetDealWithOrders(ctx context.Context, dealID int, sort domain.OrderSort) (*domain.Deal, []*domain.Order, error)
+	GetDealWithOrdersAsOf(ctx context.Context, dealID int, asOf time.Time, sort domain.OrderSort) (*domain.Deal, []*domain.Order, error)
+	ListDealHistory(ctx context.Context, dealID int) ([]*domain.DealHistoryEntry, error)
+	LockDeal(ctx context.Context, dealID int) (context.Context, func(), error)
+	ListOpenDeals(ctx context.Context, clientID, dealershipID *int) ([]*domain.Deal, error)
+	ListMultiBranchDealIDs(ctx context.Context, from, to time.Time) ([]int, error)
+	ScheduleDealDeletion(ctx context.Context, dealID int, at time.Time) (*domain.Deal, error)
+	RestoreDeal(ctx context.Context, dealID int) (*domain.Deal, error)
+	ListDealsDueForDeletion(ctx context.Context, now time.Time) ([]int, error)
+	CreateDealDocument(ctx context.Context, document *domain.DealDocument) (*domain.DealDocument, error)
+	ListDealDocuments(ctx context.Context, dealID int) ([]*domain.DealDocument, error)
+	GetDealDocument(ctx context.Context, dealID, documentID int) (*domain.DealDocument, error)
+	DeleteDealDocument(ctx context.Context, dealID, documentID int) error
+	ListChanges(ctx context.Context, entity string, since int64, limit int) ([]*domain.ChangeRecord, error)
+}
+
+// OrderRepository stores the orders of deals with their revisions, invoices and fraud reviews.
+type OrderRepository interface {
+	ListOrders(ctx context.Context, clientID int, filter domain.OrderFilter, sort domain.OrderSort, page, limit int) ([]*domain.Order, int, error)
+	ListOrdersByDeals(ctx context.Context, dealID int) ([]*domain.Order, error)
+	ListOrdersOfDeals(ctx context.Context, dealIDs []int) ([]*domain.Order, error)
+	CreateOrders(ctx context.Context, orders []*domain.Order) ([]*domain.Order, error)
+	GetClientOrder(ctx context.Context, clientID, orderID int) (*domain.Order, error)
+	UpdateOrder(ctx context.Context, order *domain.Order) (*domain.Order, error)
+	UpdateOrderStatus(ctx context.Context, orderID int, from, to string) (*domain.Order, error)
+	DeleteOrder(ctx context.Context, orderID int) error
+	ListClientOrderRevisions(ctx context.Context, clientID, orderID int) ([]*domain.OrderRevision, error)
+	SetOrderInvoice(ctx context.Context, orderID int, invoice *domain.OrderInvoice) (string, error)
+	FlagOrderForReview(ctx context.Context, orderID, clientID int, reasons []string) (*domain.OrderReview, error)
+	ListOrderReviews(ctx context.Context, status string) ([]*domain.OrderReview, error)
+	DecideOrderReview(ctx context.Context, reviewID int, status string) (*domain.OrderReview, error)
+}
+
+// OrderActivity counts the recent orders of a client for the anti-fraud rules.
+type OrderActivity interface {
+	CountClientOrdersSince(ctx context.Context, clientID int, minAmount domain.Money, since time.Time) (int, error)
+	CountClientCancellationsSince(ctx context.Context, clientID int, since time.Time) (int, error)
+}
+
+// SettlementRepository stores monetary settlements with their allocations, payments and penalties.
+type SettlementRepository interface {
+	NextSettlementRun(ctx context.Context, dealID int) (int, error)
+	CreateMonetarySettlement(ctx context.Context, settlement *domain.MonetarySettlement) (*domain.MonetarySettlement, error)
+	ExecuteMonetarySettlement(ctx context.Context, settlement *domain.MonetarySettlement,
+		allocations []*domain.OrderAllocation, entries []*domain.LedgerEntry) (*domain.MonetarySettlement, error)
+	ClearSettlementsRecompute(ctx context.Context, dealID int) error
+	FixPendingSettlements(ctx context.Context, dealID int, settlements []*domain.MonetarySettlement) ([]*domain.MonetarySettlement, error)
+	CancelPendingSettlements(ctx context.Context, dealID int) ([]*domain.MonetarySettlement, error)
+	ListStoredMonetarySettlements(ctx context.Context, dealID int, status string, page, limit int) ([]*domain.MonetarySettlement, int, error)
+	CountStoredMonetarySettlements(ctx context.Context, dealID int, status string) (int, error)
+	GetMonetarySettlement(ctx context.Context, settlementID int) (*domain.MonetarySettlement, error)
+	ExecutePendingSettlement(ctx context.Context, settlementID int, paymentReference string,
+		allocations []*domain.OrderAllocation, entries []*domain.LedgerEntry) (*domain.MonetarySettlement, error)
+	CancelMonetarySettlement(ctx context.Context, settlementID int, reason string) (*domain.MonetarySettlement, error)
+	ReviewMonetarySettlement(ctx context.Context, settlementID int, approvalStatus, reviewer, comment string) (*domain.MonetarySettlement, error)
+	CreateSettlementPayment(ctx context.Context, payment *domain.SettlementPayment) (*domain.SettlementPayment, error)
+	ListSettlementPayments(ctx context.Context, settlementID int) ([]*domain.SettlementPayment, error)
+	ListPayablePendingSettlements(ctx context.Context) ([]*domain.MonetarySettlement, error)
+	LastPenaltyDates(ctx context.Context, settlementIDs []int) (map[int]string, error)
+	CreateSettlementPenalties(ctx context.Context, penalties []*domain.SettlementPenalty) (int, error)
+	ListSettlementPenalties(ctx context.Context, dealIDs []int, upTo string) ([]*domain.SettlementPenalty, error)
+	GetPaymentDetails(ctx context.Context, account string) (*domain.PaymentDetails, error)
+	AllocatedVAT(ctx context.Context, settlementID int) (domain.Money, error)
+	SettlementTotalsByStatus(ctx context.Context, dealershipID int, from, to time.Time) ([]*domain.SettlementTotal, error)
+	SettlementTotalsByBank(ctx context.Context, dealershipID int, from, to time.Time) ([]*domain.SettlementTotal, error)
+	SettlementTotalsByOrderType(ctx context.Context, dealershipID int, from, to time.Time) ([]*domain.SettlementTotal, error)
+	ParticipantBalances(ctx context.Context, participant domain.Participant) ([]*domain.ParticipantCurrencyBalance, error)
+}
+
+// ClearingRepository stores the clearing sessions orders are collected into.
+type ClearingRepository interface {
+	GetClearingSession(ctx context.Context, sessionID int) (*domain.ClearingSession, error)
+	ListClearingSessions(ctx context.Context, status string, page, limit int) ([]*domain.ClearingSession, int, error)
+	StartComputingClearingSession(ctx context.Context, sessionID int) (*domain.ClearingSession, error)
+	TransitionClearingSession(ctx context.Context, sessionID int, from, to string) (*domain.ClearingSession, error)
+	ClearingSessionDeals(ctx context.Context, sessionID int) ([]int, error)
+	ListClearingSessionSettlements(ctx context.Context, sessionID int) ([]*domain.MonetarySettlement, error)
+}
+
+// ParticipantRepository stores the banks, clients, dealerships and managers taking part in deals.
+type ParticipantRepository interface {
+	ListBanks(ctx context.Context, activeOnly bool) ([]*domain.Bank, error)
+	GetBank(ctx context.Context, bankID int) (*domain.Bank, error)
+	CreateBank(ctx context.Context, bank domain.Bank) (*domain.Bank, error)
+	UpdateBank(ctx context.Context, bank domain.Bank) (*domain.Bank, error)
+	DeleteBank(ctx context.Context, bankID int) error
+	ListClients(ctx context.Context, page, limit int) ([]*domain.Client, int, error)
+	GetClient(ctx context.Context, clientID int) (*domain.Client, error)
+	CreateClient(ctx context.Context, client domain.Client) (*domain.Client, error)
+	UpdateClient(ctx context.Context, client domain.Client) (*domain.Client, error)
+	DeleteClient(ctx context.Context, clientID int) error
+	GetClientAnonymizedAt(ctx context.Context, clientID int) (*time.Time, error)
+	CreateAnonymizationToken(ctx context.Context, clientID int, tokenHash string, expiresAt time.Time) error
+	AnonymizeClient(ctx context.Context, clientID int, tokenHash string) (*domain.AnonymizationResult, error)
+	ListDealerships(ctx context.Context, activeOnly bool) ([]*domain.Dealership, error)
+	GetDealership(ctx context.Context, dealershipID int) (*domain.Dealership, error)
+	CreateDealership(ctx context.Context, dealership domain.Dealership) (*domain.Dealership, error)
+	UpdateDealership(ctx context.Context, dealership domain.Dealership) (*domain.Dealership, error)
+	DeleteDealership(ctx context.Context, dealershipID int) error
+	ListManagers(ctx context.Context, filter domain.ManagerFilter) ([]*domain.Manager, error)
+	GetManager(ctx context.Context, managerID int) (*domain.Manager, error)
+	CreateManager(ctx context.Context, manager domain.Manager) (*domain.Manager, error)
+	UpdateManager(ctx context.Context, manager domain.Manager) (*domain.Manager, error)
+	DeleteManager(ctx context.Context, managerID int) error
+}
+
+// CatalogRepository stores the reference data: vehicles, order types, currencies, exchange rates
+// and the business calendar.
+type CatalogRepository interface {
+	ListVehicles(ctx context.Context, condition string) ([]*domain.Vehicle, error)
+	ListVehiclesByIDs(ctx context.Context, vehicleIDs []int) ([]*domain.Vehicle, error)
+	GetVehicle(ctx context.Context, vehicleID int) (*domain.Vehicle, error)
+	CreateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error)
+	UpdateVehicle(ctx context.Context, vehicle domain.Vehicle) (*domain.Vehicle, error)
+	DeleteVehicle(ctx context.Context, vehicleID int) error
+	ListOrderTypes(ctx context.Context) ([]*domain.OrderType, error)
+	CreateOrderType(ctx context.Context, orderType domain.OrderType) (*domain.OrderType, error)
+	UpdateOrderType(ctx context.Context, orderType domain.OrderType) (*domain.OrderType, error)
+	DeleteOrderType(ctx context.Context, orderTypeID int) error
+	ListCurrencies(ctx context.Context) ([]*domain.Currency, error)
+	CreateFXRate(ctx context.Context, rate domain.FXRate) (*domain.FXRate, error)
+	SaveCBRRates(ctx context.Context, rates []domain.FXRate) (int, error)
+	ListFXRates(ctx context.Context, pivotCurrencyCode, currencyCode string) ([]*domain.FXRate, error)
+	ListEffectiveFXRates(ctx context.Context, pivotCurrencyCode string, date time.Time) ([]*domain.FXRate, error)
+	ListCalendarDays(ctx context.Context, from, to time.Time) ([]*domain.CalendarDay, error)
+	CreateCalendarDay(ctx context.Context, day domain.CalendarDay) (*domain.CalendarDay, error)
+	UpdateCalendarDay(ctx context.Context, day domain.CalendarDay) (*domain.CalendarDay, error)
+	DeleteCalendarDay(ctx context.Context, date string) error
+}
+
+// NettingRepository stores the netting rules, the obligation triggers and the netting snapshots of deals.
+type NettingRepository interface {
+	ListNettingRules(ctx context.Context, orderTypeID int) ([]*domain.NettingRuleConfig, error)
+	ListEffectiveNettingRules(ctx context.Context, date time.Time) ([]*domain.NettingRuleConfig, error)
+	CreateNettingRule(ctx context.Context, rule domain.NettingRuleConfig) (*domain.NettingRuleConfig, error)
+	UpdateNettingRule(ctx context.Context, rule domain.NettingRuleConfig) (*domain.NettingRuleConfig, error)
+	GetNettingRule(ctx context.Context, ruleID int) (*domain.NettingRuleConfig, error)
+	DeleteNettingRule(ctx context.Context, ruleID int) error
+	ListObligationTriggers(ctx context.Context, activeOnly bool) ([]*domain.ObligationTrigger, error)
+	CreateObligationTrigger(ctx context.Context, trigger domain.ObligationTrigger) (*domain.ObligationTrigger, error)
+	UpdateObligationTrigger(ctx context.Context, trigger domain.ObligationTrigger) (*domain.ObligationTrigger, error)
+	DeleteObligationTrigger(ctx context.Context, triggerID int) error
+	ListTriggerDeals(ctx context.Context, trigger *domain.ObligationTrigger, at time.Time) ([]*domain.Deal, error)
+	SaveNettingSnapshot(ctx context.Context, snapshot *domain.NettingSnapshot) (bool, error)
+	ListNettingSnapshots(ctx context.Context, dealID, page, limit int) ([]*domain.NettingSnapshot, int, error)
+}
+
+// ContractRepository stores the credit contracts and trade-in appraisals attached to deals.
+type ContractRepository interface {
+	ListCreditContracts(ctx context.Context, dealID int) ([]*domain.CreditContract, error)
+	GetCreditContract(ctx context.Context, contractID int) (*domain.CreditContract, error)
+	CreateCreditContract(ctx context.Context, contract domain.CreditContract) (*domain.CreditContract, error)
+	UpdateCreditContract(ctx context.Context, contract domain.CreditContract) (*domain.CreditContract, error)
+	DeleteCreditContract(ctx context.Context, contractID int) error
+	CountContractOrders(ctx context.Context, contractID int) (int, error)
+	ListAppraisals(ctx context.Context, dealID int) ([]*domain.TradeInAppraisal, error)
+	GetAppraisal(ctx context.Context, appraisalID int) (*domain.TradeInAppraisal, error)
+	CreateAppraisal(ctx context.Context, appraisal domain.TradeInAppraisal) (*domain.TradeInAppraisal, error)
+	UpdateAppraisal(ctx context.Context, appraisal domain.TradeInAppraisal) (*domain.TradeInAppraisal, error)
+	ReviewAppraisal(ctx context.Context, appraisalID int, status, reviewer, comment string) (*domain.TradeInAppraisal, error)
+	DeleteAppraisal(ctx context.Context, appraisalID int) error
+}
+
+// ReconciliationRepository stores the bank statement lines and payment confirmations matched to settlements.
+type ReconciliationRepository interface {
+	CreateBankStatementLine(ctx context.Context, line *domain.BankStatementLine) (*domain.BankStatementLine, bool, error)
+	MatchBankStatementLine(ctx context.Context, lineID, settlementID int) (*domain.BankStatementLine, error)
+	ListPendingSettlementsByAmount(ctx context.Context, amount domain.Money, currencyCode string) ([]*domain.MonetarySettlement, error)
+	CreatePaymentConfirmation(ctx context.Context, confirmation *domain.PaymentConfirmation) (*domain.PaymentConfirmation, bool, error)
+	GetPaymentConfirmation(ctx context.Context, source, externalID string) (*domain.PaymentConfirmation, error)
+	CompletePaymentConfirmation(ctx context.Context, confirmationID int, outcome string, settlementID *int,
+		reason string) (*domain.PaymentConfirmation, error)
+}
+
+// WebhookRepository stores the webhook subscriptions and the queue of their deliveries.
+type WebhookRepository interface {
+	CreateWebhookSubscription(ctx context.Context, subscription *domain.WebhookSubscription) (*domain.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, subscriptionID int) error
+	EnqueueWebhookEvent(ctx context.Context, event string, payload []byte) error
+	ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error)
+	MarkWebhookDelivered(ctx context.Context, deliveryID int64) error
+	MarkWebhookAttemptFailed(ctx context.Context, deliveryID int64, lastError string, nextAttemptAt *time.Time) error
+}
+
+// SecurityRepository stores the audit log, the revoked tokens and the idempotency keys of requests.
+type SecurityRepository interface {
+	InsertAuditEntry(ctx context.Context, entry *domain.AuditEntry) error
+	ListAuditEntries(ctx context.Context, filter domain.AuditFilter, page, limit int) ([]*domain.AuditEntry, int, error)
+	RevokeToken(ctx context.Context, revocation domain.TokenRevocation) (*domain.TokenRevocation, error)
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	PurgeRevokedTokens(ctx context.Context, before time.Time) (int64, error)
+	ReserveIdempotencyKey(ctx context.Context, scope, key, owner, requestHash string, expiredBefore, abandonedBefore time.Time) (*domain.IdempotencyRecord, bool, error)
+	TouchIdempotencyKey(ctx context.Context, scope, key, owner string) error
+	CompleteIdempotencyKey(ctx context.Context, scope, key, owner string, statusCode int, body []byte) error
+	ReleaseIdempotencyKey(ctx context.Context, scope, key, owner string) error
+	PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+}
+
+// MaintenanceRepository covers the health of the storage, its tenants and the purge of expired data.
+type MaintenanceRepository interface {
+	Ping(ctx context.Context) error
+	Available() bool
+	SchemaVersion() int32
+	ProvisionTenant(ctx context.Context, tenantID, name string) (*domain.Tenant, error)
+	ListTenants(ctx context.Context) ([]*domain.Tenant, error)
+	HasTenant(ctx context.Context, tenantID string) (bool, error)
+	PurgeDeals(ctx context.Context, cutoff time.Time, anonymize bool) (int64, error)
+	PurgeOrders(ctx context.Context, cutoff time.Time, anonymize bool) (int64, error)
+	PurgeMonetarySettlements(ctx context.Context, cutoff time.Time) (int64, error)
+	ListSoftDeleted(ctx context.Context, entity string, deletedBefore time.Time) ([]*domain.UpcomingPurge, error)
+}
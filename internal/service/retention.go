@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"cliring/internal/domain"
+)
+
+// retentionPeriods returns the configured retention period of every entity.
+func (s *Service) retentionPeriods() map[string]time.Duration {
+	return map[string]time.Duration{
+		domain.EntityDeals:               s.cfg.Retention.Deals,
+		domain.EntityOrders:              s.cfg.Retention.Orders,
+		domain.EntityMonetarySettlements: s.cfg.Retention.MonetarySettlements,
+	}
+}
+
+// retentionAction returns what happens to an expired row of the entity in the configured mode.
+// Monetary settlements hold no personal data, so in anonymize mode they are kept as is.
+func (s *Service) retentionAction(entity string) string {
+	if s.cfg.Retention.Mode == domain.RetentionModeAnonymize {
+		if entity == domain.EntityMonetarySettlements {
+			return ""
+		}
+		return domain.RetentionModeAnonymize
+	}
+	return domain.RetentionModeDelete
+}
+
+// PurgeExpired hard-deletes (or anonymizes) soft-deleted rows whose retention period has expired.
+func (s *Service) PurgeExpired(ctx context.Context) (*domain.PurgeResult, error) {
+	mode := s.cfg.Retention.Mode
+	if mode != domain.RetentionModeDelete && mode != domain.RetentionModeAnonymize {
+		return nil, fmt.Errorf("unknown retention mode %q: %w", mode, ErrInvalidInput)
+	}
+	anonymize := mode == domain.RetentionModeAnonymize
+
+	now := time.Now()
+	periods := s.retentionPeriods()
+	result := &domain.PurgeResult{Mode: mode, Purged: map[string]int64{}}
+
+	// Порядок важен: сначала зависимые записи, затем сделки
+	if !anonymize {
+		purged, err := s.repo.PurgeMonetarySettlements(ctx, now.Add(-periods[domain.EntityMonetarySettlements]))
+		if err != nil {
+			return nil, err
+		}
+		result.Purged[domain.EntityMonetarySettlements] = purged
+	}
+
+	purged, err := s.repo.PurgeOrders(ctx, now.Add(-periods[domain.EntityOrders]), anonymize)
+	if err != nil {
+		return nil, err
+	}
+	result.Purged[domain.EntityOrders] = purged
+
+	purged, err = s.repo.PurgeDeals(ctx, now.Add(-periods[domain.EntityDeals]), anonymize)
+	if err != nil {
+		return nil, err
+	}
+	result.Purged[domain.EntityDeals] = purged
+
+	return result, nil
+}
+
+// UpcomingPurges lists soft-deleted rows that will be purged within the given horizon.
+func (s *Service) UpcomingPurges(ctx context.Context, horizon time.Duration) ([]*domain.UpcomingPurge, error) {
+	if horizon < 0 {
+		return nil, fmt.Errorf("horizon must not be negative: %w", ErrInvalidInput)
+	}
+
+	until := time.Now().Add(horizon)
+	var purges []*domain.UpcomingPurge
+	for entity, period := range s.retentionPeriods() {
+		action := s.retentionAction(entity)
+		if action == "" {
+			continue
+		}
+
+		rows, err := s.repo.ListSoftDeleted(ctx, entity, until.Add(-period))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list soft-deleted %s: %w", entity, err)
+		}
+		for _, row := range rows {
+			row.PurgeAt = row.DeletedAt.Add(period)
+			row.Action = action
+		}
+		purges = append(purges, rows...)
+	}
+
+	sort.Slice(purges, func(i, j int) bool {
+		return purges[i].PurgeAt.Before(purges[j].PurgeAt)
+	})
+
+	return purges, nil
+}
+
+// RunRetentionPurger periodically purges expired soft-deleted rows until ctx is cancelled.
+func (s *Service) RunRetentionPurger(ctx context.Context) {
+	interval := s.cfg.Retention.PurgeInterval
+	if interval <= 0 {
+		logrus.Info("Retention purger disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Ключи идемпотентности после истечения срока действия не нужны
+			if purged, err := s.PurgeIdempotencyKeys(ctx); err != nil {
+				logrus.Error("Idempotency keys purge failed: ", err)
+			} else {
+				logrus.WithField("purged", purged).Info("Idempotency keys purge completed")
+			}
+			// Отзыв истекшего токена не нужен: токен отклоняется по сроку действия
+			if purged, err := s.PurgeRevokedTokens(ctx); err != nil {
+				logrus.Error("Revoked tokens purge failed: ", err)
+			} else {
+				logrus.WithField("purged", purged).Info("Revoked tokens purge completed")
+			}
+
+			result, err := s.PurgeExpired(ctx)
+			if err != nil {
+				logrus.Error("Retention purge failed: ", err)
+				continue
+			}
+			logrus.WithField("purged", result.Purged).Info("Retention purge completed")
+		}
+	}
+}
@@ -1,14 +1,17 @@
 package service
 
 import (
+	"cliring/config"
 	"cliring/internal/repository"
 	"context"
 	"errors"
 	"fmt"
 	"github.com/sirupsen/logrus"
+	"sync"
 	"time"
 
 	"cliring/internal/domain"
+	"cliring/pkg/integration"
 )
 
 // Errors returned by the service layer.
@@ -16,20 +19,49 @@ var (
 	ErrInvalidInput = errors.New("invalid input")
 	ErrNotFound     = errors.New("resource not found")
 	ErrUnauthorized = errors.New("unauthorized access")
+	ErrConflict     = errors.New("conflict")
+	ErrForbidden    = errors.New("forbidden")
 )
 
 // Service contains business logic for the Cliring API.
 type Service struct {
-	repo *repository.Repository
+	repo    Repository
+	cfg     *config.Config
+	catalog PriceCatalog
+	storage DocumentStorage
+	rules   []OrderRule
+	// integrations collects call statistics of external systems, probes check their reachability.
+	integrations *integration.Monitor
+	probes       map[string]Prober
+	// orderTypeCaches holds the order types catalog with the netting rules per tenant.
+	orderTypeCaches *sync.Map
+	// webhooks delivers settlement events to the subscribers.
+	webhooks WebhookSender
+	// renderer renders netting acts to PDF.
+	renderer DocumentRenderer
+	// rateSource fetches the official exchange rates of the Central Bank.
+	rateSource RateSource
 }
 
 // NewService creates a new Service instance.
-func NewService(repo *repository.Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo Repository, cfg *config.Config) *Service {
+	return &Service{
+		repo:            repo,
+		cfg:             cfg,
+		catalog:         repo,
+		rules:           defaultOrderRules(repo, cfg),
+		integrations:    integration.NewMonitor(),
+		probes:          map[string]Prober{},
+		orderTypeCaches: &sync.Map{},
+	}
 }
 
 // CreateDeal creates a new deal.
 func (s *Service) CreateDeal(ctx context.Context, req domain.Deal) (*domain.Deal, error) {
+	if err := requireRole(ctx, domain.RoleManager); err != nil {
+		return nil, err
+	}
+
 	// Validate input
 	if req.DealershipID <= 0 {
 		return nil, fmt.Errorf("invalid dealership_id: %w", ErrInvalidInput)
@@ -40,112 +72,508 @@ func (s *Service) CreateDeal(ctx context.Context, req domain.Deal) (*domain.Deal
 	if req.ClientID <= 0 {
 		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
 	}
+	if err := s.validateDealDealership(ctx, req.DealershipID); err != nil {
+		return nil, err
+	}
+	if err := s.validateDealManager(ctx, req.ManagerID, req.DealershipID); err != nil {
+		return nil, err
+	}
+
+	// Новая сделка всегда начинается с черновика
+	req.Status = domain.DealStatusDraft
 
 	createdDeal, err := s.repo.CreateDeal(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create deal: %w", err)
 	}
+	auditChange(ctx, "deals", createdDeal.DealID, nil, createdDeal)
 
 	return createdDeal, nil
 }
 
-// DeleteDeal deletes a deal.
-func (s *Service) DeleteDeal(ctx context.Context, dealID int) error {
-	// Verify deal exists
-	_, err := s.repo.GetDeal(ctx, dealID)
+// UpdateDeal partially updates a deal. Only the provided fields are changed.
+func (s *Service) UpdateDeal(ctx context.Context, dealID int, req domain.DealUpdate) (*domain.Deal, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	// Validate input
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if err := s.CheckDealAccess(ctx, dealID); err != nil {
+		return nil, err
+	}
+	if req.DealershipID == nil && req.ManagerID == nil {
+		return nil, fmt.Errorf("no fields to update: %w", ErrInvalidInput)
+	}
+	if req.DealershipID != nil && *req.DealershipID <= 0 {
+		return nil, fmt.Errorf("invalid dealership_id: %w", ErrInvalidInput)
+	}
+	if req.ManagerID != nil && *req.ManagerID <= 0 {
+		return nil, fmt.Errorf("invalid manager_id: %w", ErrInvalidInput)
+	}
+
+	// Менеджер сделки должен работать в ее дилерском центре и после изменения
+	deal, err := s.repo.GetDeal(ctx, dealID)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			return fmt.Errorf("deal not found: %w", ErrNotFound)
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+	managerID, dealershipID := deal.ManagerID, deal.DealershipID
+	if req.ManagerID != nil {
+		managerID = *req.ManagerID
+	}
+	if req.DealershipID != nil && *req.DealershipID != deal.DealershipID {
+		dealershipID = *req.DealershipID
+		if err := s.validateDealDealership(ctx, dealershipID); err != nil {
+			return nil, err
+		}
+	}
+	// Обезличенная сделка менеджера не хранит
+	if managerID > 0 {
+		if err := s.validateDealManager(ctx, managerID, dealershipID); err != nil {
+			return nil, err
 		}
-		return fmt.Errorf("failed to get deal: %w", err)
 	}
 
-	if err := s.repo.DeleteDeal(ctx, dealID); err != nil {
-		return fmt.Errorf("failed to delete deal: %w", err)
+	updatedDeal, err := s.repo.UpdateDeal(ctx, dealID, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to update deal: %w", err)
 	}
+	auditChange(ctx, "deals", dealID, deal, updatedDeal)
 
-	return nil
+	return updatedDeal, nil
 }
 
-// ListOrders retrieves a paginated list of orders for the client.
-func (s *Service) ListOrders(ctx context.Context, clientID int) ([]*domain.Order, int, error) {
-	if clientID <= 0 {
-		return nil, 0, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+// TransitionDeal moves a deal to another lifecycle status if the transition is allowed.
+// Entering clearing fixes the netting result as pending settlements; returning to active cancels them.
+// Cancelling a deal fires the cancellation triggers.
+func (s *Service) TransitionDeal(ctx context.Context, dealID int, req domain.DealTransition) (*domain.Deal, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if err := s.CheckDealAccess(ctx, dealID); err != nil {
+		return nil, err
+	}
+	if _, ok := domain.DealTransitions[req.Status]; !ok {
+		return nil, fmt.Errorf("unknown deal status %q: %w", req.Status, ErrInvalidInput)
 	}
 
-	logrus.Info("List Orders Service")
-	orders, total, err := s.repo.ListOrders(ctx, clientID)
+	deal, err := s.repo.GetDeal(ctx, dealID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list orders: %w", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
 	}
 
-	return orders, total, nil
-}
+	if !canTransitionDeal(deal.Status, req.Status) {
+		return nil, fmt.Errorf("transition from %s to %s is not allowed: %w", deal.Status, req.Status, ErrConflict)
+	}
 
-// CreateOrders creates new orders for the specified client.
-func (s *Service) CreateOrders(ctx context.Context, clientID int, req []domain.OrderCreate) ([]*domain.Order, error) {
-	if clientID <= 0 {
-		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	updatedDeal, err := s.repo.UpdateDealStatus(ctx, dealID, deal.Status, req.Status)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("deal status changed concurrently: %w", ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to update deal status: %w", err)
 	}
+	auditChange(ctx, "deals", dealID, deal, updatedDeal)
 
-	var createdOrders []*domain.Order
-	for _, orderReq := range req {
-		// Validate input
-		if orderReq.Amount <= 0 {
-			return nil, fmt.Errorf("amount must be positive: %w", ErrInvalidInput)
+	// Результат клиринга фиксируется при входе в клиринг и отменяется при возврате сделки в работу
+	switch {
+	case req.Status == domain.DealStatusClearing:
+		if _, err := s.fixPendingSettlements(ctx, dealID, nil); err != nil {
+			return nil, err
 		}
-		if orderReq.DealID <= 0 {
-			return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	case deal.Status == domain.DealStatusClearing && req.Status == domain.DealStatusActive:
+		cancelled, err := s.repo.CancelPendingSettlements(ctx, dealID)
+		if err != nil {
+			return nil, err
 		}
-		if orderReq.OrderTypeID <= 0 {
-			return nil, fmt.Errorf("invalid order_type_id: %w", ErrInvalidInput)
+		s.publishSettlementEvent(ctx, domain.EventSettlementCancelled, cancelled...)
+	case req.Status == domain.DealStatusCancelled:
+		// Неустойка за отмену начисляется сразу, фоновая задача лишь подхватывает пропущенные сделки
+		if err := s.fireDealTriggers(ctx, updatedDeal, domain.TriggerDealCancelled); err != nil {
+			return nil, err
 		}
-		if orderReq.BankID != nil && *orderReq.BankID <= 0 {
-			return nil, fmt.Errorf("invalid bank_id: %w", ErrInvalidInput)
+	}
+
+	return updatedDeal, nil
+}
+
+// canTransitionDeal reports whether a deal may move from one status to another.
+func canTransitionDeal(from, to string) bool {
+	for _, allowed := range domain.DealTransitions[from] {
+		if allowed == to {
+			return true
 		}
+	}
+	return false
+}
 
-		// Verify deal exists
-		_, err := s.repo.GetDeal(ctx, orderReq.DealID)
-		if err != nil {
+// GetDealView retrieves a deal with its orders and the settlements computed from them.
+// If asOf is set, the deal and its orders are reconstructed in the state they had at that moment.
+func (s *Service) GetDealView(ctx context.Context, dealID int, asOf *time.Time, sort domain.OrderSort) (*domain.DealView, error) {
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if err := s.CheckDealAccess(ctx, dealID); err != nil {
+		return nil, err
+	}
+	if err := validateAsOf(asOf); err != nil {
+		return nil, err
+	}
+	if err := validateOrderSort(sort); err != nil {
+		return nil, err
+	}
+
+	var deal *domain.Deal
+	var orders []*domain.Order
+	var err error
+	if asOf != nil {
+		deal, orders, err = s.repo.GetDealWithOrdersAsOf(ctx, dealID, *asOf, sort)
+	} else {
+		deal, orders, err = s.repo.GetDealWithOrders(ctx, dealID, sort)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+
+	if err := s.applyOrderCurrency(ctx, orders...); err != nil {
+		return nil, err
+	}
+	rules, err := s.nettingRules(ctx, nettingTime(asOf))
+	if err != nil {
+		return nil, err
+	}
+	settlements, legs, err := s.netSettlements(ctx, dealID, orders, asOf, rules)
+	if err != nil {
+		return nil, err
+	}
+	// Карточка клиента показывается в текущем состоянии, в том числе для исторического среза
+	var client *domain.Client
+	if deal.ClientID > 0 {
+		client, err = s.repo.GetClient(ctx, deal.ClientID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("failed to get client: %w", err)
+		}
+	}
+	vehicles, err := s.dealVehicles(ctx, orders)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.DealView{
+		Deal:        deal,
+		Client:      client,
+		Vehicles:    vehicles,
+		Orders:      orders,
+		Settlements: settlements,
+		Legs:        legs,
+	}, nil
+}
+
+// ListDealHistory retrieves the change history of a deal.
+func (s *Service) ListDealHistory(ctx context.Context, dealID int) ([]*domain.DealHistoryEntry, error) {
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+
+	entries, err := s.repo.ListDealHistory(ctx, dealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deal history: %w", err)
+	}
+
+	// История удалённой сделки остаётся доступной, поэтому 404 только если записей нет вовсе
+	if len(entries) == 0 {
+		if _, err := s.repo.GetDeal(ctx, dealID); err != nil {
 			if errors.Is(err, repository.ErrNotFound) {
 				return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
 			}
 			return nil, fmt.Errorf("failed to get deal: %w", err)
 		}
+	}
 
-		order := &domain.Order{
-			DealID:          orderReq.DealID,
-			OrderTypeID:     orderReq.OrderTypeID,
-			Amount:          orderReq.Amount,
-			Status:          domain.StatusPending, // Default status
-			NeedAndOrdersID: orderReq.NeedAndOrdersID,
-			BankID:          orderReq.BankID,
+	return entries, nil
+}
+
+// Order list page size limits.
+const (
+	defaultOrdersLimit = 20
+	maxOrdersLimit     = 100
+)
+
+// ListOrders retrieves a sorted page of orders for the client matching the filter.
+// Zero page and limit select the defaults.
+func (s *Service) ListOrders(ctx context.Context, clientID int, filter domain.OrderFilter, sort domain.OrderSort, page, limit int) (*domain.OrderList, error) {
+	if clientID <= 0 {
+		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+	if err := checkClientAccess(ctx, clientID); err != nil {
+		return nil, err
+	}
+	if err := validateOrderFilter(filter); err != nil {
+		return nil, err
+	}
+	if err := validateOrderSort(sort); err != nil {
+		return nil, err
+	}
+	if page == 0 {
+		page = 1
+	}
+	if page < 0 {
+		return nil, fmt.Errorf("page must be positive: %w", ErrInvalidInput)
+	}
+	if limit == 0 {
+		limit = defaultOrdersLimit
+	}
+	if limit < 0 || limit > maxOrdersLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d: %w", maxOrdersLimit, ErrInvalidInput)
+	}
+
+	logrus.Info("List Orders Service")
+	orders, total, err := s.repo.ListOrders(ctx, clientID, filter, sort, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	if err := s.applyOrderCurrency(ctx, orders...); err != nil {
+		return nil, err
+	}
+
+	return &domain.OrderList{
+		Orders:  orders,
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+		HasNext: page*limit < total,
+	}, nil
+}
+
+// validateOrderFilter checks the values of an order filter and that the ranges are not inverted.
+func validateOrderFilter(filter domain.OrderFilter) error {
+	if filter.Status != nil {
+		if _, ok := domain.OrderTransitions[*filter.Status]; !ok {
+			return fmt.Errorf("unknown order status %q: %w", *filter.Status, ErrInvalidInput)
 		}
+	}
+	if filter.OrderTypeID != nil && *filter.OrderTypeID <= 0 {
+		return fmt.Errorf("invalid order_type_id: %w", ErrInvalidInput)
+	}
+	if filter.DealID != nil && *filter.DealID <= 0 {
+		return fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if filter.BankID != nil && *filter.BankID <= 0 {
+		return fmt.Errorf("invalid bank_id: %w", ErrInvalidInput)
+	}
+	if filter.AmountMin != nil && *filter.AmountMin < 0 {
+		return fmt.Errorf("amount_min must not be negative: %w", ErrInvalidInput)
+	}
+	if filter.AmountMax != nil && *filter.AmountMax < 0 {
+		return fmt.Errorf("amount_max must not be negative: %w", ErrInvalidInput)
+	}
+	if filter.AmountMin != nil && filter.AmountMax != nil && *filter.AmountMin > *filter.AmountMax {
+		return fmt.Errorf("amount_min must not exceed amount_max: %w", ErrInvalidInput)
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return fmt.Errorf("created_from must be before created_to: %w", ErrInvalidInput)
+	}
+	return nil
+}
+
+// orderSortFields is the whitelist of fields an order list can be sorted by.
+var orderSortFields = map[string]bool{
+	domain.OrderSortAmount:    true,
+	domain.OrderSortCreatedAt: true,
+	domain.OrderSortStatus:    true,
+}
+
+// validateOrderSort checks the sort field and direction against the whitelist. Empty values are allowed.
+func validateOrderSort(sort domain.OrderSort) error {
+	if sort.Field != "" && !orderSortFields[sort.Field] {
+		return fmt.Errorf("unknown sort field %q: %w", sort.Field, ErrInvalidInput)
+	}
+	if sort.Direction != "" && sort.Direction != domain.SortAsc && sort.Direction != domain.SortDesc {
+		return fmt.Errorf("unknown sort order %q: %w", sort.Direction, ErrInvalidInput)
+	}
+	return nil
+}
+
+// CreateOrders creates new orders for the specified client. The batch is atomic: either all orders
+// are created or none, and the error of a failed item is a *domain.BatchItemError with its index.
+func (s *Service) CreateOrders(ctx context.Context, clientID int, req []domain.OrderCreate) ([]*domain.Order, error) {
+	if clientID <= 0 {
+		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+
+	currencies, err := s.currencies(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		createdOrder, err := s.repo.CreateOrder(ctx, order)
+	orders := make([]*domain.Order, 0, len(req))
+	violations := make([]*domain.PriceViolation, 0, len(req))
+	for i, orderReq := range req {
+		order, violation, err := s.newOrder(ctx, currencies, orderReq)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create order: %w", err)
+			return nil, &domain.BatchItemError{Index: i, Err: err}
+		}
+		orders = append(orders, order)
+		violations = append(violations, violation)
+	}
+
+	createdOrders, err := s.repo.CreateOrders(ctx, orders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create orders: %w", err)
+	}
+	for i, createdOrder := range createdOrders {
+		if violations[i] != nil {
+			createdOrder.PriceWarnings = append(createdOrder.PriceWarnings, violations[i])
+		}
+		// Подозрительные заказы не участвуют в неттинге до решения администратора
+		if err := s.reviewOrder(ctx, clientID, createdOrder); err != nil {
+			return nil, err
 		}
-		createdOrders = append(createdOrders, createdOrder)
+	}
+	if err := s.applyOrderCurrency(ctx, createdOrders...); err != nil {
+		return nil, err
+	}
+	if len(createdOrders) == 1 {
+		auditChange(ctx, "orders", createdOrders[0].OrderID, nil, createdOrders[0])
+	} else {
+		auditChange(ctx, "orders", 0, nil, createdOrders)
 	}
 
 	return createdOrders, nil
 }
 
-// UpdateOrder updates an existing order.
+// newOrder validates an order creation request and builds the order to insert together with
+// the price warning accepted in warn mode.
+func (s *Service) newOrder(ctx context.Context, currencies map[string]*domain.Currency, orderReq domain.OrderCreate) (*domain.Order, *domain.PriceViolation, error) {
+	// Validate input
+	if orderReq.Amount <= 0 {
+		return nil, nil, fmt.Errorf("amount must be positive: %w", ErrInvalidInput)
+	}
+	if orderReq.DealID <= 0 {
+		return nil, nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if err := s.validateOrderTypeID(ctx, orderReq.OrderTypeID); err != nil {
+		return nil, nil, err
+	}
+	if orderReq.BankID != nil && *orderReq.BankID <= 0 {
+		return nil, nil, fmt.Errorf("invalid bank_id: %w", ErrInvalidInput)
+	}
+	if err := s.validateOrderBank(ctx, orderReq.BankID, nil); err != nil {
+		return nil, nil, err
+	}
+	if orderReq.DealershipID != nil && *orderReq.DealershipID <= 0 {
+		return nil, nil, fmt.Errorf("invalid dealership_id: %w", ErrInvalidInput)
+	}
+	if orderReq.VehicleID != nil && *orderReq.VehicleID <= 0 {
+		return nil, nil, fmt.Errorf("invalid vehicle_id: %w", ErrInvalidInput)
+	}
+	if orderReq.CreditContractID != nil && *orderReq.CreditContractID <= 0 {
+		return nil, nil, fmt.Errorf("invalid credit_contract_id: %w", ErrInvalidInput)
+	}
+	if orderReq.AppraisalID != nil && *orderReq.AppraisalID <= 0 {
+		return nil, nil, fmt.Errorf("invalid appraisal_id: %w", ErrInvalidInput)
+	}
+	if orderReq.CurrencyCode == "" {
+		orderReq.CurrencyCode = domain.DefaultCurrencyCode
+	}
+	if _, ok := currencies[orderReq.CurrencyCode]; !ok {
+		return nil, nil, fmt.Errorf("unknown currency_code %s: %w", orderReq.CurrencyCode, ErrInvalidInput)
+	}
+
+	// Verify deal exists and accepts orders
+	deal, err := s.repo.GetDeal(ctx, orderReq.DealID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+	if deal.Status != domain.DealStatusActive {
+		return nil, nil, fmt.Errorf("deal %d is %s, orders can only be added to active deals: %w",
+			deal.DealID, deal.Status, ErrConflict)
+	}
+	if deal.DeletionScheduledAt != nil {
+		return nil, nil, fmt.Errorf("deal %d is pending deletion: %w", deal.DealID, ErrConflict)
+	}
+	// По умолчанию заказ оформляет дилерский центр сделки
+	if orderReq.DealershipID == nil {
+		orderReq.DealershipID = &deal.DealershipID
+	}
+
+	order := &domain.Order{
+		DealID:           orderReq.DealID,
+		OrderTypeID:      orderReq.OrderTypeID,
+		Amount:           orderReq.Amount,
+		Status:           domain.StatusPending, // Default status
+		NeedAndOrdersID:  orderReq.NeedAndOrdersID,
+		BankID:           orderReq.BankID,
+		CurrencyCode:     orderReq.CurrencyCode,
+		DealershipID:     orderReq.DealershipID,
+		VehicleID:        orderReq.VehicleID,
+		Discount:         orderReq.Discount,
+		VATRate:          orderReq.VATRate,
+		ExecuteAt:        orderReq.ExecuteAt,
+		InsurerID:        orderReq.InsurerID,
+		CreditContractID: orderReq.CreditContractID,
+		AppraisalID:      orderReq.AppraisalID,
+	}
+	if err := s.validateOrderInsurer(ctx, order); err != nil {
+		return nil, nil, err
+	}
+	if err := s.validateOrderAppraisal(ctx, order, false); err != nil {
+		return nil, nil, err
+	}
+	if err := s.validateOrderVehicle(ctx, order); err != nil {
+		return nil, nil, err
+	}
+	if err := s.validateOrderCreditContract(ctx, order); err != nil {
+		return nil, nil, err
+	}
+	if err := priceOrder(order); err != nil {
+		return nil, nil, err
+	}
+	violation, err := s.checkOrderPrice(ctx, order)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return order, violation, nil
+}
+
+// UpdateOrder updates an existing order of the client's deal.
 func (s *Service) UpdateOrder(ctx context.Context, clientID, orderID int, req domain.OrderCreate) (*domain.Order, error) {
 	if clientID <= 0 {
 		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
 	}
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
 
-	// Fetch the order to verify existence
-	order, err := s.repo.GetOrder(ctx, orderID)
+	// Fetch the order to verify existence and ownership
+	order, err := s.getClientOrder(ctx, clientID, orderID)
 	if err != nil {
-		if errors.Is(err, repository.ErrNotFound) {
-			return nil, fmt.Errorf("order not found: %w", ErrNotFound)
-		}
-		return nil, fmt.Errorf("failed to get order: %w", err)
+		return nil, err
 	}
+	before := *order
 
 	// Validate input
 	if req.Amount <= 0 {
@@ -154,21 +582,42 @@ func (s *Service) UpdateOrder(ctx context.Context, clientID, orderID int, req do
 	if req.DealID <= 0 {
 		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
 	}
-	if req.OrderTypeID <= 0 {
-		return nil, fmt.Errorf("invalid order_type_id: %w", ErrInvalidInput)
+	if err := s.validateOrderTypeID(ctx, req.OrderTypeID); err != nil {
+		return nil, err
 	}
 	if req.BankID != nil && *req.BankID <= 0 {
 		return nil, fmt.Errorf("invalid bank_id: %w", ErrInvalidInput)
 	}
+	if err := s.validateOrderBank(ctx, req.BankID, order.BankID); err != nil {
+		return nil, err
+	}
+	if req.DealershipID != nil && *req.DealershipID <= 0 {
+		return nil, fmt.Errorf("invalid dealership_id: %w", ErrInvalidInput)
+	}
+	if req.VehicleID != nil && *req.VehicleID <= 0 {
+		return nil, fmt.Errorf("invalid vehicle_id: %w", ErrInvalidInput)
+	}
+	if req.CreditContractID != nil && *req.CreditContractID <= 0 {
+		return nil, fmt.Errorf("invalid credit_contract_id: %w", ErrInvalidInput)
+	}
+	if req.AppraisalID != nil && *req.AppraisalID <= 0 {
+		return nil, fmt.Errorf("invalid appraisal_id: %w", ErrInvalidInput)
+	}
 
 	// Verify deal exists
-	_, err = s.repo.GetDeal(ctx, req.DealID)
+	deal, err := s.repo.GetDeal(ctx, req.DealID)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get deal: %w", err)
 	}
+	if deal.ClientID != clientID {
+		return nil, fmt.Errorf("deal %d does not belong to client %d: %w", deal.DealID, clientID, ErrUnauthorized)
+	}
+
+	// Заказ на трейд-ин, оформленный до появления оценок, может оставаться без оценки
+	legacyTradeIn := order.OrderTypeID == domain.OrderTypeTradeIn && order.AppraisalID == nil
 
 	// Update order fields
 	order.DealID = req.DealID
@@ -176,6 +625,37 @@ func (s *Service) UpdateOrder(ctx context.Context, clientID, orderID int, req do
 	order.Amount = req.Amount
 	order.NeedAndOrdersID = req.NeedAndOrdersID
 	order.BankID = req.BankID
+	if req.DealershipID != nil {
+		order.DealershipID = req.DealershipID
+	} else if order.DealershipID == nil {
+		order.DealershipID = &deal.DealershipID
+	}
+	order.VehicleID = req.VehicleID
+	order.Discount = req.Discount
+	order.VATRate = req.VATRate
+	order.ExecuteAt = req.ExecuteAt
+	order.InsurerID = req.InsurerID
+	order.CreditContractID = req.CreditContractID
+	order.AppraisalID = req.AppraisalID
+	if err := s.validateOrderInsurer(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := s.validateOrderAppraisal(ctx, order, legacyTradeIn); err != nil {
+		return nil, err
+	}
+	if err := s.validateOrderVehicle(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := s.validateOrderCreditContract(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := priceOrder(order); err != nil {
+		return nil, err
+	}
+	violation, err := s.checkOrderPrice(ctx, order)
+	if err != nil {
+		return nil, err
+	}
 
 	updatedOrder, err := s.repo.UpdateOrder(ctx, order)
 	if err != nil {
@@ -184,136 +664,367 @@ func (s *Service) UpdateOrder(ctx context.Context, clientID, orderID int, req do
 		}
 		return nil, fmt.Errorf("failed to update order: %w", err)
 	}
+	if violation != nil {
+		updatedOrder.PriceWarnings = append(updatedOrder.PriceWarnings, violation)
+	}
+	if err := s.applyOrderCurrency(ctx, updatedOrder); err != nil {
+		return nil, err
+	}
+	auditChange(ctx, "orders", orderID, &before, updatedOrder)
 
 	return updatedOrder, nil
 }
 
-//// ListMonetarySettlements retrieves a paginated list of monetary settlements for the deal.
-//func (s *Service) ListMonetarySettlements(ctx context.Context, dealID int) ([]*domain.MonetarySettlement, int, error) {
-//	if dealID <= 0 {
-//		return nil, 0, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
-//	}
-//
-//	// Получить взаиморасчёты с типом заказ в рамках сделки
-//	settlements, err := s.repo.ListOrdersByDeals(ctx, dealID)
-//	if err != nil {
-//		return nil, 0, fmt.Errorf("failed to list monetary settlements: %w", err)
-//	}
-//	// Построение матрицы обязательств
-//	// Если нет ни одного заказа, у которого bank_id не 0,то двухсторонний нетто расчёт (клиент, рольф)
-//	// Если есть заказ с bank_id не 0 => многосторонний нетто расчёт (клиент, рольф, банк)
-//
-//	// Расчёт чистых позиций для каждого участница клиринга (сумма a_ij - сумма a_ji)
-//
-//	// Отдаём MonetarySettlement , где Amount - задолжность рольфу (от клиента и банка)
-//	return monetary_settlement, total, nil
-//}
+// PatchOrder partially updates an order of the client's deal. Only the provided fields are changed.
+func (s *Service) PatchOrder(ctx context.Context, clientID, orderID int, req domain.OrderUpdate) (*domain.Order, error) {
+	if clientID <= 0 {
+		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
 
-// ListMonetarySettlements performs a netting calculation (bilateral or multilateral) based on orders for a deal.
-func (s *Service) ListMonetarySettlements(ctx context.Context, dealID int) ([]*domain.MonetarySettlement, error) {
-	if dealID <= 0 {
+	// Validate input
+	if req == (domain.OrderUpdate{}) {
+		return nil, fmt.Errorf("no fields to update: %w", ErrInvalidInput)
+	}
+	if req.Amount != nil && *req.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive: %w", ErrInvalidInput)
+	}
+	if req.DealID != nil && *req.DealID <= 0 {
 		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
 	}
+	if req.OrderTypeID != nil {
+		if err := s.validateOrderTypeID(ctx, *req.OrderTypeID); err != nil {
+			return nil, err
+		}
+	}
+	if req.NeedAndOrdersID != nil && *req.NeedAndOrdersID <= 0 {
+		return nil, fmt.Errorf("invalid need_and_orders_id: %w", ErrInvalidInput)
+	}
+	if req.BankID != nil && *req.BankID <= 0 {
+		return nil, fmt.Errorf("invalid bank_id: %w", ErrInvalidInput)
+	}
+	if req.DealershipID != nil && *req.DealershipID <= 0 {
+		return nil, fmt.Errorf("invalid dealership_id: %w", ErrInvalidInput)
+	}
+	if req.VehicleID != nil && *req.VehicleID <= 0 {
+		return nil, fmt.Errorf("invalid vehicle_id: %w", ErrInvalidInput)
+	}
+	if req.CreditContractID != nil && *req.CreditContractID <= 0 {
+		return nil, fmt.Errorf("invalid credit_contract_id: %w", ErrInvalidInput)
+	}
+	if req.AppraisalID != nil && *req.AppraisalID <= 0 {
+		return nil, fmt.Errorf("invalid appraisal_id: %w", ErrInvalidInput)
+	}
 
-	// Получить взаиморасчёты с типом заказ в рамках сделки
-	orders, err := s.repo.ListOrdersByDeals(ctx, dealID)
+	order, err := s.getClientOrder(ctx, clientID, orderID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list orders: %w", err)
+		return nil, err
 	}
+	before := *order
 
-	// Проверка на многосторонний нетто-расчёт
-	hasBank := false
-	for _, order := range orders {
-		if order.BankID != nil {
-			hasBank = true
-			break
+	// Перенос заказа допускается только в другую сделку того же клиента
+	if req.DealID != nil && *req.DealID != order.DealID {
+		deal, err := s.repo.GetDeal(ctx, *req.DealID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+			}
+			return nil, fmt.Errorf("failed to get deal: %w", err)
+		}
+		if deal.ClientID != clientID {
+			return nil, fmt.Errorf("deal %d does not belong to client %d: %w", deal.DealID, clientID, ErrUnauthorized)
 		}
+		order.DealID = *req.DealID
+	}
+	if err := s.validateOrderBank(ctx, req.BankID, order.BankID); err != nil {
+		return nil, err
+	}
+
+	legacyTradeIn := order.OrderTypeID == domain.OrderTypeTradeIn && order.AppraisalID == nil
+
+	// Update only the provided fields
+	if req.OrderTypeID != nil {
+		order.OrderTypeID = *req.OrderTypeID
+	}
+	if req.Amount != nil {
+		order.Amount = *req.Amount
+	}
+	if req.NeedAndOrdersID != nil {
+		order.NeedAndOrdersID = req.NeedAndOrdersID
+	}
+	if req.BankID != nil {
+		order.BankID = req.BankID
+	}
+	if req.DealershipID != nil {
+		order.DealershipID = req.DealershipID
+	}
+	if req.VehicleID != nil {
+		order.VehicleID = req.VehicleID
+	}
+	if req.Discount != nil {
+		order.Discount = *req.Discount
+	}
+	if req.VATRate != nil {
+		order.VATRate = *req.VATRate
+	}
+	if req.ExecuteAt != nil {
+		order.ExecuteAt = req.ExecuteAt
+	}
+	if req.InsurerID != nil {
+		order.InsurerID = req.InsurerID
+	}
+	if req.CreditContractID != nil {
+		order.CreditContractID = req.CreditContractID
+	}
+	if req.AppraisalID != nil {
+		order.AppraisalID = req.AppraisalID
+	}
+	if err := s.validateOrderInsurer(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := s.validateOrderAppraisal(ctx, order, legacyTradeIn); err != nil {
+		return nil, err
+	}
+	if err := s.validateOrderVehicle(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := s.validateOrderCreditContract(ctx, order); err != nil {
+		return nil, err
+	}
+	// Сумма с НДС пересчитывается при любом изменении суммы, скидки или ставки
+	if err := priceOrder(order); err != nil {
+		return nil, err
+	}
+	violation, err := s.checkOrderPrice(ctx, order)
+	if err != nil {
+		return nil, err
 	}
 
-	// Участники: Клиент (C), Дилерский центр (R), Банк (B) - опционально
-	participants := []string{"Client", "Rolf"}
-	if hasBank {
-		participants = append(participants, "Bank")
+	updatedOrder, err := s.repo.UpdateOrder(ctx, order)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("order not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to update order: %w", err)
+	}
+	if violation != nil {
+		updatedOrder.PriceWarnings = append(updatedOrder.PriceWarnings, violation)
+	}
+	if err := s.applyOrderCurrency(ctx, updatedOrder); err != nil {
+		return nil, err
 	}
-	n := len(participants)
+	auditChange(ctx, "orders", orderID, &before, updatedOrder)
+
+	return updatedOrder, nil
+}
 
-	// Составление матрицы обязательств: obligations[i][j] - это сумма, которую участник i должен участнику j
-	obligations := make([][]float64, n)
-	for i := range obligations {
-		obligations[i] = make([]float64, n)
+// GetOrder retrieves an order of the client's deal.
+func (s *Service) GetOrder(ctx context.Context, clientID, orderID int) (*domain.Order, error) {
+	if clientID <= 0 {
+		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+	if err := checkClientAccess(ctx, clientID); err != nil {
+		return nil, err
 	}
 
-	// Построение матрицы обязательств на основе order_type_id
-	for _, order := range orders {
-		amount := order.Amount
-		switch order.OrderTypeID {
-		case 1: // Покупка: Клиент должен Дилерскому центру
-			obligations[0][1] += amount // C -> R
-		case 2: // Кредит: Банк должен Клиенту
-			// (задолжность Клиента перед Банком не отображается, так как выходит за рамки сделки)
-			//При этом кредитные средства выделяются именно клиенту, а не Рольфу, так как расчеты Банка с Рольфом также выходят за рамки сделки.
-			if order.BankID != nil {
-				obligations[2][0] += amount // B -> C
-			}
-		case 3: // Трейд-ин: Дилерский центр должен Клиенту
-			obligations[1][0] += amount // Дилерский центр -> Клиент
-		default:
-			return nil, fmt.Errorf("unknown order_type_id %d: %w", order.OrderTypeID, ErrInvalidInput)
+	order, err := s.getClientOrder(ctx, clientID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyOrderCurrency(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// getClientOrder retrieves an order and checks that its deal belongs to the client.
+// An order of another client is reported as ErrUnauthorized.
+func (s *Service) getClientOrder(ctx context.Context, clientID, orderID int) (*domain.Order, error) {
+	if orderID <= 0 {
+		return nil, fmt.Errorf("invalid order_id: %w", ErrInvalidInput)
+	}
+
+	order, err := s.repo.GetClientOrder(ctx, clientID, orderID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, fmt.Errorf("order not found: %w", ErrNotFound)
+		case errors.Is(err, repository.ErrUnauthorized):
+			return nil, fmt.Errorf("order %d does not belong to client %d: %w", orderID, clientID, ErrUnauthorized)
 		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
 
-	// Рассчёт чистых позиций: net[i] = sum(a_ij) - sum(a_ji)
-	netPositions := make([]float64, n)
-	for i := 0; i < n; i++ {
-		for j := 0; j < n; j++ {
-			if i != j {
-				netPositions[i] += obligations[i][j]
-				netPositions[i] -= obligations[j][i]
-			}
+	return order, nil
+}
+
+// ListOrderHistory returns the revisions of a client's order, including a deleted one.
+func (s *Service) ListOrderHistory(ctx context.Context, clientID, orderID int) ([]*domain.OrderRevision, error) {
+	if clientID <= 0 {
+		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+	if err := checkClientAccess(ctx, clientID); err != nil {
+		return nil, err
+	}
+	if orderID <= 0 {
+		return nil, fmt.Errorf("invalid order_id: %w", ErrInvalidInput)
+	}
+
+	revisions, err := s.repo.ListClientOrderRevisions(ctx, clientID, orderID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, fmt.Errorf("order not found: %w", ErrNotFound)
+		case errors.Is(err, repository.ErrUnauthorized):
+			return nil, fmt.Errorf("order %d does not belong to client %d: %w", orderID, clientID, ErrUnauthorized)
 		}
+		return nil, fmt.Errorf("failed to list order history: %w", err)
 	}
 
-	// Создание денежных расчетов по ненулевым чистым позициям
-	var settlements []*domain.MonetarySettlement
-	now := time.Now()
-	for i, net := range netPositions {
-		if net != 0 {
-			settlement := &domain.MonetarySettlement{
-				MonetarySettlementID: 0, // Not saved in DB yet
-				DealID:               &dealID,
-				Amount:               net, // Positive: owes, Negative: owed
-				Status:               domain.StatusPending,
-				CreatedAt:            now,
-				UpdatedAt:            now,
-			}
-			if hasBank && participants[i] == "Bank" {
-				// Set BankID for bank participant (assume bank_id from first order with bank)
-				for _, order := range orders {
-					if order.BankID != nil {
-						settlement.BankID = order.BankID
-						break
-					}
-				}
-			}
-			settlements = append(settlements, settlement)
+	return revisions, nil
+}
+
+// TransitionOrder moves an order of the client's deal to another status if the transition is allowed.
+func (s *Service) TransitionOrder(ctx context.Context, clientID, orderID int, req domain.OrderTransition) (*domain.Order, error) {
+	if clientID <= 0 {
+		return nil, fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if _, ok := domain.OrderTransitions[req.Status]; !ok {
+		return nil, fmt.Errorf("unknown order status %q: %w", req.Status, ErrInvalidInput)
+	}
+
+	order, err := s.getClientOrder(ctx, clientID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canTransitionOrder(order.Status, req.Status) {
+		return nil, fmt.Errorf("transition from %s to %s is not allowed: %w", order.Status, req.Status, ErrInvalidInput)
+	}
+
+	updatedOrder, err := s.repo.UpdateOrderStatus(ctx, orderID, order.Status, req.Status)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("order not found: %w", ErrNotFound)
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("order status changed concurrently: %w", ErrConflict)
 		}
+		return nil, fmt.Errorf("failed to update order status: %w", err)
+	}
+	if err := s.applyOrderCurrency(ctx, updatedOrder); err != nil {
+		return nil, err
 	}
-	return settlements, nil
+	auditChange(ctx, "orders", orderID, order, updatedOrder)
+
+	return updatedOrder, nil
 }
 
-//// ListMonetarySettlements retrieves a paginated list of monetary settlements for the deal.
-//func (s *Service) ListMonetarySettlements(ctx context.Context, dealID int) ([]*domain.MonetarySettlement, int, error) {
-//	if dealID <= 0 {
-//		return nil, 0, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
-//	}
-//
-//	settlements, total, err := s.repo.ListMonetarySettlements(ctx, dealID)
-//	if err != nil {
-//		return nil, 0, fmt.Errorf("failed to list monetary settlements: %w", err)
-//	}
-//
-//	return settlements, total, nil
-//}
+// canTransitionOrder reports whether an order may move from one status to another.
+func canTransitionOrder(from, to string) bool {
+	for _, allowed := range domain.OrderTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteOrder deletes an order of the client's deal. Executed orders cannot be deleted;
+// the settlements of the deal are flagged for recomputation.
+func (s *Service) DeleteOrder(ctx context.Context, clientID, orderID int) error {
+	if clientID <= 0 {
+		return fmt.Errorf("invalid client_id: %w", ErrInvalidInput)
+	}
+	if err := denyClientWrite(ctx); err != nil {
+		return err
+	}
+
+	order, err := s.getClientOrder(ctx, clientID, orderID)
+	if err != nil {
+		return err
+	}
+
+	if order.Status == domain.StatusExecuted {
+		return fmt.Errorf("order %d is executed and cannot be deleted: %w", orderID, ErrConflict)
+	}
+
+	if err := s.repo.DeleteOrder(ctx, orderID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("order not found: %w", ErrNotFound)
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return fmt.Errorf("order %d is executed and cannot be deleted: %w", orderID, ErrConflict)
+		}
+		return fmt.Errorf("failed to delete order: %w", err)
+	}
+	auditChange(ctx, "orders", orderID, order, nil)
+
+	return nil
+}
+
+// ListMonetarySettlements performs a netting calculation (bilateral or multilateral) based on orders for a deal.
+// If asOf is set, netting uses the orders of the deal in the state they had at that moment.
+// In gross mode every obligation is settled separately instead of the net positions; empty mode means net.
+// The transfers say who pays whom to settle the positions. The deal is not locked: callers that persist the
+// result lock it with LockDeal and pass the returned context.
+func (s *Service) ListMonetarySettlements(ctx context.Context, dealID int, asOf *time.Time, mode string) ([]*domain.MonetarySettlement, []*domain.SettlementLeg, error) {
+	if dealID <= 0 {
+		return nil, nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if err := s.CheckDealAccess(ctx, dealID); err != nil {
+		return nil, nil, err
+	}
+	switch mode {
+	case "":
+		mode = domain.SettlementModeNet
+	case domain.SettlementModeNet, domain.SettlementModeGross:
+	default:
+		return nil, nil, fmt.Errorf("invalid settlement_mode %q: %w", mode, ErrInvalidInput)
+	}
+	if err := validateAsOf(asOf); err != nil {
+		return nil, nil, err
+	}
+
+	// Получить взаиморасчёты с типом заказ в рамках сделки
+	var orders []*domain.Order
+	var err error
+	if asOf != nil {
+		_, orders, err = s.repo.GetDealWithOrdersAsOf(ctx, dealID, *asOf, domain.OrderSort{})
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, fmt.Errorf("deal not found at %s: %w", asOf.Format(time.RFC3339), ErrNotFound)
+		}
+	} else {
+		// Чтение без блокировки: сохраняющий результат код блокирует сделку сам и передает ее контекст
+		orders, err = s.repo.ListOrdersByDeals(ctx, dealID)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	rules, err := s.nettingRules(ctx, nettingTime(asOf))
+	if err != nil {
+		return nil, nil, err
+	}
+	if mode == domain.SettlementModeGross {
+		return s.grossSettlements(ctx, dealID, orders, asOf, rules)
+	}
+	return s.netSettlements(ctx, dealID, orders, asOf, rules)
+}
+
+// validateAsOf rejects as_of moments in the future.
+func validateAsOf(asOf *time.Time) error {
+	if asOf != nil && asOf.After(time.Now()) {
+		return fmt.Errorf("as_of must not be in the future: %w", ErrInvalidInput)
+	}
+	return nil
+}
 
 //// CreateMonetarySettlement creates a new monetary settlement.
 //func (s *Service) CreateMonetarySettlement(ctx context.Context, req domain.MonetarySettlementCreate) (*domain.MonetarySettlement, error) {
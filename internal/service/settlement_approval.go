@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// maxReviewCommentLength is the maximum length of an approval or rejection comment.
+const maxReviewCommentLength = 500
+
+// requiresApproval reports whether a settlement of the amount is above the approval threshold.
+func (s *Service) requiresApproval(amount domain.Money) bool {
+	threshold := s.cfg.Settlement.ApprovalThreshold
+	return threshold > 0 && amount.Abs() > domain.MoneyFromFloat(threshold)
+}
+
+// ApproveMonetarySettlement approves a settlement above the approval threshold, after which it can be executed.
+func (s *Service) ApproveMonetarySettlement(ctx context.Context, settlementID int, req domain.MonetarySettlementReview) (*domain.MonetarySettlement, error) {
+	return s.reviewMonetarySettlement(ctx, settlementID, domain.ApprovalApproved, req)
+}
+
+// RejectMonetarySettlement rejects a settlement above the approval threshold with a mandatory comment.
+// A rejected settlement cannot be executed; it can be cancelled.
+func (s *Service) RejectMonetarySettlement(ctx context.Context, settlementID int, req domain.MonetarySettlementReview) (*domain.MonetarySettlement, error) {
+	if strings.TrimSpace(req.Comment) == "" {
+		return nil, fmt.Errorf("comment is required: %w", ErrInvalidInput)
+	}
+	return s.reviewMonetarySettlement(ctx, settlementID, domain.ApprovalRejected, req)
+}
+
+// reviewMonetarySettlement records the decision of the current user on a settlement awaiting approval.
+// The user who fixed the settlement cannot review it.
+func (s *Service) reviewMonetarySettlement(ctx context.Context, settlementID int, decision string, req domain.MonetarySettlementReview) (*domain.MonetarySettlement, error) {
+	if settlementID <= 0 {
+		return nil, fmt.Errorf("invalid monetary_settlement_id: %w", ErrInvalidInput)
+	}
+	comment := strings.TrimSpace(req.Comment)
+	if utf8.RuneCountInString(comment) > maxReviewCommentLength {
+		return nil, fmt.Errorf("comment must not exceed %d characters: %w", maxReviewCommentLength, ErrInvalidInput)
+	}
+	reviewer, _ := ctx.Value(domain.ActorKey{}).(string)
+	if reviewer == "" {
+		return nil, fmt.Errorf("token subject is required to review settlements: %w", ErrForbidden)
+	}
+
+	settlement, err := s.repo.GetMonetarySettlement(ctx, settlementID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("monetary settlement not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get monetary settlement: %w", err)
+	}
+	if err := s.checkSettlementAccess(ctx, settlement); err != nil {
+		return nil, err
+	}
+	if settlement.Status != domain.StatusPending || settlement.ApprovalStatus != domain.ApprovalRequired {
+		return nil, fmt.Errorf("only pending settlements awaiting approval can be reviewed: %w", ErrConflict)
+	}
+	// Принцип четырех глаз: зафиксировавший расчет пользователь не может его одобрить
+	if settlement.CreatedBy == reviewer {
+		return nil, fmt.Errorf("settlement must be reviewed by a user other than %s: %w", reviewer, ErrForbidden)
+	}
+
+	reviewed, err := s.repo.ReviewMonetarySettlement(ctx, settlementID, decision, reviewer, comment)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("monetary settlement was reviewed concurrently: %w", ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to review monetary settlement: %w", err)
+	}
+	if err := s.applySettlementCurrency(ctx, reviewed); err != nil {
+		return nil, err
+	}
+	if err := s.applySettlementDueDates(ctx, time.Now(), reviewed); err != nil {
+		return nil, err
+	}
+
+	return reviewed, nil
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// Sizes of the payment_reference and cancellation_reason columns.
+const (
+	maxPaymentReferenceLength   = 100
+	maxCancellationReasonLength = 500
+)
+
+// Stored settlement list page size limits.
+const (
+	defaultSettlementsLimit = 20
+	maxSettlementsLimit     = 100
+)
+
+// fixPendingSettlements stores the payable net positions of a deal as pending settlements when the deal
+// enters clearing or a clearing session is computed, so each payment can be executed separately with its
+// own payment reference. sessionID is the computed clearing session, nil for a deal entering clearing.
+func (s *Service) fixPendingSettlements(ctx context.Context, dealID int, sessionID *int) ([]*domain.MonetarySettlement, error) {
+	// Блокировка сделки, чтобы заказы не менялись между расчётом и сохранением результата
+	ctx, unlock, err := s.repo.LockDeal(ctx, dealID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	settlements, _, err := s.ListMonetarySettlements(ctx, dealID, nil, domain.SettlementModeNet)
+	if err != nil {
+		return nil, err
+	}
+
+	var payable []*domain.MonetarySettlement
+	for _, settlement := range settlements {
+		// Отрицательная позиция - участнику должны, платёж по ней не исполняется
+		if settlement.Amount > 0 {
+			payable = append(payable, settlement)
+		}
+	}
+	if len(payable) > 0 {
+		runNumber, err := s.repo.NextSettlementRun(ctx, dealID)
+		if err != nil {
+			return nil, err
+		}
+		for _, settlement := range payable {
+			settlement.RunNumber = runNumber
+		}
+	}
+	// Крупные расчеты исполняются только после одобрения другим пользователем
+	actor, _ := ctx.Value(domain.ActorKey{}).(string)
+	for _, settlement := range payable {
+		settlement.CreatedBy = actor
+		settlement.ClearingSessionID = sessionID
+		if s.requiresApproval(settlement.Amount) {
+			settlement.ApprovalStatus = domain.ApprovalRequired
+		}
+	}
+
+	fixed, err := s.repo.FixPendingSettlements(ctx, dealID, payable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fix pending settlements: %w", err)
+	}
+	// Расчёты пересчитаны по актуальным заказам
+	if err := s.repo.ClearSettlementsRecompute(ctx, dealID); err != nil {
+		return nil, err
+	}
+	s.publishSettlementEvent(ctx, domain.EventSettlementCreated, fixed...)
+	return fixed, nil
+}
+
+// ListStoredMonetarySettlements retrieves a page of the stored settlements of a deal in the given status:
+// pending ones fixed when the deal entered clearing, executed or cancelled ones.
+func (s *Service) ListStoredMonetarySettlements(ctx context.Context, dealID int, status string, page, limit int) (*domain.MonetarySettlementList, error) {
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	switch status {
+	case domain.StatusPending, domain.StatusExecuted, domain.StatusCancelled:
+	default:
+		return nil, fmt.Errorf("unknown settlement status %q: %w", status, ErrInvalidInput)
+	}
+	if page == 0 {
+		page = 1
+	}
+	if page < 0 {
+		return nil, fmt.Errorf("page must be positive: %w", ErrInvalidInput)
+	}
+	if limit == 0 {
+		limit = defaultSettlementsLimit
+	}
+	if limit < 0 || limit > maxSettlementsLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d: %w", maxSettlementsLimit, ErrInvalidInput)
+	}
+
+	settlements, total, err := s.repo.ListStoredMonetarySettlements(ctx, dealID, status, page, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list monetary settlements: %w", err)
+	}
+	if err := s.applySettlementCurrency(ctx, settlements...); err != nil {
+		return nil, err
+	}
+	if err := s.applySettlementDueDates(ctx, time.Now(), settlements...); err != nil {
+		return nil, err
+	}
+
+	return &domain.MonetarySettlementList{
+		Settlements: settlements,
+		Page:        page,
+		Limit:       limit,
+		Total:       total,
+		HasNext:     page*limit < total,
+	}, nil
+}
+
+// ExecuteMonetarySettlement executes a pending settlement: it is marked executed with the payment reference,
+// its amount is allocated to the outstanding orders of the deal and offsetting ledger entries are posted.
+// Only the finance department executes settlements.
+func (s *Service) ExecuteMonetarySettlement(ctx context.Context, settlementID int, req domain.MonetarySettlementExecution) (*domain.MonetarySettlement, error) {
+	if err := requireRole(ctx, domain.RoleFinance); err != nil {
+		return nil, err
+	}
+	return s.executeMonetarySettlement(ctx, settlementID, req)
+}
+
+// executeMonetarySettlement executes a pending settlement on behalf of an authorized user or a bank
+// confirming the payment.
+func (s *Service) executeMonetarySettlement(ctx context.Context, settlementID int, req domain.MonetarySettlementExecution) (*domain.MonetarySettlement, error) {
+	if settlementID <= 0 {
+		return nil, fmt.Errorf("invalid monetary_settlement_id: %w", ErrInvalidInput)
+	}
+	paymentReference := strings.TrimSpace(req.PaymentReference)
+	if paymentReference == "" {
+		return nil, fmt.Errorf("payment_reference is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(paymentReference) > maxPaymentReferenceLength {
+		return nil, fmt.Errorf("payment_reference must not exceed %d characters: %w", maxPaymentReferenceLength, ErrInvalidInput)
+	}
+
+	settlement, err := s.repo.GetMonetarySettlement(ctx, settlementID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("monetary settlement not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get monetary settlement: %w", err)
+	}
+	if err := s.checkSettlementAccess(ctx, settlement); err != nil {
+		return nil, err
+	}
+	if settlement.Status != domain.StatusPending {
+		return nil, fmt.Errorf("monetary settlement is %s, only pending settlements can be executed: %w", settlement.Status, ErrConflict)
+	}
+	switch settlement.ApprovalStatus {
+	case domain.ApprovalRequired:
+		return nil, fmt.Errorf("monetary settlement awaits approval: %w", ErrConflict)
+	case domain.ApprovalRejected:
+		return nil, fmt.Errorf("monetary settlement was rejected by %s: %w", settlement.ReviewedBy, ErrConflict)
+	}
+
+	// Блокировка сделки, чтобы заказы не менялись во время распределения оплаты
+	ctx, unlock, err := s.repo.LockDeal(ctx, *settlement.DealID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	orders, err := s.repo.ListOrdersByDeals(ctx, *settlement.DealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	allocations, err := s.allocate(settlement.Amount, orders)
+	if err != nil {
+		return nil, err
+	}
+
+	executed, err := s.repo.ExecutePendingSettlement(ctx, settlementID, paymentReference, allocations, ledgerEntries(settlement))
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("monetary settlement was executed concurrently: %w", ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to execute monetary settlement: %w", err)
+	}
+	if err := s.applySettlementCurrency(ctx, executed); err != nil {
+		return nil, err
+	}
+	if err := s.applySettlementDueDates(ctx, time.Now(), executed); err != nil {
+		return nil, err
+	}
+	auditChange(ctx, "monetary_settlements", settlementID, settlement, executed)
+	s.publishSettlementEvent(ctx, domain.EventSettlementExecuted, executed)
+
+	return executed, nil
+}
+
+// CancelMonetarySettlement cancels a pending settlement with a mandatory reason. The deal is flagged
+// for re-netting, since the cancelled payment is no longer part of its clearing result.
+func (s *Service) CancelMonetarySettlement(ctx context.Context, settlementID int, req domain.MonetarySettlementCancellation) (*domain.MonetarySettlement, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if settlementID <= 0 {
+		return nil, fmt.Errorf("invalid monetary_settlement_id: %w", ErrInvalidInput)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(reason) > maxCancellationReasonLength {
+		return nil, fmt.Errorf("reason must not exceed %d characters: %w", maxCancellationReasonLength, ErrInvalidInput)
+	}
+
+	settlement, err := s.repo.GetMonetarySettlement(ctx, settlementID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("monetary settlement not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get monetary settlement: %w", err)
+	}
+	if err := s.checkSettlementAccess(ctx, settlement); err != nil {
+		return nil, err
+	}
+	if settlement.Status != domain.StatusPending {
+		return nil, fmt.Errorf("monetary settlement is %s, only pending settlements can be cancelled: %w", settlement.Status, ErrConflict)
+	}
+	if settlement.PaidAmount > 0 {
+		return nil, fmt.Errorf("monetary settlement is partially paid and cannot be cancelled: %w", ErrConflict)
+	}
+
+	cancelled, err := s.repo.CancelMonetarySettlement(ctx, settlementID, reason)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("monetary settlement status changed concurrently: %w", ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to cancel monetary settlement: %w", err)
+	}
+	if err := s.applySettlementCurrency(ctx, cancelled); err != nil {
+		return nil, err
+	}
+	if err := s.applySettlementDueDates(ctx, time.Now(), cancelled); err != nil {
+		return nil, err
+	}
+	auditChange(ctx, "monetary_settlements", settlementID, settlement, cancelled)
+	s.publishSettlementEvent(ctx, domain.EventSettlementCancelled, cancelled)
+
+	return cancelled, nil
+}
+
+// ledgerEntries returns the double entry of a settlement payment: the clearing account is debited
+// and the account of the paying participant is credited with the settlement amount.
+func ledgerEntries(settlement *domain.MonetarySettlement) []*domain.LedgerEntry {
+	return []*domain.LedgerEntry{
+		{Account: domain.LedgerAccountClearing, Direction: domain.LedgerDebit, Amount: settlement.Amount},
+		{Account: settlement.NettingParticipant().ID(), Direction: domain.LedgerCredit, Amount: settlement.Amount},
+	}
+}
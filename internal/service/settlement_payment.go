@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// RecordSettlementPayment records a partial payment against a pending settlement. The payment that covers
+// the remaining balance executes the settlement: the amount is allocated to orders and ledger entries are posted.
+// A settlement awaiting approval stays pending until it is approved and executed.
+func (s *Service) RecordSettlementPayment(ctx context.Context, settlementID int, req domain.SettlementPaymentCreate) (*domain.MonetarySettlement, error) {
+	if err := requireRole(ctx, domain.RoleFinance); err != nil {
+		return nil, err
+	}
+	// Неизвестный расчет отклоняет сама регистрация оплаты
+	if settlementID > 0 {
+		settlement, err := s.repo.GetMonetarySettlement(ctx, settlementID)
+		switch {
+		case err == nil:
+			if err := s.checkSettlementAccess(ctx, settlement); err != nil {
+				return nil, err
+			}
+		case !errors.Is(err, repository.ErrNotFound):
+			return nil, fmt.Errorf("failed to get monetary settlement: %w", err)
+		}
+	}
+	return s.recordSettlementPayment(ctx, settlementID, req)
+}
+
+// recordSettlementPayment records a payment on behalf of an authorized user or a bank confirming the payment.
+func (s *Service) recordSettlementPayment(ctx context.Context, settlementID int, req domain.SettlementPaymentCreate) (*domain.MonetarySettlement, error) {
+	if settlementID <= 0 {
+		return nil, fmt.Errorf("invalid monetary_settlement_id: %w", ErrInvalidInput)
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive: %w", ErrInvalidInput)
+	}
+	paymentReference := strings.TrimSpace(req.PaymentReference)
+	if paymentReference == "" {
+		return nil, fmt.Errorf("payment_reference is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(paymentReference) > maxPaymentReferenceLength {
+		return nil, fmt.Errorf("payment_reference must not exceed %d characters: %w", maxPaymentReferenceLength, ErrInvalidInput)
+	}
+
+	_, err := s.repo.CreateSettlementPayment(ctx, &domain.SettlementPayment{
+		MonetarySettlementID: settlementID,
+		Amount:               req.Amount,
+		PaymentReference:     paymentReference,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, fmt.Errorf("monetary settlement not found: %w", ErrNotFound)
+		case errors.Is(err, repository.ErrConflict):
+			return nil, fmt.Errorf("only pending settlements can receive payments: %w", ErrConflict)
+		case errors.Is(err, repository.ErrInvalidInput):
+			return nil, fmt.Errorf("payment exceeds the remaining balance of the settlement: %w", ErrInvalidInput)
+		}
+		return nil, fmt.Errorf("failed to record settlement payment: %w", err)
+	}
+
+	settlement, err := s.repo.GetMonetarySettlement(ctx, settlementID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monetary settlement: %w", err)
+	}
+	if settlement.Status == domain.StatusPending && settlement.RemainingAmount == 0 && !settlement.AwaitsApproval() {
+		// Оплата покрыла остаток: расчет исполняется по номеру последнего платежного документа
+		settlement, err = s.executeMonetarySettlement(ctx, settlementID, domain.MonetarySettlementExecution{
+			PaymentReference: paymentReference,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if err := s.applySettlementCurrency(ctx, settlement); err != nil {
+			return nil, err
+		}
+		if err := s.applySettlementDueDates(ctx, time.Now(), settlement); err != nil {
+			return nil, err
+		}
+	}
+
+	settlement.Payments, err = s.repo.ListSettlementPayments(ctx, settlementID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settlement payments: %w", err)
+	}
+	return settlement, nil
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"cliring/internal/domain"
+)
+
+// AccruePenalties accrues the penalty for every day of delay up to now of the overdue pending settlements
+// at the configured daily rate on their unpaid part. Days already accrued are skipped, so a run after
+// downtime catches up. It returns the number of penalties accrued.
+func (s *Service) AccruePenalties(ctx context.Context, now time.Time) (int, error) {
+	rate := s.cfg.Penalty.DailyRate
+	if rate <= 0 {
+		return 0, nil
+	}
+
+	settlements, err := s.repo.ListPayablePendingSettlements(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(settlements) == 0 {
+		return 0, nil
+	}
+	if err := s.applySettlementDueDates(ctx, now, settlements...); err != nil {
+		return 0, err
+	}
+
+	var ids []int
+	var from time.Time
+	for _, settlement := range settlements {
+		ids = append(ids, settlement.MonetarySettlementID)
+		if from.IsZero() || settlement.CreatedAt.Before(from) {
+			from = settlement.CreatedAt
+		}
+	}
+	lastDates, err := s.repo.LastPenaltyDates(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+	calendar, err := s.loadCalendar(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var penalties []*domain.SettlementPenalty
+	for _, settlement := range settlements {
+		base := settlement.Amount - settlement.PaidAmount
+		amount := base.Percent(rate)
+		if !settlement.Overdue || settlement.Participant == "" || base <= 0 || amount <= 0 {
+			continue
+		}
+		dueDate, err := time.ParseInLocation(domain.DateLayout, settlement.DueDate, now.Location())
+		if err != nil {
+			return 0, fmt.Errorf("invalid due_date %q: %w", settlement.DueDate, err)
+		}
+		day := calendar.overdueFrom(dueDate, s.cfg.Settlement.GraceDays)
+		if last, ok := lastDates[settlement.MonetarySettlementID]; ok {
+			lastDay, err := time.ParseInLocation(domain.DateLayout, last, now.Location())
+			if err != nil {
+				return 0, fmt.Errorf("invalid accrual date %q: %w", last, err)
+			}
+			if next := lastDay.AddDate(0, 0, 1); next.After(day) {
+				day = next
+			}
+		}
+
+		// Неустойка начисляется за каждый календарный день просрочки
+		for ; !day.After(today); day = day.AddDate(0, 0, 1) {
+			penalties = append(penalties, &domain.SettlementPenalty{
+				MonetarySettlementID: settlement.MonetarySettlementID,
+				DealID:               *settlement.DealID,
+				Debtor:               settlementParticipant(settlement),
+				AccrualDate:          day.Format(domain.DateLayout),
+				BaseAmount:           base,
+				Rate:                 rate,
+				Amount:               amount,
+				CurrencyCode:         settlement.CurrencyCode,
+			})
+		}
+	}
+
+	accrued, err := s.repo.CreateSettlementPenalties(ctx, penalties)
+	if err != nil {
+		return accrued, fmt.Errorf("failed to accrue penalties: %w", err)
+	}
+	return accrued, nil
+}
+
+// RunPenaltyAccrual periodically accrues penalties on overdue settlements until ctx is cancelled.
+func (s *Service) RunPenaltyAccrual(ctx context.Context) {
+	interval := s.cfg.Penalty.AccrualInterval
+	if interval <= 0 || s.cfg.Penalty.DailyRate <= 0 {
+		logrus.Info("Penalty accrual disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			accrued, err := s.AccruePenalties(ctx, time.Now())
+			if err != nil {
+				logrus.Error("Penalty accrual failed: ", err)
+				continue
+			}
+			if accrued > 0 {
+				logrus.WithField("accrued", accrued).Info("Penalty accrual completed")
+			}
+		}
+	}
+}
+
+// ListSettlementPenalties returns the penalties accrued on the overdue settlements of a deal.
+func (s *Service) ListSettlementPenalties(ctx context.Context, dealID int) ([]*domain.SettlementPenalty, error) {
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+
+	penalties, err := s.repo.ListSettlementPenalties(ctx, []int{dealID}, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settlement penalties: %w", err)
+	}
+	if penalties == nil {
+		penalties = []*domain.SettlementPenalty{}
+	}
+	return penalties, nil
+}
+
+// dealPenalties returns the penalties of the deals in the netting currency accrued up to asOf,
+// all of them if asOf is nil. Penalties are charged in the currency of the overdue settlement;
+// a penalty in another currency than the current netting currency of its deal is not netted.
+func (s *Service) dealPenalties(ctx context.Context, dealIDs []int, currencyCode string, asOf *time.Time) ([]*domain.SettlementPenalty, error) {
+	upTo := ""
+	if asOf != nil {
+		upTo = asOf.Format(domain.DateLayout)
+	}
+	penalties, err := s.repo.ListSettlementPenalties(ctx, dealIDs, upTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settlement penalties: %w", err)
+	}
+
+	netted := penalties[:0]
+	for _, penalty := range penalties {
+		if penalty.CurrencyCode == currencyCode {
+			netted = append(netted, penalty)
+		}
+	}
+	return netted, nil
+}
+
+// settlementParticipant returns the netting participant of a stored settlement.
+func settlementParticipant(settlement *domain.MonetarySettlement) domain.Participant {
+	p := domain.Participant{Role: settlement.Participant}
+	switch settlement.Participant {
+	case domain.ParticipantClient:
+		p.ExternalID = settlement.ClientID
+	case domain.ParticipantBank:
+		p.ExternalID = settlement.BankID
+	case domain.ParticipantDealership:
+		p.ExternalID = settlement.DealershipID
+	case domain.ParticipantInsurer:
+		p.ExternalID = settlement.InsurerID
+	}
+	return p
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cliring/internal/domain"
+)
+
+// SettlementReport aggregates the stored settlements created in [from, to] for the deals of a dealership
+// by status, bank and order type. The totals are computed by the database.
+func (s *Service) SettlementReport(ctx context.Context, dealershipID int, from, to string) (*domain.SettlementReport, error) {
+	if dealershipID <= 0 {
+		return nil, fmt.Errorf("invalid dealership_id: %w", ErrInvalidInput)
+	}
+	if err := checkDealershipScope(ctx, dealershipID); err != nil {
+		return nil, err
+	}
+	fromDate, err := time.Parse(domain.DateLayout, from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from %q: %w", from, ErrInvalidInput)
+	}
+	toDate, err := time.Parse(domain.DateLayout, to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to %q: %w", to, ErrInvalidInput)
+	}
+	if toDate.Before(fromDate) {
+		return nil, fmt.Errorf("to must not be before from: %w", ErrInvalidInput)
+	}
+	// Период включает день to целиком
+	end := toDate.AddDate(0, 0, 1)
+
+	report := &domain.SettlementReport{DealershipID: dealershipID, From: from, To: to}
+	if report.ByStatus, err = s.repo.SettlementTotalsByStatus(ctx, dealershipID, fromDate, end); err != nil {
+		return nil, err
+	}
+	if report.ByBank, err = s.repo.SettlementTotalsByBank(ctx, dealershipID, fromDate, end); err != nil {
+		return nil, err
+	}
+	if report.ByOrderType, err = s.repo.SettlementTotalsByOrderType(ctx, dealershipID, fromDate, end); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
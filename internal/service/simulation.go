@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cliring/internal/domain"
+)
+
+// Simulate runs the netting engine over hypothetical orders without persisting anything.
+// Rule overrides replace the catalog rule of their order type or add a new order type.
+func (s *Service) Simulate(ctx context.Context, req domain.SimulationRequest) (*domain.SimulationResult, error) {
+	if len(req.Orders) == 0 {
+		return nil, fmt.Errorf("no orders to simulate: %w", ErrInvalidInput)
+	}
+
+	catalogRules, err := s.nettingRules(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[int]domain.NettingRule, len(catalogRules)+len(req.Rules))
+	for orderTypeID, rule := range catalogRules {
+		rules[orderTypeID] = rule
+	}
+	for i, rule := range req.Rules {
+		if err := validateNettingRule(rule); err != nil {
+			return nil, fmt.Errorf("rules[%d]: %w", i, err)
+		}
+		rules[rule.OrderTypeID] = rule
+	}
+
+	currencies, err := s.currencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	orders := make([]*domain.Order, 0, len(req.Orders))
+	for i, orderReq := range req.Orders {
+		if orderReq.CurrencyCode == "" {
+			orderReq.CurrencyCode = domain.DefaultCurrencyCode
+		}
+		if err := validateSimulationOrder(orderReq, rules, currencies); err != nil {
+			return nil, &domain.BatchItemError{Index: i, Err: err}
+		}
+		// Все заказы сценария рассчитываются в одной валюте, как и заказы одной сделки
+		if i > 0 && orderReq.CurrencyCode != orders[0].CurrencyCode {
+			err := fmt.Errorf("currency_code %s differs from %s: %w", orderReq.CurrencyCode, orders[0].CurrencyCode, ErrInvalidInput)
+			return nil, &domain.BatchItemError{Index: i, Err: err}
+		}
+		order := &domain.Order{
+			OrderTypeID:  orderReq.OrderTypeID,
+			Amount:       orderReq.Amount,
+			Status:       domain.StatusPending,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			BankID:       orderReq.BankID,
+			CurrencyCode: orderReq.CurrencyCode,
+			DealershipID: orderReq.DealershipID,
+			InsurerID:    orderReq.InsurerID,
+			Discount:     orderReq.Discount,
+			VATRate:      orderReq.VATRate,
+		}
+		if err := priceOrder(order); err != nil {
+			return nil, &domain.BatchItemError{Index: i, Err: err}
+		}
+		order.OutstandingAmount = order.GrossAmount
+		orders = append(orders, order)
+	}
+
+	settlements, legs, err := s.netSettlements(ctx, 0, orders, &now, rules)
+	if err != nil {
+		return nil, err
+	}
+	// Сценарий не относится к сделке
+	for _, settlement := range settlements {
+		settlement.DealID = nil
+	}
+
+	result := &domain.SimulationResult{
+		Rules:       make([]domain.NettingRule, 0, len(rules)),
+		Settlements: settlements,
+		Legs:        legs,
+	}
+	for _, rule := range rules {
+		result.Rules = append(result.Rules, rule)
+	}
+	sort.Slice(result.Rules, func(i, j int) bool {
+		return result.Rules[i].OrderTypeID < result.Rules[j].OrderTypeID
+	})
+	if result.Settlements == nil {
+		result.Settlements = []*domain.MonetarySettlement{}
+	}
+	if result.Legs == nil {
+		result.Legs = []*domain.SettlementLeg{}
+	}
+
+	return result, nil
+}
+
+// validateNettingRule checks that a rule links two different known participants.
+func validateNettingRule(rule domain.NettingRule) error {
+	if rule.OrderTypeID <= 0 {
+		return fmt.Errorf("invalid order_type_id: %w", ErrInvalidInput)
+	}
+	for _, kind := range []string{rule.Debtor, rule.Creditor} {
+		switch kind {
+		case domain.ParticipantClient, domain.ParticipantBank, domain.ParticipantDealership, domain.ParticipantInsurer:
+		default:
+			return fmt.Errorf("unknown participant %q: %w", kind, ErrInvalidInput)
+		}
+	}
+	if rule.Debtor == rule.Creditor {
+		return fmt.Errorf("debtor and creditor must differ: %w", ErrInvalidInput)
+	}
+	return nil
+}
+
+// validateInsurer checks the insurer of an order. An insurer is required if the netting rule of
+// the order type includes the insurer participant, otherwise the order would drop out of netting.
+func validateInsurer(insurerID *int, rule domain.NettingRule) error {
+	if insurerID != nil && *insurerID <= 0 {
+		return fmt.Errorf("invalid insurer_id: %w", ErrInvalidInput)
+	}
+	if insurerID == nil && (rule.Debtor == domain.ParticipantInsurer || rule.Creditor == domain.ParticipantInsurer) {
+		return fmt.Errorf("insurer_id is required for order_type_id %d: %w", rule.OrderTypeID, ErrInvalidInput)
+	}
+	return nil
+}
+
+// validateSimulationOrder checks a hypothetical order against the effective rules.
+func validateSimulationOrder(order domain.SimulationOrder, rules map[int]domain.NettingRule, currencies map[string]*domain.Currency) error {
+	if order.Amount <= 0 {
+		return fmt.Errorf("amount must be positive: %w", ErrInvalidInput)
+	}
+	rule, ok := rules[order.OrderTypeID]
+	if !ok {
+		return fmt.Errorf("no netting rule for order_type_id %d: %w", order.OrderTypeID, ErrInvalidInput)
+	}
+	if order.BankID != nil && *order.BankID <= 0 {
+		return fmt.Errorf("invalid bank_id: %w", ErrInvalidInput)
+	}
+	if order.BankID == nil && (rule.Debtor == domain.ParticipantBank || rule.Creditor == domain.ParticipantBank) {
+		return fmt.Errorf("bank_id is required for order_type_id %d: %w", order.OrderTypeID, ErrInvalidInput)
+	}
+	if order.DealershipID != nil && *order.DealershipID <= 0 {
+		return fmt.Errorf("invalid dealership_id: %w", ErrInvalidInput)
+	}
+	if err := validateInsurer(order.InsurerID, rule); err != nil {
+		return err
+	}
+	if _, ok := currencies[order.CurrencyCode]; !ok {
+		return fmt.Errorf("unknown currency_code %s: %w", order.CurrencyCode, ErrInvalidInput)
+	}
+	return nil
+}
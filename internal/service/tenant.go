@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+const maxTenantNameLength = 255
+
+// tenantOf returns the tenant the request works with; empty for the public schema.
+func tenantOf(ctx context.Context) string {
+	tenant, _ := ctx.Value(domain.TenantKey{}).(string)
+	return tenant
+}
+
+// requirePlatformAdmin reports ErrForbidden unless the request is made by an administrator
+// outside any tenant: tenant administrators manage their own group only.
+func requirePlatformAdmin(ctx context.Context) error {
+	if err := requireRole(ctx, domain.RoleAdmin); err != nil {
+		return err
+	}
+	if tenantOf(ctx) != "" {
+		return fmt.Errorf("tenants are managed outside of a tenant: %w", ErrForbidden)
+	}
+	return nil
+}
+
+// ProvisionTenant registers a dealership group, creates its schema and applies the migrations to it.
+// A provisioning interrupted midway is completed by repeating the request.
+func (s *Service) ProvisionTenant(ctx context.Context, req domain.Tenant) (*domain.Tenant, error) {
+	if err := requirePlatformAdmin(ctx); err != nil {
+		return nil, err
+	}
+	req.TenantID = strings.TrimSpace(req.TenantID)
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || utf8.RuneCountInString(req.Name) > maxTenantNameLength {
+		return nil, fmt.Errorf("name must be 1 to %d characters: %w", maxTenantNameLength, ErrInvalidInput)
+	}
+
+	tenant, err := s.repo.ProvisionTenant(ctx, req.TenantID, req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInvalidInput):
+			return nil, fmt.Errorf("tenant_id must be up to 40 lowercase latin letters, digits or underscores starting with a letter: %w", ErrInvalidInput)
+		case errors.Is(err, repository.ErrConflict):
+			return nil, fmt.Errorf("tenant %s already exists: %w", req.TenantID, ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to provision tenant: %w", err)
+	}
+	return tenant, nil
+}
+
+// ListTenants returns the registered dealership groups.
+func (s *Service) ListTenants(ctx context.Context) ([]*domain.Tenant, error) {
+	if err := requirePlatformAdmin(ctx); err != nil {
+		return nil, err
+	}
+	tenants, err := s.repo.ListTenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// TenantExists reports whether the tenant of a token is provisioned.
+func (s *Service) TenantExists(ctx context.Context, tenantID string) (bool, error) {
+	exists, err := s.repo.HasTenant(ctx, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check tenant: %w", err)
+	}
+	return exists, nil
+}
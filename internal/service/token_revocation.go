@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+)
+
+const (
+	maxTokenIDLength          = 255
+	maxRevocationReasonLength = 500
+)
+
+// RevokeToken revokes a token before its expiry. Without a jti the token of the request itself is revoked;
+// other tokens are revoked by administrators only. The revocation is kept until the token expires, or for
+// the configured TTL if the expiry of another token is not given.
+func (s *Service) RevokeToken(ctx context.Context, req domain.TokenRevocation) (*domain.TokenRevocation, error) {
+	req.JTI = strings.TrimSpace(req.JTI)
+	req.Reason = strings.TrimSpace(req.Reason)
+	if utf8.RuneCountInString(req.Reason) > maxRevocationReasonLength {
+		return nil, fmt.Errorf("reason must not exceed %d characters: %w", maxRevocationReasonLength, ErrInvalidInput)
+	}
+	if len(req.JTI) > maxTokenIDLength {
+		return nil, fmt.Errorf("jti must not exceed %d characters: %w", maxTokenIDLength, ErrInvalidInput)
+	}
+
+	token, _ := ctx.Value(domain.TokenKey{}).(*domain.TokenInfo)
+	switch {
+	case req.JTI == "" || (token != nil && req.JTI == token.ID):
+		if token == nil || token.ID == "" {
+			return nil, fmt.Errorf("token has no jti and cannot be revoked: %w", ErrInvalidInput)
+		}
+		req.JTI = token.ID
+		if token.ExpiresAt != nil {
+			req.ExpiresAt = token.ExpiresAt
+		}
+	default:
+		if err := requireRole(ctx, domain.RoleAdmin); err != nil {
+			return nil, err
+		}
+	}
+	if req.ExpiresAt == nil {
+		expiresAt := time.Now().Add(s.cfg.Auth.RevocationTTL)
+		req.ExpiresAt = &expiresAt
+	}
+
+	revoked, err := s.repo.RevokeToken(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return revoked, nil
+}
+
+// IsTokenRevoked reports whether the token with the jti has been revoked.
+func (s *Service) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.repo.IsTokenRevoked(ctx, jti)
+}
+
+// PurgeRevokedTokens deletes revocations of expired tokens: such tokens are rejected anyway.
+func (s *Service) PurgeRevokedTokens(ctx context.Context) (int64, error) {
+	return s.repo.PurgeRevokedTokens(ctx, time.Now())
+}
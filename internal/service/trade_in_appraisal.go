@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// ListAppraisals returns the trade-in appraisals of a deal ordered by ID.
+func (s *Service) ListAppraisals(ctx context.Context, dealID int) ([]*domain.TradeInAppraisal, error) {
+	if dealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if err := s.CheckDealAccess(ctx, dealID); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetDeal(ctx, dealID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+
+	appraisals, err := s.repo.ListAppraisals(ctx, dealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appraisals: %w", err)
+	}
+	if appraisals == nil {
+		appraisals = []*domain.TradeInAppraisal{}
+	}
+
+	return appraisals, nil
+}
+
+// GetAppraisal returns a trade-in appraisal. Appraisals of deals the user may not see are not found.
+func (s *Service) GetAppraisal(ctx context.Context, appraisalID int) (*domain.TradeInAppraisal, error) {
+	if appraisalID <= 0 {
+		return nil, fmt.Errorf("invalid appraisal_id: %w", ErrInvalidInput)
+	}
+
+	appraisal, err := s.repo.GetAppraisal(ctx, appraisalID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("appraisal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get appraisal: %w", err)
+	}
+	if err := s.CheckDealAccess(ctx, appraisal.DealID); err != nil {
+		return nil, fmt.Errorf("appraisal not found: %w", ErrNotFound)
+	}
+
+	return appraisal, nil
+}
+
+// CreateAppraisal records a pending appraisal of a used vehicle of a deal.
+func (s *Service) CreateAppraisal(ctx context.Context, req domain.TradeInAppraisal) (*domain.TradeInAppraisal, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if req.DealID <= 0 {
+		return nil, fmt.Errorf("invalid deal_id: %w", ErrInvalidInput)
+	}
+	if err := s.CheckDealAccess(ctx, req.DealID); err != nil {
+		return nil, err
+	}
+	req.Appraiser = strings.TrimSpace(req.Appraiser)
+	if err := s.validateAppraisal(ctx, req); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetDeal(ctx, req.DealID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("deal not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+
+	appraisal, err := s.repo.CreateAppraisal(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create appraisal: %w", err)
+	}
+
+	return appraisal, nil
+}
+
+// UpdateAppraisal replaces the vehicle, amount and appraiser of a pending appraisal.
+// Reviewed appraisals are immutable: a new appraisal is recorded instead.
+func (s *Service) UpdateAppraisal(ctx context.Context, req domain.TradeInAppraisal) (*domain.TradeInAppraisal, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetAppraisal(ctx, req.AppraisalID); err != nil {
+		return nil, err
+	}
+	req.Appraiser = strings.TrimSpace(req.Appraiser)
+	if err := s.validateAppraisal(ctx, req); err != nil {
+		return nil, err
+	}
+
+	appraisal, err := s.repo.UpdateAppraisal(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("only pending appraisals can be changed: %w", ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to update appraisal: %w", err)
+	}
+
+	return appraisal, nil
+}
+
+// ApproveAppraisal approves a pending appraisal, after which a trade-in order can be placed from it.
+func (s *Service) ApproveAppraisal(ctx context.Context, appraisalID int, req domain.AppraisalReview) (*domain.TradeInAppraisal, error) {
+	return s.reviewAppraisal(ctx, appraisalID, domain.AppraisalApproved, req)
+}
+
+// RejectAppraisal rejects a pending appraisal with a mandatory comment.
+func (s *Service) RejectAppraisal(ctx context.Context, appraisalID int, req domain.AppraisalReview) (*domain.TradeInAppraisal, error) {
+	if strings.TrimSpace(req.Comment) == "" {
+		return nil, fmt.Errorf("comment is required: %w", ErrInvalidInput)
+	}
+	return s.reviewAppraisal(ctx, appraisalID, domain.AppraisalRejected, req)
+}
+
+// reviewAppraisal records the decision of the current user on a pending appraisal.
+func (s *Service) reviewAppraisal(ctx context.Context, appraisalID int, status string, req domain.AppraisalReview) (*domain.TradeInAppraisal, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	comment := strings.TrimSpace(req.Comment)
+	if utf8.RuneCountInString(comment) > maxReviewCommentLength {
+		return nil, fmt.Errorf("comment must not exceed %d characters: %w", maxReviewCommentLength, ErrInvalidInput)
+	}
+	if _, err := s.GetAppraisal(ctx, appraisalID); err != nil {
+		return nil, err
+	}
+	reviewer, _ := ctx.Value(domain.ActorKey{}).(string)
+
+	appraisal, err := s.repo.ReviewAppraisal(ctx, appraisalID, status, reviewer, comment)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("only pending appraisals can be reviewed: %w", ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to review appraisal: %w", err)
+	}
+
+	return appraisal, nil
+}
+
+// DeleteAppraisal removes an appraisal no order was placed from.
+func (s *Service) DeleteAppraisal(ctx context.Context, appraisalID int) error {
+	if err := denyClientWrite(ctx); err != nil {
+		return err
+	}
+	if _, err := s.GetAppraisal(ctx, appraisalID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteAppraisal(ctx, appraisalID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return fmt.Errorf("appraisal not found: %w", ErrNotFound)
+		case errors.Is(err, repository.ErrConflict):
+			return fmt.Errorf("appraisal %d is referenced by orders: %w", appraisalID, ErrConflict)
+		}
+		return fmt.Errorf("failed to delete appraisal: %w", err)
+	}
+
+	return nil
+}
+
+// validateAppraisal checks an appraisal: a known used vehicle, a positive amount and the appraiser.
+func (s *Service) validateAppraisal(ctx context.Context, appraisal domain.TradeInAppraisal) error {
+	if appraisal.VehicleID <= 0 {
+		return fmt.Errorf("invalid vehicle_id: %w", ErrInvalidInput)
+	}
+	if appraisal.Amount <= 0 {
+		return fmt.Errorf("amount must be positive: %w", ErrInvalidInput)
+	}
+	if appraisal.Appraiser == "" {
+		return fmt.Errorf("appraiser is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(appraisal.Appraiser) > 200 {
+		return fmt.Errorf("appraiser must not exceed 200 characters: %w", ErrInvalidInput)
+	}
+
+	vehicle, err := s.repo.GetVehicle(ctx, appraisal.VehicleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("unknown vehicle_id %d: %w", appraisal.VehicleID, ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to get vehicle: %w", err)
+	}
+	if vehicle.Condition != domain.VehicleConditionUsed {
+		return fmt.Errorf("trade-in vehicle %d must be used: %w", vehicle.VehicleID, ErrInvalidInput)
+	}
+	return nil
+}
+
+// validateOrderAppraisal checks the appraisal of an order: only trade-in orders reference an appraisal,
+// and a trade-in order is placed from an approved appraisal of its deal for the appraised amount.
+// An order without a vehicle takes the vehicle of the appraisal. Trade-in orders placed before
+// appraisals were introduced (legacy) may keep no appraisal.
+func (s *Service) validateOrderAppraisal(ctx context.Context, order *domain.Order, legacy bool) error {
+	if order.AppraisalID == nil {
+		if order.OrderTypeID == domain.OrderTypeTradeIn && !legacy {
+			return fmt.Errorf("trade-in order requires an approved appraisal_id: %w", ErrInvalidInput)
+		}
+		return nil
+	}
+	if order.OrderTypeID != domain.OrderTypeTradeIn {
+		return fmt.Errorf("order type %d does not reference an appraisal: %w", order.OrderTypeID, ErrInvalidInput)
+	}
+
+	appraisal, err := s.repo.GetAppraisal(ctx, *order.AppraisalID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("unknown appraisal_id %d: %w", *order.AppraisalID, ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to get appraisal: %w", err)
+	}
+	if appraisal.Status != domain.AppraisalApproved {
+		return fmt.Errorf("appraisal %d is %s, orders can only be placed from approved appraisals: %w",
+			appraisal.AppraisalID, appraisal.Status, ErrInvalidInput)
+	}
+	if appraisal.DealID != order.DealID {
+		return fmt.Errorf("appraisal %d belongs to deal %d: %w", appraisal.AppraisalID, appraisal.DealID, ErrInvalidInput)
+	}
+	if order.Amount != appraisal.Amount {
+		return fmt.Errorf("amount %s differs from appraised amount %s of appraisal %d: %w",
+			order.Amount, appraisal.Amount, appraisal.AppraisalID, ErrInvalidInput)
+	}
+	if order.VehicleID == nil {
+		order.VehicleID = &appraisal.VehicleID
+	} else if *order.VehicleID != appraisal.VehicleID {
+		return fmt.Errorf("vehicle_id %d differs from vehicle %d of appraisal %d: %w",
+			*order.VehicleID, appraisal.VehicleID, appraisal.AppraisalID, ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"cliring/internal/domain"
+)
+
+// priceOrder validates the discount and VAT rate of an order and computes its net, VAT and gross amounts.
+// The VAT is charged on the discounted amount and rounded to kopecks.
+func priceOrder(order *domain.Order) error {
+	if order.Discount < 0 {
+		return fmt.Errorf("discount must not be negative: %w", ErrInvalidInput)
+	}
+	if order.Discount > order.Amount {
+		return fmt.Errorf("discount must not exceed amount: %w", ErrInvalidInput)
+	}
+	if order.VATRate < 0 || order.VATRate > 100 {
+		return fmt.Errorf("vat_rate must be between 0 and 100: %w", ErrInvalidInput)
+	}
+	// Ставка хранится как numeric(5,2)
+	if math.Round(order.VATRate*100) != order.VATRate*100 {
+		return fmt.Errorf("vat_rate has more than two decimal places: %w", ErrInvalidInput)
+	}
+
+	order.NetAmount = order.Amount - order.Discount
+	order.VATAmount = order.NetAmount.Percent(order.VATRate)
+	order.GrossAmount = order.NetAmount + order.VATAmount
+	return nil
+}
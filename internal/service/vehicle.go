@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// vinPattern matches a vehicle identification number: 17 characters without I, O and Q.
+var vinPattern = regexp.MustCompile(`^[A-HJ-NPR-Z0-9]{17}$`)
+
+// ListVehicles returns the vehicles ordered by vehicle_id, optionally only those in the condition.
+func (s *Service) ListVehicles(ctx context.Context, condition string) ([]*domain.Vehicle, error) {
+	if condition != "" && !validVehicleCondition(condition) {
+		return nil, fmt.Errorf("condition must be one of %s, %s: %w",
+			domain.VehicleConditionNew, domain.VehicleConditionUsed, ErrInvalidInput)
+	}
+
+	vehicles, err := s.repo.ListVehicles(ctx, condition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vehicles: %w", err)
+	}
+	if vehicles == nil {
+		vehicles = []*domain.Vehicle{}
+	}
+
+	return vehicles, nil
+}
+
+// GetVehicle returns a vehicle.
+func (s *Service) GetVehicle(ctx context.Context, vehicleID int) (*domain.Vehicle, error) {
+	if vehicleID <= 0 {
+		return nil, fmt.Errorf("invalid vehicle_id: %w", ErrInvalidInput)
+	}
+
+	vehicle, err := s.repo.GetVehicle(ctx, vehicleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("vehicle not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get vehicle: %w", err)
+	}
+
+	return vehicle, nil
+}
+
+// CreateVehicle adds a vehicle that purchase and trade-in orders can reference.
+func (s *Service) CreateVehicle(ctx context.Context, req domain.Vehicle) (*domain.Vehicle, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	req.VIN = strings.ToUpper(strings.TrimSpace(req.VIN))
+	if err := validateVehicle(req); err != nil {
+		return nil, err
+	}
+
+	vehicle, err := s.repo.CreateVehicle(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, fmt.Errorf("vehicle %d already exists: %w", req.VehicleID, ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to create vehicle: %w", err)
+	}
+
+	return vehicle, nil
+}
+
+// UpdateVehicle replaces the details of a vehicle. The amounts of orders that reference it are not changed.
+func (s *Service) UpdateVehicle(ctx context.Context, req domain.Vehicle) (*domain.Vehicle, error) {
+	if err := denyClientWrite(ctx); err != nil {
+		return nil, err
+	}
+	req.VIN = strings.ToUpper(strings.TrimSpace(req.VIN))
+	if err := validateVehicle(req); err != nil {
+		return nil, err
+	}
+
+	vehicle, err := s.repo.UpdateVehicle(ctx, req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("vehicle not found: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to update vehicle: %w", err)
+	}
+
+	return vehicle, nil
+}
+
+// DeleteVehicle removes a vehicle that no order references.
+func (s *Service) DeleteVehicle(ctx context.Context, vehicleID int) error {
+	if err := denyClientWrite(ctx); err != nil {
+		return err
+	}
+	if vehicleID <= 0 {
+		return fmt.Errorf("invalid vehicle_id: %w", ErrInvalidInput)
+	}
+
+	if err := s.repo.DeleteVehicle(ctx, vehicleID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return fmt.Errorf("vehicle not found: %w", ErrNotFound)
+		case errors.Is(err, repository.ErrConflict):
+			return fmt.Errorf("vehicle %d is used by orders: %w", vehicleID, ErrConflict)
+		}
+		return fmt.Errorf("failed to delete vehicle: %w", err)
+	}
+
+	return nil
+}
+
+// validateVehicle checks a vehicle record: a positive ID, a well-formed VIN, a model that fits the column,
+// a positive price and a known condition.
+func validateVehicle(vehicle domain.Vehicle) error {
+	if vehicle.VehicleID <= 0 {
+		return fmt.Errorf("invalid vehicle_id: %w", ErrInvalidInput)
+	}
+	if !vinPattern.MatchString(vehicle.VIN) {
+		return fmt.Errorf("vin must be 17 letters and digits without I, O and Q: %w", ErrInvalidInput)
+	}
+	model := strings.TrimSpace(vehicle.Model)
+	if model == "" {
+		return fmt.Errorf("model is required: %w", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(model) > 200 {
+		return fmt.Errorf("model must not exceed 200 characters: %w", ErrInvalidInput)
+	}
+	if vehicle.Price <= 0 {
+		return fmt.Errorf("price must be positive: %w", ErrInvalidInput)
+	}
+	if !validVehicleCondition(vehicle.Condition) {
+		return fmt.Errorf("condition must be one of %s, %s: %w",
+			domain.VehicleConditionNew, domain.VehicleConditionUsed, ErrInvalidInput)
+	}
+	return nil
+}
+
+// validVehicleCondition reports whether the condition is a known vehicle condition.
+func validVehicleCondition(condition string) bool {
+	return condition == domain.VehicleConditionNew || condition == domain.VehicleConditionUsed
+}
+
+// validateOrderVehicle checks the vehicle of an order: only purchase and trade-in orders reference a vehicle,
+// and the vehicle taken in trade-in is a used one.
+func (s *Service) validateOrderVehicle(ctx context.Context, order *domain.Order) error {
+	if order.VehicleID == nil {
+		return nil
+	}
+	if order.OrderTypeID != domain.OrderTypePurchase && order.OrderTypeID != domain.OrderTypeTradeIn {
+		return fmt.Errorf("order type %d does not reference a vehicle: %w", order.OrderTypeID, ErrInvalidInput)
+	}
+
+	vehicle, err := s.repo.GetVehicle(ctx, *order.VehicleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("unknown vehicle_id %d: %w", *order.VehicleID, ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to get vehicle: %w", err)
+	}
+	if order.OrderTypeID == domain.OrderTypeTradeIn && vehicle.Condition != domain.VehicleConditionUsed {
+		return fmt.Errorf("trade-in vehicle %d must be used: %w", vehicle.VehicleID, ErrInvalidInput)
+	}
+	return nil
+}
+
+// dealVehicles summarizes the vehicles the orders reference, ordered by vehicle_id.
+func (s *Service) dealVehicles(ctx context.Context, orders []*domain.Order) ([]*domain.DealVehicle, error) {
+	orderIDs := map[int][]int{}
+	var vehicleIDs []int
+	for _, order := range orders {
+		if order.VehicleID == nil {
+			continue
+		}
+		if _, ok := orderIDs[*order.VehicleID]; !ok {
+			vehicleIDs = append(vehicleIDs, *order.VehicleID)
+		}
+		orderIDs[*order.VehicleID] = append(orderIDs[*order.VehicleID], order.OrderID)
+	}
+	summaries := []*domain.DealVehicle{}
+	if len(vehicleIDs) == 0 {
+		return summaries, nil
+	}
+
+	vehicles, err := s.repo.ListVehiclesByIDs(ctx, vehicleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vehicles: %w", err)
+	}
+	for _, vehicle := range vehicles {
+		summaries = append(summaries, &domain.DealVehicle{
+			VehicleID: vehicle.VehicleID,
+			VIN:       vehicle.VIN,
+			Model:     vehicle.Model,
+			Price:     vehicle.Price,
+			Condition: vehicle.Condition,
+			OrderIDs:  orderIDs[vehicle.VehicleID],
+		})
+	}
+	return summaries, nil
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"cliring/internal/domain"
+	"cliring/internal/repository"
+)
+
+// webhookDispatchBatch limits the number of deliveries sent by one dispatch.
+const webhookDispatchBatch = 100
+
+// Bounds of the webhook URL and secret, the sizes of their columns.
+const (
+	maxWebhookURLLength    = 2000
+	minWebhookSecretLength = 16
+	maxWebhookSecretLength = 100
+)
+
+// webhookEvents are the events a subscription can subscribe to.
+var webhookEvents = []string{
+	domain.EventSettlementCreated,
+	domain.EventSettlementExecuted,
+	domain.EventSettlementCancelled,
+}
+
+// WebhookSender delivers a signed event to a subscriber.
+type WebhookSender interface {
+	Send(ctx context.Context, url, secret, event string, deliveryID int64, body []byte) error
+}
+
+// WithWebhookSender sets the sender of settlement events to webhook subscribers. Deliveries are
+// reported in the integrations status.
+func (s *Service) WithWebhookSender(sender WebhookSender) *Service {
+	s.webhooks = sender
+	s.integrations.Register(domain.IntegrationWebhooks)
+	return s
+}
+
+// CreateWebhookSubscription subscribes a URL to settlement events. A signing secret is generated
+// unless one is given; it is returned only in the response to this call.
+func (s *Service) CreateWebhookSubscription(ctx context.Context, req domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	if len(req.URL) > maxWebhookURLLength {
+		return nil, fmt.Errorf("url must not exceed %d characters: %w", maxWebhookURLLength, ErrInvalidInput)
+	}
+	parsed, err := url.Parse(req.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("url must be an absolute http or https URL: %w", ErrInvalidInput)
+	}
+	if len(req.Events) == 0 {
+		return nil, fmt.Errorf("events are required: %w", ErrInvalidInput)
+	}
+	var events []string
+	for _, event := range req.Events {
+		if !slices.Contains(webhookEvents, event) {
+			return nil, fmt.Errorf("unknown event %q: %w", event, ErrInvalidInput)
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = hex.EncodeToString(key)
+	}
+	if len(secret) < minWebhookSecretLength || len(secret) > maxWebhookSecretLength {
+		return nil, fmt.Errorf("secret must be %d to %d characters long: %w", minWebhookSecretLength, maxWebhookSecretLength, ErrInvalidInput)
+	}
+
+	subscription, err := s.repo.CreateWebhookSubscription(ctx, &domain.WebhookSubscription{
+		URL:    req.URL,
+		Events: events,
+		Secret: secret,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// ListWebhookSubscriptions retrieves all webhook subscriptions without their secrets.
+func (s *Service) ListWebhookSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	subscriptions, err := s.repo.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if subscriptions == nil {
+		subscriptions = []*domain.WebhookSubscription{}
+	}
+	return subscriptions, nil
+}
+
+// DeleteWebhookSubscription deletes a webhook subscription; its undelivered events are dropped.
+func (s *Service) DeleteWebhookSubscription(ctx context.Context, subscriptionID int) error {
+	if subscriptionID <= 0 {
+		return fmt.Errorf("invalid subscription_id: %w", ErrInvalidInput)
+	}
+	if err := s.repo.DeleteWebhookSubscription(ctx, subscriptionID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("webhook subscription not found: %w", ErrNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+// publishSettlementEvent queues the event of every settlement for the subscribers. The settlements
+// are already stored, so a failure to queue is logged and does not fail the operation.
+func (s *Service) publishSettlementEvent(ctx context.Context, event string, settlements ...*domain.MonetarySettlement) {
+	now := time.Now()
+	for _, settlement := range settlements {
+		payload, err := json.Marshal(domain.WebhookEvent{Event: event, OccurredAt: now, Settlement: settlement})
+		if err != nil {
+			logrus.Errorf("failed to encode %s event of settlement %d: %s", event, settlement.MonetarySettlementID, err)
+			continue
+		}
+		if err := s.repo.EnqueueWebhookEvent(ctx, event, payload); err != nil {
+			logrus.Errorf("failed to queue %s event of settlement %d: %s", event, settlement.MonetarySettlementID, err)
+		}
+	}
+}
+
+// DispatchWebhooks sends the queued events whose attempt is due and returns the number delivered.
+// A failed delivery is retried with a doubling backoff until the attempts are exhausted.
+func (s *Service) DispatchWebhooks(ctx context.Context) (int, error) {
+	if s.webhooks == nil {
+		return 0, nil
+	}
+
+	deliveries, err := s.repo.ListDueWebhookDeliveries(ctx, time.Now(), webhookDispatchBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	var delivered int
+	for _, delivery := range deliveries {
+		sendErr := s.webhooks.Send(ctx, delivery.URL, delivery.Secret, delivery.Event, delivery.DeliveryID, delivery.Payload)
+		s.integrations.Record(domain.IntegrationWebhooks, sendErr)
+		if sendErr == nil {
+			if err := s.repo.MarkWebhookDelivered(ctx, delivery.DeliveryID); err != nil {
+				return delivered, err
+			}
+			delivered++
+			continue
+		}
+
+		var nextAttemptAt *time.Time
+		if attempts := delivery.Attempts + 1; attempts < s.cfg.Webhook.MaxAttempts {
+			next := time.Now().Add(s.cfg.Webhook.RetryBackoff << (attempts - 1))
+			nextAttemptAt = &next
+		}
+		if err := s.repo.MarkWebhookAttemptFailed(ctx, delivery.DeliveryID, sendErr.Error(), nextAttemptAt); err != nil {
+			return delivered, err
+		}
+	}
+
+	return delivered, nil
+}
+
+// RunWebhookDispatcher periodically sends queued settlement events until ctx is cancelled.
+func (s *Service) RunWebhookDispatcher(ctx context.Context) {
+	interval := s.cfg.Webhook.DispatchInterval
+	if interval <= 0 || s.webhooks == nil {
+		logrus.Info("Webhook dispatcher disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DispatchWebhooks(ctx); err != nil {
+				logrus.Error("Webhook dispatch failed: ", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"cliring/internal/domain"
+)
+
+// camt053Parser parses ISO 20022 BankToCustomerStatement (camt.053) XML. Elements are matched by local name,
+// so the schema versions 02-08 are read alike.
+type camt053Parser struct{}
+
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	Entries []camtEntry `xml:"Ntry"`
+}
+
+type camtEntry struct {
+	Reference    string            `xml:"NtryRef"`
+	Amount       camtAmount        `xml:"Amt"`
+	CreditDebit  string            `xml:"CdtDbtInd"`
+	Reversal     bool              `xml:"RvslInd"`
+	Status       camtStatus        `xml:"Sts"`
+	BookingDate  camtDate          `xml:"BookgDt"`
+	ValueDate    camtDate          `xml:"ValDt"`
+	ServicerRef  string            `xml:"AcctSvcrRef"`
+	Transactions []camtTransaction `xml:"NtryDtls>TxDtls"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// camtStatus is the entry status: a code in the element text before version 08, in the Cd child since.
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtTransaction struct {
+	Amount        camtAmount `xml:"Amt"`
+	AmountDetails camtAmount `xml:"AmtDtls>TxAmt>Amt"`
+	EndToEndID    string     `xml:"Refs>EndToEndId"`
+	ServicerRef   string     `xml:"Refs>AcctSvcrRef"`
+	DebtorName    string     `xml:"RltdPties>Dbtr>Nm"`
+	// DebtorPartyName is the debtor name since version 08.
+	DebtorPartyName string   `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Unstructured    []string `xml:"RmtInf>Ustrd"`
+}
+
+// Parse returns a line per transaction of the booked credit entries; an entry without transaction details is one line.
+func (camt053Parser) Parse(data []byte) ([]*domain.BankStatementLine, error) {
+	var document camtDocument
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	var lines []*domain.BankStatementLine
+	for _, statement := range document.Statements {
+		for i, entry := range statement.Entries {
+			if entry.CreditDebit != "CRDT" || entry.Reversal || !entry.Status.booked() {
+				continue
+			}
+			valueDate := entry.ValueDate.value()
+			if valueDate == "" {
+				valueDate = entry.BookingDate.value()
+			}
+
+			transactions := entry.Transactions
+			if len(transactions) == 0 {
+				transactions = []camtTransaction{{}}
+			}
+			for _, transaction := range transactions {
+				amount := entry.Amount
+				// Сумма пакетной проводки делится по суммам ее операций
+				if len(entry.Transactions) > 1 {
+					amount = transaction.amount()
+				}
+				money, err := domain.ParseMoney(amount.Value)
+				if err != nil {
+					return nil, fmt.Errorf("%w: entry %d: %v", ErrMalformed, i+1, err)
+				}
+
+				name := transaction.DebtorName
+				if name == "" {
+					name = transaction.DebtorPartyName
+				}
+				lines = append(lines, &domain.BankStatementLine{
+					PaymentReference: firstNonEmpty(transaction.EndToEndID, transaction.ServicerRef, entry.ServicerRef, entry.Reference),
+					Amount:           money,
+					CurrencyCode:     strings.ToUpper(amount.Currency),
+					ValueDate:        valueDate,
+					PayerName:        strings.TrimSpace(name),
+					Purpose:          strings.TrimSpace(strings.Join(transaction.Unstructured, " ")),
+				})
+			}
+		}
+	}
+	return lines, nil
+}
+
+// booked reports whether the entry is booked; pending and informational entries are not payments yet.
+func (s camtStatus) booked() bool {
+	status := strings.TrimSpace(s.Code)
+	if status == "" {
+		status = strings.TrimSpace(s.Value)
+	}
+	return status == "BOOK"
+}
+
+// value returns the date in domain.DateLayout.
+func (d camtDate) value() string {
+	if d.Date != "" {
+		return strings.TrimSpace(d.Date)
+	}
+	if len(d.DateTime) >= len(domain.DateLayout) {
+		return d.DateTime[:len(domain.DateLayout)]
+	}
+	return ""
+}
+
+// amount returns the transaction amount, which is optional before version 08 and then given in the amount details.
+func (t camtTransaction) amount() camtAmount {
+	if t.Amount.Value != "" {
+		return t.Amount
+	}
+	return t.AmountDetails
+}
+
+// firstNonEmpty returns the first value that is not empty or the NOTPROVIDED placeholder of ISO 20022.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && value != "NOTPROVIDED" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package statement
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"cliring/internal/domain"
+)
+
+// mt940Parser parses SWIFT MT940 customer statements. The text must be in UTF-8 or its ASCII subset.
+type mt940Parser struct{}
+
+var (
+	// mt940FieldPattern matches the first line of a field, e.g. ":61:2505020502C150000,00NTRF5521//A1".
+	mt940FieldPattern = regexp.MustCompile(`^:(\d{2}[A-Z]?):(.*)$`)
+	// mt940BalancePattern matches an opening balance: mark, date, currency and amount.
+	mt940BalancePattern = regexp.MustCompile(`^[CD]\d{6}([A-Z]{3})`)
+	// mt940LinePattern matches a statement line: value date, optional entry date, mark, optional funds code,
+	// amount, transaction type, customer reference and optional bank reference.
+	mt940LinePattern = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d{0,2})([NFS][A-Z0-9]{3})(.*?)(?://(.*))?$`)
+)
+
+// mt940Field is a field of a statement with its continuation lines.
+type mt940Field struct {
+	tag   string
+	value string
+}
+
+// Parse returns the credit statement lines (:61:) with their information to account owner (:86:) as the purpose.
+func (mt940Parser) Parse(data []byte) ([]*domain.BankStatementLine, error) {
+	fields, err := mt940Fields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []*domain.BankStatementLine
+	var currency string
+	var last *domain.BankStatementLine
+	for _, field := range fields {
+		switch field.tag {
+		case "60F", "60M":
+			match := mt940BalancePattern.FindStringSubmatch(field.value)
+			if match == nil {
+				return nil, fmt.Errorf("%w: invalid opening balance %q", ErrMalformed, field.value)
+			}
+			currency = match[1]
+		case "61":
+			last = nil
+			firstLine, _, _ := strings.Cut(field.value, "\n")
+			match := mt940LinePattern.FindStringSubmatch(strings.TrimSpace(firstLine))
+			if match == nil {
+				return nil, fmt.Errorf("%w: invalid statement line %q", ErrMalformed, firstLine)
+			}
+			// Списания и сторно не являются входящими платежами
+			if match[3] != "C" {
+				continue
+			}
+			valueDate, err := time.Parse("060102", match[1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid value date %q", ErrMalformed, match[1])
+			}
+			amount, err := domain.ParseMoney(strings.Replace(match[5], ",", ".", 1))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+			}
+			if currency == "" {
+				return nil, fmt.Errorf("%w: statement line before the opening balance", ErrMalformed)
+			}
+
+			reference := strings.TrimSpace(match[7])
+			if reference == "" || reference == "NONREF" {
+				reference = strings.TrimSpace(match[8])
+			}
+			last = &domain.BankStatementLine{
+				PaymentReference: reference,
+				Amount:           amount,
+				CurrencyCode:     currency,
+				ValueDate:        valueDate.Format(domain.DateLayout),
+			}
+			lines = append(lines, last)
+		case "86":
+			if last != nil {
+				last.Purpose = strings.Join(strings.Fields(field.value), " ")
+			}
+			last = nil
+		case "62F", "62M":
+			last = nil
+		}
+	}
+	return lines, nil
+}
+
+// mt940Fields splits the statement text into fields. SWIFT block headers and the "-" end-of-message
+// lines are skipped, so several statements of one file are read in sequence.
+func mt940Fields(data []byte) ([]mt940Field, error) {
+	var fields []mt940Field
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r ")
+		if match := mt940FieldPattern.FindStringSubmatch(line); match != nil {
+			fields = append(fields, mt940Field{tag: match[1], value: match[2]})
+			continue
+		}
+		if line == "" || line == "-" || line == "-}" || strings.HasPrefix(line, "{") {
+			continue
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%w: unexpected line %q", ErrMalformed, line)
+		}
+		fields[len(fields)-1].value += "\n" + line
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return fields, nil
+}
@@ -0,0 +1,64 @@
+// Package statement parses bank statements in standard formats (ISO 20022 camt.053 and SWIFT MT940)
+// into bank statement lines for reconciliation. Only booked incoming payments are returned:
+// debits and reversals do not pay settlements.
+package statement
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"cliring/internal/domain"
+)
+
+// Supported statement formats.
+const (
+	FormatCAMT053 = "camt.053"
+	FormatMT940   = "mt940"
+)
+
+var (
+	// ErrUnknownFormat is returned for a format without a parser or content of an unrecognized format.
+	ErrUnknownFormat = errors.New("unknown statement format")
+	// ErrMalformed is returned when the content does not follow its format.
+	ErrMalformed = errors.New("malformed statement")
+)
+
+// Parser parses the statements of one format.
+type Parser interface {
+	Parse(data []byte) ([]*domain.BankStatementLine, error)
+}
+
+// parsers are the parsers indexed by format; a new format only needs a parser here.
+var parsers = map[string]Parser{
+	FormatCAMT053: camt053Parser{},
+	FormatMT940:   mt940Parser{},
+}
+
+// Parse parses a statement of the format into its incoming payments. An empty format is detected from the content.
+func Parse(format string, data []byte) ([]*domain.BankStatementLine, error) {
+	if format == "" {
+		format = Detect(data)
+	}
+	parser, ok := parsers[format]
+	if !ok {
+		return nil, ErrUnknownFormat
+	}
+	lines, err := parser.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", format, err)
+	}
+	return lines, nil
+}
+
+// Detect returns the format of the statement content, or "" if it is not recognized.
+func Detect(data []byte) string {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")) && bytes.Contains(trimmed, []byte("BkToCstmrStmt")):
+		return FormatCAMT053
+	case bytes.Contains(trimmed, []byte(":20:")) && bytes.Contains(trimmed, []byte(":61:")):
+		return FormatMT940
+	}
+	return ""
+}
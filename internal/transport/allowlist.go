@@ -0,0 +1,79 @@
+package transport
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ParseNetworks parses CIDRs such as 10.0.0.0/8; a bare address stands for a single host.
+func ParseNetworks(values []string) ([]netip.Prefix, error) {
+	networks := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid network %q: %w", value, err)
+			}
+			networks = append(networks, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		network, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", value, err)
+		}
+		networks = append(networks, network.Masked())
+	}
+	return networks, nil
+}
+
+// WithAdminNetworks limits administrative endpoints to the networks, e.g. office CIDRs.
+// Without networks the endpoints are reachable from any address.
+func (h *Handler) WithAdminNetworks(networks []netip.Prefix) *Handler {
+	h.adminNetworks = networks
+	return h
+}
+
+// WithTrustedProxies sets the proxies whose X-Forwarded-For and X-Real-IP headers are trusted
+// to carry the client address. Without them the address of the connection is the client address.
+func (h *Handler) WithTrustedProxies(proxies []netip.Prefix) *Handler {
+	h.trustedProxies = make([]string, 0, len(proxies))
+	for _, proxy := range proxies {
+		h.trustedProxies = append(h.trustedProxies, proxy.String())
+	}
+	return h
+}
+
+// adminNetworkMiddleware rejects requests to administrative endpoints from addresses outside
+// of the admin networks with 403. The client address honors the trusted proxies only.
+func (h *Handler) adminNetworkMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(h.adminNetworks) == 0 {
+			c.Next()
+			return
+		}
+
+		addr, err := netip.ParseAddr(c.ClientIP())
+		if err == nil {
+			addr = addr.Unmap()
+			for _, network := range h.adminNetworks {
+				if network.Contains(addr) {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		logrus.WithField("ip", c.ClientIP()).WithField("route", c.FullPath()).Warn("Administrative request from a disallowed address")
+		h.errorResponse(c, http.StatusForbidden, "ERR_FORBIDDEN", "Access from this address is not allowed")
+		c.Abort()
+	}
+}
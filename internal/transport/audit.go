@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"cliring/internal/domain"
+)
+
+const (
+	// requestIDHeader carries the ID of a request; it is generated if the client does not send one.
+	requestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 100
+	// impersonateClientHeader names the client an administrator works as when troubleshooting.
+	impersonateClientHeader = "X-Impersonate-Client"
+)
+
+// auditMiddleware records every state-changing request (POST, PUT, PATCH, DELETE) and every request made
+// on behalf of an impersonated client into the audit log after it is handled, whatever the outcome.
+// The entity is taken from the route and refined by the service, which also attaches the states before
+// and after the change.
+func (h *Handler) auditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+		c.Header(requestIDHeader, requestID)
+
+		ctx := c.Request.Context()
+		impersonated, isImpersonated := ctx.Value(domain.ImpersonationKey{}).(int)
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			if !isImpersonated {
+				c.Next()
+				return
+			}
+		}
+
+		actor, _ := ctx.Value(domain.ActorKey{}).(string)
+		entry := &domain.AuditEntry{
+			Actor:     actor,
+			Method:    c.Request.Method,
+			Route:     c.FullPath(),
+			Path:      c.Request.URL.Path,
+			Entity:    routeEntity(c.FullPath()),
+			IP:        c.ClientIP(),
+			RequestID: requestID,
+		}
+		if isImpersonated {
+			entry.ImpersonatedClientID = &impersonated
+		}
+		if len(c.Params) > 0 {
+			entry.EntityID = c.Params[0].Value
+		}
+		c.Request = c.Request.WithContext(context.WithValue(ctx, domain.AuditKey{}, entry))
+
+		c.Next()
+
+		entry.StatusCode = c.Writer.Status()
+		// Запись журнала не зависит от отмены запроса клиентом
+		if err := h.service.RecordAudit(context.WithoutCancel(ctx), entry); err != nil {
+			logrus.WithError(err).WithField("request_id", requestID).Error("Failed to record audit entry")
+		}
+	}
+}
+
+// routeEntity returns the entity a route changes: the first path segment after the API version
+// (and the admin prefix), e.g. monetary_settlements for /v1/monetary-settlements/:monetary_settlement_id.
+func routeEntity(route string) string {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	for i, segment := range segments {
+		if i == 0 || segment == "admin" || strings.HasPrefix(segment, ":") {
+			continue
+		}
+		return strings.ReplaceAll(segment, "-", "_")
+	}
+	return ""
+}
+
+// newRequestID generates a random request ID.
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,197 @@
+package transport
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"expvar"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"cliring/internal/domain"
+)
+
+// TokenParser validates a bearer token and returns it with jwt.MapClaims.
+type TokenParser interface {
+	Parse(ctx context.Context, tokenString string) (*jwt.Token, error)
+}
+
+// secretTokenParser validates HS256 tokens signed with a shared secret. It is used when no
+// OpenID Connect provider is configured, e.g. in local development.
+type secretTokenParser struct {
+	secret []byte
+	leeway time.Duration
+}
+
+// NewSecretTokenParser returns a TokenParser for HS256 tokens signed with the secret.
+func NewSecretTokenParser(secret string, leeway time.Duration) TokenParser {
+	return &secretTokenParser{secret: []byte(secret), leeway: leeway}
+}
+
+// Parse validates the signature and the exp/nbf/iat claims of the token.
+func (p *secretTokenParser) Parse(_ context.Context, tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) {
+		return p.secret, nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithLeeway(p.leeway))
+}
+
+// ClaimNames names the token claims the user attributes are taken from. Nested claims are separated
+// by dots, e.g. realm_access.roles.
+type ClaimNames struct {
+	Roles        string
+	ClientID     string
+	DealershipID string
+	Scope        string
+	Tenant       string
+}
+
+// DefaultClaimNames are the claims of tokens issued for the service.
+var DefaultClaimNames = ClaimNames{
+	Roles: "roles", ClientID: "client_id", DealershipID: "dealership_id", Scope: "scope", Tenant: "tenant_id",
+}
+
+// claimValue returns the claim at the dotted path.
+func claimValue(claims jwt.MapClaims, path string) (interface{}, bool) {
+	if path == "" {
+		return nil, false
+	}
+	var value interface{} = map[string]interface{}(claims)
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[name]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// claimRoles returns the roles claim: an array of strings or a space-separated string.
+func claimRoles(claims jwt.MapClaims, path string) ([]string, bool) {
+	value, ok := claimValue(claims, path)
+	if !ok {
+		return nil, false
+	}
+	switch value := value.(type) {
+	case []interface{}:
+		roles := make([]string, 0, len(value))
+		for _, role := range value {
+			if roleStr, ok := role.(string); ok {
+				roles = append(roles, roleStr)
+			}
+		}
+		return roles, true
+	case string:
+		return strings.Fields(value), true
+	}
+	return nil, false
+}
+
+// claimID parses a positive integer identifier claim. Providers often issue custom claims as strings,
+// so a decimal string is accepted as well.
+func claimID(value interface{}) (int, bool) {
+	switch value := value.(type) {
+	case float64:
+		if value <= 0 || value != float64(int(value)) {
+			return 0, false
+		}
+		return int(value), true
+	case string:
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return 0, false
+		}
+		return id, true
+	}
+	return 0, false
+}
+
+// Причины отклонения токенов, по которым ведутся счетчики.
+const (
+	rejectMissingHeader    = "missing_header"
+	rejectMalformed        = "malformed"
+	rejectInvalidSignature = "invalid_signature"
+	rejectExpired          = "expired"
+	rejectNotYetValid      = "not_yet_valid"
+	rejectInvalidClaims    = "invalid_claims"
+	rejectInvalid          = "invalid"
+	rejectRevoked          = "revoked"
+)
+
+// rejectedTokens counts requests rejected by authMiddleware, by reason.
+var rejectedTokens = expvar.NewMap("auth_rejected_tokens")
+
+// tokenRejection describes why a bearer token was rejected.
+type tokenRejection struct {
+	reason  string
+	code    string
+	message string
+}
+
+// classifyTokenError maps a jwt validation error to a rejection reason and an error code.
+// Time-based failures get their own codes so that clients with a skewed clock can tell them
+// apart from a forged or broken token.
+func classifyTokenError(err error) tokenRejection {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return tokenRejection{rejectMalformed, "ERR_TOKEN_MALFORMED", "Malformed JWT token"}
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return tokenRejection{rejectInvalidSignature, "ERR_TOKEN_SIGNATURE_INVALID", "Invalid JWT token signature"}
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return tokenRejection{rejectExpired, "ERR_TOKEN_EXPIRED",
+			"JWT token has expired; refresh the token or check the device clock"}
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return tokenRejection{rejectNotYetValid, "ERR_TOKEN_NOT_YET_VALID",
+			"JWT token is not valid yet; check the device clock"}
+	default:
+		return tokenRejection{rejectInvalid, "ERR_UNAUTHORIZED", "Invalid JWT token"}
+	}
+}
+
+// rejectToken counts the rejection and aborts the request with 401.
+func (h *Handler) rejectToken(c *gin.Context, rejection tokenRejection) {
+	rejectedTokens.Add(rejection.reason, 1)
+	h.errorResponse(c, http.StatusUnauthorized, rejection.code, rejection.message)
+	c.Abort()
+}
+
+// metrics returns the authentication counters.
+func (h *Handler) metrics(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(`{"auth_rejected_tokens":`+rejectedTokens.String()+`}`))
+}
+
+// paymentSourceKey is the gin context key of the bank authenticated by apiKeyMiddleware.
+const paymentSourceKey = "payment_source"
+
+// apiKeyMiddleware authenticates banks by the X-API-Key header. The bank the key belongs to is stored
+// as the payment source and as the actor of the audit trail.
+func (h *Handler) apiKeyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-API-Key")
+		var source string
+		// Сравнение за постоянное время, чтобы ключ нельзя было подобрать по времени ответа
+		for candidate, bank := range h.paymentAPIKeys {
+			if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+				source = bank
+			}
+		}
+		if key == "" || source == "" {
+			h.errorResponse(c, http.StatusUnauthorized, "ERR_UNAUTHORIZED", "Missing or invalid X-API-Key header")
+			c.Abort()
+			return
+		}
+
+		c.Set(paymentSourceKey, source)
+		ctx := context.WithValue(c.Request.Context(), domain.ActorKey{}, "api_key:"+source)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -3,50 +3,152 @@ package transport
 import (
 	"context"
 	"errors"
+	"mime"
 	"net/http"
+	"net/netip"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 
 	"cliring/internal/domain"
 	"cliring/internal/service"
+	"cliring/pkg/logging"
+	"cliring/pkg/ratelimit"
 )
 
 // Handler handles HTTP requests for the Cliring API.
 type Handler struct {
-	service *service.Service
+	service  *service.Service
+	redactor *logging.Redactor
+	tokens   TokenParser
+	claims   ClaimNames
+	limiter  ratelimit.Limiter
+	// paymentAPIKeys maps the API keys of banks sending payment confirmations to the bank names.
+	paymentAPIKeys map[string]string
+	// adminNetworks are the networks administrative endpoints are reachable from; empty means any.
+	adminNetworks  []netip.Prefix
+	trustedProxies []string
 }
 
 // NewHandler creates a new Handler instance.
 func NewHandler(service *service.Service) *Handler {
 	return &Handler{
 		service: service,
+		claims:  DefaultClaimNames,
 	}
 }
 
+// WithPayloadRedactor sets the redactor used to log request payload summaries.
+func (h *Handler) WithPayloadRedactor(redactor *logging.Redactor) *Handler {
+	h.redactor = redactor
+	return h
+}
+
+// WithTokenParser sets the parser validating bearer tokens, e.g. an OpenID Connect provider.
+func (h *Handler) WithTokenParser(tokens TokenParser) *Handler {
+	h.tokens = tokens
+	return h
+}
+
+// WithClaimNames sets the token claims the roles, the client and the dealership of the user are taken from.
+func (h *Handler) WithClaimNames(claims ClaimNames) *Handler {
+	h.claims = claims
+	return h
+}
+
+// WithRateLimiter enables per-user rate limiting of the /v1 API.
+func (h *Handler) WithRateLimiter(limiter ratelimit.Limiter) *Handler {
+	h.limiter = limiter
+	return h
+}
+
+// WithPaymentAPIKeys sets the API keys of banks sending payment confirmations, by bank name.
+func (h *Handler) WithPaymentAPIKeys(keys map[string]string) *Handler {
+	h.paymentAPIKeys = make(map[string]string, len(keys))
+	for bank, key := range keys {
+		h.paymentAPIKeys[key] = bank
+	}
+	return h
+}
+
 // InitRoutes initializes the Gin router with all API routes.
 func (h *Handler) InitRoutes() *gin.Engine {
 	router := gin.New()
+	// Адрес клиента берется из X-Forwarded-For и X-Real-IP только от доверенных прокси
+	if err := router.SetTrustedProxies(h.trustedProxies); err != nil {
+		logrus.Errorf("error set trusted proxies %s", err.Error())
+	}
 
 	// Middleware for logging and recovery
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 
+	// Пробы для оркестратора, без авторизации: живучесть не зависит от БД,
+	// готовность проверяет соединение с БД
+	router.GET("/healthz", h.healthz)
+	router.GET("/readyz", h.readyz)
+
 	// API version group
 	v1 := router.Group("/v1")
 	{
+		// Без БД сервис работает в деградированном режиме и отвечает 503
+		v1.Use(h.databaseMiddleware())
 		// Middleware for JWT authentication
 		v1.Use(h.authMiddleware())
+		// Токен с областями доступа вызывает только маршруты этих областей
+		v1.Use(h.scopeMiddleware())
+		if h.limiter != nil {
+			v1.Use(h.rateLimitMiddleware())
+		}
+		// Изменяющие запросы записываются в журнал аудита
+		v1.Use(h.auditMiddleware())
+
+		// Auth endpoints
+		// Отзывает токен запроса или, для роли admin, любой токен по jti до истечения его срока действия.
+		v1.POST("/auth/revoke", h.revokeToken)
 
 		// Deals endpoints
 		deals := v1.Group("/deals")
+		// Сделки других дилерских центров для пользователя дилерского центра не существуют
+		deals.Use(h.dealScopeMiddleware())
 		{
-			// Создает новую сделку.
-			deals.POST("", h.createDeal)
-			// Удаляет сделку по её ID.
-			deals.DELETE("/:deal_id", h.deleteDeal)
+			// Создает новую сделку (роль manager); повтор с тем же Idempotency-Key возвращает исходный ответ.
+			deals.POST("", h.requireRole(domain.RoleManager), h.idempotencyMiddleware(), h.createDeal)
+			// Частично обновляет сделку по её ID.
+			deals.PATCH("/:deal_id", h.denyClientWrite(), h.updateDeal)
+			// Переводит сделку в другой статус жизненного цикла.
+			deals.POST("/:deal_id/transition", h.denyClientWrite(), h.transitionDeal)
+			// Возвращает журнал изменений сделки.
+			deals.GET("/:deal_id/history", h.listDealHistory)
+			// Возвращает версии результата неттинга сделки, новые первыми.
+			deals.GET("/:deal_id/netting-snapshots", h.listNettingSnapshots)
+			// Возвращает неустойку, начисленную по просроченным расчетам сделки.
+			deals.GET("/:deal_id/penalties", h.listSettlementPenalties)
+			// Объясняет неттинг сделки: матрица обязательств, валовые суммы участников и вывод чистых позиций.
+			deals.GET("/:deal_id/netting/explain", h.explainNetting)
+			// Формирует акт взаимозачета сделки в PDF для подписания сторонами.
+			deals.GET("/:deal_id/netting/act", h.getNettingAct)
+			// Выгружает платежные файлы pain.001 исполненного прогона расчетов, по файлу на банк плательщика (ZIP).
+			deals.GET("/:deal_id/payment-files", h.exportPaymentFiles)
+			// Возвращает сделку вместе с заказами и рассчитанными денежными расчетами.
+			deals.GET("/:deal_id/full", h.getDealView)
+			// Прогнозирует итоговые чистые позиции с учетом неисполненных заказов и кредитных заявок.
+			deals.GET("/:deal_id/forecast", h.getDealForecast)
+			// Документы сделки (сканы доверенностей, договоры), хранятся в S3.
+			deals.POST("/:deal_id/documents", h.denyClientWrite(), h.uploadDealDocument)
+			deals.GET("/:deal_id/documents", h.listDealDocuments)
+			deals.GET("/:deal_id/documents/:document_id", h.downloadDealDocument)
+			deals.DELETE("/:deal_id/documents/:document_id", h.denyClientWrite(), h.deleteDealDocument)
+			// Планирует удаление сделки; до истечения окна отмены сделку можно восстановить.
+			deals.DELETE("/:deal_id", h.denyClientWrite(), h.deleteDeal)
+			// Отменяет запланированное удаление сделки.
+			deals.POST("/:deal_id/restore", h.denyClientWrite(), h.restoreDeal)
 		}
 
 		// Orders endpoints
@@ -54,60 +156,421 @@ func (h *Handler) InitRoutes() *gin.Engine {
 		{
 			// Возвращает постраничный список всех заказов для указанного клиента.
 			orders.GET("", h.listOrders)
-			// Создает новые заказы для указанного клиента.
-			orders.POST("", h.createOrder)
+			// Создает новые заказы для указанного клиента; повтор с тем же Idempotency-Key возвращает исходный ответ.
+			orders.POST("", h.denyClientWrite(), h.idempotencyMiddleware(), h.createOrder)
+			// Возвращает заказ клиента по его ID.
+			orders.GET("/:order_id", h.getOrder)
 			// Обновляет данные конкретного заказа по его ID.
-			orders.PUT("/:order_id", h.updateOrder)
+			orders.PUT("/:order_id", h.denyClientWrite(), h.updateOrder)
+			// Частично обновляет заказ: изменяются только переданные поля.
+			orders.PATCH("/:order_id", h.denyClientWrite(), h.patchOrder)
+			// Переводит заказ в другой статус (pending → executed|cancelled).
+			orders.POST("/:order_id/status", h.denyClientWrite(), h.transitionOrder)
+			// Удаляет ошибочно внесенный заказ клиента; исполненные заказы удалить нельзя.
+			orders.DELETE("/:order_id", h.denyClientWrite(), h.deleteOrder)
+			// Возвращает историю изменений заказа (прежние значения, автор и время правки).
+			orders.GET("/:order_id/history", h.listOrderHistory)
+			// Счет или квитанция заказа, хранится в S3; повторная загрузка заменяет файл.
+			orders.POST("/:order_id/invoice", h.denyClientWrite(), h.uploadOrderInvoice)
+			orders.GET("/:order_id/invoice", h.downloadOrderInvoice)
+		}
+
+		// Order types endpoints
+		orderTypes := v1.Group("/order-types")
+		{
+			// Справочник типов заказов с правилами неттинга (кто кому должен сумму заказа).
+			orderTypes.GET("", h.listOrderTypes)
+			orderTypes.GET("/:order_type_id", h.getOrderType)
+			// Справочник изменяет только роль admin из сетей администраторов.
+			orderTypes.POST("", h.adminNetworkMiddleware(), h.requireRole(domain.RoleAdmin), h.createOrderType)
+			orderTypes.PUT("/:order_type_id", h.adminNetworkMiddleware(), h.requireRole(domain.RoleAdmin), h.updateOrderType)
+			// Удаляет тип заказа; тип, на который ссылаются заказы, удалить нельзя.
+			orderTypes.DELETE("/:order_type_id", h.adminNetworkMiddleware(), h.requireRole(domain.RoleAdmin), h.deleteOrderType)
+		}
+
+		// Clients endpoints
+		clients := v1.Group("/clients")
+		{
+			// Клиенты сделок; отчеты и карточка сделки показывают ФИО вместо идентификатора.
+			clients.GET("", h.listClients)
+			clients.GET("/:client_id", h.getClient)
+			clients.POST("", h.denyClientWrite(), h.createClient)
+			// Изменяет персональные данные клиента; обезличенного клиента изменить нельзя.
+			clients.PUT("/:client_id", h.denyClientWrite(), h.updateClient)
+			// Удаляет клиента без сделок; клиента со сделками можно только обезличить.
+			clients.DELETE("/:client_id", h.denyClientWrite(), h.deleteClient)
+		}
+
+		// Dealerships endpoints
+		dealerships := v1.Group("/dealerships")
+		{
+			// Справочник дилерских центров; новые сделки принимаются только в активных центрах.
+			dealerships.GET("", h.listDealerships)
+			dealerships.GET("/:dealership_id", h.getDealership)
+			// Справочник ведется на уровне группы: пользователю дилерского центра изменения запрещены.
+			dealerships.POST("", h.denyClientWrite(), h.createDealership)
+			// Изменяет реквизиты дилерского центра или деактивирует его.
+			dealerships.PUT("/:dealership_id", h.denyClientWrite(), h.updateDealership)
+			// Удаляет дилерский центр без сделок, заказов и менеджеров.
+			dealerships.DELETE("/:dealership_id", h.denyClientWrite(), h.deleteDealership)
+		}
+
+		// Managers endpoints
+		managers := v1.Group("/managers")
+		{
+			// Менеджеры дилерских центров; новую сделку ведет активный менеджер ее дилерского центра.
+			managers.GET("", h.listManagers)
+			managers.GET("/:manager_id", h.getManager)
+			managers.POST("", h.denyClientWrite(), h.createManager)
+			// Изменяет данные менеджера, переводит его в другой дилерский центр или деактивирует.
+			managers.PUT("/:manager_id", h.denyClientWrite(), h.updateManager)
+			// Удаляет менеджера без сделок; менеджера со сделками можно только деактивировать.
+			managers.DELETE("/:manager_id", h.denyClientWrite(), h.deleteManager)
+		}
+
+		// Vehicles endpoints
+		vehicles := v1.Group("/vehicles")
+		{
+			// Автомобили сделок; заказы на покупку и трейд-ин ссылаются на конкретный автомобиль.
+			vehicles.GET("", h.listVehicles)
+			vehicles.GET("/:vehicle_id", h.getVehicle)
+			vehicles.POST("", h.denyClientWrite(), h.createVehicle)
+			// Изменяет данные автомобиля; суммы заказов не пересчитываются.
+			vehicles.PUT("/:vehicle_id", h.denyClientWrite(), h.updateVehicle)
+			// Удаляет автомобиль, на который не ссылаются заказы.
+			vehicles.DELETE("/:vehicle_id", h.denyClientWrite(), h.deleteVehicle)
+		}
+
+		// Credit contracts endpoints
+		creditContracts := v1.Group("/credit-contracts")
+		{
+			// Кредитные договоры сделки (deal_id обязателен); кредитный заказ без договора не участвует в неттинге.
+			creditContracts.GET("", h.listCreditContracts)
+			creditContracts.GET("/:credit_contract_id", h.getCreditContract)
+			creditContracts.POST("", h.denyClientWrite(), h.createCreditContract)
+			// Изменяет условия договора; банк договора, на который ссылаются заказы, не меняется.
+			creditContracts.PUT("/:credit_contract_id", h.denyClientWrite(), h.updateCreditContract)
+			// Удаляет договор, на который не ссылаются заказы.
+			creditContracts.DELETE("/:credit_contract_id", h.denyClientWrite(), h.deleteCreditContract)
+		}
+
+		// Trade-in appraisals endpoints
+		appraisals := v1.Group("/trade-in-appraisals")
+		{
+			// Оценки автомобилей в трейд-ин по сделке (deal_id обязателен).
+			appraisals.GET("", h.listAppraisals)
+			appraisals.GET("/:appraisal_id", h.getAppraisal)
+			appraisals.POST("", h.denyClientWrite(), h.createAppraisal)
+			// Изменяет оценку, пока по ней не принято решение.
+			appraisals.PUT("/:appraisal_id", h.denyClientWrite(), h.updateAppraisal)
+			// Заказ на трейд-ин оформляется только по одобренной оценке на оценочную сумму.
+			appraisals.POST("/:appraisal_id/approve", h.denyClientWrite(), h.approveAppraisal)
+			appraisals.POST("/:appraisal_id/reject", h.denyClientWrite(), h.rejectAppraisal)
+			// Удаляет оценку, по которой не оформлен заказ.
+			appraisals.DELETE("/:appraisal_id", h.denyClientWrite(), h.deleteAppraisal)
+		}
+
+		// Banks endpoints
+		banks := v1.Group("/banks")
+		{
+			// Справочник банков; в новых заказах допускаются только активные банки.
+			banks.GET("", h.listBanks)
+			banks.GET("/:bank_id", h.getBank)
+			banks.POST("", h.denyClientWrite(), h.createBank)
+			// Изменяет реквизиты банка или деактивирует его.
+			banks.PUT("/:bank_id", h.denyClientWrite(), h.updateBank)
+			// Удаляет банк; банк, на который ссылаются заказы или расчеты, удалить нельзя.
+			banks.DELETE("/:bank_id", h.denyClientWrite(), h.deleteBank)
 		}
 
 		// Monetary Settlements endpoints
 		monetarySettlements := v1.Group("/monetary-settlements")
+		monetarySettlements.Use(h.dealScopeMiddleware())
 		{
 			// Возвращает постраничный список всех денежных расчетов для указанной сделки.
 			monetarySettlements.GET("", h.listMonetarySettlements)
+			// Исполняет денежные расчеты по сделке и распределяет оплату по заказам; расчеты исполняет только роль finance
+			// из сетей администраторов.
+			monetarySettlements.POST("/execute", h.adminNetworkMiddleware(), h.requireRole(domain.RoleFinance), h.executeMonetarySettlements)
+			// Исполняет зафиксированный расчет по номеру платежного документа и формирует проводки.
+			monetarySettlements.POST("/:monetary_settlement_id/execute", h.adminNetworkMiddleware(), h.requireRole(domain.RoleFinance), h.executeMonetarySettlement)
+			// Отменяет неисполненный расчет с указанием причины; сделка помечается для пересчета.
+			monetarySettlements.POST("/:monetary_settlement_id/cancel", h.denyClientWrite(), h.cancelMonetarySettlement)
+			// Одобряет или отклоняет крупный расчет; доступно только роли approver, кроме зафиксировавшего расчет.
+			monetarySettlements.POST("/:monetary_settlement_id/approve", h.requireRole(domain.RoleApprover), h.approveMonetarySettlement)
+			monetarySettlements.POST("/:monetary_settlement_id/reject", h.requireRole(domain.RoleApprover), h.rejectMonetarySettlement)
+			// Регистрирует частичную оплату расчета; оплата, покрывшая остаток, исполняет расчет.
+			monetarySettlements.POST("/:monetary_settlement_id/payments", h.requireRole(domain.RoleFinance), h.recordSettlementPayment)
+			// Формирует платежные поручения по исполненному расчету для загрузки в интернет-банк.
+			monetarySettlements.GET("/:monetary_settlement_id/instructions", h.getPaymentInstructions)
+		}
+
+		// Participant balance endpoint
+		// Возвращает позицию участника (client:42, bank:1, dealership:12, insurer:3) по всем сделкам.
+		v1.GET("/participants/:participant_id/balance", h.getParticipantBalance)
+
+		// Clearing cycle endpoint
+		// Консолидированный неттинг всех открытых сделок клиента или дилерского центра.
+		v1.GET("/clearing", h.getClearingCycle)
+
+		// Clearing sessions endpoints
+		sessions := v1.Group("/clearing-sessions")
+		{
+			// Сессии группируют заказы, принятые в окне сессии; открытая сессия создается автоматически.
+			sessions.GET("", h.listClearingSessions)
+			sessions.GET("/:session_id", h.getClearingSession)
+			// Фиксирует расчеты сделок сессии; новые заказы уже попадают в следующую сессию.
+			sessions.POST("/:session_id/compute", h.denyClientWrite(), h.computeClearingSession)
+			// Закрывает сессию, все расчеты которой исполнены или отменены.
+			sessions.POST("/:session_id/close", h.denyClientWrite(), h.closeClearingSession)
+		}
+
+		// Bank statement endpoint
+		// Загружает входящие платежи банковской выписки и исполняет сопоставленные с ними расчеты.
+		v1.POST("/bank-statements", h.requireRole(domain.RoleFinance), h.importBankStatement)
+		// Загружает файл выписки в формате camt.053 или MT940; формат определяется по содержимому, если не указан.
+		v1.POST("/bank-statements/files", h.requireRole(domain.RoleFinance), h.importBankStatementFile)
+
+		// Webhook subscriptions endpoints
+		webhooks := v1.Group("/webhooks")
+		// Подписки хранят ключи подписи событий, поэтому управляются только из сетей администраторов
+		webhooks.Use(h.adminNetworkMiddleware())
+		{
+			// Подписывает внешнюю систему учета на события денежных расчетов; тело запроса подписывается ключом подписки.
+			webhooks.POST("", h.createWebhookSubscription)
+			webhooks.GET("", h.listWebhookSubscriptions)
+			webhooks.DELETE("/:subscription_id", h.deleteWebhookSubscription)
+		}
+
+		// Simulation endpoint
+		// Моделирует неттинг гипотетических заказов с переопределением правил, без сохранения.
+		v1.POST("/simulations", h.simulate)
+
+		// Change feed endpoint
+		// Возвращает упорядоченную ленту изменений сущности начиная с курсора.
+		v1.GET("/changes", h.listChanges)
+
+		// Курсы валют к валюте неттинга, действующие на дату (загружаются с сайта ЦБ РФ или задаются вручную).
+		v1.GET("/fx-rates", h.listEffectiveFXRates)
+
+		// Reports endpoints
+		reports := v1.Group("/reports")
+		{
+			// Возвращает межфилиальные расчеты между дилерскими центрами группы за период.
+			reports.GET("/inter-branch", h.interBranchReport)
+			// Возвращает итоги денежных расчетов сделок дилерского центра за период по статусам, банкам и типам заказов.
+			reports.GET("/settlements", h.settlementReport)
+		}
+
+		// Admin endpoints
+		admin := v1.Group("/admin")
+		// Административные операции доступны только роли admin из сетей администраторов
+		admin.Use(h.adminNetworkMiddleware(), h.requireRole(domain.RoleAdmin))
+		{
+			// Возвращает записи, которые будут очищены по сроку хранения в ближайшие дни.
+			admin.GET("/retention/upcoming", h.listUpcomingPurges)
+			// Запускает очистку мягко удалённых записей с истекшим сроком хранения.
+			admin.POST("/retention/purge", h.purgeExpired)
+			// Обезличивает персональные данные клиента (двухшаговое подтверждение токеном).
+			admin.POST("/clients/:client_id/anonymize", h.anonymizeClient)
+			// Производственный календарь: праздники и перенесенные рабочие дни.
+			admin.GET("/calendar", h.listCalendarDays)
+			admin.POST("/calendar", h.createCalendarDay)
+			admin.PUT("/calendar/:date", h.updateCalendarDay)
+			admin.DELETE("/calendar/:date", h.deleteCalendarDay)
+			// Курсы валют для неттинга сделок с заказами в разных валютах; каждый курс - новая версия.
+			admin.GET("/fx-rates", h.listFXRates)
+			admin.POST("/fx-rates", h.createFXRate)
+			// Правила неттинга с периодом действия: заменяют правило справочника типов заказов без релиза.
+			admin.GET("/netting-rules", h.listNettingRules)
+			admin.POST("/netting-rules", h.createNettingRule)
+			admin.PUT("/netting-rules/:rule_id", h.updateNettingRule)
+			admin.DELETE("/netting-rules/:rule_id", h.deleteNettingRule)
+			// Триггеры комиссий и договорной неустойки: при событии сделки создают заказ, который участвует в неттинге.
+			admin.GET("/obligation-triggers", h.listObligationTriggers)
+			admin.POST("/obligation-triggers", h.createObligationTrigger)
+			admin.PUT("/obligation-triggers/:trigger_id", h.updateObligationTrigger)
+			admin.DELETE("/obligation-triggers/:trigger_id", h.deleteObligationTrigger)
+			// Очередь антифрод-проверки: подозрительные заказы не участвуют в неттинге до решения.
+			admin.GET("/order-reviews", h.listOrderReviews)
+			admin.POST("/order-reviews/:review_id/approve", h.approveOrderReview)
+			admin.POST("/order-reviews/:review_id/reject", h.rejectOrderReview)
+			// Доступность внешних систем, время последнего успешного вызова и доля ошибок.
+			admin.GET("/integrations/status", h.integrationsStatus)
+			// Журнал аудита изменяющих запросов с фильтрами по пользователю, сущности и периоду, новые первыми.
+			admin.GET("/audit-log", h.listAuditLog)
+			// Арендаторы - группы дилерских центров с данными в отдельной схеме БД; создание
+			// регистрирует группу, создает схему и применяет к ней миграции. Только вне арендатора.
+			admin.POST("/tenants", h.provisionTenant)
+			admin.GET("/tenants", h.listTenants)
 		}
 	}
 
+	// Подтверждения платежей от банков: авторизация по API-ключу банка вместо JWT
+	router.POST("/v1/payments/confirmations", h.databaseMiddleware(), h.apiKeyMiddleware(), h.auditMiddleware(), h.confirmPayment)
+
+	// Документ OpenAPI строится по зарегистрированным маршрутам и отдается без авторизации,
+	// чтобы генераторы клиентов всегда соответствовали развернутому серверу
+	spec := buildOpenAPI(router.Routes())
+	router.GET(openAPIPath, func(c *gin.Context) {
+		c.JSON(http.StatusOK, spec)
+	})
+	// Счетчики отклоненных токенов по причинам, без авторизации
+	router.GET("/metrics", h.metrics)
+	// Метрики в формате Prometheus: длительность, число строк и ошибки запросов к БД
+	router.GET("/metrics/prometheus", gin.WrapH(promhttp.Handler()))
+
 	return router
 }
 
-// authMiddleware checks JWT token and client_id query parameter for /orders.
+// authMiddleware checks JWT token and client_id query parameter for /orders and /orders/{order_id}.
+// The client_id claim of a client token is put into the context of every route.
 func (h *Handler) authMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Check JWT token
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" || len(tokenString) < 7 || tokenString[:7] != "Bearer " {
-			h.errorResponse(c, http.StatusUnauthorized, "ERR_UNAUTHORIZED", "Missing or invalid Authorization header")
-			c.Abort()
+			h.rejectToken(c, tokenRejection{rejectMissingHeader, "ERR_UNAUTHORIZED", "Missing or invalid Authorization header"})
 			return
 		}
 
-		token, err := jwt.Parse(tokenString[7:], func(token *jwt.Token) (interface{}, error) {
-			// Replace with your JWT secret key retrieval logic
-			return []byte("your-secret-key"), nil
-		})
-		if err != nil || !token.Valid {
-			h.errorResponse(c, http.StatusUnauthorized, "ERR_UNAUTHORIZED", "Invalid JWT token")
-			c.Abort()
+		if h.tokens == nil {
+			h.rejectToken(c, tokenRejection{rejectInvalid, "ERR_UNAUTHORIZED", "Token validation is not configured"})
+			return
+		}
+		token, err := h.tokens.Parse(c.Request.Context(), tokenString[7:])
+		if err != nil {
+			h.rejectToken(c, classifyTokenError(err))
+			return
+		}
+		if !token.Valid {
+			h.rejectToken(c, tokenRejection{rejectInvalid, "ERR_UNAUTHORIZED", "Invalid JWT token"})
 			return
 		}
 
 		// Extract client_id from token claims
-		_, ok := token.Claims.(jwt.MapClaims)
+		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			h.errorResponse(c, http.StatusUnauthorized, "ERR_UNAUTHORIZED", "Invalid token claims")
-			c.Abort()
+			h.rejectToken(c, tokenRejection{rejectInvalidClaims, "ERR_UNAUTHORIZED", "Invalid token claims"})
 			return
 		}
+
+		// Группа дилерских центров (claim tenant_id) работает только со своей схемой БД,
+		// поэтому арендатор попадает в контекст до первого запроса к БД
+		if claimTenant, ok := claimValue(claims, h.claims.Tenant); ok {
+			tenantID, valid := claimTenant.(string)
+			if !valid || tenantID == "" {
+				h.rejectToken(c, tokenRejection{rejectInvalidClaims, "ERR_UNAUTHORIZED", "Invalid tenant_id in token"})
+				return
+			}
+			exists, err := h.service.TenantExists(c.Request.Context(), tenantID)
+			if err != nil {
+				logrus.WithError(err).Error("Failed to check tenant")
+				h.errorResponse(c, http.StatusInternalServerError, "ERR_INTERNAL", "Failed to validate token")
+				c.Abort()
+				return
+			}
+			if !exists {
+				h.errorResponse(c, http.StatusForbidden, "ERR_UNKNOWN_TENANT", "Tenant "+tenantID+" is not provisioned")
+				c.Abort()
+				return
+			}
+			ctx := context.WithValue(c.Request.Context(), domain.TenantKey{}, tenantID)
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		// Отозванный токен отклоняется до истечения срока действия
+		tokenInfo := &domain.TokenInfo{}
+		if jti, ok := claims["jti"].(string); ok && jti != "" {
+			revoked, err := h.service.IsTokenRevoked(c.Request.Context(), jti)
+			if err != nil {
+				logrus.WithError(err).Error("Failed to check token revocation")
+				h.errorResponse(c, http.StatusInternalServerError, "ERR_INTERNAL", "Failed to validate token")
+				c.Abort()
+				return
+			}
+			if revoked {
+				h.rejectToken(c, tokenRejection{rejectRevoked, "ERR_TOKEN_REVOKED", "JWT token has been revoked"})
+				return
+			}
+			tokenInfo.ID = jti
+		}
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			tokenInfo.ExpiresAt = &exp.Time
+		}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), domain.TokenKey{}, tokenInfo))
+
+		// Add actor (token subject) to context for the audit trail
+		if subject, err := claims.GetSubject(); err == nil && subject != "" {
+			ctx := context.WithValue(c.Request.Context(), domain.ActorKey{}, subject)
+			c.Request = c.Request.WithContext(ctx)
+		}
+		// Роли пользователя из claim ролей, например ["manager", "approver"]
+		if roles, ok := claimRoles(claims, h.claims.Roles); ok {
+			ctx := context.WithValue(c.Request.Context(), domain.RolesKey{}, roles)
+			c.Request = c.Request.WithContext(ctx)
+		}
+		// Области доступа токена интеграции (claim scope), например "orders:read deals:write"
+		if scopes, ok := claimRoles(claims, h.claims.Scope); ok {
+			c.Request = c.Request.WithContext(withScopes(c.Request.Context(), scopes))
+		}
+		// Пользователь дилерского центра (claim dealership_id) видит только сделки и отчеты своего центра
+		if claimDealership, ok := claimValue(claims, h.claims.DealershipID); ok {
+			dealershipID, valid := claimID(claimDealership)
+			if !valid {
+				h.rejectToken(c, tokenRejection{rejectInvalidClaims, "ERR_UNAUTHORIZED", "Invalid dealership_id in token"})
+				return
+			}
+			ctx := context.WithValue(c.Request.Context(), domain.DealershipKey{}, dealershipID)
+			c.Request = c.Request.WithContext(ctx)
+		}
+		// Клиент видит только свои сделки, заказы и расчеты: его идентификатор передается в claim client_id
+		// и попадает в контекст всех маршрутов
+		tokenClientID := 0
+		if claimClient, ok := claimValue(claims, h.claims.ClientID); ok {
+			clientID, valid := claimID(claimClient)
+			if !valid {
+				h.rejectToken(c, tokenRejection{rejectInvalidClaims, "ERR_UNAUTHORIZED", "Invalid client_id in token"})
+				return
+			}
+			tokenClientID = clientID
+			ctx := context.WithValue(c.Request.Context(), domain.ClientClaimKey{}, tokenClientID)
+			ctx = context.WithValue(ctx, domain.ClientIDKey{}, tokenClientID)
+			c.Request = c.Request.WithContext(ctx)
+		}
+		// Поддержка (роль admin) работает от имени клиента: запрос ограничен данными клиента и помечается в журнале аудита
+		if header := c.GetHeader(impersonateClientHeader); header != "" {
+			roles, _ := c.Request.Context().Value(domain.RolesKey{}).([]string)
+			if !slices.Contains(roles, domain.RoleAdmin) {
+				h.errorResponse(c, http.StatusForbidden, "ERR_FORBIDDEN", "Role admin is required to impersonate a client")
+				c.Abort()
+				return
+			}
+			clientID, err := strconv.Atoi(header)
+			if err != nil || clientID <= 0 {
+				h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid "+impersonateClientHeader+" header")
+				c.Abort()
+				return
+			}
+			tokenClientID = clientID
+			ctx := context.WithValue(c.Request.Context(), domain.ClientClaimKey{}, tokenClientID)
+			ctx = context.WithValue(ctx, domain.ClientIDKey{}, tokenClientID)
+			ctx = context.WithValue(ctx, domain.ImpersonationKey{}, tokenClientID)
+			c.Request = c.Request.WithContext(ctx)
+		}
 		if !ok {
 			h.errorResponse(c, http.StatusUnauthorized, "ERR_UNAUTHORIZED", "Missing client_id in token")
 			c.Abort()
 			return
 		}
 
-		// Check client_id query parameter only for /orders
-		if c.Request.URL.Path == "/v1/orders" {
+		// Check client_id query parameter only for /orders; a client token defines the client itself
+		if c.Request.URL.Path == "/v1/orders" || strings.HasPrefix(c.Request.URL.Path, "/v1/orders/") {
 			clientIDStr := c.Query("client_id")
+			if clientIDStr == "" && tokenClientID > 0 {
+				c.Next()
+				return
+			}
 			if clientIDStr == "" {
 				h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Missing client_id query parameter")
 				c.Abort()
@@ -119,6 +582,12 @@ func (h *Handler) authMiddleware() gin.HandlerFunc {
 				c.Abort()
 				return
 			}
+			// Параметр client_id не может подменить клиента из токена
+			if tokenClientID > 0 && clientID != tokenClientID {
+				h.errorResponse(c, http.StatusForbidden, "ERR_FORBIDDEN", "client_id does not match the token")
+				c.Abort()
+				return
+			}
 
 			// Add client_id to context
 			ctx := context.WithValue(c.Request.Context(), domain.ClientIDKey{}, clientID)
@@ -129,6 +598,97 @@ func (h *Handler) authMiddleware() gin.HandlerFunc {
 	}
 }
 
+// dealScopeMiddleware hides deals of other dealerships from users limited to a dealership:
+// requests to such a deal get 404 as if the deal did not exist. The deal is taken from the deal_id
+// path or query parameter.
+func (h *Handler) dealScopeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dealIDStr := c.Param("deal_id")
+		if dealIDStr == "" {
+			dealIDStr = c.Query("deal_id")
+		}
+		if dealIDStr == "" {
+			c.Next()
+			return
+		}
+		// Неверный deal_id отклоняет сам обработчик
+		dealID, err := strconv.Atoi(dealIDStr)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		if err := h.service.CheckDealAccess(c.Request.Context(), dealID); err != nil {
+			h.handleServiceError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// requireRole rejects requests of users without any of the roles in the token with 403.
+// Administrators pass every role check.
+func (h *Handler) requireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userRoles, _ := c.Request.Context().Value(domain.RolesKey{}).([]string)
+		if slices.Contains(userRoles, domain.RoleAdmin) {
+			c.Next()
+			return
+		}
+		for _, role := range roles {
+			if slices.Contains(userRoles, role) {
+				c.Next()
+				return
+			}
+		}
+
+		h.errorResponse(c, http.StatusForbidden, "ERR_FORBIDDEN", "Role "+strings.Join(roles, " or ")+" is required")
+		c.Abort()
+	}
+}
+
+// denyClientWrite rejects changing requests of clients without staff roles: clients only read
+// their deals, orders and settlements.
+func (h *Handler) denyClientWrite() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userRoles, _ := c.Request.Context().Value(domain.RolesKey{}).([]string)
+		staff := slices.ContainsFunc(userRoles, func(role string) bool {
+			return role == domain.RoleManager || role == domain.RoleFinance || role == domain.RoleAdmin
+		})
+		if slices.Contains(userRoles, domain.RoleClient) && !staff {
+			h.errorResponse(c, http.StatusForbidden, "ERR_FORBIDDEN", "Clients have read-only access")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitMiddleware rejects requests over the limit with 429. Requests are counted per token
+// subject, or per client address for tokens without a subject.
+func (h *Handler) rateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.ClientIP()
+		if actor, ok := c.Request.Context().Value(domain.ActorKey{}).(string); ok && actor != "" {
+			key = "sub:" + actor
+		}
+
+		allowed, err := h.limiter.Allow(c.Request.Context(), key)
+		if err != nil {
+			logrus.Error("Rate limiter error: ", err)
+		}
+		if err == nil && !allowed {
+			h.errorResponse(c, http.StatusTooManyRequests, "ERR_RATE_LIMITED", "Too many requests")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // errorResponse sends an error response in the standard format.
 func (h *Handler) errorResponse(c *gin.Context, status int, code, message string) {
 	c.JSON(status, domain.ErrorResponse{
@@ -139,20 +699,120 @@ func (h *Handler) errorResponse(c *gin.Context, status int, code, message string
 	})
 }
 
-// handleServiceError maps service errors to HTTP responses.
-func (h *Handler) handleServiceError(c *gin.Context, err error) {
-	logrus.Error("Service error: ", err)
+// logPayload logs a redacted summary of the request payload. Payloads are not logged without a redactor.
+func (h *Handler) logPayload(c *gin.Context, message string, payload any) {
+	fields := logrus.Fields{
+		"method": c.Request.Method,
+		"route":  c.FullPath(),
+	}
+	if h.redactor != nil {
+		fields["payload"] = h.redactor.Summary(payload)
+	}
+	logrus.WithFields(fields).Info(message)
+}
+
+// parseAsOf parses the optional as_of query parameter (RFC 3339). On error it writes
+// the response and returns false.
+func (h *Handler) parseAsOf(c *gin.Context) (*time.Time, bool) {
+	asOfStr := c.Query("as_of")
+	if asOfStr == "" {
+		return nil, true
+	}
+	asOf, err := time.Parse(time.RFC3339, asOfStr)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid as_of format, expected RFC 3339")
+		return nil, false
+	}
+	return &asOf, true
+}
+
+// constraintErrorDetail maps a database constraint violation to an HTTP status and error detail.
+func constraintErrorDetail(err *domain.ConstraintError) (int, domain.ErrorDetail) {
+	status, code, message := http.StatusBadRequest, "ERR_INVALID_INPUT", "Value violates a constraint"
+	switch err.Kind {
+	case domain.ConstraintUnique:
+		status, code, message = http.StatusConflict, "ERR_CONFLICT", "Resource already exists"
+	case domain.ConstraintForeignKey:
+		status, code, message = http.StatusBadRequest, "ERR_INVALID_REFERENCE", "Referenced resource does not exist"
+	case domain.ConstraintNotNull:
+		message = "Required value is missing"
+	}
+
+	return status, domain.ErrorDetail{Code: code, Message: message, Details: err}
+}
+
+// serviceErrorDetail maps a service error to an HTTP status and error detail.
+func serviceErrorDetail(err error) (int, domain.ErrorDetail) {
+	// Нарушения ограничений БД возвращаем с указанием колонки
+	var constraintErr *domain.ConstraintError
+	if errors.As(err, &constraintErr) {
+		return constraintErrorDetail(constraintErr)
+	}
 
 	switch {
 	case errors.Is(err, service.ErrInvalidInput):
-		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", err.Error())
+		return http.StatusBadRequest, domain.ErrorDetail{Code: "ERR_INVALID_INPUT", Message: err.Error()}
 	case errors.Is(err, service.ErrNotFound):
-		h.errorResponse(c, http.StatusNotFound, "ERR_NOT_FOUND", err.Error())
+		return http.StatusNotFound, domain.ErrorDetail{Code: "ERR_NOT_FOUND", Message: err.Error()}
 	case errors.Is(err, service.ErrUnauthorized):
-		h.errorResponse(c, http.StatusUnauthorized, "ERR_UNAUTHORIZED", err.Error())
+		return http.StatusUnauthorized, domain.ErrorDetail{Code: "ERR_UNAUTHORIZED", Message: err.Error()}
+	case errors.Is(err, service.ErrConflict):
+		return http.StatusConflict, domain.ErrorDetail{Code: "ERR_CONFLICT", Message: err.Error()}
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden, domain.ErrorDetail{Code: "ERR_FORBIDDEN", Message: err.Error()}
 	default:
-		h.errorResponse(c, http.StatusInternalServerError, "ERR_INTERNAL", "Internal server error")
+		return http.StatusInternalServerError, domain.ErrorDetail{Code: "ERR_INTERNAL", Message: "Internal server error"}
+	}
+}
+
+// handleServiceError maps service errors to HTTP responses.
+func (h *Handler) handleServiceError(c *gin.Context, err error) {
+	logrus.Error("Service error: ", err)
+
+	status, detail := serviceErrorDetail(err)
+	// Для пакетных запросов указываем номер элемента, из-за которого пакет отклонен
+	var batchErr *domain.BatchItemError
+	if errors.As(err, &batchErr) {
+		details := gin.H{"index": batchErr.Index}
+		if detail.Details != nil {
+			details["constraint"] = detail.Details
+		}
+		detail.Details = details
+	}
+
+	c.JSON(status, domain.ErrorResponse{Error: detail})
+}
+
+// databaseMiddleware rejects requests with 503 while the database is not open, so the API
+// stays up in degraded mode instead of failing on a missing connection.
+func (h *Handler) databaseMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.service.DatabaseAvailable() {
+			c.Header("Retry-After", "5")
+			h.errorResponse(c, http.StatusServiceUnavailable, "ERR_SERVICE_UNAVAILABLE", "Database is unavailable")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// healthz handles GET /healthz. The liveness probe does not touch the database: a database
+// outage makes the service not ready, not dead.
+func (h *Handler) healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, domain.Liveness{Status: domain.LivenessAlive})
+}
+
+// readyz handles GET /readyz.
+func (h *Handler) readyz(c *gin.Context) {
+	readiness, err := h.service.Readiness(c.Request.Context())
+	if err != nil {
+		logrus.Error("Readiness check failed: ", err)
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
 	}
+
+	c.JSON(http.StatusOK, readiness)
 }
 
 // createDeal handles POST /deals.
@@ -163,7 +823,7 @@ func (h *Handler) createDeal(c *gin.Context) {
 		return
 	}
 
-	logrus.Info("Create Deal: ", req)
+	h.logPayload(c, "Create Deal", req)
 	deal, err := h.service.CreateDeal(c.Request.Context(), req)
 	if err != nil {
 		h.handleServiceError(c, err)
@@ -173,117 +833,2485 @@ func (h *Handler) createDeal(c *gin.Context) {
 	c.JSON(http.StatusCreated, deal)
 }
 
-// deleteDeal handles DELETE /deals/{deal_id}.
-func (h *Handler) deleteDeal(c *gin.Context) {
+// updateDeal handles PATCH /deals/{deal_id}.
+func (h *Handler) updateDeal(c *gin.Context) {
 	dealID, err := strconv.Atoi(c.Param("deal_id"))
 	if err != nil {
 		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
 		return
 	}
 
-	if err := h.service.DeleteDeal(c.Request.Context(), dealID); err != nil {
-		h.handleServiceError(c, err)
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Сделка удалена"})
-}
-
-// listOrders handles GET /orders.
-func (h *Handler) listOrders(c *gin.Context) {
-	clientID, ok := c.Request.Context().Value(domain.ClientIDKey{}).(int)
-	if !ok {
-		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid client_id")
+	var req domain.DealUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
 		return
 	}
 
-	logrus.Info("List Orders Handler")
-	orders, total, err := h.service.ListOrders(c.Request.Context(), clientID)
+	h.logPayload(c, "Update Deal", req)
+	deal, err := h.service.UpdateDeal(c.Request.Context(), dealID, req)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"orders": orders,
-		"total":  total,
-	})
+	c.JSON(http.StatusOK, deal)
 }
 
-// createOrder handles POST /orders.
-func (h *Handler) createOrder(c *gin.Context) {
-	clientID, ok := c.Request.Context().Value(domain.ClientIDKey{}).(int)
-	if !ok {
-		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid client_id")
+// transitionDeal handles POST /deals/{deal_id}/transition.
+func (h *Handler) transitionDeal(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
 		return
 	}
 
-	var req []domain.OrderCreate
+	var req domain.DealTransition
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
 		return
 	}
 
-	logrus.Info("createOrder Handler")
-	orders, err := h.service.CreateOrders(c.Request.Context(), clientID, req)
+	deal, err := h.service.TransitionDeal(c.Request.Context(), dealID, req)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, orders)
+	c.JSON(http.StatusOK, deal)
 }
 
-// updateOrder handles PUT /orders/{order_id}.
-func (h *Handler) updateOrder(c *gin.Context) {
-	clientID, ok := c.Request.Context().Value(domain.ClientIDKey{}).(int)
-	if !ok {
-		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid client_id")
+// getDealView handles GET /deals/{deal_id}/full.
+func (h *Handler) getDealView(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
 		return
 	}
 
-	orderID, err := strconv.Atoi(c.Param("order_id"))
-	if err != nil {
-		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid order_id")
+	asOf, ok := h.parseAsOf(c)
+	if !ok {
 		return
 	}
 
-	var req domain.OrderCreate
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+	var sort domain.OrderSort
+	if err := c.ShouldBindQuery(&sort); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid sort parameters")
 		return
 	}
 
-	order, err := h.service.UpdateOrder(c.Request.Context(), clientID, orderID, req)
+	view, err := h.service.GetDealView(c.Request.Context(), dealID, asOf, sort)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, order)
+	c.JSON(http.StatusOK, view)
 }
 
-// listMonetarySettlements handles GET /monetary-settlements.
-func (h *Handler) listMonetarySettlements(c *gin.Context) {
-	dealIDStr := c.Query("deal_id")
-	if dealIDStr == "" {
-		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Missing deal_id query parameter")
-		return
+// getDealForecast handles GET /deals/{deal_id}/forecast.
+func (h *Handler) getDealForecast(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+
+	forecast, err := h.service.GetDealForecast(c.Request.Context(), dealID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, forecast)
+}
+
+// uploadDealDocument handles POST /deals/{deal_id}/documents.
+func (h *Handler) uploadDealDocument(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Missing file form field")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid file")
+		return
+	}
+	defer file.Close()
+
+	document, err := h.service.UploadDealDocument(c.Request.Context(), dealID, fileHeader.Filename,
+		fileHeader.Header.Get("Content-Type"), fileHeader.Size, file)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, document)
+}
+
+// listDealDocuments handles GET /deals/{deal_id}/documents.
+func (h *Handler) listDealDocuments(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+
+	documents, err := h.service.ListDealDocuments(c.Request.Context(), dealID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"documents": documents,
+	})
+}
+
+// downloadDealDocument handles GET /deals/{deal_id}/documents/{document_id}.
+func (h *Handler) downloadDealDocument(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+	documentID, err := strconv.Atoi(c.Param("document_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid document_id")
+		return
+	}
+
+	document, content, err := h.service.DownloadDealDocument(c.Request.Context(), dealID, documentID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, document.SizeBytes, document.ContentType, content, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": document.FileName}),
+	})
+}
+
+// deleteDealDocument handles DELETE /deals/{deal_id}/documents/{document_id}.
+func (h *Handler) deleteDealDocument(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+	documentID, err := strconv.Atoi(c.Param("document_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid document_id")
+		return
+	}
+
+	if err := h.service.DeleteDealDocument(c.Request.Context(), dealID, documentID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Документ удален"})
+}
+
+// listDealHistory handles GET /deals/{deal_id}/history.
+func (h *Handler) listDealHistory(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+
+	history, err := h.service.ListDealHistory(c.Request.Context(), dealID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"history": history,
+	})
+}
+
+// listNettingSnapshots handles GET /deals/{deal_id}/netting-snapshots.
+func (h *Handler) listNettingSnapshots(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+
+	var page int
+	if pageStr := c.Query("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid page format")
+			return
+		}
+	}
+
+	var limit int
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid limit format")
+			return
+		}
+	}
+
+	snapshots, err := h.service.ListNettingSnapshots(c.Request.Context(), dealID, page, limit)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshots)
+}
+
+// listSettlementPenalties handles GET /deals/{deal_id}/penalties.
+func (h *Handler) listSettlementPenalties(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+
+	penalties, err := h.service.ListSettlementPenalties(c.Request.Context(), dealID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, penalties)
+}
+
+// explainNetting handles GET /deals/{deal_id}/netting/explain.
+func (h *Handler) explainNetting(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+
+	explanation, err := h.service.ExplainNetting(c.Request.Context(), dealID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, explanation)
+}
+
+// getNettingAct handles GET /deals/{deal_id}/netting/act.
+func (h *Handler) getNettingAct(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+
+	act, err := h.service.NettingAct(c.Request.Context(), dealID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	fileName := "netting-act-" + strconv.Itoa(dealID) + ".pdf"
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	c.Data(http.StatusOK, "application/pdf", act)
+}
+
+// exportPaymentFiles handles GET /deals/{deal_id}/payment-files.
+func (h *Handler) exportPaymentFiles(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+	var runNumber int
+	if runStr := c.Query("run_number"); runStr != "" {
+		runNumber, err = strconv.Atoi(runStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid run_number format")
+			return
+		}
+	}
+
+	archive, err := h.service.ExportPaymentFiles(c.Request.Context(), dealID, runNumber)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	fileName := "payment-files-" + strconv.Itoa(dealID) + ".zip"
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// deleteDeal handles DELETE /deals/{deal_id}.
+func (h *Handler) deleteDeal(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+
+	deal, err := h.service.DeleteDeal(c.Request.Context(), dealID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Сделка будет удалена по истечении окна отмены",
+		"deal":    deal,
+	})
+}
+
+// restoreDeal handles POST /deals/{deal_id}/restore.
+func (h *Handler) restoreDeal(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Param("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+
+	deal, err := h.service.RestoreDeal(c.Request.Context(), dealID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deal)
+}
+
+// listOrders handles GET /orders.
+func (h *Handler) listOrders(c *gin.Context) {
+	clientID, ok := c.Request.Context().Value(domain.ClientIDKey{}).(int)
+	if !ok {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid client_id")
+		return
+	}
+
+	var page int
+	if pageStr := c.Query("page"); pageStr != "" {
+		var err error
+		page, err = strconv.Atoi(pageStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid page format")
+			return
+		}
+	}
+
+	var limit int
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid limit format")
+			return
+		}
+	}
+
+	var filter domain.OrderFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid filter parameters")
+		return
+	}
+
+	var sort domain.OrderSort
+	if err := c.ShouldBindQuery(&sort); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid sort parameters")
+		return
+	}
+
+	logrus.Info("List Orders Handler")
+	orders, err := h.service.ListOrders(c.Request.Context(), clientID, filter, sort, page, limit)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+// createOrder handles POST /orders.
+func (h *Handler) createOrder(c *gin.Context) {
+	clientID, ok := c.Request.Context().Value(domain.ClientIDKey{}).(int)
+	if !ok {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid client_id")
+		return
+	}
+
+	var req []domain.OrderCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	h.logPayload(c, "Create Orders", req)
+	orders, err := h.service.CreateOrders(c.Request.Context(), clientID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, orders)
+}
+
+// getOrder handles GET /orders/{order_id}.
+func (h *Handler) getOrder(c *gin.Context) {
+	clientID, ok := c.Request.Context().Value(domain.ClientIDKey{}).(int)
+	if !ok {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid client_id")
+		return
+	}
+
+	orderID, err := strconv.Atoi(c.Param("order_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid order_id")
+		return
+	}
+
+	order, err := h.service.GetOrder(c.Request.Context(), clientID, orderID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// listOrderHistory handles GET /orders/{order_id}/history.
+func (h *Handler) listOrderHistory(c *gin.Context) {
+	clientID, ok := c.Request.Context().Value(domain.ClientIDKey{}).(int)
+	if !ok {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid client_id")
+		return
+	}
+
+	orderID, err := strconv.Atoi(c.Param("order_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid order_id")
+		return
+	}
+
+	revisions, err := h.service.ListOrderHistory(c.Request.Context(), clientID, orderID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"history": revisions,
+		"total":   len(revisions),
+	})
+}
+
+// uploadOrderInvoice handles POST /orders/{order_id}/invoice.
+func (h *Handler) uploadOrderInvoice(c *gin.Context) {
+	clientID, ok := c.Request.Context().Value(domain.ClientIDKey{}).(int)
+	if !ok {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid client_id")
+		return
+	}
+
+	orderID, err := strconv.Atoi(c.Param("order_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid order_id")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Missing file form field")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid file")
+		return
+	}
+	defer file.Close()
+
+	order, err := h.service.UploadOrderInvoice(c.Request.Context(), clientID, orderID, fileHeader.Filename,
+		fileHeader.Header.Get("Content-Type"), fileHeader.Size, file)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// downloadOrderInvoice handles GET /orders/{order_id}/invoice.
+func (h *Handler) downloadOrderInvoice(c *gin.Context) {
+	clientID, ok := c.Request.Context().Value(domain.ClientIDKey{}).(int)
+	if !ok {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid client_id")
+		return
+	}
+
+	orderID, err := strconv.Atoi(c.Param("order_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid order_id")
+		return
+	}
+
+	invoice, content, err := h.service.DownloadOrderInvoice(c.Request.Context(), clientID, orderID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, invoice.SizeBytes, invoice.ContentType, content, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": invoice.FileName}),
+	})
+}
+
+// updateOrder handles PUT /orders/{order_id}.
+func (h *Handler) updateOrder(c *gin.Context) {
+	clientID, ok := c.Request.Context().Value(domain.ClientIDKey{}).(int)
+	if !ok {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid client_id")
+		return
+	}
+
+	orderID, err := strconv.Atoi(c.Param("order_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid order_id")
+		return
+	}
+
+	var req domain.OrderCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	h.logPayload(c, "Update Order", req)
+	order, err := h.service.UpdateOrder(c.Request.Context(), clientID, orderID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// patchOrder handles PATCH /orders/{order_id}.
+func (h *Handler) patchOrder(c *gin.Context) {
+	clientID, ok := c.Request.Context().Value(domain.ClientIDKey{}).(int)
+	if !ok {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid client_id")
+		return
+	}
+
+	orderID, err := strconv.Atoi(c.Param("order_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid order_id")
+		return
+	}
+
+	var req domain.OrderUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	h.logPayload(c, "Patch Order", req)
+	order, err := h.service.PatchOrder(c.Request.Context(), clientID, orderID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// transitionOrder handles POST /orders/{order_id}/status.
+func (h *Handler) transitionOrder(c *gin.Context) {
+	clientID, ok := c.Request.Context().Value(domain.ClientIDKey{}).(int)
+	if !ok {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid client_id")
+		return
+	}
+
+	orderID, err := strconv.Atoi(c.Param("order_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid order_id")
+		return
+	}
+
+	var req domain.OrderTransition
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	order, err := h.service.TransitionOrder(c.Request.Context(), clientID, orderID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// deleteOrder handles DELETE /orders/{order_id}.
+func (h *Handler) deleteOrder(c *gin.Context) {
+	clientID, ok := c.Request.Context().Value(domain.ClientIDKey{}).(int)
+	if !ok {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_CLIENT_ID", "Invalid client_id")
+		return
+	}
+
+	orderID, err := strconv.Atoi(c.Param("order_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid order_id")
+		return
+	}
+
+	if err := h.service.DeleteOrder(c.Request.Context(), clientID, orderID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Заказ удален"})
+}
+
+// listMonetarySettlements handles GET /monetary-settlements.
+func (h *Handler) listMonetarySettlements(c *gin.Context) {
+	dealIDStr := c.Query("deal_id")
+	if dealIDStr == "" {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Missing deal_id query parameter")
+		return
+	}
+
+	dealID, err := strconv.Atoi(dealIDStr)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id format")
+		return
+	}
+
+	// Сохраненные расчеты (зафиксированные при входе в клиринг, исполненные, отмененные) постранично
+	if status := c.Query("status"); status != "" {
+		var page int
+		if pageStr := c.Query("page"); pageStr != "" {
+			page, err = strconv.Atoi(pageStr)
+			if err != nil {
+				h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid page format")
+				return
+			}
+		}
+
+		var limit int
+		if limitStr := c.Query("limit"); limitStr != "" {
+			limit, err = strconv.Atoi(limitStr)
+			if err != nil {
+				h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid limit format")
+				return
+			}
+		}
+
+		settlements, err := h.service.ListStoredMonetarySettlements(c.Request.Context(), dealID, status, page, limit)
+		if err != nil {
+			h.handleServiceError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, settlements)
+		return
+	}
+
+	asOf, ok := h.parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	settlements, transfers, err := h.service.ListMonetarySettlements(c.Request.Context(), dealID, asOf, c.Query("settlement_mode"))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"settlements": settlements,
+		"transfers":   transfers,
+	})
+}
+
+// executeMonetarySettlements handles POST /monetary-settlements/execute.
+func (h *Handler) executeMonetarySettlements(c *gin.Context) {
+	dealIDStr := c.Query("deal_id")
+	if dealIDStr == "" {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Missing deal_id query parameter")
+		return
+	}
+
+	dealID, err := strconv.Atoi(dealIDStr)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id format")
+		return
+	}
+
+	settlements, err := h.service.ExecuteMonetarySettlements(c.Request.Context(), dealID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"settlements": settlements,
+	})
+}
+
+// executeMonetarySettlement handles POST /monetary-settlements/{monetary_settlement_id}/execute.
+func (h *Handler) executeMonetarySettlement(c *gin.Context) {
+	settlementID, err := strconv.Atoi(c.Param("monetary_settlement_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid monetary_settlement_id")
+		return
+	}
+
+	var req domain.MonetarySettlementExecution
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	settlement, err := h.service.ExecuteMonetarySettlement(c.Request.Context(), settlementID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settlement)
+}
+
+// cancelMonetarySettlement handles POST /monetary-settlements/{monetary_settlement_id}/cancel.
+func (h *Handler) cancelMonetarySettlement(c *gin.Context) {
+	settlementID, err := strconv.Atoi(c.Param("monetary_settlement_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid monetary_settlement_id")
+		return
+	}
+
+	var req domain.MonetarySettlementCancellation
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	settlement, err := h.service.CancelMonetarySettlement(c.Request.Context(), settlementID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settlement)
+}
+
+// approveMonetarySettlement handles POST /monetary-settlements/{monetary_settlement_id}/approve.
+func (h *Handler) approveMonetarySettlement(c *gin.Context) {
+	settlementID, err := strconv.Atoi(c.Param("monetary_settlement_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid monetary_settlement_id")
+		return
+	}
+
+	// Комментарий к одобрению необязателен, тело запроса может отсутствовать
+	var req domain.MonetarySettlementReview
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+			return
+		}
+	}
+
+	settlement, err := h.service.ApproveMonetarySettlement(c.Request.Context(), settlementID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settlement)
+}
+
+// rejectMonetarySettlement handles POST /monetary-settlements/{monetary_settlement_id}/reject.
+func (h *Handler) rejectMonetarySettlement(c *gin.Context) {
+	settlementID, err := strconv.Atoi(c.Param("monetary_settlement_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid monetary_settlement_id")
+		return
+	}
+
+	var req domain.MonetarySettlementReview
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	settlement, err := h.service.RejectMonetarySettlement(c.Request.Context(), settlementID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settlement)
+}
+
+// recordSettlementPayment handles POST /monetary-settlements/{monetary_settlement_id}/payments.
+func (h *Handler) recordSettlementPayment(c *gin.Context) {
+	settlementID, err := strconv.Atoi(c.Param("monetary_settlement_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid monetary_settlement_id")
+		return
+	}
+
+	var req domain.SettlementPaymentCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	settlement, err := h.service.RecordSettlementPayment(c.Request.Context(), settlementID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, settlement)
+}
+
+// confirmPayment handles POST /payments/confirmations. A repeated callback returns the stored confirmation with 200.
+func (h *Handler) confirmPayment(c *gin.Context) {
+	var req domain.PaymentConfirmationCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	confirmation, created, err := h.service.ConfirmPayment(c.Request.Context(), c.GetString(paymentSourceKey), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	if !created {
+		c.JSON(http.StatusOK, confirmation)
+		return
+	}
+	c.JSON(http.StatusCreated, confirmation)
+}
+
+// getParticipantBalance handles GET /participants/{participant_id}/balance.
+func (h *Handler) getParticipantBalance(c *gin.Context) {
+	balance, err := h.service.ParticipantBalance(c.Request.Context(), c.Param("participant_id"))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// getPaymentInstructions handles GET /monetary-settlements/{monetary_settlement_id}/instructions.
+func (h *Handler) getPaymentInstructions(c *gin.Context) {
+	settlementID, err := strconv.Atoi(c.Param("monetary_settlement_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid monetary_settlement_id")
+		return
+	}
+
+	instructions, err := h.service.PaymentInstructions(c.Request.Context(), settlementID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"instructions": instructions,
+	})
+}
+
+// getClearingCycle handles GET /clearing.
+func (h *Handler) getClearingCycle(c *gin.Context) {
+	var clientID, dealershipID *int
+	if clientIDStr := c.Query("client_id"); clientIDStr != "" {
+		id, err := strconv.Atoi(clientIDStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid client_id format")
+			return
+		}
+		clientID = &id
+	}
+	if dealershipIDStr := c.Query("dealership_id"); dealershipIDStr != "" {
+		id, err := strconv.Atoi(dealershipIDStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid dealership_id format")
+			return
+		}
+		dealershipID = &id
+	}
+
+	cycle, err := h.service.ClearingCycle(c.Request.Context(), clientID, dealershipID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cycle)
+}
+
+// listUpcomingPurges handles GET /admin/retention/upcoming.
+func (h *Handler) listUpcomingPurges(c *gin.Context) {
+	days := 30
+	if daysStr := c.Query("days"); daysStr != "" {
+		var err error
+		days, err = strconv.Atoi(daysStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid days format")
+			return
+		}
+	}
+
+	purges, err := h.service.UpcomingPurges(c.Request.Context(), time.Duration(days)*24*time.Hour)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"purges": purges,
+		"total":  len(purges),
+	})
+}
+
+// purgeExpired handles POST /admin/retention/purge.
+func (h *Handler) purgeExpired(c *gin.Context) {
+	result, err := h.service.PurgeExpired(c.Request.Context())
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// anonymizeClient handles POST /admin/clients/{client_id}/anonymize.
+// A request without confirmation_token returns a token, a request with it performs the anonymization.
+func (h *Handler) anonymizeClient(c *gin.Context) {
+	clientID, err := strconv.Atoi(c.Param("client_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid client_id")
+		return
+	}
+
+	var req domain.AnonymizationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+			return
+		}
+	}
+
+	if req.ConfirmationToken == "" {
+		confirmation, err := h.service.RequestClientAnonymization(c.Request.Context(), clientID)
+		if err != nil {
+			h.handleServiceError(c, err)
+			return
+		}
+		c.JSON(http.StatusAccepted, confirmation)
+		return
+	}
+
+	result, err := h.service.ConfirmClientAnonymization(c.Request.Context(), clientID, req.ConfirmationToken)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// listChanges handles GET /changes.
+func (h *Handler) listChanges(c *gin.Context) {
+	entity := c.Query("entity")
+	if entity == "" {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Missing entity query parameter")
+		return
+	}
+
+	var since int64
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		var err error
+		since, err = strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid since format")
+			return
+		}
+	}
+
+	var limit int
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid limit format")
+			return
+		}
+	}
+
+	feed, err := h.service.ListChanges(c.Request.Context(), entity, since, limit)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feed)
+}
+
+// interBranchReport handles GET /reports/inter-branch.
+func (h *Handler) interBranchReport(c *gin.Context) {
+	from, to := c.Query("from"), c.Query("to")
+	if from == "" || to == "" {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Missing from or to query parameter")
+		return
+	}
+
+	report, err := h.service.InterBranchReport(c.Request.Context(), from, to)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// settlementReport handles GET /reports/settlements.
+func (h *Handler) settlementReport(c *gin.Context) {
+	dealershipIDStr := c.Query("dealership_id")
+	if dealershipIDStr == "" {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Missing dealership_id query parameter")
+		return
+	}
+	dealershipID, err := strconv.Atoi(dealershipIDStr)
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid dealership_id format")
+		return
+	}
+	from, to := c.Query("from"), c.Query("to")
+	if from == "" || to == "" {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Missing from or to query parameter")
+		return
+	}
+
+	report, err := h.service.SettlementReport(c.Request.Context(), dealershipID, from, to)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// listCalendarDays handles GET /admin/calendar.
+func (h *Handler) listCalendarDays(c *gin.Context) {
+	year := time.Now().Year()
+	if yearStr := c.Query("year"); yearStr != "" {
+		var err error
+		year, err = strconv.Atoi(yearStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid year format")
+			return
+		}
+	}
+
+	days, err := h.service.ListCalendarDays(c.Request.Context(), year)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"days": days,
+	})
+}
+
+// createCalendarDay handles POST /admin/calendar.
+func (h *Handler) createCalendarDay(c *gin.Context) {
+	var req domain.CalendarDay
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	day, err := h.service.CreateCalendarDay(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, day)
+}
+
+// updateCalendarDay handles PUT /admin/calendar/{date}.
+func (h *Handler) updateCalendarDay(c *gin.Context) {
+	var req domain.CalendarDay
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+	req.Date = c.Param("date")
+
+	day, err := h.service.UpdateCalendarDay(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, day)
+}
+
+// deleteCalendarDay handles DELETE /admin/calendar/{date}.
+func (h *Handler) deleteCalendarDay(c *gin.Context) {
+	if err := h.service.DeleteCalendarDay(c.Request.Context(), c.Param("date")); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "День удален из календаря"})
+}
+
+// listFXRates handles GET /admin/fx-rates.
+func (h *Handler) listFXRates(c *gin.Context) {
+	rates, err := h.service.ListFXRates(c.Request.Context(), c.Query("currency_code"))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rates": rates,
+	})
+}
+
+// listEffectiveFXRates handles GET /fx-rates.
+func (h *Handler) listEffectiveFXRates(c *gin.Context) {
+	rates, date, err := h.service.ListEffectiveFXRates(c.Request.Context(), c.Query("date"))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"date":  date,
+		"rates": rates,
+	})
+}
+
+// createFXRate handles POST /admin/fx-rates.
+func (h *Handler) createFXRate(c *gin.Context) {
+	var req domain.FXRate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	rate, err := h.service.CreateFXRate(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rate)
+}
+
+// listNettingRules handles GET /admin/netting-rules.
+func (h *Handler) listNettingRules(c *gin.Context) {
+	orderTypeID := 0
+	if value := c.Query("order_type_id"); value != "" {
+		var err error
+		orderTypeID, err = strconv.Atoi(value)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid order_type_id")
+			return
+		}
+	}
+
+	rules, err := h.service.ListNettingRules(c.Request.Context(), orderTypeID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rules": rules,
+	})
+}
+
+// createNettingRule handles POST /admin/netting-rules.
+func (h *Handler) createNettingRule(c *gin.Context) {
+	var req domain.NettingRuleConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	h.logPayload(c, "Create Netting Rule", req)
+	rule, err := h.service.CreateNettingRule(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// updateNettingRule handles PUT /admin/netting-rules/{rule_id}.
+func (h *Handler) updateNettingRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("rule_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid rule_id")
+		return
+	}
+
+	var req domain.NettingRuleConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+	req.RuleID = ruleID
+
+	h.logPayload(c, "Update Netting Rule", req)
+	rule, err := h.service.UpdateNettingRule(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// deleteNettingRule handles DELETE /admin/netting-rules/{rule_id}.
+func (h *Handler) deleteNettingRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("rule_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid rule_id")
+		return
+	}
+
+	if err := h.service.DeleteNettingRule(c.Request.Context(), ruleID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Правило неттинга удалено"})
+}
+
+// listObligationTriggers handles GET /admin/obligation-triggers.
+func (h *Handler) listObligationTriggers(c *gin.Context) {
+	triggers, err := h.service.ListObligationTriggers(c.Request.Context())
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"triggers": triggers,
+	})
+}
+
+// createObligationTrigger handles POST /admin/obligation-triggers.
+func (h *Handler) createObligationTrigger(c *gin.Context) {
+	var req domain.ObligationTrigger
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	h.logPayload(c, "Create Obligation Trigger", req)
+	trigger, err := h.service.CreateObligationTrigger(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, trigger)
+}
+
+// updateObligationTrigger handles PUT /admin/obligation-triggers/{trigger_id}.
+func (h *Handler) updateObligationTrigger(c *gin.Context) {
+	triggerID, err := strconv.Atoi(c.Param("trigger_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid trigger_id")
+		return
+	}
+
+	var req domain.ObligationTrigger
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+	req.TriggerID = triggerID
+
+	h.logPayload(c, "Update Obligation Trigger", req)
+	trigger, err := h.service.UpdateObligationTrigger(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, trigger)
+}
+
+// deleteObligationTrigger handles DELETE /admin/obligation-triggers/{trigger_id}.
+func (h *Handler) deleteObligationTrigger(c *gin.Context) {
+	triggerID, err := strconv.Atoi(c.Param("trigger_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid trigger_id")
+		return
+	}
+
+	if err := h.service.DeleteObligationTrigger(c.Request.Context(), triggerID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Триггер удален"})
+}
+
+// listOrderTypes handles GET /order-types.
+func (h *Handler) listOrderTypes(c *gin.Context) {
+	orderTypes, err := h.service.ListOrderTypes(c.Request.Context())
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"order_types": orderTypes,
+	})
+}
+
+// getOrderType handles GET /order-types/{order_type_id}.
+func (h *Handler) getOrderType(c *gin.Context) {
+	orderTypeID, err := strconv.Atoi(c.Param("order_type_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid order_type_id")
+		return
+	}
+
+	orderType, err := h.service.GetOrderType(c.Request.Context(), orderTypeID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderType)
+}
+
+// createOrderType handles POST /order-types.
+func (h *Handler) createOrderType(c *gin.Context) {
+	var req domain.OrderType
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	h.logPayload(c, "Create Order Type", req)
+	orderType, err := h.service.CreateOrderType(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, orderType)
+}
+
+// updateOrderType handles PUT /order-types/{order_type_id}.
+func (h *Handler) updateOrderType(c *gin.Context) {
+	orderTypeID, err := strconv.Atoi(c.Param("order_type_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid order_type_id")
+		return
+	}
+
+	var req domain.OrderType
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+	req.OrderTypeID = orderTypeID
+
+	h.logPayload(c, "Update Order Type", req)
+	orderType, err := h.service.UpdateOrderType(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderType)
+}
+
+// deleteOrderType handles DELETE /order-types/{order_type_id}.
+func (h *Handler) deleteOrderType(c *gin.Context) {
+	orderTypeID, err := strconv.Atoi(c.Param("order_type_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid order_type_id")
+		return
+	}
+
+	if err := h.service.DeleteOrderType(c.Request.Context(), orderTypeID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Тип заказа удален"})
+}
+
+// listClients handles GET /clients.
+func (h *Handler) listClients(c *gin.Context) {
+	var page, limit int
+	var err error
+	if pageStr := c.Query("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid page format")
+			return
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid limit format")
+			return
+		}
+	}
+
+	clients, err := h.service.ListClients(c.Request.Context(), page, limit)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients)
+}
+
+// getClient handles GET /clients/{client_id}.
+func (h *Handler) getClient(c *gin.Context) {
+	clientID, err := strconv.Atoi(c.Param("client_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid client_id")
+		return
+	}
+
+	client, err := h.service.GetClient(c.Request.Context(), clientID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+// createClient handles POST /clients.
+func (h *Handler) createClient(c *gin.Context) {
+	var req domain.Client
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	// Тело запроса не логируется: в нем персональные данные клиента
+	client, err := h.service.CreateClient(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, client)
+}
+
+// updateClient handles PUT /clients/{client_id}.
+func (h *Handler) updateClient(c *gin.Context) {
+	clientID, err := strconv.Atoi(c.Param("client_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid client_id")
+		return
+	}
+
+	var req domain.Client
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+	req.ClientID = clientID
+
+	// Тело запроса не логируется: в нем персональные данные клиента
+	client, err := h.service.UpdateClient(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+// deleteClient handles DELETE /clients/{client_id}.
+func (h *Handler) deleteClient(c *gin.Context) {
+	clientID, err := strconv.Atoi(c.Param("client_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid client_id")
+		return
+	}
+
+	if err := h.service.DeleteClient(c.Request.Context(), clientID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Клиент удален"})
+}
+
+// listDealerships handles GET /dealerships.
+func (h *Handler) listDealerships(c *gin.Context) {
+	activeOnly := false
+	if value := c.Query("active"); value != "" {
+		var err error
+		activeOnly, err = strconv.ParseBool(value)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid active")
+			return
+		}
+	}
+
+	dealerships, err := h.service.ListDealerships(c.Request.Context(), activeOnly)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dealerships": dealerships,
+	})
+}
+
+// getDealership handles GET /dealerships/{dealership_id}.
+func (h *Handler) getDealership(c *gin.Context) {
+	dealershipID, err := strconv.Atoi(c.Param("dealership_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid dealership_id")
+		return
+	}
+
+	dealership, err := h.service.GetDealership(c.Request.Context(), dealershipID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dealership)
+}
+
+// createDealership handles POST /dealerships.
+func (h *Handler) createDealership(c *gin.Context) {
+	var req domain.Dealership
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	h.logPayload(c, "Create Dealership", req)
+	dealership, err := h.service.CreateDealership(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dealership)
+}
+
+// updateDealership handles PUT /dealerships/{dealership_id}.
+func (h *Handler) updateDealership(c *gin.Context) {
+	dealershipID, err := strconv.Atoi(c.Param("dealership_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid dealership_id")
+		return
+	}
+
+	var req domain.Dealership
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+	req.DealershipID = dealershipID
+
+	h.logPayload(c, "Update Dealership", req)
+	dealership, err := h.service.UpdateDealership(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dealership)
+}
+
+// deleteDealership handles DELETE /dealerships/{dealership_id}.
+func (h *Handler) deleteDealership(c *gin.Context) {
+	dealershipID, err := strconv.Atoi(c.Param("dealership_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid dealership_id")
+		return
+	}
+
+	if err := h.service.DeleteDealership(c.Request.Context(), dealershipID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Дилерский центр удален"})
+}
+
+// listManagers handles GET /managers.
+func (h *Handler) listManagers(c *gin.Context) {
+	var filter domain.ManagerFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid query parameters")
+		return
+	}
+
+	managers, err := h.service.ListManagers(c.Request.Context(), filter)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"managers": managers,
+	})
+}
+
+// getManager handles GET /managers/{manager_id}.
+func (h *Handler) getManager(c *gin.Context) {
+	managerID, err := strconv.Atoi(c.Param("manager_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid manager_id")
+		return
+	}
+
+	manager, err := h.service.GetManager(c.Request.Context(), managerID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, manager)
+}
+
+// createManager handles POST /managers.
+func (h *Handler) createManager(c *gin.Context) {
+	var req domain.Manager
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	h.logPayload(c, "Create Manager", req)
+	manager, err := h.service.CreateManager(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, manager)
+}
+
+// updateManager handles PUT /managers/{manager_id}.
+func (h *Handler) updateManager(c *gin.Context) {
+	managerID, err := strconv.Atoi(c.Param("manager_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid manager_id")
+		return
+	}
+
+	var req domain.Manager
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+	req.ManagerID = managerID
+
+	h.logPayload(c, "Update Manager", req)
+	manager, err := h.service.UpdateManager(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, manager)
+}
+
+// deleteManager handles DELETE /managers/{manager_id}.
+func (h *Handler) deleteManager(c *gin.Context) {
+	managerID, err := strconv.Atoi(c.Param("manager_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid manager_id")
+		return
+	}
+
+	if err := h.service.DeleteManager(c.Request.Context(), managerID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Менеджер удален"})
+}
+
+// listVehicles handles GET /vehicles.
+func (h *Handler) listVehicles(c *gin.Context) {
+	vehicles, err := h.service.ListVehicles(c.Request.Context(), c.Query("condition"))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vehicles": vehicles,
+	})
+}
+
+// getVehicle handles GET /vehicles/{vehicle_id}.
+func (h *Handler) getVehicle(c *gin.Context) {
+	vehicleID, err := strconv.Atoi(c.Param("vehicle_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid vehicle_id")
+		return
+	}
+
+	vehicle, err := h.service.GetVehicle(c.Request.Context(), vehicleID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, vehicle)
+}
+
+// createVehicle handles POST /vehicles.
+func (h *Handler) createVehicle(c *gin.Context) {
+	var req domain.Vehicle
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	h.logPayload(c, "Create Vehicle", req)
+	vehicle, err := h.service.CreateVehicle(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, vehicle)
+}
+
+// updateVehicle handles PUT /vehicles/{vehicle_id}.
+func (h *Handler) updateVehicle(c *gin.Context) {
+	vehicleID, err := strconv.Atoi(c.Param("vehicle_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid vehicle_id")
+		return
+	}
+
+	var req domain.Vehicle
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+	req.VehicleID = vehicleID
+
+	h.logPayload(c, "Update Vehicle", req)
+	vehicle, err := h.service.UpdateVehicle(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, vehicle)
+}
+
+// deleteVehicle handles DELETE /vehicles/{vehicle_id}.
+func (h *Handler) deleteVehicle(c *gin.Context) {
+	vehicleID, err := strconv.Atoi(c.Param("vehicle_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid vehicle_id")
+		return
+	}
+
+	if err := h.service.DeleteVehicle(c.Request.Context(), vehicleID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Автомобиль удален"})
+}
+
+// listCreditContracts handles GET /credit-contracts.
+func (h *Handler) listCreditContracts(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Query("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+
+	contracts, err := h.service.ListCreditContracts(c.Request.Context(), dealID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"credit_contracts": contracts,
+	})
+}
+
+// getCreditContract handles GET /credit-contracts/{credit_contract_id}.
+func (h *Handler) getCreditContract(c *gin.Context) {
+	contractID, err := strconv.Atoi(c.Param("credit_contract_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid credit_contract_id")
+		return
+	}
+
+	contract, err := h.service.GetCreditContract(c.Request.Context(), contractID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, contract)
+}
+
+// createCreditContract handles POST /credit-contracts.
+func (h *Handler) createCreditContract(c *gin.Context) {
+	var req domain.CreditContract
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	h.logPayload(c, "Create Credit Contract", req)
+	contract, err := h.service.CreateCreditContract(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, contract)
+}
+
+// updateCreditContract handles PUT /credit-contracts/{credit_contract_id}.
+func (h *Handler) updateCreditContract(c *gin.Context) {
+	contractID, err := strconv.Atoi(c.Param("credit_contract_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid credit_contract_id")
+		return
+	}
+
+	var req domain.CreditContract
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+	req.CreditContractID = contractID
+
+	h.logPayload(c, "Update Credit Contract", req)
+	contract, err := h.service.UpdateCreditContract(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, contract)
+}
+
+// deleteCreditContract handles DELETE /credit-contracts/{credit_contract_id}.
+func (h *Handler) deleteCreditContract(c *gin.Context) {
+	contractID, err := strconv.Atoi(c.Param("credit_contract_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid credit_contract_id")
+		return
+	}
+
+	if err := h.service.DeleteCreditContract(c.Request.Context(), contractID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Кредитный договор удален"})
+}
+
+// listAppraisals handles GET /trade-in-appraisals.
+func (h *Handler) listAppraisals(c *gin.Context) {
+	dealID, err := strconv.Atoi(c.Query("deal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id")
+		return
+	}
+
+	appraisals, err := h.service.ListAppraisals(c.Request.Context(), dealID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"appraisals": appraisals,
+	})
+}
+
+// getAppraisal handles GET /trade-in-appraisals/{appraisal_id}.
+func (h *Handler) getAppraisal(c *gin.Context) {
+	appraisalID, err := strconv.Atoi(c.Param("appraisal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid appraisal_id")
+		return
+	}
+
+	appraisal, err := h.service.GetAppraisal(c.Request.Context(), appraisalID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appraisal)
+}
+
+// createAppraisal handles POST /trade-in-appraisals.
+func (h *Handler) createAppraisal(c *gin.Context) {
+	var req domain.TradeInAppraisal
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	h.logPayload(c, "Create Trade-In Appraisal", req)
+	appraisal, err := h.service.CreateAppraisal(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, appraisal)
+}
+
+// updateAppraisal handles PUT /trade-in-appraisals/{appraisal_id}.
+func (h *Handler) updateAppraisal(c *gin.Context) {
+	appraisalID, err := strconv.Atoi(c.Param("appraisal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid appraisal_id")
+		return
+	}
+
+	var req domain.TradeInAppraisal
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+	req.AppraisalID = appraisalID
+
+	h.logPayload(c, "Update Trade-In Appraisal", req)
+	appraisal, err := h.service.UpdateAppraisal(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appraisal)
+}
+
+// approveAppraisal handles POST /trade-in-appraisals/{appraisal_id}/approve.
+func (h *Handler) approveAppraisal(c *gin.Context) {
+	appraisalID, err := strconv.Atoi(c.Param("appraisal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid appraisal_id")
+		return
+	}
+
+	// Комментарий к одобрению необязателен, тело запроса может отсутствовать
+	var req domain.AppraisalReview
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+			return
+		}
+	}
+
+	appraisal, err := h.service.ApproveAppraisal(c.Request.Context(), appraisalID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appraisal)
+}
+
+// rejectAppraisal handles POST /trade-in-appraisals/{appraisal_id}/reject.
+func (h *Handler) rejectAppraisal(c *gin.Context) {
+	appraisalID, err := strconv.Atoi(c.Param("appraisal_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid appraisal_id")
+		return
+	}
+
+	var req domain.AppraisalReview
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	appraisal, err := h.service.RejectAppraisal(c.Request.Context(), appraisalID, req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
 	}
 
-	dealID, err := strconv.Atoi(dealIDStr)
+	c.JSON(http.StatusOK, appraisal)
+}
+
+// deleteAppraisal handles DELETE /trade-in-appraisals/{appraisal_id}.
+func (h *Handler) deleteAppraisal(c *gin.Context) {
+	appraisalID, err := strconv.Atoi(c.Param("appraisal_id"))
 	if err != nil {
-		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid deal_id format")
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid appraisal_id")
+		return
+	}
+
+	if err := h.service.DeleteAppraisal(c.Request.Context(), appraisalID); err != nil {
+		h.handleServiceError(c, err)
 		return
 	}
 
-	settlements, err := h.service.ListMonetarySettlements(c.Request.Context(), dealID)
+	c.JSON(http.StatusOK, gin.H{"message": "Оценка удалена"})
+}
+
+// listBanks handles GET /banks.
+func (h *Handler) listBanks(c *gin.Context) {
+	activeOnly := false
+	if value := c.Query("active"); value != "" {
+		var err error
+		activeOnly, err = strconv.ParseBool(value)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid active")
+			return
+		}
+	}
+
+	banks, err := h.service.ListBanks(c.Request.Context(), activeOnly)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"settlements": settlements,
+		"banks": banks,
+	})
+}
+
+// getBank handles GET /banks/{bank_id}.
+func (h *Handler) getBank(c *gin.Context) {
+	bankID, err := strconv.Atoi(c.Param("bank_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid bank_id")
+		return
+	}
+
+	bank, err := h.service.GetBank(c.Request.Context(), bankID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bank)
+}
+
+// createBank handles POST /banks.
+func (h *Handler) createBank(c *gin.Context) {
+	var req domain.Bank
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	h.logPayload(c, "Create Bank", req)
+	bank, err := h.service.CreateBank(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, bank)
+}
+
+// updateBank handles PUT /banks/{bank_id}.
+func (h *Handler) updateBank(c *gin.Context) {
+	bankID, err := strconv.Atoi(c.Param("bank_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid bank_id")
+		return
+	}
+
+	var req domain.Bank
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+	req.BankID = bankID
+
+	h.logPayload(c, "Update Bank", req)
+	bank, err := h.service.UpdateBank(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bank)
+}
+
+// deleteBank handles DELETE /banks/{bank_id}.
+func (h *Handler) deleteBank(c *gin.Context) {
+	bankID, err := strconv.Atoi(c.Param("bank_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid bank_id")
+		return
+	}
+
+	if err := h.service.DeleteBank(c.Request.Context(), bankID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Банк удален"})
+}
+
+// listOrderReviews handles GET /admin/order-reviews.
+func (h *Handler) listOrderReviews(c *gin.Context) {
+	reviews, err := h.service.ListOrderReviews(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reviews": reviews,
+		"total":   len(reviews),
+	})
+}
+
+// approveOrderReview handles POST /admin/order-reviews/{review_id}/approve.
+func (h *Handler) approveOrderReview(c *gin.Context) {
+	h.decideOrderReview(c, domain.ReviewApproved)
+}
+
+// rejectOrderReview handles POST /admin/order-reviews/{review_id}/reject.
+func (h *Handler) rejectOrderReview(c *gin.Context) {
+	h.decideOrderReview(c, domain.ReviewRejected)
+}
+
+// decideOrderReview records the decision on a flagged order.
+func (h *Handler) decideOrderReview(c *gin.Context, status string) {
+	reviewID, err := strconv.Atoi(c.Param("review_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid review_id")
+		return
+	}
+
+	review, err := h.service.DecideOrderReview(c.Request.Context(), reviewID, status)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// simulate handles POST /simulations.
+func (h *Handler) simulate(c *gin.Context) {
+	var req domain.SimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	result, err := h.service.Simulate(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// listClearingSessions handles GET /clearing-sessions.
+func (h *Handler) listClearingSessions(c *gin.Context) {
+	var page int
+	var err error
+	if pageStr := c.Query("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid page format")
+			return
+		}
+	}
+
+	var limit int
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid limit format")
+			return
+		}
+	}
+
+	sessions, err := h.service.ListClearingSessions(c.Request.Context(), c.Query("status"), page, limit)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// getClearingSession handles GET /clearing-sessions/{session_id}.
+func (h *Handler) getClearingSession(c *gin.Context) {
+	sessionID, err := strconv.Atoi(c.Param("session_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid session_id")
+		return
+	}
+
+	session, err := h.service.GetClearingSession(c.Request.Context(), sessionID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// computeClearingSession handles POST /clearing-sessions/{session_id}/compute.
+func (h *Handler) computeClearingSession(c *gin.Context) {
+	sessionID, err := strconv.Atoi(c.Param("session_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid session_id")
+		return
+	}
+
+	session, err := h.service.ComputeClearingSession(c.Request.Context(), sessionID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// closeClearingSession handles POST /clearing-sessions/{session_id}/close.
+func (h *Handler) closeClearingSession(c *gin.Context) {
+	sessionID, err := strconv.Atoi(c.Param("session_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid session_id")
+		return
+	}
+
+	session, err := h.service.CloseClearingSession(c.Request.Context(), sessionID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// importBankStatement handles POST /bank-statements.
+func (h *Handler) importBankStatement(c *gin.Context) {
+	var req domain.BankStatementImport
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	reconciliation, err := h.service.ImportBankStatement(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reconciliation)
+}
+
+// importBankStatementFile handles POST /bank-statements/files.
+func (h *Handler) importBankStatementFile(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Missing file form field")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid file")
+		return
+	}
+	defer file.Close()
+
+	reconciliation, err := h.service.ImportBankStatementFile(c.Request.Context(), c.Query("format"), fileHeader.Filename,
+		fileHeader.Size, file)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reconciliation)
+}
+
+// createWebhookSubscription handles POST /webhooks.
+func (h *Handler) createWebhookSubscription(c *gin.Context) {
+	var req domain.WebhookSubscription
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	subscription, err := h.service.CreateWebhookSubscription(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// listWebhookSubscriptions handles GET /webhooks.
+func (h *Handler) listWebhookSubscriptions(c *gin.Context) {
+	subscriptions, err := h.service.ListWebhookSubscriptions(c.Request.Context())
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscriptions": subscriptions,
+	})
+}
+
+// deleteWebhookSubscription handles DELETE /webhooks/{subscription_id}.
+func (h *Handler) deleteWebhookSubscription(c *gin.Context) {
+	subscriptionID, err := strconv.Atoi(c.Param("subscription_id"))
+	if err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid subscription_id")
+		return
+	}
+
+	if err := h.service.DeleteWebhookSubscription(c.Request.Context(), subscriptionID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Подписка удалена"})
+}
+
+// integrationsStatus handles GET /admin/integrations/status.
+func (h *Handler) integrationsStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"integrations": h.service.IntegrationsStatus(c.Request.Context()),
 	})
 }
+
+// provisionTenant handles POST /admin/tenants.
+func (h *Handler) provisionTenant(c *gin.Context) {
+	var req domain.Tenant
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+		return
+	}
+
+	tenant, err := h.service.ProvisionTenant(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tenant)
+}
+
+// listTenants handles GET /admin/tenants.
+func (h *Handler) listTenants(c *gin.Context) {
+	tenants, err := h.service.ListTenants(c.Request.Context())
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tenants": tenants})
+}
+
+// listAuditLog handles GET /admin/audit-log.
+func (h *Handler) listAuditLog(c *gin.Context) {
+	var filter domain.AuditFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid filter parameters")
+		return
+	}
+	var page, limit int
+	var err error
+	if pageStr := c.Query("page"); pageStr != "" {
+		page, err = strconv.Atoi(pageStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid page format")
+			return
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid limit format")
+			return
+		}
+	}
+
+	entries, err := h.service.ListAuditLog(c.Request.Context(), filter, page, limit)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// revokeToken handles POST /auth/revoke.
+func (h *Handler) revokeToken(c *gin.Context) {
+	var req domain.TokenRevocation
+	// Пустое тело отзывает токен самого запроса
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+			return
+		}
+	}
+
+	revoked, err := h.service.RevokeToken(c.Request.Context(), req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revoked)
+}
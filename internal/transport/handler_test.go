@@ -0,0 +1,191 @@
+package transport_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"cliring/config"
+	"cliring/internal/domain"
+	"cliring/internal/repository/memory"
+	"cliring/internal/service"
+	"cliring/internal/transport"
+)
+
+// testSecret signs the tokens of the test requests.
+const testSecret = "test-secret"
+
+// testServer is the API on the in-memory repository with a deal of a client financed by a bank.
+type testServer struct {
+	router   *gin.Engine
+	clientID int
+	bankID   int
+	dealID   int
+}
+
+// newTestServer starts the API on memory.New() and fills it through the service as an administrator.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg, err := config.New()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	svc := service.NewService(memory.New(), cfg)
+	handler := transport.NewHandler(svc).WithTokenParser(transport.NewSecretTokenParser(testSecret, 0))
+	srv := &testServer{router: handler.InitRoutes(), clientID: 1, bankID: 1, dealID: 1}
+
+	ctx := context.WithValue(context.Background(), domain.ActorKey{}, "admin")
+	ctx = context.WithValue(ctx, domain.RolesKey{}, []string{domain.RoleAdmin})
+	steps := []func() error{
+		func() error {
+			_, err := svc.CreateDealership(ctx, domain.Dealership{DealershipID: 1, Name: "Рольф Юг", LegalEntity: "ООО Рольф", Active: true})
+			return err
+		},
+		func() error {
+			_, err := svc.CreateManager(ctx, domain.Manager{ManagerID: 1, DealershipID: 1, FullName: "Иванов Иван", Active: true})
+			return err
+		},
+		func() error {
+			_, err := svc.CreateClient(ctx, domain.Client{ClientID: srv.clientID, FullName: "Петров Петр"})
+			return err
+		},
+		func() error {
+			_, err := svc.CreateBank(ctx, domain.Bank{BankID: srv.bankID, Name: "Банк", Active: true})
+			return err
+		},
+		func() error {
+			_, err := svc.CreateDeal(ctx, domain.Deal{DealID: srv.dealID, DealershipID: 1, ManagerID: 1, ClientID: srv.clientID})
+			return err
+		},
+		func() error {
+			_, err := svc.TransitionDeal(ctx, srv.dealID, domain.DealTransition{Status: domain.DealStatusActive})
+			return err
+		},
+		func() error {
+			contract, err := svc.CreateCreditContract(ctx, domain.CreditContract{
+				DealID: srv.dealID, BankID: srv.bankID, ContractNumber: "КД-1", Principal: domain.MoneyFromFloat(700_000),
+				TermMonths: 36, Rate: 12.5,
+			})
+			if err != nil {
+				return err
+			}
+			_, err = svc.CreateOrders(ctx, srv.clientID, []domain.OrderCreate{
+				{DealID: srv.dealID, OrderTypeID: domain.OrderTypePurchase, Amount: domain.MoneyFromFloat(1_000_000)},
+				{DealID: srv.dealID, OrderTypeID: domain.OrderTypeCredit, Amount: domain.MoneyFromFloat(700_000), CreditContractID: &contract.CreditContractID},
+			})
+			return err
+		},
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+	return srv
+}
+
+// do performs a request with a token carrying the claims and returns the response.
+func (srv *testServer) do(t *testing.T, method, path string, claims jwt.MapClaims, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	claims["sub"] = "test"
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatalf("encode body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// errorCode returns the error code of an error response.
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp domain.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode error response %q: %v", rec.Body.String(), err)
+	}
+	return resp.Error.Code
+}
+
+func TestHandlerErrors(t *testing.T) {
+	srv := newTestServer(t)
+	admin := func() jwt.MapClaims { return jwt.MapClaims{"roles": []string{domain.RoleAdmin}} }
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		claims     jwt.MapClaims
+		body       any
+		wantStatus int
+		wantCode   string
+	}{
+		{"missing bank", http.MethodGet, "/v1/banks/999", admin(), nil, http.StatusNotFound, "ERR_NOT_FOUND"},
+		{"duplicate bank", http.MethodPost, "/v1/banks", admin(), domain.Bank{BankID: srv.bankID, Name: "Дубликат", Active: true},
+			http.StatusConflict, "ERR_CONFLICT"},
+		{"bank used by orders", http.MethodDelete, "/v1/banks/1", admin(), nil, http.StatusConflict, "ERR_CONFLICT"},
+		{"client write", http.MethodPost, "/v1/banks", jwt.MapClaims{"roles": []string{domain.RoleClient}, "client_id": srv.clientID},
+			domain.Bank{BankID: 2, Name: "Банк клиента", Active: true}, http.StatusForbidden, "ERR_FORBIDDEN"},
+		{"client without client_id", http.MethodGet, "/v1/monetary-settlements?deal_id=1", jwt.MapClaims{"roles": []string{domain.RoleClient}},
+			nil, http.StatusNotFound, "ERR_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := srv.do(t, tt.method, tt.path, tt.claims, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if code := errorCode(t, rec); code != tt.wantCode {
+				t.Errorf("got code %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestHandlerListMonetarySettlements(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := srv.do(t, http.MethodGet, "/v1/monetary-settlements?deal_id=1", jwt.MapClaims{"roles": []string{domain.RoleClient}, "client_id": srv.clientID}, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp struct {
+		Settlements []*domain.MonetarySettlement `json:"settlements"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	want := map[string]domain.Money{
+		domain.ParticipantClient:     domain.MoneyFromFloat(300_000),
+		domain.ParticipantBank:       domain.MoneyFromFloat(700_000),
+		domain.ParticipantDealership: domain.MoneyFromFloat(-1_000_000),
+	}
+	if len(resp.Settlements) != len(want) {
+		t.Fatalf("got %d settlements, want %d: %s", len(resp.Settlements), len(want), rec.Body.String())
+	}
+	for _, settlement := range resp.Settlements {
+		if settlement.Amount != want[settlement.Participant] {
+			t.Errorf("settlement of %s = %s, want %s", settlement.Participant, settlement.Amount, want[settlement.Participant])
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"cliring/internal/domain"
+	"cliring/internal/service"
+)
+
+const (
+	// idempotencyKeyHeader carries the client-generated key of a creation request.
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader marks a response replayed from a previous request.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 200
+)
+
+// responseRecorder copies the response body so that it can be stored for replays.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotencyMiddleware makes a creation request with an Idempotency-Key header safe to retry: the first
+// response is stored and replayed with the same status code to retries with the same key and body.
+// Requests without the header are executed as usual.
+func (h *Handler) idempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_IDEMPOTENCY_KEY", "Idempotency-Key is too long")
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			h.errorResponse(c, http.StatusBadRequest, "ERR_INVALID_INPUT", "Invalid request body")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Ключ действует в пределах пользователя и маршрута; хеш отличает повтор от другого запроса с тем же ключом
+		identity := c.ClientIP()
+		if actor, ok := c.Request.Context().Value(domain.ActorKey{}).(string); ok && actor != "" {
+			identity = "sub:" + actor
+		}
+		scope := identity + " " + c.Request.Method + " " + c.FullPath()
+		sum := sha256.Sum256(append([]byte(c.Request.URL.RequestURI()+"\n"), body...))
+		requestHash := hex.EncodeToString(sum[:])
+
+		ctx := c.Request.Context()
+		stored, owner, err := h.service.BeginIdempotentRequest(ctx, scope, key, requestHash)
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyInProgress):
+			h.errorResponse(c, http.StatusConflict, "ERR_IDEMPOTENCY_IN_PROGRESS", "Request with this Idempotency-Key is in progress")
+			c.Abort()
+			return
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			h.errorResponse(c, http.StatusUnprocessableEntity, "ERR_IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was used for a different request")
+			c.Abort()
+			return
+		case err != nil:
+			h.handleServiceError(c, err)
+			c.Abort()
+			return
+		case stored != nil:
+			c.Header(idempotentReplayedHeader, "true")
+			c.Data(*stored.StatusCode, "application/json; charset=utf-8", stored.Body)
+			c.Abort()
+			return
+		}
+
+		// Ключ продлевается, пока выполняется обработчик: медленный запрос не перехватывается повтором
+		heartbeatCtx, stopHeartbeat := context.WithCancel(context.WithoutCancel(ctx))
+		defer stopHeartbeat()
+		go h.service.HeartbeatIdempotentRequest(heartbeatCtx, scope, key, owner)
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		completed := false
+		defer func() {
+			// Сбой или паника обработчика освобождают ключ, чтобы повтор выполнил запрос заново
+			if !completed {
+				if err := h.service.ReleaseIdempotentRequest(context.WithoutCancel(ctx), scope, key, owner); err != nil {
+					logrus.Error("Failed to release idempotency key: ", err)
+				}
+			}
+		}()
+
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		// Ответ уже отдан: даже если его не удалось сохранить, ключ не освобождается, чтобы повтор не создал дубликат
+		completed = true
+		if err := h.service.CompleteIdempotentRequest(context.WithoutCancel(ctx), scope, key, owner, status, recorder.body.Bytes()); err != nil {
+			logrus.Error("Failed to store idempotent response: ", err)
+		}
+	}
+}
@@ -0,0 +1,659 @@
+package transport
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"cliring/internal/domain"
+)
+
+// openAPIPath is the route serving the generated OpenAPI document.
+const openAPIPath = "/v1/openapi.json"
+
+// queryParam describes a query parameter of a route.
+type queryParam struct {
+	Name     string
+	Type     string
+	Format   string
+	Required bool
+}
+
+// routeDoc describes the request and response of a route for the OpenAPI document.
+type routeDoc struct {
+	Summary string
+	Query   []queryParam
+	// Request is a value of the JSON request body type, nil if the route has no body.
+	Request any
+	// Upload is the multipart form field of an uploaded file.
+	Upload string
+	// Status is the success status code, 200 if not set.
+	Status int
+	// Response is a value of the JSON response type; Binary marks a file download instead.
+	Response any
+	Binary   bool
+	// APIKey marks a route authenticated with the X-API-Key header of a bank instead of a bearer token.
+	APIKey bool
+}
+
+// messageResponse is the response of routes that only report the result as text.
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// routeDocs documents the registered routes, keyed by method and gin path.
+// Routes without an entry are still listed in the document, without schemas.
+var routeDocs = map[string]routeDoc{
+	"GET /healthz": {Summary: "Проба живучести", Response: domain.Liveness{}},
+	"GET /readyz":  {Summary: "Проба готовности", Response: domain.Readiness{}},
+
+	"POST /v1/deals": {Summary: "Создать сделку", Request: domain.Deal{}, Status: http.StatusCreated,
+		Response: domain.Deal{}},
+	"PATCH /v1/deals/:deal_id": {Summary: "Частично обновить сделку", Request: domain.DealUpdate{},
+		Response: domain.Deal{}},
+	"DELETE /v1/deals/:deal_id": {Summary: "Запланировать удаление сделки", Status: http.StatusAccepted,
+		Response: struct {
+			Message string       `json:"message"`
+			Deal    *domain.Deal `json:"deal"`
+		}{}},
+	"POST /v1/deals/:deal_id/restore": {Summary: "Отменить удаление сделки", Response: domain.Deal{}},
+	"POST /v1/deals/:deal_id/transition": {Summary: "Сменить статус сделки", Request: domain.DealTransition{},
+		Response: domain.Deal{}},
+	"GET /v1/deals/:deal_id/history": {Summary: "Журнал изменений сделки",
+		Response: struct {
+			History []*domain.DealHistoryEntry `json:"history"`
+		}{}},
+	"GET /v1/deals/:deal_id/netting-snapshots": {Summary: "Версии результата неттинга сделки",
+		Query: []queryParam{
+			{Name: "page", Type: "integer"},
+			{Name: "limit", Type: "integer"},
+		},
+		Response: domain.NettingSnapshotList{}},
+	"GET /v1/deals/:deal_id/penalties": {Summary: "Неустойка по просроченным расчетам сделки",
+		Response: []*domain.SettlementPenalty{}},
+	"GET /v1/deals/:deal_id/netting/explain": {Summary: "Объяснение неттинга сделки",
+		Response: domain.NettingExplanation{}},
+	"GET /v1/deals/:deal_id/netting/act": {Summary: "Акт взаимозачета сделки (PDF)", Binary: true},
+	"GET /v1/deals/:deal_id/payment-files": {Summary: "Платежные файлы pain.001 прогона расчетов (ZIP)",
+		Query: []queryParam{{Name: "run_number", Type: "integer"}}, Binary: true},
+	"GET /v1/deals/:deal_id/full": {Summary: "Сделка с заказами и расчетами",
+		Query: []queryParam{
+			{Name: "as_of", Type: "string", Format: "date-time"},
+			{Name: "sort", Type: "string"},
+			{Name: "order", Type: "string"},
+		},
+		Response: domain.DealView{}},
+	"GET /v1/deals/:deal_id/forecast": {Summary: "Прогноз чистых позиций сделки", Response: domain.DealForecast{}},
+	"POST /v1/deals/:deal_id/documents": {Summary: "Загрузить документ сделки", Upload: "file",
+		Status: http.StatusCreated, Response: domain.DealDocument{}},
+	"GET /v1/deals/:deal_id/documents": {Summary: "Документы сделки",
+		Response: struct {
+			Documents []*domain.DealDocument `json:"documents"`
+		}{}},
+	"GET /v1/deals/:deal_id/documents/:document_id":    {Summary: "Скачать документ сделки", Binary: true},
+	"DELETE /v1/deals/:deal_id/documents/:document_id": {Summary: "Удалить документ сделки", Response: messageResponse{}},
+
+	"GET /v1/orders": {Summary: "Список заказов клиента",
+		Query: []queryParam{
+			{Name: "client_id", Type: "integer", Required: true},
+			{Name: "page", Type: "integer"},
+			{Name: "limit", Type: "integer"},
+			{Name: "status", Type: "string"},
+			{Name: "order_type_id", Type: "integer"},
+			{Name: "deal_id", Type: "integer"},
+			{Name: "bank_id", Type: "integer"},
+			{Name: "amount_min", Type: "number"},
+			{Name: "amount_max", Type: "number"},
+			{Name: "created_from", Type: "string", Format: "date-time"},
+			{Name: "created_to", Type: "string", Format: "date-time"},
+			{Name: "sort", Type: "string"},
+			{Name: "order", Type: "string"},
+		},
+		Response: domain.OrderList{}},
+	"POST /v1/orders": {Summary: "Создать заказы",
+		Query:   []queryParam{{Name: "client_id", Type: "integer", Required: true}},
+		Request: []domain.OrderCreate{}, Status: http.StatusCreated, Response: []*domain.Order{}},
+	"GET /v1/orders/:order_id": {Summary: "Получить заказ",
+		Query:    []queryParam{{Name: "client_id", Type: "integer", Required: true}},
+		Response: domain.Order{}},
+	"PUT /v1/orders/:order_id": {Summary: "Обновить заказ",
+		Query:   []queryParam{{Name: "client_id", Type: "integer", Required: true}},
+		Request: domain.OrderCreate{}, Response: domain.Order{}},
+	"PATCH /v1/orders/:order_id": {Summary: "Частично обновить заказ",
+		Query:   []queryParam{{Name: "client_id", Type: "integer", Required: true}},
+		Request: domain.OrderUpdate{}, Response: domain.Order{}},
+	"POST /v1/orders/:order_id/status": {Summary: "Сменить статус заказа",
+		Query:   []queryParam{{Name: "client_id", Type: "integer", Required: true}},
+		Request: domain.OrderTransition{}, Response: domain.Order{}},
+	"DELETE /v1/orders/:order_id": {Summary: "Удалить заказ",
+		Query:    []queryParam{{Name: "client_id", Type: "integer", Required: true}},
+		Response: messageResponse{}},
+	"GET /v1/orders/:order_id/history": {Summary: "История изменений заказа",
+		Query: []queryParam{{Name: "client_id", Type: "integer", Required: true}},
+		Response: struct {
+			History []*domain.OrderRevision `json:"history"`
+			Total   int                     `json:"total"`
+		}{}},
+	"POST /v1/orders/:order_id/invoice": {Summary: "Загрузить счет заказа", Upload: "file",
+		Query:  []queryParam{{Name: "client_id", Type: "integer", Required: true}},
+		Status: http.StatusCreated, Response: domain.Order{}},
+	"GET /v1/orders/:order_id/invoice": {Summary: "Скачать счет заказа", Binary: true,
+		Query: []queryParam{{Name: "client_id", Type: "integer", Required: true}}},
+
+	"GET /v1/order-types": {Summary: "Справочник типов заказов",
+		Response: struct {
+			OrderTypes []*domain.OrderType `json:"order_types"`
+		}{}},
+	"GET /v1/order-types/:order_type_id": {Summary: "Получить тип заказа", Response: domain.OrderType{}},
+	"POST /v1/order-types": {Summary: "Создать тип заказа", Request: domain.OrderType{},
+		Status: http.StatusCreated, Response: domain.OrderType{}},
+	"PUT /v1/order-types/:order_type_id": {Summary: "Изменить тип заказа", Request: domain.OrderType{},
+		Response: domain.OrderType{}},
+	"DELETE /v1/order-types/:order_type_id": {Summary: "Удалить тип заказа", Response: messageResponse{}},
+
+	"GET /v1/clients": {Summary: "Клиенты",
+		Query: []queryParam{
+			{Name: "page", Type: "integer"},
+			{Name: "limit", Type: "integer"},
+		},
+		Response: domain.ClientList{}},
+	"GET /v1/clients/:client_id": {Summary: "Получить клиента", Response: domain.Client{}},
+	"POST /v1/clients": {Summary: "Добавить клиента", Request: domain.Client{},
+		Status: http.StatusCreated, Response: domain.Client{}},
+	"PUT /v1/clients/:client_id": {Summary: "Изменить клиента", Request: domain.Client{},
+		Response: domain.Client{}},
+	"DELETE /v1/clients/:client_id": {Summary: "Удалить клиента", Response: messageResponse{}},
+
+	"GET /v1/dealerships": {Summary: "Справочник дилерских центров",
+		Query: []queryParam{{Name: "active", Type: "boolean"}},
+		Response: struct {
+			Dealerships []*domain.Dealership `json:"dealerships"`
+		}{}},
+	"GET /v1/dealerships/:dealership_id": {Summary: "Получить дилерский центр", Response: domain.Dealership{}},
+	"POST /v1/dealerships": {Summary: "Добавить дилерский центр", Request: domain.Dealership{},
+		Status: http.StatusCreated, Response: domain.Dealership{}},
+	"PUT /v1/dealerships/:dealership_id": {Summary: "Изменить дилерский центр", Request: domain.Dealership{},
+		Response: domain.Dealership{}},
+	"DELETE /v1/dealerships/:dealership_id": {Summary: "Удалить дилерский центр", Response: messageResponse{}},
+
+	"GET /v1/managers": {Summary: "Менеджеры дилерских центров",
+		Query: []queryParam{{Name: "dealership_id", Type: "integer"}, {Name: "active", Type: "boolean"}},
+		Response: struct {
+			Managers []*domain.Manager `json:"managers"`
+		}{}},
+	"GET /v1/managers/:manager_id": {Summary: "Получить менеджера", Response: domain.Manager{}},
+	"POST /v1/managers": {Summary: "Добавить менеджера", Request: domain.Manager{},
+		Status: http.StatusCreated, Response: domain.Manager{}},
+	"PUT /v1/managers/:manager_id": {Summary: "Изменить менеджера", Request: domain.Manager{},
+		Response: domain.Manager{}},
+	"DELETE /v1/managers/:manager_id": {Summary: "Удалить менеджера", Response: messageResponse{}},
+
+	"GET /v1/vehicles": {Summary: "Автомобили сделок",
+		Query: []queryParam{{Name: "condition", Type: "string"}},
+		Response: struct {
+			Vehicles []*domain.Vehicle `json:"vehicles"`
+		}{}},
+	"GET /v1/vehicles/:vehicle_id": {Summary: "Получить автомобиль", Response: domain.Vehicle{}},
+	"POST /v1/vehicles": {Summary: "Добавить автомобиль", Request: domain.Vehicle{},
+		Status: http.StatusCreated, Response: domain.Vehicle{}},
+	"PUT /v1/vehicles/:vehicle_id": {Summary: "Изменить автомобиль", Request: domain.Vehicle{},
+		Response: domain.Vehicle{}},
+	"DELETE /v1/vehicles/:vehicle_id": {Summary: "Удалить автомобиль", Response: messageResponse{}},
+
+	"GET /v1/credit-contracts": {Summary: "Кредитные договоры сделки",
+		Query: []queryParam{{Name: "deal_id", Type: "integer", Required: true}},
+		Response: struct {
+			CreditContracts []*domain.CreditContract `json:"credit_contracts"`
+		}{}},
+	"GET /v1/credit-contracts/:credit_contract_id": {Summary: "Получить кредитный договор",
+		Response: domain.CreditContract{}},
+	"POST /v1/credit-contracts": {Summary: "Добавить кредитный договор", Request: domain.CreditContract{},
+		Status: http.StatusCreated, Response: domain.CreditContract{}},
+	"PUT /v1/credit-contracts/:credit_contract_id": {Summary: "Изменить кредитный договор",
+		Request: domain.CreditContract{}, Response: domain.CreditContract{}},
+	"DELETE /v1/credit-contracts/:credit_contract_id": {Summary: "Удалить кредитный договор",
+		Response: messageResponse{}},
+
+	"GET /v1/trade-in-appraisals": {Summary: "Оценки автомобилей в трейд-ин",
+		Query: []queryParam{{Name: "deal_id", Type: "integer", Required: true}},
+		Response: struct {
+			Appraisals []*domain.TradeInAppraisal `json:"appraisals"`
+		}{}},
+	"GET /v1/trade-in-appraisals/:appraisal_id": {Summary: "Получить оценку", Response: domain.TradeInAppraisal{}},
+	"POST /v1/trade-in-appraisals": {Summary: "Добавить оценку", Request: domain.TradeInAppraisal{},
+		Status: http.StatusCreated, Response: domain.TradeInAppraisal{}},
+	"PUT /v1/trade-in-appraisals/:appraisal_id": {Summary: "Изменить оценку", Request: domain.TradeInAppraisal{},
+		Response: domain.TradeInAppraisal{}},
+	"POST /v1/trade-in-appraisals/:appraisal_id/approve": {Summary: "Одобрить оценку",
+		Request: domain.AppraisalReview{}, Response: domain.TradeInAppraisal{}},
+	"POST /v1/trade-in-appraisals/:appraisal_id/reject": {Summary: "Отклонить оценку",
+		Request: domain.AppraisalReview{}, Response: domain.TradeInAppraisal{}},
+	"DELETE /v1/trade-in-appraisals/:appraisal_id": {Summary: "Удалить оценку", Response: messageResponse{}},
+
+	"GET /v1/banks": {Summary: "Справочник банков",
+		Query: []queryParam{{Name: "active", Type: "boolean"}},
+		Response: struct {
+			Banks []*domain.Bank `json:"banks"`
+		}{}},
+	"GET /v1/banks/:bank_id": {Summary: "Получить банк", Response: domain.Bank{}},
+	"POST /v1/banks": {Summary: "Добавить банк", Request: domain.Bank{},
+		Status: http.StatusCreated, Response: domain.Bank{}},
+	"PUT /v1/banks/:bank_id": {Summary: "Изменить банк", Request: domain.Bank{},
+		Response: domain.Bank{}},
+	"DELETE /v1/banks/:bank_id": {Summary: "Удалить банк", Response: messageResponse{}},
+
+	"GET /v1/monetary-settlements": {Summary: "Денежные расчеты сделки",
+		Query: []queryParam{
+			{Name: "deal_id", Type: "integer", Required: true},
+			{Name: "as_of", Type: "string", Format: "date-time"},
+			{Name: "status", Type: "string"},
+			{Name: "settlement_mode", Type: "string"},
+			{Name: "page", Type: "integer"},
+			{Name: "limit", Type: "integer"},
+		},
+		// page, limit, total и has_next возвращаются только для сохраненных расчетов (задан status)
+		Response: domain.MonetarySettlementList{}},
+	"POST /v1/monetary-settlements/execute": {Summary: "Исполнить денежные расчеты сделки",
+		Query: []queryParam{{Name: "deal_id", Type: "integer", Required: true}},
+		Response: struct {
+			Settlements []*domain.MonetarySettlement `json:"settlements"`
+		}{}},
+	"POST /v1/monetary-settlements/:monetary_settlement_id/execute": {Summary: "Исполнить зафиксированный денежный расчет",
+		Request: domain.MonetarySettlementExecution{}, Response: domain.MonetarySettlement{}},
+	"POST /v1/monetary-settlements/:monetary_settlement_id/cancel": {Summary: "Отменить денежный расчет",
+		Request: domain.MonetarySettlementCancellation{}, Response: domain.MonetarySettlement{}},
+	"POST /v1/monetary-settlements/:monetary_settlement_id/approve": {Summary: "Одобрить крупный денежный расчет",
+		Request: domain.MonetarySettlementReview{}, Response: domain.MonetarySettlement{}},
+	"POST /v1/monetary-settlements/:monetary_settlement_id/reject": {Summary: "Отклонить крупный денежный расчет",
+		Request: domain.MonetarySettlementReview{}, Response: domain.MonetarySettlement{}},
+	"POST /v1/monetary-settlements/:monetary_settlement_id/payments": {Summary: "Зарегистрировать частичную оплату расчета",
+		Request: domain.SettlementPaymentCreate{}, Response: domain.MonetarySettlement{}, Status: http.StatusCreated},
+	"GET /v1/participants/:participant_id/balance": {Summary: "Позиция участника по всем сделкам",
+		Response: domain.ParticipantBalance{}},
+	"GET /v1/monetary-settlements/:monetary_settlement_id/instructions": {Summary: "Платежные поручения по исполненному расчету",
+		Response: struct {
+			Instructions []*domain.PaymentInstruction `json:"instructions"`
+		}{}},
+
+	"GET /v1/clearing": {Summary: "Клиринг по всем открытым сделкам клиента или дилерского центра",
+		Query: []queryParam{
+			{Name: "client_id", Type: "integer"},
+			{Name: "dealership_id", Type: "integer"},
+		},
+		Response: domain.ClearingCycle{}},
+	"GET /v1/clearing-sessions": {Summary: "Клиринговые сессии",
+		Query: []queryParam{
+			{Name: "status", Type: "string"},
+			{Name: "page", Type: "integer"},
+			{Name: "limit", Type: "integer"},
+		},
+		Response: domain.ClearingSessionList{}},
+	"GET /v1/clearing-sessions/:session_id":          {Summary: "Клиринговая сессия с ее расчетами", Response: domain.ClearingSession{}},
+	"POST /v1/clearing-sessions/:session_id/compute": {Summary: "Рассчитать клиринговую сессию", Response: domain.ClearingSession{}},
+	"POST /v1/clearing-sessions/:session_id/close":   {Summary: "Закрыть клиринговую сессию", Response: domain.ClearingSession{}},
+
+	"POST /v1/bank-statements": {Summary: "Загрузить банковскую выписку и сверить платежи с расчетами",
+		Request: domain.BankStatementImport{}, Response: domain.BankStatementReconciliation{}},
+	"POST /v1/bank-statements/files": {Summary: "Загрузить файл банковской выписки (camt.053 или MT940)", Upload: "file",
+		Query:    []queryParam{{Name: "format", Type: "string"}},
+		Response: domain.BankStatementReconciliation{}},
+	"POST /v1/payments/confirmations": {Summary: "Принять подтверждение платежа от банка",
+		Request: domain.PaymentConfirmationCreate{}, Response: domain.PaymentConfirmation{}, Status: http.StatusCreated,
+		APIKey: true},
+
+	"POST /v1/webhooks": {Summary: "Подписаться на события денежных расчетов",
+		Request: domain.WebhookSubscription{}, Status: http.StatusCreated, Response: domain.WebhookSubscription{}},
+	"GET /v1/webhooks": {Summary: "Подписки на события денежных расчетов",
+		Response: struct {
+			Subscriptions []*domain.WebhookSubscription `json:"subscriptions"`
+		}{}},
+	"DELETE /v1/webhooks/:subscription_id": {Summary: "Удалить подписку на события", Response: messageResponse{}},
+
+	"GET /v1/changes": {Summary: "Лента изменений сущности",
+		Query: []queryParam{
+			{Name: "entity", Type: "string", Required: true},
+			{Name: "since", Type: "integer", Format: "int64"},
+			{Name: "limit", Type: "integer"},
+		},
+		Response: domain.ChangeFeed{}},
+	"GET /v1/fx-rates": {Summary: "Курсы валют на дату",
+		Query: []queryParam{{Name: "date", Type: "string", Format: "date"}},
+		Response: struct {
+			Date  string           `json:"date"`
+			Rates []*domain.FXRate `json:"rates"`
+		}{}},
+	"GET /v1/reports/inter-branch": {Summary: "Отчет по межфилиальным расчетам",
+		Query: []queryParam{
+			{Name: "from", Type: "string", Format: "date", Required: true},
+			{Name: "to", Type: "string", Format: "date", Required: true},
+		},
+		Response: domain.InterBranchReport{}},
+	"GET /v1/reports/settlements": {Summary: "Отчет по денежным расчетам дилерского центра",
+		Query: []queryParam{
+			{Name: "dealership_id", Type: "integer", Required: true},
+			{Name: "from", Type: "string", Format: "date", Required: true},
+			{Name: "to", Type: "string", Format: "date", Required: true},
+		},
+		Response: domain.SettlementReport{}},
+
+	"POST /v1/simulations": {Summary: "Смоделировать неттинг гипотетических заказов",
+		Request: domain.SimulationRequest{}, Response: domain.SimulationResult{}},
+
+	"GET /v1/admin/retention/upcoming": {Summary: "Записи, которые будут очищены по сроку хранения",
+		Query: []queryParam{{Name: "days", Type: "integer"}},
+		Response: struct {
+			Purges []*domain.UpcomingPurge `json:"purges"`
+			Total  int                     `json:"total"`
+		}{}},
+	"POST /v1/admin/retention/purge": {Summary: "Очистить записи с истекшим сроком хранения",
+		Response: domain.PurgeResult{}},
+	"POST /v1/admin/clients/:client_id/anonymize": {Summary: "Обезличить персональные данные клиента",
+		Request: domain.AnonymizationRequest{}, Response: domain.AnonymizationResult{}},
+	"GET /v1/admin/calendar": {Summary: "Производственный календарь",
+		Query: []queryParam{{Name: "year", Type: "integer"}},
+		Response: struct {
+			Days []*domain.CalendarDay `json:"days"`
+		}{}},
+	"POST /v1/admin/calendar": {Summary: "Добавить день календаря", Request: domain.CalendarDay{},
+		Status: http.StatusCreated, Response: domain.CalendarDay{}},
+	"PUT /v1/admin/calendar/:date": {Summary: "Изменить день календаря", Request: domain.CalendarDay{},
+		Response: domain.CalendarDay{}},
+	"DELETE /v1/admin/calendar/:date": {Summary: "Удалить день календаря", Response: messageResponse{}},
+	"GET /v1/admin/fx-rates": {Summary: "Курсы валют к валюте неттинга",
+		Query: []queryParam{{Name: "currency_code", Type: "string"}},
+		Response: struct {
+			Rates []*domain.FXRate `json:"rates"`
+		}{}},
+	"POST /v1/admin/fx-rates": {Summary: "Добавить версию курса валюты", Request: domain.FXRate{},
+		Status: http.StatusCreated, Response: domain.FXRate{}},
+	"GET /v1/admin/netting-rules": {Summary: "Правила неттинга с периодом действия",
+		Query: []queryParam{{Name: "order_type_id", Type: "integer"}},
+		Response: struct {
+			Rules []*domain.NettingRuleConfig `json:"rules"`
+		}{}},
+	"POST /v1/admin/netting-rules": {Summary: "Добавить правило неттинга", Request: domain.NettingRuleConfig{},
+		Status: http.StatusCreated, Response: domain.NettingRuleConfig{}},
+	"PUT /v1/admin/netting-rules/:rule_id": {Summary: "Изменить правило неттинга", Request: domain.NettingRuleConfig{},
+		Response: domain.NettingRuleConfig{}},
+	"DELETE /v1/admin/netting-rules/:rule_id": {Summary: "Удалить правило неттинга", Response: messageResponse{}},
+	"GET /v1/admin/obligation-triggers": {Summary: "Триггеры комиссий и договорной неустойки",
+		Response: struct {
+			Triggers []*domain.ObligationTrigger `json:"triggers"`
+		}{}},
+	"POST /v1/admin/obligation-triggers": {Summary: "Добавить триггер", Request: domain.ObligationTrigger{},
+		Status: http.StatusCreated, Response: domain.ObligationTrigger{}},
+	"PUT /v1/admin/obligation-triggers/:trigger_id": {Summary: "Изменить триггер", Request: domain.ObligationTrigger{},
+		Response: domain.ObligationTrigger{}},
+	"DELETE /v1/admin/obligation-triggers/:trigger_id": {Summary: "Удалить триггер", Response: messageResponse{}},
+	"GET /v1/admin/order-reviews": {Summary: "Очередь антифрод-проверки заказов",
+		Query: []queryParam{{Name: "status", Type: "string"}},
+		Response: struct {
+			Reviews []*domain.OrderReview `json:"reviews"`
+			Total   int                   `json:"total"`
+		}{}},
+	"POST /v1/admin/order-reviews/:review_id/approve": {Summary: "Одобрить помеченный заказ",
+		Response: domain.OrderReview{}},
+	"POST /v1/admin/order-reviews/:review_id/reject": {Summary: "Отклонить помеченный заказ",
+		Response: domain.OrderReview{}},
+	"GET /v1/admin/integrations/status": {Summary: "Состояние внешних интеграций",
+		Response: struct {
+			Integrations []*domain.IntegrationStatus `json:"integrations"`
+		}{}},
+	"POST /v1/auth/revoke": {Summary: "Отозвать токен",
+		Request: domain.TokenRevocation{}, Response: domain.TokenRevocation{}},
+	"GET /v1/admin/audit-log": {Summary: "Журнал аудита",
+		Query: []queryParam{
+			{Name: "actor", Type: "string"},
+			{Name: "entity", Type: "string"},
+			{Name: "entity_id", Type: "string"},
+			{Name: "method", Type: "string"},
+			{Name: "impersonated", Type: "boolean"},
+			{Name: "from", Type: "string", Format: "date-time"},
+			{Name: "to", Type: "string", Format: "date-time"},
+			{Name: "page", Type: "integer"},
+			{Name: "limit", Type: "integer"},
+		},
+		Response: domain.AuditList{}},
+	"POST /v1/admin/tenants": {Summary: "Создать арендатора", Request: domain.Tenant{}, Status: http.StatusCreated,
+		Response: domain.Tenant{}},
+	"GET /v1/admin/tenants": {Summary: "Список арендаторов",
+		Response: struct {
+			Tenants []*domain.Tenant `json:"tenants"`
+		}{}},
+}
+
+// openAPIBuilder collects the OpenAPI document and the component schemas referenced from it.
+type openAPIBuilder struct {
+	schemas map[string]any
+}
+
+// buildOpenAPI generates the OpenAPI document of the registered routes.
+func buildOpenAPI(routes gin.RoutesInfo) map[string]any {
+	b := &openAPIBuilder{schemas: map[string]any{}}
+	errorSchema := b.schema(reflect.TypeOf(domain.ErrorResponse{}))
+
+	paths := map[string]any{}
+	for _, route := range routes {
+		doc := routeDocs[route.Method+" "+route.Path]
+
+		path, parameters := openAPIPathParams(route.Path)
+		for _, param := range doc.Query {
+			schema := map[string]any{"type": param.Type}
+			if param.Format != "" {
+				schema["format"] = param.Format
+			}
+			parameters = append(parameters, map[string]any{
+				"name":     param.Name,
+				"in":       "query",
+				"required": param.Required,
+				"schema":   schema,
+			})
+		}
+
+		operation := map[string]any{
+			"operationId": operationID(route.Handler),
+			"summary":     doc.Summary,
+			"responses":   b.responses(doc, errorSchema),
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		// Область доступа, нужная токену интеграции для вызова маршрута
+		if scope := routeScopes[route.Method+" "+route.Path]; scope != "" {
+			operation["x-scope"] = scope
+		}
+		if doc.APIKey {
+			operation["security"] = []any{map[string]any{"ApiKeyAuth": []string{}}}
+		} else if strings.HasPrefix(route.Path, "/v1/") {
+			operation["security"] = []any{map[string]any{"BearerAuth": []string{}}}
+		}
+		switch {
+		case doc.Request != nil:
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": b.schema(reflect.TypeOf(doc.Request))},
+				},
+			}
+		case doc.Upload != "":
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"multipart/form-data": map[string]any{"schema": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{doc.Upload: map[string]any{"type": "string", "format": "binary"}},
+						"required":   []string{doc.Upload},
+					}},
+				},
+			}
+		}
+
+		item, _ := paths[path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "API Модуля Клиринга",
+			"description": "API для управления сделками, заказами и денежными расчетами.",
+			"version":     "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": b.schemas,
+			"securitySchemes": map[string]any{
+				"BearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"ApiKeyAuth": map[string]any{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			},
+		},
+	}
+}
+
+// responses builds the responses of an operation: the documented success response and the error response.
+func (b *openAPIBuilder) responses(doc routeDoc, errorSchema any) map[string]any {
+	status := doc.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := map[string]any{"description": http.StatusText(status)}
+	switch {
+	case doc.Binary:
+		success["content"] = map[string]any{
+			"application/octet-stream": map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}},
+		}
+	case doc.Response != nil:
+		success["content"] = map[string]any{
+			"application/json": map[string]any{"schema": b.schema(reflect.TypeOf(doc.Response))},
+		}
+	}
+
+	return map[string]any{
+		strconv.Itoa(status): success,
+		"default": map[string]any{
+			"description": "Ошибка",
+			"content":     map[string]any{"application/json": map[string]any{"schema": errorSchema}},
+		},
+	}
+}
+
+// operationID derives the operation ID from the handler method name,
+// e.g. "cliring/internal/transport.(*Handler).listOrders-fm" becomes "listOrders".
+func operationID(handler string) string {
+	name := handler[strings.LastIndex(handler, ".")+1:]
+	return strings.TrimSuffix(name, "-fm")
+}
+
+// openAPIPathParams converts gin path parameters (":deal_id") into OpenAPI ones ("{deal_id}").
+// Parameters named *_id are integers, others strings.
+func openAPIPathParams(path string) (string, []any) {
+	segments := strings.Split(path, "/")
+	var parameters []any
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+
+		schema := map[string]any{"type": "string"}
+		if strings.HasSuffix(name, "_id") {
+			schema["type"] = "integer"
+		}
+		parameters = append(parameters, map[string]any{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   schema,
+		})
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	moneyType = reflect.TypeOf(domain.Money(0))
+)
+
+// schema returns the JSON schema of a Go type. Named structs are added to the components
+// and referenced.
+func (b *openAPIBuilder) schema(t reflect.Type) map[string]any {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var schema map[string]any
+	switch {
+	case t == timeType:
+		schema = map[string]any{"type": "string", "format": "date-time"}
+	case t == moneyType:
+		// Сумма хранится в копейках, но в JSON передается числом с двумя знаками после запятой
+		schema = map[string]any{"type": "number", "multipleOf": 0.01}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		if _, ok := b.schemas[t.Name()]; !ok {
+			// Заглушка до построения защищает от бесконечной рекурсии на ссылающихся друг на друга типах
+			b.schemas[t.Name()] = map[string]any{}
+			b.schemas[t.Name()] = b.structSchema(t)
+		}
+		ref := map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+		if nullable {
+			return map[string]any{"allOf": []any{ref}, "nullable": true}
+		}
+		return ref
+	case t.Kind() == reflect.Struct:
+		schema = b.structSchema(t)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		schema = map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		schema = map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case t.Kind() == reflect.String:
+		schema = map[string]any{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		schema = map[string]any{"type": "boolean"}
+	case t.Kind() == reflect.Int32:
+		schema = map[string]any{"type": "integer", "format": "int32"}
+	case t.Kind() == reflect.Int64:
+		schema = map[string]any{"type": "integer", "format": "int64"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		schema = map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		schema = map[string]any{"type": "number"}
+	default:
+		schema = map[string]any{}
+	}
+	if nullable {
+		schema["nullable"] = true
+	}
+	return schema
+}
+
+// structSchema builds the object schema of a struct from its JSON field tags.
+func (b *openAPIBuilder) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = b.schema(field.Type)
+	}
+	return map[string]any{"type": "object", "properties": properties}
+}
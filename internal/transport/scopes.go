@@ -0,0 +1,221 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"cliring/internal/domain"
+)
+
+// Scopes of tokens (JWT scope claim). A token with scopes reaches only the routes its scopes allow,
+// so that fine-grained tokens can be issued for partner integrations; roles are checked as well.
+const (
+	scopeDealsRead          = "deals:read"
+	scopeDealsWrite         = "deals:write"
+	scopeOrdersRead         = "orders:read"
+	scopeOrdersWrite        = "orders:write"
+	scopeCatalogRead        = "catalog:read"
+	scopeCatalogWrite       = "catalog:write"
+	scopeClientsRead        = "clients:read"
+	scopeClientsWrite       = "clients:write"
+	scopeSettlementsRead    = "settlements:read"
+	scopeSettlementsWrite   = "settlements:write"
+	scopeSettlementsExecute = "settlements:execute"
+	scopeClearingRead       = "clearing:read"
+	scopeClearingWrite      = "clearing:write"
+	scopeWebhooksManage     = "webhooks:manage"
+	scopeReportsRead        = "reports:read"
+	scopeAdmin              = "admin"
+)
+
+// routeScopes maps routes (method and path template) to the scope a token needs to call them.
+// Routes with an empty scope are reachable with any token; routes missing here are not reachable
+// with scoped tokens.
+var routeScopes = map[string]string{
+	"POST /v1/auth/revoke": "",
+
+	"POST /v1/deals":                                   scopeDealsWrite,
+	"PATCH /v1/deals/:deal_id":                         scopeDealsWrite,
+	"POST /v1/deals/:deal_id/transition":               scopeDealsWrite,
+	"GET /v1/deals/:deal_id/history":                   scopeDealsRead,
+	"GET /v1/deals/:deal_id/netting-snapshots":         scopeDealsRead,
+	"GET /v1/deals/:deal_id/penalties":                 scopeDealsRead,
+	"GET /v1/deals/:deal_id/netting/explain":           scopeDealsRead,
+	"GET /v1/deals/:deal_id/netting/act":               scopeDealsRead,
+	"GET /v1/deals/:deal_id/payment-files":             scopeDealsRead,
+	"GET /v1/deals/:deal_id/full":                      scopeDealsRead,
+	"GET /v1/deals/:deal_id/forecast":                  scopeDealsRead,
+	"POST /v1/deals/:deal_id/documents":                scopeDealsWrite,
+	"GET /v1/deals/:deal_id/documents":                 scopeDealsRead,
+	"GET /v1/deals/:deal_id/documents/:document_id":    scopeDealsRead,
+	"DELETE /v1/deals/:deal_id/documents/:document_id": scopeDealsWrite,
+	"DELETE /v1/deals/:deal_id":                        scopeDealsWrite,
+	"POST /v1/deals/:deal_id/restore":                  scopeDealsWrite,
+
+	"GET /v1/orders":                    scopeOrdersRead,
+	"POST /v1/orders":                   scopeOrdersWrite,
+	"GET /v1/orders/:order_id":          scopeOrdersRead,
+	"PUT /v1/orders/:order_id":          scopeOrdersWrite,
+	"PATCH /v1/orders/:order_id":        scopeOrdersWrite,
+	"POST /v1/orders/:order_id/status":  scopeOrdersWrite,
+	"DELETE /v1/orders/:order_id":       scopeOrdersWrite,
+	"GET /v1/orders/:order_id/history":  scopeOrdersRead,
+	"POST /v1/orders/:order_id/invoice": scopeOrdersWrite,
+	"GET /v1/orders/:order_id/invoice":  scopeOrdersRead,
+
+	"GET /v1/order-types":                   scopeCatalogRead,
+	"GET /v1/order-types/:order_type_id":    scopeCatalogRead,
+	"POST /v1/order-types":                  scopeCatalogWrite,
+	"PUT /v1/order-types/:order_type_id":    scopeCatalogWrite,
+	"DELETE /v1/order-types/:order_type_id": scopeCatalogWrite,
+
+	"GET /v1/clients":               scopeClientsRead,
+	"GET /v1/clients/:client_id":    scopeClientsRead,
+	"POST /v1/clients":              scopeClientsWrite,
+	"PUT /v1/clients/:client_id":    scopeClientsWrite,
+	"DELETE /v1/clients/:client_id": scopeClientsWrite,
+
+	"GET /v1/dealerships":                   scopeCatalogRead,
+	"GET /v1/dealerships/:dealership_id":    scopeCatalogRead,
+	"POST /v1/dealerships":                  scopeCatalogWrite,
+	"PUT /v1/dealerships/:dealership_id":    scopeCatalogWrite,
+	"DELETE /v1/dealerships/:dealership_id": scopeCatalogWrite,
+
+	"GET /v1/managers":                scopeCatalogRead,
+	"GET /v1/managers/:manager_id":    scopeCatalogRead,
+	"POST /v1/managers":               scopeCatalogWrite,
+	"PUT /v1/managers/:manager_id":    scopeCatalogWrite,
+	"DELETE /v1/managers/:manager_id": scopeCatalogWrite,
+
+	"GET /v1/vehicles":                scopeCatalogRead,
+	"GET /v1/vehicles/:vehicle_id":    scopeCatalogRead,
+	"POST /v1/vehicles":               scopeCatalogWrite,
+	"PUT /v1/vehicles/:vehicle_id":    scopeCatalogWrite,
+	"DELETE /v1/vehicles/:vehicle_id": scopeCatalogWrite,
+
+	"GET /v1/credit-contracts":                        scopeDealsRead,
+	"GET /v1/credit-contracts/:credit_contract_id":    scopeDealsRead,
+	"POST /v1/credit-contracts":                       scopeDealsWrite,
+	"PUT /v1/credit-contracts/:credit_contract_id":    scopeDealsWrite,
+	"DELETE /v1/credit-contracts/:credit_contract_id": scopeDealsWrite,
+
+	"GET /v1/trade-in-appraisals":                        scopeDealsRead,
+	"GET /v1/trade-in-appraisals/:appraisal_id":          scopeDealsRead,
+	"POST /v1/trade-in-appraisals":                       scopeDealsWrite,
+	"PUT /v1/trade-in-appraisals/:appraisal_id":          scopeDealsWrite,
+	"POST /v1/trade-in-appraisals/:appraisal_id/approve": scopeDealsWrite,
+	"POST /v1/trade-in-appraisals/:appraisal_id/reject":  scopeDealsWrite,
+	"DELETE /v1/trade-in-appraisals/:appraisal_id":       scopeDealsWrite,
+
+	"GET /v1/banks":             scopeCatalogRead,
+	"GET /v1/banks/:bank_id":    scopeCatalogRead,
+	"POST /v1/banks":            scopeCatalogWrite,
+	"PUT /v1/banks/:bank_id":    scopeCatalogWrite,
+	"DELETE /v1/banks/:bank_id": scopeCatalogWrite,
+
+	"GET /v1/monetary-settlements":                                      scopeSettlementsRead,
+	"POST /v1/monetary-settlements/execute":                             scopeSettlementsExecute,
+	"POST /v1/monetary-settlements/:monetary_settlement_id/execute":     scopeSettlementsExecute,
+	"POST /v1/monetary-settlements/:monetary_settlement_id/cancel":      scopeSettlementsWrite,
+	"POST /v1/monetary-settlements/:monetary_settlement_id/approve":     scopeSettlementsWrite,
+	"POST /v1/monetary-settlements/:monetary_settlement_id/reject":      scopeSettlementsWrite,
+	"POST /v1/monetary-settlements/:monetary_settlement_id/payments":    scopeSettlementsExecute,
+	"GET /v1/monetary-settlements/:monetary_settlement_id/instructions": scopeSettlementsRead,
+
+	"GET /v1/participants/:participant_id/balance": scopeClearingRead,
+
+	"GET /v1/clearing": scopeClearingRead,
+
+	"GET /v1/clearing-sessions":                      scopeClearingRead,
+	"GET /v1/clearing-sessions/:session_id":          scopeClearingRead,
+	"POST /v1/clearing-sessions/:session_id/compute": scopeClearingWrite,
+	"POST /v1/clearing-sessions/:session_id/close":   scopeClearingWrite,
+
+	"POST /v1/bank-statements":       scopeSettlementsExecute,
+	"POST /v1/bank-statements/files": scopeSettlementsExecute,
+
+	"POST /v1/webhooks":                    scopeWebhooksManage,
+	"GET /v1/webhooks":                     scopeWebhooksManage,
+	"DELETE /v1/webhooks/:subscription_id": scopeWebhooksManage,
+
+	"POST /v1/simulations": scopeClearingRead,
+
+	"GET /v1/changes": scopeClearingRead,
+
+	"GET /v1/fx-rates": scopeClearingRead,
+
+	"GET /v1/reports/inter-branch": scopeReportsRead,
+	"GET /v1/reports/settlements":  scopeReportsRead,
+
+	"GET /v1/admin/retention/upcoming":                 scopeAdmin,
+	"POST /v1/admin/retention/purge":                   scopeAdmin,
+	"POST /v1/admin/clients/:client_id/anonymize":      scopeAdmin,
+	"GET /v1/admin/calendar":                           scopeAdmin,
+	"POST /v1/admin/calendar":                          scopeAdmin,
+	"PUT /v1/admin/calendar/:date":                     scopeAdmin,
+	"DELETE /v1/admin/calendar/:date":                  scopeAdmin,
+	"GET /v1/admin/fx-rates":                           scopeAdmin,
+	"POST /v1/admin/fx-rates":                          scopeAdmin,
+	"GET /v1/admin/netting-rules":                      scopeAdmin,
+	"POST /v1/admin/netting-rules":                     scopeAdmin,
+	"PUT /v1/admin/netting-rules/:rule_id":             scopeAdmin,
+	"DELETE /v1/admin/netting-rules/:rule_id":          scopeAdmin,
+	"GET /v1/admin/obligation-triggers":                scopeAdmin,
+	"POST /v1/admin/obligation-triggers":               scopeAdmin,
+	"PUT /v1/admin/obligation-triggers/:trigger_id":    scopeAdmin,
+	"DELETE /v1/admin/obligation-triggers/:trigger_id": scopeAdmin,
+	"GET /v1/admin/order-reviews":                      scopeAdmin,
+	"POST /v1/admin/order-reviews/:review_id/approve":  scopeAdmin,
+	"POST /v1/admin/order-reviews/:review_id/reject":   scopeAdmin,
+	"GET /v1/admin/integrations/status":                scopeAdmin,
+	"GET /v1/admin/audit-log":                          scopeAdmin,
+	"POST /v1/admin/tenants":                           scopeAdmin,
+	"GET /v1/admin/tenants":                            scopeAdmin,
+}
+
+// scopeMiddleware checks the scope of the route against the scopes of the token. Tokens without
+// scopes of the service are not limited by scopes.
+func (h *Handler) scopeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, ok := c.Request.Context().Value(domain.ScopesKey{}).([]string)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		scope, mapped := routeScopes[c.Request.Method+" "+c.FullPath()]
+		if !mapped || (scope != "" && !slices.Contains(scopes, scope)) {
+			if !mapped {
+				scope = "unavailable"
+			}
+			h.errorResponse(c, http.StatusForbidden, "ERR_INSUFFICIENT_SCOPE", "Scope "+scope+" is required")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// withScopes puts the scopes of the token into the context if the token has any scope of the service.
+// OpenID Connect providers put their own scopes (openid, profile) into the claim; such tokens keep full access.
+func withScopes(ctx context.Context, scopes []string) context.Context {
+	for _, scope := range scopes {
+		if knownScope(scope) {
+			return context.WithValue(ctx, domain.ScopesKey{}, scopes)
+		}
+	}
+	return ctx
+}
+
+// knownScope reports whether a scope is checked by some route.
+func knownScope(scope string) bool {
+	for _, routeScope := range routeScopes {
+		if routeScope != "" && routeScope == scope {
+			return true
+		}
+	}
+	return false
+}
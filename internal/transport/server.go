@@ -2,6 +2,7 @@ package transport
 
 import (
 	"context"
+	"crypto/tls"
 	"net/http"
 	"time"
 )
@@ -22,6 +23,19 @@ func (s *Server) Run(port string, handler http.Handler) error {
 	return s.httpServer.ListenAndServe()
 }
 
+// RunTLS запускает сервер по HTTPS; сертификаты берутся из tlsConfig
+func (s *Server) RunTLS(port string, handler http.Handler, tlsConfig *tls.Config) error {
+	s.httpServer = &http.Server{
+		Addr:           ":" + port,
+		Handler:        handler,
+		TLSConfig:      tlsConfig,
+		MaxHeaderBytes: 1 << 20, // 1 мб
+		ReadTimeout:    10 * time.Second,
+		WriteTimeout:   10 * time.Second,
+	}
+	return s.httpServer.ListenAndServeTLS("", "")
+}
+
 func (s *Server) Shutdown(ctx context.Context) error {
 	return s.httpServer.Shutdown(ctx)
 }
@@ -0,0 +1,32 @@
+create sequence if not exists monetary_settlements_monetary_settlement_id_seq
+    owned by monetary_settlements.monetary_settlement_id;
+
+select setval('monetary_settlements_monetary_settlement_id_seq',
+              coalesce((select max(monetary_settlement_id) from monetary_settlements), 0) + 1, false);
+
+alter table monetary_settlements
+    alter column monetary_settlement_id set default nextval('monetary_settlements_monetary_settlement_id_seq');
+
+create table if not exists order_allocations (
+                                                 allocation_id          serial primary key,
+                                                 monetary_settlement_id integer not null references monetary_settlements,
+                                                 order_id               integer not null references orders,
+                                                 amount                 numeric(15, 2) not null check (amount > 0),
+    created_at             timestamp with time zone default CURRENT_TIMESTAMP
+                                         );
+
+comment on table order_allocations is 'Таблица распределения исполненных денежных взаиморасчетов по заказам';
+comment on column order_allocations.allocation_id is 'Уникальный идентификатор распределения';
+comment on column order_allocations.monetary_settlement_id is 'Идентификатор денежного взаиморасчета';
+comment on column order_allocations.order_id is 'Идентификатор заказа';
+comment on column order_allocations.amount is 'Сумма, отнесенная на заказ';
+comment on column order_allocations.created_at is 'Дата и время создания';
+
+create index if not exists idx_order_allocations_order_id on order_allocations (order_id);
+create index if not exists idx_order_allocations_monetary_settlement_id on order_allocations (monetary_settlement_id);
+
+---- create above / drop below ----
+
+drop table if exists order_allocations cascade;
+alter table monetary_settlements alter column monetary_settlement_id drop default;
+drop sequence if exists monetary_settlements_monetary_settlement_id_seq;
@@ -0,0 +1,29 @@
+create table if not exists currencies (
+                                          currency_code char(3) primary key,
+                                          name          varchar(50) not null,
+                                          minor_unit    smallint    not null default 2 check (minor_unit between 0 and 4),
+                                          symbol        varchar(5)  not null
+);
+
+comment on table currencies is 'Справочник валют';
+comment on column currencies.currency_code is 'Буквенный код валюты ISO 4217';
+comment on column currencies.name is 'Название валюты';
+comment on column currencies.minor_unit is 'Количество знаков дробной части (экспонента минимальной единицы)';
+comment on column currencies.symbol is 'Символ валюты для отображения';
+
+INSERT INTO currencies(currency_code, name, minor_unit, symbol) values ('RUB', 'Российский рубль', 2, '₽');
+INSERT INTO currencies(currency_code, name, minor_unit, symbol) values ('USD', 'Доллар США', 2, '$');
+INSERT INTO currencies(currency_code, name, minor_unit, symbol) values ('EUR', 'Евро', 2, '€');
+INSERT INTO currencies(currency_code, name, minor_unit, symbol) values ('CNY', 'Китайский юань', 2, '¥');
+
+alter table orders add column if not exists currency_code char(3) not null default 'RUB' references currencies;
+alter table monetary_settlements add column if not exists currency_code char(3) not null default 'RUB' references currencies;
+
+comment on column orders.currency_code is 'Код валюты заказа';
+comment on column monetary_settlements.currency_code is 'Код валюты взаиморасчета';
+
+---- create above / drop below ----
+
+alter table monetary_settlements drop column if exists currency_code;
+alter table orders drop column if exists currency_code;
+drop table if exists currencies cascade;
@@ -0,0 +1,17 @@
+alter table deals add column if not exists status varchar(20) not null default 'draft'
+    check (status in ('draft', 'active', 'clearing', 'completed', 'cancelled'));
+
+update deals set status = case when is_completed then 'completed' else 'active' end;
+
+alter table deals drop column if exists is_completed;
+
+comment on column deals.status is 'Статус сделки: draft, active, clearing, completed, cancelled';
+
+create index if not exists idx_deals_status on deals (status);
+
+---- create above / drop below ----
+
+alter table deals add column if not exists is_completed boolean default false;
+update deals set is_completed = (status = 'completed');
+drop index if exists idx_deals_status;
+alter table deals drop column if exists status;
@@ -0,0 +1,26 @@
+alter table deals add column if not exists deleted_at timestamp with time zone;
+alter table deals add column if not exists anonymized_at timestamp with time zone;
+alter table orders add column if not exists deleted_at timestamp with time zone;
+alter table orders add column if not exists anonymized_at timestamp with time zone;
+alter table monetary_settlements add column if not exists deleted_at timestamp with time zone;
+
+comment on column deals.deleted_at is 'Дата и время мягкого удаления';
+comment on column deals.anonymized_at is 'Дата и время обезличивания после истечения срока хранения';
+comment on column orders.deleted_at is 'Дата и время мягкого удаления';
+comment on column orders.anonymized_at is 'Дата и время обезличивания после истечения срока хранения';
+comment on column monetary_settlements.deleted_at is 'Дата и время мягкого удаления';
+
+create index if not exists idx_deals_deleted_at on deals (deleted_at) where deleted_at is not null;
+create index if not exists idx_orders_deleted_at on orders (deleted_at) where deleted_at is not null;
+create index if not exists idx_monetary_settlements_deleted_at on monetary_settlements (deleted_at) where deleted_at is not null;
+
+---- create above / drop below ----
+
+drop index if exists idx_monetary_settlements_deleted_at;
+drop index if exists idx_orders_deleted_at;
+drop index if exists idx_deals_deleted_at;
+alter table monetary_settlements drop column if exists deleted_at;
+alter table orders drop column if exists anonymized_at;
+alter table orders drop column if exists deleted_at;
+alter table deals drop column if exists anonymized_at;
+alter table deals drop column if exists deleted_at;
@@ -0,0 +1,39 @@
+alter table clients add column if not exists anonymized_at timestamp with time zone;
+
+comment on column clients.anonymized_at is 'Дата и время обезличивания персональных данных клиента';
+
+create table if not exists client_anonymization_tokens (
+                                                           token_hash char(64) primary key,
+                                                           client_id  integer not null references clients,
+                                                           expires_at timestamp with time zone not null,
+                                                           used_at    timestamp with time zone,
+                                                           created_at timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table client_anonymization_tokens is 'Токены подтверждения обезличивания клиентов';
+comment on column client_anonymization_tokens.token_hash is 'SHA-256 хеш токена подтверждения';
+comment on column client_anonymization_tokens.client_id is 'Идентификатор клиента';
+comment on column client_anonymization_tokens.expires_at is 'Срок действия токена';
+comment on column client_anonymization_tokens.used_at is 'Дата и время использования токена';
+comment on column client_anonymization_tokens.created_at is 'Дата и время создания';
+
+create table if not exists client_anonymization_audit (
+                                                          audit_id      serial primary key,
+                                                          client_id     integer not null references clients,
+                                                          anonymized_at timestamp with time zone default CURRENT_TIMESTAMP,
+                                                          fields        text[] not null
+);
+
+comment on table client_anonymization_audit is 'Журнал обезличивания персональных данных клиентов (152-ФЗ/GDPR)';
+comment on column client_anonymization_audit.audit_id is 'Уникальный идентификатор записи журнала';
+comment on column client_anonymization_audit.client_id is 'Идентификатор клиента';
+comment on column client_anonymization_audit.anonymized_at is 'Дата и время обезличивания';
+comment on column client_anonymization_audit.fields is 'Обезличенные поля';
+
+create index if not exists idx_client_anonymization_audit_client_id on client_anonymization_audit (client_id);
+
+---- create above / drop below ----
+
+drop table if exists client_anonymization_audit cascade;
+drop table if exists client_anonymization_tokens cascade;
+alter table clients drop column if exists anonymized_at;
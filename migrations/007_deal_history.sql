@@ -0,0 +1,24 @@
+create table if not exists deal_history (
+                                            history_id serial primary key,
+                                            deal_id    integer     not null,
+                                            action     varchar(20) not null,
+                                            changed_by varchar(100) not null,
+                                            changed_at timestamp with time zone default CURRENT_TIMESTAMP,
+                                            old_values jsonb,
+                                            new_values jsonb
+);
+
+comment on table deal_history is 'Журнал изменений сделок';
+comment on column deal_history.history_id is 'Уникальный идентификатор записи журнала';
+comment on column deal_history.deal_id is 'Идентификатор сделки (без внешнего ключа, журнал хранится дольше сделки)';
+comment on column deal_history.action is 'Действие: created, updated, status_changed, deleted';
+comment on column deal_history.changed_by is 'Кто выполнил изменение (subject JWT)';
+comment on column deal_history.changed_at is 'Дата и время изменения';
+comment on column deal_history.old_values is 'Значения изменившихся полей до изменения';
+comment on column deal_history.new_values is 'Значения изменившихся полей после изменения';
+
+create index if not exists idx_deal_history_deal_id on deal_history (deal_id);
+
+---- create above / drop below ----
+
+drop table if exists deal_history cascade;
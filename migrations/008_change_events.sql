@@ -0,0 +1,56 @@
+create table if not exists change_events (
+                                             change_id  bigserial primary key,
+                                             entity     varchar(50) not null,
+                                             entity_id  integer     not null,
+                                             action     varchar(10) not null,
+                                             data       jsonb       not null,
+                                             changed_at timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table change_events is 'Лента изменений сущностей (outbox) для инкрементальной синхронизации';
+comment on column change_events.change_id is 'Курсор ленты изменений';
+comment on column change_events.entity is 'Сущность: deals, orders, monetary_settlements';
+comment on column change_events.entity_id is 'Идентификатор записи сущности';
+comment on column change_events.action is 'Действие: insert, update, delete';
+comment on column change_events.data is 'Состояние записи после изменения (до удаления для delete)';
+comment on column change_events.changed_at is 'Дата и время изменения';
+
+create index if not exists idx_change_events_entity_change_id on change_events (entity, change_id);
+
+create or replace function record_change_event() returns trigger as
+$$
+declare
+    row_data jsonb;
+begin
+    if TG_OP = 'DELETE' then
+        row_data := to_jsonb(OLD);
+    else
+        row_data := to_jsonb(NEW);
+    end if;
+
+    insert into change_events (entity, entity_id, action, data)
+    values (TG_TABLE_NAME, (row_data ->> TG_ARGV[0])::integer, lower(TG_OP), row_data);
+
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger deals_change_events
+    after insert or update or delete on deals
+    for each row execute function record_change_event('deal_id');
+
+create trigger orders_change_events
+    after insert or update or delete on orders
+    for each row execute function record_change_event('order_id');
+
+create trigger monetary_settlements_change_events
+    after insert or update or delete on monetary_settlements
+    for each row execute function record_change_event('monetary_settlement_id');
+
+---- create above / drop below ----
+
+drop trigger if exists monetary_settlements_change_events on monetary_settlements;
+drop trigger if exists orders_change_events on orders;
+drop trigger if exists deals_change_events on deals;
+drop function if exists record_change_event();
+drop table if exists change_events cascade;
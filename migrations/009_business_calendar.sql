@@ -0,0 +1,43 @@
+create table if not exists business_calendar (
+                                                 calendar_date  date primary key,
+                                                 name           varchar(100) not null,
+                                                 is_working_day boolean      not null default false,
+                                                 created_at     timestamp with time zone default CURRENT_TIMESTAMP,
+                                                 updated_at     timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table business_calendar is 'Производственный календарь: праздники и перенесенные рабочие дни';
+comment on column business_calendar.calendar_date is 'Дата';
+comment on column business_calendar.name is 'Название (праздник или причина переноса)';
+comment on column business_calendar.is_working_day is 'Рабочий ли день (true для перенесенных рабочих суббот)';
+comment on column business_calendar.created_at is 'Дата и время создания';
+comment on column business_calendar.updated_at is 'Дата и время последнего обновления';
+
+INSERT INTO business_calendar(calendar_date, name) values
+    ('2025-01-01', 'Новогодние каникулы'), ('2025-01-02', 'Новогодние каникулы'),
+    ('2025-01-03', 'Новогодние каникулы'), ('2025-01-06', 'Новогодние каникулы'),
+    ('2025-01-07', 'Рождество Христово'), ('2025-01-08', 'Новогодние каникулы'),
+    ('2025-05-01', 'Праздник Весны и Труда'), ('2025-05-02', 'Перенос выходного дня'),
+    ('2025-05-08', 'Перенос выходного дня'), ('2025-05-09', 'День Победы'),
+    ('2025-06-12', 'День России'), ('2025-06-13', 'Перенос выходного дня'),
+    ('2025-11-03', 'Перенос выходного дня'), ('2025-11-04', 'День народного единства'),
+    ('2025-12-31', 'Перенос выходного дня'),
+    ('2026-01-01', 'Новогодние каникулы'), ('2026-01-02', 'Новогодние каникулы'),
+    ('2026-01-05', 'Новогодние каникулы'), ('2026-01-06', 'Новогодние каникулы'),
+    ('2026-01-07', 'Рождество Христово'), ('2026-01-08', 'Новогодние каникулы'),
+    ('2026-01-09', 'Перенос выходного дня'), ('2026-02-23', 'День защитника Отечества'),
+    ('2026-03-09', 'Перенос выходного дня'), ('2026-05-01', 'Праздник Весны и Труда'),
+    ('2026-05-11', 'Перенос выходного дня'), ('2026-06-12', 'День России'),
+    ('2026-11-04', 'День народного единства'), ('2026-12-31', 'Перенос выходного дня');
+
+INSERT INTO business_calendar(calendar_date, name, is_working_day) values
+    ('2025-11-01', 'Рабочая суббота', true);
+
+alter table monetary_settlements add column if not exists due_date date;
+
+comment on column monetary_settlements.due_date is 'Срок оплаты (с учетом производственного календаря)';
+
+---- create above / drop below ----
+
+alter table monetary_settlements drop column if exists due_date;
+drop table if exists business_calendar cascade;
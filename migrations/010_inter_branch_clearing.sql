@@ -0,0 +1,12 @@
+alter table orders add column if not exists dealership_id integer;
+alter table monetary_settlements add column if not exists dealership_id integer;
+
+comment on column orders.dealership_id is 'Идентификатор дилерского центра, оформившего заказ (по умолчанию - центр сделки)';
+comment on column monetary_settlements.dealership_id is 'Идентификатор дилерского центра - участника взаиморасчета';
+
+UPDATE orders o SET dealership_id = d.dealership_id FROM deals d WHERE d.deal_id = o.deal_id AND o.dealership_id IS NULL;
+
+---- create above / drop below ----
+
+alter table monetary_settlements drop column if exists dealership_id;
+alter table orders drop column if exists dealership_id;
@@ -0,0 +1,23 @@
+create table if not exists vehicle_catalog (
+                                               vehicle_id integer primary key,
+                                               name       varchar(200)   not null,
+                                               list_price numeric(15, 2) not null check (list_price > 0),
+                                               created_at timestamp with time zone default CURRENT_TIMESTAMP,
+                                               updated_at timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table vehicle_catalog is 'Каталог автомобилей с прайсовыми ценами';
+comment on column vehicle_catalog.vehicle_id is 'Уникальный идентификатор автомобиля в каталоге';
+comment on column vehicle_catalog.name is 'Наименование (марка, модель, комплектация)';
+comment on column vehicle_catalog.list_price is 'Прайсовая цена';
+comment on column vehicle_catalog.created_at is 'Дата и время создания';
+comment on column vehicle_catalog.updated_at is 'Дата и время последнего обновления';
+
+alter table orders add column if not exists vehicle_id integer;
+
+comment on column orders.vehicle_id is 'Идентификатор автомобиля в каталоге (для заказов на покупку)';
+
+---- create above / drop below ----
+
+alter table orders drop column if exists vehicle_id;
+drop table if exists vehicle_catalog cascade;
@@ -0,0 +1,28 @@
+create table if not exists deal_documents (
+                                              document_id  serial primary key,
+                                              deal_id      integer      not null references deals,
+                                              file_name    varchar(255) not null,
+                                              content_type varchar(100) not null,
+                                              size_bytes   bigint       not null check (size_bytes >= 0),
+                                              storage_key  varchar(500) not null unique,
+                                              uploaded_by  varchar(100) not null,
+                                              created_at   timestamp with time zone default CURRENT_TIMESTAMP,
+                                              deleted_at   timestamp with time zone
+);
+
+comment on table deal_documents is 'Документы сделки (сканы доверенностей, договоры), файлы хранятся в S3';
+comment on column deal_documents.document_id is 'Уникальный идентификатор документа';
+comment on column deal_documents.deal_id is 'Идентификатор сделки';
+comment on column deal_documents.file_name is 'Исходное имя файла';
+comment on column deal_documents.content_type is 'MIME-тип файла';
+comment on column deal_documents.size_bytes is 'Размер файла в байтах';
+comment on column deal_documents.storage_key is 'Ключ объекта в S3-хранилище';
+comment on column deal_documents.uploaded_by is 'Пользователь, загрузивший документ';
+comment on column deal_documents.created_at is 'Дата и время загрузки';
+comment on column deal_documents.deleted_at is 'Дата и время мягкого удаления';
+
+create index if not exists idx_deal_documents_deal_id on deal_documents (deal_id);
+
+---- create above / drop below ----
+
+drop table if exists deal_documents cascade;
@@ -0,0 +1,11 @@
+alter table deals add column if not exists deletion_scheduled_at timestamp with time zone;
+
+comment on column deals.deletion_scheduled_at is 'Момент окончательного (мягкого) удаления сделки; до него удаление можно отменить';
+
+create index if not exists idx_deals_deletion_scheduled_at on deals (deletion_scheduled_at)
+    where deletion_scheduled_at is not null and deleted_at is null;
+
+---- create above / drop below ----
+
+drop index if exists idx_deals_deletion_scheduled_at;
+alter table deals drop column if exists deletion_scheduled_at;
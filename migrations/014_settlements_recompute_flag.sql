@@ -0,0 +1,7 @@
+alter table deals add column if not exists settlements_recompute_required boolean not null default false;
+
+comment on column deals.settlements_recompute_required is 'Флаг необходимости пересчета денежных расчетов после удаления заказа';
+
+---- create above / drop below ----
+
+alter table deals drop column if exists settlements_recompute_required;
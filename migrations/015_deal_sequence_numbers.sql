@@ -0,0 +1,47 @@
+create table if not exists deal_counters (
+                                             deal_id             integer primary key references deals on delete cascade,
+                                             last_order_number   integer not null default 0,
+                                             last_settlement_run integer not null default 0
+);
+
+comment on table deal_counters is 'Счетчики порядковых номеров в рамках сделки';
+comment on column deal_counters.deal_id is 'Идентификатор сделки';
+comment on column deal_counters.last_order_number is 'Последний выданный номер заказа';
+comment on column deal_counters.last_settlement_run is 'Последний номер запуска исполнения расчетов';
+
+alter table orders add column if not exists order_number integer;
+alter table monetary_settlements add column if not exists run_number integer;
+
+comment on column orders.order_number is 'Порядковый номер заказа в рамках сделки';
+comment on column monetary_settlements.run_number is 'Номер запуска исполнения расчетов в рамках сделки';
+
+-- Нумерация существующих заказов в порядке создания
+update orders o
+set order_number = n.order_number
+from (select order_id, row_number() over (partition by deal_id order by created_at, order_id) as order_number
+      from orders) n
+where o.order_id = n.order_id;
+
+-- Расчеты одного запуска создаются подряд, поэтому запуски восстанавливаем по моменту создания
+update monetary_settlements ms
+set run_number = n.run_number
+from (select monetary_settlement_id,
+             dense_rank() over (partition by deal_id order by date_trunc('second', created_at)) as run_number
+      from monetary_settlements) n
+where ms.monetary_settlement_id = n.monetary_settlement_id;
+
+insert into deal_counters (deal_id, last_order_number, last_settlement_run)
+select d.deal_id,
+       coalesce((select max(order_number) from orders o where o.deal_id = d.deal_id), 0),
+       coalesce((select max(run_number) from monetary_settlements ms where ms.deal_id = d.deal_id), 0)
+from deals d
+on conflict (deal_id) do nothing;
+
+create unique index if not exists idx_orders_deal_id_order_number on orders (deal_id, order_number);
+
+---- create above / drop below ----
+
+drop index if exists idx_orders_deal_id_order_number;
+alter table monetary_settlements drop column if exists run_number;
+alter table orders drop column if exists order_number;
+drop table if exists deal_counters cascade;
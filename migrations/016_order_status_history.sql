@@ -0,0 +1,22 @@
+create table if not exists order_status_history (
+                                                    history_id  serial primary key,
+                                                    order_id    integer      not null,
+                                                    from_status varchar(20)  not null,
+                                                    to_status   varchar(20)  not null,
+                                                    changed_by  varchar(100) not null,
+                                                    changed_at  timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table order_status_history is 'Журнал смены статусов заказов';
+comment on column order_status_history.history_id is 'Уникальный идентификатор записи журнала';
+comment on column order_status_history.order_id is 'Идентификатор заказа (без внешнего ключа, журнал хранится дольше заказа)';
+comment on column order_status_history.from_status is 'Статус до изменения';
+comment on column order_status_history.to_status is 'Статус после изменения';
+comment on column order_status_history.changed_by is 'Кто выполнил изменение (subject JWT)';
+comment on column order_status_history.changed_at is 'Дата и время изменения';
+
+create index if not exists idx_order_status_history_order_id on order_status_history (order_id);
+
+---- create above / drop below ----
+
+drop table if exists order_status_history cascade;
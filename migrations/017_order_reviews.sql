@@ -0,0 +1,33 @@
+alter table orders add column if not exists review_status varchar(20)
+    check (review_status in ('pending', 'approved', 'rejected'));
+
+comment on column orders.review_status is 'Статус антифрод-проверки: pending - заказ не участвует в неттинге до решения; null - заказ не помечен';
+
+create table if not exists order_reviews (
+                                             review_id  serial primary key,
+                                             order_id   integer      not null references orders on delete cascade,
+                                             client_id  integer      not null,
+                                             reasons    text[]       not null,
+                                             status     varchar(20)  not null default 'pending' check (status in ('pending', 'approved', 'rejected')),
+                                             created_at timestamp with time zone default CURRENT_TIMESTAMP,
+                                             decided_by varchar(100),
+                                             decided_at timestamp with time zone
+);
+
+comment on table order_reviews is 'Очередь ручной проверки подозрительных заказов';
+comment on column order_reviews.review_id is 'Уникальный идентификатор проверки';
+comment on column order_reviews.order_id is 'Идентификатор помеченного заказа';
+comment on column order_reviews.client_id is 'Идентификатор клиента сделки';
+comment on column order_reviews.reasons is 'Сработавшие правила с описанием';
+comment on column order_reviews.status is 'Статус проверки: pending, approved, rejected';
+comment on column order_reviews.created_at is 'Дата и время постановки в очередь';
+comment on column order_reviews.decided_by is 'Кто принял решение (subject JWT)';
+comment on column order_reviews.decided_at is 'Дата и время решения';
+
+create index if not exists idx_order_reviews_status on order_reviews (status, created_at);
+create index if not exists idx_order_reviews_order_id on order_reviews (order_id);
+
+---- create above / drop below ----
+
+drop table if exists order_reviews cascade;
+alter table orders drop column if exists review_status;
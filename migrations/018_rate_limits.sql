@@ -0,0 +1,14 @@
+create unlogged table if not exists rate_limits (
+                                                    key          varchar(200) primary key,
+                                                    window_start timestamp with time zone not null,
+                                                    hits         integer                  not null
+);
+
+comment on table rate_limits is 'Счетчики запросов для общего ограничения частоты запросов всех экземпляров сервиса';
+comment on column rate_limits.key is 'Ключ ограничения (subject JWT или адрес клиента)';
+comment on column rate_limits.window_start is 'Начало текущего окна';
+comment on column rate_limits.hits is 'Число запросов в текущем окне';
+
+---- create above / drop below ----
+
+drop table if exists rate_limits;
@@ -0,0 +1,28 @@
+create table if not exists order_revisions (
+                                               revision_id   serial primary key,
+                                               order_id      integer        not null,
+                                               action        varchar(20)    not null,
+                                               deal_id       integer        not null,
+                                               order_type_id integer        not null,
+                                               amount        numeric(15, 2) not null,
+                                               status        varchar(20)    not null,
+                                               changed_by    varchar(100)   not null,
+                                               changed_at    timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table order_revisions is 'Ревизии заказов: значения заказа до каждого изменения, для разбора спорных ситуаций';
+comment on column order_revisions.revision_id is 'Уникальный идентификатор ревизии';
+comment on column order_revisions.order_id is 'Идентификатор заказа (без внешнего ключа, ревизии хранятся дольше заказа)';
+comment on column order_revisions.action is 'Изменение: updated, status_changed, deleted';
+comment on column order_revisions.deal_id is 'Сделка заказа до изменения';
+comment on column order_revisions.order_type_id is 'Тип заказа до изменения';
+comment on column order_revisions.amount is 'Сумма заказа до изменения';
+comment on column order_revisions.status is 'Статус заказа до изменения';
+comment on column order_revisions.changed_by is 'Кто выполнил изменение (subject JWT)';
+comment on column order_revisions.changed_at is 'Дата и время изменения';
+
+create index if not exists idx_order_revisions_order_id on order_revisions (order_id);
+
+---- create above / drop below ----
+
+drop table if exists order_revisions cascade;
@@ -0,0 +1,34 @@
+alter table order_types add column if not exists debtor varchar(20);
+alter table order_types add column if not exists creditor varchar(20);
+alter table order_types add column if not exists created_at timestamp with time zone default CURRENT_TIMESTAMP;
+alter table order_types add column if not exists updated_at timestamp with time zone default CURRENT_TIMESTAMP;
+
+-- Правила неттинга, ранее зашитые в код
+-- Покупка: Клиент должен Дилерскому центру
+update order_types set debtor = 'client', creditor = 'dealership' where order_type_id = 1;
+-- Кредит: Банк должен Клиенту
+-- (задолжность Клиента перед Банком не отображается, так как выходит за рамки сделки)
+-- При этом кредитные средства выделяются именно клиенту, а не Рольфу, так как расчеты Банка с Рольфом также выходят за рамки сделки.
+update order_types set debtor = 'bank', creditor = 'client' where order_type_id = 2;
+-- Трейд-ин: Дилерский центр должен Клиенту
+update order_types set debtor = 'dealership', creditor = 'client' where order_type_id = 3;
+update order_types set debtor = 'client', creditor = 'dealership' where debtor is null;
+
+alter table order_types alter column debtor set not null;
+alter table order_types alter column creditor set not null;
+alter table order_types add constraint order_types_participants_check
+    check (debtor in ('client', 'bank', 'dealership') and creditor in ('client', 'bank', 'dealership')
+        and debtor <> creditor);
+
+comment on column order_types.debtor is 'Участник неттинга, который должен сумму заказа (client, bank, dealership)';
+comment on column order_types.creditor is 'Участник неттинга, которому причитается сумма заказа (client, bank, dealership)';
+comment on column order_types.created_at is 'Дата и время создания';
+comment on column order_types.updated_at is 'Дата и время последнего обновления';
+
+---- create above / drop below ----
+
+alter table order_types drop constraint if exists order_types_participants_check;
+alter table order_types drop column if exists updated_at;
+alter table order_types drop column if exists created_at;
+alter table order_types drop column if exists creditor;
+alter table order_types drop column if exists debtor;
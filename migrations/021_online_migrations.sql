@@ -0,0 +1,12 @@
+create table if not exists online_migrations (
+                                                 name         varchar(100) primary key,
+                                                 completed_at timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table online_migrations is 'Завершенные фоновые миграции больших таблиц (пакетное заполнение, индексы CONCURRENTLY)';
+comment on column online_migrations.name is 'Имя фоновой миграции';
+comment on column online_migrations.completed_at is 'Дата и время завершения';
+
+---- create above / drop below ----
+
+drop table if exists online_migrations;
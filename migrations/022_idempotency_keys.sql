@@ -0,0 +1,23 @@
+create table if not exists idempotency_keys (
+                                                scope         varchar(200) not null,
+                                                key           varchar(200) not null,
+                                                request_hash  char(64)     not null,
+                                                status_code   integer,
+                                                response_body bytea,
+                                                created_at    timestamp with time zone default CURRENT_TIMESTAMP,
+                                                primary key (scope, key)
+);
+
+comment on table idempotency_keys is 'Ключи идемпотентности запросов создания и сохраненные ответы для повторов';
+comment on column idempotency_keys.scope is 'Пользователь (subject JWT или адрес клиента) и маршрут запроса';
+comment on column idempotency_keys.key is 'Значение заголовка Idempotency-Key';
+comment on column idempotency_keys.request_hash is 'SHA-256 адреса и тела запроса; повтор с другим телом отклоняется';
+comment on column idempotency_keys.status_code is 'HTTP-статус ответа; NULL, пока запрос выполняется';
+comment on column idempotency_keys.response_body is 'Тело ответа';
+comment on column idempotency_keys.created_at is 'Дата и время первого запроса с ключом';
+
+create index if not exists idx_idempotency_keys_created_at on idempotency_keys (created_at);
+
+---- create above / drop below ----
+
+drop table if exists idempotency_keys;
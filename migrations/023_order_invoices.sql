@@ -0,0 +1,16 @@
+alter table orders add column if not exists invoice_key varchar(500) unique;
+alter table orders add column if not exists invoice_file_name varchar(255);
+alter table orders add column if not exists invoice_content_type varchar(100);
+alter table orders add column if not exists invoice_size_bytes bigint check (invoice_size_bytes >= 0);
+
+comment on column orders.invoice_key is 'Ключ объекта счета/квитанции заказа в S3-хранилище';
+comment on column orders.invoice_file_name is 'Исходное имя файла счета';
+comment on column orders.invoice_content_type is 'MIME-тип файла счета';
+comment on column orders.invoice_size_bytes is 'Размер файла счета в байтах';
+
+---- create above / drop below ----
+
+alter table orders drop column if exists invoice_size_bytes;
+alter table orders drop column if exists invoice_content_type;
+alter table orders drop column if exists invoice_file_name;
+alter table orders drop column if exists invoice_key;
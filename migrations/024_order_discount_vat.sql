@@ -0,0 +1,21 @@
+alter table orders add column if not exists discount numeric(15, 2) not null default 0 check (discount >= 0);
+alter table orders add column if not exists vat_rate numeric(5, 2) not null default 0 check (vat_rate >= 0 and vat_rate <= 100);
+alter table orders add column if not exists net_amount numeric(15, 2);
+alter table orders add column if not exists vat_amount numeric(15, 2);
+alter table orders add column if not exists gross_amount numeric(15, 2);
+
+comment on column orders.amount is 'Сумма заказа без НДС до скидки';
+comment on column orders.discount is 'Скидка, вычитаемая из суммы заказа';
+comment on column orders.vat_rate is 'Ставка НДС в процентах';
+comment on column orders.net_amount is 'Сумма без НДС после скидки; null у заказов, созданных до введения НДС (равна amount)';
+comment on column orders.vat_amount is 'Сумма НДС; null у заказов, созданных до введения НДС (равна 0)';
+comment on column orders.gross_amount is 'Сумма с НДС, участвует в неттинге; null у заказов, созданных до введения НДС (равна amount)';
+
+---- create above / drop below ----
+
+alter table orders drop column if exists gross_amount;
+alter table orders drop column if exists vat_amount;
+alter table orders drop column if exists net_amount;
+alter table orders drop column if exists vat_rate;
+alter table orders drop column if exists discount;
+comment on column orders.amount is 'Сумма заказа';
@@ -0,0 +1,7 @@
+alter table orders add column if not exists execute_at timestamp with time zone;
+
+comment on column orders.execute_at is 'Плановая дата исполнения (выдача кредита, выкуп trade-in); null - без плана';
+
+---- create above / drop below ----
+
+alter table orders drop column if exists execute_at;
@@ -0,0 +1,11 @@
+-- Возврат: Дилерский центр возвращает деньги Клиенту (например, за отмененный аксессуар)
+insert into order_types (order_type_id, name, debtor, creditor)
+values (4, 'ВОЗВРАТ', 'dealership', 'client')
+on conflict do nothing;
+
+comment on column order_types.name is 'Название типа заказа (Покупка, Кредит, Трейд-ин, Возврат)';
+
+---- create above / drop below ----
+
+delete from order_types where order_type_id = 4 and not exists (select 1 from orders where order_type_id = 4);
+comment on column order_types.name is 'Название типа заказа (Покупка, Кредит, Трейд-ин)';
@@ -0,0 +1,31 @@
+alter table orders add column if not exists insurer_id integer;
+alter table monetary_settlements add column if not exists insurer_id integer;
+
+comment on column orders.insurer_id is 'Идентификатор страховой компании (КАСКО/ОСАГО, оплачиваемые через дилерский центр)';
+comment on column monetary_settlements.insurer_id is 'Идентификатор страховой компании - участника взаиморасчета';
+
+alter table order_types drop constraint if exists order_types_participants_check;
+alter table order_types add constraint order_types_participants_check
+    check (debtor in ('client', 'bank', 'dealership', 'insurer') and creditor in ('client', 'bank', 'dealership', 'insurer')
+        and debtor <> creditor);
+
+comment on column order_types.debtor is 'Участник неттинга, который должен сумму заказа (client, bank, dealership, insurer)';
+comment on column order_types.creditor is 'Участник неттинга, которому причитается сумма заказа (client, bank, dealership, insurer)';
+
+-- Страхование: Клиент должен Страховой компании страховую премию
+insert into order_types (order_type_id, name, debtor, creditor)
+values (5, 'СТРАХОВАНИЕ', 'client', 'insurer')
+on conflict do nothing;
+
+---- create above / drop below ----
+
+delete from order_types where order_type_id = 5 and not exists (select 1 from orders where order_type_id = 5);
+alter table order_types drop constraint if exists order_types_participants_check;
+alter table order_types add constraint order_types_participants_check
+    check (debtor in ('client', 'bank', 'dealership') and creditor in ('client', 'bank', 'dealership')
+        and debtor <> creditor);
+comment on column order_types.debtor is 'Участник неттинга, который должен сумму заказа (client, bank, dealership)';
+comment on column order_types.creditor is 'Участник неттинга, которому причитается сумма заказа (client, bank, dealership)';
+
+alter table monetary_settlements drop column if exists insurer_id;
+alter table orders drop column if exists insurer_id;
@@ -0,0 +1,45 @@
+alter table monetary_settlements add column if not exists participant varchar(20);
+alter table monetary_settlements add column if not exists payment_reference varchar(100);
+alter table monetary_settlements add column if not exists executed_at timestamp with time zone;
+
+comment on column monetary_settlements.participant is 'Участник неттинга, исполняющий платеж (client, bank, dealership, insurer)';
+comment on column monetary_settlements.payment_reference is 'Номер платежного документа, которым исполнен взаиморасчет';
+comment on column monetary_settlements.executed_at is 'Дата и время исполнения взаиморасчета';
+
+-- Неисполненные взаиморасчеты, зафиксированные при переводе сделки в клиринг
+create index if not exists idx_monetary_settlements_pending on monetary_settlements (deal_id)
+    where status = 'pending';
+
+create table if not exists ledger_entries (
+    ledger_entry_id        serial primary key,
+    monetary_settlement_id integer not null references monetary_settlements on delete cascade,
+    deal_id                integer not null references deals,
+    account                varchar(50) not null,
+    direction              varchar(10) not null check (direction in ('debit', 'credit')),
+    amount                 numeric(15, 2) not null check (amount > 0),
+    currency_code          char(3) not null,
+    payment_reference      varchar(100),
+    created_at             timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table ledger_entries is 'Проводки по исполненным денежным взаиморасчетам (двойная запись)';
+comment on column ledger_entries.ledger_entry_id is 'Уникальный идентификатор проводки';
+comment on column ledger_entries.monetary_settlement_id is 'Идентификатор денежного взаиморасчета';
+comment on column ledger_entries.deal_id is 'Идентификатор сделки';
+comment on column ledger_entries.account is 'Счет: clearing - клиринговый счет, иначе счет участника (client, bank:1, dealership:12, insurer:3)';
+comment on column ledger_entries.direction is 'Направление проводки: debit или credit';
+comment on column ledger_entries.amount is 'Сумма проводки';
+comment on column ledger_entries.currency_code is 'Код валюты ISO 4217';
+comment on column ledger_entries.payment_reference is 'Номер платежного документа';
+comment on column ledger_entries.created_at is 'Дата и время проводки';
+
+create index if not exists idx_ledger_entries_monetary_settlement_id on ledger_entries (monetary_settlement_id);
+create index if not exists idx_ledger_entries_deal_id on ledger_entries (deal_id);
+
+---- create above / drop below ----
+
+drop table if exists ledger_entries cascade;
+drop index if exists idx_monetary_settlements_pending;
+alter table monetary_settlements drop column if exists executed_at;
+alter table monetary_settlements drop column if exists payment_reference;
+alter table monetary_settlements drop column if exists participant;
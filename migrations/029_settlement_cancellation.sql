@@ -0,0 +1,12 @@
+alter table monetary_settlements add column if not exists cancellation_reason varchar(500);
+alter table monetary_settlements add column if not exists cancelled_at timestamp with time zone;
+
+comment on column monetary_settlements.cancellation_reason is 'Причина отмены взаиморасчета';
+comment on column monetary_settlements.cancelled_at is 'Дата и время отмены взаиморасчета';
+comment on column deals.settlements_recompute_required is 'Флаг необходимости пересчета денежных расчетов после удаления заказа или отмены расчета';
+
+---- create above / drop below ----
+
+comment on column deals.settlements_recompute_required is 'Флаг необходимости пересчета денежных расчетов после удаления заказа';
+alter table monetary_settlements drop column if exists cancelled_at;
+alter table monetary_settlements drop column if exists cancellation_reason;
@@ -0,0 +1,25 @@
+create table if not exists netting_snapshots (
+    snapshot_id serial primary key,
+    deal_id     integer     not null,
+    version     integer     not null,
+    input_hash  char(64)    not null,
+    order_ids   integer[]   not null,
+    positions   jsonb       not null,
+    legs        jsonb       not null,
+    created_at  timestamp with time zone default CURRENT_TIMESTAMP,
+    unique (deal_id, version)
+);
+
+comment on table netting_snapshots is 'Снимки результатов неттинга сделки; новая версия появляется при изменении входных данных';
+comment on column netting_snapshots.snapshot_id is 'Уникальный идентификатор снимка';
+comment on column netting_snapshots.deal_id is 'Идентификатор сделки (без внешнего ключа, снимки хранятся дольше сделки)';
+comment on column netting_snapshots.version is 'Номер версии снимка в рамках сделки';
+comment on column netting_snapshots.input_hash is 'SHA-256 входных данных неттинга (заказы и правила типов заказов)';
+comment on column netting_snapshots.order_ids is 'Идентификаторы заказов, вошедших в расчет';
+comment on column netting_snapshots.positions is 'Чистые позиции участников (денежные расчеты)';
+comment on column netting_snapshots.legs is 'Платежи между участниками';
+comment on column netting_snapshots.created_at is 'Дата и время расчета';
+
+---- create above / drop below ----
+
+drop table if exists netting_snapshots cascade;
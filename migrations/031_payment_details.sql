@@ -0,0 +1,26 @@
+create table if not exists payment_details (
+    account               varchar(50) primary key,
+    name                  varchar(255) not null,
+    inn                   varchar(12),
+    kpp                   varchar(9),
+    bank_name             varchar(255),
+    bic                   varchar(9),
+    bank_account          varchar(20),
+    correspondent_account varchar(20),
+    updated_at            timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table payment_details is 'Платежные реквизиты участников взаиморасчетов и клирингового счета; заполняются из НСИ';
+comment on column payment_details.account is 'Счет участника, как в проводках: clearing - клиринговый счет, иначе client:42, bank:1, dealership:12, insurer:3';
+comment on column payment_details.name is 'Наименование получателя или плательщика';
+comment on column payment_details.inn is 'ИНН';
+comment on column payment_details.kpp is 'КПП';
+comment on column payment_details.bank_name is 'Наименование банка, обслуживающего счет';
+comment on column payment_details.bic is 'БИК банка';
+comment on column payment_details.bank_account is 'Расчетный счет';
+comment on column payment_details.correspondent_account is 'Корреспондентский счет банка';
+comment on column payment_details.updated_at is 'Дата и время последнего обновления';
+
+---- create above / drop below ----
+
+drop table if exists payment_details cascade;
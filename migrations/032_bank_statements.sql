@@ -0,0 +1,35 @@
+create table if not exists bank_statement_lines (
+    line_id                serial primary key,
+    payment_reference      varchar(100) not null,
+    amount                 numeric(15, 2) not null check (amount > 0),
+    currency_code          char(3) not null,
+    value_date             date not null,
+    payer_name             varchar(255),
+    purpose                varchar(500),
+    status                 varchar(20) not null default 'unmatched' check (status in ('matched', 'unmatched')),
+    monetary_settlement_id integer references monetary_settlements,
+    imported_at            timestamp with time zone default CURRENT_TIMESTAMP,
+    matched_at             timestamp with time zone,
+    unique (payment_reference, value_date, amount)
+);
+
+comment on table bank_statement_lines is 'Строки банковских выписок (входящие платежи) для сверки с денежными взаиморасчетами';
+comment on column bank_statement_lines.line_id is 'Уникальный идентификатор строки выписки';
+comment on column bank_statement_lines.payment_reference is 'Номер платежного документа';
+comment on column bank_statement_lines.amount is 'Сумма платежа';
+comment on column bank_statement_lines.currency_code is 'Код валюты платежа';
+comment on column bank_statement_lines.value_date is 'Дата валютирования';
+comment on column bank_statement_lines.payer_name is 'Наименование плательщика';
+comment on column bank_statement_lines.purpose is 'Назначение платежа';
+comment on column bank_statement_lines.status is 'Статус сверки: matched - сопоставлена с взаиморасчетом, unmatched - не сопоставлена';
+comment on column bank_statement_lines.monetary_settlement_id is 'Взаиморасчет, исполненный платежом';
+comment on column bank_statement_lines.imported_at is 'Дата и время загрузки строки';
+comment on column bank_statement_lines.matched_at is 'Дата и время сопоставления';
+
+-- Поиск несопоставленных строк
+create index if not exists idx_bank_statement_lines_unmatched on bank_statement_lines (imported_at)
+    where status = 'unmatched';
+
+---- create above / drop below ----
+
+drop table if exists bank_statement_lines cascade;
@@ -0,0 +1,48 @@
+create table if not exists webhook_subscriptions (
+    subscription_id serial primary key,
+    url             varchar(2000) not null,
+    events          varchar(50)[] not null,
+    secret          varchar(100) not null,
+    created_at      timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table webhook_subscriptions is 'Подписки внешних систем учета на события денежных взаиморасчетов';
+comment on column webhook_subscriptions.subscription_id is 'Уникальный идентификатор подписки';
+comment on column webhook_subscriptions.url is 'Адрес, на который отправляются события (POST)';
+comment on column webhook_subscriptions.events is 'События подписки: settlement.created, settlement.executed, settlement.cancelled';
+comment on column webhook_subscriptions.secret is 'Ключ подписи тела запроса (HMAC-SHA256)';
+comment on column webhook_subscriptions.created_at is 'Дата и время создания';
+
+create table if not exists webhook_deliveries (
+    delivery_id     bigserial primary key,
+    subscription_id integer not null references webhook_subscriptions on delete cascade,
+    event           varchar(50) not null,
+    payload         jsonb not null,
+    status          varchar(20) not null default 'pending' check (status in ('pending', 'delivered', 'failed')),
+    attempts        integer not null default 0,
+    next_attempt_at timestamp with time zone default CURRENT_TIMESTAMP,
+    last_error      varchar(500),
+    created_at      timestamp with time zone default CURRENT_TIMESTAMP,
+    delivered_at    timestamp with time zone
+);
+
+comment on table webhook_deliveries is 'Очередь доставки событий подписчикам (outbox)';
+comment on column webhook_deliveries.delivery_id is 'Уникальный идентификатор доставки, передается в заголовке X-Cliring-Delivery';
+comment on column webhook_deliveries.subscription_id is 'Идентификатор подписки';
+comment on column webhook_deliveries.event is 'Событие';
+comment on column webhook_deliveries.payload is 'Тело запроса';
+comment on column webhook_deliveries.status is 'Статус: pending - ожидает отправки, delivered - доставлено, failed - попытки исчерпаны';
+comment on column webhook_deliveries.attempts is 'Число выполненных попыток';
+comment on column webhook_deliveries.next_attempt_at is 'Время следующей попытки';
+comment on column webhook_deliveries.last_error is 'Ошибка последней попытки';
+comment on column webhook_deliveries.created_at is 'Дата и время создания';
+comment on column webhook_deliveries.delivered_at is 'Дата и время доставки';
+
+-- Выборка доставок, ожидающих отправки
+create index if not exists idx_webhook_deliveries_pending on webhook_deliveries (next_attempt_at)
+    where status = 'pending';
+
+---- create above / drop below ----
+
+drop table if exists webhook_deliveries cascade;
+drop table if exists webhook_subscriptions cascade;
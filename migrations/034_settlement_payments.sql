@@ -0,0 +1,20 @@
+create table if not exists settlement_payments (
+    payment_id             serial primary key,
+    monetary_settlement_id integer not null references monetary_settlements on delete cascade,
+    amount                 numeric(15, 2) not null check (amount > 0),
+    payment_reference      varchar(100) not null,
+    created_at             timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table settlement_payments is 'Частичные оплаты денежных взаиморасчетов; расчет исполняется, когда оплаты покрывают его сумму';
+comment on column settlement_payments.payment_id is 'Уникальный идентификатор оплаты';
+comment on column settlement_payments.monetary_settlement_id is 'Идентификатор денежного взаиморасчета';
+comment on column settlement_payments.amount is 'Сумма оплаты';
+comment on column settlement_payments.payment_reference is 'Номер платежного документа';
+comment on column settlement_payments.created_at is 'Дата и время регистрации оплаты';
+
+create index if not exists idx_settlement_payments_settlement on settlement_payments (monetary_settlement_id);
+
+---- create above / drop below ----
+
+drop table if exists settlement_payments;
@@ -0,0 +1,28 @@
+create table if not exists fx_rates (
+    rate_id             serial primary key,
+    currency_code       char(3) not null references currencies,
+    pivot_currency_code char(3) not null references currencies,
+    rate                numeric(18, 8) not null check (rate > 0),
+    rate_date           date not null,
+    created_at          timestamp with time zone default CURRENT_TIMESTAMP,
+    check (currency_code <> pivot_currency_code)
+);
+
+comment on table fx_rates is 'Курсы валют для пересчета заказов сделки в валюту неттинга; новый курс - новая версия, курсы не изменяются';
+comment on column fx_rates.rate_id is 'Уникальный идентификатор (версия) курса';
+comment on column fx_rates.currency_code is 'Код валюты заказа';
+comment on column fx_rates.pivot_currency_code is 'Код валюты неттинга';
+comment on column fx_rates.rate is 'Количество единиц валюты неттинга за единицу валюты заказа';
+comment on column fx_rates.rate_date is 'Дата, с которой действует курс';
+comment on column fx_rates.created_at is 'Дата и время создания';
+
+create index if not exists idx_fx_rates_effective on fx_rates (pivot_currency_code, currency_code, rate_date desc, rate_id desc);
+
+alter table monetary_settlements add column if not exists fx_rates jsonb;
+
+comment on column monetary_settlements.fx_rates is 'Курсы (с версиями), по которым заказы сделки в разных валютах пересчитаны перед неттингом';
+
+---- create above / drop below ----
+
+alter table monetary_settlements drop column if exists fx_rates;
+drop table if exists fx_rates;
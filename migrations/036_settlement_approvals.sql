@@ -0,0 +1,20 @@
+alter table monetary_settlements add column if not exists approval_status varchar(20)
+    check (approval_status in ('required', 'approved', 'rejected'));
+alter table monetary_settlements add column if not exists created_by varchar(100);
+alter table monetary_settlements add column if not exists reviewed_by varchar(100);
+alter table monetary_settlements add column if not exists reviewed_at timestamp with time zone;
+alter table monetary_settlements add column if not exists review_comment varchar(500);
+
+comment on column monetary_settlements.approval_status is 'Одобрение крупного расчета вторым пользователем: required, approved, rejected; пусто - одобрение не требуется';
+comment on column monetary_settlements.created_by is 'Пользователь, запрос которого зафиксировал расчет';
+comment on column monetary_settlements.reviewed_by is 'Пользователь, одобривший или отклонивший расчет';
+comment on column monetary_settlements.reviewed_at is 'Дата и время одобрения или отклонения';
+comment on column monetary_settlements.review_comment is 'Комментарий к решению; обязателен при отклонении';
+
+---- create above / drop below ----
+
+alter table monetary_settlements drop column if exists review_comment;
+alter table monetary_settlements drop column if exists reviewed_at;
+alter table monetary_settlements drop column if exists reviewed_by;
+alter table monetary_settlements drop column if exists created_by;
+alter table monetary_settlements drop column if exists approval_status;
@@ -0,0 +1,36 @@
+create table if not exists payment_confirmations (
+    confirmation_id        serial primary key,
+    source                 varchar(50) not null,
+    external_id            varchar(100) not null,
+    reference_number       varchar(100) not null,
+    payment_reference      varchar(100) not null,
+    amount                 numeric(15, 2) not null check (amount > 0),
+    currency_code          char(3) not null,
+    status                 varchar(20) not null check (status in ('executed', 'rejected')),
+    outcome                varchar(20) not null default 'received'
+        check (outcome in ('received', 'applied', 'unmatched', 'ignored')),
+    reason                 varchar(500),
+    monetary_settlement_id integer references monetary_settlements,
+    received_at            timestamp with time zone default CURRENT_TIMESTAMP,
+    processed_at           timestamp with time zone,
+    unique (source, external_id)
+);
+
+comment on table payment_confirmations is 'Подтверждения платежей, присланные банками; повторная доставка того же подтверждения не обрабатывается';
+comment on column payment_confirmations.confirmation_id is 'Уникальный идентификатор подтверждения';
+comment on column payment_confirmations.source is 'Банк, приславший подтверждение (по API-ключу)';
+comment on column payment_confirmations.external_id is 'Идентификатор подтверждения в системе банка';
+comment on column payment_confirmations.reference_number is 'Номер расчета из назначения платежа';
+comment on column payment_confirmations.payment_reference is 'Номер платежного документа';
+comment on column payment_confirmations.amount is 'Сумма платежа';
+comment on column payment_confirmations.currency_code is 'Код валюты платежа';
+comment on column payment_confirmations.status is 'Статус платежа в банке: executed - исполнен, rejected - отклонен';
+comment on column payment_confirmations.outcome is 'Результат обработки: received - принято, applied - оплата зарегистрирована по расчету, unmatched - расчет не найден или не принимает оплату, ignored - платеж отклонен банком';
+comment on column payment_confirmations.reason is 'Причина, по которой подтверждение не применено к расчету';
+comment on column payment_confirmations.monetary_settlement_id is 'Взаиморасчет, найденный по номеру';
+comment on column payment_confirmations.received_at is 'Дата и время получения подтверждения';
+comment on column payment_confirmations.processed_at is 'Дата и время обработки подтверждения';
+
+---- create above / drop below ----
+
+drop table if exists payment_confirmations;
@@ -0,0 +1,34 @@
+create table if not exists clearing_sessions (
+    session_id   serial primary key,
+    status       varchar(20) not null default 'open' check (status in ('open', 'computing', 'settled', 'closed')),
+    opened_at    timestamp with time zone default CURRENT_TIMESTAMP,
+    computing_at timestamp with time zone,
+    settled_at   timestamp with time zone,
+    closed_at    timestamp with time zone
+);
+
+comment on table clearing_sessions is 'Клиринговые сессии: группируют заказы, принятые в окне сессии';
+comment on column clearing_sessions.session_id is 'Уникальный идентификатор сессии';
+comment on column clearing_sessions.status is 'Статус: open - принимает заказы, computing - идет расчет, settled - расчеты зафиксированы, closed - закрыта';
+comment on column clearing_sessions.opened_at is 'Дата и время открытия сессии';
+comment on column clearing_sessions.computing_at is 'Дата и время начала расчета';
+comment on column clearing_sessions.settled_at is 'Дата и время фиксации расчетов';
+comment on column clearing_sessions.closed_at is 'Дата и время закрытия сессии';
+
+-- Открытая сессия всегда одна: заказы, созданные во время расчета, попадают в следующую сессию
+create unique index if not exists idx_clearing_sessions_open on clearing_sessions (status) where status = 'open';
+
+alter table orders add column if not exists clearing_session_id integer references clearing_sessions;
+alter table monetary_settlements add column if not exists clearing_session_id integer references clearing_sessions;
+
+comment on column orders.clearing_session_id is 'Клиринговая сессия, в которой принят заказ';
+comment on column monetary_settlements.clearing_session_id is 'Клиринговая сессия, при расчете которой зафиксирован взаиморасчет';
+
+create index if not exists idx_orders_clearing_session on orders (clearing_session_id);
+create index if not exists idx_monetary_settlements_clearing_session on monetary_settlements (clearing_session_id);
+
+---- create above / drop below ----
+
+alter table monetary_settlements drop column if exists clearing_session_id;
+alter table orders drop column if exists clearing_session_id;
+drop table if exists clearing_sessions;
@@ -0,0 +1,41 @@
+create table if not exists netting_rules (
+    rule_id       serial primary key,
+    order_type_id integer not null references order_types on delete cascade,
+    debtor        varchar(20),
+    creditor      varchar(20),
+    excluded      boolean not null default false,
+    min_amount    numeric(15, 2) check (min_amount >= 0),
+    max_amount    numeric(15, 2) check (max_amount >= 0),
+    valid_from    date not null,
+    valid_to      date,
+    comment       varchar(500),
+    created_by    varchar(100),
+    created_at    timestamp with time zone default CURRENT_TIMESTAMP,
+    updated_at    timestamp with time zone default CURRENT_TIMESTAMP,
+    check ((debtor is null) = (creditor is null)),
+    check (debtor is null or (debtor in ('client', 'bank', 'dealership', 'insurer')
+        and creditor in ('client', 'bank', 'dealership', 'insurer') and debtor <> creditor)),
+    check (min_amount is null or max_amount is null or min_amount <= max_amount),
+    check (valid_to is null or valid_to >= valid_from)
+);
+
+comment on table netting_rules is 'Настраиваемые правила неттинга типов заказов; в период действия заменяют правило справочника order_types';
+comment on column netting_rules.rule_id is 'Уникальный идентификатор правила';
+comment on column netting_rules.order_type_id is 'Тип заказа';
+comment on column netting_rules.debtor is 'Участник, который должен сумму заказа; пусто - участник из справочника типов заказов';
+comment on column netting_rules.creditor is 'Участник, которому причитается сумма заказа; пусто - участник из справочника типов заказов';
+comment on column netting_rules.excluded is 'Заказы типа не участвуют в неттинге';
+comment on column netting_rules.min_amount is 'Минимальная сумма заказа с НДС для участия в неттинге (в валюте неттинга)';
+comment on column netting_rules.max_amount is 'Максимальная сумма заказа с НДС для участия в неттинге (в валюте неттинга)';
+comment on column netting_rules.valid_from is 'Дата начала действия правила';
+comment on column netting_rules.valid_to is 'Дата окончания действия правила включительно; пусто - бессрочно';
+comment on column netting_rules.comment is 'Основание изменения правила';
+comment on column netting_rules.created_by is 'Пользователь, создавший правило';
+comment on column netting_rules.created_at is 'Дата и время создания';
+comment on column netting_rules.updated_at is 'Дата и время последнего обновления';
+
+create index if not exists idx_netting_rules_order_type on netting_rules (order_type_id, valid_from);
+
+---- create above / drop below ----
+
+drop table if exists netting_rules;
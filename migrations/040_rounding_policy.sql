@@ -0,0 +1,25 @@
+alter table currencies
+    add column if not exists rounding_mode      varchar(10) not null default 'half_up'
+        check (rounding_mode in ('half_up', 'half_even')),
+    add column if not exists rounding_precision smallint    not null default 2
+        check (rounding_precision between 0 and 2),
+    add column if not exists rounding_residual  varchar(20) not null default 'client'
+        check (rounding_residual in ('client', 'bank', 'dealership', 'insurer'));
+
+comment on column currencies.rounding_mode is 'Способ округления чистых позиций и пересчета по курсу: half_up - половина от нуля, half_even - банковское';
+comment on column currencies.rounding_precision is 'Количество знаков дробной части, до которого округляются чистые позиции';
+comment on column currencies.rounding_residual is 'Участник неттинга, на которого относится остаток от округления позиций';
+
+alter table monetary_settlements
+    add column if not exists rounding_residual numeric(15, 2) not null default 0;
+
+comment on column monetary_settlements.rounding_residual is 'Остаток от округления чистых позиций, включенный в сумму расчета';
+
+---- create above / drop below ----
+
+alter table monetary_settlements drop column if exists rounding_residual;
+
+alter table currencies
+    drop column if exists rounding_residual,
+    drop column if exists rounding_precision,
+    drop column if exists rounding_mode;
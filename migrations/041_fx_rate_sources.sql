@@ -0,0 +1,13 @@
+alter table fx_rates
+    add column if not exists source varchar(20) not null default 'manual' check (source in ('manual', 'cbr'));
+
+comment on column fx_rates.source is 'Источник курса: manual - задан оператором, cbr - загружен с сайта ЦБ РФ';
+
+-- Курс ЦБ на дату загружается один раз, повторные загрузки его не дублируют
+create unique index if not exists idx_fx_rates_cbr on fx_rates (currency_code, pivot_currency_code, rate_date)
+    where source = 'cbr';
+
+---- create above / drop below ----
+
+drop index if exists idx_fx_rates_cbr;
+alter table fx_rates drop column if exists source;
@@ -0,0 +1,33 @@
+create table if not exists settlement_penalties (
+    penalty_id             serial primary key,
+    monetary_settlement_id integer        not null references monetary_settlements,
+    deal_id                integer        not null references deals,
+    participant            varchar(20)    not null check (participant in ('client', 'bank', 'dealership', 'insurer')),
+    participant_id         integer,
+    accrual_date           date           not null,
+    base_amount            numeric(15, 2) not null check (base_amount > 0),
+    rate                   numeric(9, 4)  not null check (rate > 0),
+    amount                 numeric(15, 2) not null check (amount > 0),
+    currency_code          char(3)        not null references currencies,
+    created_at             timestamp with time zone default CURRENT_TIMESTAMP,
+    unique (monetary_settlement_id, accrual_date)
+);
+
+comment on table settlement_penalties is 'Неустойка за просрочку оплаты денежных расчетов; учитывается в следующем неттинге сделки';
+comment on column settlement_penalties.penalty_id is 'Уникальный идентификатор начисления';
+comment on column settlement_penalties.monetary_settlement_id is 'Просроченный денежный расчет';
+comment on column settlement_penalties.deal_id is 'Сделка расчета';
+comment on column settlement_penalties.participant is 'Роль участника, который платит неустойку';
+comment on column settlement_penalties.participant_id is 'Банк, дилерский центр или страховая компания, который платит неустойку';
+comment on column settlement_penalties.accrual_date is 'День просрочки, за который начислена неустойка';
+comment on column settlement_penalties.base_amount is 'Неоплаченная сумма расчета, на которую начислена неустойка';
+comment on column settlement_penalties.rate is 'Ставка неустойки в процентах за день просрочки';
+comment on column settlement_penalties.amount is 'Сумма неустойки';
+comment on column settlement_penalties.currency_code is 'Валюта неустойки (валюта расчета)';
+comment on column settlement_penalties.created_at is 'Дата и время начисления';
+
+create index if not exists idx_settlement_penalties_deal on settlement_penalties (deal_id, accrual_date);
+
+---- create above / drop below ----
+
+drop table if exists settlement_penalties;
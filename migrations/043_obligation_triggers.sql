@@ -0,0 +1,56 @@
+alter table order_types add column if not exists kind varchar(20) not null default 'order'
+    check (kind in ('order', 'fee', 'penalty'));
+
+comment on column order_types.kind is 'Вид обязательства: order - заказ сделки, fee - комиссия дилерского центра, penalty - договорная неустойка';
+
+-- Комиссии дилерского центра и договорная неустойка: Клиент должен Дилерскому центру
+insert into order_types (order_type_id, name, debtor, creditor, kind)
+values (6, 'КОМИССИЯ ОФОРМЛЕНИЯ', 'client', 'dealership', 'fee'),
+       (7, 'ХРАНЕНИЕ', 'client', 'dealership', 'fee'),
+       (8, 'НЕУСТОЙКА', 'client', 'dealership', 'penalty')
+on conflict do nothing;
+
+create table if not exists obligation_triggers (
+    trigger_id    serial primary key,
+    order_type_id integer not null references order_types,
+    event         varchar(20) not null check (event in ('deal_cancelled', 'deal_open')),
+    delay_days    integer not null default 0 check (delay_days >= 0),
+    amount        numeric(15, 2) not null check (amount > 0),
+    currency_code char(3) not null references currencies,
+    vat_rate      numeric(5, 2) not null default 0 check (vat_rate >= 0 and vat_rate <= 100),
+    active        boolean not null default true,
+    comment       varchar(500),
+    created_by    varchar(100),
+    created_at    timestamp with time zone default CURRENT_TIMESTAMP,
+    updated_at    timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table obligation_triggers is 'Триггеры, автоматически создающие в сделке заказ комиссии или неустойки';
+comment on column obligation_triggers.trigger_id is 'Уникальный идентификатор триггера';
+comment on column obligation_triggers.order_type_id is 'Тип создаваемого заказа (вида fee или penalty)';
+comment on column obligation_triggers.event is 'Событие: deal_cancelled - сделка отменена не ранее delay_days дней после создания, deal_open - сделка не завершена через delay_days дней после создания';
+comment on column obligation_triggers.delay_days is 'Число календарных дней с создания сделки';
+comment on column obligation_triggers.amount is 'Сумма создаваемого заказа без НДС';
+comment on column obligation_triggers.currency_code is 'Валюта создаваемого заказа';
+comment on column obligation_triggers.vat_rate is 'Ставка НДС создаваемого заказа, %';
+comment on column obligation_triggers.active is 'Триггер срабатывает; выключенный триггер новые заказы не создает';
+comment on column obligation_triggers.comment is 'Основание (пункт договора, тариф)';
+comment on column obligation_triggers.created_by is 'Пользователь, создавший триггер';
+comment on column obligation_triggers.created_at is 'Дата и время создания';
+comment on column obligation_triggers.updated_at is 'Дата и время последнего обновления';
+
+alter table orders add column if not exists trigger_id integer references obligation_triggers on delete set null;
+
+comment on column orders.trigger_id is 'Триггер, создавший заказ; пусто - заказ создан вручную';
+
+-- Триггер срабатывает в сделке не более одного раза
+create unique index if not exists idx_orders_trigger on orders (deal_id, trigger_id) where trigger_id is not null;
+
+---- create above / drop below ----
+
+drop index if exists idx_orders_trigger;
+alter table orders drop column if exists trigger_id;
+drop table if exists obligation_triggers;
+delete from order_types where order_type_id in (6, 7, 8)
+    and not exists (select 1 from orders where orders.order_type_id = order_types.order_type_id);
+alter table order_types drop column if exists kind;
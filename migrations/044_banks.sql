@@ -0,0 +1,29 @@
+-- Справочник банков: заказы и расчеты ссылаются на него внешними ключами bank_id с момента создания схемы
+alter table if exists bank rename to banks;
+alter table banks rename column bank_name to name;
+alter table banks alter column name type varchar(255);
+alter table banks add column if not exists bic varchar(9) check (bic ~ '^[0-9]{9}$');
+alter table banks add column if not exists correspondent_account varchar(20) check (correspondent_account ~ '^[0-9]{20}$');
+alter table banks add column if not exists active boolean not null default true;
+alter table banks add column if not exists created_at timestamp with time zone default CURRENT_TIMESTAMP;
+alter table banks add column if not exists updated_at timestamp with time zone default CURRENT_TIMESTAMP;
+
+comment on table banks is 'Справочник банков-кредиторов';
+comment on column banks.bank_id is 'Уникальный идентификатор банка';
+comment on column banks.name is 'Наименование банка';
+comment on column banks.bic is 'БИК банка';
+comment on column banks.correspondent_account is 'Корреспондентский счет банка';
+comment on column banks.active is 'Банк принимается в новых заказах; неактивный банк остается в существующих заказах и расчетах';
+comment on column banks.created_at is 'Дата и время создания';
+comment on column banks.updated_at is 'Дата и время последнего обновления';
+
+---- create above / drop below ----
+
+alter table banks drop column if exists updated_at;
+alter table banks drop column if exists created_at;
+alter table banks drop column if exists active;
+alter table banks drop column if exists correspondent_account;
+alter table banks drop column if exists bic;
+alter table banks alter column name type varchar(50);
+alter table banks rename column name to bank_name;
+alter table banks rename to bank;
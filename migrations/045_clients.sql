@@ -0,0 +1,20 @@
+-- Сделки ссылаются на клиентов внешним ключом deals.client_id с момента создания схемы
+alter table clients rename column name to full_name;
+alter table clients alter column full_name type varchar(255);
+alter table clients add column if not exists phone varchar(16) check (phone ~ '^\+?[0-9]{10,15}$');
+alter table clients add column if not exists email varchar(255);
+alter table clients add column if not exists passport_ref varchar(100);
+
+comment on column clients.full_name is 'ФИО клиента';
+comment on column clients.phone is 'Телефон в международном формате';
+comment on column clients.email is 'Адрес электронной почты';
+comment on column clients.passport_ref is 'Ссылка на паспорт клиента во внешнем хранилище документов; номер паспорта не хранится';
+
+---- create above / drop below ----
+
+alter table clients drop column if exists passport_ref;
+alter table clients drop column if exists email;
+alter table clients drop column if exists phone;
+alter table clients alter column full_name type varchar(100);
+alter table clients rename column full_name to name;
+comment on column clients.name is 'Имя клиента';
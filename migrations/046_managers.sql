@@ -0,0 +1,30 @@
+-- Сделки хранят manager_id с момента создания схемы; внешний ключ не добавляется, чтобы не ломать сделки с менеджерами, не внесенными в справочник
+create table if not exists managers (
+    manager_id    integer primary key,
+    dealership_id integer not null,
+    full_name     varchar(255) not null,
+    phone         varchar(16) check (phone ~ '^\+?[0-9]{10,15}$'),
+    email         varchar(255),
+    active        boolean not null default true,
+    created_at    timestamp with time zone default CURRENT_TIMESTAMP,
+    updated_at    timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table managers is 'Менеджеры дилерских центров, ведущие сделки';
+comment on column managers.manager_id is 'Уникальный идентификатор менеджера';
+comment on column managers.dealership_id is 'Дилерский центр, в котором работает менеджер';
+comment on column managers.full_name is 'ФИО менеджера';
+comment on column managers.phone is 'Рабочий телефон в международном формате';
+comment on column managers.email is 'Рабочий адрес электронной почты';
+comment on column managers.active is 'Менеджер принимает новые сделки; неактивный менеджер остается в существующих сделках';
+comment on column managers.created_at is 'Дата и время создания';
+comment on column managers.updated_at is 'Дата и время последнего обновления';
+
+create index if not exists idx_managers_dealership_id on managers (dealership_id);
+create index if not exists idx_deals_manager_id on deals (manager_id);
+
+---- create above / drop below ----
+
+drop index if exists idx_deals_manager_id;
+drop index if exists idx_managers_dealership_id;
+drop table if exists managers;
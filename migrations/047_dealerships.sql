@@ -0,0 +1,40 @@
+create table if not exists dealerships (
+    dealership_id integer primary key,
+    name          varchar(255) not null,
+    legal_entity  varchar(255) not null,
+    address       varchar(500),
+    active        boolean not null default true,
+    created_at    timestamp with time zone default CURRENT_TIMESTAMP,
+    updated_at    timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table dealerships is 'Справочник дилерских центров группы';
+comment on column dealerships.dealership_id is 'Уникальный идентификатор дилерского центра';
+comment on column dealerships.name is 'Наименование дилерского центра';
+comment on column dealerships.legal_entity is 'Юридическое лицо, от имени которого дилерский центр заключает сделки';
+comment on column dealerships.address is 'Адрес дилерского центра';
+comment on column dealerships.active is 'Дилерский центр принимает новые сделки; неактивный центр остается в существующих сделках';
+comment on column dealerships.created_at is 'Дата и время создания';
+comment on column dealerships.updated_at is 'Дата и время последнего обновления';
+
+-- Дилерские центры, на которые уже ссылаются данные, вносятся в справочник с временным наименованием
+insert into dealerships (dealership_id, name, legal_entity)
+select ids.dealership_id, 'Дилерский центр ' || ids.dealership_id, 'Дилерский центр ' || ids.dealership_id
+from (select dealership_id from deals
+      union
+      select dealership_id from orders
+      union
+      select dealership_id from managers) ids
+where ids.dealership_id is not null
+on conflict do nothing;
+
+alter table deals add constraint fk_deals_dealership foreign key (dealership_id) references dealerships;
+alter table orders add constraint fk_orders_dealership foreign key (dealership_id) references dealerships;
+alter table managers add constraint fk_managers_dealership foreign key (dealership_id) references dealerships;
+
+---- create above / drop below ----
+
+alter table managers drop constraint if exists fk_managers_dealership;
+alter table orders drop constraint if exists fk_orders_dealership;
+alter table deals drop constraint if exists fk_deals_dealership;
+drop table if exists dealerships;
@@ -0,0 +1,35 @@
+-- Каталог автомобилей становится справочником конкретных автомобилей: заказы ссылаются на него по vehicle_id с миграции 011
+alter table if exists vehicle_catalog rename to vehicles;
+alter table vehicles rename column name to model;
+alter table vehicles rename column list_price to price;
+alter table vehicles add column if not exists vin varchar(17) check (vin ~ '^[A-HJ-NPR-Z0-9]{17}$');
+alter table vehicles add column if not exists condition varchar(10) not null default 'new' check (condition in ('new', 'used'));
+
+create unique index if not exists idx_vehicles_vin on vehicles (vin);
+
+comment on table vehicles is 'Автомобили сделок: продаваемые и принимаемые в трейд-ин';
+comment on column vehicles.vehicle_id is 'Уникальный идентификатор автомобиля';
+comment on column vehicles.model is 'Марка, модель, комплектация';
+comment on column vehicles.price is 'Цена автомобиля: прайсовая для продажи, оценочная для трейд-ина';
+comment on column vehicles.vin is 'VIN автомобиля; пусто у записей, перенесенных из каталога';
+comment on column vehicles.condition is 'Состояние: new - новый, used - с пробегом';
+
+-- Существующие заказы могли ссылаться на автомобили вне каталога, поэтому ключ проверяется только для новых строк
+alter table orders add constraint fk_orders_vehicle foreign key (vehicle_id) references vehicles not valid;
+
+comment on column orders.vehicle_id is 'Автомобиль заказа на покупку или трейд-ин';
+
+---- create above / drop below ----
+
+alter table orders drop constraint if exists fk_orders_vehicle;
+comment on column orders.vehicle_id is 'Идентификатор автомобиля в каталоге (для заказов на покупку)';
+drop index if exists idx_vehicles_vin;
+alter table vehicles drop column if exists condition;
+alter table vehicles drop column if exists vin;
+alter table vehicles rename column price to list_price;
+alter table vehicles rename column model to name;
+alter table vehicles rename to vehicle_catalog;
+comment on table vehicle_catalog is 'Каталог автомобилей с прайсовыми ценами';
+comment on column vehicle_catalog.vehicle_id is 'Уникальный идентификатор автомобиля в каталоге';
+comment on column vehicle_catalog.name is 'Наименование (марка, модель, комплектация)';
+comment on column vehicle_catalog.list_price is 'Прайсовая цена';
@@ -0,0 +1,37 @@
+create table if not exists credit_contracts (
+    credit_contract_id serial primary key,
+    deal_id            integer not null references deals on delete cascade,
+    bank_id            integer not null references banks,
+    contract_number    varchar(50) not null,
+    principal          numeric(15, 2) not null check (principal > 0),
+    currency_code      char(3) not null references currencies,
+    term_months        integer not null check (term_months > 0),
+    rate               numeric(6, 3) not null check (rate >= 0 and rate <= 100),
+    created_at         timestamp with time zone default CURRENT_TIMESTAMP,
+    updated_at         timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table credit_contracts is 'Кредитные договоры клиентов сделок';
+comment on column credit_contracts.credit_contract_id is 'Уникальный идентификатор кредитного договора';
+comment on column credit_contracts.deal_id is 'Сделка, в которой автомобиль покупается в кредит; договор удаляется вместе со сделкой при очистке';
+comment on column credit_contracts.bank_id is 'Банк-кредитор';
+comment on column credit_contracts.contract_number is 'Номер договора в банке';
+comment on column credit_contracts.principal is 'Сумма кредита';
+comment on column credit_contracts.currency_code is 'Валюта кредита';
+comment on column credit_contracts.term_months is 'Срок кредита в месяцах';
+comment on column credit_contracts.rate is 'Годовая процентная ставка, %';
+comment on column credit_contracts.created_at is 'Дата и время создания';
+comment on column credit_contracts.updated_at is 'Дата и время последнего обновления';
+
+-- Номер договора уникален в пределах банка
+create unique index if not exists idx_credit_contracts_number on credit_contracts (bank_id, contract_number);
+create index if not exists idx_credit_contracts_deal_id on credit_contracts (deal_id);
+
+alter table orders add column if not exists credit_contract_id integer references credit_contracts;
+
+comment on column orders.credit_contract_id is 'Кредитный договор заказа на кредит; неисполненный заказ на кредит без договора не участвует в неттинге';
+
+---- create above / drop below ----
+
+alter table orders drop column if exists credit_contract_id;
+drop table if exists credit_contracts;
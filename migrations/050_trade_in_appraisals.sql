@@ -0,0 +1,42 @@
+create table if not exists trade_in_appraisals (
+    appraisal_id serial primary key,
+    deal_id      integer not null references deals on delete cascade,
+    vehicle_id   integer not null references vehicles,
+    amount       numeric(15, 2) not null check (amount > 0),
+    appraiser    varchar(200) not null,
+    status       varchar(20) not null default 'pending' check (status in ('pending', 'approved', 'rejected')),
+    comment      varchar(500),
+    reviewed_by  varchar(200),
+    reviewed_at  timestamp with time zone,
+    created_at   timestamp with time zone default CURRENT_TIMESTAMP,
+    updated_at   timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table trade_in_appraisals is 'Оценки автомобилей, принимаемых в трейд-ин';
+comment on column trade_in_appraisals.appraisal_id is 'Уникальный идентификатор оценки';
+comment on column trade_in_appraisals.deal_id is 'Сделка, в которой автомобиль сдается в трейд-ин; оценка удаляется вместе со сделкой при очистке';
+comment on column trade_in_appraisals.vehicle_id is 'Оцениваемый автомобиль с пробегом';
+comment on column trade_in_appraisals.amount is 'Оценочная стоимость автомобиля';
+comment on column trade_in_appraisals.appraiser is 'Оценщик';
+comment on column trade_in_appraisals.status is 'Статус оценки: pending, approved или rejected';
+comment on column trade_in_appraisals.comment is 'Комментарий к решению по оценке';
+comment on column trade_in_appraisals.reviewed_by is 'Пользователь, одобривший или отклонивший оценку';
+comment on column trade_in_appraisals.reviewed_at is 'Дата и время решения по оценке';
+comment on column trade_in_appraisals.created_at is 'Дата и время создания';
+comment on column trade_in_appraisals.updated_at is 'Дата и время последнего обновления';
+
+create index if not exists idx_trade_in_appraisals_deal_id on trade_in_appraisals (deal_id);
+
+alter table orders add column if not exists appraisal_id integer references trade_in_appraisals;
+
+comment on column orders.appraisal_id is 'Одобренная оценка, по которой оформлен заказ на трейд-ин';
+
+-- По одной оценке оформляется один заказ на трейд-ин
+create unique index if not exists idx_orders_appraisal_id on orders (appraisal_id)
+    where appraisal_id is not null and deleted_at is null;
+
+---- create above / drop below ----
+
+drop index if exists idx_orders_appraisal_id;
+alter table orders drop column if exists appraisal_id;
+drop table if exists trade_in_appraisals;
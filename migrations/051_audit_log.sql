@@ -0,0 +1,57 @@
+create table if not exists audit_log (
+                                         audit_id    bigserial primary key,
+                                         occurred_at timestamp with time zone not null default CURRENT_TIMESTAMP,
+                                         actor       varchar(100) not null,
+                                         method      varchar(10)  not null,
+                                         route       varchar(200) not null,
+                                         path        text         not null,
+                                         entity      varchar(50),
+                                         entity_id   varchar(100),
+                                         status_code integer      not null,
+                                         ip          varchar(45),
+                                         request_id  varchar(100),
+                                         before      jsonb,
+                                         after       jsonb
+);
+
+comment on table audit_log is 'Журнал аудита изменяющих запросов; записи только добавляются';
+comment on column audit_log.audit_id is 'Уникальный идентификатор записи журнала';
+comment on column audit_log.occurred_at is 'Дата и время запроса';
+comment on column audit_log.actor is 'Кто выполнил запрос (subject JWT, system для запросов без пользователя)';
+comment on column audit_log.method is 'HTTP-метод запроса';
+comment on column audit_log.route is 'Шаблон маршрута, например /v1/deals/:deal_id';
+comment on column audit_log.path is 'Фактический путь запроса';
+comment on column audit_log.entity is 'Изменяемая сущность, например deals, orders';
+comment on column audit_log.entity_id is 'Идентификатор изменяемой записи';
+comment on column audit_log.status_code is 'HTTP-статус ответа';
+comment on column audit_log.ip is 'IP-адрес клиента';
+comment on column audit_log.request_id is 'Идентификатор запроса (заголовок X-Request-ID)';
+comment on column audit_log.before is 'Состояние записи до изменения';
+comment on column audit_log.after is 'Состояние записи после изменения';
+
+create index if not exists idx_audit_log_occurred_at on audit_log (occurred_at);
+create index if not exists idx_audit_log_actor on audit_log (actor, occurred_at);
+create index if not exists idx_audit_log_entity on audit_log (entity, entity_id);
+
+-- Журнал только дополняется: изменение и удаление записей запрещены
+create or replace function reject_audit_log_change() returns trigger as
+$$
+begin
+    raise exception 'audit_log is append-only';
+end;
+$$ language plpgsql;
+
+create trigger audit_log_append_only
+    before update or delete on audit_log
+    for each row execute function reject_audit_log_change();
+
+create trigger audit_log_no_truncate
+    before truncate on audit_log
+    for each statement execute function reject_audit_log_change();
+
+---- create above / drop below ----
+
+drop trigger if exists audit_log_no_truncate on audit_log;
+drop trigger if exists audit_log_append_only on audit_log;
+drop function if exists reject_audit_log_change();
+drop table if exists audit_log cascade;
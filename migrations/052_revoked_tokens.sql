@@ -0,0 +1,20 @@
+create table if not exists revoked_tokens (
+                                              jti        varchar(255) primary key,
+                                              expires_at timestamp with time zone not null,
+                                              revoked_by varchar(100) not null,
+                                              reason     text,
+                                              revoked_at timestamp with time zone default CURRENT_TIMESTAMP
+);
+
+comment on table revoked_tokens is 'Отозванные токены: запросы с ними отклоняются до истечения срока действия';
+comment on column revoked_tokens.jti is 'Идентификатор токена (claim jti)';
+comment on column revoked_tokens.expires_at is 'Срок действия токена; после него запись не нужна и удаляется очисткой';
+comment on column revoked_tokens.revoked_by is 'Кто отозвал токен (subject JWT)';
+comment on column revoked_tokens.reason is 'Причина отзыва';
+comment on column revoked_tokens.revoked_at is 'Дата и время отзыва';
+
+create index if not exists idx_revoked_tokens_expires_at on revoked_tokens (expires_at);
+
+---- create above / drop below ----
+
+drop table if exists revoked_tokens;
@@ -0,0 +1,11 @@
+alter table audit_log add column if not exists impersonated_client_id integer;
+
+comment on column audit_log.impersonated_client_id is 'Клиент, от имени которого администратор выполнил запрос (заголовок X-Impersonate-Client)';
+
+create index if not exists idx_audit_log_impersonated on audit_log (impersonated_client_id, occurred_at)
+    where impersonated_client_id is not null;
+
+---- create above / drop below ----
+
+drop index if exists idx_audit_log_impersonated;
+alter table audit_log drop column if exists impersonated_client_id;
@@ -0,0 +1,25 @@
+-- Реестр арендаторов (групп дилерских центров) хранится в схеме public; данные каждого арендатора -
+-- в отдельной схеме tenant_<tenant_id>, куда применяются те же миграции. В схемах арендаторов
+-- миграция ничего не создает: таблица уже есть в public.
+create table if not exists public.tenants (
+                                              tenant_id      varchar(40) primary key,
+                                              name           varchar(255) not null,
+                                              created_at     timestamp with time zone default CURRENT_TIMESTAMP,
+                                              provisioned_at timestamp with time zone
+);
+
+comment on table public.tenants is 'Арендаторы - группы дилерских центров с изолированными данными';
+comment on column public.tenants.tenant_id is 'Идентификатор арендатора (claim tenant_id JWT); схема данных - tenant_<tenant_id>';
+comment on column public.tenants.name is 'Название группы дилерских центров';
+comment on column public.tenants.created_at is 'Дата и время регистрации';
+comment on column public.tenants.provisioned_at is 'Дата и время создания схемы и применения миграций; пусто - создание не завершено';
+
+---- create above / drop below ----
+
+-- Реестр удаляется только при откате схемы public, откат схем арендаторов его не затрагивает
+do $$
+begin
+    if current_schema() = 'public' then
+        drop table if exists public.tenants;
+    end if;
+end $$;
@@ -0,0 +1,7 @@
+-- Область ключа включает subject JWT, который у OIDC-провайдеров бывает длиннее 200 символов
+alter table idempotency_keys alter column scope type text;
+
+---- create above / drop below ----
+
+delete from idempotency_keys where length(scope) > 200;
+alter table idempotency_keys alter column scope type varchar(200);
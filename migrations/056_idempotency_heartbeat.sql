@@ -0,0 +1,7 @@
+alter table idempotency_keys add column if not exists heartbeat_at timestamp with time zone default CURRENT_TIMESTAMP;
+
+comment on column idempotency_keys.heartbeat_at is 'Время последнего подтверждения, что запрос с ключом еще выполняется; повтор перехватывает ключ только после его устаревания';
+
+---- create above / drop below ----
+
+alter table idempotency_keys drop column if exists heartbeat_at;
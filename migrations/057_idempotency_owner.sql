@@ -0,0 +1,7 @@
+alter table idempotency_keys add column if not exists owner char(32);
+
+comment on column idempotency_keys.owner is 'Случайный токен запроса, зарезервировавшего ключ; сохранить ответ или освободить ключ может только он, даже после перехвата ключа повтором';
+
+---- create above / drop below ----
+
+alter table idempotency_keys drop column if exists owner;
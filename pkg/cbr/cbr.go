@@ -0,0 +1,148 @@
+package cbr
+
+import (
+	"bufio"
+	"cliring/config"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Формат дат в запросе и ответе ЦБ РФ.
+const dateLayout = "02.01.2006"
+
+// maxErrorBody - сколько байт ответа ЦБ сохраняется в тексте ошибки.
+const maxErrorBody = 200
+
+// ErrMalformed возвращается, если ответ ЦБ не удалось разобрать.
+var ErrMalformed = errors.New("malformed cbr response")
+
+// Client получает официальные курсы валют ЦБ РФ (XML_daily.asp).
+type Client struct {
+	client *http.Client
+	url    string
+}
+
+// New возвращает Client с адресом и таймаутом запроса из настроек.
+func New(cfg *config.Config) *Client {
+	return &Client{client: &http.Client{Timeout: cfg.FX.CBRTimeout}, url: cfg.FX.CBRURL}
+}
+
+// valCurs - ответ XML_daily.asp.
+type valCurs struct {
+	Date    string   `xml:"Date,attr"`
+	Valutes []valute `xml:"Valute"`
+}
+
+type valute struct {
+	CharCode string `xml:"CharCode"`
+	Nominal  string `xml:"Nominal"`
+	Value    string `xml:"Value"`
+}
+
+// DailyRates возвращает курсы ЦБ, установленные на дату: рубли за единицу валюты по буквенному коду.
+// Если курсы на дату еще не установлены, ЦБ возвращает последние установленные, поэтому возвращается
+// и дата, с которой действуют курсы, в формате 2006-01-02.
+func (c *Client) DailyRates(ctx context.Context, date time.Time) (string, map[string]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"?date_req="+date.Format(dateLayout), nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to create cbr request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to get cbr rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return "", nil, fmt.Errorf("cbr responded with status %d: %s", resp.StatusCode, body)
+	}
+	return parse(resp.Body)
+}
+
+// parse разбирает ответ XML_daily.asp.
+func parse(r io.Reader) (string, map[string]float64, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charsetReader
+
+	var curs valCurs
+	if err := decoder.Decode(&curs); err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	date, err := time.Parse(dateLayout, curs.Date)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: invalid date %q", ErrMalformed, curs.Date)
+	}
+
+	rates := make(map[string]float64, len(curs.Valutes))
+	for _, v := range curs.Valutes {
+		// Курс указывается за Nominal единиц валюты с запятой в качестве десятичного разделителя
+		value, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(v.Value), ",", ".", 1), 64)
+		if err != nil || value <= 0 {
+			return "", nil, fmt.Errorf("%w: invalid value %q of %s", ErrMalformed, v.Value, v.CharCode)
+		}
+		nominal, err := strconv.Atoi(strings.TrimSpace(v.Nominal))
+		if err != nil || nominal <= 0 {
+			return "", nil, fmt.Errorf("%w: invalid nominal %q of %s", ErrMalformed, v.Nominal, v.CharCode)
+		}
+		rates[strings.TrimSpace(v.CharCode)] = value / float64(nominal)
+	}
+	return date.Format("2006-01-02"), rates, nil
+}
+
+// charsetReader перекодирует ответ ЦБ из windows-1251 в UTF-8.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	if !strings.EqualFold(charset, "windows-1251") {
+		return nil, fmt.Errorf("unsupported charset %s", charset)
+	}
+	return &cp1251Reader{r: bufio.NewReader(input)}, nil
+}
+
+// cp1251Reader декодирует windows-1251. Кроме ASCII нужны только буквы русского алфавита
+// из названий валют; прочие символы заменяются на U+FFFD.
+type cp1251Reader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+// Read реализует io.Reader.
+func (c *cp1251Reader) Read(p []byte) (int, error) {
+	for len(c.buf) < len(p) {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			if len(c.buf) > 0 {
+				break
+			}
+			return 0, err
+		}
+		c.buf = utf8.AppendRune(c.buf, cp1251Rune(b))
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+// cp1251Rune возвращает символ байта windows-1251.
+func cp1251Rune(b byte) rune {
+	switch {
+	case b < 0x80:
+		return rune(b)
+	case b >= 0xC0:
+		return rune(b-0xC0) + 'А'
+	case b == 0xA8:
+		return 'Ё'
+	case b == 0xB8:
+		return 'ё'
+	default:
+		return utf8.RuneError
+	}
+}
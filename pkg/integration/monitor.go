@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// recentCalls - по скольким последним вызовам считается доля ошибок.
+const recentCalls = 100
+
+// Stats - статистика вызовов внешней системы с момента запуска сервиса.
+type Stats struct {
+	Calls         int64
+	Failures      int64
+	LastSuccessAt *time.Time
+	LastFailureAt *time.Time
+	LastError     string
+	// ErrorRate - доля ошибок среди последних вызовов (не более recentCalls).
+	ErrorRate float64
+}
+
+// Monitor собирает статистику вызовов внешних систем (хранилища, банков, шлюзов рассылок) по имени интеграции.
+type Monitor struct {
+	mu    sync.Mutex
+	calls map[string]*calls
+}
+
+// calls - статистика одной интеграции и кольцевой буфер исходов последних вызовов.
+type calls struct {
+	stats  Stats
+	recent [recentCalls]bool
+	next   int
+	filled int
+}
+
+// NewMonitor возвращает пустой Monitor.
+func NewMonitor() *Monitor {
+	return &Monitor{calls: map[string]*calls{}}
+}
+
+// Register добавляет интеграцию, чтобы она попадала в статус ещё до первого вызова.
+func (m *Monitor) Register(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.calls[name]; !ok {
+		m.calls[name] = &calls{}
+	}
+}
+
+// Record учитывает исход вызова интеграции; err == nil - успешный вызов.
+func (m *Monitor) Record(name string, err error) {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.calls[name]
+	if !ok {
+		c = &calls{}
+		m.calls[name] = c
+	}
+
+	c.stats.Calls++
+	if err != nil {
+		c.stats.Failures++
+		c.stats.LastFailureAt = &now
+		c.stats.LastError = err.Error()
+	} else {
+		c.stats.LastSuccessAt = &now
+	}
+	c.recent[c.next] = err != nil
+	c.next = (c.next + 1) % recentCalls
+	if c.filled < recentCalls {
+		c.filled++
+	}
+}
+
+// Names возвращает имена известных интеграций по алфавиту.
+func (m *Monitor) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.calls))
+	for name := range m.calls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stats возвращает статистику интеграции.
+func (m *Monitor) Stats(name string) Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.calls[name]
+	if !ok {
+		return Stats{}
+	}
+
+	stats := c.stats
+	if c.filled > 0 {
+		var failures int
+		for _, failed := range c.recent[:c.filled] {
+			if failed {
+				failures++
+			}
+		}
+		stats.ErrorRate = float64(failures) / float64(c.filled)
+	}
+	return stats
+}
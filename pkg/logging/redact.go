@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Правила обработки полей при логировании.
+const (
+	RuleKeep   = "keep"
+	RuleMask   = "mask"
+	RuleHash   = "hash"
+	RuleBucket = "bucket"
+	RuleDrop   = "drop"
+)
+
+// maxSummaryItems - сколько элементов массива попадает в сводку; остальные учитываются только в count.
+const maxSummaryItems = 10
+
+// amountBuckets - верхние границы диапазонов, в которые округляются суммы.
+var amountBuckets = []struct {
+	limit float64
+	label string
+}{
+	{1e3, "<1k"},
+	{1e4, "1k-10k"},
+	{1e5, "10k-100k"},
+	{1e6, "100k-1M"},
+	{1e7, "1M-10M"},
+}
+
+// Redactor строит сводки тел запросов для логов без персональных данных.
+// По умолчанию в сводку попадают идентификаторы (поля *_id) как есть и суммы (amount*) по диапазонам,
+// остальные поля отбрасываются. Правила для конкретных полей переопределяют это поведение.
+type Redactor struct {
+	rules map[string]string
+}
+
+// NewRedactor возвращает Redactor с правилами вида поле -> правило.
+func NewRedactor(rules map[string]string) (*Redactor, error) {
+	for field, rule := range rules {
+		switch rule {
+		case RuleKeep, RuleMask, RuleHash, RuleBucket, RuleDrop:
+		default:
+			return nil, fmt.Errorf("unknown redaction rule %q for field %s", rule, field)
+		}
+	}
+	return &Redactor{rules: rules}, nil
+}
+
+// Summary возвращает сводку тела запроса: для объекта - обработанные поля, для массива - count
+// и сводки первых элементов.
+func (r *Redactor) Summary(payload any) logrus.Fields {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return logrus.Fields{"payload_error": err.Error()}
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return logrus.Fields{"payload_error": err.Error()}
+	}
+
+	switch v := value.(type) {
+	case []any:
+		items := make([]map[string]any, 0, min(len(v), maxSummaryItems))
+		for i, item := range v {
+			if i == maxSummaryItems {
+				break
+			}
+			if object, ok := item.(map[string]any); ok {
+				items = append(items, r.summarizeObject(object))
+			}
+		}
+		return logrus.Fields{"count": len(v), "items": items}
+	case map[string]any:
+		return r.summarizeObject(v)
+	default:
+		return logrus.Fields{}
+	}
+}
+
+// summarizeObject применяет правила к полям верхнего уровня объекта.
+func (r *Redactor) summarizeObject(object map[string]any) map[string]any {
+	summary := make(map[string]any, len(object))
+	for field, value := range object {
+		if value == nil {
+			continue
+		}
+		switch r.rule(field) {
+		case RuleKeep:
+			summary[field] = value
+		case RuleMask:
+			summary[field] = "***"
+		case RuleHash:
+			sum := sha256.Sum256([]byte(fmt.Sprint(value)))
+			summary[field] = hex.EncodeToString(sum[:4])
+		case RuleBucket:
+			if amount, ok := value.(float64); ok {
+				summary[field] = bucket(amount)
+			}
+		}
+	}
+	return summary
+}
+
+// rule возвращает правило для поля с учетом правил по умолчанию.
+func (r *Redactor) rule(field string) string {
+	if rule, ok := r.rules[field]; ok {
+		return rule
+	}
+	switch {
+	case strings.HasSuffix(field, "_id"):
+		return RuleKeep
+	case strings.HasPrefix(field, "amount"):
+		return RuleBucket
+	default:
+		return RuleDrop
+	}
+}
+
+// bucket округляет сумму до диапазона.
+func bucket(amount float64) string {
+	if amount < 0 {
+		return "-" + bucket(-amount)
+	}
+	for _, b := range amountBuckets {
+		if amount < b.limit {
+			return b.label
+		}
+	}
+	return ">=10M"
+}
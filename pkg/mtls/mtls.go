@@ -0,0 +1,214 @@
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Files - пути к сертификату, ключу и корневым сертификатам проверяемой стороны.
+// Пустой CAFile означает: сервер не требует клиентский сертификат, клиент доверяет системным корневым сертификатам.
+type Files struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// Reloader хранит сертификат и корневые сертификаты и перечитывает файлы при их замене,
+// чтобы ротация сертификатов не требовала перезапуска сервиса. Новые соединения используют
+// актуальные файлы, установленные соединения не прерываются.
+type Reloader struct {
+	files Files
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime time.Time
+}
+
+// NewReloader загружает файлы; ошибка возвращается, если сертификат, ключ или корневые сертификаты не читаются.
+func NewReloader(files Files) (*Reloader, error) {
+	r := &Reloader{files: files}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// load читает файлы и заменяет текущие сертификаты.
+func (r *Reloader) load() error {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+
+	var cert *tls.Certificate
+	if r.files.CertFile != "" || r.files.KeyFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.files.CertFile, r.files.KeyFile)
+		if err != nil {
+			return fmt.Errorf("unable to load certificate: %w", err)
+		}
+		cert = &pair
+	}
+
+	var pool *x509.CertPool
+	if r.files.CAFile != "" {
+		pem, err := os.ReadFile(r.files.CAFile)
+		if err != nil {
+			return fmt.Errorf("unable to read ca file: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("ca file contains no certificates")
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.pool, r.modTime = cert, pool, modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// lastModified возвращает время последнего изменения файлов.
+func (r *Reloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{r.files.CertFile, r.files.KeyFile, r.files.CAFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unable to stat %s: %w", path, err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// Run проверяет файлы с периодом interval и перечитывает их после замены до отмены ctx.
+// Если новые файлы не читаются (например, ключ еще не записан), остаются прежние сертификаты.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modTime, err := r.lastModified()
+			if err != nil {
+				logrus.Errorf("error check certificates %s", err.Error())
+				continue
+			}
+			r.mu.RLock()
+			changed := !modTime.Equal(r.modTime)
+			r.mu.RUnlock()
+			if !changed {
+				continue
+			}
+			if err := r.load(); err != nil {
+				logrus.Errorf("error reload certificates %s", err.Error())
+				continue
+			}
+			logrus.Infof("certificates %s reloaded", r.files.CertFile)
+		}
+	}
+}
+
+// current возвращает текущие сертификат и корневые сертификаты.
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, r.pool
+}
+
+// ServerConfig возвращает настройки TLS сервера. С корневыми сертификатами клиентов сервер
+// проверяет клиентский сертификат: requireClientCert отклоняет соединения без него, иначе
+// сертификат проверяется, только если клиент его предъявил.
+func (r *Reloader) ServerConfig(requireClientCert bool) *tls.Config {
+	clientAuth := tls.VerifyClientCertIfGiven
+	if requireClientCert {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// GetCertificate нужен http.Server для запуска без файлов сертификата; соединения настраивает GetConfigForClient
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			if cert == nil {
+				return nil, errors.New("server certificate is not configured")
+			}
+			return cert, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.current()
+			if cert == nil {
+				return nil, errors.New("server certificate is not configured")
+			}
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if pool != nil {
+				config.ClientCAs = pool
+				config.ClientAuth = clientAuth
+			}
+			return config, nil
+		},
+	}
+}
+
+// ClientConfig возвращает настройки TLS исходящих запросов: клиентский сертификат предъявляется
+// по запросу сервера, сертификат сервера проверяется по корневым сертификатам из CAFile,
+// а без него - по системным.
+func (r *Reloader) ClientConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			if cert == nil {
+				// Пустой сертификат: сервер сам решает, допускать ли соединение без него
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		},
+	}
+	if r.files.CAFile == "" {
+		return config
+	}
+
+	// RootCAs нельзя заменить после создания транспорта, поэтому сертификат сервера проверяется
+	// вручную по актуальным корневым сертификатам; стандартная проверка отключается только ради этого
+	config.InsecureSkipVerify = true
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("server presented no certificate")
+		}
+		_, pool := r.current()
+		opts := x509.VerifyOptions{
+			DNSName:       state.ServerName,
+			Roots:         pool,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range state.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := state.PeerCertificates[0].Verify(opts)
+		return err
+	}
+	return config
+}
@@ -0,0 +1,251 @@
+package oidc
+
+import (
+	"cliring/config"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// discoveryPath - путь документа обнаружения относительно издателя.
+const discoveryPath = "/.well-known/openid-configuration"
+
+// minKeysRefresh - минимальный интервал между повторными загрузками ключей при неизвестном kid,
+// чтобы токены с подложным kid не приводили к запросу к провайдеру на каждый вызов API.
+const minKeysRefresh = time.Minute
+
+// maxResponseBody - предельный размер документа обнаружения и набора ключей.
+const maxResponseBody = 1 << 20
+
+// signingMethods - допустимые алгоритмы подписи токенов провайдера. Симметричные алгоритмы
+// не допускаются: иначе открытый ключ провайдера можно использовать как секрет HMAC.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// ErrUnknownKey возвращается, если ключа подписи токена нет в наборе ключей провайдера.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// Provider проверяет токены, выпущенные внешним провайдером OpenID Connect: подпись по ключам
+// из jwks_uri документа обнаружения, издателя, аудиторию и сроки действия с допуском на расхождение часов.
+type Provider struct {
+	client          *http.Client
+	issuer          string
+	audience        string
+	leeway          time.Duration
+	refreshInterval time.Duration
+
+	mu        sync.Mutex
+	jwksURI   string
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+// New возвращает Provider с издателем, аудиторией и таймаутами из настроек.
+func New(cfg *config.Config) *Provider {
+	return &Provider{
+		client:          &http.Client{Timeout: cfg.Auth.OIDCTimeout},
+		issuer:          strings.TrimSuffix(cfg.Auth.OIDCIssuer, "/"),
+		audience:        cfg.Auth.OIDCAudience,
+		leeway:          cfg.Auth.JWTLeeway,
+		refreshInterval: cfg.Auth.OIDCKeysRefreshInterval,
+	}
+}
+
+// discoveryDocument - используемые поля документа обнаружения.
+type discoveryDocument struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// jsonWebKey - ключ набора JWKS (RFC 7517).
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// Discover загружает документ обнаружения и ключи подписи провайдера. Вызывать необязательно:
+// при недоступности провайдера на старте загрузка повторяется при проверке первого токена.
+func (p *Provider) Discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refreshKeys(ctx)
+}
+
+// Parse проверяет подпись и стандартные claims токена и возвращает токен с claims в виде jwt.MapClaims.
+func (p *Provider) Parse(ctx context.Context, tokenString string) (*jwt.Token, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithLeeway(p.leeway),
+		jwt.WithExpirationRequired(),
+	}
+	if p.audience != "" {
+		options = append(options, jwt.WithAudience(p.audience))
+	}
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	}, options...)
+}
+
+// key возвращает ключ подписи по kid. Неизвестный kid означает ротацию ключей у провайдера:
+// набор ключей загружается заново, но не чаще minKeysRefresh.
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stale := p.keys == nil || (p.refreshInterval > 0 && time.Since(p.fetchedAt) > p.refreshInterval)
+	if key, ok := p.lookup(kid); ok && !stale {
+		return key, nil
+	}
+	if stale || time.Since(p.fetchedAt) > minKeysRefresh {
+		if err := p.refreshKeys(ctx); err != nil {
+			// Провайдер недоступен: токены проверяются по ранее загруженным ключам
+			if key, ok := p.lookup(kid); ok {
+				return key, nil
+			}
+			return nil, err
+		}
+	}
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: kid %q", ErrUnknownKey, kid)
+}
+
+// lookup ищет ключ по kid; токен без kid проверяется единственным ключом набора.
+func (p *Provider) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// refreshKeys загружает документ обнаружения (однократно) и набор ключей. Вызывается под p.mu.
+func (p *Provider) refreshKeys(ctx context.Context) error {
+	// Время попытки фиксируется и при ошибке, чтобы не обращаться к недоступному провайдеру на каждый запрос
+	p.fetchedAt = time.Now()
+
+	if p.jwksURI == "" {
+		var doc discoveryDocument
+		if err := p.getJSON(ctx, p.issuer+discoveryPath, &doc); err != nil {
+			return fmt.Errorf("unable to get oidc discovery document: %w", err)
+		}
+		if strings.TrimSuffix(doc.Issuer, "/") != p.issuer {
+			return fmt.Errorf("oidc discovery document issuer %q differs from configured issuer %q", doc.Issuer, p.issuer)
+		}
+		if doc.JWKSURI == "" {
+			return errors.New("oidc discovery document has no jwks_uri")
+		}
+		p.jwksURI = doc.JWKSURI
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.jwksURI, &set); err != nil {
+		return fmt.Errorf("unable to get oidc signing keys: %w", err)
+	}
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		// Ключи шифрования токенами не используются
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			// Ключ неподдерживаемого типа не мешает проверке токенов остальными ключами
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return errors.New("oidc provider has no supported signing keys")
+	}
+	p.keys = keys
+	return nil
+}
+
+// getJSON выполняет GET-запрос и разбирает ответ в JSON.
+func (p *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseBody)).Decode(v)
+}
+
+// publicKey возвращает открытый ключ RSA или ECDSA.
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rsa modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rsa exponent: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("rsa exponent is too large")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ec x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ec y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
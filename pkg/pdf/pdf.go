@@ -0,0 +1,153 @@
+package pdf
+
+import (
+	"bytes"
+	"cliring/config"
+	"fmt"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Параметры страницы A4 в миллиметрах.
+const (
+	pageWidth    = 210.0
+	pageMargin   = 15.0
+	contentWidth = pageWidth - 2*pageMargin
+	lineHeight   = 6.0
+)
+
+// fontFamily - имя, под которым в документ встраивается шрифт из настроек.
+const fontFamily = "document"
+
+// Document - содержимое документа: заголовок, разделы с текстом и таблицами и блоки подписей сторон.
+type Document struct {
+	Title      string
+	Subtitle   string
+	Sections   []Section
+	Signatures []Signature
+}
+
+// Section - раздел документа: заголовок, абзацы и необязательная таблица после них.
+type Section struct {
+	Heading    string
+	Paragraphs []string
+	Table      *Table
+}
+
+// Table - таблица с заголовком. Widths задает ширину колонок в миллиметрах; без Widths колонки равной ширины.
+type Table struct {
+	Header []string
+	Widths []float64
+	Rows   [][]string
+}
+
+// Signature - блок подписи стороны.
+type Signature struct {
+	Party string
+	Name  string
+}
+
+// Renderer формирует PDF-документы. Кириллица выводится шрифтом TrueType из настроек.
+type Renderer struct {
+	fontPath string
+}
+
+// New возвращает Renderer со шрифтом из настроек.
+func New(cfg *config.Config) *Renderer {
+	return &Renderer{fontPath: cfg.PDF.FontPath}
+}
+
+// Render формирует PDF-документ.
+func (r *Renderer) Render(doc *Document) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	pdf.AddUTF8Font(fontFamily, "", r.fontPath)
+	pdf.AddPage()
+
+	pdf.SetFont(fontFamily, "", 14)
+	pdf.MultiCell(0, 8, doc.Title, "", "C", false)
+	if doc.Subtitle != "" {
+		pdf.SetFont(fontFamily, "", 10)
+		pdf.MultiCell(0, lineHeight, doc.Subtitle, "", "C", false)
+	}
+
+	for _, section := range doc.Sections {
+		pdf.Ln(lineHeight / 2)
+		if section.Heading != "" {
+			pdf.SetFont(fontFamily, "", 12)
+			pdf.MultiCell(0, lineHeight+1, section.Heading, "", "L", false)
+		}
+		pdf.SetFont(fontFamily, "", 10)
+		for _, paragraph := range section.Paragraphs {
+			pdf.MultiCell(0, lineHeight-1, paragraph, "", "J", false)
+		}
+		if section.Table != nil {
+			writeTable(pdf, section.Table)
+		}
+	}
+
+	// Подписи сторон - по две в строке
+	pdf.SetFont(fontFamily, "", 10)
+	for i := 0; i < len(doc.Signatures); i += 2 {
+		pdf.Ln(lineHeight * 2)
+		y := pdf.GetY()
+		bottom := y
+		for j, signature := range doc.Signatures[i:min(i+2, len(doc.Signatures))] {
+			x := pageMargin + float64(j)*contentWidth/2
+			pdf.SetXY(x, y)
+			pdf.MultiCell(contentWidth/2-5, lineHeight-1, signature.Party, "", "L", false)
+			pdf.SetX(x)
+			pdf.MultiCell(contentWidth/2-5, lineHeight*2, "_______________ / "+signature.Name, "", "L", false)
+			bottom = max(bottom, pdf.GetY())
+		}
+		pdf.SetY(bottom)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("unable to render pdf: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeTable выводит таблицу с рамками. Строка, не помещающаяся на странице, переносится на следующую.
+func writeTable(pdf *fpdf.Fpdf, table *Table) {
+	widths := table.Widths
+	if len(widths) != len(table.Header) {
+		widths = make([]float64, len(table.Header))
+		for i := range widths {
+			widths[i] = contentWidth / float64(len(table.Header))
+		}
+	}
+
+	_, pageHeight := pdf.GetPageSize()
+	writeRow := func(cells []string) {
+		// Высота строки - по самой длинной ячейке
+		lines := 1
+		for i, cell := range cells {
+			if n := len(pdf.SplitText(cell, widths[i]-2)); n > lines {
+				lines = n
+			}
+		}
+		height := float64(lines) * (lineHeight - 1)
+		if pdf.GetY()+height > pageHeight-pageMargin {
+			pdf.AddPage()
+		}
+
+		x, y := pdf.GetXY()
+		for i, cell := range cells {
+			pdf.Rect(x, y, widths[i], height, "D")
+			pdf.SetXY(x+1, y)
+			pdf.MultiCell(widths[i]-2, lineHeight-1, cell, "", "L", false)
+			x += widths[i]
+		}
+		pdf.SetXY(pageMargin, y+height)
+	}
+
+	pdf.Ln(1)
+	writeRow(table.Header)
+	for _, row := range table.Rows {
+		writeRow(row)
+	}
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// querier - методы пула и соединения, на которых выполняются запросы.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+}
+
+// heldConnKey - ключ контекста с соединением, закрепленным WithConn.
+type heldConnKey struct{}
+
+// heldConn - закрепленное за контекстом соединение; после открепления запросы снова идут в пул.
+type heldConn struct {
+	mu       sync.Mutex
+	conn     *pgxpool.Conn
+	released bool
+}
+
+// WithConn возвращает контекст, запросы с которым выполняются на соединении conn, а не на пуле, например
+// пока на его сессии держится блокировка: запросам под блокировкой не нужно второе соединение из пула.
+// Запросы с этим контекстом должны идти последовательно. Возвращаемая функция открепляет соединение;
+// вернуть его в пул после открепления должен вызывающий код.
+func WithConn(ctx context.Context, conn *pgxpool.Conn) (context.Context, func()) {
+	held := &heldConn{conn: conn}
+	release := func() {
+		held.mu.Lock()
+		defer held.mu.Unlock()
+		held.released = true
+	}
+	return context.WithValue(ctx, heldConnKey{}, held), release
+}
+
+// connFor возвращает закрепленное за контекстом соединение или nil, если его нет или оно откреплено.
+func connFor(ctx context.Context) *pgxpool.Conn {
+	held, ok := ctx.Value(heldConnKey{}).(*heldConn)
+	if !ok {
+		return nil
+	}
+	held.mu.Lock()
+	defer held.mu.Unlock()
+	if held.released {
+		return nil
+	}
+	return held.conn
+}
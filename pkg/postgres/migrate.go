@@ -0,0 +1,274 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/tern/v2/migrate"
+	"github.com/sirupsen/logrus"
+)
+
+// MigrationInfo - миграция из директории миграций.
+type MigrationInfo struct {
+	Version int32
+	Name    string
+	Applied bool
+}
+
+// MigrationStatus - версия схемы базы данных и миграции из директории.
+type MigrationStatus struct {
+	// Current - примененная версия схемы public.
+	Current int32
+	// Target - версия, до которой миграции применяются при старте и командой migrate up.
+	Target     int32
+	Migrations []MigrationInfo
+	// Tenants - примененные версии схем арендаторов.
+	Tenants []TenantSchemaVersion
+}
+
+// TenantSchemaVersion - примененная версия схемы арендатора.
+type TenantSchemaVersion struct {
+	TenantID string
+	Current  int32
+}
+
+// Connect открывает пул соединений без применения миграций, например для команды migrate.
+func (db *Postgres) Connect(ctx context.Context) error {
+	dsn := db.dsn()
+	if dsn == "" {
+		return ErrDSNRequired
+	}
+	pool, err := db.newPool(ctx, dsn, "")
+	if err != nil {
+		return fmt.Errorf("unable to connect to database: %w", err)
+	}
+	db.setPool(pool)
+	return nil
+}
+
+// withMigrator выполняет fn с мигрантом tern на соединении conn под advisory-блокировкой миграций.
+func (db *Postgres) withMigrator(ctx context.Context, conn *pgx.Conn, fn func(migrator *migrate.Migrator) error) error {
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("unable to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Снимаем блокировку даже если контекст уже отменён
+		if _, err := conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockKey); err != nil {
+			logrus.Errorf("unable to release migration lock: %s", err)
+			// Соединение с неснятой блокировкой не возвращается в пул: закрытие сессии снимает ее
+			_ = conn.Close(context.WithoutCancel(ctx))
+		}
+	}()
+
+	migrator, err := db.newMigrator(ctx, conn)
+	if err != nil {
+		return err
+	}
+	migrator.OnStart = func(sequence int32, name, direction, _ string) {
+		logrus.Infof("Migrating %s %d %s", direction, sequence, name)
+	}
+	return fn(migrator)
+}
+
+// newMigrator создает мигрант tern на соединении conn и загружает миграции из директории.
+// Таблица версии создается в схеме соединения, поэтому у каждого арендатора своя версия.
+func (db *Postgres) newMigrator(ctx context.Context, conn *pgx.Conn) (*migrate.Migrator, error) {
+	migrator, err := migrate.NewMigrator(ctx, conn, db.config.MigrationVersionTable)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize migrator: %w", err)
+	}
+	if err := migrator.LoadMigrations(os.DirFS(db.config.MigrationsDir)); err != nil {
+		return nil, fmt.Errorf("unable to load migrations from %s: %w", db.config.MigrationsDir, err)
+	}
+	return migrator, nil
+}
+
+// targetVersion возвращает версию, закрепленную в конфигурации, или последнюю из директории.
+func (db *Postgres) targetVersion(migrator *migrate.Migrator) (int32, error) {
+	latest := int32(len(migrator.Migrations))
+	target := db.config.MigrationVersion
+	if target == 0 {
+		target = latest
+	}
+	if target > latest {
+		return 0, fmt.Errorf("target schema version %d is not found in %s", target, db.config.MigrationsDir)
+	}
+	return target, nil
+}
+
+// checkSchemaVersion запоминает версию схемы без применения миграций. Схема ниже целевой версии
+// означает, что команда migrate up еще не выполнена: соединение не считается открытым, и сервис
+// остается неготовым до обновления схемы.
+func (db *Postgres) checkSchemaVersion(ctx context.Context) error {
+	return db.withConn(ctx, func(conn *pgx.Conn) error {
+		migrator, err := db.newMigrator(ctx, conn)
+		if err != nil {
+			return err
+		}
+		current, err := migrator.GetCurrentVersion(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to get current schema version: %w", err)
+		}
+		target, err := db.targetVersion(migrator)
+		if err != nil {
+			return err
+		}
+		if current < target {
+			return fmt.Errorf("schema version %d is behind target version %d, run cliring migrate up", current, target)
+		}
+		db.schemaVersion.Store(current)
+		return nil
+	})
+}
+
+// forEachSchema выполняет fn для схемы public (tenant пуст) и для схемы каждого арендатора на отдельном
+// соединении. Ошибка в одной схеме прерывает обход. Арендаторы читаются до изменения public: откат может
+// удалить реестр.
+func (db *Postgres) forEachSchema(ctx context.Context, fn func(conn *pgx.Conn, tenant string) error) error {
+	tenants, err := db.Tenants(ctx)
+	if err != nil {
+		return err
+	}
+	err = db.withConn(ctx, func(conn *pgx.Conn) error {
+		return fn(conn, "")
+	})
+	if err != nil {
+		return err
+	}
+	for _, tenant := range tenants {
+		if tenant.ProvisionedAt == nil {
+			continue
+		}
+		err := db.withTenantConn(ctx, tenant.ID, func(conn *pgx.Conn) error {
+			return fn(conn, tenant.ID)
+		})
+		if err != nil {
+			return fmt.Errorf("tenant %s: %w", tenant.ID, err)
+		}
+	}
+	return nil
+}
+
+// MigrateUp применяет миграции до целевой версии к схеме public и к схемам арендаторов.
+func (db *Postgres) MigrateUp(ctx context.Context) error {
+	var version int32
+	err := db.withConn(ctx, func(conn *pgx.Conn) (err error) {
+		version, err = db.migrateSchema(ctx, conn)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	db.schemaVersion.Store(version)
+
+	tenants, err := db.Tenants(ctx)
+	if err != nil {
+		return err
+	}
+	for _, tenant := range tenants {
+		if err := db.migrateTenant(ctx, tenant.ID); err != nil {
+			return fmt.Errorf("tenant %s: %w", tenant.ID, err)
+		}
+	}
+	return nil
+}
+
+// MigrateDown откатывает последнюю примененную миграцию в схеме public и в схемах арендаторов.
+func (db *Postgres) MigrateDown(ctx context.Context) error {
+	return db.forEachSchema(ctx, func(conn *pgx.Conn, tenant string) error {
+		return db.withMigrator(ctx, conn, func(migrator *migrate.Migrator) error {
+			current, err := migrator.GetCurrentVersion(ctx)
+			if err != nil {
+				return fmt.Errorf("unable to get current schema version: %w", err)
+			}
+			if current == 0 {
+				return errors.New("no migrations to roll back")
+			}
+			if err := migrator.MigrateTo(ctx, current-1); err != nil {
+				return fmt.Errorf("unable to roll back migration %d: %w", current, err)
+			}
+			if tenant == "" {
+				db.schemaVersion.Store(current - 1)
+			}
+			return nil
+		})
+	})
+}
+
+// MigrateTo применяет или откатывает миграции до версии version в схеме public и в схемах арендаторов.
+func (db *Postgres) MigrateTo(ctx context.Context, version int32) error {
+	return db.forEachSchema(ctx, func(conn *pgx.Conn, tenant string) error {
+		return db.withMigrator(ctx, conn, func(migrator *migrate.Migrator) error {
+			if version < 0 || version > int32(len(migrator.Migrations)) {
+				return fmt.Errorf("schema version %d is not found in %s", version, db.config.MigrationsDir)
+			}
+			if err := migrator.MigrateTo(ctx, version); err != nil {
+				return fmt.Errorf("unable to migrate to version %d: %w", version, err)
+			}
+			if tenant == "" {
+				db.schemaVersion.Store(version)
+			}
+			return nil
+		})
+	})
+}
+
+// MigrationStatus возвращает примененную и целевую версии схемы, список миграций и версии схем арендаторов.
+func (db *Postgres) MigrationStatus(ctx context.Context) (*MigrationStatus, error) {
+	status := &MigrationStatus{}
+	err := db.withConn(ctx, func(conn *pgx.Conn) error {
+		migrator, err := db.newMigrator(ctx, conn)
+		if err != nil {
+			return err
+		}
+		status.Current, err = migrator.GetCurrentVersion(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to get current schema version: %w", err)
+		}
+		status.Target, err = db.targetVersion(migrator)
+		if err != nil {
+			return err
+		}
+		status.Migrations = make([]MigrationInfo, 0, len(migrator.Migrations))
+		for _, migration := range migrator.Migrations {
+			status.Migrations = append(status.Migrations, MigrationInfo{
+				Version: migration.Sequence,
+				Name:    migration.Name,
+				Applied: migration.Sequence <= status.Current,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tenants, err := db.Tenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tenant := range tenants {
+		if tenant.ProvisionedAt == nil {
+			continue
+		}
+		err := db.withTenantConn(ctx, tenant.ID, func(conn *pgx.Conn) error {
+			migrator, err := db.newMigrator(ctx, conn)
+			if err != nil {
+				return err
+			}
+			version, err := migrator.GetCurrentVersion(ctx)
+			if err != nil {
+				return fmt.Errorf("unable to get current schema version: %w", err)
+			}
+			status.Tenants = append(status.Tenants, TenantSchemaVersion{TenantID: tenant.ID, Current: version})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenant.ID, err)
+		}
+	}
+	return status, nil
+}
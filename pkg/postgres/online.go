@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// OnlineMigration - изменение схемы большой таблицы, которое нельзя выполнить в миграции tern
+// без долгой блокировки: миграции tern выполняются в транзакции при старте, а CREATE INDEX
+// CONCURRENTLY в транзакции невозможен, и UPDATE миллионов строк одним запросом держит блокировку
+// до конца. Порядок перевода колонки на новый тип без простоя:
+//  1. миграция tern добавляет новую колонку (без значения по умолчанию - это мгновенно);
+//  2. включается флаг двойной записи (MIGRATION_DUAL_WRITE), и сервис пишет обе колонки;
+//  3. OnlineMigration заполняет новую колонку пачками и строит индексы CONCURRENTLY;
+//  4. после завершения (таблица online_migrations) следующая миграция tern переключает чтение
+//     и удаляет старую колонку, флаг выключается.
+type OnlineMigration struct {
+	// Name - уникальное имя, под которым фиксируется завершение миграции.
+	Name string
+	// Backfills выполняются по порядку до индексов.
+	Backfills []Backfill
+	// Indexes строятся CONCURRENTLY после заполнения данных.
+	Indexes []ConcurrentIndex
+}
+
+// Backfill - пакетное заполнение данных.
+type Backfill struct {
+	// Query обновляет не более $1 строк, которые еще не заполнены, например:
+	//   UPDATE orders SET amount_new = amount
+	//   WHERE order_id IN (SELECT order_id FROM orders WHERE amount_new IS NULL LIMIT $1)
+	// Пачки выполняются, пока запрос изменяет строки.
+	Query string
+}
+
+// ConcurrentIndex - индекс, создаваемый без блокировки записи в таблицу.
+type ConcurrentIndex struct {
+	Name string
+	// Definition - часть после имени индекса, например "ON orders (deal_id)".
+	Definition string
+}
+
+// OnlineOptions задает темп фоновых миграций.
+type OnlineOptions struct {
+	// BatchSize - число строк в одной пачке заполнения.
+	BatchSize int
+	// Pause - пауза между пачками, чтобы не нагружать БД в рабочее время.
+	Pause time.Duration
+}
+
+// RunOnlineMigrations выполняет незавершенные фоновые миграции схемы арендатора из ctx (без арендатора -
+// схемы public) на отдельном соединении, чтобы не занимать соединение сервиса. Миграции выполняются
+// под advisory-блокировкой схемы: при нескольких экземплярах схему переводит один, остальные пропускают
+// запуск, а схемы разных арендаторов переводятся независимо.
+// Прерванная миграция продолжается со следующего старта: пачки и индексы идемпотентны.
+func (db *Postgres) RunOnlineMigrations(ctx context.Context, migrations []OnlineMigration, opts OnlineOptions) error {
+	if len(migrations) == 0 {
+		return nil
+	}
+	if opts.BatchSize <= 0 {
+		return fmt.Errorf("invalid online migration batch size %d", opts.BatchSize)
+	}
+
+	schema := "public"
+	if db.tenantOf != nil {
+		if tenant := db.tenantOf(ctx); tenant != "" {
+			if !ValidTenantID(tenant) {
+				return fmt.Errorf("%w: %s", ErrUnknownTenant, tenant)
+			}
+			schema = TenantSchema(tenant)
+		}
+	}
+
+	conn, err := db.connectSchema(ctx, db.dsn(), schema)
+	if err != nil {
+		return fmt.Errorf("unable to connect to database: %w", err)
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	var locked bool
+	err = conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1, hashtext($2))`, onlineMigrationLockKey, schema).Scan(&locked)
+	if err != nil {
+		return fmt.Errorf("unable to acquire online migration lock: %w", err)
+	}
+	if !locked {
+		logrus.Infof("Online migrations of schema %s are run by another instance", schema)
+		return nil
+	}
+	// Блокировка сессионная и снимается при закрытии соединения
+
+	for _, migration := range migrations {
+		var done bool
+		err := conn.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM online_migrations WHERE name = $1)`,
+			migration.Name).Scan(&done)
+		if err != nil {
+			return fmt.Errorf("unable to check online migration %s: %w", migration.Name, err)
+		}
+		if done {
+			continue
+		}
+
+		logrus.Infof("Starting online migration %s in schema %s", migration.Name, schema)
+		if err := runOnlineMigration(ctx, conn, migration, opts); err != nil {
+			return fmt.Errorf("online migration %s: %w", migration.Name, err)
+		}
+		if _, err := conn.Exec(ctx, `INSERT INTO online_migrations (name) VALUES ($1)`, migration.Name); err != nil {
+			return fmt.Errorf("unable to record online migration %s: %w", migration.Name, err)
+		}
+		logrus.Infof("Online migration %s completed in schema %s", migration.Name, schema)
+	}
+
+	return nil
+}
+
+// onlineMigrationLockKey - первый ключ advisory-блокировки фоновых миграций, второй - хеш имени схемы.
+const onlineMigrationLockKey int32 = 37643
+
+// runOnlineMigration заполняет данные пачками и строит индексы.
+func runOnlineMigration(ctx context.Context, conn *pgx.Conn, migration OnlineMigration, opts OnlineOptions) error {
+	for i, backfill := range migration.Backfills {
+		var total int64
+		for {
+			// Каждая пачка - отдельная транзакция, блокировки строк держатся только на время пачки
+			tag, err := conn.Exec(ctx, backfill.Query, opts.BatchSize)
+			if err != nil {
+				return fmt.Errorf("backfill %d: %w", i, err)
+			}
+			if tag.RowsAffected() == 0 {
+				break
+			}
+			total += tag.RowsAffected()
+			logrus.Debugf("Online migration %s: backfill %d updated %d rows", migration.Name, i, total)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(opts.Pause):
+			}
+		}
+	}
+
+	for _, index := range migration.Indexes {
+		if err := createIndexConcurrently(ctx, conn, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createIndexConcurrently строит индекс без блокировки записи. Прерванное построение оставляет
+// невалидный индекс, который удаляется перед повторной попыткой. Индекс ищется только в схеме
+// соединения: у каждого арендатора индексы с теми же именами.
+func createIndexConcurrently(ctx context.Context, conn *pgx.Conn, index ConcurrentIndex) error {
+	var valid bool
+	err := conn.QueryRow(ctx, `
+		SELECT i.indisvalid
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		WHERE c.relname = $1 AND c.relnamespace = current_schema()::regnamespace`, index.Name).Scan(&valid)
+	switch {
+	case err == nil && valid:
+		return nil
+	case err == nil:
+		identifier := pgx.Identifier{index.Name}.Sanitize()
+		if _, err := conn.Exec(ctx, `DROP INDEX CONCURRENTLY IF EXISTS `+identifier); err != nil {
+			return fmt.Errorf("unable to drop invalid index %s: %w", index.Name, err)
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return fmt.Errorf("unable to check index %s: %w", index.Name, err)
+	}
+
+	query := `CREATE INDEX CONCURRENTLY IF NOT EXISTS ` + pgx.Identifier{index.Name}.Sanitize() + ` ` + index.Definition
+	if _, err := conn.Exec(ctx, query); err != nil {
+		return fmt.Errorf("unable to create index %s: %w", index.Name, err)
+	}
+	return nil
+}
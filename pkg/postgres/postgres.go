@@ -6,84 +6,409 @@ import (
 	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/tern/v2/migrate"
 	"github.com/sirupsen/logrus"
-	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var (
 	ErrDSNRequired = errors.New("dsn required")
 )
 
+// errNotOpen возвращается запросам, пока пул соединений не открыт.
+var errNotOpen = errors.New("connection is not open")
+
+// reconnectGrace - время, в течение которого прежний пул остается открытым после переподключения,
+// чтобы выполняющиеся на нем запросы успели завершиться.
+const reconnectGrace = time.Minute
+
+// migrationLockKey - ключ advisory-блокировки, под которой экземпляры сервиса применяют миграции.
+const migrationLockKey int64 = 37642
+
+// Postgres - пул соединений с базой данных. Запросы обработчиков и фоновых задач выполняются
+// на разных соединениях пула: одно соединение pgx нельзя использовать параллельно.
 type Postgres struct {
-	Conn   *pgx.Conn
-	config config.Postgres
+	// mu защищает pool и config.DSN: Open, Reconnect и WatchDSN заменяют их параллельно с запросами.
+	mu sync.RWMutex
+	// pool - пул соединений со схемой public; nil, пока база данных не открыта.
+	pool *pgxpool.Pool
+	// reconnectMu не дает открыть или заменить пул одновременно из Maintain и WatchDSN.
+	reconnectMu sync.Mutex
+	// schemaVersion - версия схемы после применения миграций при старте.
+	schemaVersion atomic.Int32
+	config        config.Postgres
+	dualWrite     map[string]bool
+	// opened закрывается после первого успешного открытия соединения и применения миграций.
+	opened     chan struct{}
+	openedOnce sync.Once
+	// retryPolicy задает повтор запросов после временных ошибок.
+	retryPolicy RetryPolicy
+	// tracer записывает метрики запросов всех соединений.
+	tracer *Tracer
+	// tenantOf возвращает арендатора из контекста запроса, tenantPools - пулы соединений со схемами арендаторов.
+	tenantOf    func(ctx context.Context) string
+	tenantMu    sync.Mutex
+	tenantPools map[string]*pgxpool.Pool
 }
 
 // New возвращает новый экземпляр Postgres, связанный с заданным именем источника данных.
 func New(cfg *config.Config) *Postgres {
 	db := &Postgres{
-		Conn:   nil,
-		config: cfg.Postgres,
+		config:    cfg.Postgres,
+		dualWrite: make(map[string]bool, len(cfg.Postgres.DualWrite)),
+		opened:    make(chan struct{}),
+		retryPolicy: RetryPolicy{
+			MaxAttempts: cfg.Postgres.RetryMaxAttempts,
+			BaseDelay:   cfg.Postgres.RetryBaseDelay,
+			MaxDelay:    cfg.Postgres.RetryMaxDelay,
+		},
+		tracer:      &Tracer{SlowThreshold: cfg.Postgres.SlowQueryThreshold},
+		tenantPools: map[string]*pgxpool.Pool{},
+	}
+	for _, name := range cfg.Postgres.DualWrite {
+		db.dualWrite[name] = true
 	}
 	return db
 }
 
-// Open открывает соединение с postgres.
+// DualWrite сообщает, включена ли двойная запись для фоновой миграции name.
+func (db *Postgres) DualWrite(name string) bool {
+	return db.dualWrite[name]
+}
+
+// Open открывает пул соединений с postgres.
 func (db *Postgres) Open(ctx context.Context) (err error) {
+	db.reconnectMu.Lock()
+	defer db.reconnectMu.Unlock()
+
 	// Проверка, что задан DSN, прежде чем пытаться открыть соединение.
-	if db.config.DSN == "" {
+	dsn := db.dsn()
+	if dsn == "" {
 		return ErrDSNRequired
 	}
 
-	// Подключение соединения
-	db.Conn, err = pgx.Connect(ctx, db.config.DSN)
+	// Подключение пула
+	pool, err := db.newPool(ctx, dsn, "")
 	if err != nil {
 		return fmt.Errorf("unable to connect to database: %w", err)
 	}
+	db.setPool(pool)
+
+	// Без автоматической миграции схему обновляет команда cliring migrate до выкладки
+	if !db.config.MigrationAuto {
+		if err := db.checkSchemaVersion(ctx); err != nil {
+			db.setPool(nil)
+			pool.Close()
+			return err
+		}
+		db.openedOnce.Do(func() { close(db.opened) })
+		return nil
+	}
 
 	// Старт миграции
 	logrus.Info("Starting database migration")
 	if err := db.migrate(ctx); err != nil {
+		// Пул без миграций не используется: следующая попытка открывает новый
+		db.setPool(nil)
+		pool.Close()
 		return fmt.Errorf("failed to apply migrations: %w", err)
 	}
 
 	logrus.Info("Database migration completed successfully")
+	db.openedOnce.Do(func() { close(db.opened) })
 	return nil
 }
 
-// migrate- применяет миграции к базе данных с использованием tern.
-func (db *Postgres) migrate(ctx context.Context) error {
-	// Создаем мигрант tern
-	migrator, err := migrate.NewMigrator(ctx, db.Conn, db.config.MigrationVersionTable)
+// Opened возвращает канал, который закрывается после первого успешного Open.
+func (db *Postgres) Opened() <-chan struct{} {
+	return db.opened
+}
+
+// IsOpen сообщает, открыт ли пул соединений и применены ли миграции.
+func (db *Postgres) IsOpen() bool {
+	select {
+	case <-db.opened:
+		return true
+	default:
+		return false
+	}
+}
+
+// Maintain с периодом interval открывает пул соединений, если сервис стартовал без базы данных.
+// Разорванные соединения (например, после перезапуска postgres) пул заменяет сам.
+// Пока база недоступна, сервис работает в деградированном режиме и сообщает о неготовности.
+func (db *Postgres) Maintain(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if db.IsOpen() {
+				// Соединения открыты: дальнейших попыток не нужно
+				return
+			}
+			if err := db.Open(ctx); err != nil {
+				logrus.Errorf("unable to open database: %s", err)
+				continue
+			}
+			logrus.Info("Database connection opened")
+		}
+	}
+}
+
+// newPool открывает пул соединений с трассировкой запросов, запросы которого работают со схемой schema;
+// пусто - схема по умолчанию. Размер пула задается DB_MAX_CONNS, для арендатора - DB_TENANT_MAX_CONNS.
+func (db *Postgres) newPool(ctx context.Context, dsn, schema string) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
-		return fmt.Errorf("unable to initialize migrator: %w", err)
+		return nil, err
 	}
+	poolConfig.ConnConfig.Tracer = db.tracer
+	maxConns := db.config.MaxConns
+	if schema != "" {
+		poolConfig.ConnConfig.RuntimeParams["search_path"] = schema
+		maxConns = db.config.TenantMaxConns
+	}
+	if maxConns > 0 {
+		poolConfig.MaxConns = maxConns
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+	// Пул подключается лениво: недоступная база обнаруживается сразу
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
+
+// withConn выполняет fn на соединении из пула схемы public, например для миграций tern,
+// которым нужно отдельное соединение.
+func (db *Postgres) withConn(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	pool, err := db.publicPool()
+	if err != nil {
+		return err
+	}
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to acquire connection: %w", err)
+	}
+	defer conn.Release()
+	return fn(conn.Conn())
+}
+
+// publicPool возвращает пул соединений схемы public.
+func (db *Postgres) publicPool() (*pgxpool.Pool, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.pool == nil {
+		return nil, errNotOpen
+	}
+	return db.pool, nil
+}
+
+// setPool заменяет пул схемы public и возвращает прежний.
+func (db *Postgres) setPool(pool *pgxpool.Pool) *pgxpool.Pool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	old := db.pool
+	db.pool = pool
+	return old
+}
+
+// dsn возвращает текущую строку подключения.
+func (db *Postgres) dsn() string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.config.DSN
+}
+
+// setDSN заменяет строку подключения, например полученную из хранилища секретов.
+func (db *Postgres) setDSN(dsn string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.config.DSN = dsn
+}
+
+// SchemaVersion возвращает версию схемы public после применения миграций.
+func (db *Postgres) SchemaVersion() int32 {
+	return db.schemaVersion.Load()
+}
 
-	// Указываем директорию с миграциями
+// connect открывает отдельное соединение вне пула с трассировкой запросов.
+func (db *Postgres) connect(ctx context.Context, dsn string) (*pgx.Conn, error) {
+	return db.connectSchema(ctx, dsn, "")
+}
 
-	err = migrator.LoadMigrations(os.DirFS(db.config.MigrationsDir))
+// connectSchema открывает соединение, запросы которого работают со схемой schema; пусто - схема по умолчанию.
+func (db *Postgres) connectSchema(ctx context.Context, dsn, schema string) (*pgx.Conn, error) {
+	connConfig, err := pgx.ParseConfig(dsn)
 	if err != nil {
-		return fmt.Errorf("unable to load migrations from %s: %w", db.config.MigrationsDir, err)
+		return nil, err
+	}
+	connConfig.Tracer = db.tracer
+	if schema != "" {
+		connConfig.RuntimeParams["search_path"] = schema
 	}
+	return pgx.ConnectConfig(ctx, connConfig)
+}
 
-	// Применяем миграции
-	err = migrator.Migrate(ctx)
+// migrate- применяет миграции к базе данных с использованием tern: к схеме public и к схемам
+// арендаторов. Миграции выполняются под advisory-блокировкой, чтобы одновременно стартующие
+// экземпляры не применяли их параллельно. Ошибка миграции схемы арендатора не останавливает
+// сервис: она пишется в журнал, и схема обновляется следующим запуском или командой migrate up.
+func (db *Postgres) migrate(ctx context.Context) error {
+	var version int32
+	err := db.withConn(ctx, func(conn *pgx.Conn) (err error) {
+		version, err = db.migrateSchema(ctx, conn)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("unable to apply migrations: %w", err)
+		return err
 	}
+	db.schemaVersion.Store(version)
 
+	tenants, err := db.Tenants(ctx)
+	if err != nil {
+		return err
+	}
+	for _, tenant := range tenants {
+		if err := db.migrateTenant(ctx, tenant.ID); err != nil {
+			logrus.Errorf("unable to migrate tenant %s: %s", tenant.ID, err)
+		}
+	}
 	return nil
 }
 
-// Close закрывает соединение с базой данных.
-func (db *Postgres) Close(ctx context.Context) error {
-	if db.Conn != nil {
-		err := db.Conn.Close(ctx)
+// migrateSchema применяет миграции на соединении conn до целевой версии и возвращает версию схемы.
+func (db *Postgres) migrateSchema(ctx context.Context, conn *pgx.Conn) (version int32, err error) {
+	err = db.withMigrator(ctx, conn, func(migrator *migrate.Migrator) error {
+		currentVersion, err := migrator.GetCurrentVersion(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to get current schema version: %w", err)
+		}
+
+		// Целевая версия: закрепленная в конфигурации или последняя из директории
+		targetVersion, err := db.targetVersion(migrator)
 		if err != nil {
-			return fmt.Errorf("failed to close connection: %w", err)
+			return err
+		}
+
+		// Схему могла обновить более новая версия сервиса - откат не выполняем
+		if currentVersion >= targetVersion {
+			if currentVersion > targetVersion {
+				logrus.Warnf("Schema version %d is ahead of target version %d, skipping migration", currentVersion, targetVersion)
+			}
+			version = currentVersion
+			return nil
 		}
-		db.Conn = nil
+
+		// Применяем миграции
+		logrus.Infof("Migrating schema from version %d to %d", currentVersion, targetVersion)
+		if err := migrator.MigrateTo(ctx, targetVersion); err != nil {
+			return fmt.Errorf("unable to apply migrations: %w", err)
+		}
+		version = targetVersion
+		return nil
+	})
+	return version, err
+}
+
+// Reconnect открывает пул по новой строке подключения, например после ротации пароля, и заменяет
+// им текущий. Пулы арендаторов открываются заново при следующем запросе. Прежние пулы закрываются
+// через reconnectGrace.
+func (db *Postgres) Reconnect(ctx context.Context, dsn string) error {
+	db.reconnectMu.Lock()
+	defer db.reconnectMu.Unlock()
+
+	pool, err := db.newPool(ctx, dsn, "")
+	if err != nil {
+		return fmt.Errorf("unable to connect to database: %w", err)
+	}
+
+	db.mu.Lock()
+	old := db.pool
+	db.pool = pool
+	db.config.DSN = dsn
+	db.mu.Unlock()
+	oldTenants := db.takeTenantPools()
+	time.AfterFunc(reconnectGrace, func() {
+		if old != nil {
+			old.Close()
+		}
+		for _, tenantPool := range oldTenants {
+			tenantPool.Close()
+		}
+	})
+	return nil
+}
+
+// WatchDSN с периодом interval запрашивает строку подключения через fetch и переподключается,
+// если она изменилась или база не отвечает (например, отозваны прежние учетные данные).
+// Ошибки получения строки подключения не прерывают работу: остается текущий пул.
+func (db *Postgres) WatchDSN(ctx context.Context, interval time.Duration, fetch func(context.Context) (string, error)) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			dsn, err := fetch(ctx)
+			if err != nil {
+				logrus.Errorf("unable to fetch database credentials: %s", err)
+				continue
+			}
+			// До открытия соединения новая строка подключения используется следующей попыткой Open
+			if !db.IsOpen() {
+				db.setDSN(dsn)
+				continue
+			}
+			if dsn == db.dsn() && db.Ping(ctx) == nil {
+				continue
+			}
+			if err := db.Reconnect(ctx, dsn); err != nil {
+				logrus.Errorf("unable to reconnect to database: %s", err)
+				continue
+			}
+			logrus.Info("Database connection re-established with refreshed credentials")
+		}
+	}
+}
+
+// Ping проверяет, что база данных отвечает.
+func (db *Postgres) Ping(ctx context.Context) error {
+	pool, err := db.publicPool()
+	if err != nil {
+		return err
+	}
+	return pool.Ping(ctx)
+}
+
+// Close закрывает пул соединений с базой данных и пулы арендаторов.
+// Close ожидает возврата в пул всех занятых соединений.
+func (db *Postgres) Close(ctx context.Context) error {
+	for _, tenantPool := range db.takeTenantPools() {
+		tenantPool.Close()
+	}
+	if pool := db.setPool(nil); pool != nil {
+		pool.Close()
 	}
 	return nil
 }
@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Коды ошибок Postgres, после которых запрос можно повторить: запрос откатан целиком.
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgAdminShutdown        = "57P01"
+	pgCrashShutdown        = "57P02"
+	pgCannotConnectNow     = "57P03"
+	// pgConnectionExceptionClass - класс ошибок соединения (08xxx).
+	pgConnectionExceptionClass = "08"
+)
+
+// RetryPolicy задает повтор запросов после временных ошибок: конфликта сериализации, взаимной
+// блокировки, разрыва соединения до отправки запроса и переключения на реплику при отказе.
+// Пауза перед повтором растет экспоненциально от BaseDelay до MaxDelay и выбирается случайно
+// в этих пределах, чтобы экземпляры сервиса не повторяли запросы одновременно.
+type RetryPolicy struct {
+	// MaxAttempts - число попыток с первой; 1 - без повторов.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// IsTransient сообщает, можно ли повторить запрос, завершившийся ошибкой err.
+// Разрыв соединения после отправки запроса не повторяется: изменение могло быть применено.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	switch pgErr.Code {
+	case pgSerializationFailure, pgDeadlockDetected, pgAdminShutdown, pgCrashShutdown, pgCannotConnectNow:
+		return true
+	}
+	return strings.HasPrefix(pgErr.Code, pgConnectionExceptionClass)
+}
+
+// delay возвращает паузу перед повтором после attempt неудачных попыток.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	backoff := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || backoff < p.MaxDelay); i++ {
+		backoff *= 2
+	}
+	if p.MaxDelay > 0 && backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(backoff)) + 1)
+}
+
+// retry выполняет fn на соединении, закрепленном за контекстом WithConn, а без него - на пуле схемы
+// из контекста с повтором после временных ошибок. На закрепленном соединении fn выполняется один раз:
+// повтор на другом соединении потерял бы состояние его сессии.
+func (db *Postgres) retry(ctx context.Context, fn func(q querier) error) error {
+	if conn := connFor(ctx); conn != nil {
+		return fn(conn)
+	}
+	return db.retryPool(ctx, func(pool *pgxpool.Pool) error {
+		return fn(pool)
+	})
+}
+
+// retryPool выполняет fn на пуле схемы из контекста, повторяя его после временных ошибок по политике
+// db.retryPolicy. Разорванное соединение пул не возвращает в работу, поэтому следующая попытка идет
+// на новое соединение (например, к новому ведущему серверу после переключения).
+func (db *Postgres) retryPool(ctx context.Context, fn func(pool *pgxpool.Pool) error) error {
+	attempts := db.retryPolicy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		pool, poolErr := db.poolFor(ctx)
+		if poolErr != nil {
+			return poolErr
+		}
+		err = fn(pool)
+		if attempt >= attempts || !IsTransient(err) {
+			return err
+		}
+
+		logrus.Warnf("Transient database error, retrying (attempt %d of %d): %s", attempt+1, attempts, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(db.retryPolicy.delay(attempt)):
+		}
+	}
+}
+
+// Exec выполняет запрос, повторяя его после временных ошибок.
+func (db *Postgres) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	var tag pgconn.CommandTag
+	err := db.retry(ctx, func(q querier) (err error) {
+		tag, err = q.Exec(ctx, sql, args...)
+		return err
+	})
+	return tag, err
+}
+
+// Query выполняет запрос, повторяя его, если ошибка возникла до получения строк.
+// Ошибки чтения строк возвращаются из rows.Err() и не повторяются.
+func (db *Postgres) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	var rows pgx.Rows
+	err := db.retry(ctx, func(q querier) (err error) {
+		rows, err = q.Query(ctx, sql, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRow возвращает строку, запрос которой выполняется и повторяется при Scan.
+func (db *Postgres) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &retryRow{db: db, ctx: ctx, sql: sql, args: args}
+}
+
+// Begin начинает транзакцию, повторяя начало после временных ошибок. Ошибки внутри транзакции
+// не повторяются: транзакцию откатывает и при необходимости повторяет вызывающий код.
+func (db *Postgres) Begin(ctx context.Context) (pgx.Tx, error) {
+	return db.BeginTx(ctx, pgx.TxOptions{})
+}
+
+// BeginTx начинает транзакцию с параметрами opts, повторяя начало после временных ошибок.
+func (db *Postgres) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	var tx pgx.Tx
+	err := db.retry(ctx, func(q querier) (err error) {
+		tx, err = q.BeginTx(ctx, opts)
+		return err
+	})
+	return tx, err
+}
+
+// Acquire берет соединение из пула схемы из контекста, повторяя попытку после временных ошибок,
+// например для блокировки, которая должна держаться на одной сессии. Соединение возвращается в пул
+// вызовом Release.
+func (db *Postgres) Acquire(ctx context.Context) (*pgxpool.Conn, error) {
+	var conn *pgxpool.Conn
+	err := db.retryPool(ctx, func(pool *pgxpool.Pool) (err error) {
+		conn, err = pool.Acquire(ctx)
+		return err
+	})
+	return conn, err
+}
+
+// retryRow - строка QueryRow, которая выполняет запрос при Scan и повторяет его после временных ошибок.
+type retryRow struct {
+	db   *Postgres
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+// Scan выполняет запрос и читает единственную строку в dest.
+func (r *retryRow) Scan(dest ...any) error {
+	return r.db.retry(r.ctx, func(q querier) error {
+		return q.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	})
+}
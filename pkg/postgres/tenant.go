@@ -0,0 +1,242 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Данные арендатора (группы дилерских центров) хранятся в отдельной схеме tenant_<id> с теми же
+// таблицами, что и схема public. Запрос выполняется на пуле соединений, у которых search_path указывает
+// на схему арендатора из контекста запроса, поэтому запросы репозитория не меняются и не могут
+// обратиться к данным другого арендатора. Запросы без арендатора работают со схемой public.
+
+// tenantSchemaPrefix - префикс имени схемы арендатора.
+const tenantSchemaPrefix = "tenant_"
+
+// pgUndefinedTable - код ошибки Postgres об отсутствующей таблице.
+const pgUndefinedTable = "42P01"
+
+// tenantIDPattern - допустимый идентификатор арендатора: он входит в имя схемы.
+var tenantIDPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+var (
+	ErrInvalidTenantID = errors.New("invalid tenant id")
+	ErrTenantExists    = errors.New("tenant already exists")
+	ErrUnknownTenant   = errors.New("unknown tenant")
+)
+
+// Tenant - арендатор из реестра public.tenants.
+type Tenant struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+	// ProvisionedAt - время создания схемы и применения миграций; nil - создание не завершено.
+	ProvisionedAt *time.Time
+}
+
+// ValidTenantID сообщает, допустим ли идентификатор арендатора.
+func ValidTenantID(id string) bool {
+	return tenantIDPattern.MatchString(id)
+}
+
+// TenantSchema возвращает имя схемы арендатора.
+func TenantSchema(id string) string {
+	return tenantSchemaPrefix + id
+}
+
+// WithTenantResolver задает функцию, возвращающую арендатора из контекста запроса; пустая строка - схема public.
+func (db *Postgres) WithTenantResolver(resolve func(ctx context.Context) string) *Postgres {
+	db.tenantOf = resolve
+	return db
+}
+
+// poolFor возвращает пул для арендатора из контекста: для запросов без арендатора - пул схемы public,
+// для арендатора - пул с его схемой, который открывается при первом запросе.
+func (db *Postgres) poolFor(ctx context.Context) (*pgxpool.Pool, error) {
+	tenant := ""
+	if db.tenantOf != nil {
+		tenant = db.tenantOf(ctx)
+	}
+	if tenant == "" {
+		return db.publicPool()
+	}
+
+	db.tenantMu.Lock()
+	defer db.tenantMu.Unlock()
+	if pool := db.tenantPools[tenant]; pool != nil {
+		return pool, nil
+	}
+	provisioned, err := db.tenantProvisioned(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if !provisioned {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, tenant)
+	}
+	pool, err := db.newPool(ctx, db.dsn(), TenantSchema(tenant))
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to tenant %s: %w", tenant, err)
+	}
+	db.tenantPools[tenant] = pool
+	return pool, nil
+}
+
+// HasTenant сообщает, зарегистрирован ли арендатор и создана ли его схема.
+func (db *Postgres) HasTenant(ctx context.Context, id string) (bool, error) {
+	db.tenantMu.Lock()
+	pool := db.tenantPools[id]
+	db.tenantMu.Unlock()
+	if pool != nil {
+		return true, nil
+	}
+	return db.tenantProvisioned(ctx, id)
+}
+
+// tenantProvisioned проверяет по реестру, что схема арендатора создана.
+func (db *Postgres) tenantProvisioned(ctx context.Context, id string) (bool, error) {
+	if !ValidTenantID(id) {
+		return false, nil
+	}
+	pool, err := db.publicPool()
+	if err != nil {
+		return false, err
+	}
+	var provisioned bool
+	err = pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM public.tenants WHERE tenant_id = $1 AND provisioned_at IS NOT NULL)`, id).
+		Scan(&provisioned)
+	if err != nil && !isUndefinedTable(err) {
+		return false, fmt.Errorf("unable to check tenant %s: %w", id, err)
+	}
+	return provisioned, nil
+}
+
+// Tenants возвращает зарегистрированных арендаторов. До миграции с реестром список пуст.
+func (db *Postgres) Tenants(ctx context.Context) ([]Tenant, error) {
+	pool, err := db.publicPool()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := pool.Query(ctx,
+		`SELECT tenant_id, name, created_at, provisioned_at FROM public.tenants ORDER BY tenant_id`)
+	if err != nil {
+		if isUndefinedTable(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to list tenants: %w", err)
+	}
+	tenants, err := pgx.CollectRows(rows, scanTenant)
+	if err != nil {
+		if isUndefinedTable(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to list tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+// scanTenant читает строку реестра арендаторов.
+func scanTenant(row pgx.CollectableRow) (Tenant, error) {
+	var tenant Tenant
+	err := row.Scan(&tenant.ID, &tenant.Name, &tenant.CreatedAt, &tenant.ProvisionedAt)
+	return tenant, err
+}
+
+// ProvisionTenant регистрирует арендатора, создает его схему и применяет к ней миграции.
+// Прерванное создание продолжается повторным вызовом; для созданного арендатора возвращается ErrTenantExists.
+func (db *Postgres) ProvisionTenant(ctx context.Context, id, name string) (*Tenant, error) {
+	if !ValidTenantID(id) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTenantID, id)
+	}
+
+	pool, err := db.publicPool()
+	if err != nil {
+		return nil, err
+	}
+	var provisioned bool
+	err = pool.QueryRow(ctx, `
+		INSERT INTO public.tenants (tenant_id, name)
+		VALUES ($1, $2)
+		ON CONFLICT (tenant_id) DO UPDATE SET name = EXCLUDED.name
+		RETURNING provisioned_at IS NOT NULL`, id, name).Scan(&provisioned)
+	if err != nil {
+		return nil, fmt.Errorf("unable to register tenant %s: %w", id, err)
+	}
+	if provisioned {
+		return nil, fmt.Errorf("%w: %s", ErrTenantExists, id)
+	}
+
+	if err := db.migrateTenant(ctx, id); err != nil {
+		return nil, err
+	}
+
+	tenant := Tenant{ID: id}
+	err = pool.QueryRow(ctx,
+		`SELECT tenant_id, name, created_at, provisioned_at FROM public.tenants WHERE tenant_id = $1`, id).
+		Scan(&tenant.ID, &tenant.Name, &tenant.CreatedAt, &tenant.ProvisionedAt)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get tenant %s: %w", id, err)
+	}
+	return &tenant, nil
+}
+
+// migrateTenant создает схему арендатора, если ее нет, применяет к ней миграции
+// и отмечает арендатора созданным.
+func (db *Postgres) migrateTenant(ctx context.Context, id string) error {
+	pool, err := db.publicPool()
+	if err != nil {
+		return err
+	}
+	schema := pgx.Identifier{TenantSchema(id)}.Sanitize()
+	if _, err := pool.Exec(ctx, `CREATE SCHEMA IF NOT EXISTS `+schema); err != nil {
+		return fmt.Errorf("unable to create schema for tenant %s: %w", id, err)
+	}
+
+	err = db.withTenantConn(ctx, id, func(conn *pgx.Conn) error {
+		_, err := db.migrateSchema(ctx, conn)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations for tenant %s: %w", id, err)
+	}
+
+	_, err = pool.Exec(ctx,
+		`UPDATE public.tenants SET provisioned_at = CURRENT_TIMESTAMP WHERE tenant_id = $1 AND provisioned_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("unable to mark tenant %s provisioned: %w", id, err)
+	}
+	return nil
+}
+
+// withTenantConn выполняет fn на отдельном соединении со схемой арендатора и закрывает его.
+func (db *Postgres) withTenantConn(ctx context.Context, id string, fn func(conn *pgx.Conn) error) error {
+	conn, err := db.connectSchema(ctx, db.dsn(), TenantSchema(id))
+	if err != nil {
+		return fmt.Errorf("unable to connect to tenant %s: %w", id, err)
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+	return fn(conn)
+}
+
+// takeTenantPools забирает открытые пулы арендаторов для закрытия; следующий запрос арендатора
+// открывает новый пул.
+func (db *Postgres) takeTenantPools() map[string]*pgxpool.Pool {
+	db.tenantMu.Lock()
+	defer db.tenantMu.Unlock()
+	pools := db.tenantPools
+	db.tenantPools = map[string]*pgxpool.Pool{}
+	return pools
+}
+
+// isUndefinedTable сообщает, что таблица не существует, например реестр до его миграции.
+func isUndefinedTable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUndefinedTable
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+// Метрики запросов по имени запроса - методу репозитория, из которого он выполнен.
+var (
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cliring_db_query_duration_seconds",
+		Help:    "Duration of database queries.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"query", "status"})
+	queryRows = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cliring_db_query_rows",
+		Help:    "Rows returned or affected by database queries.",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+	}, []string{"query"})
+	queryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cliring_db_query_errors_total",
+		Help: "Database queries that failed.",
+	}, []string{"query"})
+)
+
+// Статусы запроса в метрике длительности.
+const (
+	queryStatusOK    = "ok"
+	queryStatusError = "error"
+)
+
+// unknownQueryName - имя запроса, выполненного не из кода сервиса.
+const unknownQueryName = "unknown"
+
+// Tracer записывает длительность, число строк и ошибки запросов в метрики Prometheus
+// и пишет в журнал запросы дольше SlowThreshold.
+type Tracer struct {
+	// SlowThreshold - длительность, начиная с которой запрос пишется в журнал; 0 - не писать.
+	SlowThreshold time.Duration
+}
+
+// queryTraceKey - ключ контекста с началом запроса.
+type queryTraceKey struct{}
+
+// queryTrace - запрос, начатый в TraceQueryStart.
+type queryTrace struct {
+	name    string
+	sql     string
+	startAt time.Time
+}
+
+// TraceQueryStart запоминает имя запроса и время его начала.
+func (t *Tracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryTraceKey{}, &queryTrace{
+		name:    queryName(),
+		sql:     data.SQL,
+		startAt: time.Now(),
+	})
+}
+
+// TraceQueryEnd записывает метрики запроса и пишет медленный запрос в журнал.
+func (t *Tracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	trace, ok := ctx.Value(queryTraceKey{}).(*queryTrace)
+	if !ok {
+		return
+	}
+	duration := time.Since(trace.startAt)
+	rows := data.CommandTag.RowsAffected()
+
+	status := queryStatusOK
+	if data.Err != nil {
+		status = queryStatusError
+		queryErrors.WithLabelValues(trace.name).Inc()
+	} else {
+		queryRows.WithLabelValues(trace.name).Observe(float64(rows))
+	}
+	queryDuration.WithLabelValues(trace.name, status).Observe(duration.Seconds())
+
+	if t.SlowThreshold <= 0 || duration < t.SlowThreshold {
+		return
+	}
+	// Параметры запроса не пишутся: в них могут быть персональные данные
+	entry := logrus.WithFields(logrus.Fields{
+		"query":       trace.name,
+		"duration_ms": duration.Milliseconds(),
+		"rows":        rows,
+		"sql":         strings.Join(strings.Fields(trace.sql), " "),
+	})
+	if data.Err != nil {
+		entry = entry.WithError(data.Err)
+	}
+	entry.Warn("Slow database query")
+}
+
+// queryName возвращает функцию, из которой выполнен запрос, например "repository.GetDeal":
+// первый кадр стека вне pgx и этого пакета. Число имен ограничено кодом сервиса,
+// поэтому имя пригодно как метка метрики.
+func queryName() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		function := frame.Function
+		if function != "" && !strings.HasPrefix(function, "github.com/jackc/") && !strings.HasPrefix(function, packagePath) {
+			return shortFunctionName(function)
+		}
+		if !more {
+			return unknownQueryName
+		}
+	}
+}
+
+// packagePath - префикс имен функций этого пакета в стеке.
+var packagePath = reflect.TypeOf(Tracer{}).PkgPath() + "."
+
+// shortFunctionName сокращает "cliring/internal/repository.(*Repository).GetDeal.func1"
+// до "repository.GetDeal".
+func shortFunctionName(function string) string {
+	function = function[strings.LastIndex(function, "/")+1:]
+	parts := strings.Split(function, ".")
+	name := make([]string, 0, len(parts))
+	for _, part := range parts {
+		// Получатель метода и номера замыканий (func1, func1.2) в имя не входят
+		if strings.HasPrefix(part, "(") || len(name) > 1 && isClosurePart(part) {
+			continue
+		}
+		name = append(name, part)
+	}
+	return strings.Join(name, ".")
+}
+
+// isClosurePart сообщает, является ли часть имени номером замыкания.
+func isClosurePart(part string) bool {
+	part = strings.TrimPrefix(part, "func")
+	if part == "" {
+		return false
+	}
+	for _, r := range part {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
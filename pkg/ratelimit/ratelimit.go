@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Limiter решает, можно ли выполнить ещё один запрос с ключом key в текущем окне.
+type Limiter interface {
+	Allow(ctx context.Context, key string) (bool, error)
+}
+
+// Store - общее для всех экземпляров сервиса хранилище счетчиков.
+type Store interface {
+	// HitRateLimit учитывает запрос с ключом key в окне, начавшемся в windowStart, и возвращает число запросов в окне.
+	HitRateLimit(ctx context.Context, key string, windowStart time.Time) (int, error)
+}
+
+// Local ограничивает запросы в пределах одного экземпляра: не более limit запросов на ключ за окно window.
+type Local struct {
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	start   time.Time
+	counter map[string]int
+}
+
+// NewLocal возвращает ограничитель с фиксированным окном, хранящий счетчики в памяти.
+func NewLocal(limit int, window time.Duration) *Local {
+	return &Local{limit: limit, window: window, counter: map[string]int{}}
+}
+
+// Allow реализует Limiter.
+func (l *Local) Allow(_ context.Context, key string) (bool, error) {
+	windowStart := time.Now().Truncate(l.window)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Новое окно - счетчики прошлого окна больше не нужны
+	if !windowStart.Equal(l.start) {
+		l.start = windowStart
+		l.counter = map[string]int{}
+	}
+	l.counter[key]++
+	return l.counter[key] <= l.limit, nil
+}
+
+// Shared ограничивает запросы по счетчикам в общем хранилище, поэтому лимит действует на весь парк
+// экземпляров за балансировщиком. Если хранилище недоступно, используется локальный ограничитель.
+type Shared struct {
+	store    Store
+	limit    int
+	window   time.Duration
+	fallback *Local
+}
+
+// NewShared возвращает ограничитель с фиксированным окном поверх общего хранилища.
+func NewShared(store Store, limit int, window time.Duration) *Shared {
+	return &Shared{store: store, limit: limit, window: window, fallback: NewLocal(limit, window)}
+}
+
+// Allow реализует Limiter.
+func (s *Shared) Allow(ctx context.Context, key string) (bool, error) {
+	hits, err := s.store.HitRateLimit(ctx, key, time.Now().Truncate(s.window))
+	if err != nil {
+		logrus.WithError(err).Warn("shared rate limit store is unavailable, falling back to local limiting")
+		return s.fallback.Allow(ctx, key)
+	}
+	return hits <= s.limit, nil
+}
@@ -0,0 +1,100 @@
+package s3
+
+import (
+	"cliring/config"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/sirupsen/logrus"
+	"io"
+)
+
+var (
+	ErrEndpointRequired = errors.New("s3 endpoint required")
+	ErrObjectNotFound   = errors.New("object not found")
+)
+
+type Storage struct {
+	Client *minio.Client
+	config config.S3
+}
+
+// New возвращает новый экземпляр S3-совместимого хранилища с заданными настройками.
+func New(cfg *config.Config) *Storage {
+	return &Storage{
+		Client: nil,
+		config: cfg.S3,
+	}
+}
+
+// Open создает клиента хранилища и бакет для документов, если он еще не существует.
+func (s *Storage) Open(ctx context.Context) (err error) {
+	if s.config.Endpoint == "" {
+		return ErrEndpointRequired
+	}
+
+	s.Client, err = minio.New(s.config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(s.config.AccessKey, s.config.SecretKey, ""),
+		Secure: s.config.UseSSL,
+		Region: s.config.Region,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create s3 client: %w", err)
+	}
+
+	exists, err := s.Client.BucketExists(ctx, s.config.Bucket)
+	if err != nil {
+		return fmt.Errorf("unable to check bucket %s: %w", s.config.Bucket, err)
+	}
+	if !exists {
+		logrus.Infof("Creating bucket %s", s.config.Bucket)
+		if err := s.Client.MakeBucket(ctx, s.config.Bucket, minio.MakeBucketOptions{Region: s.config.Region}); err != nil {
+			return fmt.Errorf("unable to create bucket %s: %w", s.config.Bucket, err)
+		}
+	}
+	return nil
+}
+
+// Ping проверяет доступность хранилища и бакета.
+func (s *Storage) Ping(ctx context.Context) error {
+	if _, err := s.Client.BucketExists(ctx, s.config.Bucket); err != nil {
+		return fmt.Errorf("unable to check bucket %s: %w", s.config.Bucket, err)
+	}
+	return nil
+}
+
+// Put сохраняет объект в бакет.
+func (s *Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.Client.PutObject(ctx, s.config.Bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("failed to put object %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get открывает объект на чтение. Вызывающий обязан закрыть результат.
+func (s *Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.Client.GetObject(ctx, s.config.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	// Ошибка отсутствия объекта возвращается только при первом обращении к нему
+	if _, err := object.Stat(); err != nil {
+		_ = object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to stat object %s: %w", key, err)
+	}
+	return object, nil
+}
+
+// Delete удаляет объект из бакета.
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	if err := s.Client.RemoveObject(ctx, s.config.Bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete object %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package secrets
+
+import (
+	"bytes"
+	"cliring/config"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// awsService - имя сервиса AWS в подписи запросов.
+const awsService = "secretsmanager"
+
+// AWS читает секреты из AWS Secrets Manager. Запросы подписываются по AWS Signature Version 4
+// ключами доступа из настроек.
+type AWS struct {
+	client          *http.Client
+	endpoint        string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// NewAWS возвращает клиента Secrets Manager с регионом и ключами доступа из настроек.
+func NewAWS(cfg *config.Config) (*AWS, error) {
+	s := cfg.Secrets
+	if s.AWSRegion == "" || s.AWSAccessKeyID == "" || s.AWSSecretAccessKey == "" {
+		return nil, errors.New("aws region and access keys required")
+	}
+	endpoint := s.AWSEndpoint
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + s.AWSRegion + ".amazonaws.com"
+	}
+	return &AWS{
+		client:          &http.Client{Timeout: s.Timeout},
+		endpoint:        strings.TrimSuffix(endpoint, "/"),
+		region:          s.AWSRegion,
+		accessKeyID:     s.AWSAccessKeyID,
+		secretAccessKey: s.AWSSecretAccessKey,
+		sessionToken:    s.AWSSessionToken,
+	}, nil
+}
+
+// Secret читает текущую версию секрета name. Секрет в формате JSON-объекта возвращается по ключам,
+// иначе значение возвращается под ключом "value".
+func (a *AWS) Secret(ctx context.Context, name string) (map[string]string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": name})
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode aws request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create aws request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	a.sign(req, payload, time.Now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read aws secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read aws response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("aws responded with status %d", resp.StatusCode)
+	}
+
+	var secret struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("unable to decode aws secret: %w", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(secret.SecretString), &fields); err != nil {
+		return map[string]string{"value": secret.SecretString}, nil
+	}
+	values := make(map[string]string, len(fields))
+	for key, value := range fields {
+		if str, ok := value.(string); ok {
+			values[key] = str
+		} else {
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return values, nil
+}
+
+// sign добавляет к запросу подпись AWS Signature Version 4.
+func (a *AWS) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.sessionToken)
+	}
+
+	// Подписываются все заголовки запроса и host, в алфавитном порядке имен
+	headers := map[string]string{"host": req.URL.Host}
+	names := []string{"host"}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		names = append(names, lower)
+	}
+	slices.Sort(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashHex(payload),
+	}, "\n")
+
+	scope := date + "/" + a.region + "/" + awsService + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+a.secretAccessKey), date)
+	key = hmacSHA256(key, a.region)
+	key = hmacSHA256(key, awsService)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+a.accessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// canonicalQuery возвращает параметры запроса в каноническом виде подписи.
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
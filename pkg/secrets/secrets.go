@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"cliring/config"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown secrets provider")
+	ErrKeyNotFound     = errors.New("secret key not found")
+)
+
+// Provider читает секреты из внешнего хранилища. Секрет - набор пар ключ-значение,
+// например строка подключения и пароль.
+type Provider interface {
+	Secret(ctx context.Context, name string) (map[string]string, error)
+}
+
+// New возвращает хранилище секретов из настроек: vault (HashiCorp Vault, KV v2) или aws (AWS Secrets Manager).
+// Без настройки возвращается nil: учетные данные берутся из переменных окружения.
+func New(cfg *config.Config) (Provider, error) {
+	switch cfg.Secrets.Provider {
+	case "":
+		return nil, nil
+	case "vault":
+		return NewVault(cfg)
+	case "aws":
+		return NewAWS(cfg)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, cfg.Secrets.Provider)
+	}
+}
+
+// Resolve возвращает значение по ссылке вида "секрет#ключ"; без ключа берется единственное значение секрета.
+func Resolve(ctx context.Context, provider Provider, ref string) (string, error) {
+	name, key, _ := strings.Cut(ref, "#")
+	values, err := provider.Secret(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("unable to read secret %s: %w", name, err)
+	}
+	if key == "" {
+		if len(values) != 1 {
+			return "", fmt.Errorf("secret %s has %d keys, specify the key as %s#key: %w", name, len(values), name, ErrKeyNotFound)
+		}
+		for _, value := range values {
+			return value, nil
+		}
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s#%s", ErrKeyNotFound, name, key)
+	}
+	return value, nil
+}
@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"cliring/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxResponseBody - предельный размер ответа хранилища секретов.
+const maxResponseBody = 1 << 20
+
+// Vault читает секреты из движка KV версии 2 HashiCorp Vault по токену.
+type Vault struct {
+	client *http.Client
+	addr   string
+	token  string
+	mount  string
+}
+
+// NewVault возвращает клиента Vault с адресом, токеном и точкой монтирования KV из настроек.
+func NewVault(cfg *config.Config) (*Vault, error) {
+	if cfg.Secrets.VaultAddr == "" || cfg.Secrets.VaultToken == "" {
+		return nil, errors.New("vault address and token required")
+	}
+	return &Vault{
+		client: &http.Client{Timeout: cfg.Secrets.Timeout},
+		addr:   strings.TrimSuffix(cfg.Secrets.VaultAddr, "/"),
+		token:  cfg.Secrets.VaultToken,
+		mount:  strings.Trim(cfg.Secrets.VaultMount, "/"),
+	}, nil
+}
+
+// Secret читает последнюю версию секрета по пути name.
+func (v *Vault) Secret(ctx context.Context, name string) (map[string]string, error) {
+	url := v.addr + "/v1/" + v.mount + "/data/" + strings.Trim(name, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read vault secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault responded with status %d", resp.StatusCode)
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("unable to decode vault secret: %w", err)
+	}
+
+	values := make(map[string]string, len(secret.Data.Data))
+	for key, value := range secret.Data.Data {
+		if str, ok := value.(string); ok {
+			values[key] = str
+		} else {
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"bytes"
+	"cliring/config"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Заголовки запроса с событием.
+const (
+	HeaderEvent     = "X-Cliring-Event"
+	HeaderDelivery  = "X-Cliring-Delivery"
+	HeaderSignature = "X-Cliring-Signature"
+	HeaderTimestamp = "X-Cliring-Timestamp"
+)
+
+// maxErrorBody - сколько байт ответа подписчика сохраняется в тексте ошибки.
+const maxErrorBody = 200
+
+// Sender отправляет события подписчикам POST-запросом с подписью тела.
+type Sender struct {
+	client *http.Client
+}
+
+// New возвращает Sender с таймаутом ответа подписчика из настроек.
+func New(cfg *config.Config) *Sender {
+	return &Sender{client: &http.Client{Timeout: cfg.Webhook.Timeout}}
+}
+
+// WithTLS задает настройки TLS запросов к подписчикам, например клиентский сертификат для mTLS с банками.
+func (s *Sender) WithTLS(tlsConfig *tls.Config) *Sender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	s.client.Transport = transport
+	return s
+}
+
+// Sign возвращает подпись запроса: "sha256=" и HMAC-SHA256 строки "<timestamp>.<тело>" в hex по ключу подписки.
+// Время отправки входит в подпись, чтобы перехваченный запрос нельзя было повторить позже окна проверки;
+// подписчик проверяет подпись функцией Verify.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send отправляет событие на url; доставка успешна, если подписчик ответил кодом 2xx.
+func (s *Sender) Send(ctx context.Context, url, secret, event string, deliveryID int64, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(deliveryID, 10))
+	// Подпись вычисляется при каждой попытке: повторная доставка получает новое время отправки
+	timestamp := time.Now().Unix()
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("webhook rejected with status %d: %s", resp.StatusCode, respBody)
+	}
+	// Тело ответа вычитывается, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultTolerance - допустимое расхождение времени отправки события и его проверки подписчиком.
+const DefaultTolerance = 5 * time.Minute
+
+// maxVerifyBody - предельный размер тела события, читаемого VerifyRequest.
+const maxVerifyBody = 1 << 20
+
+var (
+	// ErrInvalidSignature возвращается, если подпись отсутствует или не совпадает с вычисленной по ключу подписки.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrTimestampOutOfWindow возвращается, если время отправки не входит в окно проверки: запрос устарел
+	// (возможно, это повтор перехваченного запроса) или часы сторон сильно расходятся.
+	ErrTimestampOutOfWindow = errors.New("webhook timestamp is outside of the tolerance window")
+)
+
+// Verify проверяет подпись события из заголовков X-Cliring-Signature и X-Cliring-Timestamp по ключу подписки
+// и время отправки: событие отклоняется, если оно отправлено раньше или позже now больше чем на tolerance.
+// Повторы внутри окна подписчик отсеивает по X-Cliring-Delivery.
+func Verify(secret string, body []byte, signature, timestamp string, tolerance time.Duration, now time.Time) error {
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid webhook timestamp: %w", ErrInvalidSignature)
+	}
+	// Подпись проверяется до времени, чтобы не раскрывать окно проверки неподписанным запросам
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, sentAt, body))) {
+		return ErrInvalidSignature
+	}
+	if diff := now.Sub(time.Unix(sentAt, 0)); diff > tolerance || diff < -tolerance {
+		return ErrTimestampOutOfWindow
+	}
+	return nil
+}
+
+// VerifyRequest проверяет входящий запрос с событием с окном DefaultTolerance и возвращает его тело.
+// Тело запроса остается доступным для повторного чтения.
+func VerifyRequest(r *http.Request, secret string) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxVerifyBody))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read webhook body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := Verify(secret, body, r.Header.Get(HeaderSignature), r.Header.Get(HeaderTimestamp), DefaultTolerance, time.Now()); err != nil {
+		return nil, err
+	}
+	return body, nil
+}